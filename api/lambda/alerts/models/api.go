@@ -136,6 +136,7 @@ type UpdateAlertDeliveryInput struct {
 
 	// Variables that we allow updating (will be appended)
 	DeliveryResponses []*DeliveryResponse `json:"deliveryResponses"`
+	Tickets           []*ExternalTicket   `json:"tickets,omitempty"`
 }
 
 // DeliveryResponse holds the delivery response for data stored in DDB
@@ -147,6 +148,16 @@ type DeliveryResponse struct {
 	DispatchedAt time.Time `json:"dispatchedAt"`
 }
 
+// ExternalTicket is an issue created for an alert in an external ticketing system (Jira, GitHub)
+type ExternalTicket struct {
+	OutputID   string `json:"outputId" validate:"required,uuid4"`
+	OutputType string `json:"outputType" validate:"oneof=jira github"`
+	// Key identifies the issue in the ticketing system (ie `PROJ-123` for Jira or the issue number for GitHub)
+	Key       string    `json:"key" validate:"required"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 // UpdateAlertStatusOutput is an alias for an alert summary
 type UpdateAlertStatusOutput = []*AlertSummary
 
@@ -188,6 +199,7 @@ type AlertSummary struct {
 	RuleVersion       *string             `json:"ruleVersion"`
 	DedupString       *string             `json:"dedupString"`
 	DeliveryResponses []*DeliveryResponse `json:"deliveryResponses"`
+	Tickets           []*ExternalTicket   `json:"tickets"`
 	LogTypes          []string            `json:"logTypes"`
 	CreationTime      *time.Time          `json:"creationTime"`
 	UpdateTime        *time.Time          `json:"updateTime"`
@@ -50,6 +50,7 @@ type LambdaInput struct {
 	DispatchAlerts []*DispatchAlertsInput `json:"Records"`
 	DeliverAlert   *DeliverAlertInput     `json:"deliverAlert"`
 	SendTestAlert  *SendTestAlertInput    `json:"sendTestAlert"`
+	SyncTickets    *SyncTicketsInput      `json:"syncTickets"`
 }
 
 // SendTestAlertInput sends a dummy alert to the specified destinations
@@ -87,6 +88,15 @@ type DeliverAlertInput struct {
 	OutputIds []string `json:"outputIds" validate:"gt=0,dive,uuid4"`
 }
 
+// SyncTicketsInput syncs the status of alerts with the issues created for them in Jira and GitHub.
+// It is invoked on a schedule.
+//
+// Example:
+// {
+//     "syncTickets": {}
+// }
+type SyncTicketsInput struct{}
+
 // DispatchAlertsInput is an alias for an SQSMessage
 //
 // Example:
@@ -186,4 +196,23 @@ type Alert struct {
 
 	// IsResent is a flag set to indicate the alert is not new
 	IsResent bool `json:"isResent,omitempty"`
+
+	// Digest is set on the alerts that deliver a digest of the alerts grouped by the delivery policy of an output.
+	// Until the digest window ends, the alert only marks the window and has no grouped alerts.
+	Digest *AlertDigest `json:"digest,omitempty"`
+}
+
+// AlertDigest holds the alerts of a detection grouped for an output during a digest window
+type AlertDigest struct {
+	// WindowStart is the time the digest window started
+	WindowStart time.Time `json:"windowStart"`
+
+	// WindowEnd is the time the digest window ends
+	WindowEnd time.Time `json:"windowEnd"`
+
+	// AlertCount is the number of alerts grouped in the digest
+	AlertCount int `json:"alertCount,omitempty"`
+
+	// AlertIDs are the ids of the alerts grouped in the digest, up to a limit
+	AlertIDs []string `json:"alertIds,omitempty"`
 }
@@ -2,8 +2,6 @@
 // package logtypesapi documents github.com/panther-labs/panther/internal/core/logtypesapi.LogTypesAPI
 package logtypesapi
 
-import "time"
-
 /**
  * Panther is a Cloud-Native SIEM for the Modern Security Team.
  * Copyright (C) 2020 Panther Labs Inc
@@ -22,6 +20,8 @@ import "time"
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+import "time"
+
 // LogTypesAPI available endpoints
 type LogTypesAPI interface {
 	ListAvailableLogTypes() (ListAvailableLogTypesResponse, error)
@@ -36,10 +36,40 @@ type LogTypesAPI interface {
 
 	ListCustomLogs() (ListCustomLogsResponse, error)
 
+	DryRunCustomLog(input DryRunCustomLogInput) (DryRunCustomLogResponse, error)
+
+	GetIndicators() (GetIndicatorsResponse, error)
+
+	PutIndicators(input PutIndicatorsInput) (PutIndicatorsResponse, error)
+
+	InferCustomLogSchema(input InferCustomLogSchemaInput) (InferCustomLogSchemaResponse, error)
+
+	GetLookupTable(input GetLookupTableInput) (GetLookupTableResponse, error)
+
+	PutLookupTable(input PutLookupTableInput) (PutLookupTableResponse, error)
+
+	DelLookupTable(input DelLookupTableInput) (DelLookupTableResponse, error)
+
+	ListLookupTables() (ListLookupTablesResponse, error)
+
 	ListManagedSchemaUpdates(input ListManagedSchemaUpdatesInput) (ListManagedSchemaUpdatesResponse, error)
 
 	UpdateManagedSchemas(input UpdateManagedSchemasInput) (UpdateManagedSchemasResponse, error)
 
+	GetRetention(input GetRetentionInput) (GetRetentionResponse, error)
+
+	PutRetention(input PutRetentionInput) (PutRetentionResponse, error)
+
+	ListRetention() (ListRetentionResponse, error)
+
+	ListCustomLogRevisions(input ListCustomLogRevisionsInput) (ListCustomLogRevisionsResponse, error)
+
+	GetCustomLogRevision(input GetCustomLogRevisionInput) (GetCustomLogRevisionResponse, error)
+
+	DiffCustomLogRevisions(input DiffCustomLogRevisionsInput) (DiffCustomLogRevisionsResponse, error)
+
+	RevertCustomLog(input RevertCustomLogInput) (RevertCustomLogResponse, error)
+
 	GetSchema(input GetSchemaInput) (GetSchemaResponse, error)
 }
 
@@ -53,8 +83,23 @@ type LogTypesAPIPayload struct {
 	PutCustomLog             *PutCustomLogInput
 	DelCustomLog             *DelCustomLogInput
 	ListCustomLogs           *struct{}
+	DryRunCustomLog          *DryRunCustomLogInput
+	GetIndicators            *struct{}
+	PutIndicators            *PutIndicatorsInput
+	InferCustomLogSchema     *InferCustomLogSchemaInput
+	GetLookupTable           *GetLookupTableInput
+	PutLookupTable           *PutLookupTableInput
+	DelLookupTable           *DelLookupTableInput
+	ListLookupTables         *struct{}
 	ListManagedSchemaUpdates *ListManagedSchemaUpdatesInput
 	UpdateManagedSchemas     *UpdateManagedSchemasInput
+	GetRetention             *GetRetentionInput
+	PutRetention             *PutRetentionInput
+	ListRetention            *struct{}
+	ListCustomLogRevisions   *ListCustomLogRevisionsInput
+	GetCustomLogRevision     *GetCustomLogRevisionInput
+	DiffCustomLogRevisions   *DiffCustomLogRevisionsInput
+	RevertCustomLog          *RevertCustomLogInput
 	GetSchema                *GetSchemaInput
 }
 
@@ -70,6 +115,78 @@ type DelCustomLogResponse struct {
 	} `json:"error,omitempty" description:"The delete record"`
 }
 
+type DelLookupTableInput struct {
+	Name     string `json:"name" validate:"required" description:"The lookup table name"`
+	Revision int64  `json:"revision" validate:"min=1" description:"Lookup table record revision"`
+}
+
+type DelLookupTableResponse struct {
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+type DiffCustomLogRevisionsInput struct {
+	LogType      string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	FromRevision int64  `json:"fromRevision" validate:"required,min=1" description:"The revision to compare from"`
+	ToRevision   int64  `json:"toRevision" validate:"required,min=1" description:"The revision to compare to"`
+}
+
+type DiffCustomLogRevisionsResponse struct {
+	Changes []struct {
+		Type string `json:"type" description:"The type of the change"`
+		Path string `json:"path" description:"The path of the changed value in the schema"`
+		From string `json:"from,omitempty" description:"The value before the change in YAML format"`
+		To   string `json:"to,omitempty" description:"The value after the change in YAML format"`
+	} `json:"changes" description:"The changes between the two revisions (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+type DryRunCustomLogInput struct {
+	LogType    string    `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	Spec       string    `json:"logSpec" validate:"required" description:"The draft schema spec in YAML or JSON format"`
+	SourceID   string    `json:"sourceId" validate:"required" description:"The id of the source to read log data from"`
+	StartTime  time.Time `json:"startTime,omitempty" description:"Only read objects modified after this time"`
+	EndTime    time.Time `json:"endTime,omitempty" description:"Only read objects modified before this time"`
+	MaxObjects int       `json:"maxObjects,omitempty" validate:"omitempty,min=1,max=1000" description:"The maximum number of S3 objects to read (default 100)"`
+	MaxEvents  int       `json:"maxEvents,omitempty" validate:"omitempty,min=1,max=100000" description:"The maximum number of log entries to parse (default 10000)"`
+	JSONStream struct {
+		Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	} `json:"jsonStream,omitempty" description:"Read log entries from a stream of JSON values instead of lines"`
+}
+
+type DryRunCustomLogResponse struct {
+	NumObjects int `json:"numObjects,omitempty" description:"The number of S3 objects read"`
+	Report     struct {
+		NumLines    int     `json:"numLines" description:"The number of log entries parsed"`
+		NumMatched  int     `json:"numMatched" description:"The number of log entries successfully parsed"`
+		NumEvents   int     `json:"numEvents" description:"The number of events produced"`
+		SuccessRate float64 `json:"successRate" description:"The ratio of log entries successfully parsed"`
+		Errors      []struct {
+			Field    string `json:"field" description:"The path of the field (empty if the error is not specific to a field)"`
+			Count    int    `json:"count" description:"The number of log entries that failed to parse because of this field"`
+			Messages []struct {
+				Message string `json:"message" description:"The error message"`
+				Count   int    `json:"count" description:"The number of occurrences"`
+			} `json:"messages" description:"The most common error messages"`
+		} `json:"errors" description:"The most common parse errors for each field"`
+		Columns []struct {
+			Column string `json:"column" description:"The name of the column"`
+			Change string `json:"change" description:"The type of change (add, delete, update)"`
+			From   string `json:"from,omitempty" description:"The Glue type of the column before the change"`
+			To     string `json:"to,omitempty" description:"The Glue type of the column after the change"`
+		} `json:"columns" description:"The changes to the Glue table columns"`
+	} `json:"report,omitempty" description:"The results of the dry run (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
 type GetCustomLogInput struct {
 	LogType string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
 }
@@ -93,6 +210,99 @@ type GetCustomLogResponse struct {
 	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
 }
 
+type GetCustomLogRevisionInput struct {
+	LogType  string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	Revision int64  `json:"revision" validate:"required,min=1" description:"The revision to retrieve"`
+}
+
+type GetCustomLogRevisionResponse struct {
+	Result struct {
+		Name         string    `json:"logType" dynamodbav:"logType" validate:"required" description:"The schema id"`
+		Revision     int64     `json:"revision" validate:"required,min=1" description:"Schema record revision"`
+		Release      string    `json:"release,omitempty" description:"Managed schema release version"`
+		UpdatedAt    time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		CreatedAt    time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+		Managed      bool      `json:"managed,omitempty" description:"Schema is managed by Panther"`
+		Disabled     bool      `json:"disabled,omitempty" dynamodbav:"IsDeleted"  description:"Log record is deleted"`
+		Description  string    `json:"description" description:"Log type description"`
+		ReferenceURL string    `json:"referenceURL" description:"A URL with reference docs for the schema"`
+		Spec         string    `json:"logSpec" dynamodbav:"logSpec" validate:"required" description:"The schema spec in YAML or JSON format"`
+	} `json:"record,omitempty" description:"The custom log record at the requested revision (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+type GetIndicatorsResponse struct {
+	Record struct {
+		Revision   int64     `json:"revision" description:"Global indicators record revision"`
+		UpdatedAt  time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		Indicators []struct {
+			Name        string `json:"name" yaml:"name"`
+			Field       string `json:"field" yaml:"field"`
+			Description string `json:"description,omitempty" yaml:"description,omitempty"`
+			Match       string `json:"match,omitempty" yaml:"match,omitempty"`
+			Scanner     string `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+		} `json:"indicators" description:"The custom indicators available to all custom log schemas"`
+	} `json:"record,omitempty" description:"The global indicators record (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+type GetLookupTableInput struct {
+	Name string `json:"name" validate:"required" description:"The lookup table name"`
+}
+
+type GetLookupTableResponse struct {
+	Record struct {
+		Name        string    `json:"name" validate:"required" description:"The lookup table name"`
+		Revision    int64     `json:"revision" validate:"required,min=1" description:"Lookup table record revision"`
+		UpdatedAt   time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		CreatedAt   time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+		Description string    `json:"description" description:"Lookup table description"`
+		S3Key       string    `json:"s3Key" validate:"required" description:"The key of the lookup table data in the processed data bucket"`
+		Format      string    `json:"format" validate:"required,oneof=csv json" description:"The format of the lookup table data"`
+		KeyColumn   string    `json:"keyColumn" validate:"required" description:"The column with the values to match against indicator fields"`
+		Indicators  []string  `json:"indicators" validate:"required,min=1" description:"The indicator fields to match against the key column (ie p_any_ip_addresses)"`
+		Disabled    bool      `json:"disabled,omitempty" description:"Lookup table is not applied to events"`
+	} `json:"record,omitempty" description:"The lookup table record (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+type GetRetentionInput struct {
+	LogType string `json:"logType" validate:"required" description:"The log type id"`
+}
+
+type GetRetentionResponse struct {
+	Record struct {
+		LogType   string    `json:"logType" description:"The log type the retention policies apply to"`
+		Revision  int64     `json:"revision" description:"Retention record revision"`
+		UpdatedAt time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		Logs      struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"logs,omitempty" description:"The retention policy for processed log data (omitted if data are kept forever)"`
+		RuleMatches struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"ruleMatches,omitempty" description:"The retention policy for rule matches (omitted if data are kept forever)"`
+		RuleErrors struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"ruleErrors,omitempty" description:"The retention policy for rule errors (omitted if data are kept forever)"`
+	} `json:"record,omitempty" description:"The retention record (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
 type GetSchemaInput struct {
 	Name string `json:"name" validate:"required" description:"The schema id"`
 }
@@ -110,16 +320,77 @@ type GetSchemaResponse struct {
 		ReferenceURL string    `json:"referenceURL" description:"A URL with reference docs for the schema"`
 		Spec         string    `json:"logSpec" dynamodbav:"logSpec" validate:"required" description:"The schema spec in YAML or JSON format"`
 	} `json:"record,omitempty" description:"The schema record (field omitted if an error occurred)"`
+	Indicators []struct {
+		Name        string `json:"name" yaml:"name"`
+		Field       string `json:"field" yaml:"field"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+		Match       string `json:"match,omitempty" yaml:"match,omitempty"`
+		Scanner     string `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+	} `json:"indicators,omitempty" description:"The global indicators available to a user-defined schema"`
 	Error struct {
 		Code    string `json:"code" validate:"required"`
 		Message string `json:"message" validate:"required"`
 	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
 }
 
+type InferCustomLogSchemaInput struct {
+	S3Bucket   string `json:"s3Bucket,omitempty" validate:"required_without=Sample" description:"The S3 bucket to read sample objects from"`
+	S3Prefix   string `json:"s3Prefix,omitempty" description:"The S3 prefix to read sample objects from"`
+	Sample     string `json:"sample,omitempty" validate:"required_without=S3Bucket" description:"Sample log entries to use instead of S3 objects"`
+	MaxObjects int    `json:"maxObjects,omitempty" validate:"omitempty,min=1,max=100" description:"The maximum number of S3 objects to sample (default 10)"`
+	MaxEvents  int    `json:"maxEvents,omitempty" validate:"omitempty,min=1,max=10000" description:"The maximum number of log entries to sample (default 1000)"`
+	JSONStream struct {
+		Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	} `json:"jsonStream,omitempty" description:"Read log entries from a stream of JSON values instead of lines"`
+}
+
+type InferCustomLogSchemaResponse struct {
+	Spec       string `json:"logSpec,omitempty" description:"The draft schema spec in YAML format (field omitted if an error occurred)"`
+	NumObjects int    `json:"numObjects,omitempty" description:"The number of S3 objects sampled"`
+	Report     struct {
+		NumEvents      int    `json:"numEvents" description:"The number of log entries sampled"`
+		NumInvalid     int    `json:"numInvalid" description:"The number of entries that were not JSON objects"`
+		NumParseErrors int    `json:"numParseErrors" description:"The number of sampled entries that failed to parse with the draft schema"`
+		ParseError     string `json:"parseError,omitempty" description:"The first error when parsing sampled entries with the draft schema"`
+		Fields         []struct {
+			Path     string  `json:"path" description:"The path of the field (array elements are denoted with '[]')"`
+			Count    int     `json:"count" description:"The number of sampled entries that had the field"`
+			Coverage float64 `json:"coverage" description:"The ratio of sampled entries that had the field"`
+		} `json:"fields" description:"Coverage of each field in the sampled entries"`
+	} `json:"report,omitempty" description:"A report of the sampled log entries and the coverage of each field"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
 type ListAvailableLogTypesResponse struct {
 	LogTypes []string `json:"logTypes"`
 }
 
+type ListCustomLogRevisionsInput struct {
+	LogType string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+}
+
+type ListCustomLogRevisionsResponse struct {
+	Records []struct {
+		Name         string    `json:"logType" dynamodbav:"logType" validate:"required" description:"The schema id"`
+		Revision     int64     `json:"revision" validate:"required,min=1" description:"Schema record revision"`
+		Release      string    `json:"release,omitempty" description:"Managed schema release version"`
+		UpdatedAt    time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		CreatedAt    time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+		Managed      bool      `json:"managed,omitempty" description:"Schema is managed by Panther"`
+		Disabled     bool      `json:"disabled,omitempty" dynamodbav:"IsDeleted"  description:"Log record is deleted"`
+		Description  string    `json:"description" description:"Log type description"`
+		ReferenceURL string    `json:"referenceURL" description:"A URL with reference docs for the schema"`
+		Spec         string    `json:"logSpec" dynamodbav:"logSpec" validate:"required" description:"The schema spec in YAML or JSON format"`
+	} `json:"revisions" description:"The revisions of the custom log record (field omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
 type ListCustomLogsResponse struct {
 	Records []struct {
 		Name         string    `json:"logType" dynamodbav:"logType" validate:"required" description:"The schema id"`
@@ -147,6 +418,25 @@ type ListDeletedCustomLogsResponse struct {
 	} `json:"error,omitempty" description:"An error that occurred while fetching the list"`
 }
 
+type ListLookupTablesResponse struct {
+	Records []struct {
+		Name        string    `json:"name" validate:"required" description:"The lookup table name"`
+		Revision    int64     `json:"revision" validate:"required,min=1" description:"Lookup table record revision"`
+		UpdatedAt   time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		CreatedAt   time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+		Description string    `json:"description" description:"Lookup table description"`
+		S3Key       string    `json:"s3Key" validate:"required" description:"The key of the lookup table data in the processed data bucket"`
+		Format      string    `json:"format" validate:"required,oneof=csv json" description:"The format of the lookup table data"`
+		KeyColumn   string    `json:"keyColumn" validate:"required" description:"The column with the values to match against indicator fields"`
+		Indicators  []string  `json:"indicators" validate:"required,min=1" description:"The indicator fields to match against the key column (ie p_any_ip_addresses)"`
+		Disabled    bool      `json:"disabled,omitempty" description:"Lookup table is not applied to events"`
+	} `json:"lookupTables" description:"Lookup table records stored"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
 type ListManagedSchemaUpdatesInput struct{}
 
 type ListManagedSchemaUpdatesResponse struct {
@@ -161,6 +451,30 @@ type ListManagedSchemaUpdatesResponse struct {
 	} `json:"error,omitempty" description:"An error that occurred while fetching the record"`
 }
 
+type ListRetentionResponse struct {
+	Records []struct {
+		LogType   string    `json:"logType" description:"The log type the retention policies apply to"`
+		Revision  int64     `json:"revision" description:"Retention record revision"`
+		UpdatedAt time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		Logs      struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"logs,omitempty" description:"The retention policy for processed log data (omitted if data are kept forever)"`
+		RuleMatches struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"ruleMatches,omitempty" description:"The retention policy for rule matches (omitted if data are kept forever)"`
+		RuleErrors struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"ruleErrors,omitempty" description:"The retention policy for rule errors (omitted if data are kept forever)"`
+	} `json:"records,omitempty" description:"The retention records of all log types with retention policies (omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred while fetching the records"`
+}
+
 type PutCustomLogInput struct {
 	LogType      string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
 	Revision     int64  `json:"revision,omitempty" validate:"omitempty,min=1" description:"Custom log record revision to update (if omitted a new record will be created)"`
@@ -188,6 +502,131 @@ type PutCustomLogResponse struct {
 	} `json:"error,omitempty" description:"An error that occurred during the operation"`
 }
 
+type PutIndicatorsInput struct {
+	Revision   int64 `json:"revision" validate:"min=0" description:"Global indicators record revision to update (zero if no indicators were stored yet)"`
+	Indicators []struct {
+		Name        string `json:"name" yaml:"name"`
+		Field       string `json:"field" yaml:"field"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+		Match       string `json:"match,omitempty" yaml:"match,omitempty"`
+		Scanner     string `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+	} `json:"indicators" description:"The custom indicators available to all custom log schemas"`
+}
+
+type PutIndicatorsResponse struct {
+	Record struct {
+		Revision   int64     `json:"revision" description:"Global indicators record revision"`
+		UpdatedAt  time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		Indicators []struct {
+			Name        string `json:"name" yaml:"name"`
+			Field       string `json:"field" yaml:"field"`
+			Description string `json:"description,omitempty" yaml:"description,omitempty"`
+			Match       string `json:"match,omitempty" yaml:"match,omitempty"`
+			Scanner     string `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+		} `json:"indicators" description:"The custom indicators available to all custom log schemas"`
+	} `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+type PutLookupTableInput struct {
+	Name        string   `json:"name" validate:"required" description:"The lookup table name (lowercase letters, digits and underscores)"`
+	Revision    int64    `json:"revision,omitempty" validate:"omitempty,min=1" description:"Lookup table record revision to update (if omitted a new record will be created)"`
+	Description string   `json:"description" description:"Lookup table description"`
+	S3Key       string   `json:"s3Key" validate:"required" description:"The key of the lookup table data in the processed data bucket (must be under lookup_tables/)"`
+	Format      string   `json:"format" validate:"required,oneof=csv json" description:"The format of the lookup table data (csv with a header row or JSON objects)"`
+	KeyColumn   string   `json:"keyColumn" validate:"required" description:"The column with the values to match against indicator fields"`
+	Indicators  []string `json:"indicators" validate:"required,min=1" description:"The indicator fields to match against the key column (ie p_any_ip_addresses)"`
+	Disabled    bool     `json:"disabled,omitempty" description:"Do not apply the lookup table to events"`
+}
+
+type PutLookupTableResponse struct {
+	Record struct {
+		Name        string    `json:"name" validate:"required" description:"The lookup table name"`
+		Revision    int64     `json:"revision" validate:"required,min=1" description:"Lookup table record revision"`
+		UpdatedAt   time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		CreatedAt   time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+		Description string    `json:"description" description:"Lookup table description"`
+		S3Key       string    `json:"s3Key" validate:"required" description:"The key of the lookup table data in the processed data bucket"`
+		Format      string    `json:"format" validate:"required,oneof=csv json" description:"The format of the lookup table data"`
+		KeyColumn   string    `json:"keyColumn" validate:"required" description:"The column with the values to match against indicator fields"`
+		Indicators  []string  `json:"indicators" validate:"required,min=1" description:"The indicator fields to match against the key column (ie p_any_ip_addresses)"`
+		Disabled    bool      `json:"disabled,omitempty" description:"Lookup table is not applied to events"`
+	} `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+type PutRetentionInput struct {
+	LogType  string `json:"logType" validate:"required" description:"The log type id"`
+	Revision int64  `json:"revision" validate:"min=0" description:"Retention record revision to update (zero if no policies were stored yet)"`
+	Logs     struct {
+		Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+		ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+	} `json:"logs,omitempty" description:"The retention policy for processed log data (omit to keep data forever)"`
+	RuleMatches struct {
+		Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+		ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+	} `json:"ruleMatches,omitempty" description:"The retention policy for rule matches (omit to keep data forever)"`
+	RuleErrors struct {
+		Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+		ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+	} `json:"ruleErrors,omitempty" description:"The retention policy for rule errors (omit to keep data forever)"`
+}
+
+type PutRetentionResponse struct {
+	Record struct {
+		LogType   string    `json:"logType" description:"The log type the retention policies apply to"`
+		Revision  int64     `json:"revision" description:"Retention record revision"`
+		UpdatedAt time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		Logs      struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"logs,omitempty" description:"The retention policy for processed log data (omitted if data are kept forever)"`
+		RuleMatches struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"ruleMatches,omitempty" description:"The retention policy for rule matches (omitted if data are kept forever)"`
+		RuleErrors struct {
+			Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+			ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+		} `json:"ruleErrors,omitempty" description:"The retention policy for rule errors (omitted if data are kept forever)"`
+	} `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+type RevertCustomLogInput struct {
+	LogType    string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	Revision   int64  `json:"revision" validate:"required,min=1" description:"Custom log record revision to update"`
+	ToRevision int64  `json:"toRevision" validate:"required,min=1" description:"The revision to restore"`
+}
+
+type RevertCustomLogResponse struct {
+	Result struct {
+		Name         string    `json:"logType" dynamodbav:"logType" validate:"required" description:"The schema id"`
+		Revision     int64     `json:"revision" validate:"required,min=1" description:"Schema record revision"`
+		Release      string    `json:"release,omitempty" description:"Managed schema release version"`
+		UpdatedAt    time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+		CreatedAt    time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+		Managed      bool      `json:"managed,omitempty" description:"Schema is managed by Panther"`
+		Disabled     bool      `json:"disabled,omitempty" dynamodbav:"IsDeleted"  description:"Log record is deleted"`
+		Description  string    `json:"description" description:"Log type description"`
+		ReferenceURL string    `json:"referenceURL" description:"A URL with reference docs for the schema"`
+		Spec         string    `json:"logSpec" dynamodbav:"logSpec" validate:"required" description:"The schema spec in YAML or JSON format"`
+	} `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error struct {
+		Code    string `json:"code" validate:"required"`
+		Message string `json:"message" validate:"required"`
+	} `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
 type UpdateManagedSchemasInput struct {
 	Release     string `json:"release" validate:"required" description:"The release of the schema"`
 	ManifestURL string `json:"manifestURL,omitempty" validate:"omitempty,url" description:"The URL to download the manifest archive from"`
@@ -43,11 +43,13 @@ type LambdaInput struct {
 //     }
 // }
 type AddOutputInput struct {
-	UserID             *string       `json:"userId" validate:"required,uuid4"`
-	DisplayName        *string       `json:"displayName" validate:"required,min=1,excludesall='<>&\""`
-	OutputConfig       *OutputConfig `json:"outputConfig" validate:"required"`
-	DefaultForSeverity []*string     `json:"defaultForSeverity"`
-	AlertTypes         []string      `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	UserID             *string         `json:"userId" validate:"required,uuid4"`
+	DisplayName        *string         `json:"displayName" validate:"required,min=1,excludesall='<>&\""`
+	OutputConfig       *OutputConfig   `json:"outputConfig" validate:"required"`
+	DefaultForSeverity []*string       `json:"defaultForSeverity"`
+	AlertTypes         []string        `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	DeliveryPolicy     *DeliveryPolicy `json:"deliveryPolicy,omitempty"`
+	RoutingRules       []RoutingRule   `json:"routingRules,omitempty" validate:"omitempty,dive"`
 }
 
 // AddOutputOutput returns a randomly generated UUID for the output.
@@ -84,12 +86,14 @@ type DeleteOutputInput struct {
 //     }
 // }
 type UpdateOutputInput struct {
-	UserID             *string       `json:"userId" validate:"required,uuid4"`
-	DisplayName        *string       `json:"displayName" validate:"omitempty,min=1,excludesall='<>&\""`
-	OutputID           *string       `json:"outputId" validate:"required,uuid4"`
-	OutputConfig       *OutputConfig `json:"outputConfig"`
-	DefaultForSeverity []*string     `json:"defaultForSeverity"`
-	AlertTypes         []string      `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	UserID             *string         `json:"userId" validate:"required,uuid4"`
+	DisplayName        *string         `json:"displayName" validate:"omitempty,min=1,excludesall='<>&\""`
+	OutputID           *string         `json:"outputId" validate:"required,uuid4"`
+	OutputConfig       *OutputConfig   `json:"outputConfig"`
+	DefaultForSeverity []*string       `json:"defaultForSeverity"`
+	AlertTypes         []string        `json:"alertTypes" validate:"omitempty,dive,oneof=RULE RULE_ERROR POLICY"`
+	DeliveryPolicy     *DeliveryPolicy `json:"deliveryPolicy,omitempty"`
+	RoutingRules       []RoutingRule   `json:"routingRules,omitempty" validate:"omitempty,dive"`
 }
 
 // UpdateOutputOutput returns the new updated output
@@ -168,6 +172,94 @@ type AlertOutput struct {
 
 	// DefaultForSeverity defines the alert severities that will be forwarded through this output
 	DefaultForSeverity []*string `json:"defaultForSeverity"`
+
+	// DeliveryPolicy throttles and groups the alerts dispatched to this output
+	DeliveryPolicy *DeliveryPolicy `json:"deliveryPolicy,omitempty"`
+
+	// RoutingRules route alerts to this output
+	RoutingRules []RoutingRule `json:"routingRules,omitempty"`
+}
+
+// RoutingRule routes the alerts matching all of its conditions to an output.
+//
+// The routing rules of the outputs accepting the alert type are evaluated in order of priority
+// for alerts without destination overrides.
+// A rule without conditions matches all alerts.
+type RoutingRule struct {
+	// Priority orders the evaluation of routing rules, rules with lower priorities are evaluated first.
+	// Rules with the same priority are evaluated in order of output id and then in the order of the output rules.
+	Priority int `json:"priority"`
+
+	// LogTypes matches alerts for any of the log types
+	LogTypes []string `json:"logTypes,omitempty"`
+
+	// Tags matches alerts of detections with any of the tags
+	Tags []string `json:"tags,omitempty"`
+
+	// AnalysisIDs matches alerts of detections with an id matching any of the glob patterns (ie `AWS.CloudTrail.*`)
+	AnalysisIDs []string `json:"analysisIds,omitempty" validate:"omitempty,dive,glob"`
+
+	// ResourceTypes matches alerts for any of the resource types
+	ResourceTypes []string `json:"resourceTypes,omitempty"`
+
+	// AWSAccountIDs matches alerts for resources in any of the AWS accounts,
+	// or rule alerts with any of the accounts in the `p_any_aws_account_ids` key of their alert context
+	AWSAccountIDs []string `json:"awsAccountIds,omitempty" validate:"omitempty,dive,len=12,numeric"`
+
+	// Context matches alerts with values in their alert context
+	Context []ContextMatch `json:"context,omitempty" validate:"omitempty,dive"`
+
+	// StopProcessing stops the evaluation of routing rules when the rule matches.
+	// The alert is then not sent to the default outputs for its severity.
+	StopProcessing bool `json:"stopProcessing,omitempty"`
+}
+
+// ContextMatch matches a value in the alert context
+type ContextMatch struct {
+	// Key is the path of the value in the alert context (ie `user.name`)
+	Key string `json:"key" validate:"required"`
+
+	// Values are glob patterns matching the value or any of its elements if it is a list
+	Values []string `json:"values" validate:"min=1,dive,glob"`
+}
+
+// DeliveryPolicy defines how alerts are dispatched to an output.
+//
+// Alerts that cannot be delivered right away are put back in the alerts queue until they can.
+type DeliveryPolicy struct {
+	// RateLimit limits the number of alerts delivered to the output
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+
+	// DigestWindowMinutes groups the alerts of a detection.
+	// The first alert is delivered right away and the rest of the alerts in the window are delivered in a single digest.
+	DigestWindowMinutes int `json:"digestWindowMinutes,omitempty" validate:"omitempty,min=1,max=60"`
+
+	// QuietHours is a daily period when alerts are held until it ends
+	QuietHours *QuietHours `json:"quietHours,omitempty"`
+}
+
+// RateLimit is a token bucket limiting the alerts delivered to an output
+type RateLimit struct {
+	// AlertsPerMinute is the rate that alerts can be delivered at
+	AlertsPerMinute float64 `json:"alertsPerMinute" validate:"gt=0"`
+
+	// Burst is the number of alerts that can be delivered at once
+	Burst int `json:"burst" validate:"min=1"`
+}
+
+// QuietHours is a daily period when alerts are not delivered
+type QuietHours struct {
+	// Start is the time of day that quiet hours start (ie `22:00`)
+	Start string `json:"start" validate:"clock"`
+
+	// End is the time of day that quiet hours end (ie `07:30`), it can be earlier than Start
+	End string `json:"end" validate:"clock"`
+
+	// TimeZone is the IANA time zone of Start and End, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" validate:"omitempty,timezone"`
+
+	// Severities are delivered even during quiet hours
+	Severities []string `json:"severities,omitempty" validate:"omitempty,dive,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
 }
 
 // OutputConfig contains the configuration for the output
@@ -201,6 +293,18 @@ type OutputConfig struct {
 
 	// CustomWebhook contains the configuration for a Custom Webhook alert output
 	CustomWebhook *CustomWebhookConfig `json:"customWebhook,omitempty"`
+
+	// Email contains the configuration for Email alert output
+	Email *EmailConfig `json:"email,omitempty"`
+
+	// GoogleChat contains the configuration for Google Chat alert output
+	GoogleChat *GoogleChatConfig `json:"googleChat,omitempty"`
+
+	// Mattermost contains the configuration for Mattermost alert output
+	Mattermost *MattermostConfig `json:"mattermost,omitempty"`
+
+	// Webex contains the configuration for Webex alert output
+	Webex *WebexConfig `json:"webex,omitempty"`
 }
 
 // SlackConfig defines options for each Slack output.
@@ -260,4 +364,87 @@ type AsanaConfig struct {
 // CustomWebhookConfig defines options for each CustomWebhook output
 type CustomWebhookConfig struct {
 	WebhookURL string `json:"webhookURL" validate:"omitempty,url"`
+
+	// Method is the HTTP method of requests, defaults to POST
+	Method string `json:"method,omitempty" validate:"omitempty,oneof=POST PUT PATCH"`
+
+	// BodyTemplate is a Go text/template rendering the JSON body of requests from the alert notification.
+	// If empty, the notification is sent as is.
+	BodyTemplate string `json:"bodyTemplate,omitempty" validate:"omitempty,webhookTemplate"`
+
+	// Headers are added to all requests
+	Headers []CustomWebhookHeader `json:"headers,omitempty" validate:"omitempty,dive"`
+
+	// SignatureHeader is the name of a header with the HMAC-SHA256 signature of the request body
+	SignatureHeader string `json:"signatureHeader,omitempty" validate:"omitempty,httpHeader"`
+
+	// SignatureSecret is the key used to sign the request body
+	SignatureSecret string `json:"signatureSecret,omitempty"`
+}
+
+// CustomWebhookHeader is an HTTP header added to CustomWebhook requests
+type CustomWebhookHeader struct {
+	Name  string `json:"name" validate:"httpHeader"`
+	Value string `json:"value" validate:"httpHeaderValue"`
+
+	// Secret header values (ie bearer tokens) are not returned by the API
+	Secret bool `json:"secret,omitempty"`
+}
+
+// Transports of Email outputs
+const (
+	EmailTransportSES  = "ses"
+	EmailTransportSMTP = "smtp"
+)
+
+// TLS modes of SMTP connections
+const (
+	// SMTPStartTLS upgrades plain connections with STARTTLS (ie on port 587)
+	SMTPStartTLS = "STARTTLS"
+	// SMTPImplicitTLS connects over TLS (ie on port 465)
+	SMTPImplicitTLS = "TLS"
+)
+
+// EmailConfig defines options for each Email output
+type EmailConfig struct {
+	// From is the sender address of the emails
+	From string `json:"from" validate:"omitempty,email"`
+	// To are the recipient addresses of the emails
+	To []string `json:"to" validate:"omitempty,dive,email"`
+
+	// Transport sends emails with Amazon SES (the default) or an SMTP server
+	Transport string `json:"transport,omitempty" validate:"omitempty,oneof=ses smtp"`
+
+	// SESRegion is the region of the SES identity of the sender, defaults to the Panther region
+	SESRegion string `json:"sesRegion,omitempty"`
+
+	// SMTPHost and SMTPPort are the address of the SMTP server, the port defaults to 587
+	SMTPHost string `json:"smtpHost,omitempty" validate:"omitempty,hostname|ip"`
+	SMTPPort int    `json:"smtpPort,omitempty" validate:"omitempty,min=1,max=65535"`
+	// SMTPTLS is the TLS mode of SMTP connections, defaults to STARTTLS. Emails are never sent in plain text.
+	SMTPTLS string `json:"smtpTls,omitempty" validate:"omitempty,oneof=STARTTLS TLS"`
+	// SMTPUserName and SMTPPassword authenticate to the SMTP server, if set
+	SMTPUserName string `json:"smtpUserName,omitempty"`
+	SMTPPassword string `json:"smtpPassword,omitempty"`
+}
+
+// GoogleChatConfig defines options for each Google Chat output
+type GoogleChatConfig struct {
+	WebhookURL string `json:"webhookURL" validate:"omitempty,url"` // https://chat.googleapis.com/v1/spaces/...
+}
+
+// MattermostConfig defines options for each Mattermost output
+type MattermostConfig struct {
+	WebhookURL string `json:"webhookURL" validate:"omitempty,url"` // https://mattermost.example.com/hooks/...
+
+	// Channel overrides the default channel of the incoming webhook
+	Channel string `json:"channel,omitempty"`
+}
+
+// WebexConfig defines options for each Webex output
+type WebexConfig struct {
+	// BotToken is the access token of the Webex bot posting the alerts
+	BotToken string `json:"botToken"`
+	// RoomID is the space the alerts are posted to, the bot must be a member of it
+	RoomID string `json:"roomId"`
 }
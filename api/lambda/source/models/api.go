@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
 )
 
 // LambdaInput is the collection of all possible args to the Lambda function.
@@ -51,7 +53,7 @@ type LambdaInput struct {
 // CheckIntegrationInput is used to check the health of a potential configuration.
 type CheckIntegrationInput struct {
 	AWSAccountID     string `genericapi:"redact" json:"awsAccountId" validate:"omitempty,len=12,numeric"`
-	IntegrationType  string `json:"integrationType" validate:"oneof=aws-scan aws-s3 aws-sqs"`
+	IntegrationType  string `json:"integrationType" validate:"oneof=aws-scan aws-s3 aws-sqs http-push"`
 	IntegrationLabel string `json:"integrationLabel" validate:"required,integrationLabel"`
 
 	// Checks for cloudsec integrations
@@ -67,6 +69,9 @@ type CheckIntegrationInput struct {
 	// Checks for Sqs configuration
 	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
 
+	// Checks for HTTP push configuration
+	HTTPPushConfig *HTTPPushConfig `json:"httpPushConfig,omitempty"`
+
 	// PantherVersion is the version of Panther that the source was created with. Must follow semver format.
 	PantherVersionStr string `json:"pantherVersion"`
 }
@@ -91,7 +96,7 @@ type PutIntegrationInput struct {
 // PutIntegrationSettings are all the settings for the new integration.
 type PutIntegrationSettings struct {
 	IntegrationLabel           string           `json:"integrationLabel" validate:"required,integrationLabel,excludesall='<>&\""`
-	IntegrationType            string           `json:"integrationType" validate:"oneof=aws-scan aws-s3 aws-sqs"`
+	IntegrationType            string           `json:"integrationType" validate:"oneof=aws-scan aws-s3 aws-sqs http-push"`
 	UserID                     string           `json:"userId" validate:"required,uuid4"`
 	AWSAccountID               string           `genericapi:"redact" json:"awsAccountId" validate:"omitempty,len=12,numeric"`
 	CWEEnabled                 *bool            `json:"cweEnabled"`
@@ -106,7 +111,9 @@ type PutIntegrationSettings struct {
 	KmsKey                     string           `json:"kmsKey" validate:"omitempty,kmsKeyArn"`
 	ManagedBucketNotifications bool             `json:"managedBucketNotifications"`
 
-	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
+	SqsConfig      *SqsConfig         `json:"sqsConfig,omitempty"`
+	HTTPPushConfig *HTTPPushConfig    `json:"httpPushConfig,omitempty"`
+	EventFilters   []eventfilter.Rule `json:"eventFilters,omitempty" validate:"omitempty,dive"`
 }
 
 //
@@ -115,7 +122,7 @@ type PutIntegrationSettings struct {
 
 // ListIntegrationsInput allows filtering by the IntegrationType field
 type ListIntegrationsInput struct {
-	IntegrationType *string `json:"integrationType" validate:"omitempty,oneof=aws-scan aws-s3 aws-sqs"`
+	IntegrationType *string `json:"integrationType" validate:"omitempty,oneof=aws-scan aws-s3 aws-sqs http-push"`
 }
 
 // UpdateIntegrationSettingsInput is used to update integration settings.
@@ -133,7 +140,9 @@ type UpdateIntegrationSettingsInput struct {
 	S3PrefixLogTypes        S3PrefixLogtypes `json:"s3PrefixLogTypes,omitempty" validate:"omitempty,min=1"`
 	KmsKey                  string           `json:"kmsKey" validate:"omitempty,kmsKeyArn"`
 
-	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
+	SqsConfig      *SqsConfig         `json:"sqsConfig,omitempty"`
+	HTTPPushConfig *HTTPPushConfig    `json:"httpPushConfig,omitempty"`
+	EventFilters   []eventfilter.Rule `json:"eventFilters,omitempty" validate:"omitempty,dive"`
 }
 
 // DeleteIntegrationInput is used to delete a specific item from the database.
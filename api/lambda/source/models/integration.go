@@ -19,12 +19,18 @@ package models
  */
 
 import (
+	"crypto/subtle"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/panther-labs/panther/internal/compliance/snapshotlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
 	"github.com/panther-labs/panther/pkg/stringset"
 )
 
@@ -84,6 +90,11 @@ type SourceIntegrationMetadata struct {
 
 	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
 
+	HTTPPushConfig *HTTPPushConfig `json:"httpPushConfig,omitempty"`
+
+	// Optional rules to drop or sample events of log sources before they are stored in the data lake
+	EventFilters []eventfilter.Rule `json:"eventFilters,omitempty"`
+
 	// PantherVersion is the version of Panther that the source was created with.
 	PantherVersion string `json:"pantherVersion,omitempty"`
 }
@@ -102,6 +113,33 @@ type ManagedS3Resources struct {
 type S3PrefixLogtypesMapping struct {
 	S3Prefix string   `json:"prefix"`
 	LogTypes []string `json:"logTypes" validate:"required,min=1"`
+	// Optional configuration to assemble multi-line log entries (ie stack traces) for objects under this prefix
+	MultiLine *logstream.MultiLineConfig `json:"multiLine,omitempty"`
+	// Optional configuration to read objects under this prefix as a stream of JSON values (ie pretty-printed JSON)
+	JSONStream *logstream.JSONStreamConfig `json:"jsonStream,omitempty"`
+	// Optional hints for the log type of objects under this prefix based on their key
+	LogTypeHints []LogTypeHint `json:"logTypeHints,omitempty"`
+	// If set, the log type of the first classified line of an object is used for the rest of the object
+	StickyLogType bool `json:"stickyLogType,omitempty"`
+}
+
+// LogTypeHint hints the log type of S3 objects whose key matches a pattern.
+// Hinted log types are tried first when classifying the lines of an object.
+type LogTypeHint struct {
+	// KeyPattern is a regular expression matched against the object key
+	KeyPattern string `json:"keyPattern" validate:"required"`
+	LogType    string `json:"logType" validate:"required"`
+}
+
+// HintedLogTypes returns the log types hinted for an object key
+func (m *S3PrefixLogtypesMapping) HintedLogTypes(objectKey string) (logTypes []string) {
+	for _, hint := range m.LogTypeHints {
+		// Patterns are checked on save, an invalid pattern here means the hint should be ignored
+		if match, _ := regexp.MatchString(hint.KeyPattern, objectKey); match {
+			logTypes = stringset.Append(logTypes, hint.LogType)
+		}
+	}
+	return logTypes
 }
 
 type S3PrefixLogtypes []S3PrefixLogtypesMapping
@@ -114,6 +152,34 @@ func (pl S3PrefixLogtypes) LogTypes() []string {
 	return logTypes
 }
 
+// Validate checks the optional settings of each mapping
+func (pl S3PrefixLogtypes) Validate() error {
+	for _, m := range pl {
+		if m.MultiLine != nil && m.JSONStream != nil {
+			return errors.Errorf("prefix %q cannot be configured both for multi-line and JSON stream", m.S3Prefix)
+		}
+		if m.MultiLine != nil {
+			if err := m.MultiLine.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid multi-line configuration for prefix %q", m.S3Prefix)
+			}
+		}
+		if m.JSONStream != nil {
+			if err := m.JSONStream.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid JSON stream configuration for prefix %q", m.S3Prefix)
+			}
+		}
+		for _, hint := range m.LogTypeHints {
+			if _, err := regexp.Compile(hint.KeyPattern); err != nil {
+				return errors.Wrapf(err, "invalid log type hint pattern for prefix %q", m.S3Prefix)
+			}
+			if !stringset.Contains(m.LogTypes, hint.LogType) {
+				return errors.Errorf("hinted log type %q is not a log type of prefix %q", hint.LogType, m.S3Prefix)
+			}
+		}
+	}
+	return nil
+}
+
 func (pl S3PrefixLogtypes) S3Prefixes() []string {
 	prefixes := make([]string, len(pl))
 	for i, m := range pl {
@@ -143,6 +209,8 @@ func (s *SourceIntegration) RequiredLogTypes() (logTypes []string) {
 		return s.S3PrefixLogTypes.LogTypes()
 	case IntegrationTypeSqs:
 		return s.SqsConfig.LogTypes
+	case IntegrationTypeHTTPPush:
+		return s.HTTPPushConfig.LogTypes
 	default:
 		// should not be reached
 		panic(fmt.Sprintf("Could not determine logtypes for source {id:%s label:%s type:%s}",
@@ -156,6 +224,9 @@ func (s *SourceIntegration) RequiredLogProcessingRole() string {
 		return s.LogProcessingRole
 	case IntegrationTypeSqs:
 		return s.SqsConfig.LogProcessingRole
+	case IntegrationTypeHTTPPush:
+		// Data is pushed to Panther directly, there is no role to assume
+		return ""
 	default:
 		panic("Unknown type " + typ)
 	}
@@ -171,6 +242,9 @@ func (s *SourceIntegration) S3Info() (bucket string, prefixes []string) {
 		return s.S3Bucket, s.S3PrefixLogTypes.S3Prefixes()
 	case IntegrationTypeSqs:
 		return s.SqsConfig.S3Bucket, []string{"forwarder"}
+	case IntegrationTypeHTTPPush:
+		// Data is received over HTTP and is not read from S3
+		return "", nil
 	default:
 		// should not be reached
 		panic(fmt.Sprintf("Could not determine s3 info for source {id:%s label:%s type:%s}",
@@ -199,6 +273,9 @@ type SourceIntegrationHealth struct {
 
 	// Checks for Sqs integrations
 	SqsStatus SourceIntegrationItemStatus `json:"sqsStatus"`
+
+	// Checks for HTTP push integrations
+	HTTPPushStatus SourceIntegrationItemStatus `json:"httpPushStatus"`
 }
 
 type SourceIntegrationItemStatus struct {
@@ -227,3 +304,20 @@ type SqsConfig struct {
 	// THe URL of the SQS queue
 	QueueURL string `json:"queueUrl"`
 }
+
+type HTTPPushConfig struct {
+	// The log types associated with the source. Needs to be set by UI.
+	LogTypes []string `json:"logTypes" validate:"required,min=1"`
+	// The token that senders need to provide in the Authorization header of each request.
+	// It is generated by Panther when the source is created.
+	AuthToken string `json:"authToken" genericapi:"redact"`
+}
+
+// Authorize checks if a token presented by a sender matches the source's auth token.
+// The comparison is performed in constant time.
+func (c *HTTPPushConfig) Authorize(token string) bool {
+	if c == nil || c.AuthToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.AuthToken), []byte(token)) == 1
+}
@@ -22,13 +22,15 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
 )
 
 func TestS3PrefixLogtypes_LongestPrefixMatch(t *testing.T) {
 	pl := S3PrefixLogtypes{
-		{"prefixA/", []string{"Log.A"}},
-		{"prefixA/prefixB", []string{"Log.B"}},
-		{"", []string{"Log.C"}},
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}},
+		{S3Prefix: "prefixA/prefixB", LogTypes: []string{"Log.B"}},
+		{S3Prefix: "", LogTypes: []string{"Log.C"}},
 	}
 
 	testcases := []struct {
@@ -50,8 +52,8 @@ func TestS3PrefixLogtypes_LongestPrefixMatch(t *testing.T) {
 
 func TestS3PrefixLogtypes_LongestPrefixMatch_ReturnNil(t *testing.T) {
 	pl := S3PrefixLogtypes{
-		{"prefixA/", []string{"Log.A"}},
-		{"prefixA/prefixB", []string{"Log.B"}},
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}},
+		{S3Prefix: "prefixA/prefixB", LogTypes: []string{"Log.B"}},
 	}
 
 	_, matched := pl.LongestPrefixMatch("logs/log.json")
@@ -59,3 +61,61 @@ func TestS3PrefixLogtypes_LongestPrefixMatch_ReturnNil(t *testing.T) {
 	// No prefix matched
 	require.False(t, matched)
 }
+
+func TestHTTPPushConfig_Authorize(t *testing.T) {
+	config := &HTTPPushConfig{
+		LogTypes:  []string{"Log.A"},
+		AuthToken: "secret",
+	}
+	require.True(t, config.Authorize("secret"))
+	require.False(t, config.Authorize("Secret"))
+	require.False(t, config.Authorize(""))
+
+	// Sources without a token should never authorize requests
+	require.False(t, (&HTTPPushConfig{}).Authorize(""))
+	var nilConfig *HTTPPushConfig
+	require.False(t, nilConfig.Authorize("secret"))
+}
+
+func TestS3PrefixLogtypes_Validate(t *testing.T) {
+	require.NoError(t, S3PrefixLogtypes{
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}},
+		{S3Prefix: "prefixB/", LogTypes: []string{"Log.B"}, JSONStream: &logstream.JSONStreamConfig{Path: "data.events"}},
+		{S3Prefix: "prefixC/", LogTypes: []string{"Log.C"}, MultiLine: &logstream.MultiLineConfig{StartPattern: `^\d{4}`}},
+		{S3Prefix: "prefixD/", LogTypes: []string{"Log.D", "Log.E"}, LogTypeHints: []LogTypeHint{{KeyPattern: `/e/`, LogType: "Log.E"}}},
+	}.Validate())
+	require.Error(t, S3PrefixLogtypes{
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}, LogTypeHints: []LogTypeHint{{KeyPattern: `(`, LogType: "Log.A"}}},
+	}.Validate())
+	require.Error(t, S3PrefixLogtypes{
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}, LogTypeHints: []LogTypeHint{{KeyPattern: `/b/`, LogType: "Log.B"}}},
+	}.Validate())
+	require.Error(t, S3PrefixLogtypes{
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}, JSONStream: &logstream.JSONStreamConfig{Path: "data..events"}},
+	}.Validate())
+	require.Error(t, S3PrefixLogtypes{
+		{S3Prefix: "prefixA/", LogTypes: []string{"Log.A"}, MultiLine: &logstream.MultiLineConfig{StartPattern: `(`}},
+	}.Validate())
+	require.Error(t, S3PrefixLogtypes{
+		{
+			S3Prefix:   "prefixA/",
+			LogTypes:   []string{"Log.A"},
+			MultiLine:  &logstream.MultiLineConfig{StartPattern: `^\d{4}`},
+			JSONStream: &logstream.JSONStreamConfig{},
+		},
+	}.Validate())
+}
+
+func TestS3PrefixLogtypesMapping_HintedLogTypes(t *testing.T) {
+	m := S3PrefixLogtypesMapping{
+		S3Prefix: "logs/",
+		LogTypes: []string{"Log.A", "Log.B"},
+		LogTypeHints: []LogTypeHint{
+			{KeyPattern: `^logs/a/`, LogType: "Log.A"},
+			{KeyPattern: `\.b\.gz$`, LogType: "Log.B"},
+		},
+	}
+	require.Equal(t, []string{"Log.A"}, m.HintedLogTypes("logs/a/1.json"))
+	require.Equal(t, []string{"Log.A", "Log.B"}, m.HintedLogTypes("logs/a/1.b.gz"))
+	require.Empty(t, m.HintedLogTypes("logs/c/1.json"))
+}
@@ -25,6 +25,8 @@ const (
 	IntegrationTypeAWS3 = "aws-s3"
 	// IntegrationTypeSqs is integration type for pulling data from an SQS queue.
 	IntegrationTypeSqs = "aws-sqs"
+	// IntegrationTypeHTTPPush is the integration type for receiving data pushed over HTTP.
+	IntegrationTypeHTTPPush = "http-push"
 
 	// StatusError is the string set in the database when an error occurs in a scan.
 	StatusError = "error"
@@ -18,6 +18,10 @@ package models
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
+// SystemUserID is the id of the Panther system user, used for changes made by Panther itself.
+// The web app displays it as Panther (see PANTHER_USER_ID).
+const SystemUserID = "00000000-0000-4000-8000-000000000000"
+
 // User describes a Panther user
 type User struct {
 	CreatedAt  *int64  `json:"createdAt"`
@@ -0,0 +1,166 @@
+package customlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+type DryRunOpts struct {
+	Schema    *string
+	Current   *string
+	LogType   *string
+	SourceID  *string
+	Start     *string
+	End       *string
+	MaxEvents *int
+}
+
+// DryRun reports how a draft schema parses a sample of logs without storing any events.
+// Logs are read from local files or, if a source id is specified, from the source's S3 bucket using the log types API.
+func DryRun(logger *zap.SugaredLogger, opts *DryRunOpts) {
+	schemaFile := *opts.Schema
+	if schemaFile == "" {
+		flag.Usage()
+		logger.Fatal("no schema file provided")
+	}
+	var report *customlogs.DryRunReport
+	if *opts.SourceID != "" {
+		report = dryRunSource(logger, opts)
+	} else {
+		report = dryRunFiles(logger, opts)
+	}
+	out, err := yaml.Marshal(report)
+	if err != nil {
+		logger.Fatalf("failed to marshal report: %s", err)
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		logger.Fatalf("failed to write report: %s", err)
+	}
+}
+
+func dryRunFiles(logger *zap.SugaredLogger, opts *DryRunOpts) *customlogs.DryRunReport {
+	schema, err := readSchemaFile(*opts.Schema)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	var current *logschema.Schema
+	if *opts.Current != "" {
+		if current, err = readSchemaFile(*opts.Current); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	dryRun, err := customlogs.NewDryRun(*opts.LogType, schema)
+	if err != nil {
+		logger.Fatalf("failed to build schema %q: %s", *opts.Schema, err)
+	}
+	dryRun.MaxEvents = *opts.MaxEvents
+	inputFiles := flag.Args()
+	if len(inputFiles) == 0 {
+		inputFiles = []string{"-"}
+	}
+	for _, inputFile := range inputFiles {
+		if dryRun.Full() {
+			break
+		}
+		f := os.Stdin
+		if inputFile != "-" {
+			if f, err = os.Open(inputFile); err != nil {
+				logger.Fatalf("failed to open input file %q: %s", inputFile, err)
+			}
+		}
+		err = dryRun.ParseStream(logstream.NewLineStream(f, logstream.DefaultBufferSize))
+		_ = f.Close()
+		if err != nil {
+			logger.Fatalf("failed to read input file %q: %s", inputFile, err)
+		}
+	}
+	report, err := dryRun.Report(current)
+	if err != nil {
+		logger.Fatalf("failed to diff schema columns: %s", err)
+	}
+	return report
+}
+
+func dryRunSource(logger *zap.SugaredLogger, opts *DryRunOpts) *customlogs.DryRunReport {
+	spec, err := ioutil.ReadFile(*opts.Schema)
+	if err != nil {
+		logger.Fatalf("failed to load schema file %q: %s", *opts.Schema, err)
+	}
+	input := logtypesapi.DryRunCustomLogInput{
+		LogType:   *opts.LogType,
+		Spec:      string(spec),
+		SourceID:  *opts.SourceID,
+		MaxEvents: *opts.MaxEvents,
+	}
+	if *opts.Start != "" {
+		if input.StartTime, err = time.Parse(time.RFC3339, *opts.Start); err != nil {
+			logger.Fatalf("failed to parse %q flag: %s", "start", err)
+		}
+	}
+	if *opts.End != "" {
+		if input.EndTime, err = time.Parse(time.RFC3339, *opts.End); err != nil {
+			logger.Fatalf("failed to parse %q flag: %s", "end", err)
+		}
+	}
+	sess, err := session.NewSession()
+	if err != nil {
+		logger.Fatalf("failed to build AWS session: %s", err)
+	}
+	client := logtypesapi.LogTypesAPILambdaClient{
+		LambdaName: logtypesapi.LambdaName,
+		LambdaAPI:  lambda.New(sess),
+	}
+	reply, err := client.DryRunCustomLog(context.Background(), &input)
+	if err != nil {
+		logger.Fatalf("dry run failed: %s", err)
+	}
+	if reply.Error != nil {
+		logger.Fatalf("dry run failed: %s", reply.Error)
+	}
+	logger.Infof("Read %d objects from source %q", reply.NumObjects, input.SourceID)
+	return reply.Report
+}
+
+func readSchemaFile(filename string) (*logschema.Schema, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load schema file %q", filename)
+	}
+	schema := logschema.Schema{}
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse schema file as YAML %q", filename)
+	}
+	return &schema, nil
+}
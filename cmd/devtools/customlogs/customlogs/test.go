@@ -56,6 +56,9 @@ func Test(logger *zap.SugaredLogger, opts *TestOpts) {
 	if err := yaml.Unmarshal(schemaData, &schema); err != nil {
 		logger.Fatalf("failed to parse schema file as YAML %q: %s", schemaFile, err)
 	}
+	if err := customlogs.RegisterIndicators("Custom.Test", schema.Indicators...); err != nil {
+		logger.Fatalf("Failed to register indicators of schema %q: %s\n", schemaFile, err)
+	}
 	entry, err := customlogs.Build("Custom.Test", &schema)
 	if err != nil {
 		validationErrors := logschema.ValidationErrors(err)
@@ -28,14 +28,16 @@ import (
 
 	"github.com/panther-labs/panther/cmd/devtools/customlogs/customlogs"
 	"github.com/panther-labs/panther/cmd/opstools"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
 )
 
 // CLI commands
 const testCmd = "test"
 const inferCmd = "infer"
+const dryRunCmd = "dryrun"
 
 func main() {
-	opstools.SetUsage(strings.Join([]string{testCmd, inferCmd}, ","))
+	opstools.SetUsage(strings.Join([]string{testCmd, inferCmd, dryRunCmd}, ","))
 
 	loggerConfig := zap.NewDevelopmentConfig()
 	loggerConfig.DisableStacktrace = true
@@ -51,6 +53,11 @@ func main() {
 		logger.Fatalf("Need to provide one command")
 	}
 
+	// Parsed events are not stored, hash masks use a random key
+	if err := transforms.SetRandomHashKey(); err != nil {
+		logger.Fatal(err)
+	}
+
 	switch cmd := os.Args[1]; cmd {
 	case testCmd:
 		opstools.SetUsage(`-s SCHEMA_FILE [-o OUTPUT_FILE] [INPUT_FILES...]`)
@@ -73,6 +80,22 @@ func main() {
 			flag.Usage()
 		}
 		customlogs.Infer(logger.Desugar(), opts)
+	case dryRunCmd:
+		opstools.SetUsage(`-s SCHEMA_FILE [-c CURRENT_SCHEMA_FILE] [INPUT_FILES...] | -s SCHEMA_FILE -source-id SOURCE_ID [-start TIME] [-end TIME]`)
+		opts := &customlogs.DryRunOpts{
+			Schema:    flag.String("s", "", "Schema file"),
+			Current:   flag.String("c", "", "Current schema file to diff Glue columns against"),
+			LogType:   flag.String("log-type", "Custom.Test", "The name of the log type"),
+			SourceID:  flag.String("source-id", "", "Read logs from the S3 bucket of the source with this id instead of input files"),
+			Start:     flag.String("start", "", "Only read S3 objects modified after this time (RFC3339)"),
+			End:       flag.String("end", "", "Only read S3 objects modified before this time (RFC3339)"),
+			MaxEvents: flag.Int("max-events", 0, "Maximum number of log entries to parse"),
+		}
+		if err := flag.CommandLine.Parse(os.Args[2:]); err != nil {
+			logger.Fatalf("failed to parse command line arguments")
+			flag.Usage()
+		}
+		customlogs.DryRun(logger, opts)
 	default:
 		logger.Fatalf("Invalid command [%s]", cmd)
 		flag.Usage()
@@ -0,0 +1,282 @@
+package deadletters
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+)
+
+var jsonAPI = common.ConfigForDataLakeWriters()
+
+// Replay re-processes log lines that failed to classify using the current log type parsers.
+// Each replayed object is replaced by a new object holding only the lines that failed to classify again.
+type Replay struct {
+	S3Client s3iface.S3API
+	Bucket   string
+	Start    time.Time
+	End      time.Time
+	// SourceID limits the replay to the lines of a single source
+	SourceID string
+	// Resolver resolves the parsers of log types
+	Resolver pantherlog.ParserResolver
+	// Extensions enrich, normalize and redact the events of replayed lines the same way as the log processor
+	Extensions *processor.Extensions
+	// LoadSource loads the configuration of a source
+	LoadSource func(id string) (*models.SourceIntegration, error)
+	// NewDestination creates the destination for the events of classified lines
+	NewDestination func() destinations.Destination
+	// DeadLetters writes the lines that are not replayed, if nil the replay is a dry run and no objects are deleted
+	DeadLetters *deadletter.Writer
+	Logger      *zap.Logger
+	Stats       Stats
+}
+
+type Stats struct {
+	NumObjects int
+	NumLines   int
+	NumFailed  int
+	NumSkipped int
+}
+
+func (r *Replay) Run(ctx context.Context) error {
+	// Collect all keys before replaying since lines that fail again are written to the current hour partition
+	keys, err := r.listObjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := r.replayObject(ctx, key); err != nil {
+			return errors.Wrapf(err, "failed to replay s3://%s/%s", r.Bucket, key)
+		}
+		r.Stats.NumObjects++
+	}
+	return nil
+}
+
+func (r *Replay) listObjects(ctx context.Context) (keys []string, err error) {
+	tbl := deadletter.GlueTableMetadata()
+	for hour := r.Start.UTC().Truncate(time.Hour); hour.Before(r.End); hour = hour.Add(time.Hour) {
+		input := s3.ListObjectsV2Input{
+			Bucket: aws.String(r.Bucket),
+			Prefix: aws.String(tbl.PartitionPrefix(hour)),
+		}
+		err := r.S3Client.ListObjectsV2PagesWithContext(ctx, &input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+			for _, obj := range page.Contents {
+				keys = append(keys, aws.StringValue(obj.Key))
+			}
+			return true
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list dead letters for %s", hour)
+		}
+	}
+	return keys, nil
+}
+
+func (r *Replay) replayObject(ctx context.Context, key string) error {
+	entries, err := r.readEntries(ctx, key)
+	if err != nil {
+		return err
+	}
+	r.Logger.Debug("replaying dead letters", zap.String("key", key), zap.Int("numLines", len(entries)))
+
+	// Group lines by their input so that log type hints of S3 sources apply
+	type inputKey struct {
+		SourceID, S3Bucket, S3ObjectKey string
+	}
+	var streams []*entryStream
+	index := make(map[inputKey]*entryStream)
+	for _, entry := range entries {
+		// Lines of log types with redaction rules are not stored, only their entries are kept
+		if entry.Line == "" || (r.SourceID != "" && entry.SourceID != r.SourceID) {
+			if err := r.skip(entry); err != nil {
+				return err
+			}
+			continue
+		}
+		k := inputKey{entry.SourceID, entry.S3Bucket, entry.S3ObjectKey}
+		stream, ok := index[k]
+		if !ok {
+			stream = &entryStream{}
+			index[k] = stream
+			streams = append(streams, stream)
+		}
+		stream.entries = append(stream.entries, entry)
+	}
+
+	sink := &replaySink{writer: r.DeadLetters}
+	dataStreams := make(chan *common.DataStream, len(streams))
+	for _, stream := range streams {
+		first := stream.entries[0]
+		src, err := r.LoadSource(first.SourceID)
+		if err != nil {
+			r.Logger.Warn("failed to load source, skipping its lines", zap.String("sourceId", first.SourceID), zap.Error(err))
+			for _, entry := range stream.entries {
+				if err := r.skip(entry); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		stream.sink = sink
+		dataStreams <- &common.DataStream{
+			Stream:      stream,
+			Source:      src,
+			S3Bucket:    first.S3Bucket,
+			S3ObjectKey: first.S3ObjectKey,
+		}
+		r.Stats.NumLines += len(stream.entries)
+	}
+	close(dataStreams)
+
+	redactor, err := r.Extensions.Redactor(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load redaction rules")
+	}
+	enricher, normalizer := r.Extensions.Load(ctx)
+	newProcessor := processor.NewFactoryWithDeadLetters(r.Resolver, sink, redactor).WithEnricher(enricher).WithNormalizer(normalizer)
+	if err := processor.Process(ctx, dataStreams, r.NewDestination(), newProcessor); err != nil {
+		return err
+	}
+	r.Stats.NumFailed += sink.numFailed
+	if err := r.DeadLetters.Flush(); err != nil {
+		return err
+	}
+	if r.DeadLetters == nil {
+		return nil
+	}
+	_, err = r.S3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(r.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// skip keeps an entry in the dead letters table without replaying it
+func (r *Replay) skip(entry *deadletter.Entry) error {
+	r.Stats.NumSkipped++
+	return r.DeadLetters.Write(entry)
+}
+
+func (r *Replay) readEntries(ctx context.Context, key string) ([]*deadletter.Entry, error) {
+	obj, err := r.S3Client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Body.Close()
+	gz, err := gzip.NewReader(obj.Body)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*deadletter.Entry
+	lines := bufio.NewReader(gz)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			entry := deadletter.Entry{}
+			if err := jsonAPI.Unmarshal(line, &entry); err != nil {
+				return nil, errors.Wrap(err, "invalid dead letter entry")
+			}
+			entries = append(entries, &entry)
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// entryStream is a log stream over the lines of dead letter entries
+type entryStream struct {
+	entries []*deadletter.Entry
+	sink    *replaySink
+	pos     int
+}
+
+func (s *entryStream) Next() []byte {
+	if s.pos == 0 {
+		// Streams are processed serially so the sink can map line numbers to the original entries
+		s.sink.current = s
+	}
+	if s.pos >= len(s.entries) {
+		return nil
+	}
+	entry := s.entries[s.pos]
+	s.pos++
+	return []byte(entry.Line)
+}
+
+func (s *entryStream) Err() error {
+	return nil
+}
+
+// replaySink captures the lines that fail to classify again
+type replaySink struct {
+	writer    *deadletter.Writer
+	current   *entryStream
+	numFailed int
+}
+
+func (s *replaySink) Write(entry *deadletter.Entry) error {
+	s.numFailed++
+	// The processor counts lines of the replayed stream, restore the line number in the original input
+	if s.current != nil && entry.LineNumber > 0 && entry.LineNumber <= uint64(len(s.current.entries)) {
+		entry.LineNumber = s.current.entries[entry.LineNumber-1].LineNumber
+	}
+	return s.writer.Write(entry)
+}
+
+func (s *replaySink) Flush() error {
+	return s.writer.Flush()
+}
+
+// DiscardDestination counts the events of classified lines without writing them, it is used for dry runs
+type DiscardDestination struct {
+	NumEvents int
+}
+
+func (d *DiscardDestination) SendEvents(_ context.Context, events chan *parsers.Result, _ chan error) {
+	for range events {
+		d.NumEvents++
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/cmd/opstools"
+	"github.com/panther-labs/panther/cmd/opstools/deadletters"
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	logmetrics "github.com/panther-labs/panther/internal/log_analysis/log_processor/metrics"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/sources"
+	"github.com/panther-labs/panther/pkg/awscfn"
+	"github.com/panther-labs/panther/tools/cfnstacks"
+)
+
+var (
+	version string // we expect this to be set by the build tool as `-X main.version=<some version>`
+)
+
+func main() {
+	opstools.SetUsage("replays log lines that failed to classify using the current log type parsers (Panther version %s)", version)
+	opts := struct {
+		MasterStack    *string
+		End            *string
+		Start          *string
+		SourceID       *string
+		DryRun         *bool
+		Debug          *bool
+		Region         *string
+		MaxConnections *int
+		MaxRetries     *int
+		MemorySize     *int
+	}{
+		MasterStack: flag.String("master-stack", "",
+			"if set, this is the name of the Panther master stack used to deploy, if not set the deployment is assumed from source"),
+		Start:          flag.String("start", "", "Replay lines that failed to classify after this date YYYY-MM-DD"),
+		End:            flag.String("end", "", "Replay lines that failed to classify until this date YYYY-MM-DD (defaults to now)"),
+		SourceID:       flag.String("source-id", "", "Only replay lines of the source with this id"),
+		DryRun:         flag.Bool("dry-run", false, "Classify the lines and report the results without applying any changes"),
+		Debug:          flag.Bool("debug", false, "Enable additional logging"),
+		Region:         flag.String("region", "", "Set the AWS region to run on"),
+		MaxRetries:     flag.Int("max-retries", 12, "Max retries for AWS requests"),
+		MaxConnections: flag.Int("max-connections", 100, "Max number of connections to AWS"),
+		MemorySize:     flag.Int("memory", 1024, "The memory in MB to use for buffering events"),
+	}
+	flag.Parse()
+
+	log := opstools.MustBuildLogger(*opts.Debug)
+	// The log processor logs using the global logger
+	zap.ReplaceGlobals(log.Desugar())
+
+	if *opts.Start == "" {
+		log.Fatalf("%q flag is required", "start")
+	}
+	start, err := parseDate(*opts.Start)
+	if err != nil {
+		log.Fatalf("failed to parse %q flag: %s", "start", err)
+	}
+	end := time.Now().UTC()
+	if opt := *opts.End; opt != "" {
+		tm, err := parseDate(opt)
+		if err != nil {
+			log.Fatalf("failed to parse %q flag: %s", "end", err)
+		}
+		end = tm
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:     opts.Region,
+		MaxRetries: opts.MaxRetries,
+		HTTPClient: opstools.NewHTTPClient(*opts.MaxConnections, 0),
+	})
+	if err != nil {
+		log.Fatalf("failed to build AWS session: %s", err)
+	}
+
+	opstools.ValidatePantherVersion(sess, log, *opts.MasterStack, version)
+
+	bootstrapStack, err := cfnstacks.GetBootstrapStack(cloudformation.New(sess), *opts.MasterStack)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputs, err := awscfn.StackOutputs(cloudformation.New(sess), bootstrapStack)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Set up the globals used by the log processor components
+	common.Session = sess
+	common.LambdaClient = lambda.New(sess)
+	common.GlueClient = glue.New(sess)
+	common.S3Client = s3.New(sess)
+	common.SnsClient = sns.New(sess)
+	common.Config = common.EnvConfig{
+		AwsLambdaFunctionMemorySize: *opts.MemorySize,
+		ProcessedDataBucket:         outputs["ProcessedDataBucket"],
+		SnsTopicARN:                 outputs["ProcessedDataTopicArn"],
+	}
+	logmetrics.Setup()
+
+	resolver := &logtypesapi.Resolver{
+		LogTypesAPI: &logtypesapi.LogTypesAPILambdaClient{
+			LambdaName: logtypesapi.LambdaName,
+			LambdaAPI:  common.LambdaClient,
+			Validate:   validator.New().Struct,
+		},
+		NativeLogTypes: registry.NativeLogTypes(),
+	}
+
+	dryRunDestination := &deadletters.DiscardDestination{}
+	// Replayed events are enriched, normalized and redacted the same way as the events of the log processor
+	replay := deadletters.Replay{
+		S3Client:   common.S3Client,
+		Bucket:     common.Config.ProcessedDataBucket,
+		Start:      start,
+		End:        end,
+		SourceID:   *opts.SourceID,
+		Resolver:   logtypes.ParserResolver(resolver),
+		Extensions: processor.NewExtensions(),
+		LoadSource: sources.LoadSource,
+		NewDestination: func() destinations.Destination {
+			return dryRunDestination
+		},
+		Logger: log.Desugar(),
+	}
+	if !*opts.DryRun {
+		replay.NewDestination = func() destinations.Destination {
+			return destinations.CreateS3Destination(common.ConfigForDataLakeWriters())
+		}
+		replay.DeadLetters = &deadletter.Writer{
+			S3Uploader: s3manager.NewUploaderWithClient(common.S3Client),
+			SNSClient:  common.SnsClient,
+			Bucket:     common.Config.ProcessedDataBucket,
+			TopicARN:   common.Config.SnsTopicARN,
+		}
+	}
+
+	log.Info("replay started")
+	if err := replay.Run(context.Background()); err != nil {
+		log.Errorf("replay failed: %s", err)
+	}
+	stats := replay.Stats
+	log.Infof("replay finished: %d objects, %d lines replayed, %d lines failed to classify, %d lines skipped",
+		stats.NumObjects, stats.NumLines, stats.NumFailed, stats.NumSkipped)
+	if *opts.DryRun {
+		log.Infof("dry run: %d events would be written", dryRunDestination.NumEvents)
+	}
+}
+
+func parseDate(input string) (time.Time, error) {
+	const layoutDate = "2006-01-02"
+	tm, err := time.Parse(layoutDate, input)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to parse %q as date (YYYY-MM-DD)", input)
+	}
+	return tm, nil
+}
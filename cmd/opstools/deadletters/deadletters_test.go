@@ -0,0 +1,180 @@
+package deadletters
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/datamodels"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	logmetrics "github.com/panther-labs/panther/internal/log_analysis/log_processor/metrics"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/testutil"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+const testLogType = "Test.Log"
+
+var testResolver = logtypes.ParserResolver(logtypes.LocalResolver(logtypes.Must("test", logtypes.Config{
+	Name:         testLogType,
+	Description:  "Test log type",
+	ReferenceURL: "-",
+	Schema: &struct {
+		Line string `json:"line" description:"log line"`
+	}{},
+	NewParser: pantherlog.FactoryFunc(func(_ interface{}) (parsers.Interface, error) {
+		return testutil.ParserConfig{
+			"fixed": &parsers.Result{},
+			"bad":   errors.New("still failing"),
+		}.Parser(), nil
+	}),
+})))
+
+func TestReplay(t *testing.T) {
+	logmetrics.Setup()
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	key := deadletter.S3ObjectKey(hour)
+	var input bytes.Buffer
+	{
+		w := gzip.NewWriter(&input)
+		for _, entry := range []*deadletter.Entry{
+			{CaptureTime: hour, SourceID: "source", S3ObjectKey: "input.log", LineNumber: 3, Line: "fixed"},
+			{CaptureTime: hour, SourceID: "source", S3ObjectKey: "input.log", LineNumber: 7, Line: "bad"},
+			{CaptureTime: hour, SourceID: "other", LineNumber: 1, Line: "skipped"},
+		} {
+			data, err := jsonAPI.Marshal(entry)
+			require.NoError(t, err)
+			_, err = w.Write(append(data, '\n'))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+	}
+
+	s3Client := &testutils.S3Mock{}
+	s3Client.On("ListObjectsV2PagesWithContext", mock.Anything, &s3.ListObjectsV2Input{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String("logs/panther_dead_letters/year=2020/month=01/day=01/hour=10/"),
+	}, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{{Key: aws.String(key)}},
+	}, nil).Once()
+	s3Client.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String(key),
+	}, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(&input),
+	}, nil).Once()
+	s3Client.On("DeleteObjectWithContext", mock.Anything, &s3.DeleteObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String(key),
+	}, mock.Anything).Return(&s3.DeleteObjectOutput{}, nil).Once()
+
+	// Lines that are not replayed are written to a new object
+	uploader := &testutils.S3UploaderMock{}
+	var output []byte
+	uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Run(func(args mock.Arguments) {
+		r, err := gzip.NewReader(args.Get(0).(*s3manager.UploadInput).Body)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		output = append(output, data...)
+	})
+	snsClient := &testutils.SnsMock{}
+	snsClient.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil)
+
+	dest := &DiscardDestination{}
+	loadedDataModels := false
+	replay := Replay{
+		S3Client: s3Client,
+		Bucket:   "bucket",
+		Start:    hour,
+		End:      hour.Add(time.Hour),
+		SourceID: "source",
+		Resolver: testResolver,
+		Extensions: &processor.Extensions{
+			DataModels: &datamodels.Loader{
+				ListDataModels: func(_ context.Context) ([]datamodels.DataModel, error) {
+					loadedDataModels = true
+					return nil, nil
+				},
+			},
+		},
+		LoadSource: func(id string) (*models.SourceIntegration, error) {
+			require.Equal(t, "source", id)
+			return &models.SourceIntegration{
+				SourceIntegrationMetadata: models.SourceIntegrationMetadata{
+					IntegrationID:    id,
+					IntegrationType:  models.IntegrationTypeAWS3,
+					S3PrefixLogTypes: models.S3PrefixLogtypes{{S3Prefix: "", LogTypes: []string{testLogType}}},
+				},
+			}, nil
+		},
+		NewDestination: func() destinations.Destination {
+			return dest
+		},
+		DeadLetters: &deadletter.Writer{
+			S3Uploader: uploader,
+			SNSClient:  snsClient,
+			Bucket:     "bucket",
+			TopicARN:   "topic",
+		},
+		Logger: zap.NewNop(),
+	}
+	require.NoError(t, replay.Run(context.Background()))
+	s3Client.AssertExpectations(t)
+	require.Equal(t, Stats{
+		NumObjects: 1,
+		NumLines:   2,
+		NumFailed:  1,
+		NumSkipped: 1,
+	}, replay.Stats)
+	require.Equal(t, 1, dest.NumEvents)
+	// Replayed events are normalized using the data models of the log processor
+	require.True(t, loadedDataModels)
+
+	entries := map[string]*deadletter.Entry{}
+	for _, line := range bytes.Split(bytes.TrimSpace(output), []byte("\n")) {
+		entry := deadletter.Entry{}
+		require.NoError(t, jsonAPI.Unmarshal(line, &entry))
+		entries[entry.Line] = &entry
+	}
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(1), entries["skipped"].LineNumber)
+	// The line number of the original input is kept
+	require.Equal(t, uint64(7), entries["bad"].LineNumber)
+	require.Equal(t, map[string]string{testLogType: "still failing"}, entries["bad"].ParserErrors)
+}
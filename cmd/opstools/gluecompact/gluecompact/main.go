@@ -0,0 +1,148 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/panther-labs/panther/cmd/opstools"
+	"github.com/panther-labs/panther/internal/log_analysis/gluetasks"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+var (
+	version string // we expect this to be set by the build tool as `-X main.version=<some version>`
+)
+
+func main() {
+	opstools.SetUsage("merges small files in hourly partitions of processed log tables (Panther version %s)", version)
+	opts := struct {
+		MasterStack    *string
+		End            *string
+		Start          *string
+		DryRun         *bool
+		Debug          *bool
+		Region         *string
+		NumWorkers     *int
+		MinFiles       *int
+		MaxFileSize    *int
+		GracePeriod    *time.Duration
+		MaxConnections *int
+		MaxRetries     *int
+		Prefix         *string
+	}{
+		MasterStack: flag.String("master-stack", "",
+			"if set, this is the name of the Panther master stack used to deploy, if not set the deployment is assumed from source"),
+		Start:          flag.String("start", "", "Compact partitions after this time YYYY-MM-DDTHH"),
+		End:            flag.String("end", "", "Compact partitions before this time YYYY-MM-DDTHH"),
+		DryRun:         flag.Bool("dry-run", false, "Scan for partitions to compact without applying any changes"),
+		Debug:          flag.Bool("debug", false, "Enable additional logging"),
+		Region:         flag.String("region", "", "Set the AWS region to run on"),
+		MaxRetries:     flag.Int("max-retries", 12, "Max retries for AWS requests"),
+		MaxConnections: flag.Int("max-connections", 100, "Max number of connections to AWS"),
+		NumWorkers:     flag.Int("workers", 8, "Number of parallel workers for each table"),
+		MinFiles:       flag.Int("min-files", gluetasks.DefaultCompactMinFiles, "Minimum number of files in a partition to compact it"),
+		MaxFileSize:    flag.Int("max-file-size", gluetasks.DefaultCompactMaxFileSize, "Size in bytes of merged files"),
+		GracePeriod:    flag.Duration("grace-period", gluetasks.DefaultCompactGracePeriod, "Minimum age of files to merge or delete"),
+		Prefix:         flag.String("prefix", "", "A prefix to filter log type names"),
+	}
+	flag.Parse()
+
+	log := opstools.MustBuildLogger(*opts.Debug)
+	var start, end time.Time
+	if opt := *opts.Start; opt != "" {
+		tm, err := parseHour(opt)
+		if err != nil {
+			log.Fatalf("failed to parse %q flag: %s", "start", err)
+		}
+		start = tm
+	}
+	if opt := *opts.End; opt != "" {
+		tm, err := parseHour(opt)
+		if err != nil {
+			log.Fatalf("failed to parse %q flag: %s", "end", err)
+		}
+		end = tm
+	}
+
+	var matchPrefix string
+	if optPrefix := *opts.Prefix; optPrefix != "" {
+		matchPrefix = pantherdb.TableName(optPrefix)
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:     opts.Region,
+		MaxRetries: opts.MaxRetries,
+		HTTPClient: opstools.NewHTTPClient(*opts.MaxConnections, 0),
+	})
+	if err != nil {
+		log.Fatalf("failed to build AWS session: %s", err)
+	}
+
+	opstools.ValidatePantherVersion(sess, log, *opts.MasterStack, version)
+
+	glueAPI := glue.New(sess)
+	s3API := s3.New(sess)
+	tasks := []gluetasks.CompactDatabaseTables{
+		{
+			DatabaseName: pantherdb.LogProcessingDatabase,
+		},
+		{
+			DatabaseName: pantherdb.CloudSecurityDatabase,
+		},
+	}
+	group, ctx := errgroup.WithContext(context.Background())
+	log.Info("compaction started")
+	for i := range tasks {
+		task := &tasks[i]
+		task.Start = start
+		task.End = end
+		task.DryRun = *opts.DryRun
+		task.MatchPrefix = matchPrefix
+		task.NumWorkers = *opts.NumWorkers
+		task.MinFiles = *opts.MinFiles
+		task.MaxFileSize = *opts.MaxFileSize
+		task.GracePeriod = *opts.GracePeriod
+		group.Go(func() error {
+			return task.Run(ctx, glueAPI, s3API, log.Desugar())
+		})
+	}
+	if err := group.Wait(); err != nil {
+		log.Fatalf("compaction failed: %s", err)
+	}
+	log.Info("compaction finished")
+}
+
+func parseHour(input string) (time.Time, error) {
+	const layoutHour = "2006-01-02T15"
+	tm, err := time.Parse(layoutHour, input)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to parse %q as hour (YYYY-MM-DDTHH)", input)
+	}
+	return tm, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/cmd/opstools"
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/retention"
+)
+
+var (
+	version string // we expect this to be set by the build tool as `-X main.version=<some version>`
+)
+
+func main() {
+	opstools.SetUsage("expires data older than the retention policies of log types (Panther version %s)", version)
+	opts := struct {
+		MasterStack    *string
+		DryRun         *bool
+		Debug          *bool
+		Region         *string
+		MaxConnections *int
+		MaxRetries     *int
+		Prefix         *string
+		Output         *string
+	}{
+		MasterStack: flag.String("master-stack", "",
+			"if set, this is the name of the Panther master stack used to deploy, if not set the deployment is assumed from source"),
+		DryRun:         flag.Bool("dry-run", false, "Report the partitions that would expire without applying any changes"),
+		Debug:          flag.Bool("debug", false, "Enable additional logging"),
+		Region:         flag.String("region", "", "Set the AWS region to run on"),
+		MaxRetries:     flag.Int("max-retries", 12, "Max retries for AWS requests"),
+		MaxConnections: flag.Int("max-connections", 100, "Max number of connections to AWS"),
+		Prefix:         flag.String("prefix", "", "A prefix to filter log type names"),
+		Output:         flag.String("output", "", "Write the JSON report to this file instead of stdout"),
+	}
+	flag.Parse()
+
+	log := opstools.MustBuildLogger(*opts.Debug)
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:     opts.Region,
+		MaxRetries: opts.MaxRetries,
+		HTTPClient: opstools.NewHTTPClient(*opts.MaxConnections, 0),
+	})
+	if err != nil {
+		log.Fatalf("failed to build AWS session: %s", err)
+	}
+
+	opstools.ValidatePantherVersion(sess, log, *opts.MasterStack, version)
+
+	ctx := context.Background()
+	client := logtypesapi.LogTypesAPILambdaClient{
+		LambdaName: logtypesapi.LambdaName,
+		LambdaAPI:  lambda.New(sess),
+		Validate:   validator.New().Struct,
+	}
+	reply, err := client.ListRetention(ctx)
+	if err != nil {
+		log.Fatalf("failed to list retention policies: %s", err)
+	}
+	if reply.Error != nil {
+		log.Fatalf("failed to list retention policies: %s", reply.Error.Message)
+	}
+	var records []*logtypesapi.RetentionRecord
+	for _, record := range reply.Records {
+		if strings.HasPrefix(record.LogType, *opts.Prefix) {
+			records = append(records, record)
+		}
+	}
+
+	enforcer := retention.Enforcer{
+		GlueAPI: glue.New(sess),
+		S3API:   s3.New(sess),
+		Logger:  log.Desugar(),
+		DryRun:  *opts.DryRun,
+	}
+	log.Infof("enforcing retention policies of %d log types", len(records))
+	report, runErr := enforcer.Run(ctx, time.Now(), records...)
+
+	// The report lists the partitions expired so far even if the run failed
+	out := os.Stdout
+	if *opts.Output != "" {
+		f, err := os.Create(*opts.Output)
+		if err != nil {
+			log.Fatalf("failed to create report file: %s", err)
+		}
+		out = f
+	}
+	enc := jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("failed to write report: %s", err)
+	}
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			log.Fatalf("failed to write report: %s", err)
+		}
+	}
+	if runErr != nil {
+		log.Fatalf("retention failed: %s", runErr)
+	}
+	log.Infof("expired %d partitions (%d objects, %d bytes)", report.NumPartitions, report.NumObjects, report.NumBytes)
+}
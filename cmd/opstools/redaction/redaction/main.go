@@ -0,0 +1,144 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/cmd/opstools"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/redaction"
+	"github.com/panther-labs/panther/pkg/awscfn"
+	"github.com/panther-labs/panther/pkg/encryption"
+	"github.com/panther-labs/panther/tools/cfnstacks"
+)
+
+var (
+	version string // we expect this to be set by the build tool as `-X main.version=<some version>`
+)
+
+func main() {
+	opstools.SetUsage("manages the rules to redact personal data in processed logs (Panther version %s)", version)
+	opts := struct {
+		MasterStack *string
+		Debug       *bool
+		Region      *string
+		MaxRetries  *int
+		Rules       *string
+		Token       *string
+	}{
+		MasterStack: flag.String("master-stack", "",
+			"if set, this is the name of the Panther master stack used to deploy, if not set the deployment is assumed from source"),
+		Debug:      flag.Bool("debug", false, "Enable additional logging"),
+		Region:     flag.String("region", "", "Set the AWS region to run on"),
+		MaxRetries: flag.Int("max-retries", 12, "Max retries for AWS requests"),
+		Rules: flag.String("rules", "",
+			"Replace the redaction rules with the rules in this JSON file (log type -> list of rules), use an empty object to remove all rules"),
+		Token: flag.String("token", "", "Print the token of a value to use in rules and queries"),
+	}
+	flag.Parse()
+
+	log := opstools.MustBuildLogger(*opts.Debug)
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:     opts.Region,
+		MaxRetries: opts.MaxRetries,
+	})
+	if err != nil {
+		log.Fatalf("failed to build AWS session: %s", err)
+	}
+
+	opstools.ValidatePantherVersion(sess, log, *opts.MasterStack, version)
+
+	bootstrapStack, err := cfnstacks.GetBootstrapStack(cloudformation.New(sess), *opts.MasterStack)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputs, err := awscfn.StackOutputs(cloudformation.New(sess), bootstrapStack)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bucket := outputs["ProcessedDataBucket"]
+
+	ctx := context.Background()
+	s3Client := s3.New(sess)
+	key := encryption.New(redaction.KeyAlias, sess)
+	config, err := redaction.ReadConfigS3(ctx, s3Client, bucket, redaction.ConfigS3Key)
+	if err != nil {
+		log.Fatalf("failed to read redaction configuration: %s", err)
+	}
+
+	switch {
+	case *opts.Token != "":
+		if config == nil || config.EncryptedKey == nil {
+			log.Fatal("no redaction key has been set")
+		}
+		secret := redaction.Secret{}
+		if err := key.DecryptConfig(config.EncryptedKey, &secret); err != nil {
+			log.Fatalf("failed to decrypt redaction key: %s", err)
+		}
+		fmt.Println(redaction.Token(secret.HMACKey, *opts.Token))
+	case *opts.Rules != "":
+		data, err := ioutil.ReadFile(*opts.Rules)
+		if err != nil {
+			log.Fatalf("failed to read rules: %s", err)
+		}
+		rules := map[string][]redaction.Rule{}
+		if err := jsoniter.Unmarshal(data, &rules); err != nil {
+			log.Fatalf("failed to parse rules: %s", err)
+		}
+		if _, err := redaction.New([]byte("validate"), rules); err != nil {
+			log.Fatalf("invalid rules: %s", err)
+		}
+		if config == nil {
+			config = &redaction.Config{}
+		}
+		generated, err := config.EnsureKey(key.EncryptConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if generated {
+			log.Info("generated a new redaction key")
+		}
+		config.LogTypes = rules
+		if err := redaction.WriteConfigS3(ctx, s3Client, bucket, redaction.ConfigS3Key, config); err != nil {
+			log.Fatalf("failed to upload redaction configuration: %s", err)
+		}
+		log.Infof("updated the redaction rules of %d log types, changes apply to logs processed after a few minutes", len(rules))
+	default:
+		var rules map[string][]redaction.Rule
+		if config != nil {
+			rules = config.LogTypes
+		}
+		enc := jsoniter.ConfigCompatibleWithStandardLibrary.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rules); err != nil {
+			log.Fatalf("failed to print rules: %s", err)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package syslogrelay
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package syslogrelay receives syslog messages over TCP and UDP and forwards them to an HTTP push source.
+//
+// Appliances that only speak syslog point to a relay running in their network.
+// The relay batches the messages and pushes them to Panther with the auth token of the source,
+// so that they are authorized, parsed and stored the same way as any other HTTP push request.
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultBatchSize is the default number of messages pushed in each request
+	DefaultBatchSize = 1000
+	// DefaultFlushInterval is the default maximum time messages wait before they are pushed
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultMaxAttempts is the default number of attempts to push a batch
+	DefaultMaxAttempts = 5
+
+	// Maximum size of a syslog message
+	maxMessageSize = 64 * 1024
+)
+
+// Relay forwards syslog messages to an HTTP push source
+type Relay struct {
+	// URL is the endpoint of the HTTP push source (ie https://<api>/sources/<sourceId>)
+	URL string
+	// Token is the auth token of the HTTP push source
+	Token  string
+	Client *http.Client
+	// BatchSize is the maximum number of messages pushed in each request
+	BatchSize int
+	// FlushInterval is the maximum time a message waits before it is pushed
+	FlushInterval time.Duration
+	// MaxAttempts is the number of attempts to push a batch before it is dropped
+	MaxAttempts int
+	// RetryDelay is the delay before the first retry, it doubles on each attempt
+	RetryDelay time.Duration
+	Logger     *zap.SugaredLogger
+
+	once     sync.Once
+	messages chan string
+}
+
+func (r *Relay) init() {
+	r.once.Do(func() {
+		if r.BatchSize <= 0 {
+			r.BatchSize = DefaultBatchSize
+		}
+		if r.FlushInterval <= 0 {
+			r.FlushInterval = DefaultFlushInterval
+		}
+		if r.MaxAttempts <= 0 {
+			r.MaxAttempts = DefaultMaxAttempts
+		}
+		if r.RetryDelay <= 0 {
+			r.RetryDelay = time.Second
+		}
+		if r.Client == nil {
+			r.Client = http.DefaultClient
+		}
+		if r.Logger == nil {
+			r.Logger = zap.NewNop().Sugar()
+		}
+		r.messages = make(chan string, r.BatchSize)
+	})
+}
+
+// Run pushes received messages in batches until the context is done.
+// Messages that are pending when the context is done are pushed before it returns.
+func (r *Relay) Run(ctx context.Context) {
+	r.init()
+	ticker := time.NewTicker(r.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]string, 0, r.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// Pending messages are pushed even if the context is done
+		if err := r.push(context.Background(), batch); err != nil {
+			r.Logger.Errorw("dropped syslog messages", "count", len(batch), "error", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case msg := <-r.messages:
+			batch = append(batch, msg)
+			if len(batch) >= r.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for {
+				select {
+				case msg := <-r.messages:
+					batch = append(batch, msg)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// ServeTCP receives messages from TCP connections until the context is done.
+// Both octet counting and newline delimited framing (RFC 6587) are supported.
+func (r *Relay) ServeTCP(ctx context.Context, ln net.Listener) error {
+	r.init()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "failed to accept connection")
+		}
+		go r.serveConn(ctx, conn)
+	}
+}
+
+func (r *Relay) serveConn(ctx context.Context, conn net.Conn) {
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		_ = conn.Close()
+	}()
+	br := bufio.NewReaderSize(conn, maxMessageSize)
+	for {
+		msg, err := ReadMessage(br)
+		if msg != "" {
+			r.enqueue(ctx, msg)
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				r.Logger.Warnw("closing syslog connection", "remoteAddr", conn.RemoteAddr().String(), "error", err)
+			}
+			return
+		}
+	}
+}
+
+// ServeUDP receives messages from UDP datagrams until the context is done.
+// Each datagram holds a single message (RFC 5426).
+func (r *Relay) ServeUDP(ctx context.Context, conn net.PacketConn) error {
+	r.init()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "failed to read datagram")
+		}
+		if msg := strings.TrimRight(string(buf[:n]), "\r\n\x00"); msg != "" {
+			r.enqueue(ctx, msg)
+		}
+	}
+}
+
+func (r *Relay) enqueue(ctx context.Context, msg string) {
+	// HTTP push sources read a log entry from each line
+	msg = strings.ReplaceAll(msg, "\n", " ")
+	select {
+	case r.messages <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// ReadMessage reads a syslog message framed with octet counting or with a trailing newline.
+// Octet counted messages start with the message length followed by a space.
+func ReadMessage(br *bufio.Reader) (string, error) {
+	first, err := br.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	if first[0] < '1' || first[0] > '9' {
+		line, err := br.ReadString('\n')
+		return strings.TrimRight(line, "\r\n\x00"), err
+	}
+	prefix, err := br.ReadString(' ')
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read message length")
+	}
+	size, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+	if err != nil || size > maxMessageSize {
+		return "", errors.Errorf("invalid message length %q", prefix)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(br, msg); err != nil {
+		return "", errors.Wrap(err, "failed to read message")
+	}
+	return strings.TrimRight(string(msg), "\r\n\x00"), nil
+}
+
+// push sends a batch of messages to the HTTP push source.
+// Failed requests are retried with exponential backoff unless the source rejected the request.
+func (r *Relay) push(ctx context.Context, batch []string) error {
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	for _, msg := range batch {
+		_, _ = gz.Write([]byte(msg))
+		_, _ = gz.Write([]byte{'\n'})
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	delay := r.RetryDelay
+	var err error
+	for attempt := 1; attempt <= r.MaxAttempts; attempt++ {
+		var retry bool
+		if retry, err = r.post(ctx, body.Bytes()); err == nil || !retry {
+			return err
+		}
+		if attempt < r.MaxAttempts {
+			r.Logger.Warnw("failed to push syslog messages, retrying", "attempt", attempt, "error", err)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// post sends a request to the HTTP push source and reports if a failed request should be retried
+func (r *Relay) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.Token)
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Content-Type", "text/plain")
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, errors.Errorf("HTTP push source responded with %s", resp.Status)
+	default:
+		return false, errors.Errorf("HTTP push source rejected the request with %s", resp.Status)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/panther-labs/panther/cmd/opstools"
+	"github.com/panther-labs/panther/cmd/opstools/syslogrelay"
+)
+
+const (
+	banner = "receives syslog messages over TCP and UDP and forwards them to a Panther HTTP push source"
+
+	tokenEnv = "SYSLOG_RELAY_TOKEN"
+)
+
+var (
+	URL = flag.String("url", "",
+		"The endpoint of the HTTP push source (e.g., https://<api>/sources/<sourceId>).")
+	TOKEN = flag.String("token", "",
+		"The auth token of the HTTP push source (optional, defaults to the "+tokenEnv+" env var).")
+	TCP     = flag.String("tcp", ":6514", "The address to receive syslog messages over TCP, empty to disable TCP.")
+	UDP     = flag.String("udp", ":514", "The address to receive syslog messages over UDP, empty to disable UDP.")
+	TLSCERT = flag.String("tls-cert", "",
+		"The certificate file to receive syslog messages over TLS (optional, RFC 5425).")
+	TLSKEY        = flag.String("tls-key", "", "The private key file of the TLS certificate.")
+	BATCHSIZE     = flag.Int("batch-size", syslogrelay.DefaultBatchSize, "The maximum number of messages pushed in each request.")
+	FLUSHINTERVAL = flag.Duration("flush-interval", syslogrelay.DefaultFlushInterval,
+		"The maximum time a message waits before it is pushed.")
+	DEBUG = flag.Bool("debug", false, "Enable debug logging")
+)
+
+func main() {
+	opstools.SetUsage(banner)
+	flag.Parse()
+	logger := opstools.MustBuildLogger(*DEBUG)
+
+	if *URL == "" {
+		logger.Fatal("-url is required")
+	}
+	token := *TOKEN
+	if token == "" {
+		token = os.Getenv(tokenEnv)
+	}
+	if token == "" {
+		logger.Fatalf("-token or %s env var is required", tokenEnv)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		logger.Info("shutting down")
+		cancel()
+	}()
+
+	relay := &syslogrelay.Relay{
+		URL:           *URL,
+		Token:         token,
+		Client:        opstools.NewHTTPClient(1, time.Minute),
+		BatchSize:     *BATCHSIZE,
+		FlushInterval: *FLUSHINTERVAL,
+		Logger:        logger,
+	}
+
+	var wg sync.WaitGroup
+	if *TCP != "" {
+		ln, err := net.Listen("tcp", *TCP)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if *TLSCERT != "" {
+			cert, err := tls.LoadX509KeyPair(*TLSCERT, *TLSKEY)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			ln = tls.NewListener(ln, &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				MinVersion:   tls.VersionTLS12,
+			})
+		}
+		logger.Infof("receiving syslog messages on tcp %s", ln.Addr())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := relay.ServeTCP(ctx, ln); err != nil {
+				logger.Error(err)
+				cancel()
+			}
+		}()
+	}
+	if *UDP != "" {
+		conn, err := net.ListenPacket("udp", *UDP)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		logger.Infof("receiving syslog messages on udp %s", conn.LocalAddr())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := relay.ServeUDP(ctx, conn); err != nil {
+				logger.Error(err)
+				cancel()
+			}
+		}()
+	}
+	relay.Run(ctx)
+	wg.Wait()
+}
@@ -0,0 +1,176 @@
+package syslogrelay
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMessage(t *testing.T) {
+	input := "<34>1 2020-10-11T22:14:15Z host app - - - hello\n" +
+		"24 <34>1 multi\nline message" +
+		"<34>last message without newline"
+	br := bufio.NewReader(strings.NewReader(input))
+	msg, err := ReadMessage(br)
+	require.NoError(t, err)
+	require.Equal(t, "<34>1 2020-10-11T22:14:15Z host app - - - hello", msg)
+	msg, err = ReadMessage(br)
+	require.NoError(t, err)
+	require.Equal(t, "<34>1 multi\nline message", msg)
+	msg, err = ReadMessage(br)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "<34>last message without newline", msg)
+
+	_, err = ReadMessage(bufio.NewReader(strings.NewReader("99999999 foo")))
+	require.Error(t, err)
+}
+
+type pushServer struct {
+	mu       sync.Mutex
+	requests int
+	lines    []string
+	status   []int
+}
+
+func (s *pushServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if len(s.status) > 0 {
+		status := s.status[0]
+		s.status = s.status[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("Content-Encoding") != "gzip" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	body, _ := ioutil.ReadAll(gz)
+	s.lines = append(s.lines, strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")...)
+}
+
+func (s *pushServer) result() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests, append([]string(nil), s.lines...)
+}
+
+func TestRelay(t *testing.T) {
+	server := &pushServer{
+		// The first request is retried
+		status: []int{http.StatusBadGateway},
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	relay := &Relay{
+		URL:           srv.URL,
+		Token:         "token",
+		Client:        srv.Client(),
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		RetryDelay:    time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		require.NoError(t, relay.ServeTCP(ctx, ln))
+	}()
+	go func() {
+		defer wg.Done()
+		require.NoError(t, relay.ServeUDP(ctx, udp))
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("tcp message\n10 multi\nline"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	udpConn, err := net.Dial("udp", udp.LocalAddr().String())
+	require.NoError(t, err)
+	_, err = udpConn.Write([]byte("udp message\n"))
+	require.NoError(t, err)
+	require.NoError(t, udpConn.Close())
+
+	// The batch is pushed once it is full
+	require.Eventually(t, func() bool {
+		_, lines := server.result()
+		return len(lines) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	wg.Wait()
+
+	requests, lines := server.result()
+	require.Equal(t, 2, requests)
+	require.ElementsMatch(t, []string{"tcp message", "multi line", "udp message"}, lines)
+}
+
+func TestRelayRejected(t *testing.T) {
+	server := &pushServer{
+		status: []int{http.StatusUnauthorized},
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	relay := &Relay{
+		URL:        srv.URL,
+		Token:      "token",
+		Client:     srv.Client(),
+		RetryDelay: time.Millisecond,
+	}
+	relay.init()
+	// Rejected requests are not retried
+	require.Error(t, relay.push(context.Background(), []string{"foo"}))
+	requests, lines := server.result()
+	require.Equal(t, 1, requests)
+	require.Empty(t, lines)
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/throttle"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/tickets"
 	alertTable "github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
 )
@@ -48,6 +50,7 @@ type envConfig struct {
 	AlertQueueURL          string        `required:"true" split_words:"true"`
 	AlertsAPI              string        `required:"true" split_words:"true"`
 	OutputsAPI             string        `required:"true" split_words:"true"`
+	DeliveryStateTableName string        `required:"true" split_words:"true"`
 }
 
 // Globals
@@ -55,6 +58,8 @@ var (
 	env                  envConfig
 	awsSession           *session.Session
 	alertsTableClient    *alertTable.AlertsTable
+	deliveryStore        *throttle.Store
+	ticketStore          *tickets.Store
 	lambdaClient         lambdaiface.LambdaAPI
 	outputClient         outputs.API
 	sqsClient            sqsiface.SQSAPI
@@ -79,6 +84,14 @@ func Setup() {
 		RuleIDCreationTimeIndexName:        env.RuleIndexName,
 		TimePartitionCreationTimeIndexName: env.TimeIndexName,
 	}
+	deliveryStore = &throttle.Store{
+		Client:    alertsTableClient.Client,
+		TableName: env.DeliveryStateTableName,
+	}
+	ticketStore = &tickets.Store{
+		Client:    alertsTableClient.Client,
+		TableName: env.DeliveryStateTableName,
+	}
 	analysisClient = gatewayapi.NewClient(lambdaClient, "panther-analysis-api")
 	softDeadlineDuration = 10 * time.Second
 }
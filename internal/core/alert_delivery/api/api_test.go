@@ -45,6 +45,16 @@ func (m *mockOutputsClient) Slack(
 	return args.Get(0).(*outputs.AlertDeliveryResponse)
 }
 
+func (m *mockOutputsClient) Email(
+	ctx context.Context,
+	alert *deliverymodel.Alert,
+	config *outputModels.EmailConfig,
+) *outputs.AlertDeliveryResponse {
+
+	args := m.Called(ctx, alert, config)
+	return args.Get(0).(*outputs.AlertDeliveryResponse)
+}
+
 func sampleAlert() *deliverymodel.Alert {
 	return &deliverymodel.Alert{
 		AlertID:      aws.String("alert-id"),
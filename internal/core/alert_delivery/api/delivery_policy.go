@@ -0,0 +1,140 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// deferredAlert is an alert put back in the queue to be delivered later
+type deferredAlert struct {
+	alert *deliverymodel.Alert
+	delay time.Duration
+}
+
+// applyDeliveryPolicies - applies the delivery policies of outputs to the alerts dispatched to them.
+//
+// It returns the alerts to deliver now, the statuses of the alerts grouped in digests and the alerts to deliver later.
+// Alerts are delivered if the state of the delivery policies cannot be updated.
+func applyDeliveryPolicies(alertOutputs AlertOutputMap, now time.Time) (AlertOutputMap, []DispatchStatus, []deferredAlert) {
+	deliver := make(AlertOutputMap, len(alertOutputs))
+	grouped := []DispatchStatus{}
+	deferred := []deferredAlert{}
+	for alert, outputs := range alertOutputs {
+		for _, output := range outputs {
+			outputID := *output.OutputID
+			commonFields := []zap.Field{
+				zap.Stringp("alertID", alert.AlertID),
+				zap.String("analysisID", alert.AnalysisID),
+				zap.String("outputID", outputID),
+			}
+
+			// Digests bypass delivery policies
+			if alert.Digest != nil {
+				if alert.Digest.AlertCount > 0 {
+					deliver[alert] = append(deliver[alert], output)
+					continue
+				}
+				if delay := alert.Digest.WindowEnd.Sub(now); delay > 0 {
+					deferred = append(deferred, deferredAlert{alert: alertForOutput(alert, outputID), delay: delay})
+					continue
+				}
+				digest, err := deliveryStore.CloseDigest(outputID, alert.AnalysisID, alert.Digest.WindowEnd)
+				if err != nil {
+					zap.L().Error("failed to close digest", append(commonFields, zap.Error(err))...)
+					deferred = append(deferred, deferredAlert{
+						alert: alertForOutput(alert, outputID),
+						delay: time.Duration(env.MinRetryDelaySecs) * time.Second,
+					})
+					continue
+				}
+				if digest != nil {
+					digestAlert := newDigestAlert(alert, digest)
+					deliver[digestAlert] = []*outputModels.AlertOutput{output}
+				}
+				continue
+			}
+
+			decision, err := deliveryStore.Apply(alert, outputID, output.DeliveryPolicy, now)
+			if err != nil {
+				zap.L().Error("failed to apply delivery policy", append(commonFields, zap.Error(err))...)
+			}
+			switch {
+			case decision.Grouped:
+				grouped = append(grouped, DispatchStatus{
+					Alert:        *alert,
+					OutputID:     outputID,
+					Message:      "Grouped in a digest delivered at " + decision.DigestWindowEnd.UTC().Format(time.RFC3339),
+					StatusCode:   http.StatusAccepted,
+					Success:      true,
+					DispatchedAt: now,
+				})
+				continue
+			case decision.Delay > 0:
+				zap.L().Debug("deferring alert delivery", append(commonFields, zap.Duration("delay", decision.Delay))...)
+				deferred = append(deferred, deferredAlert{alert: alertForOutput(alert, outputID), delay: decision.Delay})
+			default:
+				deliver[alert] = append(deliver[alert], output)
+			}
+			// The alert that opened a digest window is put back in the queue to close it when it ends
+			if !decision.DigestWindowEnd.IsZero() {
+				marker := alertForOutput(alert, outputID)
+				marker.Digest = &deliverymodel.AlertDigest{
+					WindowStart: now,
+					WindowEnd:   decision.DigestWindowEnd,
+				}
+				deferred = append(deferred, deferredAlert{alert: marker, delay: decision.DigestWindowEnd.Sub(now)})
+			}
+		}
+	}
+	return deliver, grouped, deferred
+}
+
+// alertForOutput - returns a copy of an alert dispatched only to a specific output
+func alertForOutput(alert *deliverymodel.Alert, outputID string) *deliverymodel.Alert {
+	result := *alert
+	result.OutputIds = []string{outputID}
+	result.Destinations = nil
+	return &result
+}
+
+// newDigestAlert - builds the alert delivering a digest from the alert that opened its window
+func newDigestAlert(alert *deliverymodel.Alert, digest *deliverymodel.AlertDigest) *deliverymodel.Alert {
+	result := *alert
+	result.Digest = digest
+	name := alert.AnalysisID
+	if aws.StringValue(alert.AnalysisName) != "" {
+		name = *alert.AnalysisName
+	}
+	result.Title = fmt.Sprintf("%d alerts from %s in the last %d minutes",
+		digest.AlertCount, name, int(digest.WindowEnd.Sub(digest.WindowStart).Minutes()))
+	result.Context = map[string]interface{}{
+		"alertCount": digest.AlertCount,
+		"alertIds":   digest.AlertIDs,
+	}
+	return &result
+}
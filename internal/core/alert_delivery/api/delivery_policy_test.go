@@ -0,0 +1,147 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/throttle"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var errConditionalFailed = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+
+func TestApplyDeliveryPoliciesNoPolicy(t *testing.T) {
+	alert := sampleAlert()
+	output := &outputModels.AlertOutput{OutputID: aws.String("output-id")}
+	alertOutputs := AlertOutputMap{alert: {output}}
+
+	deliver, grouped, deferred := applyDeliveryPolicies(alertOutputs, time.Now().UTC())
+	assert.Equal(t, alertOutputs, deliver)
+	assert.Empty(t, grouped)
+	assert.Empty(t, deferred)
+}
+
+func TestApplyDeliveryPoliciesQuietHours(t *testing.T) {
+	now := time.Date(2020, 11, 2, 23, 0, 0, 0, time.UTC)
+	alert := sampleAlert()
+	alert.OutputIds = nil
+	alert.Destinations = []string{"output-id-1", "output-id-2"}
+	quietOutput := &outputModels.AlertOutput{
+		OutputID: aws.String("output-id-1"),
+		DeliveryPolicy: &outputModels.DeliveryPolicy{
+			QuietHours: &outputModels.QuietHours{Start: "22:00", End: "07:00"},
+		},
+	}
+	output := &outputModels.AlertOutput{OutputID: aws.String("output-id-2")}
+
+	deliver, grouped, deferred := applyDeliveryPolicies(AlertOutputMap{alert: {quietOutput, output}}, now)
+	assert.Equal(t, AlertOutputMap{alert: {output}}, deliver)
+	assert.Empty(t, grouped)
+	require.Len(t, deferred, 1)
+	assert.Equal(t, 8*time.Hour, deferred[0].delay)
+	assert.Equal(t, []string{"output-id-1"}, deferred[0].alert.OutputIds)
+	assert.Nil(t, deferred[0].alert.Destinations)
+	assert.Equal(t, 0, deferred[0].alert.RetryCount)
+}
+
+func TestApplyDeliveryPoliciesDigest(t *testing.T) {
+	now := time.Date(2020, 11, 2, 12, 0, 0, 0, time.UTC)
+	client := &testutils.DynamoDBMock{}
+	deliveryStore = &throttle.Store{Client: client, TableName: "table"}
+	output := &outputModels.AlertOutput{
+		OutputID:       aws.String("output-id"),
+		DeliveryPolicy: &outputModels.DeliveryPolicy{DigestWindowMinutes: 10},
+	}
+
+	// The alert is grouped in an open digest
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"windowEnd":  {N: aws.String("1604318700")},
+			"alertCount": {N: aws.String("1")},
+		},
+	}, nil).Once()
+	alert := sampleAlert()
+	deliver, grouped, deferred := applyDeliveryPolicies(AlertOutputMap{alert: {output}}, now)
+	assert.Empty(t, deliver)
+	assert.Empty(t, deferred)
+	assert.Equal(t, []DispatchStatus{
+		{
+			Alert:        *alert,
+			OutputID:     "output-id",
+			Message:      "Grouped in a digest delivered at 2020-11-02T12:05:00Z",
+			StatusCode:   http.StatusAccepted,
+			Success:      true,
+			DispatchedAt: now,
+		},
+	}, grouped)
+
+	// The alert opens a digest window and is delivered
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errConditionalFailed).Twice()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	alert = sampleAlert()
+	deliver, grouped, deferred = applyDeliveryPolicies(AlertOutputMap{alert: {output}}, now)
+	assert.Equal(t, AlertOutputMap{alert: {output}}, deliver)
+	assert.Empty(t, grouped)
+	require.Len(t, deferred, 1)
+	assert.Equal(t, 10*time.Minute, deferred[0].delay)
+	assert.Equal(t, &deliverymodel.AlertDigest{WindowStart: now, WindowEnd: now.Add(10 * time.Minute)}, deferred[0].alert.Digest)
+
+	// The digest marker is deferred until the window ends
+	marker := deferred[0].alert
+	deliver, grouped, deferred = applyDeliveryPolicies(AlertOutputMap{marker: {output}}, now.Add(time.Minute))
+	assert.Empty(t, deliver)
+	assert.Empty(t, grouped)
+	require.Len(t, deferred, 1)
+	assert.Equal(t, 9*time.Minute, deferred[0].delay)
+
+	// The digest is delivered when the window ends
+	client.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"windowStart": {N: aws.String("1604318400")},
+			"windowEnd":   {N: aws.String("1604319000")},
+			"alertCount":  {N: aws.String("37")},
+			"alertIds":    {SS: aws.StringSlice([]string{"alert-1", "alert-2"})},
+		},
+	}, nil).Once()
+	deliver, grouped, deferred = applyDeliveryPolicies(AlertOutputMap{marker: {output}}, now.Add(10*time.Minute))
+	assert.Empty(t, grouped)
+	assert.Empty(t, deferred)
+	require.Len(t, deliver, 1)
+	for digestAlert, outputs := range deliver {
+		assert.Equal(t, []*outputModels.AlertOutput{output}, outputs)
+		assert.Equal(t, "37 alerts from test_rule_name in the last 10 minutes", digestAlert.Title)
+		assert.Equal(t, []string{"alert-1", "alert-2"}, digestAlert.Digest.AlertIDs)
+		// Digests are delivered regardless of delivery policies
+		deliverDigest, _, _ := applyDeliveryPolicies(AlertOutputMap{digestAlert: {output}}, now.Add(20*time.Minute))
+		assert.Equal(t, deliver, deliverDigest)
+	}
+	client.AssertExpectations(t)
+}
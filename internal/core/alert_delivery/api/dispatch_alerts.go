@@ -20,6 +20,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-playground/validator"
 	jsoniter "github.com/json-iterator/go"
@@ -44,8 +45,12 @@ func (API) DispatchAlerts(ctx context.Context, input []*deliverymodel.DispatchAl
 		return nil, err
 	}
 
+	// Apply the delivery policies of outputs. Alerts can be grouped in digests or held back in the queue.
+	alertOutputMap, groupedStatuses, deferredAlerts := applyDeliveryPolicies(alertOutputMap, time.Now().UTC())
+
 	// Send alerts to the specified destination(s) and obtain each response status
 	dispatchStatuses := sendAlerts(ctx, alertOutputMap, outputClient)
+	dispatchStatuses = append(dispatchStatuses, groupedStatuses...)
 
 	// Record the delivery statuses to ddb. Ignore the returned output.
 	updateAlerts(dispatchStatuses)
@@ -61,6 +66,9 @@ func (API) DispatchAlerts(ctx context.Context, input []*deliverymodel.DispatchAl
 	// Put any alerts that need to be retried back into the queue
 	retry(alertsToRetry, env.AlertQueueURL, env.MinRetryDelaySecs, env.MaxRetryDelaySecs)
 
+	// Put any alerts held by delivery policies back into the queue
+	deferAlerts(deferredAlerts, env.AlertQueueURL)
+
 	return nil, err
 }
 
@@ -19,9 +19,14 @@ package api
  */
 
 import (
+	"fmt"
+	"path"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/arn"
 	"go.uber.org/zap"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
@@ -29,9 +34,14 @@ import (
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
-const alertOutputSkip = "SKIP"
+const (
+	alertOutputSkip = "SKIP"
+	// alertContextAccountIDs is the key of the alert context with the AWS accounts of rule alerts
+	alertContextAccountIDs = "p_any_aws_account_ids"
+)
 
-// getAlertOutputs - Get output ids for an alert by dynmaic destinations, destination overrides, or default severity
+// getAlertOutputs - Get output ids for an alert by dynmaic destinations, destination overrides, routing rules,
+// or default severity
 func getAlertOutputs(alert *deliverymodel.Alert) ([]*outputModels.AlertOutput, error) {
 	// fetch all available panther outputs
 	outputs, err := getOutputs()
@@ -56,6 +66,12 @@ func getAlertOutputs(alert *deliverymodel.Alert) ([]*outputModels.AlertOutput, e
 		return alertOutputs, nil
 	}
 
+	// Next, evaluate the routing rules of the outputs accepting the alert type in order
+	routedOutputs, stop := getOutputsByRoutingRules(alert, filterOutputsByAlertType(alert, outputs))
+	if stop {
+		return getUniqueOutputs(routedOutputs), nil
+	}
+
 	// If no other dynamic/overrides were set, we calculate based on the severity rating (default)
 	alertOutputs = getOutputsBySeverity(alert, outputs)
 
@@ -63,7 +79,7 @@ func getAlertOutputs(alert *deliverymodel.Alert) ([]*outputModels.AlertOutput, e
 	alertOutputs = filterOutputsByAlertType(alert, alertOutputs)
 
 	// Then, we obtain a list of unique outputs
-	return getUniqueOutputs(alertOutputs), nil
+	return getUniqueOutputs(append(routedOutputs, alertOutputs...)), nil
 }
 
 // getOutputs - Gets a list of outputs from panther (using a cache)
@@ -126,6 +142,182 @@ func getOutputsByDestinationOverrides(
 	return alertOutputs, len(alertOutputs) > 0
 }
 
+// getOutputsByRoutingRules - evaluates the routing rules of outputs in order of priority.
+//
+// It returns the outputs of matching rules and true if a matching rule stopped the evaluation.
+// Callers should only pass the outputs accepting the type of the alert, rules are not filtered by alert type.
+// Rules with the same priority are evaluated in order of output id and then in the order they are defined in their output.
+func getOutputsByRoutingRules(alert *deliverymodel.Alert, outputs []*outputModels.AlertOutput) ([]*outputModels.AlertOutput, bool) {
+	type outputRule struct {
+		rule   *outputModels.RoutingRule
+		output *outputModels.AlertOutput
+	}
+	rules := []outputRule{}
+	for _, output := range outputs {
+		for i := range output.RoutingRules {
+			rules = append(rules, outputRule{rule: &output.RoutingRules[i], output: output})
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].rule.Priority != rules[j].rule.Priority {
+			return rules[i].rule.Priority < rules[j].rule.Priority
+		}
+		return *rules[i].output.OutputID < *rules[j].output.OutputID
+	})
+
+	alertOutputs := []*outputModels.AlertOutput{}
+	for _, r := range rules {
+		if !matchRoutingRule(alert, r.rule) {
+			continue
+		}
+		alertOutputs = append(alertOutputs, r.output)
+		if r.rule.StopProcessing {
+			return alertOutputs, true
+		}
+	}
+	return alertOutputs, false
+}
+
+// matchRoutingRule - checks if an alert matches all the conditions of a routing rule
+func matchRoutingRule(alert *deliverymodel.Alert, rule *outputModels.RoutingRule) bool {
+	if len(rule.LogTypes) > 0 && !containsAny(rule.LogTypes, alert.LogTypes) {
+		return false
+	}
+	if len(rule.Tags) > 0 && !containsAny(rule.Tags, alert.Tags) {
+		return false
+	}
+	if len(rule.AnalysisIDs) > 0 && !matchAnyGlob(rule.AnalysisIDs, alert.AnalysisID) {
+		return false
+	}
+	if len(rule.ResourceTypes) > 0 && !containsAny(rule.ResourceTypes, alert.ResourceTypes) {
+		return false
+	}
+	if len(rule.AWSAccountIDs) > 0 && !containsAny(rule.AWSAccountIDs, alertAccountIDs(alert)) {
+		return false
+	}
+	for _, match := range rule.Context {
+		if !matchContext(alert.Context, match) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchContext - checks if a value in the alert context, or any of its elements if it is a list, matches a glob pattern
+func matchContext(alertContext map[string]interface{}, match outputModels.ContextMatch) bool {
+	var value interface{} = alertContext
+	for _, key := range strings.Split(match.Key, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = obj[key]; !ok {
+			return false
+		}
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	for _, v := range values {
+		switch v.(type) {
+		case nil, map[string]interface{}, []interface{}:
+			continue
+		}
+		if matchAnyGlob(match.Values, fmt.Sprint(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// alertAccountIDs - returns the AWS accounts of an alert.
+//
+// Policy alerts are for the account of their resource.
+// Rule alerts are for the accounts in the `p_any_aws_account_ids` key of their alert context.
+func alertAccountIDs(alert *deliverymodel.Alert) []string {
+	accountIDs := []string{}
+	if accountID := resourceAccountID(alert.ResourceID); accountID != "" {
+		accountIDs = append(accountIDs, accountID)
+	}
+	switch values := alert.Context[alertContextAccountIDs].(type) {
+	case string:
+		accountIDs = append(accountIDs, values)
+	case []interface{}:
+		for _, value := range values {
+			if accountID, ok := value.(string); ok {
+				accountIDs = append(accountIDs, accountID)
+			}
+		}
+	}
+	return accountIDs
+}
+
+// resourceAccountID - returns the AWS account of a resource from its ARN or its id (ie `123456789012::AWS.CloudTrail.Meta`)
+func resourceAccountID(resourceID string) string {
+	if resourceARN, err := arn.Parse(resourceID); err == nil {
+		return resourceARN.AccountID
+	}
+	if pos := strings.Index(resourceID, "::"); pos > 0 {
+		return resourceID[:pos]
+	}
+	return ""
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchAnyGlob(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		// Patterns are validated when outputs are saved
+		if re, err := globRegexp(pattern); err == nil && re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globRegexp - converts a glob pattern to a regular expression matching the whole value.
+// It uses the syntax of path.Match but `*` and `?` also match `/` since values are not paths (ie ARNs)
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString(`(?s)^`)
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(`.*`)
+		case '?':
+			expr.WriteString(`.`)
+		case '\\':
+			if i++; i == len(pattern) {
+				return nil, path.ErrBadPattern
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			// Character classes have the same syntax in regular expressions
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				return nil, path.ErrBadPattern
+			}
+			end += i + 1
+			expr.WriteString(pattern[i : end+1])
+			i = end
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString(`$`)
+	return regexp.Compile(expr.String())
+}
+
 func getOutputsBySeverity(alert *deliverymodel.Alert, outputs []*outputModels.AlertOutput) []*outputModels.AlertOutput {
 	alertOutputs := []*outputModels.AlertOutput{}
 	for _, output := range outputs {
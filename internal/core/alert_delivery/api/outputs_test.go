@@ -242,6 +242,201 @@ func TestGetAlertOutputsFromAlertType(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestGetAlertOutputsFromRoutingRules(t *testing.T) {
+	mockClient := &testutils.LambdaMock{}
+	lambdaClient = mockClient
+
+	routedOutputs := outputModels.GetOutputsOutput{
+		{
+			OutputID:           aws.String("output-id-1"),
+			DefaultForSeverity: aws.StringSlice([]string{"INFO"}),
+			AlertTypes:         []string{deliverymodel.RuleType},
+		},
+		{
+			OutputID:   aws.String("output-id-2"),
+			AlertTypes: []string{deliverymodel.RuleType},
+			RoutingRules: []outputModels.RoutingRule{
+				{Priority: 2, LogTypes: []string{"AWS.CloudTrail"}},
+			},
+		},
+		{
+			OutputID:   aws.String("output-id-3"),
+			AlertTypes: []string{deliverymodel.RuleType},
+			RoutingRules: []outputModels.RoutingRule{
+				{Priority: 1, Tags: []string{"noisy"}, StopProcessing: true},
+			},
+		},
+		{
+			OutputID:   aws.String("output-id-4"),
+			AlertTypes: []string{deliverymodel.RuleType},
+			RoutingRules: []outputModels.RoutingRule{
+				{Priority: 1, Tags: []string{"noisy", "tie"}, StopProcessing: true},
+			},
+		},
+		{
+			OutputID:   aws.String("output-id-5"),
+			AlertTypes: []string{deliverymodel.PolicyType},
+			RoutingRules: []outputModels.RoutingRule{
+				{Priority: 0, LogTypes: []string{"AWS.CloudTrail"}, StopProcessing: true},
+			},
+		},
+	}
+	payload, err := jsoniter.Marshal(routedOutputs)
+	require.NoError(t, err)
+	mockLambdaResponse := &lambda.InvokeOutput{Payload: payload}
+
+	// Need to expire the cache because other tests mutate this global when run in parallel
+	outputsCache = &alertOutputsCache{
+		RefreshInterval: time.Second * time.Duration(30),
+		Expiry:          time.Now().Add(time.Minute * time.Duration(-5)),
+	}
+	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
+
+	alert := &deliverymodel.Alert{
+		AlertID:    aws.String("alert-id"),
+		Type:       deliverymodel.RuleType,
+		Severity:   "INFO",
+		AnalysisID: "test-rule-id",
+		LogTypes:   []string{"AWS.CloudTrail"},
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	// Routed outputs are added to the default outputs for the severity.
+	// Rules of outputs not accepting the alert type are not evaluated.
+	result, err := getAlertOutputs(alert)
+	require.NoError(t, err)
+	assert.Equal(t, []*outputModels.AlertOutput{routedOutputs[0], routedOutputs[1]}, result)
+
+	// A matching rule that stops processing overrides the rest of the rules and the default outputs
+	alert.Tags = []string{"noisy"}
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	assert.Equal(t, []*outputModels.AlertOutput{routedOutputs[2]}, result)
+
+	// Rules with the same priority are evaluated in order of output id
+	alert.Tags = []string{"tie"}
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	assert.Equal(t, []*outputModels.AlertOutput{routedOutputs[3]}, result)
+	alert.Tags = []string{"noisy", "tie"}
+	result, err = getAlertOutputs(alert)
+	require.NoError(t, err)
+	assert.Equal(t, []*outputModels.AlertOutput{routedOutputs[2]}, result)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestMatchRoutingRule(t *testing.T) {
+	alert := &deliverymodel.Alert{
+		Type:          deliverymodel.PolicyType,
+		AnalysisID:    "AWS.S3.Bucket.Encryption",
+		ResourceID:    "arn:aws:iam::123456789012:role/admin",
+		ResourceTypes: []string{"AWS.IAM.Role"},
+		Tags:          []string{"IAM", "Compliance"},
+		Context: map[string]interface{}{
+			"user": map[string]interface{}{
+				"name":   "root",
+				"groups": []interface{}{"admins", "security"},
+				"mfa":    false,
+			},
+			"role":                  "arn:aws:iam::123:role/Admin",
+			"count":                 float64(3),
+			"p_any_aws_account_ids": []interface{}{"111111111111"},
+		},
+	}
+	for _, tc := range []struct {
+		name   string
+		rule   outputModels.RoutingRule
+		expect bool
+	}{
+		{name: "no conditions", rule: outputModels.RoutingRule{}, expect: true},
+		{name: "log type", rule: outputModels.RoutingRule{LogTypes: []string{"AWS.CloudTrail"}}, expect: false},
+		{name: "tags", rule: outputModels.RoutingRule{Tags: []string{"PCI", "IAM"}}, expect: true},
+		{name: "analysis id glob", rule: outputModels.RoutingRule{AnalysisIDs: []string{"AWS.S3.*"}}, expect: true},
+		{name: "analysis id no match", rule: outputModels.RoutingRule{AnalysisIDs: []string{"GCP.*"}}, expect: false},
+		{name: "resource type", rule: outputModels.RoutingRule{ResourceTypes: []string{"AWS.IAM.Role"}}, expect: true},
+		{name: "account", rule: outputModels.RoutingRule{AWSAccountIDs: []string{"123456789012"}}, expect: true},
+		{name: "other account", rule: outputModels.RoutingRule{AWSAccountIDs: []string{"210987654321"}}, expect: false},
+		{name: "context account", rule: outputModels.RoutingRule{AWSAccountIDs: []string{"111111111111"}}, expect: true},
+		{
+			name: "context value",
+			rule: outputModels.RoutingRule{Context: []outputModels.ContextMatch{
+				{Key: "user.name", Values: []string{"ro*"}},
+				{Key: "user.mfa", Values: []string{"false"}},
+				{Key: "count", Values: []string{"3"}},
+			}},
+			expect: true,
+		},
+		{
+			name:   "context arn glob",
+			rule:   outputModels.RoutingRule{Context: []outputModels.ContextMatch{{Key: "role", Values: []string{"arn:aws:iam::*"}}}},
+			expect: true,
+		},
+		{
+			name:   "context list",
+			rule:   outputModels.RoutingRule{Context: []outputModels.ContextMatch{{Key: "user.groups", Values: []string{"security"}}}},
+			expect: true,
+		},
+		{
+			name:   "context missing key",
+			rule:   outputModels.RoutingRule{Context: []outputModels.ContextMatch{{Key: "user.email", Values: []string{"*"}}}},
+			expect: false,
+		},
+		{
+			name:   "context object",
+			rule:   outputModels.RoutingRule{Context: []outputModels.ContextMatch{{Key: "user", Values: []string{"*"}}}},
+			expect: false,
+		},
+		{
+			name: "all conditions must match",
+			rule: outputModels.RoutingRule{
+				Tags:        []string{"IAM"},
+				AnalysisIDs: []string{"GCP.*"},
+			},
+			expect: false,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, matchRoutingRule(alert, &tc.rule))
+		})
+	}
+}
+
+func TestMatchAnyGlob(t *testing.T) {
+	assert.True(t, matchAnyGlob([]string{"arn:aws:iam::*"}, "arn:aws:iam::123:role/Admin"))
+	assert.True(t, matchAnyGlob([]string{"arn:aws:iam::*:role/*"}, "arn:aws:iam::123:role/path/Admin"))
+	assert.True(t, matchAnyGlob([]string{"GCP.*", "AWS.S3.?ucket.*"}, "AWS.S3.Bucket.Encryption"))
+	assert.True(t, matchAnyGlob([]string{"AWS.[A-Z]3.*"}, "AWS.S3.Bucket"))
+	assert.True(t, matchAnyGlob([]string{`a\*b`}, "a*b"))
+	assert.False(t, matchAnyGlob([]string{`a\*b`}, "axb"))
+	assert.False(t, matchAnyGlob([]string{"arn:aws:s3:::*"}, "arn:aws:iam::123:role/Admin"))
+	assert.False(t, matchAnyGlob([]string{"AWS.S3"}, "AWS.S3.Bucket"))
+	assert.False(t, matchAnyGlob([]string{"AWS.S3.*"}, "xAWS.S3.Bucket"))
+	assert.False(t, matchAnyGlob([]string{"AWS.[S3.*"}, "AWS.S3.Bucket"))
+}
+
+func TestResourceAccountID(t *testing.T) {
+	assert.Equal(t, "123456789012", resourceAccountID("arn:aws:iam::123456789012:role/admin"))
+	assert.Equal(t, "123456789012", resourceAccountID("123456789012::AWS.CloudTrail.Meta"))
+	assert.Equal(t, "", resourceAccountID("arn:aws:s3:::bucket"))
+	assert.Equal(t, "", resourceAccountID("resource"))
+}
+
+func TestAlertAccountIDs(t *testing.T) {
+	alert := &deliverymodel.Alert{ResourceID: "123456789012::AWS.CloudTrail.Meta"}
+	assert.Equal(t, []string{"123456789012"}, alertAccountIDs(alert))
+	alert = &deliverymodel.Alert{Context: map[string]interface{}{
+		"p_any_aws_account_ids": []interface{}{"111111111111", "222222222222", nil},
+	}}
+	assert.Equal(t, []string{"111111111111", "222222222222"}, alertAccountIDs(alert))
+	alert = &deliverymodel.Alert{Context: map[string]interface{}{
+		"p_any_aws_account_ids": "111111111111",
+	}}
+	assert.Equal(t, []string{"111111111111"}, alertAccountIDs(alert))
+	assert.Empty(t, alertAccountIDs(&deliverymodel.Alert{}))
+}
+
 func TestGetAlertOutputsIdsError(t *testing.T) {
 	mockClient := &testutils.LambdaMock{}
 	lambdaClient = mockClient
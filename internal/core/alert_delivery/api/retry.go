@@ -32,7 +32,11 @@ import (
 	"github.com/panther-labs/panther/pkg/awsbatch/sqsbatch"
 )
 
-const maxSQSBackoff = 30 * time.Second
+const (
+	maxSQSBackoff = 30 * time.Second
+	// maxSQSDelay is the maximum delay of SQS messages
+	maxSQSDelay = 15 * time.Minute
+)
 
 // retry - sends a list of alerts back to the queue with random delays.
 func retry(alerts []*deliverymodel.Alert, queueURL string, minDelaySecs int, maxDelaySecs int) {
@@ -47,6 +51,40 @@ func retry(alerts []*deliverymodel.Alert, queueURL string, minDelaySecs int, max
 	sendToSQS(input)
 }
 
+// deferAlerts - sends a list of alerts held by delivery policies back to the queue with their delays.
+//
+// Alerts deferred longer than the maximum SQS delay are deferred again when they are received.
+func deferAlerts(alerts []deferredAlert, queueURL string) {
+	if len(alerts) == 0 {
+		return
+	}
+
+	entries := []*sqs.SendMessageBatchRequestEntry{}
+	for i, deferred := range alerts {
+		body, err := jsoniter.MarshalToString(deferred.alert)
+		if err != nil {
+			zap.L().Panic("error encoding alert as JSON", zap.Error(err))
+		}
+		entries = append(entries, createEntry(body, i, deferDelaySeconds(deferred.delay)))
+	}
+	sendToSQS(&sqs.SendMessageBatchInput{
+		Entries:  entries,
+		QueueUrl: aws.String(queueURL),
+	})
+}
+
+// deferDelaySeconds - converts a delay to SQS delay seconds, rounding up to at least a second
+func deferDelaySeconds(delay time.Duration) int64 {
+	if delay > maxSQSDelay {
+		delay = maxSQSDelay
+	}
+	seconds := int64((delay + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
 func createInput(alerts []*deliverymodel.Alert, queueURL string, minDelaySecs int, maxDelaySecs int) *sqs.SendMessageBatchInput {
 	return &sqs.SendMessageBatchInput{
 		Entries:  createEntries(alerts, minDelaySecs, maxDelaySecs),
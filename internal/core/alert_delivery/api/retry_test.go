@@ -20,6 +20,7 @@ package api
 
 import (
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -66,3 +67,35 @@ func TestRetry(t *testing.T) {
 	retry(alerts, queueURL, 5, 6)
 	mockSQS.AssertExpectations(t)
 }
+
+func TestDeferAlerts(t *testing.T) {
+	mockSQS := &testutils.SqsMock{}
+	sqsClient = mockSQS
+
+	alert := sampleAlert()
+	body, err := jsoniter.MarshalToString(alert)
+	require.NoError(t, err)
+
+	input := &sqs.SendMessageBatchInput{
+		Entries: []*sqs.SendMessageBatchRequestEntry{
+			{
+				DelaySeconds: aws.Int64(int64(2)),
+				Id:           aws.String("0"),
+				MessageBody:  aws.String(body),
+			},
+			{
+				DelaySeconds: aws.Int64(int64(900)),
+				Id:           aws.String("1"),
+				MessageBody:  aws.String(body),
+			},
+		},
+		QueueUrl: aws.String("sqs-url"),
+	}
+
+	mockSQS.On("SendMessageBatch", input).Return(&sqs.SendMessageBatchOutput{}, nil).Once()
+	deferAlerts([]deferredAlert{
+		{alert: alert, delay: 1500 * time.Millisecond},
+		{alert: alert, delay: 8 * time.Hour},
+	}, "sqs-url")
+	mockSQS.AssertExpectations(t)
+}
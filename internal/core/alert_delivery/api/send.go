@@ -24,6 +24,7 @@ import (
 
 	"go.uber.org/zap"
 
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
@@ -41,6 +42,8 @@ type DispatchStatus struct {
 	Success      bool
 	NeedsRetry   bool
 	DispatchedAt time.Time
+	// Ticket is the issue created by ticketing outputs
+	Ticket *alertModels.ExternalTicket
 }
 
 // sendAlerts - dispatches alerts to their associated outputIds in parallel
@@ -160,6 +163,14 @@ func sendAlert(
 		response = outputClient.Asana(ctx, alert, output.OutputConfig.Asana)
 	case "customwebhook":
 		response = outputClient.CustomWebhook(ctx, alert, output.OutputConfig.CustomWebhook)
+	case "email":
+		response = outputClient.Email(ctx, alert, output.OutputConfig.Email)
+	case "googlechat":
+		response = outputClient.GoogleChat(ctx, alert, output.OutputConfig.GoogleChat)
+	case "mattermost":
+		response = outputClient.Mattermost(ctx, alert, output.OutputConfig.Mattermost)
+	case "webex":
+		response = outputClient.Webex(ctx, alert, output.OutputConfig.Webex)
 	default:
 		zap.L().Warn("unsupported output type", commonFields...)
 		statusChannel <- DispatchStatus{
@@ -189,7 +200,7 @@ func sendAlert(
 	}
 
 	// Retry only if not successful and we don't have a permanent failure
-	statusChannel <- DispatchStatus{
+	status := DispatchStatus{
 		Alert:        *alert,
 		OutputID:     *output.OutputID,
 		StatusCode:   response.StatusCode,
@@ -198,4 +209,14 @@ func sendAlert(
 		NeedsRetry:   !response.Success && !response.Permanent,
 		DispatchedAt: dispatchedAt,
 	}
+	if response.Ticket != nil {
+		status.Ticket = &alertModels.ExternalTicket{
+			OutputID:   *output.OutputID,
+			OutputType: *output.OutputType,
+			Key:        response.Ticket.Key,
+			URL:        response.Ticket.URL,
+			CreatedAt:  dispatchedAt,
+		}
+	}
+	statusChannel <- status
 }
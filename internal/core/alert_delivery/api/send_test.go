@@ -72,6 +72,41 @@ func TestSendPanic(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestSendEmail(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+
+	ch := make(chan DispatchStatus, 1)
+	alert := sampleAlert()
+	emailConfig := &outputModels.EmailConfig{From: "alerts@example.com", To: []string{"security@example.com"}}
+	alertOutput := &outputModels.AlertOutput{
+		OutputID:     aws.String("output-id"),
+		OutputType:   aws.String("email"),
+		DisplayName:  aws.String("email:security"),
+		OutputConfig: &outputModels.OutputConfig{Email: emailConfig},
+	}
+	dispatchedAt := time.Now().UTC()
+
+	expectedResponse := DispatchStatus{
+		Alert:        *alert,
+		OutputID:     *alertOutput.OutputID,
+		StatusCode:   200,
+		Success:      true,
+		Message:      "messageId",
+		NeedsRetry:   false,
+		DispatchedAt: dispatchedAt,
+	}
+	ctx := context.Background()
+	mockClient.On("Email", ctx, alert, emailConfig).Return(&outputs.AlertDeliveryResponse{
+		StatusCode: 200,
+		Message:    "messageId",
+		Success:    true,
+	}).Once()
+	go sendAlert(ctx, alert, alertOutput, dispatchedAt, ch, outputClient)
+	assert.Equal(t, expectedResponse, <-ch)
+	mockClient.AssertExpectations(t)
+}
+
 func TestSendUnsupportedOutput(t *testing.T) {
 	mockClient := &mockOutputsClient{}
 	outputClient = mockClient
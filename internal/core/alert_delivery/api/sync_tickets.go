@@ -0,0 +1,210 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	userModels "github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/tickets"
+	"github.com/panther-labs/panther/pkg/genericapi"
+)
+
+const maxConcurrentTicketSyncs = 10
+
+// trackTickets - starts syncing the tickets created by alert deliveries
+func trackTickets(statuses []DispatchStatus) {
+	now := time.Now().UTC()
+	for _, status := range statuses {
+		if status.Ticket == nil {
+			continue
+		}
+		alertIDs := []string{*status.Alert.AlertID}
+		if status.Alert.Digest != nil {
+			alertIDs = status.Alert.Digest.AlertIDs
+		}
+		ticket := &tickets.Ticket{
+			OutputID:   status.Ticket.OutputID,
+			OutputType: status.Ticket.OutputType,
+			Key:        status.Ticket.Key,
+			URL:        status.Ticket.URL,
+			AlertIDs:   alertIDs,
+		}
+		// We log, but do not return the error, the ticket was created and the alert delivered
+		if err := ticketStore.Put(ticket, now); err != nil {
+			zap.L().Error("failed to track ticket", zap.String("outputID", ticket.OutputID), zap.Error(err))
+		}
+	}
+}
+
+// SyncTickets syncs the status of alerts with the tickets created for them in Jira and GitHub.
+//
+// Alerts are resolved (or closed if the issue was not completed) when their ticket is closed,
+// and the status changes of alerts in Panther are added as comments to their ticket.
+func (API) SyncTickets(ctx context.Context, _ *deliverymodel.SyncTicketsInput) (interface{}, error) {
+	syncedTickets, err := ticketStore.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(syncedTickets) == 0 {
+		return nil, nil
+	}
+	alertOutputs, err := getOutputs()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get outputs")
+	}
+	outputsByID := make(map[string]*outputModels.AlertOutput, len(alertOutputs))
+	for _, output := range alertOutputs {
+		outputsByID[*output.OutputID] = output
+	}
+
+	// Stop syncing before the lambda times out, the remaining tickets are synced on the next run
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-softDeadlineDuration))
+		defer cancel()
+	}
+
+	semaphore := make(chan struct{}, maxConcurrentTicketSyncs)
+	var wg sync.WaitGroup
+	for _, ticket := range syncedTickets {
+		wg.Add(1)
+		go func(ticket *tickets.Ticket) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			if ctx.Err() != nil {
+				return
+			}
+			if err := syncTicket(ctx, ticket, outputsByID[ticket.OutputID]); err != nil {
+				zap.L().Warn("failed to sync ticket",
+					zap.String("outputID", ticket.OutputID), zap.String("key", ticket.Key), zap.Error(err))
+			}
+		}(ticket)
+	}
+	wg.Wait()
+	return nil, nil
+}
+
+// syncTicket - syncs the status of one ticket with its alerts
+func syncTicket(ctx context.Context, ticket *tickets.Ticket, output *outputModels.AlertOutput) error {
+	// Tickets of deleted outputs or outputs which no longer create tickets cannot be synced
+	if output == nil || *output.OutputType != ticket.OutputType {
+		return ticketStore.Delete(ticket)
+	}
+
+	status, err := getTicketStatus(ctx, ticket, output)
+	if err != nil {
+		return err
+	}
+	if status.Closed {
+		alertStatus := alertModels.ResolvedStatus
+		if !status.Completed {
+			alertStatus = alertModels.ClosedStatus
+		}
+		if err := updateAlertsStatus(ticket.AlertIDs, alertStatus); err != nil {
+			return err
+		}
+		zap.L().Info("ticket closed, updated alerts status",
+			zap.String("key", ticket.Key), zap.String("status", alertStatus), zap.Strings("alertIDs", ticket.AlertIDs))
+		return ticketStore.Delete(ticket)
+	}
+
+	changed := false
+	for _, alertID := range ticket.AlertIDs {
+		alertItem, err := alertsTableClient.GetAlert(alertID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get alert %s", alertID)
+		}
+		if alertItem == nil {
+			continue
+		}
+		alertStatus := alertItem.Status
+		if alertStatus == "" {
+			alertStatus = alertModels.OpenStatus
+		}
+		lastStatus, synced := ticket.AlertStatuses[alertID]
+		if lastStatus == alertStatus {
+			continue
+		}
+		// The first sync only records the status of the alert
+		if synced {
+			comment := fmt.Sprintf("Panther alert %s status changed from %s to %s", alertID, lastStatus, alertStatus)
+			if err := addTicketComment(ctx, ticket, comment, output); err != nil {
+				return err
+			}
+		}
+		if ticket.AlertStatuses == nil {
+			ticket.AlertStatuses = make(map[string]string, len(ticket.AlertIDs))
+		}
+		ticket.AlertStatuses[alertID] = alertStatus
+		changed = true
+	}
+	// Open tickets are kept as long as their status can be read
+	now := time.Now().UTC()
+	if changed || ticket.NeedsRefresh(now) {
+		return ticketStore.Put(ticket, now)
+	}
+	return nil
+}
+
+func getTicketStatus(ctx context.Context, ticket *tickets.Ticket, output *outputModels.AlertOutput) (*outputs.TicketStatus, error) {
+	switch ticket.OutputType {
+	case "jira":
+		return outputClient.JiraTicketStatus(ctx, ticket.Key, output.OutputConfig.Jira)
+	case "github":
+		return outputClient.GithubTicketStatus(ctx, ticket.Key, output.OutputConfig.Github)
+	default:
+		return nil, errors.Errorf("unsupported ticket output type %s", ticket.OutputType)
+	}
+}
+
+func addTicketComment(ctx context.Context, ticket *tickets.Ticket, comment string, output *outputModels.AlertOutput) error {
+	switch ticket.OutputType {
+	case "jira":
+		return outputClient.JiraComment(ctx, ticket.Key, comment, output.OutputConfig.Jira)
+	case "github":
+		return outputClient.GithubComment(ctx, ticket.Key, comment, output.OutputConfig.Github)
+	default:
+		return errors.Errorf("unsupported ticket output type %s", ticket.OutputType)
+	}
+}
+
+// updateAlertsStatus - invokes a lambda to update the status of alerts as the Panther system user
+func updateAlertsStatus(alertIDs []string, status string) error {
+	input := alertModels.LambdaInput{
+		UpdateAlertStatus: &alertModels.UpdateAlertStatusInput{
+			AlertIDs: alertIDs,
+			Status:   status,
+			UserID:   userModels.SystemUserID,
+		},
+	}
+	var response alertModels.UpdateAlertStatusOutput
+	return genericapi.Invoke(lambdaClient, env.AlertsAPI, &input, &response)
+}
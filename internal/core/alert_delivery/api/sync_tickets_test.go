@@ -0,0 +1,239 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	alertModels "github.com/panther-labs/panther/api/lambda/alerts/models"
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	userModels "github.com/panther-labs/panther/api/lambda/users/models"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/tickets"
+	alertTable "github.com/panther-labs/panther/internal/log_analysis/alerts_api/table"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+const (
+	ticketAlertID     = "0123456789abcdef0123456789abcdef"
+	ticketsTable      = "delivery-state"
+	ticketAlertsTable = "alerts"
+)
+
+func (m *mockOutputsClient) JiraTicketStatus(
+	ctx context.Context, key string, config *outputModels.JiraConfig) (*outputs.TicketStatus, error) {
+
+	args := m.Called(ctx, key, config)
+	return args.Get(0).(*outputs.TicketStatus), args.Error(1)
+}
+
+func (m *mockOutputsClient) GithubTicketStatus(
+	ctx context.Context, key string, config *outputModels.GithubConfig) (*outputs.TicketStatus, error) {
+
+	args := m.Called(ctx, key, config)
+	return args.Get(0).(*outputs.TicketStatus), args.Error(1)
+}
+
+func (m *mockOutputsClient) GithubComment(ctx context.Context, key, comment string, config *outputModels.GithubConfig) error {
+	args := m.Called(ctx, key, comment, config)
+	return args.Error(0)
+}
+
+// setupTicketSync mocks the clients used to sync tickets and returns the mocked table client
+func setupTicketSync(t *testing.T, syncedTickets []*tickets.Ticket, alertOutputs []*outputModels.AlertOutput) *testutils.DynamoDBMock {
+	ddbClient := &testutils.DynamoDBMock{}
+	ticketStore = &tickets.Store{Client: ddbClient, TableName: ticketsTable}
+	alertsTableClient = &alertTable.AlertsTable{AlertsTableName: ticketAlertsTable, Client: ddbClient}
+	outputsCache = &alertOutputsCache{RefreshInterval: time.Hour}
+	outputsCache.setOutputs(alertOutputs)
+	outputsCache.setExpiry(time.Now().UTC())
+
+	items := make([]map[string]*dynamodb.AttributeValue, len(syncedTickets))
+	for i, ticket := range syncedTickets {
+		item, err := dynamodbattribute.MarshalMap(ticket)
+		require.NoError(t, err)
+		items[i] = item
+	}
+	ddbClient.On("Scan", mock.Anything).Return(&dynamodb.ScanOutput{Items: items}, nil).Once()
+	return ddbClient
+}
+
+func TestSyncTicketsClosed(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+	mockLambda := &testutils.LambdaMock{}
+	lambdaClient = mockLambda
+
+	jira := &outputModels.JiraConfig{OrgDomain: "https://example.atlassian.net"}
+	ticket := &tickets.Ticket{
+		ID:         "ticket#output-id#PROJ-1",
+		OutputID:   "output-id",
+		OutputType: "jira",
+		Key:        "PROJ-1",
+		AlertIDs:   []string{ticketAlertID},
+	}
+	ddbClient := setupTicketSync(t, []*tickets.Ticket{ticket}, []*outputModels.AlertOutput{
+		{OutputID: aws.String("output-id"), OutputType: aws.String("jira"), OutputConfig: &outputModels.OutputConfig{Jira: jira}},
+	})
+
+	mockClient.On("JiraTicketStatus", mock.Anything, "PROJ-1", jira).
+		Return(&outputs.TicketStatus{Name: "Won't Do", Closed: true}, nil).Once()
+	payload, err := jsoniter.Marshal(alertModels.UpdateAlertStatusOutput{})
+	require.NoError(t, err)
+	mockLambda.On("Invoke", mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+		request := alertModels.LambdaInput{}
+		return jsoniter.Unmarshal(input.Payload, &request) == nil &&
+			request.UpdateAlertStatus != nil &&
+			request.UpdateAlertStatus.Status == alertModels.ClosedStatus &&
+			request.UpdateAlertStatus.UserID == userModels.SystemUserID &&
+			assert.Equal(t, []string{ticketAlertID}, request.UpdateAlertStatus.AlertIDs)
+	})).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+	ddbClient.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return aws.StringValue(input.Key["id"].S) == "ticket#output-id#PROJ-1"
+	})).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	_, err = (API{}).SyncTickets(context.Background(), &deliverymodel.SyncTicketsInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockLambda.AssertExpectations(t)
+	ddbClient.AssertExpectations(t)
+}
+
+func TestSyncTicketsComment(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+
+	github := &outputModels.GithubConfig{RepoName: "profile/reponame"}
+	ticket := &tickets.Ticket{
+		ID:            "ticket#output-id#7",
+		OutputID:      "output-id",
+		OutputType:    "github",
+		Key:           "7",
+		AlertIDs:      []string{ticketAlertID},
+		AlertStatuses: map[string]string{ticketAlertID: alertModels.OpenStatus},
+	}
+	ddbClient := setupTicketSync(t, []*tickets.Ticket{ticket}, []*outputModels.AlertOutput{
+		{OutputID: aws.String("output-id"), OutputType: aws.String("github"), OutputConfig: &outputModels.OutputConfig{Github: github}},
+	})
+
+	mockClient.On("GithubTicketStatus", mock.Anything, "7", github).Return(&outputs.TicketStatus{Name: "open"}, nil).Once()
+	alertItem, err := dynamodbattribute.MarshalMap(&alertTable.AlertItem{AlertID: ticketAlertID, Status: alertModels.TriagedStatus})
+	require.NoError(t, err)
+	ddbClient.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+		return aws.StringValue(input.TableName) == ticketAlertsTable
+	})).Return(&dynamodb.GetItemOutput{Item: alertItem}, nil).Once()
+	mockClient.On("GithubComment", mock.Anything, "7",
+		"Panther alert "+ticketAlertID+" status changed from OPEN to TRIAGED", github).Return(nil).Once()
+	ddbClient.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		saved := tickets.Ticket{}
+		return dynamodbattribute.UnmarshalMap(input.Item, &saved) == nil &&
+			saved.AlertStatuses[ticketAlertID] == alertModels.TriagedStatus
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	_, err = (API{}).SyncTickets(context.Background(), &deliverymodel.SyncTicketsInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	ddbClient.AssertExpectations(t)
+}
+
+func TestSyncTicketsRefresh(t *testing.T) {
+	mockClient := &mockOutputsClient{}
+	outputClient = mockClient
+
+	github := &outputModels.GithubConfig{RepoName: "profile/reponame"}
+	expiresAt := time.Now().Add(-time.Hour).Unix()
+	ticket := &tickets.Ticket{
+		ID:            "ticket#output-id#7",
+		OutputID:      "output-id",
+		OutputType:    "github",
+		Key:           "7",
+		AlertIDs:      []string{ticketAlertID},
+		AlertStatuses: map[string]string{ticketAlertID: alertModels.OpenStatus},
+		ExpiresAt:     expiresAt,
+	}
+	ddbClient := setupTicketSync(t, []*tickets.Ticket{ticket}, []*outputModels.AlertOutput{
+		{OutputID: aws.String("output-id"), OutputType: aws.String("github"), OutputConfig: &outputModels.OutputConfig{Github: github}},
+	})
+
+	mockClient.On("GithubTicketStatus", mock.Anything, "7", github).Return(&outputs.TicketStatus{Name: "open"}, nil).Once()
+	alertItem, err := dynamodbattribute.MarshalMap(&alertTable.AlertItem{AlertID: ticketAlertID, Status: alertModels.OpenStatus})
+	require.NoError(t, err)
+	ddbClient.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{Item: alertItem}, nil).Once()
+	// Open tickets are kept while their status can be read, even if their alerts did not change
+	ddbClient.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		saved := tickets.Ticket{}
+		return dynamodbattribute.UnmarshalMap(input.Item, &saved) == nil && saved.ExpiresAt > expiresAt
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	_, err = (API{}).SyncTickets(context.Background(), &deliverymodel.SyncTicketsInput{})
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	ddbClient.AssertExpectations(t)
+}
+
+func TestSyncTicketsDeletedOutput(t *testing.T) {
+	outputClient = &mockOutputsClient{}
+	ticket := &tickets.Ticket{ID: "ticket#output-id#7", OutputID: "output-id", OutputType: "github", Key: "7"}
+	ddbClient := setupTicketSync(t, []*tickets.Ticket{ticket}, nil)
+	ddbClient.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	_, err := (API{}).SyncTickets(context.Background(), &deliverymodel.SyncTicketsInput{})
+	require.NoError(t, err)
+	ddbClient.AssertExpectations(t)
+}
+
+func TestTrackTicketsDigest(t *testing.T) {
+	ddbClient := &testutils.DynamoDBMock{}
+	ticketStore = &tickets.Store{Client: ddbClient, TableName: ticketsTable}
+
+	statuses := []DispatchStatus{
+		{
+			Alert: deliverymodel.Alert{
+				AlertID: aws.String("digest-id"),
+				Digest:  &deliverymodel.AlertDigest{AlertIDs: []string{"alert-1", "alert-2"}},
+			},
+			OutputID: "output-id",
+			Ticket:   &alertModels.ExternalTicket{OutputID: "output-id", OutputType: "jira", Key: "PROJ-1"},
+		},
+		{
+			Alert:    deliverymodel.Alert{AlertID: aws.String("alert-3")},
+			OutputID: "slack-id",
+		},
+	}
+	ddbClient.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		saved := tickets.Ticket{}
+		return dynamodbattribute.UnmarshalMap(input.Item, &saved) == nil &&
+			saved.Key == "PROJ-1" && assert.Equal(t, []string{"alert-1", "alert-2"}, saved.AlertIDs)
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	trackTickets(statuses)
+	ddbClient.AssertExpectations(t)
+}
@@ -25,10 +25,13 @@ import (
 	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
+const maxConcurrentAlertUpdates = 20
+
 // updateAlerts - dispatches parallel lambda requests to update the alert statuses
 func updateAlerts(statuses []DispatchStatus) []*alertModels.AlertSummary {
 	// create a relational mapping for alertID to a list of delivery statuses
 	alertMap := make(map[string][]*alertModels.DeliveryResponse)
+	ticketMap := make(map[string][]*alertModels.ExternalTicket)
 	for _, status := range statuses {
 		// convert to the response type the lambda expects
 		deliveryResponse := &alertModels.DeliveryResponse{
@@ -38,17 +41,36 @@ func updateAlerts(statuses []DispatchStatus) []*alertModels.AlertSummary {
 			Success:      status.Success,
 			DispatchedAt: status.DispatchedAt,
 		}
-		alertMap[*status.Alert.AlertID] = append(alertMap[*status.Alert.AlertID], deliveryResponse)
+		// The delivery of a digest is recorded on the alerts grouped in it
+		alertIDs := []string{*status.Alert.AlertID}
+		if status.Alert.Digest != nil {
+			alertIDs = status.Alert.Digest.AlertIDs
+		}
+		for _, alertID := range alertIDs {
+			alertMap[alertID] = append(alertMap[alertID], deliveryResponse)
+			if status.Ticket != nil {
+				ticketMap[alertID] = append(ticketMap[alertID], status.Ticket)
+			}
+		}
 	}
 
+	// Start syncing the created tickets with their alerts
+	trackTickets(statuses)
+
 	// Init a channel
 	alertSummaryChannel := make(chan alertModels.AlertSummary)
 
 	// Make a lambda call for each alert in parallel. We dont make a single API call to reduce the failure impact.
 	zap.L().Debug("Invoking UpdateAlertDelivery in parallel")
 
+	// Digests can update many alerts at once, so the number of concurrent calls is limited.
+	semaphore := make(chan struct{}, maxConcurrentAlertUpdates)
 	for alertID, deliveryResponse := range alertMap {
-		go updateAlert(alertID, deliveryResponse, alertSummaryChannel)
+		go func(alertID string, deliveryResponse []*alertModels.DeliveryResponse) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			updateAlert(alertID, deliveryResponse, ticketMap[alertID], alertSummaryChannel)
+		}(alertID, deliveryResponse)
 	}
 
 	zap.L().Debug("Joining UpdateAlertDelivery results")
@@ -63,11 +85,18 @@ func updateAlerts(statuses []DispatchStatus) []*alertModels.AlertSummary {
 }
 
 // updateAlert - invokes a lambda to update an alert's delivery status
-func updateAlert(alertID string, deliveryResponse []*alertModels.DeliveryResponse, alertSummaryChannel chan alertModels.AlertSummary) {
+func updateAlert(
+	alertID string,
+	deliveryResponse []*alertModels.DeliveryResponse,
+	tickets []*alertModels.ExternalTicket,
+	alertSummaryChannel chan alertModels.AlertSummary,
+) {
+
 	input := alertModels.LambdaInput{
 		UpdateAlertDelivery: &alertModels.UpdateAlertDeliveryInput{
 			AlertID:           alertID,
 			DeliveryResponses: deliveryResponse,
+			Tickets:           tickets,
 		},
 	}
 	response := alertModels.UpdateAlertDeliveryOutput{}
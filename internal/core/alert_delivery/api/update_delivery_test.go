@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/lambda"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
@@ -134,6 +135,47 @@ func TestUpdateAlerts(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestUpdateAlertsDigest(t *testing.T) {
+	mockClient := &testutils.LambdaMock{}
+	lambdaClient = mockClient
+
+	dispatchedAt := time.Now().UTC()
+	statuses := []DispatchStatus{
+		{
+			Alert: deliverymodel.Alert{
+				AlertID:   aws.String("alert-id"),
+				Type:      deliverymodel.RuleType,
+				Severity:  "INFO",
+				CreatedAt: dispatchedAt,
+				Digest: &deliverymodel.AlertDigest{
+					AlertCount: 2,
+					AlertIDs:   []string{"alert-id-1", "alert-id-2"},
+				},
+			},
+			OutputID:     "output-id",
+			Message:      "success",
+			StatusCode:   200,
+			Success:      true,
+			DispatchedAt: dispatchedAt,
+		},
+	}
+
+	// The delivery of the digest is recorded on the grouped alerts only
+	for _, alertID := range []string{"alert-id-1", "alert-id-2"} {
+		alertID := alertID
+		payload, err := jsoniter.Marshal(alertModels.AlertSummary{AlertID: alertID})
+		require.NoError(t, err)
+		mockClient.On("Invoke", mock.MatchedBy(func(input *lambda.InvokeInput) bool {
+			request := alertModels.LambdaInput{}
+			return jsoniter.Unmarshal(input.Payload, &request) == nil && request.UpdateAlertDelivery.AlertID == alertID
+		})).Return(&lambda.InvokeOutput{Payload: payload}, nil).Once()
+	}
+
+	response := updateAlerts(statuses)
+	assert.Len(t, response, 2)
+	mockClient.AssertExpectations(t)
+}
+
 func TestUpdateAlert(t *testing.T) {
 	mockClient := &testutils.LambdaMock{}
 	lambdaClient = mockClient
@@ -176,7 +218,7 @@ func TestUpdateAlert(t *testing.T) {
 	mockLambdaResponse := &lambda.InvokeOutput{Payload: payload}
 	mockClient.On("Invoke", mock.Anything).Return(mockLambdaResponse, nil).Once()
 
-	go updateAlert(alertID, deliveryResponses, ch)
+	go updateAlert(alertID, deliveryResponses, nil, ch)
 	response := <-ch
 	assert.Equal(t, expectedResponse, response)
 	mockClient.AssertExpectations(t)
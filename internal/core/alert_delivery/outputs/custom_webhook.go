@@ -19,19 +19,127 @@ package outputs
  */
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"text/template"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
+const (
+	// CustomWebhookSignaturePrefix is the prefix of the signature header value, followed by the hex encoded HMAC-SHA256 of the body
+	CustomWebhookSignaturePrefix = "sha256="
+)
+
 // CustomWebhook alert send an alert.
 func (client *OutputClient) CustomWebhook(
 	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.CustomWebhookConfig) *AlertDeliveryResponse {
 
+	notification := generateNotificationFromAlert(alert)
 	postInput := &PostInput{
-		url:  config.WebhookURL,
-		body: generateNotificationFromAlert(alert),
+		url:    config.WebhookURL,
+		method: config.Method,
+		body:   notification,
+	}
+	for _, header := range config.Headers {
+		if postInput.headers == nil {
+			postInput.headers = make(map[string]string, len(config.Headers))
+		}
+		postInput.headers[header.Name] = header.Value
+	}
+	if config.BodyTemplate == "" && config.SignatureHeader == "" {
+		return client.httpWrapper.post(ctx, postInput)
+	}
+
+	var body []byte
+	var err error
+	if config.BodyTemplate != "" {
+		body, err = renderWebhookTemplate(config.BodyTemplate, &notification)
+	} else {
+		body, err = jsoniter.Marshal(&notification)
+	}
+	if err != nil {
+		return &AlertDeliveryResponse{
+			StatusCode: 500,
+			Success:    false,
+			Message:    "custom webhook body error: " + err.Error(),
+			Permanent:  true,
+		}
+	}
+	postInput.body = jsoniter.RawMessage(body)
+	if config.SignatureHeader != "" {
+		if postInput.headers == nil {
+			postInput.headers = make(map[string]string, 1)
+		}
+		postInput.headers[config.SignatureHeader] = signWebhookBody(config.SignatureSecret, body)
 	}
 	return client.httpWrapper.post(ctx, postInput)
 }
+
+// ValidateWebhookTemplate checks that a body template renders a valid JSON document for a sample alert
+func ValidateWebhookTemplate(text string) error {
+	alertID := "sample-alert-id"
+	alert := deliverymodel.Alert{
+		AlertID:    &alertID,
+		AnalysisID: "Sample.Rule",
+		Type:       deliverymodel.RuleType,
+		CreatedAt:  time.Now().UTC(),
+		Severity:   "INFO",
+		Title:      "Sample alert",
+	}
+	notification := generateNotificationFromAlert(&alert)
+	_, err := renderWebhookTemplate(text, &notification)
+	return err
+}
+
+// renderWebhookTemplate executes a body template over the fields of a notification.
+//
+// Besides the builtin template functions, templates can use
+//   - `json` to encode a value as JSON (ie `{"title": {{ json .Title }}}`)
+//   - `get` to look up a nested value by a dot separated path, returning nil if it is missing
+//     (ie `{{ json (get .AlertContext "user.name") }}`)
+func renderWebhookTemplate(text string, notification *Notification) ([]byte, error) {
+	tpl, err := template.New("body").Option("missingkey=zero").Funcs(webhookTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid body template")
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, notification); err != nil {
+		return nil, errors.Wrap(err, "failed to render body template")
+	}
+	if !jsoniter.Valid(buf.Bytes()) {
+		return nil, errors.New("body template did not render valid JSON, use the `json` function to encode values")
+	}
+	return buf.Bytes(), nil
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		return jsoniter.MarshalToString(value)
+	},
+	"get": func(value interface{}, path string) interface{} {
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = obj[key]
+		}
+		return value
+	},
+}
+
+func signWebhookBody(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	_, _ = h.Write(body)
+	return CustomWebhookSignaturePrefix + hex.EncodeToString(h.Sum(nil))
+}
@@ -20,10 +20,12 @@ package outputs
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/require"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
@@ -84,3 +86,89 @@ func TestCustomWebhookAlert(t *testing.T) {
 	require.Nil(t, client.CustomWebhook(ctx, alert, customWebhookConfig))
 	httpWrapper.AssertExpectations(t)
 }
+
+func TestCustomWebhookTemplate(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+	alert := &deliverymodel.Alert{
+		AlertID:    aws.String("alertId"),
+		AnalysisID: "ruleId",
+		Type:       deliverymodel.RuleType,
+		Severity:   "HIGH",
+		Title:      "Suspicious login",
+		Context: map[string]interface{}{
+			"user": map[string]interface{}{"name": "alice"},
+		},
+	}
+	config := &outputModels.CustomWebhookConfig{
+		WebhookURL: "custom-webhook-url",
+		Method:     http.MethodPut,
+		BodyTemplate: `{
+			"summary": {{ json .Title }},
+			"priority": {{ if eq .Severity "HIGH" "CRITICAL" }}1{{ else }}3{{ end }},
+			"user": {{ json (get .AlertContext "user.name") }},
+			"host": {{ json (get .AlertContext "host.name") }}
+		}`,
+		Headers: []outputModels.CustomWebhookHeader{
+			{Name: "Authorization", Value: "Bearer token", Secret: true},
+			{Name: "X-Source", Value: "panther"},
+		},
+		SignatureHeader: "X-Signature",
+		SignatureSecret: "secret",
+	}
+	body := `{
+			"summary": "New Alert: Suspicious login",
+			"priority": 1,
+			"user": "alice",
+			"host": null
+		}`
+	expectedPostInput := &PostInput{
+		url:    "custom-webhook-url",
+		method: http.MethodPut,
+		body:   jsoniter.RawMessage(body),
+		headers: map[string]string{
+			"Authorization": "Bearer token",
+			"X-Source":      "panther",
+			"X-Signature":   signWebhookBody("secret", []byte(body)),
+		},
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	require.Nil(t, client.CustomWebhook(ctx, alert, config))
+	httpWrapper.AssertExpectations(t)
+}
+
+func TestCustomWebhookInvalidTemplate(t *testing.T) {
+	client := &OutputClient{httpWrapper: &mockHTTPWrapper{}}
+	alert := &deliverymodel.Alert{
+		AlertID:    aws.String("alertId"),
+		AnalysisID: "ruleId",
+		Type:       deliverymodel.RuleType,
+	}
+	config := &outputModels.CustomWebhookConfig{
+		WebhookURL:   "custom-webhook-url",
+		BodyTemplate: `{"summary": {{ .Title }}}`,
+	}
+	response := client.CustomWebhook(context.Background(), alert, config)
+	require.NotNil(t, response)
+	require.False(t, response.Success)
+	require.True(t, response.Permanent)
+}
+
+func TestSignWebhookBody(t *testing.T) {
+	// echo -n '{"id":"foo"}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=60868fd70007967e1ee47fd9a06180c5260416c96a338be842e18fc998d4308e",
+		signWebhookBody("secret", []byte(`{"id":"foo"}`)))
+}
+
+func TestValidateWebhookTemplate(t *testing.T) {
+	require.NoError(t, ValidateWebhookTemplate(`{"title": {{ json .Title }}, "tags": {{ json .Tags }}}`))
+	require.NoError(t, ValidateWebhookTemplate(`{"user": {{ json (get .AlertContext "user.name") }}}`))
+	// Values must be encoded as JSON
+	require.Error(t, ValidateWebhookTemplate(`{"title": {{ .Title }}}`))
+	// Parse errors
+	require.Error(t, ValidateWebhookTemplate(`{"title": {{ json .Title }`))
+	// Unknown fields
+	require.Error(t, ValidateWebhookTemplate(`{"title": {{ json .Foo }}}`))
+}
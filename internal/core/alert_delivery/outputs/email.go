@@ -0,0 +1,345 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// Tests can replace these with mock implementations
+var (
+	getSesClient = buildSesClient
+	sendSMTPMail = sendMailSMTP
+	// smtpRootCAs verify the certificates of SMTP servers, the system roots are used if nil
+	smtpRootCAs *x509.CertPool
+)
+
+// SES clients are reused across alerts, there is one per region
+var sesClients = struct {
+	sync.Mutex
+	byRegion map[string]sesiface.SESAPI
+}{byRegion: make(map[string]sesiface.SESAPI)}
+
+const (
+	defaultSMTPPort        = 587
+	defaultSMTPImplicitTLS = 465
+	emailCharset           = "UTF-8"
+)
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="margin: 0; padding: 16px; font-family: Helvetica, Arial, sans-serif; color: #1c2f3f;">
+  <table width="100%" cellpadding="0" cellspacing="0" style="max-width: 640px;">
+    <tr>
+      <td style="border-left: 4px solid {{.Color}}; padding: 12px 16px;">
+        <h2 style="margin: 0 0 4px 0; font-size: 18px;">{{.Title}}</h2>
+        <p style="margin: 0; color: #5c6f80;">{{.Message}}</p>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 12px 16px;">
+        <table cellpadding="4" cellspacing="0">
+          {{- range .Facts}}
+          <tr>
+            <td style="font-weight: bold; vertical-align: top; white-space: nowrap;">{{.Name}}</td>
+            <td style="white-space: pre-wrap; word-break: break-word;">{{.Value}}</td>
+          </tr>
+          {{- end}}
+        </table>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 12px 16px;">
+        <a href="{{.Link}}"
+           style="display: inline-block; padding: 8px 16px; border-radius: 4px;
+                  background: #6967f4; color: #ffffff; text-decoration: none;">
+          View in Panther
+        </a>
+      </td>
+    </tr>
+  </table>
+</body>
+</html>
+`))
+
+// emailContent is the rendered email of an alert
+type emailContent struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Email sends an alert by email with Amazon SES or an SMTP server.
+func (client *OutputClient) Email(
+	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.EmailConfig) *AlertDeliveryResponse {
+
+	content, err := renderEmail(alert)
+	if err != nil {
+		errorMsg := "Failed to render email"
+		zap.L().Error(errorMsg, zap.Error(errors.WithStack(err)))
+		return &AlertDeliveryResponse{
+			StatusCode: 500,
+			Message:    errorMsg,
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+
+	if config.Transport == outputModels.EmailTransportSMTP {
+		return client.sendSMTPEmail(ctx, content, config)
+	}
+	return client.sendSESEmail(ctx, content, config)
+}
+
+func (client *OutputClient) sendSESEmail(
+	ctx context.Context, content *emailContent, config *outputModels.EmailConfig) *AlertDeliveryResponse {
+
+	input := &ses.SendEmailInput{
+		Source:      aws.String(config.From),
+		Destination: &ses.Destination{ToAddresses: aws.StringSlice(config.To)},
+		Message: &ses.Message{
+			Subject: &ses.Content{Charset: aws.String(emailCharset), Data: aws.String(content.Subject)},
+			Body: &ses.Body{
+				Html: &ses.Content{Charset: aws.String(emailCharset), Data: aws.String(content.HTML)},
+				Text: &ses.Content{Charset: aws.String(emailCharset), Data: aws.String(content.Text)},
+			},
+		},
+	}
+	response, err := getSesClient(client.session, config.SESRegion).SendEmailWithContext(ctx, input)
+	if err != nil {
+		zap.L().Error("Failed to send email with SES", zap.Error(err))
+		return getAlertResponseFromSESError(err)
+	}
+	return &AlertDeliveryResponse{
+		StatusCode: 200,
+		Message:    aws.StringValue(response.MessageId),
+		Permanent:  false,
+		Success:    true,
+	}
+}
+
+func (client *OutputClient) sendSMTPEmail(
+	ctx context.Context, content *emailContent, config *outputModels.EmailConfig) *AlertDeliveryResponse {
+
+	message, err := buildMIMEMessage(config.From, config.To, content, time.Now().UTC())
+	if err != nil {
+		return &AlertDeliveryResponse{
+			StatusCode: 500,
+			Message:    "Failed to build email: " + err.Error(),
+			Permanent:  true,
+			Success:    false,
+		}
+	}
+	if err := sendSMTPMail(ctx, config, message); err != nil {
+		zap.L().Warn("Failed to send email with SMTP", zap.Error(err))
+		response := getResponse(500, "smtp error: "+err.Error())
+		// Permanent SMTP errors (ie authentication failures or unknown mailboxes) are not retried
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			response.StatusCode = 400
+			response.Permanent = true
+		}
+		return response
+	}
+	return &AlertDeliveryResponse{
+		StatusCode: 200,
+		Message:    "email sent",
+		Permanent:  false,
+		Success:    true,
+	}
+}
+
+// renderEmail renders the subject and the plain text and HTML bodies of an alert email
+func renderEmail(alert *deliverymodel.Alert) (*emailContent, error) {
+	var html bytes.Buffer
+	err := emailTemplate.Execute(&html, map[string]interface{}{
+		"Color":   template.CSS(severityColors[alert.Severity]),
+		"Title":   generateAlertTitle(alert),
+		"Message": generateAlertMessage(alert),
+		"Facts":   generateAlertFacts(alert),
+		"Link":    generateURL(alert),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &emailContent{
+		Subject: removeNewLines(generateAlertTitle(alert)),
+		Text:    generateDetailedAlertMessage(alert),
+		HTML:    html.String(),
+	}, nil
+}
+
+// buildMIMEMessage builds a multipart email with alternative plain text and HTML bodies
+func buildMIMEMessage(from string, to []string, content *emailContent, date time.Time) ([]byte, error) {
+	var message bytes.Buffer
+	body := multipart.NewWriter(&message)
+	headers := []string{
+		"From: " + from,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode(emailCharset, content.Subject),
+		"Date: " + date.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + body.Boundary(),
+	}
+	message.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		// Clients display the last part they support
+		{contentType: "text/plain", content: content.Text},
+		{contentType: "text/html", content: content.HTML},
+	}
+	for _, part := range parts {
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=" + emailCharset},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// sendMailSMTP sends an email through an SMTP server, connections are always encrypted with TLS
+func sendMailSMTP(ctx context.Context, config *outputModels.EmailConfig, message []byte) error {
+	implicitTLS := config.SMTPTLS == outputModels.SMTPImplicitTLS
+	port := config.SMTPPort
+	if port == 0 {
+		port = defaultSMTPPort
+		if implicitTLS {
+			port = defaultSMTPImplicitTLS
+		}
+	}
+	address := net.JoinHostPort(config.SMTPHost, strconv.Itoa(port))
+	tlsConfig := &tls.Config{
+		ServerName: config.SMTPHost,
+		RootCAs:    smtpRootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	var conn net.Conn
+	var err error
+	if implicitTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to SMTP server")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	smtpClient, err := smtp.NewClient(conn, config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer smtpClient.Close()
+
+	if !implicitTLS {
+		if ok, _ := smtpClient.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := smtpClient.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if config.SMTPUserName != "" {
+		if err := smtpClient.Auth(smtp.PlainAuth("", config.SMTPUserName, config.SMTPPassword, config.SMTPHost)); err != nil {
+			return err
+		}
+	}
+	if err := smtpClient.Mail(config.From); err != nil {
+		return err
+	}
+	for _, recipient := range config.To {
+		if err := smtpClient.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	writer, err := smtpClient.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return smtpClient.Quit()
+}
+
+// buildSesClient returns the cached SES client of a region, an empty region uses the region of the session
+func buildSesClient(awsSession *session.Session, region string) sesiface.SESAPI {
+	sesClients.Lock()
+	defer sesClients.Unlock()
+	if client, ok := sesClients.byRegion[region]; ok {
+		return client
+	}
+	config := aws.NewConfig()
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+	client := ses.New(awsSession, config)
+	sesClients.byRegion[region] = client
+	return client
+}
@@ -0,0 +1,392 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+type mockSesClient struct {
+	sesiface.SESAPI
+	mock.Mock
+}
+
+func (m *mockSesClient) SendEmailWithContext(
+	ctx aws.Context, input *ses.SendEmailInput, options ...request.Option) (*ses.SendEmailOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*ses.SendEmailOutput), args.Error(1)
+}
+
+func emailAlert() *deliverymodel.Alert {
+	return &deliverymodel.Alert{
+		AlertID:             aws.String("alertId"),
+		AnalysisID:          "ruleId",
+		Type:                deliverymodel.RuleType,
+		CreatedAt:           time.Date(2019, 8, 3, 11, 40, 13, 0, time.UTC),
+		AnalysisName:        aws.String("ruleName"),
+		AnalysisDescription: "<script>alert(1)</script>",
+		Severity:            "MEDIUM",
+		Runbook:             "runbook",
+	}
+}
+
+func TestRenderEmail(t *testing.T) {
+	content, err := renderEmail(emailAlert())
+	require.NoError(t, err)
+	assert.Equal(t, "New Alert: ruleName", content.Subject)
+	assert.Equal(t, generateDetailedAlertMessage(emailAlert()), content.Text)
+	assert.Contains(t, content.HTML, "border-left: 4px solid #d9822b")
+	assert.Contains(t, content.HTML, `<a href="https://panther.io/alerts/alertId"`)
+	assert.Contains(t, content.HTML, "runbook")
+	// Alert fields are escaped
+	assert.Contains(t, content.HTML, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, content.HTML, "<script>")
+}
+
+func TestEmailSES(t *testing.T) {
+	client := &mockSesClient{}
+	outputClient := &OutputClient{}
+	config := &outputModels.EmailConfig{
+		From:      "alerts@example.com",
+		To:        []string{"security@example.com"},
+		SESRegion: "us-east-1",
+	}
+	getSesClient = func(_ *session.Session, region string) sesiface.SESAPI {
+		assert.Equal(t, "us-east-1", region)
+		return client
+	}
+	defer func() { getSesClient = buildSesClient }()
+
+	ctx := context.Background()
+	client.On("SendEmailWithContext", ctx, mock.MatchedBy(func(input *ses.SendEmailInput) bool {
+		return aws.StringValue(input.Source) == "alerts@example.com" &&
+			assert.Equal(t, []string{"security@example.com"}, aws.StringValueSlice(input.Destination.ToAddresses)) &&
+			aws.StringValue(input.Message.Subject.Data) == "New Alert: ruleName" &&
+			strings.HasPrefix(aws.StringValue(input.Message.Body.Html.Data), "<!DOCTYPE html>") &&
+			aws.StringValue(input.Message.Body.Text.Data) == generateDetailedAlertMessage(emailAlert())
+	})).Return(&ses.SendEmailOutput{MessageId: aws.String("messageId")}, nil).Once()
+
+	assert.Equal(t, &AlertDeliveryResponse{
+		StatusCode: 200,
+		Message:    "messageId",
+		Success:    true,
+	}, outputClient.Email(ctx, emailAlert(), config))
+
+	client.On("SendEmailWithContext", ctx, mock.Anything).
+		Return((*ses.SendEmailOutput)(nil), awserr.New(ses.ErrCodeMessageRejected, "Email address is not verified", nil)).Once()
+	response := outputClient.Email(ctx, emailAlert(), config)
+	assert.False(t, response.Success)
+	assert.Equal(t, 400, response.StatusCode)
+	client.AssertExpectations(t)
+}
+
+func TestEmailSMTP(t *testing.T) {
+	outputClient := &OutputClient{}
+	config := &outputModels.EmailConfig{
+		From:         "alerts@example.com",
+		To:           []string{"security@example.com", "oncall@example.com"},
+		Transport:    outputModels.EmailTransportSMTP,
+		SMTPHost:     "smtp.example.com",
+		SMTPUserName: "alerts",
+		SMTPPassword: "password",
+	}
+	var sent []byte
+	sendSMTPMail = func(_ context.Context, smtpConfig *outputModels.EmailConfig, message []byte) error {
+		assert.Equal(t, config, smtpConfig)
+		sent = message
+		return nil
+	}
+	defer func() { sendSMTPMail = sendMailSMTP }()
+
+	assert.Equal(t, &AlertDeliveryResponse{
+		StatusCode: 200,
+		Message:    "email sent",
+		Success:    true,
+	}, outputClient.Email(context.Background(), emailAlert(), config))
+
+	message, err := mail.ReadMessage(bytes.NewReader(sent))
+	require.NoError(t, err)
+	assert.Equal(t, "alerts@example.com", message.Header.Get("From"))
+	assert.Equal(t, "security@example.com, oncall@example.com", message.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "New Alert: ruleName", subject)
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	var contentTypes, bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			break
+		}
+		// The multipart reader decodes quoted-printable parts
+		body, err := ioutil.ReadAll(part)
+		require.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, contentTypes)
+	require.Len(t, bodies, 2)
+	// Line breaks of text parts are encoded as CRLF
+	assert.Equal(t, generateDetailedAlertMessage(emailAlert()), strings.ReplaceAll(bodies[0], "\r\n", "\n"))
+	assert.Contains(t, bodies[1], "View in Panther")
+}
+
+func TestEmailSMTPPermanentFailure(t *testing.T) {
+	outputClient := &OutputClient{}
+	config := &outputModels.EmailConfig{
+		From:      "alerts@example.com",
+		To:        []string{"security@example.com"},
+		Transport: outputModels.EmailTransportSMTP,
+		SMTPHost:  "smtp.example.com",
+	}
+	sendSMTPMail = func(context.Context, *outputModels.EmailConfig, []byte) error {
+		return &textproto.Error{Code: 535, Msg: "Authentication failed"}
+	}
+	defer func() { sendSMTPMail = sendMailSMTP }()
+
+	response := outputClient.Email(context.Background(), emailAlert(), config)
+	assert.False(t, response.Success)
+	assert.True(t, response.Permanent)
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+// testSMTPServer is a minimal SMTP server accepting one message per connection
+type testSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	// startTLS advertises the STARTTLS extension on plain connections
+	startTLS bool
+	password string
+	// rejectRcpt is a recipient refused by the server
+	rejectRcpt string
+	messages   chan []byte
+}
+
+// newTestSMTPServer starts an SMTP server on a local port.
+// Its certificate is valid for 127.0.0.1 and is trusted by sendMailSMTP until the test ends.
+func newTestSMTPServer(t *testing.T, implicitTLS bool) *testSMTPServer {
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	certServer.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(certServer.Certificate())
+	smtpRootCAs = roots
+	t.Cleanup(func() { smtpRootCAs = nil })
+
+	server := &testSMTPServer{
+		tlsConfig: &tls.Config{Certificates: certServer.TLS.Certificates},
+		startTLS:  true,
+		password:  "password",
+		messages:  make(chan []byte, 1),
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if implicitTLS {
+		listener = tls.NewListener(listener, server.tlsConfig)
+	}
+	server.listener = listener
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *testSMTPServer) config() *outputModels.EmailConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return &outputModels.EmailConfig{
+		From:         "alerts@example.com",
+		To:           []string{"security@example.com", "oncall@example.com"},
+		Transport:    outputModels.EmailTransportSMTP,
+		SMTPHost:     addr.IP.String(),
+		SMTPPort:     addr.Port,
+		SMTPUserName: "alerts",
+		SMTPPassword: s.password,
+	}
+}
+
+func (s *testSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testSMTPServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	_, isTLS := conn.(*tls.Conn)
+	text := textproto.NewConn(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			if text.PrintfLine("%s", line) != nil {
+				return
+			}
+		}
+	}
+	reply("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO":
+			if s.startTLS && !isTLS {
+				reply("250-localhost", "250 STARTTLS")
+			} else {
+				reply("250-localhost", "250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn, isTLS = tlsConn, true
+			text = textproto.NewConn(conn)
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			if string(credentials) != "\x00alerts\x00"+s.password {
+				reply("535 5.7.8 Authentication failed")
+				continue
+			}
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			reply("250 OK")
+		case "RCPT":
+			if s.rejectRcpt != "" && strings.Contains(line, "<"+s.rejectRcpt+">") {
+				reply("550 5.1.1 No such user")
+				continue
+			}
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			message, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.messages <- message
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSendMailSMTP(t *testing.T) {
+	message := []byte("Subject: test\r\n\r\nbody\r\n")
+	// The server reads messages with LF line endings
+	received := []byte("Subject: test\n\nbody\n")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("STARTTLS", func(t *testing.T) {
+		server := newTestSMTPServer(t, false)
+		require.NoError(t, sendMailSMTP(ctx, server.config(), message))
+		assert.Equal(t, received, <-server.messages)
+	})
+	t.Run("implicit TLS", func(t *testing.T) {
+		server := newTestSMTPServer(t, true)
+		config := server.config()
+		config.SMTPTLS = outputModels.SMTPImplicitTLS
+		require.NoError(t, sendMailSMTP(ctx, config, message))
+		assert.Equal(t, received, <-server.messages)
+	})
+	t.Run("STARTTLS required", func(t *testing.T) {
+		server := newTestSMTPServer(t, false)
+		server.startTLS = false
+		err := sendMailSMTP(ctx, server.config(), message)
+		require.Error(t, err)
+		assert.Equal(t, "SMTP server does not support STARTTLS", err.Error())
+		assert.Empty(t, server.messages)
+	})
+	t.Run("untrusted certificate", func(t *testing.T) {
+		server := newTestSMTPServer(t, false)
+		smtpRootCAs = x509.NewCertPool()
+		err := sendMailSMTP(ctx, server.config(), message)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "certificate")
+		assert.Empty(t, server.messages)
+	})
+	t.Run("auth error", func(t *testing.T) {
+		server := newTestSMTPServer(t, false)
+		config := server.config()
+		config.SMTPPassword = "wrong"
+		err := sendMailSMTP(ctx, config, message)
+		var smtpErr *textproto.Error
+		require.True(t, errors.As(err, &smtpErr), "unexpected error %v", err)
+		assert.Equal(t, 535, smtpErr.Code)
+		assert.Empty(t, server.messages)
+	})
+	t.Run("rcpt error", func(t *testing.T) {
+		server := newTestSMTPServer(t, false)
+		server.rejectRcpt = "oncall@example.com"
+		err := sendMailSMTP(ctx, server.config(), message)
+		var smtpErr *textproto.Error
+		require.True(t, errors.As(err, &smtpErr), "unexpected error %v", err)
+		assert.Equal(t, 550, smtpErr.Code)
+		assert.Empty(t, server.messages)
+	})
+}
+
+func TestBuildSesClient(t *testing.T) {
+	awsSession := session.Must(session.NewSession(aws.NewConfig().WithRegion("us-east-1")))
+	client := buildSesClient(awsSession, "us-west-2")
+	assert.Same(t, client, buildSesClient(awsSession, "us-west-2"))
+	assert.Equal(t, "us-west-2", aws.StringValue(client.(*ses.SES).Config.Region))
+	assert.NotSame(t, client, buildSesClient(awsSession, "eu-west-1"))
+}
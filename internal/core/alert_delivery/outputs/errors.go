@@ -33,6 +33,9 @@ type AlertDeliveryResponse struct {
 
 	// Success is true if we determine the request executed successfully. False otherwise.
 	Success bool
+
+	// Ticket is the issue created by ticketing outputs (Jira, GitHub), if any.
+	Ticket *Ticket
 }
 
 func (e *AlertDeliveryResponse) Error() string { return e.Message }
@@ -20,9 +20,13 @@ package outputs
 
 import (
 	"context"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
@@ -32,6 +36,10 @@ import (
 const (
 	githubEndpoint = "https://api.github.com/repos/"
 	requestType    = "/issues"
+
+	githubClosedState = "closed"
+	// State reason of issues closed as not planned, other closed issues are completed
+	githubNotPlannedReason = "not_planned"
 )
 
 // Github alert send an issue.
@@ -52,16 +60,71 @@ func (client *OutputClient) Github(
 		"body":  description + link + runBook + severity + tags + alertContext,
 	}
 
-	token := "token " + config.Token
 	repoURL := githubEndpoint + config.RepoName + requestType
-	requestHeader := map[string]string{
-		AuthorizationHTTPHeader: token,
-	}
+	requestHeader := githubHeaders(config)
 
 	postInput := &PostInput{
 		url:     repoURL,
 		body:    githubRequest,
 		headers: requestHeader,
 	}
-	return client.httpWrapper.post(ctx, postInput)
+	response := client.httpWrapper.post(ctx, postInput)
+	if response != nil && response.Success {
+		issue := struct {
+			Number  int    `json:"number"`
+			HTMLURL string `json:"html_url"`
+		}{}
+		if err := jsoniter.UnmarshalFromString(response.Message, &issue); err == nil && issue.Number != 0 {
+			response.Ticket = &Ticket{Key: strconv.Itoa(issue.Number), URL: issue.HTMLURL}
+		}
+	}
+	return response
+}
+
+// GithubTicketStatus returns the status of a GitHub issue.
+func (client *OutputClient) GithubTicketStatus(ctx context.Context, key string, config *outputModels.GithubConfig) (*TicketStatus, error) {
+	postInput := &PostInput{
+		url:     githubEndpoint + config.RepoName + requestType + "/" + url.PathEscape(key),
+		method:  http.MethodGet,
+		headers: githubHeaders(config),
+	}
+	response := client.httpWrapper.post(ctx, postInput)
+	if response == nil || !response.Success {
+		return nil, ticketError(response)
+	}
+
+	issue := struct {
+		State       string `json:"state"`
+		StateReason string `json:"state_reason"`
+	}{}
+	if err := jsoniter.UnmarshalFromString(response.Message, &issue); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse GitHub issue %s", key)
+	}
+	status := &TicketStatus{
+		Name:   issue.State,
+		Closed: issue.State == githubClosedState,
+	}
+	if status.Closed {
+		status.Completed = issue.StateReason != githubNotPlannedReason
+	}
+	return status, nil
+}
+
+// GithubComment adds a comment to a GitHub issue.
+func (client *OutputClient) GithubComment(ctx context.Context, key, comment string, config *outputModels.GithubConfig) error {
+	postInput := &PostInput{
+		url:     githubEndpoint + config.RepoName + requestType + "/" + url.PathEscape(key) + "/comments",
+		body:    map[string]string{"body": comment},
+		headers: githubHeaders(config),
+	}
+	if response := client.httpWrapper.post(ctx, postInput); response == nil || !response.Success {
+		return ticketError(response)
+	}
+	return nil
+}
+
+func githubHeaders(config *outputModels.GithubConfig) map[string]string {
+	return map[string]string{
+		AuthorizationHTTPHeader: "token " + config.Token,
+	}
 }
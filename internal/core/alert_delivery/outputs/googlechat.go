@@ -0,0 +1,82 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// GoogleChat sends an alert to a Google Chat space as a card.
+func (client *OutputClient) GoogleChat(
+	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.GoogleChatConfig) *AlertDeliveryResponse {
+
+	widgets := []map[string]interface{}{}
+	for _, fact := range generateAlertFacts(alert) {
+		text := fact.Value
+		if fact.Name == "Severity" {
+			text = `<font color="` + severityColors[alert.Severity] + `">` + alert.Severity + `</font>`
+		}
+		widgets = append(widgets, map[string]interface{}{
+			"decoratedText": map[string]interface{}{
+				"topLabel": fact.Name,
+				"text":     text,
+				"wrapText": true,
+			},
+		})
+	}
+	widgets = append(widgets, map[string]interface{}{
+		"buttonList": map[string]interface{}{
+			"buttons": []map[string]interface{}{
+				{
+					"text": "View in Panther",
+					"onClick": map[string]interface{}{
+						"openLink": map[string]string{"url": generateURL(alert)},
+					},
+				},
+			},
+		},
+	})
+
+	payload := map[string]interface{}{
+		"fallbackText": generateAlertTitle(alert),
+		"cardsV2": []map[string]interface{}{
+			{
+				"cardId": "panther-alert",
+				"card": map[string]interface{}{
+					"header": map[string]string{
+						"title":    generateAlertTitle(alert),
+						"subtitle": generateAlertMessage(alert),
+					},
+					"sections": []map[string]interface{}{
+						{"widgets": widgets},
+					},
+				},
+			},
+		},
+	}
+
+	postInput := &PostInput{
+		url:  config.WebhookURL,
+		body: payload,
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
@@ -0,0 +1,118 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var googleChatConfig = &outputModels.GoogleChatConfig{
+	WebhookURL: "https://chat.googleapis.com/v1/spaces/space/messages?key=key",
+}
+
+func TestGoogleChatAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	var createdAtTime, _ = time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	alert := &deliverymodel.Alert{
+		AlertID:      aws.String("alertId"),
+		AnalysisID:   "policyId",
+		Type:         deliverymodel.PolicyType,
+		CreatedAt:    createdAtTime,
+		OutputIds:    []string{"output-id"},
+		AnalysisName: aws.String("policyName"),
+		Severity:     "HIGH",
+		Runbook:      "runbook",
+		Context:      map[string]interface{}{"key": "value"},
+	}
+
+	googleChatPayload := map[string]interface{}{
+		"fallbackText": "Policy Failure: policyName",
+		"cardsV2": []map[string]interface{}{
+			{
+				"cardId": "panther-alert",
+				"card": map[string]interface{}{
+					"header": map[string]string{
+						"title":    "Policy Failure: policyName",
+						"subtitle": "policyName failed on new resources",
+					},
+					"sections": []map[string]interface{}{
+						{
+							"widgets": []map[string]interface{}{
+								{
+									"decoratedText": map[string]interface{}{
+										"topLabel": "Severity",
+										"text":     `<font color="#cb2e2e">HIGH</font>`,
+										"wrapText": true,
+									},
+								},
+								{
+									"decoratedText": map[string]interface{}{
+										"topLabel": "Runbook",
+										"text":     "runbook",
+										"wrapText": true,
+									},
+								},
+								{
+									"decoratedText": map[string]interface{}{
+										"topLabel": "AlertContext",
+										"text":     `{"key":"value"}`,
+										"wrapText": true,
+									},
+								},
+								{
+									"buttonList": map[string]interface{}{
+										"buttons": []map[string]interface{}{
+											{
+												"text": "View in Panther",
+												"onClick": map[string]interface{}{
+													"openLink": map[string]string{"url": "https://panther.io/alerts/alertId"},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	requestURL := googleChatConfig.WebhookURL
+	expectedPostInput := &PostInput{
+		url:  requestURL,
+		body: googleChatPayload,
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	assert.Nil(t, client.GoogleChat(ctx, alert, googleChatConfig))
+	httpWrapper.AssertExpectations(t)
+}
@@ -21,17 +21,23 @@ package outputs
 import (
 	"context"
 	"encoding/base64"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
 
 	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
 	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
 const (
-	jiraEndpoint = "/rest/api/latest/issue/"
+	jiraEndpoint   = "/rest/api/latest/issue/"
+	jiraBrowsePath = "/browse/"
+	// Status category of all the statuses of done issues
+	jiraDoneCategory = "done"
 )
 
 // Jira alert send an issue.
@@ -71,17 +77,91 @@ func (client *OutputClient) Jira(
 		"fields": fields,
 	}
 
-	auth := config.UserName + ":" + config.APIKey
-	basicAuthToken := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
 	jiraRestURL := config.OrgDomain + jiraEndpoint
-	requestHeader := map[string]string{
-		AuthorizationHTTPHeader: basicAuthToken,
-	}
+	requestHeader := jiraHeaders(config)
 
 	postInput := &PostInput{
 		url:     jiraRestURL,
 		body:    jiraRequest,
 		headers: requestHeader,
 	}
-	return client.httpWrapper.post(ctx, postInput)
+	response := client.httpWrapper.post(ctx, postInput)
+	if response != nil && response.Success {
+		issue := struct {
+			Key string `json:"key"`
+		}{}
+		if err := jsoniter.UnmarshalFromString(response.Message, &issue); err == nil && issue.Key != "" {
+			response.Ticket = &Ticket{Key: issue.Key, URL: config.OrgDomain + jiraBrowsePath + issue.Key}
+		}
+	}
+	return response
+}
+
+// JiraTicketStatus returns the status of a Jira issue.
+func (client *OutputClient) JiraTicketStatus(ctx context.Context, key string, config *outputModels.JiraConfig) (*TicketStatus, error) {
+	postInput := &PostInput{
+		url:     config.OrgDomain + jiraEndpoint + url.PathEscape(key) + "?fields=status,resolution",
+		method:  http.MethodGet,
+		headers: jiraHeaders(config),
+	}
+	response := client.httpWrapper.post(ctx, postInput)
+	if response == nil || !response.Success {
+		return nil, ticketError(response)
+	}
+
+	issue := struct {
+		Fields struct {
+			Status struct {
+				Name           string `json:"name"`
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"status"`
+			Resolution *struct {
+				Name string `json:"name"`
+			} `json:"resolution"`
+		} `json:"fields"`
+	}{}
+	if err := jsoniter.UnmarshalFromString(response.Message, &issue); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse Jira issue %s", key)
+	}
+	status := &TicketStatus{
+		Name:   issue.Fields.Status.Name,
+		Closed: issue.Fields.Status.StatusCategory.Key == jiraDoneCategory,
+	}
+	if status.Closed {
+		// Issues closed without a resolution are considered done
+		status.Completed = issue.Fields.Resolution == nil || isJiraCompletedResolution(issue.Fields.Resolution.Name)
+	}
+	return status, nil
+}
+
+// JiraComment adds a comment to a Jira issue.
+func (client *OutputClient) JiraComment(ctx context.Context, key, comment string, config *outputModels.JiraConfig) error {
+	postInput := &PostInput{
+		url:     config.OrgDomain + jiraEndpoint + url.PathEscape(key) + "/comment",
+		body:    map[string]string{"body": comment},
+		headers: jiraHeaders(config),
+	}
+	if response := client.httpWrapper.post(ctx, postInput); response == nil || !response.Success {
+		return ticketError(response)
+	}
+	return nil
+}
+
+func jiraHeaders(config *outputModels.JiraConfig) map[string]string {
+	auth := config.UserName + ":" + config.APIKey
+	return map[string]string{
+		AuthorizationHTTPHeader: "Basic " + base64.StdEncoding.EncodeToString([]byte(auth)),
+	}
+}
+
+// isJiraCompletedResolution returns false for the default Jira resolutions of issues closed without a fix
+func isJiraCompletedResolution(resolution string) bool {
+	switch strings.ToLower(resolution) {
+	case "won't do", "won't fix", "duplicate", "cannot reproduce", "declined":
+		return false
+	default:
+		return true
+	}
 }
@@ -0,0 +1,63 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// Mattermost sends an alert to a Mattermost channel as a message attachment.
+func (client *OutputClient) Mattermost(
+	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.MattermostConfig) *AlertDeliveryResponse {
+
+	fields := []map[string]interface{}{}
+	for _, fact := range generateAlertFacts(alert) {
+		fields = append(fields, map[string]interface{}{
+			"title": fact.Name,
+			"value": fact.Value,
+			// Only the severity is short enough to be displayed side by side
+			"short": fact.Name == "Severity",
+		})
+	}
+
+	payload := map[string]interface{}{
+		"attachments": []map[string]interface{}{
+			{
+				"fallback":   generateAlertTitle(alert),
+				"color":      severityColors[alert.Severity],
+				"title":      generateAlertTitle(alert),
+				"title_link": generateURL(alert),
+				"text":       generateAlertMessage(alert),
+				"fields":     fields,
+			},
+		},
+	}
+	if config.Channel != "" {
+		payload["channel"] = config.Channel
+	}
+
+	postInput := &PostInput{
+		url:  config.WebhookURL,
+		body: payload,
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
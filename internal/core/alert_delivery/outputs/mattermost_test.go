@@ -0,0 +1,82 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var mattermostConfig = &outputModels.MattermostConfig{
+	WebhookURL: "https://mattermost.example.com/hooks/hook-id",
+	Channel:    "security-alerts",
+}
+
+func TestMattermostAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	var createdAtTime, _ = time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	alert := &deliverymodel.Alert{
+		AlertID:             aws.String("alertId"),
+		AnalysisID:          "ruleId",
+		Type:                deliverymodel.RuleType,
+		CreatedAt:           createdAtTime,
+		OutputIds:           []string{"output-id"},
+		AnalysisName:        aws.String("ruleName"),
+		AnalysisDescription: "description",
+		Severity:            "INFO",
+		Tags:                []string{"tag1", "tag2"},
+	}
+
+	mattermostPayload := map[string]interface{}{
+		"channel": "security-alerts",
+		"attachments": []map[string]interface{}{
+			{
+				"fallback":   "New Alert: ruleName",
+				"color":      "#47b881",
+				"title":      "New Alert: ruleName",
+				"title_link": "https://panther.io/alerts/alertId",
+				"text":       "ruleName triggered",
+				"fields": []map[string]interface{}{
+					{"title": "Severity", "value": "INFO", "short": true},
+					{"title": "Description", "value": "description", "short": false},
+					{"title": "Tags", "value": "tag1, tag2", "short": false},
+				},
+			},
+		},
+	}
+
+	expectedPostInput := &PostInput{
+		url:  mattermostConfig.WebhookURL,
+		body: mattermostPayload,
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	assert.Nil(t, client.Mattermost(ctx, alert, mattermostConfig))
+	httpWrapper.AssertExpectations(t)
+}
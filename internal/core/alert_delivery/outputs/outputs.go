@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -47,6 +48,7 @@ type HTTPWrapper struct {
 // PostInput type
 type PostInput struct {
 	url     string
+	method  string // defaults to POST
 	body    interface{}
 	headers map[string]string
 }
@@ -73,6 +75,16 @@ type API interface {
 	Sns(context.Context, *deliverymodel.Alert, *outputModels.SnsConfig) *AlertDeliveryResponse
 	Asana(context.Context, *deliverymodel.Alert, *outputModels.AsanaConfig) *AlertDeliveryResponse
 	CustomWebhook(context.Context, *deliverymodel.Alert, *outputModels.CustomWebhookConfig) *AlertDeliveryResponse
+	Email(context.Context, *deliverymodel.Alert, *outputModels.EmailConfig) *AlertDeliveryResponse
+	GoogleChat(context.Context, *deliverymodel.Alert, *outputModels.GoogleChatConfig) *AlertDeliveryResponse
+	Mattermost(context.Context, *deliverymodel.Alert, *outputModels.MattermostConfig) *AlertDeliveryResponse
+	Webex(context.Context, *deliverymodel.Alert, *outputModels.WebexConfig) *AlertDeliveryResponse
+
+	// Tickets created by the Jira and GitHub outputs
+	JiraTicketStatus(context.Context, string, *outputModels.JiraConfig) (*TicketStatus, error)
+	JiraComment(context.Context, string, string, *outputModels.JiraConfig) error
+	GithubTicketStatus(context.Context, string, *outputModels.GithubConfig) (*TicketStatus, error)
+	GithubComment(context.Context, string, string, *outputModels.GithubConfig) error
 }
 
 // OutputClient encapsulates the clients that allow sending alerts to multiple outputs
@@ -196,6 +208,9 @@ func generateAlertTitle(alert *deliverymodel.Alert) string {
 	if alert.IsResent {
 		return "[Re-sent]: " + alert.Title
 	}
+	if alert.Digest != nil {
+		return "Alert Digest: " + alert.Title
+	}
 	switch alert.Type {
 	case deliverymodel.RuleType:
 		if alert.Title != "" {
@@ -218,6 +233,36 @@ func getDisplayName(alert *deliverymodel.Alert) string {
 	return alert.AnalysisID
 }
 
+// alertFact is a detail of an alert displayed by outputs with card layouts
+type alertFact struct {
+	Name  string
+	Value string
+}
+
+// generateAlertFacts returns the details of an alert displayed in cards, empty details are omitted
+func generateAlertFacts(alert *deliverymodel.Alert) []alertFact {
+	// Best effort attempt to marshal Alert Context
+	var alertContext string
+	if len(alert.Context) > 0 {
+		alertContext, _ = jsoniter.MarshalToString(alert.Context)
+	}
+	facts := []alertFact{
+		{Name: "Severity", Value: alert.Severity},
+		{Name: "Description", Value: alert.AnalysisDescription},
+		{Name: "Runbook", Value: alert.Runbook},
+		{Name: "Reference", Value: alert.Reference},
+		{Name: "Tags", Value: strings.Join(alert.Tags, ", ")},
+		{Name: "AlertContext", Value: alertContext},
+	}
+	nonEmpty := facts[:0]
+	for _, fact := range facts {
+		if fact.Value != "" {
+			nonEmpty = append(nonEmpty, fact)
+		}
+	}
+	return nonEmpty
+}
+
 func generateURL(alert *deliverymodel.Alert) string {
 	if alert.IsTest {
 		return appDomainURL
@@ -83,3 +83,12 @@ func TestGenerateAlertTitlePolicyId(t *testing.T) {
 	}
 	assert.Equal(t, "Policy Failure: policy.id", generateAlertTitle(alert))
 }
+
+func TestGenerateAlertTitleDigest(t *testing.T) {
+	alert := &alertModel.Alert{
+		Type:   alertModel.PolicyType,
+		Title:  "3 alerts from policy.id in the last 10 minutes",
+		Digest: &alertModel.AlertDigest{AlertCount: 3},
+	}
+	assert.Equal(t, "Alert Digest: 3 alerts from policy.id in the last 10 minutes", generateAlertTitle(alert))
+}
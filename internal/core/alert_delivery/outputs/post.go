@@ -32,20 +32,29 @@ const (
 )
 
 // post sends a JSON body to an endpoint.
+// Requests without a body (ie GET requests) are sent with an empty body.
 func (client *HTTPWrapper) post(ctx context.Context, input *PostInput) *AlertDeliveryResponse {
-	payload, err := jsoniter.Marshal(input.body)
-
-	// If there was an error marshaling the input
-	if err != nil {
-		return &AlertDeliveryResponse{
-			StatusCode: 500, // Internal server error
-			Success:    false,
-			Message:    "json marshal error: " + err.Error(),
-			Permanent:  true,
+	var payload []byte
+	if input.body != nil {
+		var err error
+		payload, err = jsoniter.Marshal(input.body)
+
+		// If there was an error marshaling the input
+		if err != nil {
+			return &AlertDeliveryResponse{
+				StatusCode: 500, // Internal server error
+				Success:    false,
+				Message:    "json marshal error: " + err.Error(),
+				Permanent:  true,
+			}
 		}
 	}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", input.url, bytes.NewBuffer(payload))
+	method := input.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	request, err := http.NewRequestWithContext(ctx, method, input.url, bytes.NewBuffer(payload))
 
 	// If there was an error creating the request
 	if err != nil {
@@ -31,9 +31,10 @@ import (
 
 type mockHTTPClient struct {
 	HTTPiface
-	statusCode   int
-	requestError bool
-	requestBody  string // Request body is saved here for tests to verify
+	statusCode    int
+	requestError  bool
+	requestBody   string // Request body is saved here for tests to verify
+	requestMethod string
 }
 
 const requestEndpoint = "https://runpanther.io"
@@ -47,6 +48,7 @@ func (m *mockHTTPClient) Do(request *http.Request) (*http.Response, error) {
 		panic(err)
 	}
 	m.requestBody = string(requestBytes)
+	m.requestMethod = request.Method
 
 	responseBody := ioutil.NopCloser(bytes.NewReader([]byte("response")))
 	return &http.Response{Body: responseBody, StatusCode: m.statusCode}, nil
@@ -112,3 +114,20 @@ func TestPostCreated(t *testing.T) {
 		Permanent:  false,
 	}, c.post(ctx, postInput))
 }
+
+func TestPostMethod(t *testing.T) {
+	httpClient := &mockHTTPClient{statusCode: http.StatusOK}
+	c := &HTTPWrapper{httpClient: httpClient}
+	postInput := &PostInput{
+		url:  requestEndpoint,
+		body: map[string]interface{}{"abc": 123},
+	}
+	ctx := context.Background()
+	c.post(ctx, postInput)
+	assert.Equal(t, http.MethodPost, httpClient.requestMethod)
+
+	postInput.method = http.MethodPut
+	c.post(ctx, postInput)
+	assert.Equal(t, http.MethodPut, httpClient.requestMethod)
+	assert.Equal(t, `{"abc":123}`, httpClient.requestBody)
+}
@@ -0,0 +1,47 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import "github.com/pkg/errors"
+
+// Ticket identifies an issue created in an external ticketing system
+type Ticket struct {
+	// Key identifies the issue in the ticketing system (ie `PROJ-123` for Jira or the issue number for GitHub)
+	Key string
+	// URL is the link to the issue
+	URL string
+}
+
+// TicketStatus is the status of an issue in an external ticketing system
+type TicketStatus struct {
+	// Name is the status name in the ticketing system
+	Name string
+	// Closed is true if the issue is done
+	Closed bool
+	// Completed is true if a closed issue was fixed, as opposed to being closed as a duplicate or won't do
+	Completed bool
+}
+
+// ticketError returns the error of a failed ticket request
+func ticketError(response *AlertDeliveryResponse) error {
+	if response == nil {
+		return errors.New("ticket request failed: empty response")
+	}
+	return errors.Wrap(response, "ticket request failed")
+}
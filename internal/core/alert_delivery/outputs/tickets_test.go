@@ -0,0 +1,180 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+)
+
+// stubTicketAPI serves canned responses of a ticketing system API by method and URL
+type stubTicketAPI struct {
+	HTTPiface
+	responses map[string]string
+	requests  map[string]string // Request bodies are saved here for tests to verify
+}
+
+func (s *stubTicketAPI) Do(request *http.Request) (*http.Response, error) {
+	route := request.Method + " " + request.URL.String()
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		panic(err)
+	}
+	if s.requests == nil {
+		s.requests = make(map[string]string)
+	}
+	s.requests[route] = string(body)
+
+	response, ok := s.responses[route]
+	statusCode := http.StatusOK
+	if !ok {
+		statusCode = http.StatusNotFound
+	}
+	return &http.Response{
+		Status:     http.StatusText(statusCode),
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(response))),
+	}, nil
+}
+
+func newStubTicketClient(responses map[string]string) (*OutputClient, *stubTicketAPI) {
+	api := &stubTicketAPI{responses: responses}
+	return &OutputClient{httpWrapper: &HTTPWrapper{httpClient: api}}, api
+}
+
+func TestJiraAlertTicket(t *testing.T) {
+	client, _ := newStubTicketClient(map[string]string{
+		"POST https://panther-labs.atlassian.net/rest/api/latest/issue/": `{"id":"10001","key":"QR-12"}`,
+	})
+	alert := &deliverymodel.Alert{AlertID: aws.String("alertId"), AnalysisID: "policyId", Type: deliverymodel.PolicyType}
+
+	response := client.Jira(context.Background(), alert, jiraConfig)
+	require.True(t, response.Success)
+	assert.Equal(t, &Ticket{Key: "QR-12", URL: "https://panther-labs.atlassian.net/browse/QR-12"}, response.Ticket)
+}
+
+func TestJiraTicketStatus(t *testing.T) {
+	issueURL := "GET https://panther-labs.atlassian.net/rest/api/latest/issue/QR-12?fields=status,resolution"
+	testCases := []struct {
+		name     string
+		response string
+		expected *TicketStatus
+	}{
+		{
+			name:     "open",
+			response: `{"fields":{"status":{"name":"In Progress","statusCategory":{"key":"indeterminate"}},"resolution":null}}`,
+			expected: &TicketStatus{Name: "In Progress"},
+		},
+		{
+			name:     "done",
+			response: `{"fields":{"status":{"name":"Done","statusCategory":{"key":"done"}},"resolution":{"name":"Done"}}}`,
+			expected: &TicketStatus{Name: "Done", Closed: true, Completed: true},
+		},
+		{
+			name:     "won't do",
+			response: `{"fields":{"status":{"name":"Closed","statusCategory":{"key":"done"}},"resolution":{"name":"Won't Do"}}}`,
+			expected: &TicketStatus{Name: "Closed", Closed: true},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			client, _ := newStubTicketClient(map[string]string{issueURL: tc.response})
+			status, err := client.JiraTicketStatus(context.Background(), "QR-12", jiraConfig)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestJiraTicketStatusNotFound(t *testing.T) {
+	client, _ := newStubTicketClient(nil)
+	_, err := client.JiraTicketStatus(context.Background(), "QR-12", jiraConfig)
+	assert.Error(t, err)
+}
+
+func TestJiraComment(t *testing.T) {
+	commentURL := "POST https://panther-labs.atlassian.net/rest/api/latest/issue/QR-12/comment"
+	client, api := newStubTicketClient(map[string]string{commentURL: `{"id":"1"}`})
+
+	require.NoError(t, client.JiraComment(context.Background(), "QR-12", "status changed", jiraConfig))
+	assert.JSONEq(t, `{"body":"status changed"}`, api.requests[commentURL])
+}
+
+func TestGithubAlertTicket(t *testing.T) {
+	client, _ := newStubTicketClient(map[string]string{
+		"POST https://api.github.com/repos/profile/reponame/issues": `{"number":7,"html_url":"https://github.com/profile/reponame/issues/7"}`,
+	})
+	alert := &deliverymodel.Alert{AlertID: aws.String("alertId"), AnalysisID: "policyId", Type: deliverymodel.PolicyType}
+
+	response := client.Github(context.Background(), alert, githubConfig)
+	require.True(t, response.Success)
+	assert.Equal(t, &Ticket{Key: "7", URL: "https://github.com/profile/reponame/issues/7"}, response.Ticket)
+}
+
+func TestGithubTicketStatus(t *testing.T) {
+	issueURL := "GET https://api.github.com/repos/profile/reponame/issues/7"
+	testCases := []struct {
+		name     string
+		response string
+		expected *TicketStatus
+	}{
+		{
+			name:     "open",
+			response: `{"state":"open","state_reason":null}`,
+			expected: &TicketStatus{Name: "open"},
+		},
+		{
+			name:     "completed",
+			response: `{"state":"closed","state_reason":"completed"}`,
+			expected: &TicketStatus{Name: "closed", Closed: true, Completed: true},
+		},
+		{
+			name:     "not planned",
+			response: `{"state":"closed","state_reason":"not_planned"}`,
+			expected: &TicketStatus{Name: "closed", Closed: true},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			client, _ := newStubTicketClient(map[string]string{issueURL: tc.response})
+			status, err := client.GithubTicketStatus(context.Background(), "7", githubConfig)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestGithubComment(t *testing.T) {
+	commentURL := "POST https://api.github.com/repos/profile/reponame/issues/7/comments"
+	client, api := newStubTicketClient(map[string]string{commentURL: `{"id":1}`})
+
+	require.NoError(t, client.GithubComment(context.Background(), "7", "status changed", githubConfig))
+	assert.JSONEq(t, `{"body":"status changed"}`, api.requests[commentURL])
+}
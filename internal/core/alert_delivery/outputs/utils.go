@@ -21,6 +21,7 @@ import (
 	"regexp"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ses"
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/pkg/errors"
@@ -44,6 +45,15 @@ func getAlertResponseFromSNSError(err error) *AlertDeliveryResponse {
 	return getResponse(500, err.Error())
 }
 
+func getAlertResponseFromSESError(err error) *AlertDeliveryResponse {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		statusCode := mapSESSendEmailErrorCodeToStatusCode(awsErr)
+		return getResponse(statusCode, awsErr.Error())
+	}
+	return getResponse(500, err.Error())
+}
+
 // getResponse - generates a failed response that can be retried
 func getResponse(statusCode int, message string) *AlertDeliveryResponse {
 	return &AlertDeliveryResponse{
@@ -90,6 +100,24 @@ func mapSNSPublishErrorCodeToStatusCode(awsErr awserr.Error) int {
 	}
 }
 
+// Maps SES.SendEmail error codes to response status codes
+func mapSESSendEmailErrorCodeToStatusCode(awsErr awserr.Error) int {
+	switch awsErr.Code() {
+	case ses.ErrCodeMessageRejected:
+		return 400
+	case ses.ErrCodeMailFromDomainNotVerifiedException:
+		return 400
+	case ses.ErrCodeConfigurationSetDoesNotExistException:
+		return 404
+	case ses.ErrCodeConfigurationSetSendingPausedException:
+		return 403
+	case ses.ErrCodeAccountSendingPausedException:
+		return 403
+	default:
+		return 500
+	}
+}
+
 // Maps SQS.SendMessage error codes to response status codes
 func mapSQSSendMessageErrorCodeToStatusCode(awsErr awserr.Error) int {
 	switch awsErr.Code() {
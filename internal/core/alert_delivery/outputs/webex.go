@@ -0,0 +1,105 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	webexEndpoint = "https://webexapis.com/v1/messages"
+	// Webex renders Adaptive Cards sent as message attachments
+	adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+)
+
+// Adaptive Card colors of severities
+var webexSeverityColors = map[string]string{
+	"CRITICAL": "Attention",
+	"HIGH":     "Attention",
+	"MEDIUM":   "Warning",
+	"LOW":      "Warning",
+	"INFO":     "Good",
+}
+
+// Webex posts an alert to a Webex space as an Adaptive Card.
+func (client *OutputClient) Webex(
+	ctx context.Context, alert *deliverymodel.Alert, config *outputModels.WebexConfig) *AlertDeliveryResponse {
+
+	facts := []map[string]string{}
+	for _, fact := range generateAlertFacts(alert) {
+		facts = append(facts, map[string]string{"title": fact.Name, "value": fact.Value})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.3",
+		"body": []map[string]interface{}{
+			{
+				"type":   "TextBlock",
+				"text":   generateAlertTitle(alert),
+				"size":   "Medium",
+				"weight": "Bolder",
+				"color":  webexSeverityColors[alert.Severity],
+				"wrap":   true,
+			},
+			{
+				"type":     "TextBlock",
+				"text":     generateAlertMessage(alert),
+				"isSubtle": true,
+				"wrap":     true,
+			},
+			{
+				"type":  "FactSet",
+				"facts": facts,
+			},
+		},
+		"actions": []map[string]interface{}{
+			{
+				"type":  "Action.OpenUrl",
+				"title": "View in Panther",
+				"url":   generateURL(alert),
+			},
+		},
+	}
+
+	payload := map[string]interface{}{
+		"roomId": config.RoomID,
+		// Clients that cannot render cards display the markdown
+		"markdown": "**" + generateAlertTitle(alert) + "**\n\n[Click here to view in the Panther UI](" + generateURL(alert) + ")",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": adaptiveCardContentType,
+				"content":     card,
+			},
+		},
+	}
+
+	postInput := &PostInput{
+		url:  webexEndpoint,
+		body: payload,
+		headers: map[string]string{
+			AuthorizationHTTPHeader: "Bearer " + config.BotToken,
+		},
+	}
+	return client.httpWrapper.post(ctx, postInput)
+}
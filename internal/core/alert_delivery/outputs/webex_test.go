@@ -0,0 +1,111 @@
+package outputs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+var webexConfig = &outputModels.WebexConfig{
+	BotToken: "bot-token",
+	RoomID:   "room-id",
+}
+
+func TestWebexAlert(t *testing.T) {
+	httpWrapper := &mockHTTPWrapper{}
+	client := &OutputClient{httpWrapper: httpWrapper}
+
+	var createdAtTime, _ = time.Parse(time.RFC3339, "2019-08-03T11:40:13Z")
+	alert := &deliverymodel.Alert{
+		AlertID:      aws.String("alertId"),
+		AnalysisID:   "policyId",
+		Type:         deliverymodel.PolicyType,
+		CreatedAt:    createdAtTime,
+		OutputIds:    []string{"output-id"},
+		AnalysisName: aws.String("policyName"),
+		Severity:     "CRITICAL",
+		Reference:    "reference",
+	}
+
+	webexPayload := map[string]interface{}{
+		"roomId":   "room-id",
+		"markdown": "**Policy Failure: policyName**\n\n[Click here to view in the Panther UI](https://panther.io/alerts/alertId)",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.3",
+					"body": []map[string]interface{}{
+						{
+							"type":   "TextBlock",
+							"text":   "Policy Failure: policyName",
+							"size":   "Medium",
+							"weight": "Bolder",
+							"color":  "Attention",
+							"wrap":   true,
+						},
+						{
+							"type":     "TextBlock",
+							"text":     "policyName failed on new resources",
+							"isSubtle": true,
+							"wrap":     true,
+						},
+						{
+							"type": "FactSet",
+							"facts": []map[string]string{
+								{"title": "Severity", "value": "CRITICAL"},
+								{"title": "Reference", "value": "reference"},
+							},
+						},
+					},
+					"actions": []map[string]interface{}{
+						{
+							"type":  "Action.OpenUrl",
+							"title": "View in Panther",
+							"url":   "https://panther.io/alerts/alertId",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedPostInput := &PostInput{
+		url:  "https://webexapis.com/v1/messages",
+		body: webexPayload,
+		headers: map[string]string{
+			AuthorizationHTTPHeader: "Bearer bot-token",
+		},
+	}
+	ctx := context.Background()
+	httpWrapper.On("post", ctx, expectedPostInput).Return((*AlertDeliveryResponse)(nil))
+
+	assert.Nil(t, client.Webex(ctx, alert, webexConfig))
+	httpWrapper.AssertExpectations(t)
+}
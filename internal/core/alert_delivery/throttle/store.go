@@ -0,0 +1,250 @@
+package throttle
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+const (
+	// MaxDigestAlertIDs is the maximum number of alert ids kept in a digest, the rest of the alerts are only counted
+	MaxDigestAlertIDs = 500
+
+	// Items are kept for a day after their last use
+	itemTTL = 24 * time.Hour
+	// Number of attempts to update an item concurrently updated by other lambdas
+	maxUpdateAttempts = 5
+)
+
+// Store keeps the state of delivery policies in a DynamoDB table
+type Store struct {
+	Client    dynamodbiface.DynamoDBAPI
+	TableName string
+}
+
+// bucketItem is the table item of a rate limit
+type bucketItem struct {
+	ID string `json:"id"`
+	tokenBucket
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// digestItem is the table item of a digest window
+type digestItem struct {
+	ID string `json:"id"`
+	// WindowStart and WindowEnd are in seconds since epoch
+	WindowStart int64    `json:"windowStart"`
+	WindowEnd   int64    `json:"windowEnd"`
+	AlertCount  int      `json:"alertCount"`
+	AlertIDs    []string `json:"alertIds,omitempty"`
+	ExpiresAt   int64    `json:"expiresAt"`
+}
+
+func rateLimitKey(outputID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"id": {S: aws.String("ratelimit#" + outputID)}}
+}
+
+func digestKey(outputID, analysisID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{"id": {S: aws.String("digest#" + outputID + "#" + analysisID)}}
+}
+
+func numberValue(n int64) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(n, 10))}
+}
+
+// TakeToken takes a token from the rate limit of an output.
+// If no token is available, it returns the time until one is.
+func (s *Store) TakeToken(outputID string, limit *outputModels.RateLimit, now time.Time) (time.Duration, error) {
+	key := rateLimitKey(outputID)
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		output, err := s.Client.GetItem(&dynamodb.GetItemInput{
+			TableName:      aws.String(s.TableName),
+			Key:            key,
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get rate limit")
+		}
+		item := bucketItem{}
+		if err := dynamodbattribute.UnmarshalMap(output.Item, &item); err != nil {
+			return 0, errors.Wrap(err, "failed to unmarshal rate limit")
+		}
+		// Updates are conditioned on the bucket not being updated by another lambda in the meantime
+		input := &dynamodb.PutItemInput{
+			TableName:           aws.String(s.TableName),
+			ConditionExpression: aws.String("attribute_not_exists(id)"),
+		}
+		if output.Item != nil {
+			input.ConditionExpression = aws.String("updatedAt = :updatedAt")
+			input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+				":updatedAt": numberValue(item.UpdatedAt),
+			}
+		}
+		if wait := item.take(limit, now); wait > 0 {
+			return wait, nil
+		}
+		item.ID = *key["id"].S
+		item.ExpiresAt = now.Add(itemTTL).Unix()
+		input.Item, err = dynamodbattribute.MarshalMap(&item)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to marshal rate limit")
+		}
+		if _, err := s.Client.PutItem(input); err != nil {
+			if isConditionalCheckFailed(err) {
+				continue
+			}
+			return 0, errors.Wrap(err, "failed to update rate limit")
+		}
+		return 0, nil
+	}
+	return 0, errors.New("failed to update rate limit: too many concurrent updates")
+}
+
+// AddToDigest adds an alert to the open digest window of a detection for an output.
+//
+// If no window is open, it opens a new one and returns false, the alert should then be delivered.
+// It returns the end of the digest window.
+func (s *Store) AddToDigest(outputID, analysisID, alertID string, window time.Duration, now time.Time) (time.Time, bool, error) {
+	key := digestKey(outputID, analysisID)
+	// A window stays open after it ends until its grouped alerts are delivered
+	const openCondition = "attribute_exists(id) AND (windowEnd > :now OR alertCount > :zero)"
+	values := map[string]*dynamodb.AttributeValue{
+		":now":  numberValue(now.Unix()),
+		":zero": numberValue(0),
+		":one":  numberValue(1),
+	}
+	valuesWithID := map[string]*dynamodb.AttributeValue{
+		":alertId": {SS: aws.StringSlice([]string{alertID})},
+		":maxIds":  numberValue(MaxDigestAlertIDs),
+	}
+	for k, v := range values {
+		valuesWithID[k] = v
+	}
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		output, err := s.Client.UpdateItem(&dynamodb.UpdateItemInput{
+			TableName:        aws.String(s.TableName),
+			Key:              key,
+			UpdateExpression: aws.String("ADD alertCount :one, alertIds :alertId"),
+			ConditionExpression: aws.String(openCondition +
+				" AND (attribute_not_exists(alertIds) OR size(alertIds) < :maxIds)"),
+			ExpressionAttributeValues: valuesWithID,
+			ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+		})
+		if err != nil && isConditionalCheckFailed(err) {
+			// The window is closed or it has too many alert ids, try to only count the alert
+			output, err = s.Client.UpdateItem(&dynamodb.UpdateItemInput{
+				TableName:                 aws.String(s.TableName),
+				Key:                       key,
+				UpdateExpression:          aws.String("ADD alertCount :one"),
+				ConditionExpression:       aws.String(openCondition),
+				ExpressionAttributeValues: values,
+				ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+			})
+		}
+		if err == nil {
+			item := digestItem{}
+			if err := dynamodbattribute.UnmarshalMap(output.Attributes, &item); err != nil {
+				return time.Time{}, false, errors.Wrap(err, "failed to unmarshal digest")
+			}
+			return time.Unix(item.WindowEnd, 0).UTC(), true, nil
+		}
+		if !isConditionalCheckFailed(err) {
+			return time.Time{}, false, errors.Wrap(err, "failed to add alert to digest")
+		}
+
+		// Open a new window
+		item := digestItem{
+			ID:          *key["id"].S,
+			WindowStart: now.Unix(),
+			WindowEnd:   now.Add(window).Unix(),
+			ExpiresAt:   now.Add(window + itemTTL).Unix(),
+		}
+		itemValue, err := dynamodbattribute.MarshalMap(&item)
+		if err != nil {
+			return time.Time{}, false, errors.Wrap(err, "failed to marshal digest")
+		}
+		_, err = s.Client.PutItem(&dynamodb.PutItemInput{
+			TableName:           aws.String(s.TableName),
+			Item:                itemValue,
+			ConditionExpression: aws.String("attribute_not_exists(id) OR (windowEnd <= :now AND alertCount = :zero)"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":now":  values[":now"],
+				":zero": values[":zero"],
+			},
+		})
+		if err == nil {
+			return time.Unix(item.WindowEnd, 0).UTC(), false, nil
+		}
+		if !isConditionalCheckFailed(err) {
+			return time.Time{}, false, errors.Wrap(err, "failed to open digest")
+		}
+		// Another lambda opened a window in the meantime
+	}
+	return time.Time{}, false, errors.New("failed to add alert to digest: too many concurrent updates")
+}
+
+// CloseDigest closes the digest window of a detection for an output and returns its grouped alerts.
+//
+// It returns nil if no alerts were grouped or the window was already closed.
+func (s *Store) CloseDigest(outputID, analysisID string, windowEnd time.Time) (*deliverymodel.AlertDigest, error) {
+	output, err := s.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.TableName),
+		Key:                 digestKey(outputID, analysisID),
+		ConditionExpression: aws.String("windowEnd = :windowEnd"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":windowEnd": numberValue(windowEnd.Unix()),
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to close digest")
+	}
+	item := digestItem{}
+	if err := dynamodbattribute.UnmarshalMap(output.Attributes, &item); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal digest")
+	}
+	if item.AlertCount == 0 {
+		return nil, nil
+	}
+	return &deliverymodel.AlertDigest{
+		WindowStart: time.Unix(item.WindowStart, 0).UTC(),
+		WindowEnd:   time.Unix(item.WindowEnd, 0).UTC(),
+		AlertCount:  item.AlertCount,
+		AlertIDs:    item.AlertIDs,
+	}, nil
+}
+
+func isConditionalCheckFailed(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
@@ -0,0 +1,161 @@
+package throttle
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var (
+	testNow              = time.Date(2020, 11, 2, 12, 0, 0, 0, time.UTC)
+	errConditionalFailed = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+)
+
+func TestTakeTokenNewBucket(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+	client.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		return aws.StringValue(input.ConditionExpression) == "attribute_not_exists(id)" &&
+			aws.StringValue(input.Item["id"].S) == "ratelimit#output-id" &&
+			aws.StringValue(input.Item["tokens"].N) == "1"
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	wait, err := store.TakeToken("output-id", &outputModels.RateLimit{AlertsPerMinute: 1, Burst: 2}, testNow)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	client.AssertExpectations(t)
+}
+
+func TestTakeTokenEmptyBucket(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"id":        {S: aws.String("ratelimit#output-id")},
+			"tokens":    {N: aws.String("0.5")},
+			"updatedAt": {N: aws.String("1604318400000000000")},
+		},
+	}, nil).Once()
+
+	wait, err := store.TakeToken("output-id", &outputModels.RateLimit{AlertsPerMinute: 1, Burst: 2}, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, wait)
+	client.AssertExpectations(t)
+}
+
+func TestTakeTokenConcurrentUpdate(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	client.On("GetItem", mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Twice()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, errConditionalFailed).Once()
+	client.On("PutItem", mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	wait, err := store.TakeToken("output-id", &outputModels.RateLimit{AlertsPerMinute: 1, Burst: 2}, testNow)
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+	client.AssertExpectations(t)
+}
+
+func TestAddToDigestGrouped(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	windowEnd := testNow.Add(5 * time.Minute)
+	client.On("UpdateItem", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return aws.StringValue(input.Key["id"].S) == "digest#output-id#rule-id" &&
+			aws.StringValue(input.UpdateExpression) == "ADD alertCount :one, alertIds :alertId" &&
+			aws.StringValueSlice(input.ExpressionAttributeValues[":alertId"].SS)[0] == "alert-id"
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"windowEnd":  {N: aws.String("1604318700")},
+			"alertCount": {N: aws.String("3")},
+		},
+	}, nil).Once()
+
+	end, grouped, err := store.AddToDigest("output-id", "rule-id", "alert-id", 10*time.Minute, testNow)
+	require.NoError(t, err)
+	assert.True(t, grouped)
+	assert.True(t, windowEnd.Equal(end))
+	client.AssertExpectations(t)
+}
+
+func TestAddToDigestOpensWindow(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	client.On("UpdateItem", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errConditionalFailed).Twice()
+	client.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		return aws.StringValue(input.Item["windowEnd"].N) == "1604319000" &&
+			aws.StringValue(input.Item["alertCount"].N) == "0"
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	end, grouped, err := store.AddToDigest("output-id", "rule-id", "alert-id", 10*time.Minute, testNow)
+	require.NoError(t, err)
+	assert.False(t, grouped)
+	assert.True(t, testNow.Add(10*time.Minute).Equal(end))
+	client.AssertExpectations(t)
+}
+
+func TestCloseDigest(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	windowEnd := testNow.Add(10 * time.Minute)
+	client.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return aws.StringValue(input.ExpressionAttributeValues[":windowEnd"].N) == "1604319000"
+	})).Return(&dynamodb.DeleteItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"windowStart": {N: aws.String("1604318400")},
+			"windowEnd":   {N: aws.String("1604319000")},
+			"alertCount":  {N: aws.String("2")},
+			"alertIds":    {SS: aws.StringSlice([]string{"alert-1", "alert-2"})},
+		},
+	}, nil).Once()
+
+	digest, err := store.CloseDigest("output-id", "rule-id", windowEnd)
+	require.NoError(t, err)
+	assert.Equal(t, &deliverymodel.AlertDigest{
+		WindowStart: testNow,
+		WindowEnd:   windowEnd,
+		AlertCount:  2,
+		AlertIDs:    []string{"alert-1", "alert-2"},
+	}, digest)
+	client.AssertExpectations(t)
+}
+
+func TestCloseDigestClosed(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := Store{Client: client, TableName: "table"}
+	client.On("DeleteItem", mock.Anything).Return(&dynamodb.DeleteItemOutput{}, errConditionalFailed).Once()
+
+	digest, err := store.CloseDigest("output-id", "rule-id", testNow)
+	require.NoError(t, err)
+	assert.Nil(t, digest)
+	client.AssertExpectations(t)
+}
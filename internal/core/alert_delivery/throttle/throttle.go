@@ -0,0 +1,153 @@
+package throttle
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package throttle applies the delivery policies of outputs to the alerts dispatched to them.
+//
+// Alerts are held during quiet hours, grouped in digests per detection and rate limited with a token bucket.
+// The state of rate limits and digests is shared by all alert delivery lambdas in a DynamoDB table.
+
+import (
+	"math"
+	"time"
+
+	deliverymodel "github.com/panther-labs/panther/api/lambda/delivery/models"
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+// ClockLayout is the time layout of the start and end of quiet hours
+const ClockLayout = "15:04"
+
+// Decision is the result of applying a delivery policy to an alert
+type Decision struct {
+	// Delay is set if the alert should be delivered later
+	Delay time.Duration
+	// Grouped is set if the alert was grouped in an open digest
+	Grouped bool
+	// DigestWindowEnd is the end of the digest window the alert opened or was grouped in
+	DigestWindowEnd time.Time
+}
+
+// Apply applies the delivery policy of an output to an alert.
+//
+// If an error occurs after the alert opened a digest window, the decision is returned along with the error.
+func (s *Store) Apply(alert *deliverymodel.Alert, outputID string, policy *outputModels.DeliveryPolicy, now time.Time) (Decision, error) {
+	decision := Decision{}
+	if policy == nil {
+		return decision, nil
+	}
+	if end, ok := QuietUntil(policy.QuietHours, alert.Severity, now); ok {
+		decision.Delay = end.Sub(now)
+		return decision, nil
+	}
+	if policy.DigestWindowMinutes > 0 && alert.AlertID != nil {
+		window := time.Duration(policy.DigestWindowMinutes) * time.Minute
+		windowEnd, grouped, err := s.AddToDigest(outputID, alert.AnalysisID, *alert.AlertID, window, now)
+		if err != nil {
+			return decision, err
+		}
+		decision.DigestWindowEnd = windowEnd
+		if grouped {
+			decision.Grouped = true
+			return decision, nil
+		}
+	}
+	if policy.RateLimit != nil {
+		wait, err := s.TakeToken(outputID, policy.RateLimit, now)
+		if err != nil {
+			return decision, err
+		}
+		decision.Delay = wait
+	}
+	return decision, nil
+}
+
+// QuietUntil checks if an alert of some severity is held by quiet hours and returns the time they end.
+func QuietUntil(quietHours *outputModels.QuietHours, severity string, now time.Time) (time.Time, bool) {
+	if quietHours == nil {
+		return time.Time{}, false
+	}
+	for _, s := range quietHours.Severities {
+		if s == severity {
+			return time.Time{}, false
+		}
+	}
+	loc, err := time.LoadLocation(quietHours.TimeZone)
+	if err != nil {
+		// Time zones are validated when outputs are saved
+		return time.Time{}, false
+	}
+	startClock, err := time.Parse(ClockLayout, quietHours.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	endClock, err := time.Parse(ClockLayout, quietHours.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+	now = now.In(loc)
+	start := atClock(now, startClock)
+	end := atClock(now, endClock)
+	switch {
+	case start.Equal(end):
+		return time.Time{}, false
+	case start.Before(end):
+		// Quiet hours within a day (ie 12:00-14:00)
+		return end, !now.Before(start) && now.Before(end)
+	case now.Before(end):
+		// Quiet hours past midnight (ie 22:00-07:00) that started yesterday
+		return end, true
+	case !now.Before(start):
+		// Quiet hours past midnight that end tomorrow
+		return atClock(now.AddDate(0, 0, 1), endClock), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// atClock returns the time of day of clock at the date of t
+func atClock(t time.Time, clock time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, t.Location())
+}
+
+// tokenBucket is the state of a rate limit
+type tokenBucket struct {
+	Tokens float64 `json:"tokens"`
+	// UpdatedAt is the time of the last update in nanoseconds since epoch
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// take refills the bucket up to now and takes a token from it.
+// If the bucket is empty, it returns the time until a token is available.
+func (b *tokenBucket) take(limit *outputModels.RateLimit, now time.Time) time.Duration {
+	burst := float64(limit.Burst)
+	ratePerSecond := limit.AlertsPerMinute / 60
+	if b.UpdatedAt == 0 {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(time.Unix(0, b.UpdatedAt)); elapsed > 0 {
+		b.Tokens = math.Min(burst, b.Tokens+elapsed.Seconds()*ratePerSecond)
+	}
+	b.UpdatedAt = now.UnixNano()
+	if b.Tokens < 1 {
+		return time.Duration(math.Ceil((1 - b.Tokens) / ratePerSecond * float64(time.Second)))
+	}
+	b.Tokens--
+	return 0
+}
@@ -0,0 +1,94 @@
+package throttle
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	outputModels "github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestQuietUntil(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2020, 11, 2, hour, minute, 0, 0, time.UTC)
+	}
+	overnight := &outputModels.QuietHours{Start: "22:00", End: "07:30", Severities: []string{"CRITICAL"}}
+	daytime := &outputModels.QuietHours{Start: "12:00", End: "14:00"}
+
+	for _, tc := range []struct {
+		name       string
+		quietHours *outputModels.QuietHours
+		severity   string
+		now        time.Time
+		expectOK   bool
+		expectEnd  time.Time
+	}{
+		{name: "no quiet hours", now: day(23, 0)},
+		{name: "before overnight", quietHours: overnight, severity: "LOW", now: day(21, 59)},
+		{name: "overnight start", quietHours: overnight, severity: "LOW", now: day(22, 0), expectOK: true, expectEnd: day(31, 30)},
+		{name: "overnight morning", quietHours: overnight, severity: "LOW", now: day(3, 0), expectOK: true, expectEnd: day(7, 30)},
+		{name: "overnight end", quietHours: overnight, severity: "LOW", now: day(7, 30)},
+		{name: "overnight bypass", quietHours: overnight, severity: "CRITICAL", now: day(23, 0)},
+		{name: "daytime", quietHours: daytime, severity: "LOW", now: day(13, 0), expectOK: true, expectEnd: day(14, 0)},
+		{name: "after daytime", quietHours: daytime, severity: "LOW", now: day(23, 0)},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			end, ok := QuietUntil(tc.quietHours, tc.severity, tc.now)
+			require.Equal(t, tc.expectOK, ok)
+			if ok {
+				assert.True(t, tc.expectEnd.Equal(end), "expected %s, got %s", tc.expectEnd, end)
+			}
+		})
+	}
+}
+
+func TestQuietUntilTimeZone(t *testing.T) {
+	quietHours := &outputModels.QuietHours{Start: "22:00", End: "07:00", TimeZone: "America/New_York"}
+	// 03:00 UTC is 22:00 in New York
+	now := time.Date(2020, 11, 3, 3, 0, 0, 0, time.UTC)
+	end, ok := QuietUntil(quietHours, "HIGH", now)
+	require.True(t, ok)
+	assert.True(t, time.Date(2020, 11, 3, 12, 0, 0, 0, time.UTC).Equal(end), end.UTC())
+
+	_, ok = QuietUntil(quietHours, "HIGH", now.Add(-time.Minute))
+	assert.False(t, ok)
+}
+
+func TestTokenBucket(t *testing.T) {
+	limit := &outputModels.RateLimit{AlertsPerMinute: 6, Burst: 2}
+	now := time.Date(2020, 11, 2, 12, 0, 0, 0, time.UTC)
+	bucket := tokenBucket{}
+
+	// A new bucket is full
+	assert.Zero(t, bucket.take(limit, now))
+	assert.Zero(t, bucket.take(limit, now))
+	// Empty bucket, a token is added every 10 seconds
+	assert.Equal(t, 10*time.Second, bucket.take(limit, now))
+	assert.Equal(t, 5*time.Second, bucket.take(limit, now.Add(5*time.Second)))
+	assert.Zero(t, bucket.take(limit, now.Add(10*time.Second)))
+	// Tokens are refilled up to the burst
+	assert.Zero(t, bucket.take(limit, now.Add(time.Hour)))
+	assert.Zero(t, bucket.take(limit, now.Add(time.Hour)))
+	assert.Equal(t, 10*time.Second, bucket.take(limit, now.Add(time.Hour)))
+}
@@ -0,0 +1,125 @@
+package tickets
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package tickets keeps track of the issues created by alert outputs in external ticketing systems,
+// so that their status can be synced with the status of their alerts.
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Tickets expire 90 days after they were last synced, ie if their issue was deleted or the output cannot read it
+	ticketTTL = 90 * 24 * time.Hour
+	// The expiration of synced tickets is refreshed at most once a day
+	ticketRefreshInterval = 24 * time.Hour
+
+	keyPrefix = "ticket#"
+)
+
+// Store keeps the tickets synced with alerts in a DynamoDB table
+type Store struct {
+	Client    dynamodbiface.DynamoDBAPI
+	TableName string
+}
+
+// Ticket is the table item of an issue synced with its alerts
+type Ticket struct {
+	ID         string `json:"id"`
+	OutputID   string `json:"outputId"`
+	OutputType string `json:"outputType"`
+	Key        string `json:"key"`
+	URL        string `json:"url,omitempty"`
+	// AlertIDs are the alerts of the ticket, there are many for digests
+	AlertIDs []string `json:"alertIds"`
+	// AlertStatuses are the last statuses of the alerts synced with the ticket
+	AlertStatuses map[string]string `json:"alertStatuses,omitempty"`
+	// ExpiresAt is in seconds since epoch
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+func ticketID(outputID, key string) string {
+	return keyPrefix + outputID + "#" + key
+}
+
+// NeedsRefresh returns true if the expiration of a synced ticket should be refreshed
+func (t *Ticket) NeedsRefresh(now time.Time) bool {
+	return t.ExpiresAt < now.Add(ticketTTL-ticketRefreshInterval).Unix()
+}
+
+// Put starts syncing a ticket, or saves its synced alert statuses and refreshes its expiration
+func (s *Store) Put(ticket *Ticket, now time.Time) error {
+	ticket.ID = ticketID(ticket.OutputID, ticket.Key)
+	ticket.ExpiresAt = now.Add(ticketTTL).Unix()
+	item, err := dynamodbattribute.MarshalMap(ticket)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal ticket")
+	}
+	_, err = s.Client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(s.TableName),
+		Item:      item,
+	})
+	return errors.Wrapf(err, "failed to put ticket %s", ticket.ID)
+}
+
+// List returns all the synced tickets
+func (s *Store) List() ([]*Ticket, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(s.TableName),
+		FilterExpression: aws.String("begins_with(id, :prefix)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":prefix": {S: aws.String(keyPrefix)},
+		},
+	}
+	var tickets []*Ticket
+	for {
+		output, err := s.Client.Scan(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan tickets")
+		}
+		var page []*Ticket
+		if err := dynamodbattribute.UnmarshalListOfMaps(output.Items, &page); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal tickets")
+		}
+		tickets = append(tickets, page...)
+		if len(output.LastEvaluatedKey) == 0 {
+			return tickets, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// Delete stops syncing a ticket
+func (s *Store) Delete(ticket *Ticket) error {
+	id := ticketID(ticket.OutputID, ticket.Key)
+	_, err := s.Client.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(s.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	return errors.Wrapf(err, "failed to delete ticket %s", id)
+}
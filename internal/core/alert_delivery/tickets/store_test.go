@@ -0,0 +1,105 @@
+package tickets
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+var testNow = time.Date(2020, 11, 2, 12, 0, 0, 0, time.UTC)
+
+func TestPut(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := &Store{Client: client, TableName: "state"}
+
+	client.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		ticket := Ticket{}
+		require.NoError(t, dynamodbattribute.UnmarshalMap(input.Item, &ticket))
+		return aws.StringValue(input.TableName) == "state" &&
+			ticket.ID == "ticket#output-id#PROJ-1" &&
+			ticket.ExpiresAt == testNow.Add(ticketTTL).Unix() &&
+			assert.Equal(t, []string{"alert-id"}, ticket.AlertIDs)
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	client.On("PutItem", mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+		ticket := Ticket{}
+		require.NoError(t, dynamodbattribute.UnmarshalMap(input.Item, &ticket))
+		return ticket.ExpiresAt == testNow.Add(time.Hour+ticketTTL).Unix()
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	ticket := &Ticket{OutputID: "output-id", OutputType: "jira", Key: "PROJ-1", AlertIDs: []string{"alert-id"}}
+	require.NoError(t, store.Put(ticket, testNow))
+	// The expiration of synced tickets is refreshed
+	require.NoError(t, store.Put(ticket, testNow.Add(time.Hour)))
+	client.AssertExpectations(t)
+}
+
+func TestNeedsRefresh(t *testing.T) {
+	ticket := &Ticket{ExpiresAt: testNow.Add(ticketTTL).Unix()}
+	assert.False(t, ticket.NeedsRefresh(testNow))
+	assert.False(t, ticket.NeedsRefresh(testNow.Add(ticketRefreshInterval)))
+	assert.True(t, ticket.NeedsRefresh(testNow.Add(ticketRefreshInterval+time.Second)))
+	assert.True(t, (&Ticket{}).NeedsRefresh(testNow))
+}
+
+func TestList(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := &Store{Client: client, TableName: "state"}
+
+	first, err := dynamodbattribute.MarshalMap(&Ticket{ID: "ticket#output-id#PROJ-1", Key: "PROJ-1"})
+	require.NoError(t, err)
+	second, err := dynamodbattribute.MarshalMap(&Ticket{ID: "ticket#output-id#PROJ-2", Key: "PROJ-2"})
+	require.NoError(t, err)
+	lastKey := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("ticket#output-id#PROJ-1")}}
+
+	client.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil && aws.StringValue(input.FilterExpression) == "begins_with(id, :prefix)"
+	})).Return(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{first}, LastEvaluatedKey: lastKey}, nil).Once()
+	client.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{second}}, nil).Once()
+
+	tickets, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, tickets, 2)
+	assert.Equal(t, "PROJ-1", tickets[0].Key)
+	assert.Equal(t, "PROJ-2", tickets[1].Key)
+	client.AssertExpectations(t)
+}
+
+func TestDelete(t *testing.T) {
+	client := &testutils.DynamoDBMock{}
+	store := &Store{Client: client, TableName: "state"}
+
+	client.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return aws.StringValue(input.Key["id"].S) == "ticket#output-id#12"
+	})).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+	require.NoError(t, store.Delete(&Ticket{OutputID: "output-id", Key: "12"}))
+	client.AssertExpectations(t)
+}
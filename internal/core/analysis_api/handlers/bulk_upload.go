@@ -39,6 +39,7 @@ import (
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	compliancemodels "github.com/panther-labs/panther/api/lambda/compliance/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/stringset"
 )
 
 type writeResult struct {
@@ -57,6 +58,19 @@ func (API) BulkUpload(input *models.BulkUploadInput) *events.APIGatewayProxyResp
 		}
 	}
 
+	// The tables of log types that are no longer mapped by the data models are also updated
+	var dataModelIDs []string
+	for _, policy := range policies {
+		if policy.Type == models.TypeDataModel {
+			dataModelIDs = append(dataModelIDs, policy.ID)
+		}
+	}
+	storedLogTypes := make(map[string][]string, len(dataModelIDs))
+	for _, item := range storedDataModels(dataModelIDs...) {
+		storedLogTypes[item.ID] = item.ResourceTypes
+	}
+	var dataModelTables []string
+
 	// Create/modify each policy in parallel
 	results := make(chan writeResult)
 	for _, policy := range policies {
@@ -129,6 +143,9 @@ func (API) BulkUpload(input *models.BulkUploadInput) *events.APIGatewayProxyResp
 			} else if result.changeType == updatedItem {
 				counts.ModifiedDataModels++
 			}
+			if result.changeType != noChange {
+				dataModelTables = stringset.Concat(dataModelTables, storedLogTypes[result.item.ID], dataModelLogTypes(result.item))
+			}
 
 		default:
 			response = &events.APIGatewayProxyResponse{
@@ -146,6 +163,11 @@ func (API) BulkUpload(input *models.BulkUploadInput) *events.APIGatewayProxyResp
 		}
 	}
 
+	// If at least one data model was created or modified, update the data model fields of its log tables
+	if len(dataModelTables) > 0 {
+		updateDataModelTables(dataModelTables)
+	}
+
 	if response != nil {
 		return response
 	}
@@ -62,6 +62,7 @@ var (
 
 type envConfig struct {
 	Bucket               string `required:"true" split_words:"true"`
+	DataCatalogQueueURL  string `required:"true" split_words:"true"`
 	LayerManagerQueueURL string `required:"true" split_words:"true"`
 	RulesEngine          string `required:"true" split_words:"true"`
 	PackTable            string `required:"true" split_words:"true"`
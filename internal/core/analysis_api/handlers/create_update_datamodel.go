@@ -28,6 +28,7 @@ import (
 
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/stringset"
 )
 
 var (
@@ -80,6 +81,9 @@ func writeDataModel(input *models.UpdateDataModelInput, create bool) *events.API
 		Type:          models.TypeDataModel,
 	}
 
+	// The tables of log types that are no longer mapped by the data model are also updated
+	logTypes := dataModelLogTypes(storedDataModels(item.ID)...)
+
 	var statusCode int
 	if create {
 		if _, err := writeItem(item, input.UserID, aws.Bool(false)); err != nil {
@@ -103,6 +107,7 @@ func writeDataModel(input *models.UpdateDataModelInput, create bool) *events.API
 		}
 		statusCode = http.StatusOK
 	}
+	updateDataModelTables(stringset.Concat(logTypes, dataModelLogTypes(item)))
 
 	return gatewayapi.MarshalResponse(item.DataModel(), statusCode)
 }
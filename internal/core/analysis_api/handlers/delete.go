@@ -71,7 +71,16 @@ func (API) DeleteDetections(input *models.DeletePoliciesInput) *events.APIGatewa
 }
 
 func (api API) DeleteDataModels(input *models.DeleteDataModelsInput) *events.APIGatewayProxyResponse {
-	return api.DeleteRules(input)
+	ids := make([]string, len(input.Entries))
+	for i, entry := range input.Entries {
+		ids[i] = entry.ID
+	}
+	logTypes := dataModelLogTypes(storedDataModels(ids...)...)
+	response := api.DeleteRules(input)
+	if response.StatusCode == http.StatusOK {
+		updateDataModelTables(logTypes)
+	}
+	return response
 }
 
 func (API) DeleteGlobals(input *models.DeleteGlobalsInput) *events.APIGatewayProxyResponse {
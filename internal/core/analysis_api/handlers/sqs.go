@@ -19,12 +19,16 @@ package handlers
  */
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	jsoniter "github.com/json-iterator/go"
 	"go.uber.org/zap"
 
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/datacatalog_updater/datacatalog"
+	"github.com/panther-labs/panther/pkg/stringset"
 )
 
 // Queue a policy for re-analysis (evaluate against all applicable resources).
@@ -57,3 +61,48 @@ func updateLayer() error {
 	})
 	return err
 }
+
+// updateDataModelTables sends messages to the datacatalog updater to update the tables of the log types
+// mapped by changed data models, so that their p_udm column has the fields of the enabled data model.
+// Callers should include the log types mapped before the change, so that removed fields are dropped.
+//
+// The data model is already stored at this point, so failures are only logged.
+// The tables are updated again on the next data model change or deployment.
+func updateDataModelTables(logTypes []string) {
+	client := datacatalog.Client{
+		QueueURL: env.DataCatalogQueueURL,
+		SQSAPI:   sqsClient,
+	}
+	for _, logType := range logTypes {
+		if err := client.SendUpdateTableForLogType(context.Background(), logType); err != nil {
+			zap.L().Error("failed to update table with data model fields", zap.String("logType", logType), zap.Error(err))
+		}
+	}
+}
+
+// dataModelLogTypes returns the distinct log types mapped by the data models among the items
+func dataModelLogTypes(items ...*tableItem) (logTypes []string) {
+	for _, item := range items {
+		if item != nil && item.Type == models.TypeDataModel {
+			logTypes = stringset.Append(logTypes, item.ResourceTypes...)
+		}
+	}
+	return logTypes
+}
+
+// storedDataModels loads the stored data models with the provided ids, before they are modified or deleted.
+// Items that fail to load are skipped, their tables are updated on the next data model change or deployment.
+func storedDataModels(ids ...string) []*tableItem {
+	items := make([]*tableItem, 0, len(ids))
+	for _, id := range ids {
+		item, err := dynamoGet(id, true)
+		if err != nil {
+			zap.L().Error("failed to load data model", zap.String("id", id), zap.Error(err))
+			continue
+		}
+		if item != nil && item.Type == models.TypeDataModel {
+			items = append(items, item)
+		}
+	}
+	return items
+}
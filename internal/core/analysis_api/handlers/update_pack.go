@@ -30,6 +30,7 @@ import (
 
 	"github.com/panther-labs/panther/api/lambda/analysis/models"
 	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/stringset"
 )
 
 func (API) PatchPack(input *models.PatchPackInput) *events.APIGatewayProxyResponse {
@@ -87,7 +88,9 @@ func updatePackEnabledStatus(input *models.PatchPackInput, oldPackItem *packTabl
 		}
 	}
 	// actually run the update
+	var dataModelTables []string
 	for _, detection := range detections {
+		dataModelTables = stringset.Concat(dataModelTables, dataModelLogTypes(detection))
 		_, err = writeItem(detection, input.UserID, nil)
 		if err != nil {
 			// TODO: should we try to rollback the other updated detections?
@@ -97,6 +100,9 @@ func updatePackEnabledStatus(input *models.PatchPackInput, oldPackItem *packTabl
 			}
 		}
 	}
+	if len(dataModelTables) > 0 {
+		updateDataModelTables(dataModelTables)
+	}
 	// Finally, update the pack enabled status
 	oldPackItem.Enabled = input.Enabled
 	err = updatePack(oldPackItem, input.UserID, aws.Bool(true))
@@ -215,6 +221,12 @@ func updateDetectionsToVersion(userID string, pack *packTableItem, newDetectionI
 	if err != nil {
 		return err
 	}
+	// The tables of log types that are no longer mapped by the data models are also updated,
+	// collect them before the old items are modified
+	var dataModelTables []string
+	for _, oldDetection := range oldDetectionItems {
+		dataModelTables = stringset.Concat(dataModelTables, dataModelLogTypes(oldDetection))
+	}
 	newDetections := setupUpdateDetectionsToVersion(pack, oldDetectionItems, newDetectionItems)
 	for _, newDetection := range newDetections {
 		_, err = writeItem(newDetection, userID, nil)
@@ -223,6 +235,10 @@ func updateDetectionsToVersion(userID string, pack *packTableItem, newDetectionI
 			return err
 		}
 	}
+	dataModelTables = stringset.Concat(dataModelTables, dataModelLogTypes(newDetections...))
+	if len(dataModelTables) > 0 {
+		updateDataModelTables(dataModelTables)
+	}
 	return nil
 }
 
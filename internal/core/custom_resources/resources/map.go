@@ -185,6 +185,16 @@ var CustomResources = map[string]cfn.CustomResourceFunction{
 	// PhysicalId: custom:panther-user:$USER_ID
 	"Custom::PantherUser": customPantherUser,
 
+	// Generates the key used to tokenize personal data in processed logs, if there is none (singleton).
+	// Hash masks of custom log transforms use the key even if there are no redaction rules.
+	// The key is kept when the resource is deleted, a new key would change the tokens of all values.
+	//
+	// Parameters:
+	//     ProcessedDataBucket: string (required)
+	// Outputs: None
+	// PhysicalId: custom:redaction:key
+	"Custom::RedactionKey": customRedactionKey,
+
 	// Update notifications for an S3 bucket.
 	//
 	// Parameters = s3.PutBucketNotificationConfigurationInput
@@ -0,0 +1,66 @@
+package resources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/redaction"
+	"github.com/panther-labs/panther/pkg/encryption"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+type RedactionKeyProperties struct {
+	ProcessedDataBucket string `validate:"required"`
+}
+
+func customRedactionKey(ctx context.Context, event cfn.Event) (string, map[string]interface{}, error) {
+	const physicalResourceID = "custom:redaction:key"
+	switch event.RequestType {
+	case cfn.RequestCreate, cfn.RequestUpdate:
+		var props RedactionKeyProperties
+		if err := parseProperties(event.ResourceProperties, &props); err != nil {
+			return physicalResourceID, nil, err
+		}
+		bucket := props.ProcessedDataBucket
+		config, err := redaction.ReadConfigS3(ctx, s3Client, bucket, redaction.ConfigS3Key)
+		if err != nil {
+			return physicalResourceID, nil, errors.WithMessage(err, "failed to read redaction configuration")
+		}
+		if config == nil {
+			config = &redaction.Config{}
+		}
+		generated, err := config.EnsureKey(encryption.New(redaction.KeyAlias, awsSession).EncryptConfig)
+		if err != nil || !generated {
+			return physicalResourceID, nil, err
+		}
+		if err := redaction.WriteConfigS3(ctx, s3Client, bucket, redaction.ConfigS3Key, config); err != nil {
+			return physicalResourceID, nil, errors.WithMessage(err, "failed to upload redaction configuration")
+		}
+		lambdalogger.FromContext(ctx).Info("generated a new redaction key", zap.String("bucket", bucket))
+		return physicalResourceID, nil, nil
+	default:
+		// skip deletes - the key must outlive deployments so that tokens of stored values do not change
+		return event.PhysicalResourceID, nil, nil
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/pkg/errors"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
@@ -43,6 +44,11 @@ type LogTypesAPI struct {
 	UpdateDataCatalog func(ctx context.Context, logType string, from, to []logschema.FieldSchema) error
 	LogTypesInUse     func(ctx context.Context) ([]string, error)
 	ManagedSchemas    managedschemas.ReleaseFeeder
+	LookupTables      LookupTableDatabase
+	GlobalIndicators  IndicatorsDatabase
+	Retention         RetentionDatabase
+	S3ForBucket       func(ctx context.Context, bucket string) (s3iface.S3API, error)
+	SourceS3          func(ctx context.Context, sourceID string) (*S3Source, error)
 }
 
 // SchemaDatabase handles the external actions required for LogTypesAPI to be implemented
@@ -53,6 +59,10 @@ type SchemaDatabase interface {
 	PutSchema(ctx context.Context, id string, record *SchemaRecord) (*SchemaRecord, error)
 	// ScanSchemas iterates through all schema records as long as scan returns true
 	ScanSchemas(ctx context.Context, scan ScanSchemaFunc) error
+	// GetSchemaRevision gets a single revision from the history of a schema record
+	GetSchemaRevision(ctx context.Context, id string, revision int64) (*SchemaRecord, error)
+	// ScanSchemaRevisions iterates through the revision history of a schema record as long as scan returns true
+	ScanSchemaRevisions(ctx context.Context, id string, scan ScanSchemaFunc) error
 }
 
 type ScanSchemaFunc func(r *SchemaRecord) bool
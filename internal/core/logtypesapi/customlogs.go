@@ -63,7 +63,7 @@ func (api *LogTypesAPI) PutCustomLog(ctx context.Context, input *PutCustomLogInp
 	if err != nil {
 		return nil, err
 	}
-	if err := checkSchema(id, schema); err != nil {
+	if err := api.checkCustomSchema(ctx, id, schema); err != nil {
 		return nil, err
 	}
 
@@ -112,6 +112,9 @@ func (api *LogTypesAPI) PutCustomLog(ctx context.Context, input *PutCustomLogInp
 		if err := api.checkUpdate(currentSchema, schema); err != nil {
 			return nil, NewAPIError(ErrInvalidUpdate, fmt.Sprintf("schema update is not backwards compatible: %s", err))
 		}
+		if err := api.checkRevisionHistory(ctx, current, schema); err != nil {
+			return nil, err
+		}
 
 		result, err := api.Database.PutSchema(ctx, id, &SchemaRecord{
 			Name:         id,
@@ -0,0 +1,130 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
+)
+
+const (
+	defaultDryRunMaxObjects = 100
+	defaultDryRunMaxEvents  = 10000
+)
+
+// DryRunCustomLog parses log data of a source with a draft schema and reports how many entries would fail to parse.
+// If the custom log type already exists, the report includes the changes to the Glue table columns.
+// Nothing is written to the data lake during the dry run.
+func (api *LogTypesAPI) DryRunCustomLog(ctx context.Context, input *DryRunCustomLogInput) (*DryRunCustomLogOutput, error) {
+	name := customlogs.LogType(input.LogType)
+	schema, err := buildSchema(input.Spec)
+	if err != nil {
+		return nil, err
+	}
+	schema.Schema = name
+	if schema, err = api.withGlobalIndicators(ctx, schema); err != nil {
+		return nil, err
+	}
+	if err := checkSchema(name, schema); err != nil {
+		return nil, err
+	}
+	// Events are not stored, hash masks can use a random key if the redaction key is not available
+	if err := transforms.SetRandomHashKey(); err != nil {
+		return nil, err
+	}
+	dryRun, err := customlogs.NewDryRun(name, schema)
+	if err != nil {
+		return nil, NewAPIError(ErrInvalidLogSchema, err.Error())
+	}
+	dryRun.MaxEvents = input.MaxEvents
+	if dryRun.MaxEvents == 0 {
+		dryRun.MaxEvents = defaultDryRunMaxEvents
+	}
+
+	var current *logschema.Schema
+	record, err := api.Database.GetSchema(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.IsCustom() && !record.Disabled {
+		if current, err = buildRecordSchema(record); err != nil {
+			return nil, err
+		}
+	}
+
+	if api.SourceS3 == nil {
+		return nil, errors.New("reading source data is not supported")
+	}
+	src, err := api.SourceS3(ctx, input.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	maxObjects := input.MaxObjects
+	if maxObjects == 0 {
+		maxObjects = defaultDryRunMaxObjects
+	}
+	keys, err := src.listObjects(ctx, input.StartTime, input.EndTime, maxObjects)
+	if err != nil {
+		return nil, err
+	}
+	numObjects := 0
+	for _, key := range keys {
+		if dryRun.Full() {
+			break
+		}
+		if err := src.readObject(ctx, key, input.JSONStream, dryRun.ParseStream); err != nil {
+			return nil, err
+		}
+		numObjects++
+	}
+	report, err := dryRun.Report(current)
+	if err != nil {
+		return nil, err
+	}
+	return &DryRunCustomLogOutput{
+		NumObjects: numObjects,
+		Report:     report,
+	}, nil
+}
+
+// nolint:lll
+type DryRunCustomLogInput struct {
+	LogType    string                      `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	Spec       string                      `json:"logSpec" validate:"required" description:"The draft schema spec in YAML or JSON format"`
+	SourceID   string                      `json:"sourceId" validate:"required" description:"The id of the source to read log data from"`
+	StartTime  time.Time                   `json:"startTime,omitempty" description:"Only read objects modified after this time"`
+	EndTime    time.Time                   `json:"endTime,omitempty" description:"Only read objects modified before this time"`
+	MaxObjects int                         `json:"maxObjects,omitempty" validate:"omitempty,min=1,max=1000" description:"The maximum number of S3 objects to read (default 100)"`
+	MaxEvents  int                         `json:"maxEvents,omitempty" validate:"omitempty,min=1,max=100000" description:"The maximum number of log entries to parse (default 10000)"`
+	JSONStream *logstream.JSONStreamConfig `json:"jsonStream,omitempty" description:"Read log entries from a stream of JSON values instead of lines"`
+}
+
+// nolint:lll
+type DryRunCustomLogOutput struct {
+	NumObjects int                      `json:"numObjects,omitempty" description:"The number of S3 objects read"`
+	Report     *customlogs.DryRunReport `json:"report,omitempty" description:"The results of the dry run (field omitted if an error occurred)"`
+	Error      *APIError                `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
@@ -0,0 +1,137 @@
+package logtypesapi_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestAPI_DryRunCustomLog(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	s3Mock := &testutils.S3Mock{}
+	api := logtypesapi.LogTypesAPI{
+		Database: logtypesapi.NewInMemory(),
+		UpdateDataCatalog: func(_ context.Context, _ string, _, _ []logschema.FieldSchema) error {
+			return nil
+		},
+		SourceS3: func(_ context.Context, sourceID string) (*logtypesapi.S3Source, error) {
+			assert.Equal("source-id", sourceID)
+			return &logtypesapi.S3Source{
+				S3:       s3Mock,
+				Bucket:   "bucket",
+				Prefixes: []string{"logs/"},
+			}, nil
+		},
+	}
+	_, err := api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType: "Custom.Event",
+		Spec:    `{"version": 0, "fields": [{"name": "foo", "type": "string"}]}`,
+	})
+	assert.NoError(err)
+
+	now := time.Now()
+	s3Mock.On("ListObjectsV2PagesWithContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String("logs/old.json"), Size: aws.Int64(10), LastModified: aws.Time(now.Add(-48 * time.Hour))},
+			{Key: aws.String("logs/new.json"), Size: aws.Int64(10), LastModified: aws.Time(now)},
+		},
+	}, nil).Once()
+	s3Mock.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("logs/new.json"),
+	}, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewBufferString("{\"foo\": \"a\", \"bar\": 1}\n{\"foo\": \"b\", \"bar\": \"x\"}\n")),
+	}, nil).Once()
+
+	reply, err := api.DryRunCustomLog(ctx, &logtypesapi.DryRunCustomLogInput{
+		LogType:   "Custom.Event",
+		Spec:      `{"version": 0, "fields": [{"name": "foo", "type": "string"}, {"name": "bar", "type": "bigint"}]}`,
+		SourceID:  "source-id",
+		StartTime: now.Add(-time.Hour),
+	})
+	assert.NoError(err)
+	s3Mock.AssertExpectations(t)
+	assert.Equal(1, reply.NumObjects)
+	assert.Equal(2, reply.Report.NumLines)
+	assert.Equal(1, reply.Report.NumMatched)
+	assert.Len(reply.Report.Errors, 1)
+	assert.Equal("bar", reply.Report.Errors[0].Field)
+	assert.Equal([]customlogs.ColumnChange{
+		{Column: "bar", Change: customlogs.ColumnAdd, To: "bigint"},
+	}, reply.Report.Columns)
+
+	_, err = api.DryRunCustomLog(ctx, &logtypesapi.DryRunCustomLogInput{
+		LogType:  "Custom.Event",
+		Spec:     `{"version": 0, "fields": []}`,
+		SourceID: "source-id",
+	})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrInvalidLogSchema, logtypesapi.AsAPIError(err).Code)
+}
+
+func TestAPI_DryRunCustomLogHashMask(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	s3Mock := &testutils.S3Mock{}
+	api := logtypesapi.LogTypesAPI{
+		Database: logtypesapi.NewInMemory(),
+		SourceS3: func(_ context.Context, _ string) (*logtypesapi.S3Source, error) {
+			return &logtypesapi.S3Source{
+				S3:       s3Mock,
+				Bucket:   "bucket",
+				Prefixes: []string{"logs/"},
+			}, nil
+		},
+	}
+	s3Mock.On("ListObjectsV2PagesWithContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String("logs/new.json"), Size: aws.Int64(10), LastModified: aws.Time(time.Now())},
+		},
+	}, nil).Once()
+	s3Mock.On("GetObjectWithContext", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewBufferString("{\"user\": \"alice\"}\n")),
+	}, nil).Once()
+
+	// Hash masks do not require the redaction key of the log processor
+	reply, err := api.DryRunCustomLog(ctx, &logtypesapi.DryRunCustomLogInput{
+		LogType: "Custom.Event",
+		Spec: `{"version": 0, "fields": [{"name": "user", "type": "string"}],
+"transform": [{"mask": {"field": "user", "method": "hash"}}]}`,
+		SourceID: "source-id",
+	})
+	assert.NoError(err)
+	s3Mock.AssertExpectations(t)
+	assert.Equal(1, reply.Report.NumLines)
+	assert.Equal(1, reply.Report.NumMatched)
+}
@@ -21,6 +21,7 @@ package logtypesapi
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,12 +43,22 @@ const (
 	// We will use this kind of record to store custom log types
 	// For backwards compatibility the value is 'custom'
 	recordKindSchema = "custom"
+	// We will use this kind of record to store lookup tables
+	recordKindLookupTable = "lookup"
+	// We will use this kind of record to store the global indicators
+	recordKindIndicators = "indicators"
+	// We will use this kind of record to store the retention policies of log types
+	recordKindRetention = "retention"
 
 	attrRecordKind = "RecordKind"
+	attrRecordID   = "RecordID"
 	attrRevision   = "revision"
 )
 
 var _ SchemaDatabase = (*DynamoDBSchemas)(nil)
+var _ LookupTableDatabase = (*DynamoDBSchemas)(nil)
+var _ IndicatorsDatabase = (*DynamoDBSchemas)(nil)
+var _ RetentionDatabase = (*DynamoDBSchemas)(nil)
 
 // DynamoDBSchemas provides logtypes api actions for DDB
 type DynamoDBSchemas struct {
@@ -141,9 +152,88 @@ func (d *DynamoDBSchemas) PutSchema(ctx context.Context, id string, record *Sche
 	if err := dynamodbattribute.UnmarshalMap(reply.Attributes, &result); err != nil {
 		return nil, err
 	}
+	// Keep a snapshot of the new revision in the record history.
+	// The update has already been applied so failing here should not fail the whole operation.
+	if err := d.putSchemaRevision(ctx, id, &result); err != nil {
+		L(ctx).Error("failed to store schema revision",
+			zap.String("logType", id),
+			zap.Int64("revision", result.Revision),
+			zap.Error(err))
+	}
 	return &result, nil
 }
 
+func (d *DynamoDBSchemas) putSchemaRevision(ctx context.Context, id string, record *SchemaRecord) error {
+	item := ddbSchemaRecord{
+		recordKey:    schemaRevisionKey(id, record.Revision),
+		SchemaRecord: *record,
+	}
+	expr, err := expression.NewBuilder().WithCondition(
+		expression.Name(attrRecordKind).AttributeNotExists(),
+	).Build()
+	if err != nil {
+		return errors.WithMessage(err, "failed to build put schema revision expression")
+	}
+	_, err = d.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(d.TableName),
+		Item:                      mustMarshalMap(&item),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return err
+}
+
+func (d *DynamoDBSchemas) GetSchemaRevision(ctx context.Context, id string, revision int64) (*SchemaRecord, error) {
+	output, err := d.DB.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key:       mustMarshalMap(schemaRevisionKey(id, revision)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	record := ddbSchemaRecord{}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &record); err != nil {
+		return nil, err
+	}
+	if record.Name == "" {
+		return nil, nil
+	}
+	return &record.SchemaRecord, nil
+}
+
+func (d *DynamoDBSchemas) ScanSchemaRevisions(ctx context.Context, id string, scan ScanSchemaFunc) error {
+	query, err := expression.NewBuilder().WithKeyCondition(expression.KeyAnd(
+		expression.Key(attrRecordKind).Equal(expression.Value(recordKindSchema)),
+		expression.Key(attrRecordID).BeginsWith(schemaRevisionPrefix(id)),
+	)).Build()
+	if err != nil {
+		return err
+	}
+	var itemErr error
+	queryErr := d.DB.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		KeyConditionExpression:    query.KeyCondition(),
+		ExpressionAttributeNames:  query.Names(),
+		ExpressionAttributeValues: query.Values(),
+		TableName:                 aws.String(d.TableName),
+	}, func(page *dynamodb.QueryOutput, isLast bool) bool {
+		for _, item := range page.Items {
+			record := ddbSchemaRecord{}
+			if itemErr = dynamodbattribute.UnmarshalMap(item, &record); itemErr != nil {
+				return false
+			}
+			if !scan(&record.SchemaRecord) {
+				return false
+			}
+		}
+		return true
+	})
+	if queryErr != nil {
+		return queryErr
+	}
+	return itemErr
+}
+
 func buildPutSchemaExpression(record *SchemaRecord) (expression.Expression, error) {
 	return transact.BuildExpression(&transact.Update{
 		Set: map[string]interface{}{
@@ -189,7 +279,7 @@ func buildPutSchemaExpression(record *SchemaRecord) (expression.Expression, erro
 
 type recordKey struct {
 	RecordID   string `json:"RecordID" validate:"required"`
-	RecordKind string `json:"RecordKind" validate:"required,oneof=native status custom"`
+	RecordKind string `json:"RecordKind" validate:"required,oneof=native status custom lookup indicators retention"`
 }
 
 func mustMarshalMap(val interface{}) map[string]*dynamodb.AttributeValue {
@@ -210,7 +300,294 @@ func schemaRecordID(id string) string {
 	return strings.ToUpper(id)
 }
 
+// Revision history records are stored next to the schema record with a RecordID of `<ID>@<REVISION>`
+func schemaRevisionKey(id string, revision int64) recordKey {
+	return recordKey{
+		RecordID:   schemaRevisionPrefix(id) + strconv.FormatInt(revision, 10),
+		RecordKind: recordKindSchema,
+	}
+}
+
+func schemaRevisionPrefix(id string) string {
+	return schemaRecordID(id) + "@"
+}
+
 type ddbSchemaRecord struct {
 	recordKey
 	SchemaRecord
 }
+
+func (d *DynamoDBSchemas) ScanLookupTables(ctx context.Context, scan ScanLookupTableFunc) error {
+	filter, err := expression.NewBuilder().WithFilter(
+		expression.Name(attrRecordKind).Equal(expression.Value(recordKindLookupTable)),
+	).Build()
+	if err != nil {
+		return err
+	}
+	var itemErr error
+	scanErr := d.DB.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		FilterExpression:          filter.Filter(),
+		ExpressionAttributeNames:  filter.Names(),
+		ExpressionAttributeValues: filter.Values(),
+		TableName:                 aws.String(d.TableName),
+	}, func(page *dynamodb.ScanOutput, isLast bool) bool {
+		for _, item := range page.Items {
+			record := ddbLookupTableRecord{}
+			if itemErr = dynamodbattribute.UnmarshalMap(item, &record); itemErr != nil {
+				return false
+			}
+			if !scan(&record.LookupTableRecord) {
+				return false
+			}
+		}
+		return true
+	})
+	if scanErr != nil {
+		return scanErr
+	}
+	return itemErr
+}
+
+func (d *DynamoDBSchemas) GetLookupTable(ctx context.Context, name string) (*LookupTableRecord, error) {
+	output, err := d.DB.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key:       mustMarshalMap(lookupTableRecordKey(name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	record := ddbLookupTableRecord{}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &record); err != nil {
+		return nil, err
+	}
+	if record.Name == "" {
+		return nil, nil
+	}
+	return &record.LookupTableRecord, nil
+}
+
+// nolint:lll
+func (d *DynamoDBSchemas) PutLookupTable(ctx context.Context, name string, record *LookupTableRecord) (*LookupTableRecord, error) {
+	currentRevision := record.Revision
+	item := ddbLookupTableRecord{
+		recordKey:         lookupTableRecordKey(name),
+		LookupTableRecord: *record,
+	}
+	item.Revision = currentRevision + 1
+	cond := expression.Name(attrRecordKind).AttributeNotExists()
+	if currentRevision != 0 {
+		cond = expression.Name(attrRevision).Equal(expression.Value(currentRevision))
+	}
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to build put lookup table expression")
+	}
+	_, err = d.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(d.TableName),
+		Item:                      mustMarshalMap(&item),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		if errors.As(err, &dynamodb.ConditionalCheckFailedException{}) {
+			if currentRevision == 0 {
+				return nil, NewAPIError(ErrAlreadyExists, fmt.Sprintf("lookup table %q already exists", name))
+			}
+			return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("lookup table %q is not at revision %d", name, currentRevision))
+		}
+		return nil, err
+	}
+	return &item.LookupTableRecord, nil
+}
+
+func (d *DynamoDBSchemas) DeleteLookupTable(ctx context.Context, name string, revision int64) error {
+	expr, err := expression.NewBuilder().WithCondition(
+		expression.Name(attrRevision).Equal(expression.Value(revision)),
+	).Build()
+	if err != nil {
+		return errors.WithMessage(err, "failed to build delete lookup table expression")
+	}
+	_, err = d.DB.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(d.TableName),
+		Key:                       mustMarshalMap(lookupTableRecordKey(name)),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		if errors.As(err, &dynamodb.ConditionalCheckFailedException{}) {
+			return NewAPIError(ErrRevisionConflict, fmt.Sprintf("lookup table %q is not at revision %d", name, revision))
+		}
+		return err
+	}
+	return nil
+}
+
+func lookupTableRecordKey(name string) recordKey {
+	return recordKey{
+		RecordID:   strings.ToLower(name),
+		RecordKind: recordKindLookupTable,
+	}
+}
+
+type ddbLookupTableRecord struct {
+	recordKey
+	LookupTableRecord
+}
+
+func (d *DynamoDBSchemas) GetIndicators(ctx context.Context) (*IndicatorsRecord, error) {
+	output, err := d.DB.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key:       mustMarshalMap(indicatorsRecordKey()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+	record := ddbIndicatorsRecord{}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &record); err != nil {
+		return nil, err
+	}
+	return &record.IndicatorsRecord, nil
+}
+
+func (d *DynamoDBSchemas) PutIndicators(ctx context.Context, record *IndicatorsRecord) (*IndicatorsRecord, error) {
+	currentRevision := record.Revision
+	item := ddbIndicatorsRecord{
+		recordKey:        indicatorsRecordKey(),
+		IndicatorsRecord: *record,
+	}
+	item.Revision = currentRevision + 1
+	cond := expression.Name(attrRecordKind).AttributeNotExists()
+	if currentRevision != 0 {
+		cond = expression.Name(attrRevision).Equal(expression.Value(currentRevision))
+	}
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to build put indicators expression")
+	}
+	_, err = d.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(d.TableName),
+		Item:                      mustMarshalMap(&item),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		if errors.As(err, &dynamodb.ConditionalCheckFailedException{}) {
+			return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("global indicators are not at revision %d", currentRevision))
+		}
+		return nil, err
+	}
+	return &item.IndicatorsRecord, nil
+}
+
+// There is a single global indicators record
+func indicatorsRecordKey() recordKey {
+	return recordKey{
+		RecordID:   "global",
+		RecordKind: recordKindIndicators,
+	}
+}
+
+type ddbIndicatorsRecord struct {
+	recordKey
+	IndicatorsRecord
+}
+
+func (d *DynamoDBSchemas) GetRetention(ctx context.Context, logType string) (*RetentionRecord, error) {
+	output, err := d.DB.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.TableName),
+		Key:       mustMarshalMap(retentionRecordKey(logType)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+	record := ddbRetentionRecord{}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, &record); err != nil {
+		return nil, err
+	}
+	return &record.RetentionRecord, nil
+}
+
+// nolint:lll
+func (d *DynamoDBSchemas) PutRetention(ctx context.Context, logType string, record *RetentionRecord) (*RetentionRecord, error) {
+	currentRevision := record.Revision
+	item := ddbRetentionRecord{
+		recordKey:       retentionRecordKey(logType),
+		RetentionRecord: *record,
+	}
+	item.Revision = currentRevision + 1
+	cond := expression.Name(attrRecordKind).AttributeNotExists()
+	if currentRevision != 0 {
+		cond = expression.Name(attrRevision).Equal(expression.Value(currentRevision))
+	}
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to build put retention expression")
+	}
+	_, err = d.DB.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(d.TableName),
+		Item:                      mustMarshalMap(&item),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		if errors.As(err, &dynamodb.ConditionalCheckFailedException{}) {
+			return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("retention of %q is not at revision %d", logType, currentRevision))
+		}
+		return nil, err
+	}
+	return &item.RetentionRecord, nil
+}
+
+func (d *DynamoDBSchemas) ScanRetention(ctx context.Context, scan ScanRetentionFunc) error {
+	filter, err := expression.NewBuilder().WithFilter(
+		expression.Name(attrRecordKind).Equal(expression.Value(recordKindRetention)),
+	).Build()
+	if err != nil {
+		return err
+	}
+	var itemErr error
+	scanErr := d.DB.ScanPagesWithContext(ctx, &dynamodb.ScanInput{
+		FilterExpression:          filter.Filter(),
+		ExpressionAttributeNames:  filter.Names(),
+		ExpressionAttributeValues: filter.Values(),
+		TableName:                 aws.String(d.TableName),
+	}, func(page *dynamodb.ScanOutput, isLast bool) bool {
+		for _, item := range page.Items {
+			record := ddbRetentionRecord{}
+			if itemErr = dynamodbattribute.UnmarshalMap(item, &record); itemErr != nil {
+				return false
+			}
+			if !scan(&record.RetentionRecord) {
+				return false
+			}
+		}
+		return true
+	})
+	if scanErr != nil {
+		return scanErr
+	}
+	return itemErr
+}
+
+// Retention records use the same id as the schema record of the log type
+func retentionRecordKey(logType string) recordKey {
+	return recordKey{
+		RecordID:   strings.ToUpper(logType),
+		RecordKind: recordKindRetention,
+	}
+}
+
+type ddbRetentionRecord struct {
+	recordKey
+	RetentionRecord
+}
@@ -0,0 +1,192 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+)
+
+// IndicatorsDatabase handles the storage of the global indicators record
+type IndicatorsDatabase interface {
+	// GetIndicators gets the global indicators record
+	GetIndicators(ctx context.Context) (*IndicatorsRecord, error)
+	// PutIndicators puts the global indicators record, incrementing its revision
+	PutIndicators(ctx context.Context, record *IndicatorsRecord) (*IndicatorsRecord, error)
+}
+
+// IndicatorsRecord holds the custom indicators that are available to all custom log schemas.
+//
+// Indicators declared in a schema take precedence over global indicators with the same name.
+// nolint:lll
+type IndicatorsRecord struct {
+	Revision   int64                       `json:"revision" description:"Global indicators record revision"`
+	UpdatedAt  time.Time                   `json:"updatedAt" description:"Last update timestamp of the record"`
+	Indicators []logschema.IndicatorSchema `json:"indicators" description:"The custom indicators available to all custom log schemas"`
+}
+
+// GetIndicators gets the global indicators record
+func (api *LogTypesAPI) GetIndicators(ctx context.Context) (*GetIndicatorsOutput, error) {
+	record, err := api.getIndicators(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GetIndicatorsOutput{
+		Record: record,
+	}, nil
+}
+
+//nolint:lll
+type GetIndicatorsOutput struct {
+	Record *IndicatorsRecord `json:"record,omitempty" description:"The global indicators record (field omitted if an error occurred)"`
+	Error  *APIError         `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+// PutIndicators updates the global indicators record.
+//
+// The update is rejected if any custom log schema cannot be built with the new indicators.
+// The tables of all custom log types are updated to include new indicator fields.
+func (api *LogTypesAPI) PutIndicators(ctx context.Context, input *PutIndicatorsInput) (*PutIndicatorsOutput, error) {
+	if err := customlogs.ValidateIndicators(input.Indicators...); err != nil {
+		return nil, NewAPIError(ErrInvalidLogSchema, err.Error())
+	}
+	current, err := api.getIndicators(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revision != input.Revision {
+		return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("global indicators are not on revision %d", input.Revision))
+	}
+
+	var custom []*logschema.Schema
+	var checkErr error
+	scan := func(r *SchemaRecord) bool {
+		if !r.IsCustom() || r.Disabled {
+			return true
+		}
+		schema, err := buildSchema(r.Spec)
+		if err != nil {
+			checkErr = multierr.Append(checkErr, fmt.Errorf("schema %q is invalid: %s", r.Name, err))
+			return true
+		}
+		schema.Schema = r.Name
+		merged := mergeIndicators(schema, input.Indicators)
+		if err := checkSchema(r.Name, merged); err != nil {
+			checkErr = multierr.Append(checkErr, fmt.Errorf("schema %q is invalid: %s", r.Name, err))
+			return true
+		}
+		custom = append(custom, schema)
+		return true
+	}
+	if err := api.Database.ScanSchemas(ctx, scan); err != nil {
+		return nil, err
+	}
+	if checkErr != nil {
+		return nil, NewAPIError(ErrInvalidUpdate, fmt.Sprintf("indicators update breaks custom log schemas: %s", checkErr))
+	}
+
+	record, err := api.GlobalIndicators.PutIndicators(ctx, &IndicatorsRecord{
+		Revision:   input.Revision,
+		UpdatedAt:  time.Now(),
+		Indicators: input.Indicators,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, schema := range custom {
+		if err := api.UpdateDataCatalog(ctx, schema.Schema, schema.Fields, schema.Fields); err != nil {
+			// The error will be shown to the user as a "ServerError"
+			return nil, errors.Wrapf(err, "could not queue event for %q database update", schema.Schema)
+		}
+	}
+	return &PutIndicatorsOutput{
+		Record: record,
+	}, nil
+}
+
+// nolint:lll
+type PutIndicatorsInput struct {
+	Revision   int64                       `json:"revision" validate:"min=0" description:"Global indicators record revision to update (zero if no indicators were stored yet)"`
+	Indicators []logschema.IndicatorSchema `json:"indicators" description:"The custom indicators available to all custom log schemas"`
+}
+
+//nolint:lll
+type PutIndicatorsOutput struct {
+	Record *IndicatorsRecord `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error  *APIError         `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+func (api *LogTypesAPI) getIndicators(ctx context.Context) (*IndicatorsRecord, error) {
+	if api.GlobalIndicators == nil {
+		return &IndicatorsRecord{}, nil
+	}
+	record, err := api.GlobalIndicators.GetIndicators(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return &IndicatorsRecord{}, nil
+	}
+	return record, nil
+}
+
+// checkCustomSchema checks that a custom log schema can be built using the global indicators
+func (api *LogTypesAPI) checkCustomSchema(ctx context.Context, name string, schema *logschema.Schema) error {
+	merged, err := api.withGlobalIndicators(ctx, schema)
+	if err != nil {
+		return err
+	}
+	return checkSchema(name, merged)
+}
+
+// withGlobalIndicators returns a copy of a custom log schema that includes the global indicators
+func (api *LogTypesAPI) withGlobalIndicators(ctx context.Context, schema *logschema.Schema) (*logschema.Schema, error) {
+	record, err := api.getIndicators(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mergeIndicators(schema, record.Indicators), nil
+}
+
+// mergeIndicators returns a shallow copy of schema that includes the global indicators not declared in the schema
+func mergeIndicators(schema *logschema.Schema, global []logschema.IndicatorSchema) *logschema.Schema {
+	out := *schema
+	if len(global) == 0 {
+		return &out
+	}
+	declared := make(map[string]bool, len(schema.Indicators))
+	for _, ind := range schema.Indicators {
+		declared[ind.Name] = true
+	}
+	out.Indicators = make([]logschema.IndicatorSchema, 0, len(global)+len(schema.Indicators))
+	for _, ind := range global {
+		if !declared[ind.Name] {
+			out.Indicators = append(out.Indicators, ind)
+		}
+	}
+	out.Indicators = append(out.Indicators, schema.Indicators...)
+	return &out
+}
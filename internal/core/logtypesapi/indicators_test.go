@@ -0,0 +1,109 @@
+package logtypesapi_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+)
+
+func TestAPI_PutIndicators(t *testing.T) {
+	db := logtypesapi.NewInMemory()
+	var updated []string
+	api := logtypesapi.LogTypesAPI{
+		Database:         db,
+		GlobalIndicators: db,
+		UpdateDataCatalog: func(_ context.Context, logType string, _, _ []logschema.FieldSchema) error {
+			updated = append(updated, logType)
+			return nil
+		},
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+
+	const spec = `{"version": 0, "fields": [{"name": "user", "type": "string", "indicators": ["employee_id"]}]}`
+	_, err := api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType: "Custom.Event",
+		Spec:    spec,
+	})
+	assert.Error(err, "undeclared indicator")
+
+	_, err = api.PutIndicators(ctx, &logtypesapi.PutIndicatorsInput{
+		Indicators: []logschema.IndicatorSchema{
+			{Name: "employee_id", Field: "p_any_employee_ids", Match: "("},
+		},
+	})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrInvalidLogSchema, logtypesapi.AsAPIError(err).Code)
+
+	reply, err := api.PutIndicators(ctx, &logtypesapi.PutIndicatorsInput{
+		Indicators: []logschema.IndicatorSchema{
+			{Name: "employee_id", Field: "p_any_employee_ids", Match: `E[0-9]+`},
+		},
+	})
+	assert.NoError(err)
+	assert.Equal(int64(1), reply.Record.Revision)
+
+	_, err = api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType: "Custom.Event",
+		Spec:    spec,
+	})
+	assert.NoError(err)
+
+	schema, err := api.GetSchema(ctx, &logtypesapi.GetSchemaInput{
+		Name: "Custom.Event",
+	})
+	assert.NoError(err)
+	assert.Equal(reply.Record.Indicators, schema.Indicators)
+
+	_, err = api.PutIndicators(ctx, &logtypesapi.PutIndicatorsInput{
+		Indicators: []logschema.IndicatorSchema{
+			{Name: "employee_id", Field: "p_any_employee_ids"},
+		},
+	})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrRevisionConflict, logtypesapi.AsAPIError(err).Code)
+
+	_, err = api.PutIndicators(ctx, &logtypesapi.PutIndicatorsInput{
+		Revision: 1,
+	})
+	assert.Error(err, "indicator is used by a custom log schema")
+	assert.Equal(logtypesapi.ErrInvalidUpdate, logtypesapi.AsAPIError(err).Code)
+
+	updated = nil
+	reply, err = api.PutIndicators(ctx, &logtypesapi.PutIndicatorsInput{
+		Revision: 1,
+		Indicators: []logschema.IndicatorSchema{
+			{Name: "employee_id", Field: "p_any_employee_ids"},
+			{Name: "pod", Field: "p_any_k8s_pods"},
+		},
+	})
+	assert.NoError(err)
+	assert.Equal(int64(2), reply.Record.Revision)
+	assert.Equal([]string{"Custom.Event"}, updated)
+
+	current, err := api.GetIndicators(ctx)
+	assert.NoError(err)
+	assert.Equal(reply.Record, current.Record)
+}
@@ -0,0 +1,131 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+const (
+	defaultInferMaxObjects = 10
+	defaultInferMaxEvents  = 1000
+	// The log type name used to validate the draft schema
+	inferLogTypeName = customlogs.LogTypePrefix + ".Draft"
+)
+
+// InferCustomLogSchema infers a draft schema for a custom log type from sample log entries.
+// The entries are read either from objects under an S3 prefix or from the sample data in the input.
+// nolint:lll
+func (api *LogTypesAPI) InferCustomLogSchema(ctx context.Context, input *InferCustomLogSchemaInput) (*InferCustomLogSchemaOutput, error) {
+	sampler := customlogs.Sampler{
+		MaxEvents: input.MaxEvents,
+	}
+	if sampler.MaxEvents == 0 {
+		sampler.MaxEvents = defaultInferMaxEvents
+	}
+	numObjects := 0
+	if input.Sample != "" {
+		stream, err := newSampleStream(strings.NewReader(input.Sample), input.JSONStream)
+		if err != nil {
+			return nil, NewAPIError(ErrInvalidSyntax, err.Error())
+		}
+		if err := sampler.SampleStream(stream); err != nil {
+			return nil, NewAPIError(ErrInvalidSyntax, err.Error())
+		}
+	} else {
+		n, err := api.sampleS3(ctx, &sampler, input)
+		if err != nil {
+			return nil, err
+		}
+		numObjects = n
+	}
+	schema := sampler.Schema()
+	if schema == nil {
+		return nil, NewAPIError(ErrNotFound, "no JSON log entries found in samples")
+	}
+	spec, err := yaml.Marshal(schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal draft schema")
+	}
+	return &InferCustomLogSchemaOutput{
+		Spec:       string(spec),
+		NumObjects: numObjects,
+		Report:     sampler.Report(inferLogTypeName),
+	}, nil
+}
+
+// nolint:lll
+type InferCustomLogSchemaInput struct {
+	S3Bucket   string                      `json:"s3Bucket,omitempty" validate:"required_without=Sample" description:"The S3 bucket to read sample objects from"`
+	S3Prefix   string                      `json:"s3Prefix,omitempty" description:"The S3 prefix to read sample objects from"`
+	Sample     string                      `json:"sample,omitempty" validate:"required_without=S3Bucket" description:"Sample log entries to use instead of S3 objects"`
+	MaxObjects int                         `json:"maxObjects,omitempty" validate:"omitempty,min=1,max=100" description:"The maximum number of S3 objects to sample (default 10)"`
+	MaxEvents  int                         `json:"maxEvents,omitempty" validate:"omitempty,min=1,max=10000" description:"The maximum number of log entries to sample (default 1000)"`
+	JSONStream *logstream.JSONStreamConfig `json:"jsonStream,omitempty" description:"Read log entries from a stream of JSON values instead of lines"`
+}
+
+// nolint:lll
+type InferCustomLogSchemaOutput struct {
+	Spec       string                   `json:"logSpec,omitempty" description:"The draft schema spec in YAML format (field omitted if an error occurred)"`
+	NumObjects int                      `json:"numObjects,omitempty" description:"The number of S3 objects sampled"`
+	Report     *customlogs.SampleReport `json:"report,omitempty" description:"A report of the sampled log entries and the coverage of each field"`
+	Error      *APIError                `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+func (api *LogTypesAPI) sampleS3(ctx context.Context, sampler *customlogs.Sampler, input *InferCustomLogSchemaInput) (int, error) {
+	if api.S3ForBucket == nil {
+		return 0, errors.New("sampling S3 objects is not supported")
+	}
+	client, err := api.S3ForBucket(ctx, input.S3Bucket)
+	if err != nil {
+		return 0, err
+	}
+	maxObjects := input.MaxObjects
+	if maxObjects == 0 {
+		maxObjects = defaultInferMaxObjects
+	}
+	src := S3Source{
+		S3:       client,
+		Bucket:   input.S3Bucket,
+		Prefixes: []string{input.S3Prefix},
+	}
+	keys, err := src.listObjects(ctx, time.Time{}, time.Time{}, maxObjects)
+	if err != nil {
+		return 0, err
+	}
+	numObjects := 0
+	for _, key := range keys {
+		if sampler.Full() {
+			break
+		}
+		if err := src.readObject(ctx, key, input.JSONStream, sampler.SampleStream); err != nil {
+			return numObjects, err
+		}
+		numObjects++
+	}
+	return numObjects, nil
+}
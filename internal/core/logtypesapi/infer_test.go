@@ -0,0 +1,123 @@
+package logtypesapi_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestAPI_InferCustomLogSchemaSample(t *testing.T) {
+	assert := require.New(t)
+	api := logtypesapi.LogTypesAPI{}
+	reply, err := api.InferCustomLogSchema(context.Background(), &logtypesapi.InferCustomLogSchemaInput{
+		Sample: `{"ts": "2020-01-02T10:11:12Z", "url": "https://example.com"}` + "\n" + `{"ts": "2020-01-02T10:11:13Z"}`,
+	})
+	assert.NoError(err)
+	schema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal([]byte(reply.Spec), &schema))
+	fields := fieldsByName(schema.Fields)
+	assert.Len(fields, 2)
+	assert.True(fields["ts"].IsEventTime)
+	assert.Equal([]string{"url"}, fields["url"].Indicators)
+	assert.Equal(2, reply.Report.NumEvents)
+	assert.Equal(0, reply.Report.NumParseErrors)
+
+	_, err = api.InferCustomLogSchema(context.Background(), &logtypesapi.InferCustomLogSchemaInput{
+		Sample: "not json",
+	})
+	assert.Equal(logtypesapi.ErrNotFound, logtypesapi.AsAPIError(err).Code)
+}
+
+func TestAPI_InferCustomLogSchemaS3(t *testing.T) {
+	assert := require.New(t)
+	s3Mock := &testutils.S3Mock{}
+	api := logtypesapi.LogTypesAPI{
+		S3ForBucket: func(_ context.Context, bucket string) (s3iface.S3API, error) {
+			assert.Equal("bucket", bucket)
+			return s3Mock, nil
+		},
+	}
+	s3Mock.On("ListObjectsV2PagesWithContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []*s3.Object{
+			{Key: aws.String("logs/"), Size: aws.Int64(0)},
+			{Key: aws.String("logs/a.json.gz"), Size: aws.Int64(10)},
+			{Key: aws.String("logs/b.json"), Size: aws.Int64(10)},
+		},
+	}, nil).Once()
+
+	gzipped := bytes.Buffer{}
+	gz := gzip.NewWriter(&gzipped)
+	_, _ = gz.Write([]byte(`{"events": [{"id": 1}, {"id": 2}]}`))
+	assert.NoError(gz.Close())
+	s3Mock.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("logs/a.json.gz"),
+	}, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(&gzipped),
+	}, nil).Once()
+	s3Mock.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String("logs/b.json"),
+	}, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewBufferString(`{"events": [{"id": 3, "name": "foo"}]}`)),
+	}, nil).Once()
+
+	reply, err := api.InferCustomLogSchema(context.Background(), &logtypesapi.InferCustomLogSchemaInput{
+		S3Bucket: "bucket",
+		S3Prefix: "logs/",
+		JSONStream: &logstream.JSONStreamConfig{
+			Path: "events",
+		},
+	})
+	assert.NoError(err)
+	s3Mock.AssertExpectations(t)
+	assert.Equal(2, reply.NumObjects)
+	assert.Equal(3, reply.Report.NumEvents)
+	schema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal([]byte(reply.Spec), &schema))
+	fields := fieldsByName(schema.Fields)
+	assert.Len(fields, 2)
+	assert.Equal(logschema.TypeBigInt, fields["id"].Type)
+	assert.True(fields["id"].Required)
+	assert.False(fields["name"].Required)
+}
+
+func fieldsByName(fields []logschema.FieldSchema) map[string]logschema.FieldSchema {
+	m := make(map[string]logschema.FieldSchema, len(fields))
+	for _, f := range fields {
+		m[f.Name] = f
+	}
+	return m
+}
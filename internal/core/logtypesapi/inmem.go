@@ -24,14 +24,21 @@ import (
 	"sync"
 )
 
-// InMemDB is an in-memory implementation of the SchemaDatabase.
+// InMemDB is an in-memory implementation of the SchemaDatabase, LookupTableDatabase, IndicatorsDatabase and RetentionDatabase.
 // It is useful for tests and for caching results of another implementation.
 type InMemDB struct {
-	mu      sync.RWMutex
-	records map[string]*SchemaRecord
+	mu           sync.RWMutex
+	records      map[string]*SchemaRecord
+	revisions    map[string][]*SchemaRecord
+	lookupTables map[string]*LookupTableRecord
+	indicators   *IndicatorsRecord
+	retention    map[string]*RetentionRecord
 }
 
 var _ SchemaDatabase = (*InMemDB)(nil)
+var _ LookupTableDatabase = (*InMemDB)(nil)
+var _ IndicatorsDatabase = (*InMemDB)(nil)
+var _ RetentionDatabase = (*InMemDB)(nil)
 
 func NewInMemory() *InMemDB {
 	return &InMemDB{
@@ -57,10 +64,15 @@ func (db *InMemDB) PutSchema(_ context.Context, name string, r *SchemaRecord) (*
 	if db.records == nil {
 		db.records = map[string]*SchemaRecord{}
 	}
+	if db.revisions == nil {
+		db.revisions = map[string][]*SchemaRecord{}
+	}
 	current, ok := db.records[id]
 	if !ok {
 		r.Revision = 1
 		db.records[id] = r
+		snapshot := *r
+		db.revisions[id] = append(db.revisions[id], &snapshot)
 		return r, nil
 	}
 	if current.Revision != revision {
@@ -69,6 +81,8 @@ func (db *InMemDB) PutSchema(_ context.Context, name string, r *SchemaRecord) (*
 	rec := *r
 	rec.Revision++
 	db.records[id] = &rec
+	snapshot := rec
+	db.revisions[id] = append(db.revisions[id], &snapshot)
 	return &rec, nil
 }
 
@@ -82,3 +96,131 @@ func (db *InMemDB) ScanSchemas(_ context.Context, scan ScanSchemaFunc) error {
 	}
 	return nil
 }
+
+func (db *InMemDB) GetSchemaRevision(_ context.Context, id string, revision int64) (*SchemaRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, r := range db.revisions[strings.ToUpper(id)] {
+		if r.Revision == revision {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (db *InMemDB) ScanSchemaRevisions(_ context.Context, id string, scan ScanSchemaFunc) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, r := range db.revisions[strings.ToUpper(id)] {
+		if !scan(r) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (db *InMemDB) GetLookupTable(_ context.Context, name string) (*LookupTableRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.lookupTables[name], nil
+}
+
+func (db *InMemDB) PutLookupTable(_ context.Context, name string, r *LookupTableRecord) (*LookupTableRecord, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.lookupTables == nil {
+		db.lookupTables = map[string]*LookupTableRecord{}
+	}
+	var currentRevision int64
+	if current, ok := db.lookupTables[name]; ok {
+		currentRevision = current.Revision
+	}
+	if currentRevision != r.Revision {
+		return nil, NewAPIError(ErrRevisionConflict, "record revision mismatch")
+	}
+	rec := *r
+	rec.Revision++
+	db.lookupTables[name] = &rec
+	return &rec, nil
+}
+
+func (db *InMemDB) DeleteLookupTable(_ context.Context, name string, revision int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	current, ok := db.lookupTables[name]
+	if !ok || current.Revision != revision {
+		return NewAPIError(ErrRevisionConflict, "record revision mismatch")
+	}
+	delete(db.lookupTables, name)
+	return nil
+}
+
+func (db *InMemDB) ScanLookupTables(_ context.Context, scan ScanLookupTableFunc) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, r := range db.lookupTables {
+		if !scan(r) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (db *InMemDB) GetIndicators(_ context.Context) (*IndicatorsRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.indicators, nil
+}
+
+func (db *InMemDB) PutIndicators(_ context.Context, r *IndicatorsRecord) (*IndicatorsRecord, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var currentRevision int64
+	if db.indicators != nil {
+		currentRevision = db.indicators.Revision
+	}
+	if currentRevision != r.Revision {
+		return nil, NewAPIError(ErrRevisionConflict, "record revision mismatch")
+	}
+	rec := *r
+	rec.Revision++
+	db.indicators = &rec
+	return &rec, nil
+}
+
+func (db *InMemDB) GetRetention(_ context.Context, logType string) (*RetentionRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.retention[strings.ToUpper(logType)], nil
+}
+
+func (db *InMemDB) PutRetention(_ context.Context, logType string, r *RetentionRecord) (*RetentionRecord, error) {
+	id := strings.ToUpper(logType)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.retention == nil {
+		db.retention = map[string]*RetentionRecord{}
+	}
+	var currentRevision int64
+	if current, ok := db.retention[id]; ok {
+		currentRevision = current.Revision
+	}
+	if currentRevision != r.Revision {
+		return nil, NewAPIError(ErrRevisionConflict, "record revision mismatch")
+	}
+	rec := *r
+	rec.Revision++
+	db.retention[id] = &rec
+	return &rec, nil
+}
+
+func (db *InMemDB) ScanRetention(_ context.Context, scan ScanRetentionFunc) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, r := range db.retention {
+		if !scan(r) {
+			return nil
+		}
+	}
+	return nil
+}
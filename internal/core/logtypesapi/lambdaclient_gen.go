@@ -45,8 +45,23 @@ type LogTypesAPIPayload struct {
 	PutCustomLog             *PutCustomLogInput             `json:"PutCustomLog,omitempty"`
 	DelCustomLog             *DelCustomLogInput             `json:"DelCustomLog,omitempty"`
 	ListCustomLogs           *struct{}                      `json:"ListCustomLogs,omitempty"`
+	DryRunCustomLog          *DryRunCustomLogInput          `json:"DryRunCustomLog,omitempty"`
+	GetIndicators            *struct{}                      `json:"GetIndicators,omitempty"`
+	PutIndicators            *PutIndicatorsInput            `json:"PutIndicators,omitempty"`
+	InferCustomLogSchema     *InferCustomLogSchemaInput     `json:"InferCustomLogSchema,omitempty"`
+	GetLookupTable           *GetLookupTableInput           `json:"GetLookupTable,omitempty"`
+	PutLookupTable           *PutLookupTableInput           `json:"PutLookupTable,omitempty"`
+	DelLookupTable           *DelLookupTableInput           `json:"DelLookupTable,omitempty"`
+	ListLookupTables         *struct{}                      `json:"ListLookupTables,omitempty"`
 	ListManagedSchemaUpdates *ListManagedSchemaUpdatesInput `json:"ListManagedSchemaUpdates,omitempty"`
 	UpdateManagedSchemas     *UpdateManagedSchemasInput     `json:"UpdateManagedSchemas,omitempty"`
+	GetRetention             *GetRetentionInput             `json:"GetRetention,omitempty"`
+	PutRetention             *PutRetentionInput             `json:"PutRetention,omitempty"`
+	ListRetention            *struct{}                      `json:"ListRetention,omitempty"`
+	ListCustomLogRevisions   *ListCustomLogRevisionsInput   `json:"ListCustomLogRevisions,omitempty"`
+	GetCustomLogRevision     *GetCustomLogRevisionInput     `json:"GetCustomLogRevision,omitempty"`
+	DiffCustomLogRevisions   *DiffCustomLogRevisionsInput   `json:"DiffCustomLogRevisions,omitempty"`
+	RevertCustomLog          *RevertCustomLogInput          `json:"RevertCustomLog,omitempty"`
 	GetSchema                *GetSchemaInput                `json:"GetSchema,omitempty"`
 }
 
@@ -125,6 +140,112 @@ func (c *LogTypesAPILambdaClient) ListCustomLogs(ctx context.Context) (*ListCust
 	return &reply, nil
 }
 
+func (c *LogTypesAPILambdaClient) DryRunCustomLog(ctx context.Context, input *DryRunCustomLogInput) (*DryRunCustomLogOutput, error) {
+	if input == nil {
+		input = &DryRunCustomLogInput{}
+	}
+	payload := LogTypesAPIPayload{
+		DryRunCustomLog: input,
+	}
+	reply := DryRunCustomLogOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) GetIndicators(ctx context.Context) (*GetIndicatorsOutput, error) {
+	payload := LogTypesAPIPayload{
+		GetIndicators: &struct{}{},
+	}
+	reply := GetIndicatorsOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) PutIndicators(ctx context.Context, input *PutIndicatorsInput) (*PutIndicatorsOutput, error) {
+	if input == nil {
+		input = &PutIndicatorsInput{}
+	}
+	payload := LogTypesAPIPayload{
+		PutIndicators: input,
+	}
+	reply := PutIndicatorsOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) InferCustomLogSchema(ctx context.Context, input *InferCustomLogSchemaInput) (*InferCustomLogSchemaOutput, error) {
+	if input == nil {
+		input = &InferCustomLogSchemaInput{}
+	}
+	payload := LogTypesAPIPayload{
+		InferCustomLogSchema: input,
+	}
+	reply := InferCustomLogSchemaOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) GetLookupTable(ctx context.Context, input *GetLookupTableInput) (*GetLookupTableOutput, error) {
+	if input == nil {
+		input = &GetLookupTableInput{}
+	}
+	payload := LogTypesAPIPayload{
+		GetLookupTable: input,
+	}
+	reply := GetLookupTableOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) PutLookupTable(ctx context.Context, input *PutLookupTableInput) (*PutLookupTableOutput, error) {
+	if input == nil {
+		input = &PutLookupTableInput{}
+	}
+	payload := LogTypesAPIPayload{
+		PutLookupTable: input,
+	}
+	reply := PutLookupTableOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) DelLookupTable(ctx context.Context, input *DelLookupTableInput) (*DelLookupTableOutput, error) {
+	if input == nil {
+		input = &DelLookupTableInput{}
+	}
+	payload := LogTypesAPIPayload{
+		DelLookupTable: input,
+	}
+	reply := DelLookupTableOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) ListLookupTables(ctx context.Context) (*ListLookupTablesOutput, error) {
+	payload := LogTypesAPIPayload{
+		ListLookupTables: &struct{}{},
+	}
+	reply := ListLookupTablesOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *LogTypesAPILambdaClient) ListManagedSchemaUpdates(ctx context.Context, input *ListManagedSchemaUpdatesInput) (*ListManagedSchemaUpdatesOutput, error) {
 	if input == nil {
 		input = &ListManagedSchemaUpdatesInput{}
@@ -153,6 +274,101 @@ func (c *LogTypesAPILambdaClient) UpdateManagedSchemas(ctx context.Context, inpu
 	return &reply, nil
 }
 
+func (c *LogTypesAPILambdaClient) GetRetention(ctx context.Context, input *GetRetentionInput) (*GetRetentionOutput, error) {
+	if input == nil {
+		input = &GetRetentionInput{}
+	}
+	payload := LogTypesAPIPayload{
+		GetRetention: input,
+	}
+	reply := GetRetentionOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) PutRetention(ctx context.Context, input *PutRetentionInput) (*PutRetentionOutput, error) {
+	if input == nil {
+		input = &PutRetentionInput{}
+	}
+	payload := LogTypesAPIPayload{
+		PutRetention: input,
+	}
+	reply := PutRetentionOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) ListRetention(ctx context.Context) (*ListRetentionOutput, error) {
+	payload := LogTypesAPIPayload{
+		ListRetention: &struct{}{},
+	}
+	reply := ListRetentionOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) ListCustomLogRevisions(ctx context.Context, input *ListCustomLogRevisionsInput) (*ListCustomLogRevisionsOutput, error) {
+	if input == nil {
+		input = &ListCustomLogRevisionsInput{}
+	}
+	payload := LogTypesAPIPayload{
+		ListCustomLogRevisions: input,
+	}
+	reply := ListCustomLogRevisionsOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) GetCustomLogRevision(ctx context.Context, input *GetCustomLogRevisionInput) (*GetCustomLogRevisionOutput, error) {
+	if input == nil {
+		input = &GetCustomLogRevisionInput{}
+	}
+	payload := LogTypesAPIPayload{
+		GetCustomLogRevision: input,
+	}
+	reply := GetCustomLogRevisionOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) DiffCustomLogRevisions(ctx context.Context, input *DiffCustomLogRevisionsInput) (*DiffCustomLogRevisionsOutput, error) {
+	if input == nil {
+		input = &DiffCustomLogRevisionsInput{}
+	}
+	payload := LogTypesAPIPayload{
+		DiffCustomLogRevisions: input,
+	}
+	reply := DiffCustomLogRevisionsOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *LogTypesAPILambdaClient) RevertCustomLog(ctx context.Context, input *RevertCustomLogInput) (*RevertCustomLogOutput, error) {
+	if input == nil {
+		input = &RevertCustomLogInput{}
+	}
+	payload := LogTypesAPIPayload{
+		RevertCustomLog: input,
+	}
+	reply := RevertCustomLogOutput{}
+	if err := c.invoke(ctx, &payload, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
 func (c *LogTypesAPILambdaClient) GetSchema(ctx context.Context, input *GetSchemaInput) (*GetSchemaOutput, error) {
 	if input == nil {
 		input = &GetSchemaInput{}
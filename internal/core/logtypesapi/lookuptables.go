@@ -0,0 +1,213 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const (
+	// LookupTablesPrefix is the prefix in the processed data bucket under which lookup table data is stored
+	LookupTablesPrefix = "lookup_tables/"
+
+	LookupTableFormatCSV  = "csv"
+	LookupTableFormatJSON = "json"
+)
+
+// LookupTableDatabase handles the storage of lookup table records
+type LookupTableDatabase interface {
+	// GetLookupTable gets a single lookup table record
+	GetLookupTable(ctx context.Context, name string) (*LookupTableRecord, error)
+	// PutLookupTable puts a single lookup table record, incrementing its revision
+	PutLookupTable(ctx context.Context, name string, record *LookupTableRecord) (*LookupTableRecord, error)
+	// DeleteLookupTable deletes a lookup table record at a specific revision
+	DeleteLookupTable(ctx context.Context, name string, revision int64) error
+	// ScanLookupTables iterates through all lookup table records as long as scan returns true
+	ScanLookupTables(ctx context.Context, scan ScanLookupTableFunc) error
+}
+
+type ScanLookupTableFunc func(r *LookupTableRecord) bool
+
+// LookupTableRecord describes a lookup table used to enrich events.
+//
+// The data of a lookup table are stored in the processed data bucket under LookupTablesPrefix.
+// Events with indicator values matching the values of the key column are enriched with the matching row,
+// in the p_enrichment field under the name of the lookup table.
+// nolint:lll
+type LookupTableRecord struct {
+	Name        string    `json:"name" validate:"required" description:"The lookup table name"`
+	Revision    int64     `json:"revision" validate:"required,min=1" description:"Lookup table record revision"`
+	UpdatedAt   time.Time `json:"updatedAt" description:"Last update timestamp of the record"`
+	CreatedAt   time.Time `json:"createdAt" description:"Creation timestamp of the record"`
+	Description string    `json:"description" description:"Lookup table description"`
+	S3Key       string    `json:"s3Key" validate:"required" description:"The key of the lookup table data in the processed data bucket"`
+	Format      string    `json:"format" validate:"required,oneof=csv json" description:"The format of the lookup table data"`
+	KeyColumn   string    `json:"keyColumn" validate:"required" description:"The column with the values to match against indicator fields"`
+	Indicators  []string  `json:"indicators" validate:"required,min=1" description:"The indicator fields to match against the key column (ie p_any_ip_addresses)"`
+	Disabled    bool      `json:"disabled,omitempty" description:"Lookup table is not applied to events"`
+}
+
+// GetLookupTable gets a lookup table record
+func (api *LogTypesAPI) GetLookupTable(ctx context.Context, input *GetLookupTableInput) (*GetLookupTableOutput, error) {
+	record, err := api.LookupTables.GetLookupTable(ctx, input.Name)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, NewAPIError(ErrNotFound, fmt.Sprintf("lookup table %q not found", input.Name))
+	}
+	return &GetLookupTableOutput{
+		Record: record,
+	}, nil
+}
+
+type GetLookupTableInput struct {
+	Name string `json:"name" validate:"required" description:"The lookup table name"`
+}
+
+//nolint:lll
+type GetLookupTableOutput struct {
+	Record *LookupTableRecord `json:"record,omitempty" description:"The lookup table record (field omitted if an error occurred)"`
+	Error  *APIError          `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+// PutLookupTable creates or updates a lookup table record
+func (api *LogTypesAPI) PutLookupTable(ctx context.Context, input *PutLookupTableInput) (*PutLookupTableOutput, error) {
+	if err := validateLookupTable(input); err != nil {
+		return nil, err
+	}
+
+	record := LookupTableRecord{
+		Name:        input.Name,
+		Revision:    input.Revision,
+		Description: input.Description,
+		S3Key:       input.S3Key,
+		Format:      input.Format,
+		KeyColumn:   input.KeyColumn,
+		Indicators:  input.Indicators,
+		Disabled:    input.Disabled,
+	}
+	now := time.Now()
+	record.UpdatedAt = now
+	record.CreatedAt = now
+	if input.Revision != 0 {
+		current, err := api.LookupTables.GetLookupTable(ctx, input.Name)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, NewAPIError(ErrNotFound, fmt.Sprintf("lookup table %q was not found", input.Name))
+		}
+		if current.Revision != input.Revision {
+			return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("lookup table %q is not on revision %d", input.Name, input.Revision))
+		}
+		record.CreatedAt = current.CreatedAt
+	}
+
+	result, err := api.LookupTables.PutLookupTable(ctx, input.Name, &record)
+	if err != nil {
+		return nil, err
+	}
+	return &PutLookupTableOutput{
+		Record: result,
+	}, nil
+}
+
+var lookupTableNameRx = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func validateLookupTable(input *PutLookupTableInput) error {
+	if !lookupTableNameRx.MatchString(input.Name) {
+		return NewAPIError(ErrInvalidUpdate, fmt.Sprintf("invalid lookup table name %q", input.Name))
+	}
+	if key := path.Clean(input.S3Key); !strings.HasPrefix(key, LookupTablesPrefix) {
+		return NewAPIError(ErrInvalidUpdate, fmt.Sprintf("lookup table data must be stored under %q", LookupTablesPrefix))
+	}
+	for _, name := range input.Indicators {
+		if _, ok := pantherlog.IndicatorFieldByNameJSON(name); !ok {
+			return NewAPIError(ErrInvalidUpdate, fmt.Sprintf("invalid indicator field %q", name))
+		}
+	}
+	return nil
+}
+
+// nolint:lll
+type PutLookupTableInput struct {
+	Name string `json:"name" validate:"required" description:"The lookup table name (lowercase letters, digits and underscores)"`
+	// Revision is required when updating a lookup table record.
+	// If it is omitted a new lookup table record will be created.
+	Revision    int64    `json:"revision,omitempty" validate:"omitempty,min=1" description:"Lookup table record revision to update (if omitted a new record will be created)"`
+	Description string   `json:"description" description:"Lookup table description"`
+	S3Key       string   `json:"s3Key" validate:"required" description:"The key of the lookup table data in the processed data bucket (must be under lookup_tables/)"`
+	Format      string   `json:"format" validate:"required,oneof=csv json" description:"The format of the lookup table data (csv with a header row or JSON objects)"`
+	KeyColumn   string   `json:"keyColumn" validate:"required" description:"The column with the values to match against indicator fields"`
+	Indicators  []string `json:"indicators" validate:"required,min=1" description:"The indicator fields to match against the key column (ie p_any_ip_addresses)"`
+	Disabled    bool     `json:"disabled,omitempty" description:"Do not apply the lookup table to events"`
+}
+
+//nolint:lll
+type PutLookupTableOutput struct {
+	Record *LookupTableRecord `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error  *APIError          `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+// DelLookupTable deletes a lookup table record.
+// The lookup table data in S3 are not affected.
+func (api *LogTypesAPI) DelLookupTable(ctx context.Context, input *DelLookupTableInput) (*DelLookupTableOutput, error) {
+	if err := api.LookupTables.DeleteLookupTable(ctx, input.Name, input.Revision); err != nil {
+		return nil, err
+	}
+	return &DelLookupTableOutput{}, nil
+}
+
+type DelLookupTableInput struct {
+	Name     string `json:"name" validate:"required" description:"The lookup table name"`
+	Revision int64  `json:"revision" validate:"min=1" description:"Lookup table record revision"`
+}
+
+type DelLookupTableOutput struct {
+	Error *APIError `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+// ListLookupTables lists all lookup table records
+func (api *LogTypesAPI) ListLookupTables(ctx context.Context) (*ListLookupTablesOutput, error) {
+	records := make([]*LookupTableRecord, 0, 8)
+	scan := func(r *LookupTableRecord) bool {
+		records = append(records, r)
+		return true
+	}
+	if err := api.LookupTables.ScanLookupTables(ctx, scan); err != nil {
+		return nil, err
+	}
+	return &ListLookupTablesOutput{
+		Records: records,
+	}, nil
+}
+
+//nolint:lll
+type ListLookupTablesOutput struct {
+	Records []*LookupTableRecord `json:"lookupTables" description:"Lookup table records stored"`
+	Error   *APIError            `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
@@ -0,0 +1,110 @@
+package logtypesapi_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+)
+
+func TestAPI_LookupTables(t *testing.T) {
+	db := logtypesapi.NewInMemory()
+	api := logtypesapi.LogTypesAPI{
+		Database:     db,
+		LookupTables: db,
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+
+	input := logtypesapi.PutLookupTableInput{
+		Name:        "asset_owners",
+		Description: "Asset owners by IP address",
+		S3Key:       "lookup_tables/asset_owners.csv",
+		Format:      logtypesapi.LookupTableFormatCSV,
+		KeyColumn:   "ip",
+		Indicators:  []string{"p_any_ip_addresses"},
+	}
+	reply, err := api.PutLookupTable(ctx, &input)
+	assert.NoError(err)
+	assert.Equal(int64(1), reply.Record.Revision)
+	assert.Equal("ip", reply.Record.KeyColumn)
+	assert.False(reply.Record.CreatedAt.IsZero())
+
+	// Creating a record twice fails
+	_, err = api.PutLookupTable(ctx, &input)
+	assert.Error(err)
+
+	input.Revision = 1
+	input.KeyColumn = "address"
+	reply, err = api.PutLookupTable(ctx, &input)
+	assert.NoError(err)
+	assert.Equal(int64(2), reply.Record.Revision)
+
+	// Stale revision
+	_, err = api.PutLookupTable(ctx, &input)
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrRevisionConflict, logtypesapi.AsAPIError(err).Code)
+
+	get, err := api.GetLookupTable(ctx, &logtypesapi.GetLookupTableInput{Name: "asset_owners"})
+	assert.NoError(err)
+	assert.Equal(reply.Record, get.Record)
+
+	list, err := api.ListLookupTables(ctx)
+	assert.NoError(err)
+	assert.Equal([]*logtypesapi.LookupTableRecord{reply.Record}, list.Records)
+
+	_, err = api.DelLookupTable(ctx, &logtypesapi.DelLookupTableInput{Name: "asset_owners", Revision: 2})
+	assert.NoError(err)
+	_, err = api.GetLookupTable(ctx, &logtypesapi.GetLookupTableInput{Name: "asset_owners"})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrNotFound, logtypesapi.AsAPIError(err).Code)
+}
+
+func TestAPI_PutLookupTableInvalid(t *testing.T) {
+	db := logtypesapi.NewInMemory()
+	api := logtypesapi.LogTypesAPI{
+		LookupTables: db,
+	}
+	ctx := context.Background()
+	valid := logtypesapi.PutLookupTableInput{
+		Name:       "bad_domains",
+		S3Key:      "lookup_tables/bad_domains.json",
+		Format:     logtypesapi.LookupTableFormatJSON,
+		KeyColumn:  "domain",
+		Indicators: []string{"p_any_domain_names"},
+	}
+	for name, modify := range map[string]func(input *logtypesapi.PutLookupTableInput){
+		"name":      func(input *logtypesapi.PutLookupTableInput) { input.Name = "Bad-Domains" },
+		"prefix":    func(input *logtypesapi.PutLookupTableInput) { input.S3Key = "logs/bad_domains.json" },
+		"traversal": func(input *logtypesapi.PutLookupTableInput) { input.S3Key = "lookup_tables/../logs/foo.json" },
+		"indicator": func(input *logtypesapi.PutLookupTableInput) { input.Indicators = []string{"p_any_foo"} },
+	} {
+		input := valid
+		modify(&input)
+		_, err := api.PutLookupTable(ctx, &input)
+		require.Error(t, err, name)
+		require.Equal(t, logtypesapi.ErrInvalidUpdate, logtypesapi.AsAPIError(err).Code, name)
+	}
+	_, err := api.PutLookupTable(ctx, &valid)
+	require.NoError(t, err)
+}
@@ -20,12 +20,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	lambdaclient "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/google/go-github/github"
 	jsoniter "github.com/json-iterator/go"
@@ -64,11 +71,15 @@ func main() {
 
 	session := session.Must(session.NewSession())
 	lambdaClient := lambdaclient.New(session)
+	db := &logtypesapi.DynamoDBSchemas{
+		DB:        dynamodb.New(session),
+		TableName: config.LogTypesTableName,
+	}
 	api := &logtypesapi.LogTypesAPI{
-		Database: &logtypesapi.DynamoDBSchemas{
-			DB:        dynamodb.New(session),
-			TableName: config.LogTypesTableName,
-		},
+		Database:         db,
+		LookupTables:     db,
+		GlobalIndicators: db,
+		Retention:        db,
 		UpdateDataCatalog: func(ctx context.Context, logType string, from, to []logschema.FieldSchema) error {
 			if from == nil || to == nil {
 				return nil
@@ -80,13 +91,9 @@ func main() {
 			return client.SendUpdateTableForLogType(ctx, logType)
 		},
 		LogTypesInUse: func(ctx context.Context) ([]string, error) {
-			input := &models.LambdaInput{
-				ListIntegrations: &models.ListIntegrationsInput{},
-			}
-			var integrations []*models.SourceIntegration
-			const sourcesAPILambda = "panther-source-api"
-			if err := genericapi.Invoke(lambdaClient, sourcesAPILambda, input, &integrations); err != nil {
-				return nil, errors.Wrap(err, "failed to retrieve existing integrations")
+			integrations, err := listIntegrations(lambdaClient)
+			if err != nil {
+				return nil, err
 			}
 			var logTypes []string
 			for _, output := range integrations {
@@ -94,6 +101,50 @@ func main() {
 			}
 			return logTypes, nil
 		},
+		S3ForBucket: func(ctx context.Context, bucket string) (s3iface.S3API, error) {
+			integrations, err := listIntegrations(lambdaClient)
+			if err != nil {
+				return nil, err
+			}
+			// Only allow reading samples from buckets of existing sources using the role of the source
+			for _, integration := range integrations {
+				if b, _ := integration.S3Info(); b != bucket {
+					continue
+				}
+				roleARN := integration.RequiredLogProcessingRole()
+				if roleARN == "" {
+					continue
+				}
+				return newS3Client(ctx, session, bucket, roleARN)
+			}
+			return nil, logtypesapi.NewAPIError(logtypesapi.ErrNotFound, fmt.Sprintf("no source reads from S3 bucket %q", bucket))
+		},
+		SourceS3: func(ctx context.Context, sourceID string) (*logtypesapi.S3Source, error) {
+			integrations, err := listIntegrations(lambdaClient)
+			if err != nil {
+				return nil, err
+			}
+			for _, integration := range integrations {
+				if integration.IntegrationID != sourceID {
+					continue
+				}
+				bucket, prefixes := integration.S3Info()
+				roleARN := integration.RequiredLogProcessingRole()
+				if bucket == "" || roleARN == "" {
+					return nil, logtypesapi.NewAPIError(logtypesapi.ErrInvalidUpdate, fmt.Sprintf("source %q does not read from S3", sourceID))
+				}
+				client, err := newS3Client(ctx, session, bucket, roleARN)
+				if err != nil {
+					return nil, err
+				}
+				return &logtypesapi.S3Source{
+					S3:       client,
+					Bucket:   bucket,
+					Prefixes: prefixes,
+				}, nil
+			}
+			return nil, logtypesapi.NewAPIError(logtypesapi.ErrNotFound, fmt.Sprintf("source %q not found", sourceID))
+		},
 		ManagedSchemas: &managedschemas.GitHubRepository{
 			Repo:   "panther-analysis",
 			Owner:  "panther-labs",
@@ -143,3 +194,33 @@ func main() {
 
 	lambda.StartHandler(handler)
 }
+
+func listIntegrations(lambdaClient lambdaiface.LambdaAPI) ([]*models.SourceIntegration, error) {
+	input := &models.LambdaInput{
+		ListIntegrations: &models.ListIntegrationsInput{},
+	}
+	var integrations []*models.SourceIntegration
+	const sourcesAPILambda = "panther-source-api"
+	if err := genericapi.Invoke(lambdaClient, sourcesAPILambda, input, &integrations); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve existing integrations")
+	}
+	return integrations, nil
+}
+
+// newS3Client creates an S3 client in the region of the bucket by assuming the log processing role of a source.
+func newS3Client(ctx context.Context, sess *session.Session, bucket, roleARN string) (s3iface.S3API, error) {
+	creds := stscreds.NewCredentials(sess, roleARN)
+	client := s3.New(sess, aws.NewConfig().WithCredentials(creds))
+	location, err := client.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find region for S3 bucket %q", bucket)
+	}
+	// A nil location constraint means the bucket is in us-east-1
+	region := endpoints.UsEast1RegionID
+	if location.LocationConstraint != nil {
+		region = *location.LocationConstraint
+	}
+	return s3.New(sess, aws.NewConfig().WithCredentials(creds).WithRegion(region)), nil
+}
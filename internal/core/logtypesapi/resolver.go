@@ -75,5 +75,9 @@ func (r *Resolver) Resolve(ctx context.Context, name string) (logtypes.Entry, er
 	schema.Description = record.Description
 	schema.ReferenceURL = record.ReferenceURL
 
-	return customlogs.Build(record.Name, &schema)
+	merged := mergeIndicators(&schema, reply.Indicators)
+	if err := customlogs.RegisterIndicators(record.Name, merged.Indicators...); err != nil {
+		return nil, err
+	}
+	return customlogs.Build(record.Name, merged)
 }
@@ -0,0 +1,242 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/panther-labs/panther/internal/compliance/snapshotlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+// Storage classes that expired data can be archived to
+const (
+	ArchiveStandardIA         = "STANDARD_IA"
+	ArchiveOneZoneIA          = "ONEZONE_IA"
+	ArchiveIntelligentTiering = "INTELLIGENT_TIERING"
+	ArchiveGlacier            = "GLACIER"
+	ArchiveDeepArchive        = "DEEP_ARCHIVE"
+)
+
+const maxRetentionDays = 36500
+
+// RetentionDatabase handles the storage of per log type retention records
+type RetentionDatabase interface {
+	// GetRetention gets the retention record of a log type
+	GetRetention(ctx context.Context, logType string) (*RetentionRecord, error)
+	// PutRetention puts the retention record of a log type, incrementing its revision
+	PutRetention(ctx context.Context, logType string, record *RetentionRecord) (*RetentionRecord, error)
+	// ScanRetention iterates through all retention records as long as scan returns true
+	ScanRetention(ctx context.Context, scan ScanRetentionFunc) error
+}
+
+type ScanRetentionFunc func(r *RetentionRecord) bool
+
+// RetentionRecord holds the retention policies for the data of a log type.
+//
+// Each database holding data for the log type has its own policy.
+// A nil policy keeps the data forever.
+// nolint:lll
+type RetentionRecord struct {
+	LogType     string           `json:"logType" description:"The log type the retention policies apply to"`
+	Revision    int64            `json:"revision" description:"Retention record revision"`
+	UpdatedAt   time.Time        `json:"updatedAt" description:"Last update timestamp of the record"`
+	Logs        *RetentionPolicy `json:"logs,omitempty" description:"The retention policy for processed log data (omitted if data are kept forever)"`
+	RuleMatches *RetentionPolicy `json:"ruleMatches,omitempty" description:"The retention policy for rule matches (omitted if data are kept forever)"`
+	RuleErrors  *RetentionPolicy `json:"ruleErrors,omitempty" description:"The retention policy for rule errors (omitted if data are kept forever)"`
+}
+
+// RetentionPolicy sets how long data are kept and what happens to them once they expire
+// nolint:lll
+type RetentionPolicy struct {
+	Days                int    `json:"days" validate:"min=1,max=36500" description:"The number of days to keep the data queryable"`
+	ArchiveStorageClass string `json:"archiveStorageClass,omitempty" validate:"omitempty,oneof=STANDARD_IA ONEZONE_IA INTELLIGENT_TIERING GLACIER DEEP_ARCHIVE" description:"Move expired data to this S3 storage class instead of deleting them"`
+}
+
+// Policies returns the retention policies of the record by database name
+func (r *RetentionRecord) Policies() map[string]*RetentionPolicy {
+	policies := make(map[string]*RetentionPolicy, 3)
+	if r.Logs != nil {
+		switch pantherdb.GetDataType(r.LogType) {
+		case pantherdb.CloudSecurity:
+			policies[pantherdb.CloudSecurityDatabase] = r.Logs
+		default:
+			policies[pantherdb.LogProcessingDatabase] = r.Logs
+		}
+	}
+	if r.RuleMatches != nil {
+		policies[pantherdb.RuleMatchDatabase] = r.RuleMatches
+	}
+	if r.RuleErrors != nil {
+		policies[pantherdb.RuleErrorsDatabase] = r.RuleErrors
+	}
+	return policies
+}
+
+// GetRetention gets the retention record of a log type.
+//
+// If no retention policies were set for the log type the record has revision zero and keeps all data forever.
+func (api *LogTypesAPI) GetRetention(ctx context.Context, input *GetRetentionInput) (*GetRetentionOutput, error) {
+	record, err := api.getRetention(ctx, input.LogType)
+	if err != nil {
+		return nil, err
+	}
+	return &GetRetentionOutput{
+		Record: record,
+	}, nil
+}
+
+type GetRetentionInput struct {
+	LogType string `json:"logType" validate:"required" description:"The log type id"`
+}
+
+//nolint:lll
+type GetRetentionOutput struct {
+	Record *RetentionRecord `json:"record,omitempty" description:"The retention record (field omitted if an error occurred)"`
+	Error  *APIError        `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+// PutRetention updates the retention policies of a log type.
+//
+// Expired data are not removed by this call, they are removed by the next run of the retention job.
+func (api *LogTypesAPI) PutRetention(ctx context.Context, input *PutRetentionInput) (*PutRetentionOutput, error) {
+	if api.Retention == nil {
+		return nil, NewAPIError(ErrServerError, "retention policies are not supported")
+	}
+	for _, p := range []*RetentionPolicy{input.Logs, input.RuleMatches, input.RuleErrors} {
+		if err := validateRetentionPolicy(p); err != nil {
+			return nil, NewAPIError(ErrInvalidUpdate, err.Error())
+		}
+	}
+	if err := api.checkLogTypeExists(ctx, input.LogType); err != nil {
+		return nil, err
+	}
+	current, err := api.getRetention(ctx, input.LogType)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revision != input.Revision {
+		return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("retention of %q is not on revision %d", input.LogType, input.Revision))
+	}
+	record, err := api.Retention.PutRetention(ctx, input.LogType, &RetentionRecord{
+		LogType:     input.LogType,
+		Revision:    input.Revision,
+		UpdatedAt:   time.Now(),
+		Logs:        input.Logs,
+		RuleMatches: input.RuleMatches,
+		RuleErrors:  input.RuleErrors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PutRetentionOutput{
+		Record: record,
+	}, nil
+}
+
+// nolint:lll
+type PutRetentionInput struct {
+	LogType     string           `json:"logType" validate:"required" description:"The log type id"`
+	Revision    int64            `json:"revision" validate:"min=0" description:"Retention record revision to update (zero if no policies were stored yet)"`
+	Logs        *RetentionPolicy `json:"logs,omitempty" description:"The retention policy for processed log data (omit to keep data forever)"`
+	RuleMatches *RetentionPolicy `json:"ruleMatches,omitempty" description:"The retention policy for rule matches (omit to keep data forever)"`
+	RuleErrors  *RetentionPolicy `json:"ruleErrors,omitempty" description:"The retention policy for rule errors (omit to keep data forever)"`
+}
+
+//nolint:lll
+type PutRetentionOutput struct {
+	Record *RetentionRecord `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error  *APIError        `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+// ListRetention lists the retention records of all log types with retention policies
+func (api *LogTypesAPI) ListRetention(ctx context.Context) (*ListRetentionOutput, error) {
+	records := make([]*RetentionRecord, 0)
+	if api.Retention == nil {
+		return &ListRetentionOutput{
+			Records: records,
+		}, nil
+	}
+	scan := func(r *RetentionRecord) bool {
+		records = append(records, r)
+		return true
+	}
+	if err := api.Retention.ScanRetention(ctx, scan); err != nil {
+		return nil, err
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].LogType < records[j].LogType
+	})
+	return &ListRetentionOutput{
+		Records: records,
+	}, nil
+}
+
+//nolint:lll
+type ListRetentionOutput struct {
+	Records []*RetentionRecord `json:"records,omitempty" description:"The retention records of all log types with retention policies (omitted if an error occurred)"`
+	Error   *APIError          `json:"error,omitempty" description:"An error that occurred while fetching the records"`
+}
+
+func (api *LogTypesAPI) getRetention(ctx context.Context, logType string) (*RetentionRecord, error) {
+	if api.Retention == nil {
+		return &RetentionRecord{LogType: logType}, nil
+	}
+	record, err := api.Retention.GetRetention(ctx, logType)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return &RetentionRecord{LogType: logType}, nil
+	}
+	return record, nil
+}
+
+// checkLogTypeExists checks that a log type is available or that it is a cloud security snapshot log type
+func (api *LogTypesAPI) checkLogTypeExists(ctx context.Context, logType string) error {
+	if snapshotlogs.LogTypes().Find(logType) != nil {
+		return nil
+	}
+	record, err := api.Database.GetSchema(ctx, logType)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.Disabled || record.Name != logType {
+		return NewAPIError(ErrNotFound, fmt.Sprintf("log type %q not found", logType))
+	}
+	return nil
+}
+
+func validateRetentionPolicy(p *RetentionPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.Days < 1 || p.Days > maxRetentionDays {
+		return fmt.Errorf("retention days must be between 1 and %d", maxRetentionDays)
+	}
+	switch p.ArchiveStorageClass {
+	case "", ArchiveStandardIA, ArchiveOneZoneIA, ArchiveIntelligentTiering, ArchiveGlacier, ArchiveDeepArchive:
+		return nil
+	default:
+		return fmt.Errorf("invalid archive storage class %q", p.ArchiveStorageClass)
+	}
+}
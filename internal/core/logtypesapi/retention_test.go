@@ -0,0 +1,105 @@
+package logtypesapi_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+func TestAPI_Retention(t *testing.T) {
+	db := logtypesapi.NewInMemory()
+	api := logtypesapi.LogTypesAPI{
+		Database:  db,
+		Retention: db,
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+
+	_, err := db.PutSchema(ctx, "Custom.Foo", &logtypesapi.SchemaRecord{Name: "Custom.Foo"})
+	assert.NoError(err)
+
+	get, err := api.GetRetention(ctx, &logtypesapi.GetRetentionInput{LogType: "Custom.Foo"})
+	assert.NoError(err)
+	assert.Equal(int64(0), get.Record.Revision)
+	assert.Empty(get.Record.Policies())
+
+	// Unknown log type
+	_, err = api.PutRetention(ctx, &logtypesapi.PutRetentionInput{
+		LogType: "Custom.Bar",
+		Logs:    &logtypesapi.RetentionPolicy{Days: 30},
+	})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrNotFound, logtypesapi.AsAPIError(err).Code)
+
+	// Invalid policy
+	_, err = api.PutRetention(ctx, &logtypesapi.PutRetentionInput{
+		LogType: "Custom.Foo",
+		Logs:    &logtypesapi.RetentionPolicy{Days: 30, ArchiveStorageClass: "REDUCED_REDUNDANCY"},
+	})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrInvalidUpdate, logtypesapi.AsAPIError(err).Code)
+
+	input := logtypesapi.PutRetentionInput{
+		LogType:     "Custom.Foo",
+		Logs:        &logtypesapi.RetentionPolicy{Days: 90, ArchiveStorageClass: logtypesapi.ArchiveGlacier},
+		RuleMatches: &logtypesapi.RetentionPolicy{Days: 365},
+	}
+	reply, err := api.PutRetention(ctx, &input)
+	assert.NoError(err)
+	assert.Equal(int64(1), reply.Record.Revision)
+	assert.Equal(map[string]*logtypesapi.RetentionPolicy{
+		pantherdb.LogProcessingDatabase: input.Logs,
+		pantherdb.RuleMatchDatabase:     input.RuleMatches,
+	}, reply.Record.Policies())
+
+	// Stale revision
+	_, err = api.PutRetention(ctx, &input)
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrRevisionConflict, logtypesapi.AsAPIError(err).Code)
+
+	input.Revision = 1
+	input.RuleErrors = &logtypesapi.RetentionPolicy{Days: 7}
+	reply, err = api.PutRetention(ctx, &input)
+	assert.NoError(err)
+	assert.Equal(int64(2), reply.Record.Revision)
+
+	get, err = api.GetRetention(ctx, &logtypesapi.GetRetentionInput{LogType: "Custom.Foo"})
+	assert.NoError(err)
+	assert.Equal(reply.Record, get.Record)
+
+	// Cloud security log types are stored in a different database
+	snapshot, err := api.PutRetention(ctx, &logtypesapi.PutRetentionInput{
+		LogType: "Compliance.History",
+		Logs:    &logtypesapi.RetentionPolicy{Days: 30},
+	})
+	assert.NoError(err)
+	assert.Equal(map[string]*logtypesapi.RetentionPolicy{
+		pantherdb.CloudSecurityDatabase: {Days: 30},
+	}, snapshot.Record.Policies())
+
+	list, err := api.ListRetention(ctx)
+	assert.NoError(err)
+	assert.Equal([]*logtypesapi.RetentionRecord{snapshot.Record, reply.Record}, list.Records)
+}
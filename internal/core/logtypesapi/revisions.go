@@ -0,0 +1,356 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+)
+
+// ListCustomLogRevisions lists all stored revisions of a custom log record, oldest first.
+// nolint:lll
+func (api *LogTypesAPI) ListCustomLogRevisions(ctx context.Context, input *ListCustomLogRevisionsInput) (*ListCustomLogRevisionsOutput, error) {
+	id := customlogs.LogType(input.LogType)
+	current, err := api.getCustomLogRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := api.scanRevisions(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	return &ListCustomLogRevisionsOutput{
+		Records: revisions,
+	}, nil
+}
+
+type ListCustomLogRevisionsInput struct {
+	LogType string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+}
+
+//nolint:lll
+type ListCustomLogRevisionsOutput struct {
+	Records []*SchemaRecord `json:"revisions" description:"The revisions of the custom log record (field omitted if an error occurred)"`
+	Error   *APIError       `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+// GetCustomLogRevision gets a specific revision of a custom log record
+func (api *LogTypesAPI) GetCustomLogRevision(ctx context.Context, input *GetCustomLogRevisionInput) (*GetCustomLogRevisionOutput, error) {
+	id := customlogs.LogType(input.LogType)
+	current, err := api.getCustomLogRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	record, err := api.getRevision(ctx, current, input.Revision)
+	if err != nil {
+		return nil, err
+	}
+	return &GetCustomLogRevisionOutput{
+		Result: record,
+	}, nil
+}
+
+type GetCustomLogRevisionInput struct {
+	LogType  string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	Revision int64  `json:"revision" validate:"required,min=1" description:"The revision to retrieve"`
+}
+
+//nolint:lll
+type GetCustomLogRevisionOutput struct {
+	Result *SchemaRecord `json:"record,omitempty" description:"The custom log record at the requested revision (field omitted if an error occurred)"`
+	Error  *APIError     `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+}
+
+// DiffCustomLogRevisions lists the schema changes required to go from one revision of a custom log record to another
+// nolint:lll
+func (api *LogTypesAPI) DiffCustomLogRevisions(ctx context.Context, input *DiffCustomLogRevisionsInput) (*DiffCustomLogRevisionsOutput, error) {
+	id := customlogs.LogType(input.LogType)
+	current, err := api.getCustomLogRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	from, err := api.getRevision(ctx, current, input.FromRevision)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.getRevision(ctx, current, input.ToRevision)
+	if err != nil {
+		return nil, err
+	}
+	fromSchema, err := buildRecordSchema(from)
+	if err != nil {
+		return nil, err
+	}
+	toSchema, err := buildRecordSchema(to)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := logschema.Diff(fromSchema, toSchema)
+	if err != nil {
+		return nil, NewAPIError(ErrInvalidLogSchema, err.Error())
+	}
+	changes := make([]SchemaChange, 0, len(diff))
+	for i := range diff {
+		changes = append(changes, newSchemaChange(&diff[i]))
+	}
+	return &DiffCustomLogRevisionsOutput{
+		Changes: changes,
+	}, nil
+}
+
+// nolint:lll
+type DiffCustomLogRevisionsInput struct {
+	LogType      string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	FromRevision int64  `json:"fromRevision" validate:"required,min=1" description:"The revision to compare from"`
+	ToRevision   int64  `json:"toRevision" validate:"required,min=1" description:"The revision to compare to"`
+}
+
+//nolint:lll
+type DiffCustomLogRevisionsOutput struct {
+	Changes []SchemaChange `json:"changes" description:"The changes between the two revisions (field omitted if an error occurred)"`
+	Error   *APIError      `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+// SchemaChange is a logschema.Change in a form suitable for API replies
+type SchemaChange struct {
+	Type string `json:"type" description:"The type of the change"`
+	Path string `json:"path" description:"The path of the changed value in the schema"`
+	From string `json:"from,omitempty" description:"The value before the change in YAML format"`
+	To   string `json:"to,omitempty" description:"The value after the change in YAML format"`
+}
+
+func newSchemaChange(c *logschema.Change) SchemaChange {
+	path := c.Path
+	// Field additions and deletions have the path of the parent object
+	switch c.Type {
+	case logschema.AddField:
+		if field, ok := c.To.(*logschema.FieldSchema); ok {
+			path = append(path[:len(path):len(path)], field.Name)
+		}
+	case logschema.DeleteField:
+		if field, ok := c.From.(*logschema.FieldSchema); ok {
+			path = append(path[:len(path):len(path)], field.Name)
+		}
+	}
+	return SchemaChange{
+		Type: c.Type,
+		Path: strings.Join(path, "."),
+		From: marshalChangeValue(c.From),
+		To:   marshalChangeValue(c.To),
+	}
+}
+
+func marshalChangeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// RevertCustomLog restores the schema of a previous revision as a new revision of a custom log record.
+//
+// Since a schema update can only add fields, reverting to a previous revision can only remove fields from the
+// data catalog. The JSON data already stored for the removed fields is not affected and becomes available again
+// if the fields are restored.
+func (api *LogTypesAPI) RevertCustomLog(ctx context.Context, input *RevertCustomLogInput) (*RevertCustomLogOutput, error) {
+	id := customlogs.LogType(input.LogType)
+	current, err := api.getCustomLogRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revision != input.Revision {
+		return nil, NewAPIError(ErrRevisionConflict, fmt.Sprintf("record %q is not on revision %d", id, input.Revision))
+	}
+	if input.ToRevision >= current.Revision {
+		return nil, NewAPIError(ErrInvalidUpdate, fmt.Sprintf("cannot revert record %q to revision %d", id, input.ToRevision))
+	}
+	target, err := api.getRevision(ctx, current, input.ToRevision)
+	if err != nil {
+		return nil, err
+	}
+	currentSchema, err := buildRecordSchema(current)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := buildRecordSchema(target)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.checkCustomSchema(ctx, id, schema); err != nil {
+		return nil, err
+	}
+	if err := checkRevert(currentSchema, schema); err != nil {
+		return nil, NewAPIError(ErrInvalidUpdate, fmt.Sprintf("cannot revert to revision %d: %s", input.ToRevision, err))
+	}
+
+	result, err := api.Database.PutSchema(ctx, id, &SchemaRecord{
+		Name:         id,
+		Revision:     current.Revision,
+		UpdatedAt:    time.Now(),
+		CreatedAt:    current.CreatedAt,
+		Managed:      false,
+		Disabled:     current.Disabled,
+		Description:  target.Description,
+		ReferenceURL: target.ReferenceURL,
+		Spec:         target.Spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := api.UpdateDataCatalog(ctx, input.LogType, currentSchema.Fields, schema.Fields); err != nil {
+		// The error will be shown to the user as a "ServerError"
+		return nil, errors.Wrapf(err, "could not queue event for %q database update", input.LogType)
+	}
+	return &RevertCustomLogOutput{
+		Result: result,
+	}, nil
+}
+
+// nolint:lll
+type RevertCustomLogInput struct {
+	LogType    string `json:"logType" validate:"required,startswith=Custom." description:"The log type id"`
+	Revision   int64  `json:"revision" validate:"required,min=1" description:"Custom log record revision to update"`
+	ToRevision int64  `json:"toRevision" validate:"required,min=1" description:"The revision to restore"`
+}
+
+//nolint:lll
+type RevertCustomLogOutput struct {
+	Result *SchemaRecord `json:"record,omitempty" description:"The modified record (field is omitted if an error occurred)"`
+	Error  *APIError     `json:"error,omitempty" description:"An error that occurred during the operation"`
+}
+
+// checkRevert allows reverts to drop fields but not to change the type of existing fields.
+func checkRevert(from, to *logschema.Schema) error {
+	diff, err := logschema.Diff(from, to)
+	if err != nil {
+		return err
+	}
+	for i := range diff {
+		c := &diff[i]
+		if c.Type == logschema.DeleteField {
+			continue
+		}
+		if e := customlogs.CheckSchemaChange(c); e != nil {
+			err = multierr.Append(err, e)
+		}
+	}
+	return err
+}
+
+// checkRevisionHistory checks that a schema does not change the type of a field in any of the previous revisions.
+// Fields removed by a revert can still be present in stored data and should be added back with the same type.
+func (api *LogTypesAPI) checkRevisionHistory(ctx context.Context, current *SchemaRecord, schema *logschema.Schema) error {
+	revisions, err := api.scanRevisions(ctx, current)
+	if err != nil {
+		return err
+	}
+	for _, r := range revisions {
+		if r.Revision == current.Revision {
+			continue
+		}
+		prev, err := buildSchema(r.Spec)
+		if err != nil {
+			// Skip revisions that cannot be parsed any more
+			continue
+		}
+		if err := checkRevert(prev, schema); err != nil {
+			return NewAPIError(ErrInvalidUpdate, fmt.Sprintf("schema update conflicts with revision %d: %s", r.Revision, err))
+		}
+	}
+	return nil
+}
+
+func (api *LogTypesAPI) getCustomLogRecord(ctx context.Context, id string) (*SchemaRecord, error) {
+	r, err := api.Database.GetSchema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || !r.IsCustom() || r.Disabled {
+		return nil, NewAPIError(ErrNotFound, fmt.Sprintf("custom log record %q not found", id))
+	}
+	return r, nil
+}
+
+// getRevision gets a revision of a record.
+// Records stored before revision history was kept have no history records so we fall back to the current record.
+func (api *LogTypesAPI) getRevision(ctx context.Context, current *SchemaRecord, revision int64) (*SchemaRecord, error) {
+	if revision == current.Revision {
+		return current, nil
+	}
+	r, err := api.Database.GetSchemaRevision(ctx, current.Name, revision)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, NewAPIError(ErrNotFound, fmt.Sprintf("revision %d of record %q not found", revision, current.Name))
+	}
+	return r, nil
+}
+
+// scanRevisions collects all revisions of a record sorted by revision.
+// The current record is always included even if it has no history record.
+func (api *LogTypesAPI) scanRevisions(ctx context.Context, current *SchemaRecord) ([]*SchemaRecord, error) {
+	var revisions []*SchemaRecord
+	scan := func(r *SchemaRecord) bool {
+		if r.Revision != current.Revision {
+			revisions = append(revisions, r)
+		}
+		return true
+	}
+	if err := api.Database.ScanSchemaRevisions(ctx, current.Name, scan); err != nil {
+		return nil, err
+	}
+	revisions = append(revisions, current)
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+func buildRecordSchema(r *SchemaRecord) (*logschema.Schema, error) {
+	schema, err := buildSchema(r.Spec)
+	if err != nil {
+		return nil, err
+	}
+	schema.Schema = r.Name
+	if r.Description != "" {
+		schema.Description = r.Description
+	}
+	if r.ReferenceURL != "" {
+		schema.ReferenceURL = r.ReferenceURL
+	}
+	return schema, nil
+}
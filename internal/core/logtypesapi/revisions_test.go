@@ -0,0 +1,137 @@
+package logtypesapi_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+)
+
+func TestAPI_RevertCustomLog(t *testing.T) {
+	type catalogUpdate struct {
+		From, To []logschema.FieldSchema
+	}
+	var updates []catalogUpdate
+	api := logtypesapi.LogTypesAPI{
+		Database: logtypesapi.NewInMemory(),
+		UpdateDataCatalog: func(ctx context.Context, logType string, from, to []logschema.FieldSchema) error {
+			updates = append(updates, catalogUpdate{From: from, To: to})
+			return nil
+		},
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+
+	const (
+		specV1 = `{"version": 0, "fields": [{"name": "foo", "type": "string"}]}`
+		specV2 = `{"version": 0, "fields": [{"name": "foo", "type": "string"}, {"name": "bar", "type": "string"}]}`
+		specV4 = `{"version": 0, "fields": [{"name": "foo", "type": "string"}, {"name": "bar", "type": "bigint"}]}`
+	)
+	_, err := api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType: "Custom.Event",
+		Spec:    specV1,
+	})
+	assert.NoError(err)
+	_, err = api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType:  "Custom.Event",
+		Revision: 1,
+		Spec:     specV2,
+	})
+	assert.NoError(err)
+
+	{
+		reply, err := api.ListCustomLogRevisions(ctx, &logtypesapi.ListCustomLogRevisionsInput{
+			LogType: "Custom.Event",
+		})
+		assert.NoError(err)
+		assert.Len(reply.Records, 2)
+		assert.Equal(int64(1), reply.Records[0].Revision)
+		assert.Equal(specV1, reply.Records[0].Spec)
+		assert.Equal(int64(2), reply.Records[1].Revision)
+		assert.Equal(specV2, reply.Records[1].Spec)
+	}
+	{
+		reply, err := api.GetCustomLogRevision(ctx, &logtypesapi.GetCustomLogRevisionInput{
+			LogType:  "Custom.Event",
+			Revision: 1,
+		})
+		assert.NoError(err)
+		assert.Equal(specV1, reply.Result.Spec)
+		_, err = api.GetCustomLogRevision(ctx, &logtypesapi.GetCustomLogRevisionInput{
+			LogType:  "Custom.Event",
+			Revision: 42,
+		})
+		assert.Equal(logtypesapi.ErrNotFound, logtypesapi.AsAPIError(err).Code)
+	}
+	{
+		reply, err := api.DiffCustomLogRevisions(ctx, &logtypesapi.DiffCustomLogRevisionsInput{
+			LogType:      "Custom.Event",
+			FromRevision: 2,
+			ToRevision:   1,
+		})
+		assert.NoError(err)
+		assert.Len(reply.Changes, 1)
+		assert.Equal(logschema.DeleteField, reply.Changes[0].Type)
+		assert.Equal("Fields.bar", reply.Changes[0].Path)
+		assert.Contains(reply.Changes[0].From, "name: bar")
+	}
+
+	// Revision conflict
+	_, err = api.RevertCustomLog(ctx, &logtypesapi.RevertCustomLogInput{
+		LogType:    "Custom.Event",
+		Revision:   1,
+		ToRevision: 1,
+	})
+	assert.Equal(logtypesapi.ErrRevisionConflict, logtypesapi.AsAPIError(err).Code)
+
+	updates = nil
+	reply, err := api.RevertCustomLog(ctx, &logtypesapi.RevertCustomLogInput{
+		LogType:    "Custom.Event",
+		Revision:   2,
+		ToRevision: 1,
+	})
+	assert.NoError(err)
+	assert.Equal(int64(3), reply.Result.Revision)
+	assert.Equal(specV1, reply.Result.Spec)
+	assert.Len(updates, 1)
+	assert.Len(updates[0].From, 2)
+	assert.Len(updates[0].To, 1)
+
+	// Adding back a reverted field with a different type is not allowed
+	_, err = api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType:  "Custom.Event",
+		Revision: 3,
+		Spec:     specV4,
+	})
+	assert.Error(err)
+	assert.Equal(logtypesapi.ErrInvalidUpdate, logtypesapi.AsAPIError(err).Code)
+
+	// Adding back a reverted field with the same type is allowed
+	_, err = api.PutCustomLog(ctx, &logtypesapi.PutCustomLogInput{
+		LogType:  "Custom.Event",
+		Revision: 3,
+		Spec:     specV2,
+	})
+	assert.NoError(err)
+}
@@ -0,0 +1,127 @@
+package logtypesapi
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+// S3Source is an S3 location with log data used for samples
+type S3Source struct {
+	S3       s3iface.S3API
+	Bucket   string
+	Prefixes []string
+}
+
+// listObjects lists up to max non-empty objects under the source prefixes.
+// If since or until are not zero, only objects modified within the time range are listed.
+func (src *S3Source) listObjects(ctx context.Context, since, until time.Time, max int) ([]string, error) {
+	var keys []string
+	prefixes := src.Prefixes
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	for _, prefix := range prefixes {
+		input := s3.ListObjectsV2Input{
+			Bucket: aws.String(src.Bucket),
+			Prefix: aws.String(prefix),
+		}
+		err := src.S3.ListObjectsV2PagesWithContext(ctx, &input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+			for _, obj := range page.Contents {
+				key := aws.StringValue(obj.Key)
+				// Skip 'folders' and empty objects
+				if aws.Int64Value(obj.Size) == 0 || strings.HasSuffix(key, "/") {
+					continue
+				}
+				if tm := aws.TimeValue(obj.LastModified); (!since.IsZero() && tm.Before(since)) || (!until.IsZero() && tm.After(until)) {
+					continue
+				}
+				keys = append(keys, key)
+				if len(keys) >= max {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list objects in s3://%s/%s", src.Bucket, prefix)
+		}
+		if len(keys) >= max {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return nil, NewAPIError(ErrNotFound, fmt.Sprintf("no objects found in s3://%s/%s", src.Bucket, strings.Join(prefixes, ",")))
+	}
+	return keys, nil
+}
+
+// readObject reads the log entries of an object using the same log stream readers as the log processor.
+func (src *S3Source) readObject(ctx context.Context, key string, config *logstream.JSONStreamConfig,
+	read func(logstream.Stream) error) error {
+
+	obj, err := src.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(src.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get s3://%s/%s", src.Bucket, key)
+	}
+	defer obj.Body.Close()
+	stream, err := newSampleStream(obj.Body, config)
+	if err == nil {
+		err = read(stream)
+	}
+	if err != nil {
+		return NewAPIError(ErrInvalidSyntax, fmt.Sprintf("failed to read s3://%s/%s: %s", src.Bucket, key, err))
+	}
+	return nil
+}
+
+// newSampleStream creates a log entry stream for sample data.
+// Gzip compressed data are transparently uncompressed.
+func newSampleStream(r io.Reader, config *logstream.JSONStreamConfig) (logstream.Stream, error) {
+	buf := bufio.NewReader(r)
+	if header, _ := buf.Peek(2); len(header) == 2 && header[0] == 0x1f && header[1] == 0x8b {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	} else {
+		r = buf
+	}
+	if config != nil {
+		return logstream.NewJSONStreamWithConfig(r, logstream.DefaultBufferSize, config)
+	}
+	return logstream.NewLineStream(r, logstream.DefaultBufferSize), nil
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
 )
 
 // GetSchemaInput specifies the schema id and revision to retrieve.
@@ -33,9 +34,11 @@ type GetSchemaInput struct {
 	Name string `json:"name" validate:"required" description:"The schema id"`
 }
 
+//nolint:lll
 type GetSchemaOutput struct {
-	Record *SchemaRecord `json:"record,omitempty" description:"The schema record (field omitted if an error occurred)"`
-	Error  *APIError     `json:"error,omitempty" description:"An error that occurred while fetching the record"`
+	Record     *SchemaRecord               `json:"record,omitempty" description:"The schema record (field omitted if an error occurred)"`
+	Indicators []logschema.IndicatorSchema `json:"indicators,omitempty" description:"The global indicators available to a user-defined schema"`
+	Error      *APIError                   `json:"error,omitempty" description:"An error that occurred while fetching the record"`
 }
 
 // GetSchema gets a schema record
@@ -47,8 +50,18 @@ func (api *LogTypesAPI) GetSchema(ctx context.Context, input *GetSchemaInput) (*
 	if record == nil {
 		return nil, NewAPIError(ErrNotFound, fmt.Sprintf("schema record %s not found", input.Name))
 	}
+	if !record.IsCustom() {
+		return &GetSchemaOutput{
+			Record: record,
+		}, nil
+	}
+	indicators, err := api.getIndicators(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return &GetSchemaOutput{
-		Record: record,
+		Record:     record,
+		Indicators: indicators.Indicators,
 	}, nil
 }
 
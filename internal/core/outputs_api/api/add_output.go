@@ -62,6 +62,8 @@ func (API) AddOutput(input *models.AddOutputInput) (*models.AddOutputOutput, err
 		OutputConfig:       input.OutputConfig,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
+		RoutingRules:       input.RoutingRules,
 	}
 
 	alertOutputItem, err := AlertOutputToItem(alertOutput)
@@ -72,6 +72,8 @@ func (API) UpdateOutput(input *models.UpdateOutputInput) (*models.UpdateOutputOu
 		OutputConfig:       newConfig,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
+		RoutingRules:       input.RoutingRules,
 	}
 
 	alertOutputItem, err := AlertOutputToItem(alertOutput)
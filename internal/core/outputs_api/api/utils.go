@@ -20,6 +20,7 @@ package api
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go/aws"
 	jsoniter "github.com/json-iterator/go"
@@ -45,6 +46,8 @@ func AlertOutputToItem(input *models.AlertOutput) (*table.AlertOutputItem, error
 		OutputType:         input.OutputType,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
+		RoutingRules:       input.RoutingRules,
 	}
 
 	if input.OutputConfig != nil {
@@ -70,6 +73,8 @@ func ItemToAlertOutput(input *table.AlertOutputItem) (alertOutput *models.AlertO
 		OutputType:         input.OutputType,
 		DefaultForSeverity: input.DefaultForSeverity,
 		AlertTypes:         input.AlertTypes,
+		DeliveryPolicy:     input.DeliveryPolicy,
+		RoutingRules:       input.RoutingRules,
 	}
 
 	// Decrypt the output before returning to the caller
@@ -106,6 +111,24 @@ func redactOutput(outputConfig *models.OutputConfig) {
 	}
 	if outputConfig.CustomWebhook != nil {
 		outputConfig.CustomWebhook.WebhookURL = redacted
+		outputConfig.CustomWebhook.SignatureSecret = redacted
+		for i := range outputConfig.CustomWebhook.Headers {
+			if outputConfig.CustomWebhook.Headers[i].Secret {
+				outputConfig.CustomWebhook.Headers[i].Value = redacted
+			}
+		}
+	}
+	if outputConfig.Email != nil && outputConfig.Email.SMTPPassword != "" {
+		outputConfig.Email.SMTPPassword = redacted
+	}
+	if outputConfig.GoogleChat != nil {
+		outputConfig.GoogleChat.WebhookURL = redacted
+	}
+	if outputConfig.Mattermost != nil {
+		outputConfig.Mattermost.WebhookURL = redacted
+	}
+	if outputConfig.Webex != nil {
+		outputConfig.Webex.BotToken = redacted
 	}
 }
 
@@ -156,6 +179,18 @@ func getOutputType(outputConfig *models.OutputConfig) (*string, error) {
 	if outputConfig.CustomWebhook != nil {
 		return aws.String("customwebhook"), nil
 	}
+	if outputConfig.Email != nil {
+		return aws.String("email"), nil
+	}
+	if outputConfig.GoogleChat != nil {
+		return aws.String("googlechat"), nil
+	}
+	if outputConfig.Mattermost != nil {
+		return aws.String("mattermost"), nil
+	}
+	if outputConfig.Webex != nil {
+		return aws.String("webex"), nil
+	}
 
 	return nil, errors.New("no valid output configuration specified for alert output")
 }
@@ -222,9 +257,30 @@ func mergeConfigs(oldConfig, newConfig *models.OutputConfig) (*models.OutputConf
 		}
 	}
 
+	// Headers are replaced as a list, keep the values of redacted secret headers
+	if oldConfig.CustomWebhook != nil && combinedConfig.CustomWebhook != nil {
+		mergeSecretHeaders(oldConfig.CustomWebhook.Headers, combinedConfig.CustomWebhook.Headers)
+	}
+
 	return combinedConfig, nil
 }
 
+// mergeSecretHeaders sets the value of secret headers left empty to the value of the old header with the same name
+func mergeSecretHeaders(oldHeaders, newHeaders []models.CustomWebhookHeader) {
+	for i := range newHeaders {
+		header := &newHeaders[i]
+		if !header.Secret || header.Value != "" {
+			continue
+		}
+		for _, oldHeader := range oldHeaders {
+			if oldHeader.Secret && http.CanonicalHeaderKey(oldHeader.Name) == http.CanonicalHeaderKey(header.Name) {
+				header.Value = oldHeader.Value
+				break
+			}
+		}
+	}
+}
+
 func validateConfigByType(config *models.OutputConfig, outputType *string) error {
 	switch *outputType {
 	case "slack":
@@ -265,10 +321,47 @@ func validateConfigByType(config *models.OutputConfig, outputType *string) error
 			return nil
 		}
 	case "customwebhook":
-		if config.CustomWebhook.WebhookURL != "" {
+		if config.CustomWebhook.WebhookURL != "" && validateCustomWebhookSecrets(config.CustomWebhook) {
+			return nil
+		}
+	case "email":
+		if config.Email.From != "" && len(config.Email.To) != 0 && validateEmailTransport(config.Email) {
+			return nil
+		}
+	case "googlechat":
+		if config.GoogleChat.WebhookURL != "" {
+			return nil
+		}
+	case "mattermost":
+		if config.Mattermost.WebhookURL != "" {
+			return nil
+		}
+	case "webex":
+		if config.Webex.BotToken != "" && config.Webex.RoomID != "" {
 			return nil
 		}
 	}
 
 	return errors.New("invalid output configuration specified for alert output, missing required fields")
 }
+
+// validateCustomWebhookSecrets checks that the signature and all secret headers have values
+func validateCustomWebhookSecrets(config *models.CustomWebhookConfig) bool {
+	if config.SignatureHeader != "" && config.SignatureSecret == "" {
+		return false
+	}
+	for _, header := range config.Headers {
+		if header.Secret && header.Value == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validateEmailTransport checks that SMTP outputs have a server and a password for their user
+func validateEmailTransport(config *models.EmailConfig) bool {
+	if config.Transport != models.EmailTransportSMTP {
+		return true
+	}
+	return config.SMTPHost != "" && (config.SMTPUserName == "" || config.SMTPPassword != "")
+}
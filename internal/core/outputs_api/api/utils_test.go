@@ -0,0 +1,117 @@
+package api
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
+)
+
+func TestMergeConfigsCustomWebhookSecrets(t *testing.T) {
+	oldConfig := &models.OutputConfig{
+		CustomWebhook: &models.CustomWebhookConfig{
+			WebhookURL: "https://example.com/webhook",
+			Headers: []models.CustomWebhookHeader{
+				{Name: "Authorization", Value: "Bearer token", Secret: true},
+				{Name: "X-Source", Value: "panther"},
+			},
+			SignatureHeader: "X-Signature",
+			SignatureSecret: "secret",
+		},
+	}
+	// A redacted config as returned by the API with a new header
+	newConfig := &models.OutputConfig{
+		CustomWebhook: &models.CustomWebhookConfig{
+			WebhookURL: "https://example.com/webhook",
+			Headers: []models.CustomWebhookHeader{
+				{Name: "authorization", Secret: true},
+				{Name: "X-Team", Value: "security"},
+			},
+			SignatureHeader: "X-Signature",
+		},
+	}
+	config, err := mergeConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, &models.CustomWebhookConfig{
+		WebhookURL: "https://example.com/webhook",
+		Headers: []models.CustomWebhookHeader{
+			{Name: "authorization", Value: "Bearer token", Secret: true},
+			{Name: "X-Team", Value: "security"},
+		},
+		SignatureHeader: "X-Signature",
+		SignatureSecret: "secret",
+	}, config.CustomWebhook)
+	assert.NoError(t, validateConfigByType(config, aws.String("customwebhook")))
+
+	redactOutput(config)
+	assert.Equal(t, "", config.CustomWebhook.SignatureSecret)
+	assert.Equal(t, "", config.CustomWebhook.Headers[0].Value)
+	assert.Equal(t, "security", config.CustomWebhook.Headers[1].Value)
+	assert.Error(t, validateConfigByType(config, aws.String("customwebhook")))
+}
+
+func TestMergeConfigsEmailSMTPPassword(t *testing.T) {
+	oldConfig := &models.OutputConfig{
+		Email: &models.EmailConfig{
+			From:         "alerts@example.com",
+			To:           []string{"security@example.com"},
+			Transport:    models.EmailTransportSMTP,
+			SMTPHost:     "smtp.example.com",
+			SMTPUserName: "alerts",
+			SMTPPassword: "password",
+		},
+	}
+	// A redacted config as returned by the API with new recipients and port
+	newConfig := &models.OutputConfig{
+		Email: &models.EmailConfig{
+			From:         "alerts@example.com",
+			To:           []string{"security@example.com", "oncall@example.com"},
+			Transport:    models.EmailTransportSMTP,
+			SMTPHost:     "smtp.example.com",
+			SMTPPort:     465,
+			SMTPTLS:      models.SMTPImplicitTLS,
+			SMTPUserName: "alerts",
+		},
+	}
+	config, err := mergeConfigs(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, &models.EmailConfig{
+		From:         "alerts@example.com",
+		To:           []string{"security@example.com", "oncall@example.com"},
+		Transport:    models.EmailTransportSMTP,
+		SMTPHost:     "smtp.example.com",
+		SMTPPort:     465,
+		SMTPTLS:      models.SMTPImplicitTLS,
+		SMTPUserName: "alerts",
+		SMTPPassword: "password",
+	}, config.Email)
+	assert.NoError(t, validateConfigByType(config, aws.String("email")))
+
+	redactOutput(config)
+	assert.Equal(t, "", config.Email.SMTPPassword)
+	assert.Error(t, validateConfigByType(config, aws.String("email")))
+	// SES outputs do not need credentials
+	config.Email.Transport = models.EmailTransportSES
+	assert.NoError(t, validateConfigByType(config, aws.String("email")))
+}
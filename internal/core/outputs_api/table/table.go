@@ -24,6 +24,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/panther-labs/panther/api/lambda/outputs/models"
 )
 
 // OutputsAPI defines the interface for the outputs table which can be used for mocking.
@@ -88,4 +90,10 @@ type AlertOutputItem struct {
 	// AlertTypes is a whitelist of alert types to send to this destination.
 	// To be backwards compatible, we cannot have a `min=1` and an empty list == all types.
 	AlertTypes []string `json:"alertTypes" dynamodbav:"alertTypes,stringset"`
+
+	// DeliveryPolicy throttles and groups the alerts dispatched to this output
+	DeliveryPolicy *models.DeliveryPolicy `json:"deliveryPolicy,omitempty"`
+
+	// RoutingRules route alerts to this output
+	RoutingRules []models.RoutingRule `json:"routingRules,omitempty"`
 }
@@ -47,6 +47,12 @@ func (table *OutputsTable) UpdateOutput(alertOutput *AlertOutputItem) (*AlertOut
 	if alertOutput.AlertTypes != nil {
 		updateExpression.Set(expression.Name("alertTypes"), expression.Value(alertOutput.AlertTypes))
 	}
+	if alertOutput.DeliveryPolicy != nil {
+		updateExpression.Set(expression.Name("deliveryPolicy"), expression.Value(alertOutput.DeliveryPolicy))
+	}
+	if alertOutput.RoutingRules != nil {
+		updateExpression.Set(expression.Name("routingRules"), expression.Value(alertOutput.RoutingRules))
+	}
 
 	conditionExpression := expression.Name("outputId").Equal(expression.Value(alertOutput.OutputID))
 	combinedExpression, err := expression.NewBuilder().
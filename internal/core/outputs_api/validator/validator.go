@@ -19,8 +19,15 @@ package validator
  */
 
 import (
+	"path"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"golang.org/x/net/http/httpguts"
 	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/internal/core/alert_delivery/outputs"
+	"github.com/panther-labs/panther/internal/core/alert_delivery/throttle"
 )
 
 // Validator builds a custom struct validator.
@@ -29,6 +36,24 @@ func Validator() (*validator.Validate, error) {
 	if err := result.RegisterValidation("snsArn", validateAwsArn); err != nil {
 		return nil, err
 	}
+	if err := result.RegisterValidation("httpHeader", validateHTTPHeader); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("httpHeaderValue", validateHTTPHeaderValue); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("webhookTemplate", validateWebhookTemplate); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("clock", validateClock); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("timezone", validateTimeZone); err != nil {
+		return nil, err
+	}
+	if err := result.RegisterValidation("glob", validateGlob); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -36,3 +61,30 @@ func validateAwsArn(fl validator.FieldLevel) bool {
 	fieldArn, err := arn.Parse(fl.Field().String())
 	return err == nil && fieldArn.Service == "sns"
 }
+
+func validateHTTPHeader(fl validator.FieldLevel) bool {
+	return httpguts.ValidHeaderFieldName(fl.Field().String())
+}
+
+func validateHTTPHeaderValue(fl validator.FieldLevel) bool {
+	return httpguts.ValidHeaderFieldValue(fl.Field().String())
+}
+
+func validateWebhookTemplate(fl validator.FieldLevel) bool {
+	return outputs.ValidateWebhookTemplate(fl.Field().String()) == nil
+}
+
+func validateClock(fl validator.FieldLevel) bool {
+	_, err := time.Parse(throttle.ClockLayout, fl.Field().String())
+	return err == nil
+}
+
+func validateTimeZone(fl validator.FieldLevel) bool {
+	_, err := time.LoadLocation(fl.Field().String())
+	return err == nil
+}
+
+func validateGlob(fl validator.FieldLevel) bool {
+	_, err := path.Match(fl.Field().String(), "")
+	return err == nil
+}
@@ -92,3 +92,168 @@ func TestAddNonSnsArn(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Sns", "TopicArn", "snsArn"), err.Error())
 }
+
+func TestAddCustomWebhookValid(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	assert.NoError(t, validator.Struct(&models.AddOutputInput{
+		UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+		DisplayName: aws.String("mywebhook"),
+		AlertTypes:  []string{deliverymodel.RuleType},
+		OutputConfig: &models.OutputConfig{
+			CustomWebhook: &models.CustomWebhookConfig{
+				WebhookURL:   "https://example.com/webhook",
+				Method:       "PUT",
+				BodyTemplate: `{"title": {{ json .Title }}}`,
+				Headers: []models.CustomWebhookHeader{
+					{Name: "Authorization", Value: "Bearer token", Secret: true},
+				},
+				SignatureHeader: "X-Signature",
+				SignatureSecret: "secret",
+			},
+		},
+	}))
+}
+
+func TestAddCustomWebhookInvalid(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	input := func(config models.CustomWebhookConfig) *models.AddOutputInput {
+		config.WebhookURL = "https://example.com/webhook"
+		return &models.AddOutputInput{
+			UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:  aws.String("mywebhook"),
+			AlertTypes:   []string{deliverymodel.RuleType},
+			OutputConfig: &models.OutputConfig{CustomWebhook: &config},
+		}
+	}
+
+	err = validator.Struct(input(models.CustomWebhookConfig{Method: "GET"}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook", "Method", "oneof"), err.Error())
+
+	err = validator.Struct(input(models.CustomWebhookConfig{BodyTemplate: `{"title": {{ .Title }}}`}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook", "BodyTemplate", "webhookTemplate"), err.Error())
+
+	err = validator.Struct(input(models.CustomWebhookConfig{SignatureHeader: "X Signature"}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook", "SignatureHeader", "httpHeader"), err.Error())
+
+	err = validator.Struct(input(models.CustomWebhookConfig{
+		Headers: []models.CustomWebhookHeader{{Name: "", Value: "foo"}},
+	}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook.Headers[0]", "Name", "httpHeader"), err.Error())
+
+	err = validator.Struct(input(models.CustomWebhookConfig{
+		Headers: []models.CustomWebhookHeader{{Name: "X-Foo", Value: "foo\r\nX-Bar: bar"}},
+	}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.CustomWebhook.Headers[0]", "Value", "httpHeaderValue"), err.Error())
+}
+
+func TestAddOutputEmailSMTPHost(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	input := func(host string) *models.AddOutputInput {
+		return &models.AddOutputInput{
+			UserID:      aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName: aws.String("myemail"),
+			AlertTypes:  []string{deliverymodel.RuleType},
+			OutputConfig: &models.OutputConfig{Email: &models.EmailConfig{
+				From:      "alerts@example.com",
+				To:        []string{"security@example.com"},
+				Transport: models.EmailTransportSMTP,
+				SMTPHost:  host,
+			}},
+		}
+	}
+
+	assert.NoError(t, validator.Struct(input("smtp.example.com")))
+	assert.NoError(t, validator.Struct(input("10.0.0.25")))
+	assert.NoError(t, validator.Struct(input("fd00::25")))
+	err = validator.Struct(input("smtp.example.com:587"))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.OutputConfig.Email", "SMTPHost", "hostname|ip"), err.Error())
+}
+
+func TestAddOutputDeliveryPolicy(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	input := func(policy models.DeliveryPolicy) *models.AddOutputInput {
+		return &models.AddOutputInput{
+			UserID:         aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:    aws.String("mychannel"),
+			AlertTypes:     []string{deliverymodel.RuleType},
+			OutputConfig:   &models.OutputConfig{Slack: &models.SlackConfig{WebhookURL: "https://hooks.slack.com"}},
+			DeliveryPolicy: &policy,
+		}
+	}
+
+	assert.NoError(t, validator.Struct(input(models.DeliveryPolicy{
+		RateLimit:           &models.RateLimit{AlertsPerMinute: 0.5, Burst: 10},
+		DigestWindowMinutes: 10,
+		QuietHours: &models.QuietHours{
+			Start:      "22:00",
+			End:        "07:30",
+			TimeZone:   "America/New_York",
+			Severities: []string{"CRITICAL"},
+		},
+	})))
+
+	err = validator.Struct(input(models.DeliveryPolicy{QuietHours: &models.QuietHours{Start: "10pm", End: "07:30"}}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.DeliveryPolicy.QuietHours", "Start", "clock"), err.Error())
+
+	err = validator.Struct(input(models.DeliveryPolicy{
+		QuietHours: &models.QuietHours{Start: "22:00", End: "07:30", TimeZone: "Mars/Olympus"},
+	}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.DeliveryPolicy.QuietHours", "TimeZone", "timezone"), err.Error())
+
+	err = validator.Struct(input(models.DeliveryPolicy{DigestWindowMinutes: 120}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.DeliveryPolicy", "DigestWindowMinutes", "max"), err.Error())
+
+	err = validator.Struct(input(models.DeliveryPolicy{RateLimit: &models.RateLimit{Burst: 1}}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.DeliveryPolicy.RateLimit", "AlertsPerMinute", "gt"), err.Error())
+}
+
+func TestAddOutputRoutingRules(t *testing.T) {
+	validator, err := Validator()
+	require.NoError(t, err)
+	input := func(rules ...models.RoutingRule) *models.AddOutputInput {
+		return &models.AddOutputInput{
+			UserID:       aws.String("3601990c-b566-404b-b367-3c6eacd6fe60"),
+			DisplayName:  aws.String("mychannel"),
+			AlertTypes:   []string{deliverymodel.RuleType},
+			OutputConfig: &models.OutputConfig{Slack: &models.SlackConfig{WebhookURL: "https://hooks.slack.com"}},
+			RoutingRules: rules,
+		}
+	}
+
+	assert.NoError(t, validator.Struct(input(models.RoutingRule{
+		Priority:       1,
+		LogTypes:       []string{"AWS.CloudTrail"},
+		Tags:           []string{"IAM"},
+		AnalysisIDs:    []string{"AWS.CloudTrail.*"},
+		ResourceTypes:  []string{"AWS.IAM.Role"},
+		AWSAccountIDs:  []string{"123456789012"},
+		Context:        []models.ContextMatch{{Key: "user.name", Values: []string{"root"}}},
+		StopProcessing: true,
+	})))
+
+	err = validator.Struct(input(models.RoutingRule{AnalysisIDs: []string{"AWS.[CloudTrail"}}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.RoutingRules[0]", "AnalysisIDs[0]", "glob"), err.Error())
+
+	err = validator.Struct(input(models.RoutingRule{AWSAccountIDs: []string{"12345"}}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.RoutingRules[0]", "AWSAccountIDs[0]", "len"), err.Error())
+
+	err = validator.Struct(input(models.RoutingRule{Context: []models.ContextMatch{{Key: "user.name"}}}))
+	require.Error(t, err)
+	assert.Equal(t, expectedMsg("AddOutputInput.RoutingRules[0].Context[0]", "Values", "min"), err.Error())
+}
@@ -63,6 +63,8 @@ func (api *API) CheckIntegration(input *models.CheckIntegrationInput) (*models.S
 		return api.checkAwsS3Integration(input), nil
 	case models.IntegrationTypeSqs:
 		return api.checkSqsQueueHealth(input), nil
+	case models.IntegrationTypeHTTPPush:
+		return api.checkHTTPPushHealth(input), nil
 	default:
 		return nil, checkIntegrationInternalError
 	}
@@ -394,6 +396,8 @@ func (api *API) evaluateIntegration(integration *models.CheckIntegrationInput) (
 			return status.SqsStatus.Message, false, nil
 		}
 		return status.SqsStatus.Message, true, nil
+	case models.IntegrationTypeHTTPPush:
+		return status.HTTPPushStatus.Message, status.HTTPPushStatus.Healthy, nil
 
 	default:
 		return "", false, errors.New("invalid integration type")
@@ -431,3 +435,22 @@ func (api *API) checkSqsQueueHealth(input *models.CheckIntegrationInput) *models
 	health.SqsStatus.Message = "We were able to call sqs:GetQueueAttributes on the specified SQS queue."
 	return health
 }
+
+// Check the health of the HTTP push source
+func (api *API) checkHTTPPushHealth(input *models.CheckIntegrationInput) *models.SourceIntegrationHealth {
+	health := &models.SourceIntegrationHealth{
+		IntegrationType: input.IntegrationType,
+	}
+
+	// There are no external resources to check for HTTP push sources.
+	// Senders push data directly to Panther, so we only need a valid configuration.
+	if input.HTTPPushConfig == nil || len(input.HTTPPushConfig.LogTypes) == 0 {
+		health.HTTPPushStatus.Healthy = false
+		health.HTTPPushStatus.Message = "The HTTP push source must have at least one log type."
+		return health
+	}
+
+	health.HTTPPushStatus.Healthy = true
+	health.HTTPPushStatus.Message = "The HTTP push source is configured properly."
+	return health
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/dchest/uniuri"
 	"github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
@@ -40,6 +41,11 @@ import (
 	"github.com/panther-labs/panther/pkg/stringset"
 )
 
+const (
+	// The length of the auth tokens generated for HTTP push sources
+	httpPushAuthTokenLength = 40
+)
+
 var (
 	putIntegrationInternalError = &genericapi.InternalError{Message: "Failed to add source. Please try again later"}
 )
@@ -131,6 +137,10 @@ func (api *API) setupExternalResources(integration *models.SourceIntegration) er
 }
 
 func (api *API) validateIntegration(input *models.PutIntegrationInput) error {
+	if err := validateEventFilters(input.EventFilters); err != nil {
+		return err
+	}
+
 	// Prefixes in the same S3 source should be unique (although we allow overlapping for now)
 	if input.IntegrationType == models.IntegrationTypeAWS3 {
 		prefixes := input.S3PrefixLogTypes.S3Prefixes()
@@ -139,6 +149,17 @@ func (api *API) validateIntegration(input *models.PutIntegrationInput) error {
 				Message: "Cannot have duplicate prefixes in an s3 source.",
 			}
 		}
+		if err := input.S3PrefixLogTypes.Validate(); err != nil {
+			return &genericapi.InvalidInputError{
+				Message: err.Error(),
+			}
+		}
+	}
+
+	if input.IntegrationType == models.IntegrationTypeHTTPPush && input.HTTPPushConfig == nil {
+		return &genericapi.InvalidInputError{
+			Message: "Missing configuration for HTTP push source.",
+		}
 	}
 
 	// Validate the new integration (healthcheck).
@@ -157,6 +178,7 @@ func (api *API) validateIntegration(input *models.PutIntegrationInput) error {
 		S3PrefixLogTypes:  input.S3PrefixLogTypes,
 		KmsKey:            input.KmsKey,
 		SqsConfig:         input.SqsConfig,
+		HTTPPushConfig:    input.HTTPPushConfig,
 	})
 	if err != nil {
 		return putIntegrationInternalError
@@ -216,9 +238,9 @@ func (api API) integrationAlreadyExists(input *models.PutIntegrationInput) error
 						}
 					}
 				}
-			case models.IntegrationTypeSqs:
+			case models.IntegrationTypeSqs, models.IntegrationTypeHTTPPush:
 				if existingIntegration.IntegrationLabel == input.IntegrationLabel {
-					// Sqs sources need to have different labels
+					// Sqs and HTTP push sources need to have different labels
 					return &genericapi.InvalidInputError{
 						Message: fmt.Sprintf("Integration with label %s already exists", input.IntegrationLabel),
 					}
@@ -290,6 +312,7 @@ func (api *API) generateNewIntegration(input *models.PutIntegrationInput) *model
 		IntegrationLabel: input.IntegrationLabel,
 		IntegrationType:  input.IntegrationType,
 		PantherVersion:   api.Config.Version,
+		EventFilters:     input.EventFilters,
 	}
 
 	switch input.IntegrationType {
@@ -323,6 +346,11 @@ func (api *API) generateNewIntegration(input *models.PutIntegrationInput) *model
 			LogTypes:             input.SqsConfig.LogTypes,
 			QueueURL:             api.SourceSqsQueueURL(metadata.IntegrationID),
 		}
+	case models.IntegrationTypeHTTPPush:
+		metadata.HTTPPushConfig = &models.HTTPPushConfig{
+			LogTypes:  input.HTTPPushConfig.LogTypes,
+			AuthToken: uniuri.NewLen(httpPushAuthTokenLength),
+		}
 	}
 	return &models.SourceIntegration{
 		SourceIntegrationMetadata: metadata,
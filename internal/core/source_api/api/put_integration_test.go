@@ -37,6 +37,8 @@ import (
 	awspoller "github.com/panther-labs/panther/internal/compliance/snapshot_poller/pollers/aws"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb/modelstest"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 func generateMockSQSBatchInputOutput(integration models.SourceIntegrationMetadata) (
@@ -391,3 +393,68 @@ func TestPutSqsIntegration(t *testing.T) {
 	assert.JSONEq(t, expectedSqsQueuePolicy, *createQueueRequest.Attributes["Policy"])
 	apiTest.AssertExpectations(t)
 }
+
+func TestPutHTTPPushIntegration(t *testing.T) {
+	t.Parallel()
+	apiTest := NewAPITest()
+	apiTest.DdbClient = &ddb.DDB{Client: &modelstest.MockDDBClient{TestErr: false}, TableName: "test"}
+	apiTest.EvaluateIntegrationFunc = apiTest.evaluateIntegration
+	apiTest.mockSqs.On("SendMessageWithContext", mock.Anything, mock.Anything).Return(&sqs.SendMessageOutput{}, nil)
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		PutIntegrationSettings: models.PutIntegrationSettings{
+			IntegrationLabel: testIntegrationLabel,
+			IntegrationType:  models.IntegrationTypeHTTPPush,
+			HTTPPushConfig: &models.HTTPPushConfig{
+				LogTypes: []string{"Syslog.RFC5424"},
+			},
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, out)
+	bucket, prefixes := out.S3Info()
+	assert.Empty(t, bucket)
+	assert.Empty(t, prefixes)
+	assert.Empty(t, out.RequiredLogProcessingRole())
+	assert.Equal(t, []string{"Syslog.RFC5424"}, out.RequiredLogTypes())
+	// A token is generated for each new source
+	assert.Len(t, out.HTTPPushConfig.AuthToken, httpPushAuthTokenLength)
+	assert.True(t, out.HTTPPushConfig.Authorize(out.HTTPPushConfig.AuthToken))
+	apiTest.AssertExpectations(t)
+}
+
+func TestPutHTTPPushIntegrationMissingConfig(t *testing.T) {
+	t.Parallel()
+	apiTest := NewAPITest()
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		PutIntegrationSettings: models.PutIntegrationSettings{
+			IntegrationLabel: testIntegrationLabel,
+			IntegrationType:  models.IntegrationTypeHTTPPush,
+		},
+	})
+	require.Error(t, err)
+	require.Nil(t, out)
+}
+
+func TestPutIntegrationInvalidEventFilters(t *testing.T) {
+	t.Parallel()
+	apiTest := NewAPITest()
+
+	out, err := apiTest.PutIntegration(&models.PutIntegrationInput{
+		PutIntegrationSettings: models.PutIntegrationSettings{
+			IntegrationLabel: testIntegrationLabel,
+			IntegrationType:  models.IntegrationTypeHTTPPush,
+			HTTPPushConfig: &models.HTTPPushConfig{
+				LogTypes: []string{"Syslog.RFC5424"},
+			},
+			EventFilters: []eventfilter.Rule{
+				{Action: eventfilter.ActionSample, SampleRate: 0.5},
+			},
+		},
+	})
+	require.Error(t, err)
+	require.IsType(t, &genericapi.InvalidInputError{}, err)
+	require.Nil(t, out)
+}
@@ -51,6 +51,10 @@ func (api *API) UpdateIntegrationSettings(input *models.UpdateIntegrationSetting
 		return nil, err
 	}
 
+	if err := validateEventFilters(input.EventFilters); err != nil {
+		return nil, err
+	}
+
 	// Validate the updates
 	// Validate the new integration (healthcheck).
 	if existingItem.IntegrationType != models.IntegrationTypeAWS3 {
@@ -108,6 +112,7 @@ func (api *API) checkSource(existingItem *ddb.Integration, input *models.UpdateI
 		S3PrefixLogTypes:  input.S3PrefixLogTypes,
 		KmsKey:            input.KmsKey,
 		SqsConfig:         input.SqsConfig,
+		HTTPPushConfig:    input.HTTPPushConfig,
 	})
 	if err != nil {
 		return err
@@ -134,6 +139,11 @@ func (api *API) validateUniqueConstraints(existingIntegrationItem *ddb.Integrati
 				Message: "Cannot have duplicate prefixes in an s3 source.",
 			}
 		}
+		if err := input.S3PrefixLogTypes.Validate(); err != nil {
+			return &genericapi.InvalidInputError{
+				Message: err.Error(),
+			}
+		}
 	}
 
 	existingIntegrations, err := api.ListIntegrations(&models.ListIntegrationsInput{})
@@ -168,9 +178,9 @@ func (api *API) validateUniqueConstraints(existingIntegrationItem *ddb.Integrati
 						}
 					}
 				}
-			case models.IntegrationTypeSqs:
+			case models.IntegrationTypeSqs, models.IntegrationTypeHTTPPush:
 				if existingIntegration.IntegrationLabel == input.IntegrationLabel {
-					// Sqs sources need to have different labels
+					// Sqs and HTTP push sources need to have different labels
 					return &genericapi.InvalidInputError{
 						Message: fmt.Sprintf("Integration with label %s already exists", input.IntegrationLabel),
 					}
@@ -182,6 +192,7 @@ func (api *API) validateUniqueConstraints(existingIntegrationItem *ddb.Integrati
 }
 
 func updateIntegrationDBItem(item *ddb.Integration, input *models.UpdateIntegrationSettingsInput) {
+	item.EventFilters = input.EventFilters
 	switch item.IntegrationType {
 	case models.IntegrationTypeAWSScan:
 		item.IntegrationLabel = input.IntegrationLabel
@@ -209,6 +220,10 @@ func updateIntegrationDBItem(item *ddb.Integration, input *models.UpdateIntegrat
 		item.SqsConfig.LogTypes = input.SqsConfig.LogTypes
 		item.SqsConfig.AllowedSourceArns = input.SqsConfig.AllowedSourceArns
 		item.SqsConfig.AllowedPrincipalArns = input.SqsConfig.AllowedPrincipalArns
+	case models.IntegrationTypeHTTPPush:
+		// The auth token is generated by Panther and is not updated
+		item.IntegrationLabel = input.IntegrationLabel
+		item.HTTPPushConfig.LogTypes = input.HTTPPushConfig.LogTypes
 	}
 }
 
@@ -267,6 +282,9 @@ func (api *API) updateTables(item *ddb.Integration, input *models.UpdateIntegrat
 	case models.IntegrationTypeSqs:
 		existingLogTypes = item.SqsConfig.LogTypes
 		newLogTypes = input.SqsConfig.LogTypes
+	case models.IntegrationTypeHTTPPush:
+		existingLogTypes = item.HTTPPushConfig.LogTypes
+		newLogTypes = input.HTTPPushConfig.LogTypes
 	}
 
 	// If the user hasn't added new log types to the integration
@@ -23,6 +23,8 @@ import (
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/core/source_api/ddb"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
+	"github.com/panther-labs/panther/pkg/genericapi"
 )
 
 func integrationToItem(input *models.SourceIntegration) *ddb.Integration {
@@ -36,6 +38,7 @@ func integrationToItem(input *models.SourceIntegration) *ddb.Integration {
 		PantherVersion:   input.PantherVersion,
 	}
 	item.LastEventReceived = input.LastEventReceived
+	item.EventFilters = input.EventFilters
 
 	switch input.IntegrationType {
 	case models.IntegrationTypeAWS3:
@@ -72,6 +75,11 @@ func integrationToItem(input *models.SourceIntegration) *ddb.Integration {
 			AllowedPrincipalArns: input.SqsConfig.AllowedPrincipalArns,
 			AllowedSourceArns:    input.SqsConfig.AllowedSourceArns,
 		}
+	case models.IntegrationTypeHTTPPush:
+		item.HTTPPushConfig = &ddb.HTTPPushConfig{
+			LogTypes:  input.HTTPPushConfig.LogTypes,
+			AuthToken: input.HTTPPushConfig.AuthToken,
+		}
 	}
 	return item
 }
@@ -94,3 +102,13 @@ func reduceNoPrefixStrings(strs []string) (reduced []string) {
 	}
 	return
 }
+
+// validateEventFilters checks that the event filter rules of a source can be compiled by the log processor
+func validateEventFilters(rules []eventfilter.Rule) error {
+	if _, err := eventfilter.Build(rules...); err != nil {
+		return &genericapi.InvalidInputError{
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
 )
 
 // Integration represents an integration item as it is stored in DynamoDB.
@@ -62,6 +63,10 @@ type Integration struct {
 
 	SqsConfig *SqsConfig `json:"sqsConfig,omitempty"`
 
+	HTTPPushConfig *HTTPPushConfig `json:"httpPushConfig,omitempty"`
+
+	EventFilters []eventfilter.Rule `json:"eventFilters,omitempty"`
+
 	// The Panther version in which this source was created.
 	PantherVersion string `json:"pantherVersion,omitempty"`
 }
@@ -80,3 +85,8 @@ type SqsConfig struct {
 	AllowedSourceArns    []string `json:"allowedSourceArns" dynamodbav:",stringset"`
 	QueueURL             string   `json:"queueUrl,omitempty"`
 }
+
+type HTTPPushConfig struct {
+	LogTypes  []string `json:"logTypes" dynamodbav:",stringset"`
+	AuthToken string   `json:"authToken,omitempty"`
+}
@@ -104,6 +104,7 @@ func ItemToIntegration(item *Integration) *models.SourceIntegration {
 	integration.CreatedBy = item.CreatedBy
 	integration.LastEventReceived = item.LastEventReceived
 	integration.PantherVersion = item.PantherVersion
+	integration.EventFilters = item.EventFilters
 	switch item.IntegrationType {
 	case models.IntegrationTypeAWS3:
 		integration.AWSAccountID = item.AWSAccountID
@@ -144,6 +145,11 @@ func ItemToIntegration(item *Integration) *models.SourceIntegration {
 			AllowedPrincipalArns: item.SqsConfig.AllowedPrincipalArns,
 			AllowedSourceArns:    item.SqsConfig.AllowedSourceArns,
 		}
+	case models.IntegrationTypeHTTPPush:
+		integration.HTTPPushConfig = &models.HTTPPushConfig{
+			LogTypes:  item.HTTPPushConfig.LogTypes,
+			AuthToken: item.HTTPPushConfig.AuthToken,
+		}
 	}
 	return integration
 }
@@ -77,6 +77,7 @@ var (
 			LastUpdatedBy:     "userId",
 			LastUpdatedByTime: timeInTest,
 			DeliveryResponses: []*models.DeliveryResponse{},
+			Tickets:           []*models.ExternalTicket{},
 			Description:       "description",
 			Reference:         "reference",
 			Runbook:           "runbook",
@@ -204,6 +205,7 @@ func TestListAllAlertsWithoutTitle(t *testing.T) {
 			LastUpdatedBy:     "userId",
 			LastUpdatedByTime: timeInTest,
 			DeliveryResponses: []*models.DeliveryResponse{},
+			Tickets:           []*models.ExternalTicket{},
 			Description:       "description",
 			Reference:         "reference",
 			Runbook:           "runbook",
@@ -229,6 +231,7 @@ func TestListAllAlertsWithoutTitle(t *testing.T) {
 			LastUpdatedBy:     "userId",
 			LastUpdatedByTime: timeInTest,
 			DeliveryResponses: []*models.DeliveryResponse{},
+			Tickets:           []*models.ExternalTicket{},
 			Description:       "description",
 			Reference:         "reference",
 			Runbook:           "runbook",
@@ -41,6 +41,7 @@ const (
 	LogTypesKey          = "logTypes"
 	ResourceTypesKey     = "resourceTypes"
 	DeliveryResponsesKey = "deliveryResponses"
+	TicketsKey           = "tickets"
 	LastUpdatedByKey     = "lastUpdatedBy"
 	LastUpdatedByTimeKey = "lastUpdatedByTime"
 	TypeKey              = "type"
@@ -100,6 +101,8 @@ type AlertItem struct {
 	FirstEventMatchTime time.Time                  `json:"firstEventMatchTime"`
 	CreationTime        time.Time                  `json:"creationTime"`
 	DeliveryResponses   []*models.DeliveryResponse `json:"deliveryResponses"`
+	// Tickets - stores the issues created for the alert in external ticketing systems
+	Tickets []*models.ExternalTicket `json:"tickets,omitempty"`
 	// UpdateTime - stores the timestamp from an update from a dedup event
 	UpdateTime time.Time `json:"updateTime"`
 	Severity   string    `json:"severity"`
@@ -88,6 +88,13 @@ func (table *AlertsTable) UpdateAlertDelivery(input *models.UpdateAlertDeliveryI
 			expression.IfNotExists(expression.Name(DeliveryResponsesKey), expression.Value(emptyList)),
 			expression.Value(input.DeliveryResponses),
 		))
+	if len(input.Tickets) > 0 {
+		updateBuilder = updateBuilder.Set(expression.Name(TicketsKey),
+			expression.ListAppend(
+				expression.IfNotExists(expression.Name(TicketsKey), expression.Value(emptyList)),
+				expression.Value(input.Tickets),
+			))
+	}
 
 	// Create the condition builder
 	conditionBuilder := expression.Equal(expression.Name(AlertIDKey), expression.Value(input.AlertID))
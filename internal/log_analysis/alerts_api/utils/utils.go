@@ -85,6 +85,7 @@ func AlertItemToSummary(item *table.AlertItem, rule *models.Rule) *alertmodels.A
 		LastUpdatedByTime: item.LastUpdatedByTime,
 		UpdateTime:        &item.UpdateTime,
 		DeliveryResponses: item.DeliveryResponses,
+		Tickets:           item.Tickets,
 		PolicyID:          item.PolicyID,
 		PolicyDisplayName: item.PolicyDisplayName,
 		PolicySourceID:    item.PolicySourceID,
@@ -79,6 +79,10 @@ func DataTypeFromS3Key(s3key string) (dataType pantherdb.DataType, err error) {
 	switch keyParts[0] {
 	case logS3Prefix:
 		dataType = pantherdb.LogData
+		// log lines that failed to classify are stored in the log processing database but must not reach the rules engine
+		if keyParts[1] == pantherdb.DeadLettersTable {
+			dataType = pantherdb.DeadLetters
+		}
 	case ruleMatchS3Prefix:
 		dataType = pantherdb.RuleData
 	case ruleErrorsS3Prefix:
@@ -41,6 +41,11 @@ func TestDataTypeFromS3Key(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, pantherdb.LogData, dataType)
 
+	// dead letters
+	dataType, err = DataTypeFromS3Key(logS3Prefix + "/" + pantherdb.DeadLettersTable)
+	require.NoError(t, err)
+	assert.Equal(t, pantherdb.DeadLetters, dataType)
+
 	// rule matches
 	dataType, err = DataTypeFromS3Key(ruleMatchS3Prefix + "/some_table")
 	require.NoError(t, err)
@@ -0,0 +1,133 @@
+package awsglue
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/pkg/errors"
+)
+
+// DataFormat is the storage format of the data files of a table
+type DataFormat string
+
+const (
+	// DataFormatJSON stores data as gzip compressed JSON lines (the default)
+	DataFormatJSON DataFormat = "json"
+	// DataFormatParquet stores data as snappy compressed Parquet files
+	DataFormatParquet DataFormat = "parquet"
+)
+
+const (
+	jsonSerDe           = "org.openx.data.jsonserde.JsonSerDe"
+	jsonInputFormat     = "org.apache.hadoop.mapred.TextInputFormat"
+	jsonOutputFormat    = "org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat"
+	parquetSerDe        = "org.apache.hadoop.hive.ql.io.parquet.serde.ParquetHiveSerDe"
+	parquetInputFormat  = "org.apache.hadoop.hive.ql.io.parquet.MapredParquetInputFormat"
+	parquetOutputFormat = "org.apache.hadoop.hive.ql.io.parquet.MapredParquetOutputFormat"
+)
+
+// ParseDataFormat parses a data format name
+func ParseDataFormat(name string) (DataFormat, error) {
+	switch f := DataFormat(strings.ToLower(strings.TrimSpace(name))); f {
+	case DataFormatJSON, DataFormatParquet:
+		return f, nil
+	case "":
+		return DataFormatJSON, nil
+	default:
+		return "", errors.Errorf("invalid data format %q", name)
+	}
+}
+
+// FileExtension returns the extension used for data files in this format
+func (f DataFormat) FileExtension() string {
+	switch f {
+	case DataFormatParquet:
+		return ".parquet"
+	default:
+		return ".json.gz"
+	}
+}
+
+// DataFormatFromS3Key detects the data format of an S3 object using its file extension
+func DataFormatFromS3Key(key string) (DataFormat, bool) {
+	switch {
+	case strings.HasSuffix(key, DataFormatParquet.FileExtension()):
+		return DataFormatParquet, true
+	case strings.HasSuffix(key, DataFormatJSON.FileExtension()):
+		return DataFormatJSON, true
+	default:
+		return "", false
+	}
+}
+
+// NotificationPrefix is the S3 prefix for gzip JSON copies of data files stored in other formats.
+// Processed data consumers (rules engine, cloud security) read gzip JSON so they are notified about the copy.
+const NotificationPrefix = "notifications/"
+
+// NotificationCopyKey returns the key of the gzip JSON copy of a data file that is announced to processed data consumers.
+// The copy keeps the key of the data file so that TableObjectKey can map it back.
+func NotificationCopyKey(key string) string {
+	return NotificationPrefix + key + DataFormatJSON.FileExtension()
+}
+
+// TableObjectKey returns the key of the data file a notification copy was made for.
+// It returns false if key is not a notification copy.
+func TableObjectKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, NotificationPrefix) || !strings.HasSuffix(key, DataFormatJSON.FileExtension()) {
+		return "", false
+	}
+	key = strings.TrimPrefix(key, NotificationPrefix)
+	return strings.TrimSuffix(key, DataFormatJSON.FileExtension()), true
+}
+
+// DataFormatFromStorageDescriptor detects the data format of a table or partition using its SerDe
+func DataFormatFromStorageDescriptor(desc *glue.StorageDescriptor) DataFormat {
+	if desc != nil && desc.SerdeInfo != nil {
+		lib := strings.ToLower(aws.StringValue(desc.SerdeInfo.SerializationLibrary))
+		if strings.Contains(lib, "parquet") {
+			return DataFormatParquet
+		}
+	}
+	return DataFormatJSON
+}
+
+// WithStorageDescriptor returns a copy of desc that reads data in this format.
+// Columns, location and SerDe parameters are preserved so that partitions in different formats can coexist in a table.
+func (f DataFormat) WithStorageDescriptor(desc *glue.StorageDescriptor) *glue.StorageDescriptor {
+	out := *desc // copy because we will mutate
+	serde := glue.SerDeInfo{}
+	if desc.SerdeInfo != nil {
+		serde = *desc.SerdeInfo
+	}
+	switch f {
+	case DataFormatParquet:
+		out.InputFormat = aws.String(parquetInputFormat)
+		out.OutputFormat = aws.String(parquetOutputFormat)
+		serde.SerializationLibrary = aws.String(parquetSerDe)
+	default:
+		out.InputFormat = aws.String(jsonInputFormat)
+		out.OutputFormat = aws.String(jsonOutputFormat)
+		serde.SerializationLibrary = aws.String(jsonSerDe)
+	}
+	out.SerdeInfo = &serde
+	return &out
+}
@@ -0,0 +1,110 @@
+package awsglue
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+func TestParseDataFormat(t *testing.T) {
+	assert := require.New(t)
+	format, err := ParseDataFormat("")
+	assert.NoError(err)
+	assert.Equal(DataFormatJSON, format)
+	format, err = ParseDataFormat(" Parquet ")
+	assert.NoError(err)
+	assert.Equal(DataFormatParquet, format)
+	_, err = ParseDataFormat("orc")
+	assert.Error(err)
+}
+
+func TestDataFormatFromS3Key(t *testing.T) {
+	assert := require.New(t)
+	format, ok := DataFormatFromS3Key("logs/table/year=2020/month=01/day=03/hour=01/20200103T010000Z-uuid4.parquet")
+	assert.True(ok)
+	assert.Equal(DataFormatParquet, format)
+	format, ok = DataFormatFromS3Key("logs/table/year=2020/month=01/day=03/hour=01/20200103T010000Z-uuid4.json.gz")
+	assert.True(ok)
+	assert.Equal(DataFormatJSON, format)
+	_, ok = DataFormatFromS3Key("logs/table/year=2020/month=01/day=03/hour=01/")
+	assert.False(ok)
+}
+
+func TestNotificationCopyKey(t *testing.T) {
+	assert := require.New(t)
+	const key = "logs/table/year=2020/month=01/day=03/hour=01/20200103T010000Z-uuid4.parquet"
+	copyKey := NotificationCopyKey(key)
+	assert.Equal("notifications/logs/table/year=2020/month=01/day=03/hour=01/20200103T010000Z-uuid4.parquet.json.gz", copyKey)
+	format, ok := DataFormatFromS3Key(copyKey)
+	assert.True(ok)
+	assert.Equal(DataFormatJSON, format)
+	tableKey, ok := TableObjectKey(copyKey)
+	assert.True(ok)
+	assert.Equal(key, tableKey)
+	_, ok = TableObjectKey(key)
+	assert.False(ok)
+}
+
+func TestDataFormat_WithStorageDescriptor(t *testing.T) {
+	assert := require.New(t)
+	assert.Equal(DataFormatJSON, DataFormatFromStorageDescriptor(testStorageDescriptor))
+
+	desc := DataFormatParquet.WithStorageDescriptor(testStorageDescriptor)
+	assert.Equal(DataFormatParquet, DataFormatFromStorageDescriptor(desc))
+	assert.Equal(parquetInputFormat, aws.StringValue(desc.InputFormat))
+	assert.Equal(parquetOutputFormat, aws.StringValue(desc.OutputFormat))
+	assert.Equal(testStorageDescriptor.Columns, desc.Columns)
+	assert.Equal(testStorageDescriptor.Location, desc.Location)
+	assert.Equal(testStorageDescriptor.SerdeInfo.Parameters, desc.SerdeInfo.Parameters)
+	// The original descriptor should not be modified
+	assert.Equal(jsonSerDe, aws.StringValue(testStorageDescriptor.SerdeInfo.SerializationLibrary))
+
+	desc = DataFormatJSON.WithStorageDescriptor(desc)
+	assert.True(IsJSONPartition(desc))
+	assert.Equal(jsonInputFormat, aws.StringValue(desc.InputFormat))
+}
+
+func TestGlueTableMetadata_WithFormat(t *testing.T) {
+	type event struct {
+		Foo string `json:"foo"`
+	}
+	assert := require.New(t)
+	gm := NewGlueTableMetadata(pantherdb.LogProcessingDatabase, "my_logs_type", "description", GlueTableHourly, &event{})
+	assert.Equal(DataFormatJSON, gm.Format())
+	pq := gm.WithFormat(DataFormatParquet)
+	assert.Equal(DataFormatParquet, pq.Format())
+	assert.Equal(DataFormatJSON, gm.Format())
+
+	input, err := pq.glueTableInput("bucket")
+	assert.NoError(err)
+	assert.Equal(parquetSerDe, aws.StringValue(input.StorageDescriptor.SerdeInfo.SerializationLibrary))
+	assert.Equal([]*glue.Column{{
+		Name:    aws.String("foo"),
+		Type:    aws.String("string"),
+		Comment: aws.String(""),
+	}}, input.StorageDescriptor.Columns)
+	// Rule tables are always written as JSON
+	assert.Equal(DataFormatJSON, pq.RuleTable().Format())
+}
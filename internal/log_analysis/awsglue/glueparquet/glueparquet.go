@@ -0,0 +1,400 @@
+// Package glueparquet converts JSON events to Parquet rows using the columns of a Glue table.
+package glueparquet
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/gluetimestamp"
+)
+
+// Schema is a Parquet schema derived from the columns of a Glue table
+type Schema struct {
+	root *node
+	// schemaJSON is the schema in the format expected by parquet-go JSON writers
+	schemaJSON string
+}
+
+// NewSchema creates a Parquet schema from Glue table columns
+func NewSchema(columns []*glue.Column) (*Schema, error) {
+	root := node{
+		kind:   kindStruct,
+		fields: make(map[string]*node, len(columns)),
+	}
+	for _, col := range columns {
+		name := aws.StringValue(col.Name)
+		typ, err := parseType(aws.StringValue(col.Type))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid type for column %q", name)
+		}
+		root.names = append(root.names, name)
+		root.fields[name] = typ
+	}
+	return &Schema{
+		root:       &root,
+		schemaJSON: root.schemaJSON("parquet_go_root", "REQUIRED"),
+	}, nil
+}
+
+// NewSchemaFromGlue creates a Parquet schema from glueschema columns
+func NewSchemaFromGlue(columns []glueschema.Column) (*Schema, error) {
+	glueColumns := make([]*glue.Column, len(columns))
+	for i := range columns {
+		glueColumns[i] = &glue.Column{
+			Name: aws.String(columns[i].Name),
+			Type: aws.String(string(columns[i].Type)),
+		}
+	}
+	return NewSchema(glueColumns)
+}
+
+// JSON returns the schema in the JSON format used by parquet-go
+func (s *Schema) JSON() string {
+	return s.schemaJSON
+}
+
+// ConvertJSON converts a JSON event to a JSON row matching the Parquet schema.
+// Field names are converted to column names, timestamps are converted to milliseconds and fields not in the schema
+// are dropped.
+func (s *Schema) ConvertJSON(dst, event []byte) ([]byte, error) {
+	iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, event)
+	stream := jsoniter.NewStream(jsoniter.ConfigDefault, nil, 0)
+	return s.convertJSON(iter, stream, dst, event)
+}
+
+func (s *Schema) convertJSON(iter *jsoniter.Iterator, stream *jsoniter.Stream, dst, event []byte) ([]byte, error) {
+	iter.ResetBytes(event)
+	iter.Error = nil
+	stream.Reset(nil)
+	stream.SetBuffer(dst)
+	stream.Error = nil
+	s.root.convert(iter, stream)
+	if err := iter.Error; err != nil {
+		return nil, errors.Wrap(err, "failed to read JSON event")
+	}
+	if err := stream.Error; err != nil {
+		return nil, errors.Wrap(err, "failed to write JSON row")
+	}
+	return stream.Buffer(), nil
+}
+
+type nodeKind int
+
+const (
+	kindScalar nodeKind = iota
+	kindArray
+	kindMap
+	kindStruct
+)
+
+type node struct {
+	kind nodeKind
+	// scalar type
+	typ glueschema.Type
+	// array elements or map values
+	elem *node
+	// struct fields by column name
+	fields map[string]*node
+	// struct field names in column order
+	names []string
+}
+
+// parseType parses a Glue type string (ie 'array<struct<foo:string>>')
+func parseType(typ string) (*node, error) {
+	p := typeParser{input: typ}
+	n, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.input) {
+		return nil, errors.Errorf("unexpected input at %d in type %q", p.pos, typ)
+	}
+	return n, nil
+}
+
+type typeParser struct {
+	input string
+	pos   int
+}
+
+func (p *typeParser) parse() (*node, error) {
+	name := p.readUntil("<,>:")
+	switch name {
+	case "array":
+		if err := p.expect('<'); err != nil {
+			return nil, err
+		}
+		elem, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect('>'); err != nil {
+			return nil, err
+		}
+		return &node{kind: kindArray, elem: elem}, nil
+	case "map":
+		if err := p.expect('<'); err != nil {
+			return nil, err
+		}
+		key, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		if key.kind != kindScalar || key.typ != glueschema.TypeString {
+			return nil, errors.Errorf("unsupported map key type in %q", p.input)
+		}
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		val, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect('>'); err != nil {
+			return nil, err
+		}
+		return &node{kind: kindMap, elem: val}, nil
+	case "struct":
+		if err := p.expect('<'); err != nil {
+			return nil, err
+		}
+		n := node{
+			kind:   kindStruct,
+			fields: make(map[string]*node),
+		}
+		for {
+			fieldName := p.readUntil(":")
+			if err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			field, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			n.names = append(n.names, fieldName)
+			n.fields[fieldName] = field
+			if p.peek() == ',' {
+				p.pos++
+				continue
+			}
+			if err := p.expect('>'); err != nil {
+				return nil, err
+			}
+			return &n, nil
+		}
+	case "":
+		return nil, errors.Errorf("missing type at %d in %q", p.pos, p.input)
+	default:
+		typ := glueschema.Type(name)
+		if _, ok := scalarTags[typ]; !ok {
+			return nil, errors.Errorf("unsupported type %q", name)
+		}
+		return &node{kind: kindScalar, typ: typ}, nil
+	}
+}
+
+func (p *typeParser) readUntil(stop string) string {
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(stop, rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *typeParser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *typeParser) expect(c byte) error {
+	if p.peek() != c {
+		return errors.Errorf("expected %q at %d in %q", c, p.pos, p.input)
+	}
+	p.pos++
+	return nil
+}
+
+// scalarTags maps Glue scalar types to parquet-go schema tags
+var scalarTags = map[glueschema.Type]string{
+	glueschema.TypeString:    "type=UTF8",
+	glueschema.TypeBool:      "type=BOOLEAN",
+	glueschema.TypeTimestamp: "type=TIMESTAMP_MILLIS",
+	glueschema.TypeTinyInt:   "type=INT_8",
+	glueschema.TypeSmallInt:  "type=INT_16",
+	glueschema.TypeInt:       "type=INT32",
+	glueschema.TypeBigInt:    "type=INT64",
+	glueschema.TypeDouble:    "type=DOUBLE",
+	glueschema.TypeFloat:     "type=FLOAT",
+}
+
+func (n *node) schemaJSON(name, repetition string) string {
+	tag := "name=" + name + ", repetitiontype=" + repetition
+	switch n.kind {
+	case kindArray:
+		return `{"Tag":"` + tag + `, type=LIST","Fields":[` + n.elem.schemaJSON("element", "OPTIONAL") + `]}`
+	case kindMap:
+		key := `{"Tag":"name=key, type=UTF8, repetitiontype=REQUIRED"}`
+		return `{"Tag":"` + tag + `, type=MAP","Fields":[` + key + `,` + n.elem.schemaJSON("value", "OPTIONAL") + `]}`
+	case kindStruct:
+		fields := make([]string, len(n.names))
+		for i, name := range n.names {
+			fields[i] = n.fields[name].schemaJSON(name, "OPTIONAL")
+		}
+		return `{"Tag":"` + tag + `","Fields":[` + strings.Join(fields, ",") + `]}`
+	default:
+		return `{"Tag":"` + tag + `, ` + scalarTags[n.typ] + `"}`
+	}
+}
+
+func (n *node) convert(iter *jsoniter.Iterator, stream *jsoniter.Stream) {
+	if iter.WhatIsNext() == jsoniter.NilValue {
+		iter.Skip()
+		stream.WriteNil()
+		return
+	}
+	switch n.kind {
+	case kindStruct:
+		if iter.WhatIsNext() != jsoniter.ObjectValue {
+			iter.Skip()
+			stream.WriteNil()
+			return
+		}
+		stream.WriteObjectStart()
+		more := false
+		for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+			name := glueschema.ColumnName(key)
+			field, ok := n.fields[name]
+			if !ok {
+				iter.Skip()
+				continue
+			}
+			if more {
+				stream.WriteMore()
+			}
+			more = true
+			stream.WriteObjectField(name)
+			field.convert(iter, stream)
+		}
+		stream.WriteObjectEnd()
+	case kindMap:
+		if iter.WhatIsNext() != jsoniter.ObjectValue {
+			iter.Skip()
+			stream.WriteNil()
+			return
+		}
+		stream.WriteObjectStart()
+		more := false
+		for key := iter.ReadObject(); key != ""; key = iter.ReadObject() {
+			if more {
+				stream.WriteMore()
+			}
+			more = true
+			stream.WriteObjectField(key)
+			n.elem.convert(iter, stream)
+		}
+		stream.WriteObjectEnd()
+	case kindArray:
+		if iter.WhatIsNext() != jsoniter.ArrayValue {
+			iter.Skip()
+			stream.WriteNil()
+			return
+		}
+		stream.WriteArrayStart()
+		more := false
+		for iter.ReadArray() {
+			if more {
+				stream.WriteMore()
+			}
+			more = true
+			n.elem.convert(iter, stream)
+		}
+		stream.WriteArrayEnd()
+	default:
+		n.convertScalar(iter, stream)
+	}
+}
+
+func (n *node) convertScalar(iter *jsoniter.Iterator, stream *jsoniter.Stream) {
+	switch n.typ {
+	case glueschema.TypeString:
+		if iter.WhatIsNext() == jsoniter.StringValue {
+			stream.WriteString(iter.ReadString())
+			return
+		}
+		// Raw JSON values are stored as strings
+		stream.WriteString(string(iter.SkipAndReturnBytes()))
+	case glueschema.TypeTimestamp:
+		switch iter.WhatIsNext() {
+		case jsoniter.StringValue:
+			tm, err := time.Parse(gluetimestamp.Layout, iter.ReadString())
+			if err != nil {
+				stream.WriteNil()
+				return
+			}
+			stream.WriteInt64(tm.UnixNano() / int64(time.Millisecond))
+		case jsoniter.NumberValue:
+			stream.WriteRaw(string(iter.SkipAndReturnBytes()))
+		default:
+			iter.Skip()
+			stream.WriteNil()
+		}
+	case glueschema.TypeBool:
+		switch iter.WhatIsNext() {
+		case jsoniter.BoolValue:
+			stream.WriteBool(iter.ReadBool())
+		case jsoniter.StringValue:
+			b, err := strconv.ParseBool(iter.ReadString())
+			if err != nil {
+				stream.WriteNil()
+				return
+			}
+			stream.WriteBool(b)
+		default:
+			iter.Skip()
+			stream.WriteNil()
+		}
+	default:
+		// Numeric types
+		switch iter.WhatIsNext() {
+		case jsoniter.NumberValue:
+			stream.WriteRaw(string(iter.SkipAndReturnBytes()))
+		case jsoniter.StringValue:
+			num := iter.ReadString()
+			if _, err := strconv.ParseFloat(num, 64); err != nil {
+				stream.WriteNil()
+				return
+			}
+			stream.WriteRaw(num)
+		default:
+			iter.Skip()
+			stream.WriteNil()
+		}
+	}
+}
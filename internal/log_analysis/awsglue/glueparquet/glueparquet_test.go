@@ -0,0 +1,121 @@
+package glueparquet
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var testColumns = []*glue.Column{
+	{Name: aws.String("name"), Type: aws.String("string")},
+	{Name: aws.String("count"), Type: aws.String("bigint")},
+	{Name: aws.String("ok"), Type: aws.String("boolean")},
+	{Name: aws.String("ts"), Type: aws.String("timestamp")},
+	{Name: aws.String("tags"), Type: aws.String("array<string>")},
+	{Name: aws.String("attrs"), Type: aws.String("map<string,int>")},
+	{Name: aws.String("raw"), Type: aws.String("string")},
+	{Name: aws.String("user"), Type: aws.String("struct<id:bigint,user_name:string>")},
+}
+
+func TestParseType(t *testing.T) {
+	assert := require.New(t)
+	n, err := parseType("array<struct<foo:string,bar:map<string,array<bigint>>>>")
+	assert.NoError(err)
+	assert.Equal(kindArray, n.kind)
+	assert.Equal(kindStruct, n.elem.kind)
+	assert.Equal([]string{"foo", "bar"}, n.elem.names)
+	assert.Equal(kindMap, n.elem.fields["bar"].kind)
+	assert.Equal(kindArray, n.elem.fields["bar"].elem.kind)
+
+	for _, invalid := range []string{
+		"",
+		"decimal(10,2)",
+		"array<string",
+		"map<int,string>",
+		"struct<foo:string,>",
+		"string>",
+	} {
+		_, err := parseType(invalid)
+		assert.Error(err, invalid)
+	}
+}
+
+func TestSchema_ConvertJSON(t *testing.T) {
+	assert := require.New(t)
+	schema, err := NewSchema(testColumns)
+	assert.NoError(err)
+	event := `{
+		"name": "foo",
+		"count": 42,
+		"ok": true,
+		"ts": "2020-01-03 01:01:01.500000000",
+		"tags": ["a", "b"],
+		"attrs": {"x": 1},
+		"raw": {"nested": [1, 2]},
+		"user": {"id": "7", "user.name": "bar"},
+		"unknown": "baz"
+	}`
+	row, err := schema.ConvertJSON(nil, []byte(event))
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"name": "foo",
+		"count": 42,
+		"ok": true,
+		"ts": 1578013261500,
+		"tags": ["a", "b"],
+		"attrs": {"x": 1},
+		"raw": "{\"nested\": [1, 2]}",
+		"user": {"id": 7, "user_name": "bar"}
+	}`, string(row))
+}
+
+func TestWriter(t *testing.T) {
+	assert := require.New(t)
+	schema, err := NewSchema(testColumns)
+	assert.NoError(err)
+	var out bytes.Buffer
+	w, err := schema.NewWriter(&out)
+	assert.NoError(err)
+	assert.NoError(w.WriteJSON([]byte(`{"name":"foo","count":1,"ts":"2020-01-03 01:01:01.000000000","tags":["a"],"user":{"id":1}}`)))
+	assert.NoError(w.WriteJSON([]byte(`{"name":"bar","attrs":{"x":2},"ok":false}`)))
+	assert.NoError(w.Close())
+
+	file, err := buffer.NewBufferFile(out.Bytes())
+	assert.NoError(err)
+	r, err := reader.NewParquetReader(file, nil, 1)
+	assert.NoError(err)
+	defer r.ReadStop()
+	assert.Equal(int64(2), r.GetNumRows())
+	rows, err := r.ReadByNumber(2)
+	assert.NoError(err)
+	data, err := jsoniter.Marshal(rows)
+	assert.NoError(err)
+	assert.JSONEq(`[
+		{"Name":"foo","Count":1,"Ok":null,"Ts":1578013261000,"Tags":["a"],"Attrs":null,"Raw":null,"User":{"Id":1,"User_name":null}},
+		{"Name":"bar","Count":null,"Ok":false,"Ts":null,"Tags":null,"Attrs":{"x":2},"Raw":null,"User":null}
+	]`, string(data))
+}
@@ -0,0 +1,76 @@
+package glueparquet
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Writer writes JSON events as rows of a Parquet file
+type Writer struct {
+	schema *Schema
+	writer *writer.JSONWriter
+	iter   *jsoniter.Iterator
+	stream *jsoniter.Stream
+	row    []byte
+}
+
+// NewWriter creates a Parquet file writer.
+// The file is written to w when the writer is closed.
+func (s *Schema) NewWriter(w io.Writer) (*Writer, error) {
+	// We use a single goroutine to marshal rows since writers already run concurrently
+	const parallelism = 1
+	pw, err := writer.NewJSONWriterFromWriter(s.schemaJSON, w, parallelism)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Parquet writer")
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &Writer{
+		schema: s,
+		writer: pw,
+		iter:   jsoniter.ParseBytes(jsoniter.ConfigDefault, nil),
+		stream: jsoniter.NewStream(jsoniter.ConfigDefault, nil, 0),
+	}, nil
+}
+
+// WriteJSON writes a JSON event as a row
+func (w *Writer) WriteJSON(event []byte) error {
+	row, err := w.schema.convertJSON(w.iter, w.stream, w.row[:0], event)
+	if err != nil {
+		return err
+	}
+	w.row = row
+	if err := w.writer.Write(string(row)); err != nil {
+		return errors.Wrap(err, "failed to write Parquet row")
+	}
+	return nil
+}
+
+// Close flushes all rows and writes the Parquet file footer
+func (w *Writer) Close() error {
+	if err := w.writer.WriteStop(); err != nil {
+		return errors.Wrap(err, "failed to write Parquet file")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package awsglue
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/pkg/errors"
+)
+
+// TableLayout is the way the data files of a table are tracked
+type TableLayout string
+
+const (
+	// TableLayoutDefault tracks data files by listing the S3 objects of each partition (the default)
+	TableLayoutDefault TableLayout = ""
+	// TableLayoutManifest also tracks data files in manifests stored under the table prefix (see datalake/tablemeta).
+	// Queries still read the S3 objects of each partition, manifests are used by compaction and retention.
+	TableLayoutManifest TableLayout = "manifest"
+
+	// TableParameterLayout is the Glue table parameter that stores the layout of a table
+	TableParameterLayout = "panther_table_layout"
+)
+
+// ParseTableLayout parses a table layout name
+func ParseTableLayout(name string) (TableLayout, error) {
+	switch layout := TableLayout(strings.ToLower(strings.TrimSpace(name))); layout {
+	case TableLayoutDefault, TableLayoutManifest:
+		return layout, nil
+	default:
+		return "", errors.Errorf("invalid table layout %q", name)
+	}
+}
+
+// TableLayoutFromTable detects the layout of a table using its parameters
+func TableLayoutFromTable(tbl *glue.TableData) TableLayout {
+	if tbl == nil {
+		return TableLayoutDefault
+	}
+	layout, err := ParseTableLayout(aws.StringValue(tbl.Parameters[TableParameterLayout]))
+	if err != nil {
+		return TableLayoutDefault
+	}
+	return layout
+}
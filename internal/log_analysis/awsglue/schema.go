@@ -76,3 +76,21 @@ var (
 		},
 	)
 )
+
+// UDMColumn returns the column holding the data model fields of events.
+// Its struct type has the fields of the data model enabled for the log type of the table, so that
+// data model changes only update the tables of the log types they map.
+func UDMColumn(fields []string) Column {
+	cols := make([]Column, len(fields))
+	for i, name := range fields {
+		cols[i] = Column{
+			Name: name,
+			Type: glueschema.TypeString,
+		}
+	}
+	return Column{
+		Name:    "p_udm",
+		Type:    glueschema.StructOf(cols),
+		Comment: "Panther added field with normalized data model fields",
+	}
+}
@@ -51,6 +51,9 @@ type GlueTableMetadata struct {
 	prefix       string
 	timebin      GlueTableTimebin // at what time resolution is this table partitioned
 	eventStruct  interface{}
+	format       DataFormat  // the storage format of the data files
+	udmFields    []string    // the data model fields of the p_udm column
+	layout       TableLayout // the way data files are tracked
 }
 
 // Creates a new GlueTableMetadata object for Panther log sources
@@ -65,9 +68,31 @@ func NewGlueTableMetadata(
 		timebin:      timebin,
 		prefix:       tablePrefix,
 		eventStruct:  eventStruct,
+		format:       DataFormatJSON,
 	}
 }
 
+// WithFormat returns a copy of the table metadata that stores data in a different format
+func (gm *GlueTableMetadata) WithFormat(format DataFormat) *GlueTableMetadata {
+	out := *gm
+	out.format = format
+	return &out
+}
+
+// WithUDMFields returns a copy of the table metadata with a p_udm column for the provided data model fields
+func (gm *GlueTableMetadata) WithUDMFields(fields []string) *GlueTableMetadata {
+	out := *gm
+	out.udmFields = fields
+	return &out
+}
+
+// WithLayout returns a copy of the table metadata that tracks data files using a different layout
+func (gm *GlueTableMetadata) WithLayout(layout TableLayout) *GlueTableMetadata {
+	out := *gm
+	out.layout = layout
+	return &out
+}
+
 func (gm *GlueTableMetadata) DatabaseName() string {
 	return gm.databaseName
 }
@@ -93,6 +118,14 @@ func (gm *GlueTableMetadata) EventStruct() interface{} {
 	return gm.eventStruct
 }
 
+func (gm *GlueTableMetadata) Format() DataFormat {
+	return gm.format
+}
+
+func (gm *GlueTableMetadata) Layout() TableLayout {
+	return gm.layout
+}
+
 func (gm *GlueTableMetadata) HasPartitions(glueClient glueiface.GlueAPI) (bool, error) {
 	return TableHasPartitions(glueClient, gm.databaseName, gm.tableName)
 }
@@ -119,7 +152,9 @@ func (gm *GlueTableMetadata) RuleTable() *GlueTableMetadata {
 		return gm
 	}
 	// the corresponding rule table shares the same structure as the log table + some columns
-	return NewGlueTableMetadata(pantherdb.RuleMatchDatabase, gm.tableName, gm.Description(), GlueTableHourly, gm.EventStruct())
+	tbl := NewGlueTableMetadata(pantherdb.RuleMatchDatabase, gm.tableName, gm.Description(), GlueTableHourly, gm.EventStruct())
+	tbl.udmFields = gm.udmFields
+	return tbl
 }
 
 func (gm *GlueTableMetadata) RuleErrorTable() *GlueTableMetadata {
@@ -127,7 +162,9 @@ func (gm *GlueTableMetadata) RuleErrorTable() *GlueTableMetadata {
 		return gm
 	}
 	// the corresponding rule table shares the same structure as the log table + some columns
-	return NewGlueTableMetadata(pantherdb.RuleErrorsDatabase, gm.tableName, gm.Description(), GlueTableHourly, gm.EventStruct())
+	tbl := NewGlueTableMetadata(pantherdb.RuleErrorsDatabase, gm.tableName, gm.Description(), GlueTableHourly, gm.EventStruct())
+	tbl.udmFields = gm.udmFields
+	return tbl
 }
 
 func (gm *GlueTableMetadata) glueTableInput(bucketName string) (*glue.TableInput, error) {
@@ -146,6 +183,9 @@ func (gm *GlueTableMetadata) glueTableInput(bucketName string) (*glue.TableInput
 	if err != nil {
 		return nil, err
 	}
+	if len(gm.udmFields) > 0 {
+		columns = append(columns, UDMColumn(gm.udmFields))
+	}
 	switch gm.databaseName {
 	case pantherdb.RuleMatchDatabase:
 		// append the columns added by the rule engine
@@ -176,22 +216,27 @@ func (gm *GlueTableMetadata) glueTableInput(bucketName string) (*glue.TableInput
 		descriptorParameters[fmt.Sprintf("mapping.%s", from)] = &to
 	}
 
-	return &glue.TableInput{
-		Name:          &gm.tableName,
-		Description:   &gm.description,
-		PartitionKeys: partitionColumns,
-		StorageDescriptor: &glue.StorageDescriptor{ // configure as JSON
-			Columns:      glueColumns,
-			Location:     aws.String("s3://" + bucketName + "/" + gm.prefix),
-			InputFormat:  aws.String("org.apache.hadoop.mapred.TextInputFormat"),
-			OutputFormat: aws.String("org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat"),
-			SerdeInfo: &glue.SerDeInfo{
-				SerializationLibrary: aws.String("org.openx.data.jsonserde.JsonSerDe"),
-				Parameters:           descriptorParameters,
-			},
+	// The JSON SerDe parameters are kept for all formats so that partitions with JSON data can be added to the table
+	storageDescriptor := gm.format.WithStorageDescriptor(&glue.StorageDescriptor{
+		Columns:  glueColumns,
+		Location: aws.String("s3://" + bucketName + "/" + gm.prefix),
+		SerdeInfo: &glue.SerDeInfo{
+			Parameters: descriptorParameters,
 		},
-		TableType: aws.String("EXTERNAL_TABLE"),
-	}, nil
+	})
+	tableInput := &glue.TableInput{
+		Name:              &gm.tableName,
+		Description:       &gm.description,
+		PartitionKeys:     partitionColumns,
+		StorageDescriptor: storageDescriptor,
+		TableType:         aws.String("EXTERNAL_TABLE"),
+	}
+	if gm.layout != TableLayoutDefault {
+		tableInput.Parameters = map[string]*string{
+			TableParameterLayout: aws.String(string(gm.layout)),
+		}
+	}
+	return tableInput, nil
 }
 
 func (gm *GlueTableMetadata) UpdateTableIfExists(ctx context.Context, glueAPI glueiface.GlueAPI, bucketName string) (bool, error) {
@@ -387,7 +432,8 @@ func (gm *GlueTableMetadata) SyncPartitions(glueClient glueiface.GlueAPI, s3Clie
 				storageDescriptor := *getPartitionOutput.Partition.StorageDescriptor // copy because we will mutate
 				storageDescriptor.Columns = columns
 				// we need to update the SerDeInfo for JSON partitions to get the column mappings
-				if IsJSONPartition(&storageDescriptor) {
+				// partitions with data in a different format than the table keep their SerDe
+				if IsJSONPartition(&storageDescriptor) && IsJSONPartition(tableOutput.Table.StorageDescriptor) {
 					storageDescriptor.SerdeInfo = tableOutput.Table.StorageDescriptor.SerdeInfo
 				}
 				_, err = UpdatePartition(glueClient, gm.databaseName, gm.tableName, values,
@@ -428,17 +474,20 @@ func (gm *GlueTableMetadata) SyncPartitions(glueClient glueiface.GlueAPI, s3Clie
 }
 
 func (gm *GlueTableMetadata) CreateJSONPartition(client glueiface.GlueAPI, t time.Time) (created bool, err error) {
+	return gm.CreatePartition(client, t, DataFormatJSON)
+}
+
+// CreatePartition creates a partition for data files in the specified format.
+// The partition inherits the StorageDescriptor of the table, adapted to the data format if needed.
+func (gm *GlueTableMetadata) CreatePartition(client glueiface.GlueAPI, t time.Time, format DataFormat) (created bool, err error) {
 	// inherit StorageDescriptor from table
 	tableOutput, err := GetTable(client, gm.databaseName, gm.tableName)
 	if err != nil {
 		return false, err
 	}
-
-	// ensure this is a JSON table, use Contains() because there are multiple json serdes
-	if !IsJSONPartition(tableOutput.Table.StorageDescriptor) {
-		return false, errors.Errorf("not a JSON table: %#v", *tableOutput.Table.StorageDescriptor)
+	if DataFormatFromStorageDescriptor(tableOutput.Table.StorageDescriptor) != format {
+		tableOutput.Table.StorageDescriptor = format.WithStorageDescriptor(tableOutput.Table.StorageDescriptor)
 	}
-
 	return gm.createPartition(client, t, tableOutput)
 }
 
@@ -138,6 +138,57 @@ func TestGlueTableMetadataRuleMatches(t *testing.T) {
 	assert.Equal(t, "rules/my_rule/year=2020/month=01/day=03/hour=01/", gm.PartitionPrefix(refTime))
 }
 
+func TestGlueTableMetadata_WithUDMFields(t *testing.T) {
+	type event struct {
+		Foo string `json:"foo"`
+	}
+	assert := require.New(t)
+	gm := NewGlueTableMetadata(pantherdb.LogProcessingDatabase, "my_logs_type", "description", GlueTableHourly, &event{})
+	udm := gm.WithUDMFields([]string{"actor_user", "source_ip"})
+
+	input, err := gm.glueTableInput("bucket")
+	assert.NoError(err)
+	assert.Len(input.StorageDescriptor.Columns, 1)
+
+	input, err = udm.glueTableInput("bucket")
+	assert.NoError(err)
+	assert.Equal([]*glue.Column{
+		{
+			Name:    aws.String("foo"),
+			Type:    aws.String("string"),
+			Comment: aws.String(""),
+		},
+		{
+			Name:    aws.String("p_udm"),
+			Type:    aws.String("struct<actor_user:string,source_ip:string>"),
+			Comment: aws.String("Panther added field with normalized data model fields"),
+		},
+	}, input.StorageDescriptor.Columns)
+
+	// Rule tables keep the data model fields of the log table
+	input, err = udm.RuleTable().glueTableInput("bucket")
+	assert.NoError(err)
+	assert.Equal("p_udm", aws.StringValue(input.StorageDescriptor.Columns[1].Name))
+}
+
+func TestGlueTableMetadata_WithLayout(t *testing.T) {
+	type event struct {
+		Foo string `json:"foo"`
+	}
+	assert := require.New(t)
+	gm := NewGlueTableMetadata(pantherdb.LogProcessingDatabase, "my_logs_type", "description", GlueTableHourly, &event{})
+
+	input, err := gm.glueTableInput("bucket")
+	assert.NoError(err)
+	assert.Nil(input.Parameters)
+	assert.Equal(TableLayoutDefault, TableLayoutFromTable(&glue.TableData{Parameters: input.Parameters}))
+
+	input, err = gm.WithLayout(TableLayoutManifest).glueTableInput("bucket")
+	assert.NoError(err)
+	assert.Equal("manifest", aws.StringValue(input.Parameters[TableParameterLayout]))
+	assert.Equal(TableLayoutManifest, TableLayoutFromTable(&glue.TableData{Parameters: input.Parameters}))
+}
+
 func TestCreateJSONPartition(t *testing.T) {
 	gm := NewGlueTableMetadata(pantherdb.LogProcessingDatabase, "test_logs", "Description", GlueTableHourly, partitionTestEvent{})
 
@@ -0,0 +1,135 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/gluetasks"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+	"github.com/panther-labs/panther/pkg/awsretry"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+// The panther-partition-compactor lambda runs on a schedule and merges the small files of recent hourly partitions.
+
+const (
+	maxRetries = 20
+	// Stop compacting new partitions before the lambda times out
+	deadlineMargin = 2 * time.Minute
+)
+
+type handler struct {
+	GlueAPI glueiface.GlueAPI
+	S3API   s3iface.S3API
+	Logger  *zap.Logger
+	// Delay is the time to wait after an hour ends before compacting its partition, to allow late files to arrive
+	Delay time.Duration
+	// Lookback is the time range of partitions to compact
+	Lookback    time.Duration
+	MinFiles    int
+	MaxFileSize int
+	// GracePeriod is the time to wait before merging new files or deleting merged files
+	GracePeriod time.Duration
+	NumWorkers  int
+}
+
+func (h *handler) Run(ctx context.Context, _ events.CloudWatchEvent) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-deadlineMargin))
+		defer cancel()
+	}
+	end := time.Now().UTC().Add(-h.Delay)
+	start := end.Add(-h.Lookback)
+	// Rule match tables are written by the rules engine in larger batches, only processed data tables are compacted
+	for _, db := range []string{pantherdb.LogProcessingDatabase, pantherdb.CloudSecurityDatabase} {
+		task := gluetasks.CompactDatabaseTables{
+			DatabaseName: db,
+			Start:        start,
+			End:          end,
+			MinFiles:     h.MinFiles,
+			MaxFileSize:  h.MaxFileSize,
+			GracePeriod:  h.GracePeriod,
+			NumWorkers:   h.NumWorkers,
+		}
+		if err := task.Run(ctx, h.GlueAPI, h.S3API, h.Logger); err != nil {
+			// Partitions left uncompacted are picked up by the next run
+			if ctx.Err() == context.DeadlineExceeded {
+				h.Logger.Warn("compaction stopped before the lambda deadline", zap.Any("stats", &task.Stats))
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	config := struct {
+		Debug       bool          `split_words:"true"`
+		Delay       time.Duration `default:"2h"`
+		Lookback    time.Duration `default:"24h"`
+		MinFiles    int           `default:"8" split_words:"true"`
+		MaxFileSize int           `default:"67108864" split_words:"true"`
+		GracePeriod time.Duration `default:"1h" split_words:"true"`
+		NumWorkers  int           `default:"8" split_words:"true"`
+	}{}
+	envconfig.MustProcess("", &config)
+
+	logger := lambdalogger.Config{
+		Debug:     config.Debug,
+		Namespace: "log_analysis",
+		Component: "partition_compactor",
+	}.MustBuild()
+
+	awsSession := session.Must(session.NewSession()) // use default retries for fetching creds, avoids hangs!
+	clientsSession := awsSession.Copy(
+		request.WithRetryer(
+			aws.NewConfig().WithMaxRetries(maxRetries),
+			awsretry.NewConnectionErrRetryer(maxRetries),
+		),
+	)
+
+	h := handler{
+		GlueAPI:     glue.New(clientsSession),
+		S3API:       s3.New(clientsSession),
+		Logger:      logger,
+		Delay:       config.Delay,
+		Lookback:    config.Lookback,
+		MinFiles:    config.MinFiles,
+		MaxFileSize: config.MaxFileSize,
+		GracePeriod: config.GracePeriod,
+		NumWorkers:  config.NumWorkers,
+	}
+	lambda.Start(h.Run)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/panther-labs/panther/internal/log_analysis/gluetables"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+	"github.com/panther-labs/panther/pkg/stringset"
 )
 
 type CreateTablesEvent struct {
@@ -51,7 +52,7 @@ func (h *LambdaHandler) HandleCreateTablesEvent(ctx context.Context, event *Crea
 
 func (h *LambdaHandler) createTablesForLogTypes(ctx context.Context, logTypes []string) error {
 	// We map the log types to their 'base' log tables.
-	tables, err := resolveTables(ctx, h.Resolver, logTypes...)
+	tables, err := h.resolveTables(ctx, logTypes...)
 	if err != nil {
 		return err
 	}
@@ -79,7 +80,7 @@ func (h *LambdaHandler) createTablesForLogTypes(ctx context.Context, logTypes []
 
 func (h *LambdaHandler) createOrUpdateTablesForLogTypes(ctx context.Context, logTypes []string) error {
 	// We map the log types to their 'base' log tables, errors are collected and not fatal
-	tables, err := resolveTables(ctx, h.Resolver, logTypes...)
+	tables, err := h.resolveTables(ctx, logTypes...)
 	if err != nil {
 		return err
 	}
@@ -133,25 +134,52 @@ func (h *LambdaHandler) fetchAllDeployedLogTypes(ctx context.Context) ([]string,
 // Resolves the tables for the provided log types.
 // Note that this will return only the BASE tables (tables in for panther_logs and panther_cloudsecurity databases) but not any
 // downstream tables e.g. panther_rule_matches, panther_rule_errors
-func resolveTables(ctx context.Context, r logtypes.Resolver, names ...string) ([]*awsglue.GlueTableMetadata, error) {
+func (h *LambdaHandler) resolveTables(ctx context.Context, names ...string) ([]*awsglue.GlueTableMetadata, error) {
+	udmFields, err := h.udmFields(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var out []*awsglue.GlueTableMetadata
 	for _, name := range names {
-		entry, err := r.Resolve(ctx, name)
+		entry, err := h.Resolver.Resolve(ctx, name)
 		if err != nil {
 			return nil, errors.Wrapf(err, "cannot resolve logType: %s", name)
 		}
 		if entry == nil { // don't fail whole operation if missing data...
 			continue
 		}
-		out = append(out, tableForEntry(entry))
+		out = append(out, h.tableForEntry(entry, udmFields[name]))
 	}
 	return out, nil
 }
 
-func tableForEntry(entry logtypes.Entry) *awsglue.GlueTableMetadata {
+// udmFields lists the data model fields of the p_udm column for each log type.
+// Tables are not updated if the fields cannot be listed, to avoid dropping the column.
+func (h *LambdaHandler) udmFields(ctx context.Context) (map[string][]string, error) {
+	if h.ListUDMFields == nil {
+		return nil, nil
+	}
+	fields, err := h.ListUDMFields(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to list data model fields")
+	}
+	return fields, nil
+}
+
+func (h *LambdaHandler) tableForEntry(entry logtypes.Entry, udmFields []string) *awsglue.GlueTableMetadata {
 	eventSchema := entry.Schema()
 	desc := entry.Describe()
 	tableName := pantherdb.TableName(desc.Name)
 	db := pantherdb.DatabaseName(pantherdb.GetDataType(desc.Name))
-	return awsglue.NewGlueTableMetadata(db, tableName, desc.Description, awsglue.GlueTableHourly, eventSchema)
+	tbl := awsglue.NewGlueTableMetadata(db, tableName, desc.Description, awsglue.GlueTableHourly, eventSchema)
+	tbl = tbl.WithUDMFields(udmFields)
+	// Only log tables are stored as Parquet or tracked in manifests,
+	// rule match/error tables are written by the rules engine as JSON
+	if stringset.Contains(h.ParquetLogTypes, desc.Name) {
+		tbl = tbl.WithFormat(awsglue.DataFormatParquet)
+	}
+	if stringset.Contains(h.ManifestLogTypes, desc.Name) {
+		tbl = tbl.WithLayout(awsglue.TableLayoutManifest)
+	}
+	return tbl
 }
@@ -24,9 +24,11 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/glue"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -62,6 +64,67 @@ func TestSQS_CreateTables(t *testing.T) {
 	mockAthenaClient.AssertExpectations(t)
 }
 
+func TestSQS_CreateTablesUDM(t *testing.T) {
+	initProcessTest()
+	handler.ListUDMFields = func(_ context.Context) (map[string][]string, error) {
+		return map[string][]string{
+			"AWS.VPCFlow":    {"actor_user", "source_ip"},
+			"AWS.CloudTrail": {"event_name"},
+		}, nil
+	}
+
+	body := sqsTask{
+		CreateTables: &CreateTablesEvent{
+			LogTypes: []string{"AWS.VPCFlow"},
+		},
+	}
+	marshalled, err := jsoniter.Marshal(body)
+	require.NoError(t, err)
+	event := events.SQSEvent{Records: []events.SQSMessage{{Body: string(marshalled)}}}
+
+	// All tables of the log type have the p_udm column
+	hasUDMColumn := mock.MatchedBy(func(input *glue.CreateTableInput) bool {
+		columns := input.TableInput.StorageDescriptor.Columns
+		for _, col := range columns {
+			if aws.StringValue(col.Name) == "p_udm" {
+				return aws.StringValue(col.Type) == "struct<actor_user:string,source_ip:string>"
+			}
+		}
+		return false
+	})
+	mockGlueClient.On("CreateTableWithContext", mock.Anything, hasUDMColumn).Return(&glue.CreateTableOutput{}, nil).Times(3)
+	mockAthenaClient := &testutils.AthenaMock{}
+	handler.AthenaClient = mockAthenaClient
+	mockAthenaClient.On("ListTableMetadataPagesWithContext",
+		mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(4)
+
+	err = handler.HandleSQSEvent(lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{}), &event)
+	require.NoError(t, err)
+	mockGlueClient.AssertExpectations(t)
+	mockAthenaClient.AssertExpectations(t)
+}
+
+func TestSQS_CreateTablesUDMError(t *testing.T) {
+	initProcessTest()
+	handler.ListUDMFields = func(_ context.Context) (map[string][]string, error) {
+		return nil, errors.New("failed")
+	}
+
+	body := sqsTask{
+		CreateTables: &CreateTablesEvent{
+			LogTypes: []string{"AWS.VPCFlow"},
+		},
+	}
+	marshalled, err := jsoniter.Marshal(body)
+	require.NoError(t, err)
+	event := events.SQSEvent{Records: []events.SQSMessage{{Body: string(marshalled)}}}
+
+	// Tables are not created without the data model fields
+	err = handler.HandleSQSEvent(lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{}), &event)
+	require.Error(t, err)
+	mockGlueClient.AssertExpectations(t)
+}
+
 func TestSQS_Sync(t *testing.T) {
 	initProcessTest()
 
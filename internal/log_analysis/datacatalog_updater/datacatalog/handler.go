@@ -47,6 +47,14 @@ type LambdaHandler struct {
 	AthenaClient          athenaiface.AthenaAPI
 	SQSClient             sqsiface.SQSAPI
 	Logger                *zap.Logger
+	// ParquetLogTypes are the log types whose tables store data in Parquet format
+	ParquetLogTypes []string
+	// ManifestLogTypes are the log types whose tables track data files in manifests
+	ManifestLogTypes []string
+	// ListUDMFields lists the data model fields of the p_udm column in the tables of each log type.
+	// Tables of log types without data model fields do not have a p_udm column.
+	// If nil, tables do not have a p_udm column.
+	ListUDMFields func(ctx context.Context) (map[string][]string, error)
 
 	// Glue partitions known to have been created.
 	partitionsCreated map[string]struct{}
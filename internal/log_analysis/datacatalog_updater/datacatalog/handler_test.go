@@ -33,6 +33,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
 	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
@@ -111,6 +112,23 @@ func TestProcessSuccess(t *testing.T) {
 	mockGlueClient.AssertExpectations(t)
 }
 
+// nolint:lll
+func TestProcessSuccessNotificationCopy(t *testing.T) {
+	initProcessTest()
+
+	// Parquet data files are announced using gzip JSON copies, the partition should be created for the data file
+	mockGlueClient.On("GetTable", mock.Anything).Return(testGetTableOutput, nil).Once()
+	mockGlueClient.On("CreatePartition", mock.Anything).Return(&glue.CreatePartitionOutput{}, nil).Once()
+
+	const key = "logs/table/year=2020/month=02/day=26/hour=15/item.parquet"
+	assert.NoError(t, handler.HandleSQSEvent(lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{}),
+		getEvent(t, awsglue.NotificationCopyKey(key))))
+	mockGlueClient.AssertExpectations(t)
+	input := mockGlueClient.Calls[1].Arguments.Get(0).(*glue.CreatePartitionInput)
+	require.Equal(t, "s3://testbucket/logs/table/year=2020/month=02/day=26/hour=15/", aws.StringValue(input.PartitionInput.StorageDescriptor.Location))
+	require.Equal(t, awsglue.DataFormatParquet, awsglue.DataFormatFromStorageDescriptor(input.PartitionInput.StorageDescriptor))
+}
+
 // nolint:lll
 func TestProcessSuccessAlreadyCreatedPartition(t *testing.T) {
 	initProcessTest()
@@ -184,6 +202,7 @@ func initProcessTest() {
 	handler.ListAvailableLogTypes = func(_ context.Context) ([]string, error) {
 		return availableLogTypes, nil
 	}
+	handler.ListUDMFields = nil
 }
 
 func generateLogTablesMock(logTypes ...string) (tables []*glue.TableData) {
@@ -48,6 +48,10 @@ func (h *LambdaHandler) HandleS3Event(ctx context.Context, event *events.S3Event
 func (h *LambdaHandler) HandleS3EventRecord(ctx context.Context, event *events.S3EventRecord) error {
 	bucketName := event.S3.Bucket.Name
 	objectKey := event.S3.Object.Key
+	// Data files in formats other than JSON are announced using a gzip JSON copy, create the partition for the data file
+	if key, ok := awsglue.TableObjectKey(objectKey); ok {
+		objectKey = key
+	}
 	partition, err := awsglue.PartitionFromS3Object(bucketName, objectKey)
 	if err != nil {
 		lambdalogger.FromContext(ctx).Warn("invalid S3 event", zap.Any("event", event))
@@ -59,7 +63,12 @@ func (h *LambdaHandler) HandleS3EventRecord(ctx context.Context, event *events.S
 	}
 	partitionTime := partition.GetTime()
 	tableMeta := partition.GetGlueTableMetadata()
-	if _, err := tableMeta.CreateJSONPartition(h.GlueClient, partitionTime); err != nil {
+	// The log processor writes each object in the format of its table, detect it using the file extension
+	format, ok := awsglue.DataFormatFromS3Key(objectKey)
+	if !ok {
+		format = awsglue.DataFormatJSON
+	}
+	if _, err := tableMeta.CreatePartition(h.GlueClient, partitionTime, format); err != nil {
 		return errors.Wrapf(err, "cannot create partition for s3://%s/%s", bucketName, objectKey)
 	}
 
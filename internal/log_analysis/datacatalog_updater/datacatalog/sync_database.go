@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
 	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
 	"github.com/panther-labs/panther/pkg/stringset"
 )
@@ -54,6 +55,11 @@ func (h *LambdaHandler) HandleSyncDatabaseEvent(ctx context.Context, event *Sync
 		return errors.Wrap(err, "failed to update tables for deployed log types")
 	}
 
+	// Log lines that failed to classify are stored in a separate table so they can be queried and replayed
+	if err := deadletter.GlueTableMetadata().CreateOrUpdateTable(h.GlueClient, h.ProcessedDataBucket); err != nil {
+		return errors.Wrap(err, "failed to update dead letters table")
+	}
+
 	if err := h.createOrReplaceViewsForAllDeployedLogTables(ctx); err != nil {
 		return errors.Wrap(err, "failed to update athena views for deployed log types")
 	}
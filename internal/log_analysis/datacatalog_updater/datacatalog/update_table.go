@@ -36,7 +36,11 @@ func (h *LambdaHandler) HandleUpdateTablesEvent(ctx context.Context, event *Upda
 	if err != nil {
 		return err
 	}
-	tbl := tableForEntry(entry)
+	udmFields, err := h.udmFields(ctx)
+	if err != nil {
+		return err
+	}
+	tbl := h.tableForEntry(entry, udmFields[event.LogType])
 	updated, err := tbl.UpdateTableIfExists(ctx, h.GlueClient, h.ProcessedDataBucket)
 	if err != nil {
 		return err
@@ -35,9 +35,11 @@ import (
 	"github.com/panther-labs/panther/internal/compliance/snapshotlogs"
 	"github.com/panther-labs/panther/internal/core/logtypesapi"
 	"github.com/panther-labs/panther/internal/log_analysis/datacatalog_updater/datacatalog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/datamodels"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
 	"github.com/panther-labs/panther/pkg/awsretry"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
 	"github.com/panther-labs/panther/pkg/lambdalogger"
 	"github.com/panther-labs/panther/pkg/stringset"
 )
@@ -51,11 +53,13 @@ const (
 func main() {
 	// nolint: maligned
 	config := struct {
-		AthenaWorkgroup     string `required:"true" split_words:"true"`
-		SyncWorkersPerTable int    `default:"10" split_words:"true"`
-		QueueURL            string `required:"true" split_words:"true"`
-		ProcessedDataBucket string `split_words:"true"`
-		Debug               bool   `split_words:"true"`
+		AthenaWorkgroup     string   `required:"true" split_words:"true"`
+		SyncWorkersPerTable int      `default:"10" split_words:"true"`
+		QueueURL            string   `required:"true" split_words:"true"`
+		ProcessedDataBucket string   `split_words:"true"`
+		ParquetLogTypes     []string `split_words:"true"`
+		ManifestLogTypes    []string `split_words:"true"`
+		Debug               bool     `split_words:"true"`
 	}{}
 	envconfig.MustProcess("", &config)
 
@@ -83,6 +87,8 @@ func main() {
 		LambdaAPI:  lambdaClient,
 	}
 
+	analysisAPI := gatewayapi.NewClient(lambdaClient, "panther-analysis-api")
+
 	apiResolver := &logtypesapi.Resolver{
 		LogTypesAPI:    logtypesAPI,
 		NativeLogTypes: logtypes.MustMerge("native", registry.NativeLogTypes(), snapshotlogs.LogTypes()),
@@ -117,11 +123,25 @@ func main() {
 			// append in snapshot logs which are always onboarded
 			return stringset.Append(reply.LogTypes, logtypes.CollectNames(snapshotlogs.LogTypes())...), nil
 		},
-		GlueClient:   glue.New(clientsSession),
-		Resolver:     resolver,
-		AthenaClient: athena.New(clientsSession),
-		SQSClient:    sqs.New(clientsSession),
-		Logger:       logger,
+		GlueClient:       glue.New(clientsSession),
+		Resolver:         resolver,
+		AthenaClient:     athena.New(clientsSession),
+		SQSClient:        sqs.New(clientsSession),
+		Logger:           logger,
+		ParquetLogTypes:  config.ParquetLogTypes,
+		ManifestLogTypes: config.ManifestLogTypes,
+		ListUDMFields: func(ctx context.Context) (map[string][]string, error) {
+			models, err := datamodels.ListEnabled(analysisAPI)
+			if err != nil {
+				return nil, err
+			}
+			// Invalid mappings are also skipped by the log processor, so they are left out of the p_udm column
+			normalizer, err := datamodels.New(models...)
+			if err != nil {
+				lambdalogger.FromContext(ctx).Warn("invalid data model mappings", zap.Error(err))
+			}
+			return normalizer.FieldsByLogType(), nil
+		},
 	}
 
 	lambda.StartHandler(&handler)
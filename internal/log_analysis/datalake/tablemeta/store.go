@@ -0,0 +1,346 @@
+package tablemeta
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/pkg/awsutils"
+)
+
+// ErrNotFound is returned by stores when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ErrExists is returned by stores when a conditional write finds an existing object
+var ErrExists = errors.New("object already exists")
+
+// Object is an object listed in a store
+type Object struct {
+	Key          string
+	LastModified time.Time
+}
+
+// Store is the object storage for table metadata.
+//
+// Writing a single object must be atomic, readers should either see the whole object or nothing.
+// PutIfAbsent must also be atomic, of all concurrent writes to the same key exactly one succeeds.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+	// PutIfAbsent writes an object only if no object exists at key, it fails with ErrExists otherwise
+	PutIfAbsent(ctx context.Context, key string, data []byte) error
+	// List returns all objects with a key prefix in lexicographic order of their keys
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// S3Store stores objects in an S3 bucket
+type S3Store struct {
+	S3     s3iface.S3API
+	Bucket string
+}
+
+var _ Store = (*S3Store)(nil)
+
+// Get implements Store interface
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if awsutils.IsAnyError(err, s3.ErrCodeNoSuchKey) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.Wrapf(err, "failed to get s3://%s/%s", s.Bucket, key)
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+// Put implements Store interface
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.S3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	})
+	return errors.Wrapf(err, "failed to put s3://%s/%s", s.Bucket, key)
+}
+
+// PutIfAbsent implements Store interface.
+// It uses an S3 conditional write (If-None-Match: *), S3 rejects the request if the key exists
+// or if a concurrent conditional write to the same key is in progress.
+func (s *S3Store) PutIfAbsent(ctx context.Context, key string, data []byte) error {
+	_, err := s.S3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(data),
+	}, ifNoneMatchAny)
+	if err != nil {
+		if awsutils.IsAnyError(err, "PreconditionFailed", "ConditionalRequestConflict") {
+			return ErrExists
+		}
+		return errors.Wrapf(err, "failed to put s3://%s/%s", s.Bucket, key)
+	}
+	return nil
+}
+
+// The SDK does not model conditional writes for PutObject so the header is set directly
+func ifNoneMatchAny(r *request.Request) {
+	r.Handlers.Build.PushBack(func(r *request.Request) {
+		r.HTTPRequest.Header.Set("If-None-Match", "*")
+	})
+}
+
+// List implements Store interface
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	input := s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	}
+	err := s.S3.ListObjectsV2PagesWithContext(ctx, &input, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			objects = append(objects, Object{
+				Key:          aws.StringValue(obj.Key),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list s3://%s/%s", s.Bucket, prefix)
+	}
+	// S3 lists keys in UTF-8 binary order already, sorting here makes the order explicit
+	sortObjects(objects)
+	return objects, nil
+}
+
+// Delete implements Store interface.
+// All versions of the keys are deleted, so that versioned buckets do not keep deleted objects as noncurrent versions.
+func (s *S3Store) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	objects, err := s.listVersions(ctx, keys)
+	if err != nil {
+		return err
+	}
+	const maxKeysPerRequest = 1000
+	for len(objects) > 0 {
+		n := len(objects)
+		if n > maxKeysPerRequest {
+			n = maxKeysPerRequest
+		}
+		out, err := s.S3.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.Bucket),
+			Delete: &s3.Delete{
+				Objects: objects[:n],
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to delete objects in s3://%s", s.Bucket)
+		}
+		if len(out.Errors) > 0 {
+			e := out.Errors[0]
+			return errors.Errorf("failed to delete s3://%s/%s: %s", s.Bucket, aws.StringValue(e.Key), aws.StringValue(e.Message))
+		}
+		objects = objects[n:]
+	}
+	return nil
+}
+
+// listVersions lists the versions and delete markers of keys.
+// The keys are listed under their common prefix, since they are deleted together for a single table partition.
+func (s *S3Store) listVersions(ctx context.Context, keys []string) ([]*s3.ObjectIdentifier, error) {
+	prefix := keys[0]
+	deleted := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		deleted[key] = struct{}{}
+		for !strings.HasPrefix(key, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	var objects []*s3.ObjectIdentifier
+	add := func(key, versionID *string) {
+		if _, ok := deleted[aws.StringValue(key)]; ok {
+			objects = append(objects, &s3.ObjectIdentifier{Key: key, VersionId: versionID})
+		}
+	}
+	err := s.S3.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		for _, v := range page.Versions {
+			add(v.Key, v.VersionId)
+		}
+		for _, m := range page.DeleteMarkers {
+			add(m.Key, m.VersionId)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list object versions in s3://%s/%s", s.Bucket, prefix)
+	}
+	return objects, nil
+}
+
+// FSStore stores objects in a local directory.
+// It is a stand-in for S3 to use in tests and local tools.
+type FSStore struct {
+	Root string
+}
+
+var _ Store = (*FSStore)(nil)
+
+func (s *FSStore) path(key string) string {
+	return filepath.Join(s.Root, filepath.FromSlash(key))
+}
+
+// Get implements Store interface
+func (s *FSStore) Get(_ context.Context, key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Put implements Store interface
+func (s *FSStore) Put(_ context.Context, key string, data []byte) error {
+	p := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename so that readers never see partial objects
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// PutIfAbsent implements Store interface
+func (s *FSStore) PutIfAbsent(_ context.Context, key string, data []byte) error {
+	p := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// Unlike rename, link fails if the target exists
+	if err := os.Link(tmp.Name(), p); err != nil {
+		if os.IsExist(err) {
+			return ErrExists
+		}
+		return err
+	}
+	return nil
+}
+
+// List implements Store interface
+func (s *FSStore) List(_ context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.Walk(s.Root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Root, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			objects = append(objects, Object{
+				Key:          key,
+				LastModified: info.ModTime().UTC(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortObjects(objects)
+	return objects, nil
+}
+
+// Delete implements Store interface
+func (s *FSStore) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortObjects(objects []Object) {
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+}
+
+// ObjectKeys returns the keys of listed objects
+func ObjectKeys(objects []Object) []string {
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return keys
+}
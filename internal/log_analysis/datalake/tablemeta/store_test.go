@@ -0,0 +1,71 @@
+package tablemeta
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestS3StoreDelete(t *testing.T) {
+	s3Mock := &testutils.S3Mock{}
+	store := S3Store{
+		S3:     s3Mock,
+		Bucket: "bucket",
+	}
+	prefix := "logs/foo/year=2020/month=01/day=01/hour=10/"
+	s3Mock.On("ListObjectVersionsPagesWithContext", mock.Anything, &s3.ListObjectVersionsInput{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String(prefix),
+	}, mock.Anything, mock.Anything).Return(&s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String(prefix + "a.json.gz"), VersionId: aws.String("1"), IsLatest: aws.Bool(true)},
+			{Key: aws.String(prefix + "a.json.gz"), VersionId: aws.String("0")},
+			{Key: aws.String(prefix + "b.json.gz"), VersionId: aws.String("2"), IsLatest: aws.Bool(true)},
+			{Key: aws.String(prefix + "c.json.gz"), VersionId: aws.String("3"), IsLatest: aws.Bool(true)},
+		},
+		DeleteMarkers: []*s3.DeleteMarkerEntry{
+			{Key: aws.String(prefix + "b.json.gz"), VersionId: aws.String("4")},
+		},
+	}, nil).Once()
+	// All versions of the deleted keys are removed, other keys under the prefix are kept
+	s3Mock.On("DeleteObjectsWithContext", mock.Anything, &s3.DeleteObjectsInput{
+		Bucket: aws.String("bucket"),
+		Delete: &s3.Delete{
+			Objects: []*s3.ObjectIdentifier{
+				{Key: aws.String(prefix + "a.json.gz"), VersionId: aws.String("1")},
+				{Key: aws.String(prefix + "a.json.gz"), VersionId: aws.String("0")},
+				{Key: aws.String(prefix + "b.json.gz"), VersionId: aws.String("2")},
+				{Key: aws.String(prefix + "b.json.gz"), VersionId: aws.String("4")},
+			},
+			Quiet: aws.Bool(true),
+		},
+	}).Return(&s3.DeleteObjectsOutput{}, nil).Once()
+
+	require.NoError(t, store.Delete(context.Background(), prefix+"a.json.gz", prefix+"b.json.gz"))
+	require.NoError(t, store.Delete(context.Background()))
+	s3Mock.AssertExpectations(t)
+}
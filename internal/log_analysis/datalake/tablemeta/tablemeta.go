@@ -0,0 +1,529 @@
+package tablemeta
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package tablemeta implements a metadata layer for log tables inspired by Apache Iceberg manifests.
+//
+// The data files of each hourly partition are tracked by manifests stored under the table prefix:
+//
+//   <table prefix>metadata/manifests/year=YYYY/month=MM/day=DD/hour=HH/<version>.json
+//   <table prefix>metadata/pending/year=YYYY/month=MM/day=DD/hour=HH/<id>.json
+//   <table prefix>metadata/schemas/<schema id>.json
+//
+// Each commit writes the next version of the partition with a conditional write, so of all concurrent
+// commits exactly one succeeds. The others retry after a backoff, or fail with ErrConflict if the files
+// they replace are no longer live.
+// Writers that keep losing the race record their files as pending instead, and Reconcile commits them later.
+// The live files of a partition are the files added by all active manifests minus the files they remove.
+// Compaction replaces many small files with fewer large ones in a single manifest, and checkpoints
+// collapse the manifests of a partition into one.
+//
+// Manifests are bookkeeping for the maintenance tasks (compaction and retention), they are not used to
+// resolve queries. Athena still reads all objects under the Glue partition location, so files removed
+// from the manifests must also be removed from the partition location.
+//
+// Data files reference the schema they were written with, so columns can be added to a table without
+// rewriting existing partitions or files.
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+)
+
+const (
+	metadataPrefix  = "metadata/"
+	manifestsPrefix = metadataPrefix + "manifests/"
+	pendingPrefix   = metadataPrefix + "pending/"
+	schemasPrefix   = metadataPrefix + "schemas/"
+
+	// The timebin of table partitions
+	timebin = awsglue.GlueTableHourly
+
+	// maxCommitAttempts limits the retries of a commit that lost the race for a version
+	maxCommitAttempts = 10
+	// Commits that lost the race for a version wait before retrying, doubling the wait on each attempt
+	minCommitBackoff = 50 * time.Millisecond
+	maxCommitBackoff = 2 * time.Second
+)
+
+// ErrConflict is returned when a commit is based on files that are no longer live
+var ErrConflict = errors.New("table files were modified by another commit")
+
+// DataFile is a data file of a table
+type DataFile struct {
+	// Key is the S3 object key of the file
+	Key string `json:"key"`
+	// Format is the storage format of the file
+	Format awsglue.DataFormat `json:"format"`
+	// SchemaID is the id of the table schema the file was written with
+	SchemaID string `json:"schemaId,omitempty"`
+	// Rows is the number of rows in the file
+	Rows int64 `json:"rows"`
+	// Size is the size of the file in bytes
+	Size int64 `json:"size"`
+}
+
+// Manifest is an atomic change to the data files of a table partition
+type Manifest struct {
+	// Key is the object key of the manifest, it is set when manifests are read
+	Key string `json:"-"`
+	// Partition is the hour of the partition
+	Partition time.Time `json:"partition"`
+	// Version is the version of the partition committed by the manifest
+	Version int64 `json:"version"`
+	// CreatedAt is the time the manifest was committed
+	CreatedAt time.Time `json:"createdAt"`
+	// Added lists the files added to the partition
+	Added []DataFile `json:"added,omitempty"`
+	// Removed lists the keys of files removed from the partition
+	Removed []string `json:"removed,omitempty"`
+	// Supersedes lists the keys of manifests replaced by this manifest (ie by a checkpoint)
+	Supersedes []string `json:"supersedes,omitempty"`
+}
+
+// Schema is a version of the columns of a table
+type Schema struct {
+	ID      string              `json:"id"`
+	Columns []glueschema.Column `json:"columns"`
+}
+
+// SchemaID computes the id of a schema from its columns
+func SchemaID(columns []glueschema.Column) string {
+	h := sha256.New()
+	for _, col := range columns {
+		_, _ = fmt.Fprintf(h, "%s:%s;", col.Name, col.Type)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// CheckEvolution checks that the columns of a schema can be added to the table without rewriting data files.
+// Columns can be added, but existing columns cannot change type.
+func (s *Schema) CheckEvolution(columns []glueschema.Column) error {
+	types := make(map[string]glueschema.Type, len(s.Columns))
+	for _, col := range s.Columns {
+		types[col.Name] = col.Type
+	}
+	for _, col := range columns {
+		if typ, ok := types[col.Name]; ok && typ != col.Type {
+			return errors.Errorf("column %q changed type from %s to %s", col.Name, typ, col.Type)
+		}
+	}
+	return nil
+}
+
+// Table is the metadata of a table stored alongside its data
+type Table struct {
+	Store Store
+	// Prefix is the object key prefix of the table (see awsglue.TablePrefix)
+	Prefix string
+}
+
+// PutSchema stores a schema for columns and returns its id.
+// Schemas are stored by id, so storing the same schema multiple times is safe.
+func (t *Table) PutSchema(ctx context.Context, columns []glueschema.Column) (string, error) {
+	schema := Schema{
+		ID:      SchemaID(columns),
+		Columns: columns,
+	}
+	key := t.Prefix + schemasPrefix + schema.ID + ".json"
+	if _, err := t.Store.Get(ctx, key); err == nil {
+		return schema.ID, nil
+	} else if err != ErrNotFound {
+		return "", errors.Wrapf(err, "failed to check schema %s", schema.ID)
+	}
+	data, err := jsoniter.Marshal(&schema)
+	if err != nil {
+		return "", err
+	}
+	if err := t.Store.Put(ctx, key, data); err != nil {
+		return "", errors.Wrapf(err, "failed to store schema %s", schema.ID)
+	}
+	return schema.ID, nil
+}
+
+// Schema reads a schema by id
+func (t *Table) Schema(ctx context.Context, id string) (*Schema, error) {
+	data, err := t.Store.Get(ctx, t.Prefix+schemasPrefix+id+".json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read schema %s", id)
+	}
+	schema := Schema{}
+	if err := jsoniter.Unmarshal(data, &schema); err != nil {
+		return nil, errors.Wrapf(err, "invalid schema %s", id)
+	}
+	return &schema, nil
+}
+
+// AppendFiles adds data files to a partition.
+// It fails with ErrConflict if all attempts lose the race for a version, the files can then be added with AppendPending.
+func (t *Table) AppendFiles(ctx context.Context, partition time.Time, files ...DataFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	// Appends only need the latest version, reading the manifests of the partition is not required.
+	// A lost race means the version was taken, so the next one is tried without listing the partition again.
+	objects, err := t.Store.List(ctx, t.partitionPrefix(partition))
+	if err != nil {
+		return err
+	}
+	version := latestVersion(ObjectKeys(objects))
+	for attempt := 1; ; attempt++ {
+		version++
+		_, err := t.commit(ctx, version, &Manifest{
+			Partition: partition,
+			Added:     files,
+		})
+		if err != ErrExists {
+			return err
+		}
+		if attempt == maxCommitAttempts {
+			return errors.Wrapf(ErrConflict, "version %d was committed by another writer", version)
+		}
+		if err := commitBackoff(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// AppendPending records data files to be added to a partition by the next Reconcile.
+// Each call writes a new object, so unlike AppendFiles it never conflicts with other writers.
+func (t *Table) AppendPending(ctx context.Context, partition time.Time, files ...DataFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	data, err := jsoniter.Marshal(files)
+	if err != nil {
+		return err
+	}
+	key := t.pendingPrefix(partition) + uuid.New().String() + ".json"
+	if err := t.Store.Put(ctx, key, data); err != nil {
+		return errors.Wrap(err, "failed to store pending files")
+	}
+	return nil
+}
+
+// Reconcile commits the pending data files of a partition in a single manifest.
+// Files that are already live are skipped, so it is safe to call again if deleting the pending files failed.
+// It returns the number of files added.
+func (t *Table) Reconcile(ctx context.Context, partition time.Time) (int, error) {
+	objects, err := t.Store.List(ctx, t.pendingPrefix(partition))
+	if err != nil || len(objects) == 0 {
+		return 0, err
+	}
+	var pending []DataFile
+	for _, obj := range objects {
+		data, err := t.Store.Get(ctx, obj.Key)
+		if err != nil {
+			// Another reconcile may have deleted the files after they were listed
+			if err == ErrNotFound {
+				continue
+			}
+			return 0, err
+		}
+		var files []DataFile
+		if err := jsoniter.Unmarshal(data, &files); err != nil {
+			return 0, errors.Wrapf(err, "invalid pending files %q", obj.Key)
+		}
+		pending = append(pending, files...)
+	}
+	m, err := t.update(ctx, partition, func(snapshot *Snapshot) (*Manifest, error) {
+		live := make(map[string]struct{}, len(snapshot.Files))
+		for _, f := range snapshot.Files {
+			live[f.Key] = struct{}{}
+		}
+		var added []DataFile
+		for _, f := range pending {
+			if _, ok := live[f.Key]; !ok {
+				added = append(added, f)
+			}
+		}
+		if len(added) == 0 {
+			return nil, nil
+		}
+		return &Manifest{
+			Partition: partition,
+			Added:     added,
+		}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := t.Store.Delete(ctx, ObjectKeys(objects)...); err != nil {
+		return 0, err
+	}
+	if m == nil {
+		return 0, nil
+	}
+	return len(m.Added), nil
+}
+
+// ReplaceFiles replaces data files of a partition in a single commit.
+// The commit is conditional on the version of the partition the removed files were checked against.
+// It fails with ErrConflict if any of the removed files is not live.
+// The removed files are not deleted, since queries may still be reading them.
+func (t *Table) ReplaceFiles(ctx context.Context, partition time.Time, remove []string, add []DataFile) error {
+	_, err := t.update(ctx, partition, func(snapshot *Snapshot) (*Manifest, error) {
+		live := make(map[string]struct{}, len(snapshot.Files))
+		for _, f := range snapshot.Files {
+			live[f.Key] = struct{}{}
+		}
+		for _, key := range remove {
+			if _, ok := live[key]; !ok {
+				return nil, errors.Wrapf(ErrConflict, "file %q is not live", key)
+			}
+		}
+		return &Manifest{
+			Partition: partition,
+			Added:     add,
+			Removed:   remove,
+		}, nil
+	})
+	return err
+}
+
+// Checkpoint collapses all manifests of a partition to a single manifest with the live files.
+// Superseded manifests are deleted after the checkpoint is committed.
+func (t *Table) Checkpoint(ctx context.Context, partition time.Time) error {
+	m, err := t.update(ctx, partition, func(snapshot *Snapshot) (*Manifest, error) {
+		if len(snapshot.Manifests) < 2 {
+			return nil, nil
+		}
+		return &Manifest{
+			Partition:  partition,
+			Added:      snapshot.Files,
+			Supersedes: snapshot.Manifests,
+		}, nil
+	})
+	if err != nil || m == nil {
+		return err
+	}
+	return t.Store.Delete(ctx, m.Supersedes...)
+}
+
+// update commits the manifest built from the current snapshot of a partition.
+// If another commit wins the race for the next version, the manifest is built again from a new snapshot.
+// A nil manifest skips the commit.
+func (t *Table) update(ctx context.Context, partition time.Time, build func(s *Snapshot) (*Manifest, error)) (*Manifest, error) {
+	for attempt := 1; ; attempt++ {
+		snapshot, err := t.Snapshot(ctx, partition)
+		if err != nil {
+			return nil, err
+		}
+		m, err := build(snapshot)
+		if err != nil || m == nil {
+			return nil, err
+		}
+		m, err = t.commit(ctx, snapshot.Version+1, m)
+		if err != ErrExists {
+			return m, err
+		}
+		if attempt == maxCommitAttempts {
+			return nil, errors.Wrapf(ErrConflict, "version %d was committed by another writer", snapshot.Version+1)
+		}
+		if err := commitBackoff(ctx, attempt); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// commitBackoff waits before the next attempt of a commit.
+// The wait is randomized so that concurrent writers spread their attempts.
+func commitBackoff(ctx context.Context, attempt int) error {
+	wait := minCommitBackoff << (attempt - 1)
+	if wait > maxCommitBackoff || wait <= 0 {
+		wait = maxCommitBackoff
+	}
+	wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)) // nolint:gosec
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Snapshot is the state of a table partition
+type Snapshot struct {
+	Partition time.Time
+	// Version is the latest committed version of the partition, it is zero if there are no manifests
+	Version int64
+	// Files are the live data files of the partition sorted by key
+	Files []DataFile
+	// Manifests are the keys of the active manifests of the partition
+	Manifests []string
+}
+
+// Snapshot reads the current state of a partition
+func (t *Table) Snapshot(ctx context.Context, partition time.Time) (*Snapshot, error) {
+	objects, err := t.Store.List(ctx, t.partitionPrefix(partition))
+	if err != nil {
+		return nil, err
+	}
+	keys := ObjectKeys(objects)
+	manifests, err := t.readManifests(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	superseded := map[string]struct{}{}
+	for _, m := range manifests {
+		for _, key := range m.Supersedes {
+			superseded[key] = struct{}{}
+		}
+	}
+	files := map[string]DataFile{}
+	removed := map[string]struct{}{}
+	snapshot := Snapshot{
+		Partition: timebin.Truncate(partition.UTC()),
+		Version:   latestVersion(keys),
+	}
+	for _, m := range manifests {
+		if _, ok := superseded[m.Key]; ok {
+			continue
+		}
+		snapshot.Manifests = append(snapshot.Manifests, m.Key)
+		for _, f := range m.Added {
+			files[f.Key] = f
+		}
+		for _, key := range m.Removed {
+			removed[key] = struct{}{}
+		}
+	}
+	for key, f := range files {
+		if _, ok := removed[key]; ok {
+			continue
+		}
+		snapshot.Files = append(snapshot.Files, f)
+	}
+	sort.Slice(snapshot.Files, func(i, j int) bool {
+		return snapshot.Files[i].Key < snapshot.Files[j].Key
+	})
+	return &snapshot, nil
+}
+
+// Partitions lists the partitions of the table that have manifests
+func (t *Table) Partitions(ctx context.Context) ([]time.Time, error) {
+	objects, err := t.Store.List(ctx, t.Prefix+manifestsPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var partitions []time.Time
+	for _, obj := range objects {
+		tm, ok := timebin.TimeFromS3Path(strings.TrimPrefix(obj.Key, t.Prefix+manifestsPrefix))
+		if !ok {
+			continue
+		}
+		if n := len(partitions); n > 0 && partitions[n-1].Equal(tm) {
+			continue
+		}
+		partitions = append(partitions, tm)
+	}
+	return partitions, nil
+}
+
+// DropPartition removes all manifests and pending files of a partition
+func (t *Table) DropPartition(ctx context.Context, partition time.Time) error {
+	for _, prefix := range []string{t.partitionPrefix(partition), t.pendingPrefix(partition)} {
+		objects, err := t.Store.List(ctx, prefix)
+		if err != nil {
+			return err
+		}
+		if err := t.Store.Delete(ctx, ObjectKeys(objects)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) partitionPrefix(partition time.Time) string {
+	return t.Prefix + manifestsPrefix + timebin.PartitionPathS3(partition.UTC())
+}
+
+func (t *Table) pendingPrefix(partition time.Time) string {
+	return t.Prefix + pendingPrefix + timebin.PartitionPathS3(partition.UTC())
+}
+
+// commit writes a manifest as a version of its partition.
+// It returns ErrExists if the version was already committed.
+func (t *Table) commit(ctx context.Context, version int64, m *Manifest) (*Manifest, error) {
+	m.Partition = timebin.Truncate(m.Partition.UTC())
+	m.Version = version
+	m.CreatedAt = time.Now().UTC()
+	// Versions are zero padded so keys sort in commit order
+	m.Key = fmt.Sprintf("%s%020d.json", t.partitionPrefix(m.Partition), version)
+	data, err := jsoniter.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Store.PutIfAbsent(ctx, m.Key, data); err != nil {
+		if err == ErrExists {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "failed to commit manifest")
+	}
+	return m, nil
+}
+
+// latestVersion finds the latest version in the sorted manifest keys of a partition
+func latestVersion(keys []string) int64 {
+	for i := len(keys) - 1; i >= 0; i-- {
+		name := strings.TrimSuffix(path.Base(keys[i]), ".json")
+		if version, err := strconv.ParseInt(name, 10, 64); err == nil {
+			return version
+		}
+	}
+	return 0
+}
+
+func (t *Table) readManifests(ctx context.Context, keys []string) ([]*Manifest, error) {
+	manifests := make([]*Manifest, 0, len(keys))
+	for _, key := range keys {
+		data, err := t.Store.Get(ctx, key)
+		if err != nil {
+			// A checkpoint may have deleted the manifest after it was listed.
+			// The checkpoint supersedes it so it is safe to skip.
+			if err == ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		m := Manifest{}
+		if err := jsoniter.Unmarshal(data, &m); err != nil {
+			return nil, errors.Wrapf(err, "invalid manifest %q", key)
+		}
+		m.Key = key
+		manifests = append(manifests, &m)
+	}
+	return manifests, nil
+}
@@ -0,0 +1,229 @@
+package tablemeta
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+)
+
+func TestTable(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	store := &FSStore{Root: t.TempDir()}
+	table := Table{
+		Store:  store,
+		Prefix: "logs/aws_cloudtrail/",
+	}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	snapshot, err := table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Empty(snapshot.Files)
+
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(table.AppendFiles(ctx, hour.Add(time.Minute), testFile(key, 10)))
+	}
+	assert.NoError(table.AppendFiles(ctx, hour.Add(time.Hour), testFile("d", 1)))
+
+	snapshot, err = table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Equal(hour, snapshot.Partition)
+	assert.Equal([]DataFile{testFile("a", 10), testFile("b", 10), testFile("c", 10)}, snapshot.Files)
+	assert.Len(snapshot.Manifests, 3)
+
+	partitions, err := table.Partitions(ctx)
+	assert.NoError(err)
+	assert.Equal([]time.Time{hour, hour.Add(time.Hour)}, partitions)
+
+	// Compaction
+	assert.NoError(table.ReplaceFiles(ctx, hour, []string{"a", "b"}, []DataFile{testFile("ab", 20)}))
+	err = table.ReplaceFiles(ctx, hour, []string{"a", "c"}, []DataFile{testFile("ac", 20)})
+	assert.Error(err)
+	assert.Contains(err.Error(), ErrConflict.Error())
+
+	snapshot, err = table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Equal([]DataFile{testFile("ab", 20), testFile("c", 10)}, snapshot.Files)
+
+	// Checkpoint
+	assert.NoError(table.Checkpoint(ctx, hour))
+	snapshot, err = table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Equal([]DataFile{testFile("ab", 20), testFile("c", 10)}, snapshot.Files)
+	assert.Len(snapshot.Manifests, 1)
+	objects, err := store.List(ctx, table.partitionPrefix(hour))
+	assert.NoError(err)
+	assert.Equal(snapshot.Manifests, ObjectKeys(objects))
+	assert.False(objects[0].LastModified.IsZero())
+
+	// Drop
+	assert.NoError(table.DropPartition(ctx, hour))
+	partitions, err = table.Partitions(ctx)
+	assert.NoError(err)
+	assert.Equal([]time.Time{hour.Add(time.Hour)}, partitions)
+}
+
+func TestTableConcurrentCommits(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	store := &racingStore{FSStore: FSStore{Root: t.TempDir()}}
+	table := Table{
+		Store:  store,
+		Prefix: "logs/aws_cloudtrail/",
+	}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(table.AppendFiles(ctx, hour, testFile("a", 10), testFile("b", 10)))
+
+	// An append wins the race, the replace is retried on the new version
+	store.race = func() {
+		assert.NoError(table.AppendFiles(ctx, hour, testFile("c", 10)))
+	}
+	assert.NoError(table.ReplaceFiles(ctx, hour, []string{"a"}, []DataFile{testFile("aa", 10)}))
+	snapshot, err := table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Equal(int64(3), snapshot.Version)
+	assert.Equal([]DataFile{testFile("aa", 10), testFile("b", 10), testFile("c", 10)}, snapshot.Files)
+
+	// A replace of the same files wins the race, the retry finds the files are no longer live
+	store.race = func() {
+		assert.NoError(table.ReplaceFiles(ctx, hour, []string{"b"}, []DataFile{testFile("bb", 10)}))
+	}
+	err = table.ReplaceFiles(ctx, hour, []string{"b", "c"}, []DataFile{testFile("bc", 20)})
+	assert.Error(err)
+	assert.Contains(err.Error(), ErrConflict.Error())
+	snapshot, err = table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Equal(int64(4), snapshot.Version)
+	assert.Equal([]DataFile{testFile("aa", 10), testFile("bb", 10), testFile("c", 10)}, snapshot.Files)
+
+	err = store.PutIfAbsent(ctx, snapshot.Manifests[0], []byte("{}"))
+	assert.Equal(ErrExists, err)
+}
+
+func TestTableConcurrentAppends(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	table := Table{
+		Store:  &FSStore{Root: t.TempDir()},
+		Prefix: "logs/aws_cloudtrail/",
+	}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	const numWriters = 16
+	var group errgroup.Group
+	start := make(chan struct{})
+	for i := 0; i < numWriters; i++ {
+		file := testFile(fmt.Sprintf("%02d", i), 10)
+		group.Go(func() error {
+			<-start
+			// Writers that keep losing the race leave their files pending
+			if err := table.AppendFiles(ctx, hour, file); err != nil {
+				if !errors.Is(err, ErrConflict) {
+					return err
+				}
+				return table.AppendPending(ctx, hour, file)
+			}
+			return nil
+		})
+	}
+	close(start)
+	assert.NoError(group.Wait())
+	_, err := table.Reconcile(ctx, hour)
+	assert.NoError(err)
+	snapshot, err := table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Len(snapshot.Files, numWriters)
+
+	// Pending files that are already live are not added again
+	assert.NoError(table.AppendPending(ctx, hour, snapshot.Files[0], testFile("late", 10)))
+	n, err := table.Reconcile(ctx, hour)
+	assert.NoError(err)
+	assert.Equal(1, n)
+	n, err = table.Reconcile(ctx, hour)
+	assert.NoError(err)
+	assert.Equal(0, n)
+	next, err := table.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Len(next.Files, numWriters+1)
+	assert.Equal(snapshot.Version+1, next.Version)
+}
+
+// racingStore runs a concurrent commit right before the next conditional write
+type racingStore struct {
+	FSStore
+	race func()
+}
+
+func (s *racingStore) PutIfAbsent(ctx context.Context, key string, data []byte) error {
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+	return s.FSStore.PutIfAbsent(ctx, key, data)
+}
+
+func TestTableSchema(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	table := Table{
+		Store:  &FSStore{Root: t.TempDir()},
+		Prefix: "logs/aws_cloudtrail/",
+	}
+	columns := []glueschema.Column{
+		{Name: "foo", Type: glueschema.TypeString},
+	}
+	id, err := table.PutSchema(ctx, columns)
+	assert.NoError(err)
+	again, err := table.PutSchema(ctx, columns)
+	assert.NoError(err)
+	assert.Equal(id, again)
+
+	schema, err := table.Schema(ctx, id)
+	assert.NoError(err)
+	assert.Equal(columns, schema.Columns)
+
+	assert.NoError(schema.CheckEvolution([]glueschema.Column{
+		{Name: "foo", Type: glueschema.TypeString},
+		{Name: "bar", Type: glueschema.TypeBigInt},
+	}))
+	assert.Error(schema.CheckEvolution([]glueschema.Column{
+		{Name: "foo", Type: glueschema.TypeBigInt},
+	}))
+
+	_, err = table.Schema(ctx, "missing")
+	assert.Error(err)
+}
+
+func testFile(key string, rows int64) DataFile {
+	return DataFile{
+		Key:    key,
+		Format: awsglue.DataFormatJSON,
+		Rows:   rows,
+		Size:   rows * 100,
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
 	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
 )
@@ -89,9 +90,16 @@ func (vm *ViewMaker) GenerateLogViews(ctx context.Context) (sqlStatements []stri
 
 // createView creates a view over all tables in the db the using "panther" fields
 func (vm *ViewMaker) createView(ctx context.Context, databaseName, viewName string) (sql string, tables []Table, err error) {
-	tables, err = vm.tableLister.ListTables(ctx, databaseName)
+	listed, err := vm.tableLister.ListTables(ctx, databaseName)
 	if err != nil {
-		return "", tables, err
+		return "", listed, err
+	}
+	for _, table := range listed {
+		// log lines that failed to classify do not have the Panther fields of log tables
+		if table.DatabaseName() == pantherdb.LogProcessingDatabase && table.Name() == pantherdb.DeadLettersTable {
+			continue
+		}
+		tables = append(tables, table)
 	}
 	return generateView(viewName, tables), tables, err
 }
@@ -151,6 +159,10 @@ func newPantherViewColumns(tables []Table) *pantherViewColumns {
 func (pvc *pantherViewColumns) collectViewColumns(table Table) {
 	var selectColumns []string
 	for _, col := range table.Columns() {
+		// the p_udm struct has the data model fields of each log type, so it cannot be in a union of tables
+		if col.Name() == pantherlog.FieldUDMJSON {
+			continue
+		}
 		if strings.HasPrefix(col.Name(), parsers.PantherFieldPrefix) || col.IsPartition() { // only Panther columns or partitions
 			selectColumns = append(selectColumns, col.Name())
 		}
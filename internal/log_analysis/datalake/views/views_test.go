@@ -55,6 +55,7 @@ func (tc *testColumn) IsPartition() bool {
 
 type testTable struct {
 	awsglue.GlueTableMetadata
+	udmFields []string
 }
 
 func (at *testTable) Name() string {
@@ -67,6 +68,9 @@ func (at *testTable) Columns() (cols []Column) {
 	for i, col := range columns {
 		cols[i] = &testColumn{Column: col}
 	}
+	if at.udmFields != nil {
+		cols = append(cols, &testColumn{Column: awsglue.UDMColumn(at.udmFields)})
+	}
 	return cols
 }
 
@@ -86,22 +90,29 @@ func TestGenerateViewAllLogs(t *testing.T) {
 		"table1", "test table1", awsglue.GlueTableHourly, &table1Event{})}
 	var table2 = &testTable{GlueTableMetadata: *awsglue.NewGlueTableMetadata(pantherdb.LogProcessingDatabase,
 		"table2", "test table2", awsglue.GlueTableHourly, &table2Event{})}
+	// the p_udm column has a different struct type in each table and is left out of views
+	table1.udmFields = []string{"source_ip"}
+	table2.udmFields = []string{"source_ip", "username"}
+
+	// tables of log lines that failed to classify are not included in views
+	var deadLetters = &testTable{GlueTableMetadata: *awsglue.NewGlueTableMetadata(pantherdb.LogProcessingDatabase,
+		pantherdb.DeadLettersTable, "test dead letters", awsglue.GlueTableHourly, &table1Event{})}
 
 	var lister testTableLister
-	lister.tables = []Table{table1, table2}
+	lister.tables = []Table{table1, table2, deadLetters}
 
 	// nolint (lll)
 	expectedAllLogsSQL := `create or replace view panther_views.all_logs as
-select 'panther_logs' AS p_db_name,NULL AS p_any_aws_account_ids,NULL AS p_any_aws_arns,NULL AS p_any_aws_instance_ids,NULL AS p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table1
+select 'panther_logs' AS p_db_name,NULL AS p_any_aws_account_ids,NULL AS p_any_aws_arns,NULL AS p_any_aws_instance_ids,NULL AS p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_enrichment,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table1
 	union all
-select 'panther_logs' AS p_db_name,p_any_aws_account_ids,p_any_aws_arns,p_any_aws_instance_ids,p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table2
+select 'panther_logs' AS p_db_name,p_any_aws_account_ids,p_any_aws_arns,p_any_aws_instance_ids,p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_enrichment,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table2
 ;
 `
 	// nolint (lll)
 	expectedAllDatabasesSQL := `create or replace view panther_views.all_databases as
-select 'panther_logs' AS p_db_name,NULL AS p_any_aws_account_ids,NULL AS p_any_aws_arns,NULL AS p_any_aws_instance_ids,NULL AS p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table1
+select 'panther_logs' AS p_db_name,NULL AS p_any_aws_account_ids,NULL AS p_any_aws_arns,NULL AS p_any_aws_instance_ids,NULL AS p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_enrichment,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table1
 	union all
-select 'panther_logs' AS p_db_name,p_any_aws_account_ids,p_any_aws_arns,p_any_aws_instance_ids,p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table2
+select 'panther_logs' AS p_db_name,p_any_aws_account_ids,p_any_aws_arns,p_any_aws_instance_ids,p_any_aws_tags,p_any_domain_names,p_any_ip_addresses,p_any_md5_hashes,p_any_sha1_hashes,p_any_sha256_hashes,p_enrichment,p_event_time,p_log_type,p_parse_time,p_row_id,p_source_id,p_source_label from panther_logs.table2
 ;
 `
 	sqlStatements, err := NewViewMaker(&lister).GenerateLogViews(context.Background())
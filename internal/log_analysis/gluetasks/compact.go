@@ -0,0 +1,692 @@
+package gluetasks
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
+)
+
+const (
+	// DefaultCompactMinFiles is the default minimum number of files in a partition to compact it
+	DefaultCompactMinFiles = 8
+	// DefaultCompactMaxFileSize is the default size in bytes of merged files
+	DefaultCompactMaxFileSize = 64 * 1024 * 1024
+	// DefaultCompactGracePeriod is the default time to wait before merging or deleting files
+	DefaultCompactGracePeriod = time.Hour
+
+	// Merged files are written to a new directory under the hourly path with this name prefix.
+	// Athena ignores paths starting with '_' so the files are not queried until the partition location is swapped.
+	compactedDirPrefix = "_compacted-"
+	// The marker tracks the compaction of a partition while it is in progress so an interrupted run can be resumed.
+	// Athena ignores files starting with '_' so the marker is never queried.
+	compactedMarker = "_compacted.json"
+	// Same layout as the files written by the log processor
+	compactedTimestampLayout = "20060102T150405Z"
+)
+
+// CompactDatabaseTables merges the small files of hourly partitions for all tables in a database
+type CompactDatabaseTables struct {
+	// DatabaseName scans this Glue database for partitions to compact
+	DatabaseName string
+	// MatchPrefix will match tables whose name begins with this prefix
+	MatchPrefix string
+	// Start sets the start of the time range (inclusive)
+	Start time.Time
+	// End sets the end of the time range (exclusive)
+	End time.Time
+	// MinFiles is the minimum number of files in a partition to compact it
+	MinFiles int
+	// MaxFileSize is the size in bytes of merged files
+	MaxFileSize int
+	// GracePeriod is the time to wait before merging new files or deleting merged files
+	GracePeriod time.Duration
+	// NumWorkers sets the number of partitions compacted in parallel for each table
+	NumWorkers int
+	// DryRun is a flag to not modify any partitions
+	DryRun bool
+	// Stats holds the stats for all tables compacted
+	Stats CompactStats
+}
+
+// Run executes the compaction
+func (c *CompactDatabaseTables) Run(ctx context.Context, glueAPI glueiface.GlueAPI, s3API s3iface.S3API, log *zap.Logger) error {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	log = log.Named("CompactDatabaseTables").With(zap.String("database", c.DatabaseName))
+	log.Info("compaction started")
+	defer func(since time.Time) {
+		log.Info("db compaction finished", zap.Any("stats", &c.Stats), zap.Duration("duration", time.Since(since)))
+	}(time.Now())
+	input := glue.GetTablesInput{
+		DatabaseName: &c.DatabaseName,
+	}
+	if c.MatchPrefix != "" {
+		expr := c.MatchPrefix + "*"
+		input.Expression = &expr
+	}
+	var tables []*glue.TableData
+	err := glueAPI.GetTablesPagesWithContext(ctx, &input, func(page *glue.GetTablesOutput, _ bool) bool {
+		tables = append(tables, page.TableList...)
+		return true
+	})
+	if err != nil {
+		log.Error("table scan failed", zap.Error(err))
+		return err
+	}
+	// Tables are compacted one at a time, partitions of each table are compacted in parallel
+	for _, tbl := range tables {
+		task := CompactTablePartitions{
+			DatabaseName: c.DatabaseName,
+			TableName:    aws.StringValue(tbl.Name),
+			Start:        c.Start,
+			End:          c.End,
+			MinFiles:     c.MinFiles,
+			MaxFileSize:  c.MaxFileSize,
+			GracePeriod:  c.GracePeriod,
+			NumWorkers:   c.NumWorkers,
+			DryRun:       c.DryRun,
+		}
+		err := task.compactTable(ctx, glueAPI, s3API, log.With(zap.String("table", task.TableName)), tbl)
+		c.Stats.merge(&task.Stats)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompactTablePartitions merges the small files of hourly partitions into larger files.
+//
+// The log processor always writes files to the hourly path of a partition. Merged files are written to a new
+// directory under the hourly path and the partition location is swapped to it with a single Glue update,
+// so queries read either the sources or the merged files but never both.
+// Lines are copied as-is, so p_row_id and all other p_ fields are preserved.
+//
+// Files modified within the grace period are not merged, since their writers may still be adding them to the
+// table manifests. Merged sources are deleted by a later run after the grace period, so that queries started
+// before the swap can still read them.
+// Files written to the hourly path after a swap are not queried until a later run merges them with the
+// files at the partition location.
+type CompactTablePartitions struct {
+	DatabaseName string
+	TableName    string
+	// Start sets the start of the time range (inclusive)
+	Start time.Time
+	// End sets the end of the time range (exclusive)
+	End time.Time
+	// MinFiles is the minimum number of files in a partition to compact it
+	MinFiles int
+	// MaxFileSize is the size in bytes of merged files
+	MaxFileSize int
+	// GracePeriod is the time to wait before merging new files or deleting merged files
+	GracePeriod time.Duration
+	// NumWorkers sets the number of partitions compacted in parallel
+	NumWorkers int
+	// DryRun is a flag to not modify any partitions
+	DryRun bool
+	Stats  CompactStats
+}
+
+// Run executes the compaction
+func (c *CompactTablePartitions) Run(ctx context.Context, glueAPI glueiface.GlueAPI, s3API s3iface.S3API, log *zap.Logger) error {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	log = log.Named("CompactTablePartitions").With(
+		zap.String("database", c.DatabaseName),
+		zap.String("table", c.TableName),
+	)
+	tbl, err := findTable(ctx, glueAPI, c.DatabaseName, c.TableName)
+	if err != nil {
+		log.Error("table not found", zap.Error(err))
+		return err
+	}
+	return c.compactTable(ctx, glueAPI, s3API, log, tbl)
+}
+
+func (c *CompactTablePartitions) compactTable(ctx context.Context, glueAPI glueiface.GlueAPI, s3API s3iface.S3API,
+	log *zap.Logger, tbl *glue.TableData) (err error) {
+
+	bucket, tablePrefix, err := awsglue.ParseS3URL(aws.StringValue(tbl.StorageDescriptor.Location))
+	if err != nil {
+		return errors.Wrapf(err, "invalid location for table %s.%s", c.DatabaseName, c.TableName)
+	}
+	w := c.newCompactor(glueAPI, &tablemeta.S3Store{S3: s3API, Bucket: bucket}, log, tbl, tablePrefix)
+
+	defer func(since time.Time) {
+		delta := time.Since(since)
+		if err != nil {
+			log.Error("table compaction failed", zap.Error(err), zap.Duration("duration", delta), zap.Any("stats", &c.Stats))
+		} else {
+			log.Info("table compaction finished", zap.Duration("duration", delta), zap.Any("stats", &c.Stats))
+		}
+	}(time.Now())
+
+	group, ctx := errgroup.WithContext(ctx)
+	partitions := make(chan *glue.Partition)
+	group.Go(func() error {
+		defer close(partitions)
+		input := glue.GetPartitionsInput{
+			CatalogId:    tbl.CatalogId,
+			DatabaseName: tbl.DatabaseName,
+			TableName:    tbl.Name,
+		}
+		start := c.Start
+		if !start.IsZero() {
+			// Partition filters exclude the start hour
+			start = hourly.Truncate(start.UTC()).Add(-time.Hour)
+		}
+		if expr := hourly.PartitionFilter(start, c.End); expr != "" {
+			input.Expression = &expr
+		}
+		log.Info("scanning partitions")
+		return glueAPI.GetPartitionsPagesWithContext(ctx, &input, func(page *glue.GetPartitionsOutput, _ bool) bool {
+			for _, p := range page.Partitions {
+				select {
+				case partitions <- p:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		})
+	})
+	numWorkers := c.NumWorkers
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	var mu sync.Mutex
+	for i := 0; i < numWorkers; i++ {
+		group.Go(func() error {
+			for p := range partitions {
+				stats, err := w.compactPartition(ctx, p)
+				mu.Lock()
+				c.Stats.merge(stats)
+				mu.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+func (c *CompactTablePartitions) newCompactor(glueAPI glueiface.GlueAPI, store tablemeta.Store, log *zap.Logger,
+	tbl *glue.TableData, tablePrefix string) *partitionCompactor {
+
+	if !strings.HasSuffix(tablePrefix, "/") {
+		tablePrefix += "/"
+	}
+	bucket, _, _ := awsglue.ParseS3URL(aws.StringValue(tbl.StorageDescriptor.Location))
+	w := partitionCompactor{
+		glue:        glueAPI,
+		table:       tbl,
+		bucket:      bucket,
+		tablePrefix: tablePrefix,
+		store:       store,
+		minFiles:    c.MinFiles,
+		maxFileSize: c.MaxFileSize,
+		gracePeriod: c.GracePeriod,
+		dryRun:      c.DryRun,
+		log:         log,
+	}
+	if w.minFiles < 1 {
+		w.minFiles = DefaultCompactMinFiles
+	}
+	if w.maxFileSize < 1 {
+		w.maxFileSize = DefaultCompactMaxFileSize
+	}
+	if w.gracePeriod <= 0 {
+		w.gracePeriod = DefaultCompactGracePeriod
+	}
+	if awsglue.TableLayoutFromTable(tbl) == awsglue.TableLayoutManifest {
+		w.manifests = &tablemeta.Table{
+			Store:  store,
+			Prefix: tablePrefix,
+		}
+	}
+	return &w
+}
+
+type partitionCompactor struct {
+	glue        glueiface.GlueAPI
+	table       *glue.TableData
+	bucket      string
+	tablePrefix string
+	store       tablemeta.Store
+	// manifests are updated for tables with the manifest layout, it is nil for other tables
+	manifests   *tablemeta.Table
+	minFiles    int
+	maxFileSize int
+	gracePeriod time.Duration
+	dryRun      bool
+	log         *zap.Logger
+}
+
+type compactionMarker struct {
+	// Sources are the files being merged
+	Sources []string `json:"sources"`
+	// FilePrefix is the key prefix of the merged files, it is the partition location after the swap
+	FilePrefix string `json:"filePrefix"`
+	// Done is set once all merged files were written, the partition location is swapped only after that
+	Done bool `json:"done,omitempty"`
+	// Files are the merged files
+	Files []tablemeta.DataFile `json:"files,omitempty"`
+	// SwappedAt is set once the partition location was swapped, the sources are deleted after the grace period
+	SwappedAt *time.Time `json:"swappedAt,omitempty"`
+}
+
+func (w *partitionCompactor) compactPartition(ctx context.Context, p *glue.Partition) (*CompactStats, error) {
+	stats := CompactStats{}
+	tm, err := awsglue.PartitionTimeFromValues(p.Values)
+	if err != nil {
+		w.log.Warn("invalid partition values", zap.Strings("values", aws.StringValueSlice(p.Values)), zap.Error(err))
+		return &stats, nil
+	}
+	stats.observePartition(tm)
+	log := w.log.With(zap.String("partition", tm.Format(time.RFC3339)))
+	if awsglue.DataFormatFromStorageDescriptor(p.StorageDescriptor) != awsglue.DataFormatJSON {
+		log.Debug("skipping partition compaction", zap.String("reason", "format"))
+		return &stats, nil
+	}
+	_, location, err := awsglue.ParseS3URL(aws.StringValue(p.StorageDescriptor.Location))
+	if err != nil {
+		return &stats, errors.Wrapf(err, "invalid location for partition %s", tm.Format(time.RFC3339))
+	}
+	if !strings.HasSuffix(location, "/") {
+		location += "/"
+	}
+	// The marker is kept at the hourly path, since the partition location changes on every compaction
+	hourPath := w.tablePrefix + hourly.PartitionPathS3(tm)
+	if !w.dryRun {
+		// Writers leave files pending if they fail to commit them, they are added before they get merged
+		if w.manifests != nil {
+			if _, err := w.manifests.Reconcile(ctx, tm); err != nil {
+				return &stats, errors.Wrapf(err, "failed to reconcile manifests of partition %s", tm.Format(time.RFC3339))
+			}
+		}
+		ready, err := w.resume(ctx, p, tm, hourPath)
+		if err != nil {
+			return &stats, errors.Wrapf(err, "failed to resume compaction of partition %s", tm.Format(time.RFC3339))
+		}
+		if !ready {
+			log.Debug("skipping partition compaction", zap.String("reason", "gracePeriod"))
+			return &stats, nil
+		}
+	}
+	// Files at the partition location are merged again along with any new files
+	var sources []string
+	if location != hourPath {
+		objects, err := w.store.List(ctx, location)
+		if err != nil {
+			return &stats, err
+		}
+		for _, obj := range objects {
+			if !strings.HasPrefix(path.Base(obj.Key), "_") {
+				sources = append(sources, obj.Key)
+			}
+		}
+	}
+	objects, err := w.store.List(ctx, hourPath)
+	if err != nil {
+		return &stats, err
+	}
+	modifiedBefore := time.Now().Add(-w.gracePeriod)
+	numPending := 0
+	for _, obj := range objects {
+		key := obj.Key
+		if strings.HasPrefix(strings.TrimPrefix(key, hourPath), "_") {
+			continue
+		}
+		if location != hourPath && strings.HasPrefix(key, location) {
+			continue
+		}
+		if format, ok := awsglue.DataFormatFromS3Key(key); !ok || format != awsglue.DataFormatJSON {
+			log.Warn("skipping partition compaction", zap.String("reason", "unknown file"), zap.String("key", key))
+			return &stats, nil
+		}
+		// Writers add files to the manifests after they are uploaded
+		if obj.LastModified.After(modifiedBefore) {
+			continue
+		}
+		sources = append(sources, key)
+		numPending++
+	}
+	// New files are not queried after a swap until they are merged
+	minFiles := w.minFiles
+	if location != hourPath {
+		minFiles = 1
+	}
+	if numPending < minFiles {
+		return &stats, nil
+	}
+	stats.NumDiff++
+	stats.NumFilesIn += len(sources)
+	if w.dryRun {
+		log.Debug("skipping partition compaction", zap.String("reason", "dryRun"), zap.Int("numFiles", len(sources)))
+		return &stats, nil
+	}
+
+	filePrefix := fmt.Sprintf("%s%s%s-%s/", hourPath, compactedDirPrefix, time.Now().UTC().Format(compactedTimestampLayout), uuid.New())
+	marker, err := w.mergeSources(ctx, tm, hourPath, filePrefix, sources)
+	if err != nil {
+		if cleanupErr := w.rollback(ctx, hourPath, filePrefix); cleanupErr != nil {
+			log.Warn("failed to delete merged files", zap.Error(cleanupErr))
+		}
+		return &stats, errors.Wrapf(err, "failed to compact partition %s", tm.Format(time.RFC3339))
+	}
+	// The marker is complete, if this fails the next run resumes from here
+	if err := w.commit(ctx, p, tm, hourPath, marker); err != nil {
+		return &stats, errors.Wrapf(err, "failed to compact partition %s", tm.Format(time.RFC3339))
+	}
+	stats.NumCompacted++
+	for _, f := range marker.Files {
+		stats.NumFilesOut++
+		stats.NumRows += f.Rows
+		stats.NumBytes += f.Size
+	}
+	log.Debug("partition compacted", zap.Int("numFilesIn", len(sources)), zap.Int("numFilesOut", len(marker.Files)))
+	return &stats, nil
+}
+
+// mergeSources writes the merged files of a partition and returns the completed marker
+func (w *partitionCompactor) mergeSources(ctx context.Context, tm time.Time, location, filePrefix string,
+	sources []string) (*compactionMarker, error) {
+
+	marker := compactionMarker{
+		Sources:    sources,
+		FilePrefix: filePrefix,
+	}
+	if err := w.putMarker(ctx, location, &marker); err != nil {
+		return nil, err
+	}
+	files, err := w.mergeFiles(ctx, tm, sources, filePrefix)
+	if err != nil {
+		return nil, err
+	}
+	marker.Done = true
+	marker.Files = files
+	if err := w.putMarker(ctx, location, &marker); err != nil {
+		return nil, err
+	}
+	return &marker, nil
+}
+
+// commit replaces the merged sources with the merged files and swaps the partition location to them.
+// All steps are safe to repeat so an interrupted commit is completed by the next run.
+func (w *partitionCompactor) commit(ctx context.Context, p *glue.Partition, tm time.Time, hourPath string,
+	marker *compactionMarker) error {
+
+	if err := w.replaceManifestFiles(ctx, tm, marker.Sources, marker.Files); err != nil {
+		return err
+	}
+	if err := w.swapLocation(ctx, p, marker.FilePrefix); err != nil {
+		return err
+	}
+	swappedAt := time.Now().UTC()
+	marker.SwappedAt = &swappedAt
+	return w.putMarker(ctx, hourPath, marker)
+}
+
+// swapLocation points the partition to a new location in a single update
+func (w *partitionCompactor) swapLocation(ctx context.Context, p *glue.Partition, location string) error {
+	desc := *p.StorageDescriptor
+	desc.Location = aws.String(fmt.Sprintf("s3://%s/%s", w.bucket, location))
+	_, err := w.glue.UpdatePartitionWithContext(ctx, &glue.UpdatePartitionInput{
+		CatalogId:    w.table.CatalogId,
+		DatabaseName: w.table.DatabaseName,
+		TableName:    w.table.Name,
+		PartitionInput: &glue.PartitionInput{
+			LastAccessTime:    p.LastAccessTime,
+			LastAnalyzedTime:  p.LastAnalyzedTime,
+			Parameters:        p.Parameters,
+			StorageDescriptor: &desc,
+			Values:            p.Values,
+		},
+		PartitionValueList: p.Values,
+	})
+	return errors.Wrap(err, "failed to swap partition location")
+}
+
+// rollback deletes the merged files of a compaction that did not complete
+func (w *partitionCompactor) rollback(ctx context.Context, hourPath, filePrefix string) error {
+	objects, err := w.store.List(ctx, filePrefix)
+	if err != nil {
+		return err
+	}
+	return w.store.Delete(ctx, append(tablemeta.ObjectKeys(objects), hourPath+compactedMarker)...)
+}
+
+// resume completes, rolls back or cleans up the last compaction of the partition.
+// It returns false if the partition should not be compacted again until the grace period of the last swap is over.
+func (w *partitionCompactor) resume(ctx context.Context, p *glue.Partition, tm time.Time, hourPath string) (bool, error) {
+	marker, err := w.readMarker(ctx, hourPath)
+	if err != nil || marker == nil {
+		return err == nil, err
+	}
+	if !marker.Done {
+		w.log.Info("rolling back compaction", zap.String("location", hourPath))
+		return true, w.rollback(ctx, hourPath, marker.FilePrefix)
+	}
+	if marker.SwappedAt == nil {
+		w.log.Info("resuming compaction", zap.String("location", hourPath))
+		return false, w.commit(ctx, p, tm, hourPath, marker)
+	}
+	// Queries started before the swap may still be reading the sources
+	if time.Since(*marker.SwappedAt) < w.gracePeriod {
+		return false, nil
+	}
+	if err := w.store.Delete(ctx, marker.Sources...); err != nil {
+		return false, errors.Wrap(err, "failed to delete merged files")
+	}
+	return true, w.store.Delete(ctx, hourPath+compactedMarker)
+}
+
+// replaceManifestFiles commits the merged files to the table manifests.
+// Files are only replaced once, so it is safe to call again for the same compaction.
+func (w *partitionCompactor) replaceManifestFiles(ctx context.Context, tm time.Time,
+	sources []string, files []tablemeta.DataFile) error {
+
+	if w.manifests == nil || len(files) == 0 {
+		return nil
+	}
+	snapshot, err := w.manifests.Snapshot(ctx, tm)
+	if err != nil {
+		return err
+	}
+	live := make(map[string]bool, len(snapshot.Files))
+	for _, f := range snapshot.Files {
+		live[f.Key] = true
+	}
+	if live[files[0].Key] {
+		return nil
+	}
+	// Files written before the table used manifests are not tracked
+	var removed []string
+	for _, key := range sources {
+		if live[key] {
+			removed = append(removed, key)
+		}
+	}
+	return w.manifests.ReplaceFiles(ctx, tm, removed, files)
+}
+
+// mergeFiles copies the lines of gzip compressed JSON files to new files of up to maxFileSize bytes
+func (w *partitionCompactor) mergeFiles(ctx context.Context, tm time.Time, keys []string, filePrefix string) ([]tablemeta.DataFile, error) {
+	var files []tablemeta.DataFile
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	var numRows int64
+	flush := func() error {
+		if numRows == 0 {
+			return nil
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s%d%s", filePrefix, len(files), awsglue.DataFormatJSON.FileExtension())
+		if err := w.store.Put(ctx, key, buf.Bytes()); err != nil {
+			return err
+		}
+		files = append(files, tablemeta.DataFile{
+			Key:    key,
+			Format: awsglue.DataFormatJSON,
+			Rows:   numRows,
+			Size:   int64(buf.Len()),
+		})
+		buf.Reset()
+		gz.Reset(&buf)
+		numRows = 0
+		return nil
+	}
+	for _, key := range keys {
+		data, err := w.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", key)
+		}
+		lines := bufio.NewReader(r)
+		for {
+			line, err := lines.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				if _, err := gz.Write(line); err != nil {
+					return nil, err
+				}
+				if _, err := gz.Write([]byte{'\n'}); err != nil {
+					return nil, err
+				}
+				numRows++
+				if buf.Len() >= w.maxFileSize {
+					if err := flush(); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read %s", key)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// readMarker reads the compaction marker of a partition, it returns nil if there is none
+func (w *partitionCompactor) readMarker(ctx context.Context, location string) (*compactionMarker, error) {
+	data, err := w.store.Get(ctx, location+compactedMarker)
+	if err != nil {
+		if err == tablemeta.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	marker := compactionMarker{}
+	if err := jsoniter.Unmarshal(data, &marker); err != nil {
+		return nil, errors.Wrapf(err, "invalid compaction marker at %s", location)
+	}
+	return &marker, nil
+}
+
+func (w *partitionCompactor) putMarker(ctx context.Context, location string, marker *compactionMarker) error {
+	data, err := jsoniter.Marshal(marker)
+	if err != nil {
+		return err
+	}
+	return w.store.Put(ctx, location+compactedMarker, data)
+}
+
+type CompactStats struct {
+	NumPartitions    int
+	NumDiff          int
+	NumCompacted     int
+	NumFilesIn       int
+	NumFilesOut      int
+	NumRows          int64
+	NumBytes         int64
+	MinTime, MaxTime time.Time
+}
+
+func (s *CompactStats) merge(other *CompactStats) {
+	s.NumPartitions += other.NumPartitions
+	s.NumDiff += other.NumDiff
+	s.NumCompacted += other.NumCompacted
+	s.NumFilesIn += other.NumFilesIn
+	s.NumFilesOut += other.NumFilesOut
+	s.NumRows += other.NumRows
+	s.NumBytes += other.NumBytes
+	s.observeMinTime(other.MinTime)
+	s.observeMaxTime(other.MaxTime)
+}
+
+func (s *CompactStats) observePartition(tm time.Time) {
+	s.NumPartitions++
+	s.observeMinTime(tm)
+	s.observeMaxTime(tm)
+}
+
+func (s *CompactStats) observeMinTime(tm time.Time) {
+	if tm.IsZero() {
+		return
+	}
+	if s.MinTime.IsZero() || s.MinTime.After(tm) {
+		s.MinTime = tm
+	}
+}
+
+func (s *CompactStats) observeMaxTime(tm time.Time) {
+	if s.MaxTime.Before(tm) {
+		s.MaxTime = tm
+	}
+}
@@ -0,0 +1,248 @@
+package gluetasks
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestCompactPartition(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	store := &tablemeta.FSStore{Root: t.TempDir()}
+	tbl := testCompactTable()
+	tbl.Parameters = map[string]*string{
+		awsglue.TableParameterLayout: aws.String(string(awsglue.TableLayoutManifest)),
+	}
+	task := CompactTablePartitions{
+		MinFiles: 3,
+	}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	hourPath := "logs/foo/year=2020/month=01/day=01/hour=10/"
+	partition := testCompactPartition(hour, hourPath)
+	w := task.newCompactor(testSwapGlue(partition), store, zap.NewNop(), tbl, "logs/foo")
+
+	putTestFile(t, store, hourPath+"a.json.gz", `{"p_row_id":"1","p_log_type":"Foo"}`, `{"p_row_id":"2","p_log_type":"Foo"}`)
+	putTestFile(t, store, hourPath+"b.json.gz", `{"p_row_id":"3","p_log_type":"Foo"}`)
+	putTestFile(t, store, hourPath+"c.json.gz", `{"p_row_id":"4","p_log_type":"Foo"}`)
+	assert.NoError(w.manifests.AppendFiles(ctx, hour, tablemeta.DataFile{Key: hourPath + "b.json.gz"}))
+	assert.NoError(w.manifests.AppendPending(ctx, hour, tablemeta.DataFile{Key: hourPath + "c.json.gz"}))
+
+	// Files modified within the grace period are not merged, pending files are added to the manifests
+	stats, err := w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(0, stats.NumDiff)
+	snapshot, err := w.manifests.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Len(snapshot.Files, 2)
+
+	w.gracePeriod = 0
+	w.dryRun = true
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(1, stats.NumDiff)
+	assert.Equal(3, stats.NumFilesIn)
+	assert.Equal(0, stats.NumCompacted)
+
+	w.dryRun = false
+	w.gracePeriod = time.Hour
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(0, stats.NumDiff)
+	w.gracePeriod = 0
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(1, stats.NumCompacted)
+	assert.Equal(1, stats.NumFilesOut)
+	assert.Equal(int64(4), stats.NumRows)
+
+	// The partition location is swapped to the merged files
+	_, location, err := awsglue.ParseS3URL(aws.StringValue(partition.StorageDescriptor.Location))
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(location, hourPath+compactedDirPrefix), location)
+	assert.Equal([]string{
+		`{"p_row_id":"1","p_log_type":"Foo"}`,
+		`{"p_row_id":"2","p_log_type":"Foo"}`,
+		`{"p_row_id":"3","p_log_type":"Foo"}`,
+		`{"p_row_id":"4","p_log_type":"Foo"}`,
+	}, readTestLines(t, store, location))
+	keys := testKeys(t, store, location)
+	assert.Len(keys, 1)
+	snapshot, err = w.manifests.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Len(snapshot.Files, 1)
+	assert.Equal(keys[0], snapshot.Files[0].Key)
+
+	// The sources are deleted by a later run after the grace period
+	w.gracePeriod = time.Hour
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(0, stats.NumDiff)
+	assert.Len(testKeys(t, store, hourPath), 5)
+	w.gracePeriod = 0
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(0, stats.NumDiff)
+	assert.Equal(keys, testKeys(t, store, hourPath))
+
+	// Late files are merged with the files at the partition location, even if there are too few
+	putTestFile(t, store, hourPath+"d.json.gz", `{"p_row_id":"5","p_log_type":"Foo"}`)
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(1, stats.NumCompacted)
+	assert.Equal(2, stats.NumFilesIn)
+	_, next, err := awsglue.ParseS3URL(aws.StringValue(partition.StorageDescriptor.Location))
+	assert.NoError(err)
+	assert.NotEqual(location, next)
+	assert.Len(readTestLines(t, store, next), 5)
+	snapshot, err = w.manifests.Snapshot(ctx, hour)
+	assert.NoError(err)
+	assert.Len(snapshot.Files, 1)
+	assert.True(strings.HasPrefix(snapshot.Files[0].Key, next))
+}
+
+func TestCompactPartitionResume(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	store := &tablemeta.FSStore{Root: t.TempDir()}
+	task := CompactTablePartitions{
+		MinFiles: 2,
+	}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	hourPath := "logs/foo/year=2020/month=01/day=01/hour=10/"
+	partition := testCompactPartition(hour, hourPath)
+	w := task.newCompactor(testSwapGlue(partition), store, zap.NewNop(), testCompactTable(), "logs/foo/")
+	w.gracePeriod = 0
+	sources := []string{hourPath + "a.json.gz", hourPath + "b.json.gz"}
+	putTestFile(t, store, sources[0], `{"p_row_id":"1"}`)
+	putTestFile(t, store, sources[1], `{"p_row_id":"2"}`)
+
+	// A run interrupted while writing the merged files is rolled back
+	filePrefix := hourPath + compactedDirPrefix + "1/"
+	putTestFile(t, store, filePrefix+"0.json.gz", `{"p_row_id":"1"}`)
+	assert.NoError(w.putMarker(ctx, hourPath, &compactionMarker{
+		Sources:    sources,
+		FilePrefix: filePrefix,
+	}))
+	ready, err := w.resume(ctx, partition, hour, hourPath)
+	assert.NoError(err)
+	assert.True(ready)
+	assert.Equal(sources, testKeys(t, store, hourPath))
+
+	// A run interrupted before the swap is completed, the sources are deleted by the next run
+	files, err := w.mergeFiles(ctx, hour, sources[:1], filePrefix)
+	assert.NoError(err)
+	assert.NoError(w.putMarker(ctx, hourPath, &compactionMarker{
+		Sources:    sources[:1],
+		FilePrefix: filePrefix,
+		Done:       true,
+		Files:      files,
+	}))
+	stats, err := w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(0, stats.NumDiff)
+	assert.Equal("s3://bucket/"+filePrefix, aws.StringValue(partition.StorageDescriptor.Location))
+	assert.Equal([]string{`{"p_row_id":"1"}`}, readTestLines(t, store, filePrefix))
+
+	stats, err = w.compactPartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(1, stats.NumCompacted)
+	assert.NotContains(testKeys(t, store, hourPath), sources[0])
+}
+
+// testSwapGlue updates the location of the partition when the compactor swaps it
+func testSwapGlue(partition *glue.Partition) *testutils.GlueMock {
+	glueMock := &testutils.GlueMock{}
+	glueMock.On("UpdatePartitionWithContext", mock.Anything, mock.Anything).Return(&glue.UpdatePartitionOutput{}, nil).
+		Run(func(args mock.Arguments) {
+			input := args.Get(1).(*glue.UpdatePartitionInput)
+			partition.StorageDescriptor = input.PartitionInput.StorageDescriptor
+		})
+	return glueMock
+}
+
+func testCompactTable() *glue.TableData {
+	return &glue.TableData{
+		DatabaseName: aws.String("panther_logs"),
+		Name:         aws.String("foo"),
+		StorageDescriptor: &glue.StorageDescriptor{
+			Location: aws.String("s3://bucket/logs/foo/"),
+		},
+	}
+}
+
+func testCompactPartition(tm time.Time, location string) *glue.Partition {
+	desc := awsglue.DataFormatJSON.WithStorageDescriptor(&glue.StorageDescriptor{
+		Location: aws.String("s3://bucket/" + location),
+	})
+	return &glue.Partition{
+		Values:            hourly.PartitionValuesFromTime(tm),
+		StorageDescriptor: desc,
+	}
+}
+
+func putTestFile(t *testing.T, store tablemeta.Store, key string, lines ...string) {
+	buf := bytes.Buffer{}
+	w := gzip.NewWriter(&buf)
+	for _, line := range lines {
+		_, err := w.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, store.Put(context.Background(), key, buf.Bytes()))
+}
+
+func testKeys(t *testing.T, store tablemeta.Store, prefix string) []string {
+	objects, err := store.List(context.Background(), prefix)
+	require.NoError(t, err)
+	return tablemeta.ObjectKeys(objects)
+}
+
+func readTestLines(t *testing.T, store tablemeta.Store, location string) []string {
+	var lines []string
+	for _, key := range testKeys(t, store, location) {
+		if strings.HasSuffix(key, compactedMarker) {
+			continue
+		}
+		data, err := store.Get(context.Background(), key)
+		require.NoError(t, err)
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		out, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		lines = append(lines, strings.Split(strings.TrimSpace(string(out)), "\n")...)
+	}
+	return lines
+}
@@ -23,6 +23,7 @@ import (
 	goerr "errors"
 	"fmt"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -301,7 +302,7 @@ func (w *recoverWorker) recoverPartitionAt(ctx context.Context, tbl *glue.TableD
 		}
 		w.log.Info("scanning partition", zap.String("time", tm.Format("2006-01-02 15:04")))
 		// Check to see if there are data for this partition in S3
-		s3Location, err := w.findS3PartitionAt(ctx, tbl, tm)
+		s3Location, format, err := w.findS3PartitionAt(ctx, tbl, tm)
 		if err != nil {
 			// No data found, skip to the next hour
 			if errors.Is(err, errS3ObjectNotFound) {
@@ -318,6 +319,10 @@ func (w *recoverWorker) recoverPartitionAt(ctx context.Context, tbl *glue.TableD
 		w.stats.NumS3Hit++
 		// We found a partition to be recovered
 		desc := *tbl.StorageDescriptor
+		// Partitions written before a table changed format keep the format of their data
+		if format != awsglue.DataFormatFromStorageDescriptor(&desc) {
+			desc = *format.WithStorageDescriptor(&desc)
+		}
 		desc.Location = aws.String(s3Location)
 		batch.PartitionInputList = append(batch.PartitionInputList, &glue.PartitionInput{
 			StorageDescriptor: &desc,
@@ -371,42 +376,62 @@ func (w *recoverWorker) collectErrors(replyErrors []*glue.PartitionError) (err e
 
 var errS3ObjectNotFound = goerr.New("s3 object not found")
 
-func (w *recoverWorker) findS3PartitionAt(ctx context.Context, tbl *glue.TableData, tm time.Time) (string, error) {
+// findS3PartitionAt checks for data at the partition S3 location and detects their format
+func (w *recoverWorker) findS3PartitionAt(ctx context.Context, tbl *glue.TableData, tm time.Time) (string, awsglue.DataFormat, error) {
 	bin, err := awsglue.TimebinFromTable(tbl)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	bucket, tblPrefix, err := awsglue.ParseS3URL(*tbl.StorageDescriptor.Location)
 	if err != nil {
-		return "", errors.WithMessagef(err, "failed to parse S3 path for table %q", aws.StringValue(tbl.Name))
+		return "", "", errors.WithMessagef(err, "failed to parse S3 path for table %q", aws.StringValue(tbl.Name))
 	}
 	objPrefix := path.Join(tblPrefix, bin.PartitionPathS3(tm))
 	objPrefix = objPrefix + "/"
-	// We use as small number of max keys to avoid multiple calls in case there are empty objects
-	const maxKeys = 100
 	listObjectsInput := s3.ListObjectsV2Input{
-		Bucket:  &bucket,
-		Prefix:  &objPrefix,
-		MaxKeys: aws.Int64(maxKeys),
+		Bucket: &bucket,
+		Prefix: &objPrefix,
 	}
 	hasData := false
+	// Compacted partitions are located at the merged files of their latest compaction
+	location := objPrefix
+	// Objects with an unknown extension are assumed to be in the format of the table
+	format := awsglue.DataFormatFromStorageDescriptor(tbl.StorageDescriptor)
 	onPage := func(page *s3.ListObjectsV2Output, isLast bool) bool {
 		for _, obj := range page.Contents {
-			if aws.Int64Value(obj.Size) > 0 {
-				hasData = true
-				return false // Stop S3 scan iterator
+			if aws.Int64Value(obj.Size) == 0 {
+				continue
+			}
+			key := aws.StringValue(obj.Key)
+			if name := strings.TrimPrefix(key, objPrefix); strings.HasPrefix(name, compactedDirPrefix) {
+				// Compaction directories are named after the time they were written
+				if dir := objPrefix + path.Dir(name) + "/"; dir > location {
+					location = dir
+				}
+				continue
+			}
+			if strings.HasPrefix(path.Base(key), "_") {
+				continue
+			}
+			hasData = true
+			if objFormat, ok := awsglue.DataFormatFromS3Key(key); ok {
+				format = objFormat
 			}
 		}
-		return true // All objects where empty, keep looking
+		return true // Keep looking for compacted files
 	}
 	if err := w.s3.ListObjectsV2PagesWithContext(ctx, &listObjectsInput, onPage); err != nil {
-		return "", err
+		return "", "", err
+	}
+	if location != objPrefix {
+		// Only JSON partitions are compacted
+		return fmt.Sprintf("s3://%s/%s", bucket, location), awsglue.DataFormatJSON, nil
 	}
 	if !hasData {
 		// We use the well-known error to communicate the not found case
-		return "", errors.Wrapf(errS3ObjectNotFound, "no partition data for %q at %s", aws.StringValue(tbl.Name), tm)
+		return "", "", errors.Wrapf(errS3ObjectNotFound, "no partition data for %q at %s", aws.StringValue(tbl.Name), tm)
 	}
-	return fmt.Sprintf("s3://%s/%s", bucket, objPrefix), nil
+	return fmt.Sprintf("s3://%s/%s", bucket, objPrefix), format, nil
 }
 
 func buildRecoverRange(tbl *glue.TableData, start, end time.Time) (time.Time, time.Time, error) {
@@ -0,0 +1,357 @@
+package gluetasks
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
+	"github.com/panther-labs/panther/pkg/awsutils"
+)
+
+const (
+	// Archived data are stored under this prefix of the bucket, outside of all table locations
+	ArchivePrefix = "archive/"
+
+	ExpireActionDelete  = "delete"
+	ExpireActionArchive = "archive"
+
+	// S3 DeleteObjects accepts up to 1000 keys per request
+	maxDeleteObjects = 1000
+)
+
+// ExpireTablePartitions removes the hourly partitions of a table that are older than a retention period.
+//
+// The data of an expired partition are deleted from S3 or moved under ArchivePrefix with a colder storage class.
+// The bucket is versioned, so all versions of the expired objects are deleted and not just the current ones.
+// The partition is dropped from Glue only after all of its data were removed, so a failed run is resumed by the next one.
+type ExpireTablePartitions struct {
+	DatabaseName string
+	TableName    string
+	// Before expires partitions before this time (exclusive)
+	Before time.Time
+	// ArchiveStorageClass moves expired data to this S3 storage class instead of deleting them
+	ArchiveStorageClass string
+	// DryRun is a flag to only report the partitions that would expire
+	DryRun bool
+	// Report lists the expired partitions
+	Report RetentionReport
+}
+
+// RetentionReport lists the partitions removed by retention tasks
+type RetentionReport struct {
+	NumPartitions int                `json:"numPartitions"`
+	NumObjects    int                `json:"numObjects"`
+	NumBytes      int64              `json:"numBytes"`
+	Partitions    []ExpiredPartition `json:"partitions,omitempty"`
+}
+
+// ExpiredPartition is a report entry for an expired partition
+type ExpiredPartition struct {
+	Database   string    `json:"database"`
+	Table      string    `json:"table"`
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	Locations  []string  `json:"locations"`
+	NumObjects int       `json:"numObjects"`
+	NumBytes   int64     `json:"numBytes"`
+}
+
+// Merge adds the entries of another report
+func (r *RetentionReport) Merge(other *RetentionReport) {
+	r.NumPartitions += other.NumPartitions
+	r.NumObjects += other.NumObjects
+	r.NumBytes += other.NumBytes
+	r.Partitions = append(r.Partitions, other.Partitions...)
+}
+
+func (r *RetentionReport) add(p *ExpiredPartition) {
+	r.NumPartitions++
+	r.NumObjects += p.NumObjects
+	r.NumBytes += p.NumBytes
+	r.Partitions = append(r.Partitions, *p)
+}
+
+// Run executes the expiration
+func (e *ExpireTablePartitions) Run(ctx context.Context, glueAPI glueiface.GlueAPI, s3API s3iface.S3API, log *zap.Logger) error {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	log = log.Named("ExpireTablePartitions").With(
+		zap.String("database", e.DatabaseName),
+		zap.String("table", e.TableName),
+	)
+	if e.Before.IsZero() {
+		return errors.New("no retention period")
+	}
+	tbl, err := findTable(ctx, glueAPI, e.DatabaseName, e.TableName)
+	if err != nil {
+		if awsutils.IsAnyError(err, glue.ErrCodeEntityNotFoundException) {
+			log.Debug("table not found")
+			return nil
+		}
+		return err
+	}
+	bucket, tablePrefix, err := awsglue.ParseS3URL(aws.StringValue(tbl.StorageDescriptor.Location))
+	if err != nil {
+		return errors.Wrapf(err, "invalid location for table %s.%s", e.DatabaseName, e.TableName)
+	}
+	w := e.newExpirer(glueAPI, s3API, &tablemeta.S3Store{S3: s3API, Bucket: bucket}, log, tbl, bucket, tablePrefix)
+
+	defer func(since time.Time) {
+		log.Info("table expiration finished", zap.Duration("duration", time.Since(since)),
+			zap.Int("numPartitions", e.Report.NumPartitions),
+			zap.Int("numObjects", e.Report.NumObjects),
+			zap.Int64("numBytes", e.Report.NumBytes),
+			zap.Bool("dryRun", e.DryRun),
+		)
+	}(time.Now())
+
+	var partitions []*glue.Partition
+	input := glue.GetPartitionsInput{
+		CatalogId:    tbl.CatalogId,
+		DatabaseName: tbl.DatabaseName,
+		TableName:    tbl.Name,
+		Expression:   aws.String(hourly.PartitionsBefore(e.Before.UTC())),
+	}
+	err = glueAPI.GetPartitionsPagesWithContext(ctx, &input, func(page *glue.GetPartitionsOutput, _ bool) bool {
+		partitions = append(partitions, page.Partitions...)
+		return true
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to scan partitions of %s.%s", e.DatabaseName, e.TableName)
+	}
+	for _, p := range partitions {
+		entry, err := w.expirePartition(ctx, p)
+		if err != nil {
+			return err
+		}
+		if entry != nil {
+			e.Report.add(entry)
+		}
+	}
+	return nil
+}
+
+func (e *ExpireTablePartitions) newExpirer(glueAPI glueiface.GlueAPI, s3API s3iface.S3API, store tablemeta.Store, log *zap.Logger,
+	tbl *glue.TableData, bucket, tablePrefix string) *partitionExpirer {
+
+	if !strings.HasSuffix(tablePrefix, "/") {
+		tablePrefix += "/"
+	}
+	w := partitionExpirer{
+		glue:         glueAPI,
+		s3:           s3API,
+		table:        tbl,
+		bucket:       bucket,
+		tablePrefix:  tablePrefix,
+		storageClass: e.ArchiveStorageClass,
+		dryRun:       e.DryRun,
+		log:          log,
+	}
+	if awsglue.TableLayoutFromTable(tbl) == awsglue.TableLayoutManifest {
+		w.manifests = &tablemeta.Table{
+			Store:  store,
+			Prefix: tablePrefix,
+		}
+	}
+	return &w
+}
+
+type partitionExpirer struct {
+	glue        glueiface.GlueAPI
+	s3          s3iface.S3API
+	table       *glue.TableData
+	bucket      string
+	tablePrefix string
+	// manifests are dropped for tables with the manifest layout, it is nil for other tables
+	manifests    *tablemeta.Table
+	storageClass string
+	dryRun       bool
+	log          *zap.Logger
+}
+
+func (w *partitionExpirer) expirePartition(ctx context.Context, p *glue.Partition) (*ExpiredPartition, error) {
+	tm, err := awsglue.PartitionTimeFromValues(p.Values)
+	if err != nil {
+		w.log.Warn("invalid partition values", zap.Strings("values", aws.StringValueSlice(p.Values)), zap.Error(err))
+		return nil, nil
+	}
+	entry := ExpiredPartition{
+		Database: aws.StringValue(w.table.DatabaseName),
+		Table:    aws.StringValue(w.table.Name),
+		Time:     tm,
+		Action:   ExpireActionDelete,
+	}
+	if w.storageClass != "" {
+		entry.Action = ExpireActionArchive
+	}
+	// The log processor always writes to the default path, even if a partition was moved to another location
+	locations := []string{w.tablePrefix + hourly.PartitionPathS3(tm)}
+	if p.StorageDescriptor != nil {
+		_, location, err := awsglue.ParseS3URL(aws.StringValue(p.StorageDescriptor.Location))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid location for partition %s", tm.Format(time.RFC3339))
+		}
+		if !strings.HasSuffix(location, "/") {
+			location += "/"
+		}
+		if location != locations[0] {
+			locations = append(locations, location)
+		}
+	}
+	var current []*s3.ObjectVersion
+	var versions []*s3.ObjectIdentifier
+	for _, location := range locations {
+		entry.Locations = append(entry.Locations, "s3://"+w.bucket+"/"+location)
+		latest, noncurrent, err := w.listVersions(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		current = append(current, latest...)
+		versions = append(versions, noncurrent...)
+	}
+	for _, obj := range current {
+		entry.NumObjects++
+		entry.NumBytes += aws.Int64Value(obj.Size)
+		versions = append(versions, &s3.ObjectIdentifier{Key: obj.Key, VersionId: obj.VersionId})
+	}
+	if w.dryRun {
+		return &entry, nil
+	}
+
+	if w.storageClass != "" {
+		if err := w.archiveObjects(ctx, current); err != nil {
+			return nil, err
+		}
+		// Copies made by previous failed runs are left as noncurrent versions of the archived objects
+		for _, location := range locations {
+			_, noncurrent, err := w.listVersions(ctx, ArchivePrefix+location)
+			if err != nil {
+				return nil, err
+			}
+			if err := w.deleteObjects(ctx, noncurrent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := w.deleteObjects(ctx, versions); err != nil {
+		return nil, err
+	}
+	if w.manifests != nil {
+		if err := w.manifests.DropPartition(ctx, tm); err != nil {
+			return nil, errors.Wrapf(err, "failed to drop manifests of partition %s", tm.Format(time.RFC3339))
+		}
+	}
+	_, err = w.glue.DeletePartitionWithContext(ctx, &glue.DeletePartitionInput{
+		CatalogId:       w.table.CatalogId,
+		DatabaseName:    w.table.DatabaseName,
+		TableName:       w.table.Name,
+		PartitionValues: p.Values,
+	})
+	if err != nil && !awsutils.IsAnyError(err, glue.ErrCodeEntityNotFoundException) {
+		return nil, errors.Wrapf(err, "failed to drop partition %s", tm.Format(time.RFC3339))
+	}
+	w.log.Debug("partition expired", zap.Time("partition", tm), zap.String("action", entry.Action),
+		zap.Int("numObjects", entry.NumObjects))
+	return &entry, nil
+}
+
+// listVersions lists the objects under a prefix.
+// It returns the current versions of the objects and the identifiers of all noncurrent versions and delete markers.
+func (w *partitionExpirer) listVersions(ctx context.Context, prefix string) ([]*s3.ObjectVersion, []*s3.ObjectIdentifier, error) {
+	var current []*s3.ObjectVersion
+	var noncurrent []*s3.ObjectIdentifier
+	err := w.s3.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(w.bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		for _, v := range page.Versions {
+			if aws.BoolValue(v.IsLatest) {
+				current = append(current, v)
+				continue
+			}
+			noncurrent = append(noncurrent, &s3.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range page.DeleteMarkers {
+			noncurrent = append(noncurrent, &s3.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to list object versions at %s", prefix)
+	}
+	return current, noncurrent, nil
+}
+
+// archiveObjects copies objects under the archive prefix with the archive storage class
+func (w *partitionExpirer) archiveObjects(ctx context.Context, objects []*s3.ObjectVersion) error {
+	for _, obj := range objects {
+		key := aws.StringValue(obj.Key)
+		_, err := w.s3.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+			Bucket:       aws.String(w.bucket),
+			Key:          aws.String(ArchivePrefix + key),
+			CopySource:   aws.String(url.PathEscape(w.bucket + "/" + key)),
+			StorageClass: aws.String(w.storageClass),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to archive %s", key)
+		}
+	}
+	return nil
+}
+
+// deleteObjects permanently deletes object versions
+func (w *partitionExpirer) deleteObjects(ctx context.Context, objects []*s3.ObjectIdentifier) error {
+	for len(objects) > 0 {
+		n := len(objects)
+		if n > maxDeleteObjects {
+			n = maxDeleteObjects
+		}
+		batch := objects[:n]
+		objects = objects[n:]
+		reply, err := w.s3.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(w.bucket),
+			Delete: &s3.Delete{
+				Objects: batch,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to delete expired objects")
+		}
+		if len(reply.Errors) > 0 {
+			e := reply.Errors[0]
+			return errors.Errorf("failed to delete %s: %s", aws.StringValue(e.Key), aws.StringValue(e.Message))
+		}
+	}
+	return nil
+}
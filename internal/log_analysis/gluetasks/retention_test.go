@@ -0,0 +1,198 @@
+package gluetasks
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestExpirePartition(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	store := &tablemeta.FSStore{Root: t.TempDir()}
+	glueMock := &testutils.GlueMock{}
+	s3Mock := &testutils.S3Mock{}
+	tbl := testCompactTable()
+	tbl.Parameters = map[string]*string{
+		awsglue.TableParameterLayout: aws.String(string(awsglue.TableLayoutManifest)),
+	}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	defaultLocation := "logs/foo/year=2020/month=01/day=01/hour=10/"
+	location := "logs/foo/moved/year=2020/month=01/day=01/hour=10/"
+	partition := testCompactPartition(hour, location)
+
+	s3Mock.On("ListObjectVersionsPagesWithContext", mock.Anything, &s3.ListObjectVersionsInput{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String(defaultLocation),
+	}, mock.Anything, mock.Anything).Return(&s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String(defaultLocation + "c.json.gz"), VersionId: aws.String("c2"), IsLatest: aws.Bool(true), Size: aws.Int64(10)},
+			{Key: aws.String(defaultLocation + "c.json.gz"), VersionId: aws.String("c1"), IsLatest: aws.Bool(false), Size: aws.Int64(10)},
+		},
+		DeleteMarkers: []*s3.DeleteMarkerEntry{
+			{Key: aws.String(defaultLocation + "d.json.gz"), VersionId: aws.String("d2"), IsLatest: aws.Bool(true)},
+		},
+	}, nil)
+	s3Mock.On("ListObjectVersionsPagesWithContext", mock.Anything, &s3.ListObjectVersionsInput{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String(location),
+	}, mock.Anything, mock.Anything).Return(&s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String(location + "_compacted.json"), VersionId: aws.String("m1"), IsLatest: aws.Bool(true), Size: aws.Int64(20)},
+			{Key: aws.String(location + "ab.json.gz"), VersionId: aws.String("ab1"), IsLatest: aws.Bool(true), Size: aws.Int64(30)},
+		},
+	}, nil)
+
+	task := ExpireTablePartitions{
+		ArchiveStorageClass: s3.StorageClassGlacier,
+		DryRun:              true,
+	}
+	w := task.newExpirer(glueMock, s3Mock, store, zap.NewNop(), tbl, "bucket", "logs/foo")
+	assert.NoError(w.manifests.AppendFiles(ctx, hour, tablemeta.DataFile{Key: location + "ab.json.gz"}))
+
+	// Dry run only reports the partition
+	entry, err := w.expirePartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(&ExpiredPartition{
+		Database: "panther_logs",
+		Table:    "foo",
+		Time:     hour,
+		Action:   ExpireActionArchive,
+		Locations: []string{
+			"s3://bucket/" + defaultLocation,
+			"s3://bucket/" + location,
+		},
+		NumObjects: 3,
+		NumBytes:   60,
+	}, entry)
+	s3Mock.AssertNotCalled(t, "CopyObjectWithContext", mock.Anything, mock.Anything)
+	s3Mock.AssertNotCalled(t, "DeleteObjectsWithContext", mock.Anything, mock.Anything)
+	glueMock.AssertExpectations(t)
+
+	w.dryRun = false
+	s3Mock.On("CopyObjectWithContext", mock.Anything, mock.Anything).Return(&s3.CopyObjectOutput{}, nil).Times(3)
+	// A previous run already archived c.json.gz
+	s3Mock.On("ListObjectVersionsPagesWithContext", mock.Anything, &s3.ListObjectVersionsInput{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String(ArchivePrefix + defaultLocation),
+	}, mock.Anything, mock.Anything).Return(&s3.ListObjectVersionsOutput{
+		Versions: []*s3.ObjectVersion{
+			{Key: aws.String(ArchivePrefix + defaultLocation + "c.json.gz"), VersionId: aws.String("a2"), IsLatest: aws.Bool(true)},
+			{Key: aws.String(ArchivePrefix + defaultLocation + "c.json.gz"), VersionId: aws.String("a1"), IsLatest: aws.Bool(false)},
+		},
+	}, nil).Once()
+	s3Mock.On("ListObjectVersionsPagesWithContext", mock.Anything, &s3.ListObjectVersionsInput{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String(ArchivePrefix + location),
+	}, mock.Anything, mock.Anything).Return(&s3.ListObjectVersionsOutput{}, nil).Once()
+	s3Mock.On("DeleteObjectsWithContext", mock.Anything, mock.Anything).Return(&s3.DeleteObjectsOutput{}, nil).Twice()
+	glueMock.On("DeletePartitionWithContext", mock.Anything, &glue.DeletePartitionInput{
+		DatabaseName:    aws.String("panther_logs"),
+		TableName:       aws.String("foo"),
+		PartitionValues: partition.Values,
+	}).Return(&glue.DeletePartitionOutput{}, nil).Once()
+	entry, err = w.expirePartition(ctx, partition)
+	assert.NoError(err)
+	assert.Equal(3, entry.NumObjects)
+	s3Mock.AssertExpectations(t)
+	glueMock.AssertExpectations(t)
+
+	var archived []string
+	var deleted [][]string
+	for _, call := range s3Mock.Calls {
+		switch call.Method {
+		case "CopyObjectWithContext":
+			input := call.Arguments.Get(1).(*s3.CopyObjectInput)
+			assert.Equal(s3.StorageClassGlacier, aws.StringValue(input.StorageClass))
+			archived = append(archived, aws.StringValue(input.Key))
+		case "DeleteObjectsWithContext":
+			input := call.Arguments.Get(1).(*s3.DeleteObjectsInput)
+			var versions []string
+			for _, obj := range input.Delete.Objects {
+				versions = append(versions, aws.StringValue(obj.Key)+"@"+aws.StringValue(obj.VersionId))
+			}
+			deleted = append(deleted, versions)
+		}
+	}
+	assert.Equal([]string{
+		ArchivePrefix + defaultLocation + "c.json.gz",
+		ArchivePrefix + location + "_compacted.json",
+		ArchivePrefix + location + "ab.json.gz",
+	}, archived)
+	// All versions and delete markers are deleted
+	assert.Equal([][]string{
+		{
+			ArchivePrefix + defaultLocation + "c.json.gz@a1",
+		},
+		{
+			defaultLocation + "c.json.gz@c1",
+			defaultLocation + "d.json.gz@d2",
+			defaultLocation + "c.json.gz@c2",
+			location + "_compacted.json@m1",
+			location + "ab.json.gz@ab1",
+		},
+	}, deleted)
+
+	partitions, err := w.manifests.Partitions(ctx)
+	assert.NoError(err)
+	assert.Empty(partitions)
+}
+
+func TestExpirePartitionDeleteFailure(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	glueMock := &testutils.GlueMock{}
+	s3Mock := &testutils.S3Mock{}
+	hour := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	defaultLocation := "logs/foo/year=2020/month=01/day=01/hour=10/"
+	s3Mock.On("ListObjectVersionsPagesWithContext", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+		&s3.ListObjectVersionsOutput{
+			Versions: []*s3.ObjectVersion{
+				{Key: aws.String(defaultLocation + "a.json.gz"), IsLatest: aws.Bool(true), Size: aws.Int64(10)},
+			},
+		}, nil)
+	s3Mock.On("DeleteObjectsWithContext", mock.Anything, mock.Anything).Return(&s3.DeleteObjectsOutput{
+		Errors: []*s3.Error{
+			{Key: aws.String(defaultLocation + "a.json.gz"), Message: aws.String("Access Denied")},
+		},
+	}, nil).Once()
+
+	task := ExpireTablePartitions{}
+	w := task.newExpirer(glueMock, s3Mock, nil, zap.NewNop(), testCompactTable(), "bucket", "logs/foo/")
+	assert.Nil(w.manifests)
+
+	// The partition is kept if its data could not be deleted
+	_, err := w.expirePartition(ctx, testCompactPartition(hour, defaultLocation))
+	assert.Error(err)
+	s3Mock.AssertExpectations(t)
+	glueMock.AssertNotCalled(t, "DeletePartitionWithContext", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,88 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/http_receiver/receiver"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/metrics"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/registry"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+var httpReceiver *receiver.Receiver
+
+func main() {
+	common.Setup()
+	resolver := &logtypesapi.Resolver{
+		LogTypesAPI: &logtypesapi.LogTypesAPILambdaClient{
+			LambdaName: logtypesapi.LambdaName,
+			LambdaAPI:  common.LambdaClient,
+			Validate:   validator.New().Struct,
+		},
+		NativeLogTypes: registry.NativeLogTypes(),
+	}
+	deadLetters := &deadletter.Writer{
+		S3Uploader: s3manager.NewUploaderWithClient(common.S3Client),
+		SNSClient:  common.SnsClient,
+		Bucket:     common.Config.ProcessedDataBucket,
+		TopicARN:   common.Config.SnsTopicARN,
+	}
+	// Events are enriched, normalized and redacted the same way as events read from S3
+	extensions := processor.NewExtensions()
+	httpReceiver = receiver.New(func(ctx context.Context) (processor.Factory, error) {
+		// Events must not be stored without redacting personal data, senders will retry the request
+		redactor, err := extensions.Redactor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		enricher, normalizer := extensions.Load(ctx)
+		factory := processor.NewFactoryWithDeadLetters(logtypes.ParserResolver(resolver), deadLetters, redactor)
+		return factory.WithEnricher(enricher).WithNormalizer(normalizer), nil
+	})
+	lambda.Start(handle)
+}
+
+func handle(ctx context.Context, req *events.APIGatewayProxyRequest) (resp *events.APIGatewayProxyResponse, err error) {
+	lc, _ := lambdalogger.ConfigureGlobal(ctx, nil)
+	operation := common.OpLogManager.Start(lc.InvokedFunctionArn, common.OpLogLambdaServiceDim).WithMemUsed(lambdacontext.MemoryLimitInMB)
+	defer func() {
+		operation.Stop().Log(err)
+	}()
+	defer func() {
+		// Force syncing metrics at the end of the invocation
+		if err := metrics.CWManager.Sync(); err != nil {
+			zap.L().Warn("failed to sync metrics", zap.Error(err))
+		}
+	}()
+	return httpReceiver.Handle(ctx, req)
+}
@@ -0,0 +1,89 @@
+package receiver
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/sources"
+)
+
+// Receiver handles requests sent to HTTP push sources.
+// The request body is processed synchronously and the events are written to the data lake
+// before a response is returned to the sender.
+type Receiver struct {
+	// NewProcessor returns the processor factory for a request
+	NewProcessor   func(ctx context.Context) (processor.Factory, error)
+	NewDestination func() destinations.Destination
+	// ReadRequest converts a request to data streams, used to simplify mocking during testing
+	ReadRequest func(ctx context.Context, req *events.APIGatewayProxyRequest) ([]*common.DataStream, error)
+}
+
+// New creates a receiver that writes events to the processed data bucket.
+func New(newProcessor func(ctx context.Context) (processor.Factory, error)) *Receiver {
+	return &Receiver{
+		NewProcessor: newProcessor,
+		NewDestination: func() destinations.Destination {
+			return destinations.CreateS3Destination(common.ConfigForDataLakeWriters())
+		},
+		ReadRequest: sources.ReadHTTPPushRequest,
+	}
+}
+
+func (r *Receiver) Handle(ctx context.Context, req *events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if req.HTTPMethod != http.MethodPost {
+		return &events.APIGatewayProxyResponse{StatusCode: http.StatusMethodNotAllowed}, nil
+	}
+	streams, err := r.ReadRequest(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, sources.ErrHTTPPushUnauthorized):
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusUnauthorized}, nil
+		case errors.Is(err, sources.ErrHTTPPushInvalidBody):
+			zap.L().Warn("failed to read HTTP push request", zap.Error(err))
+			return &events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest}, nil
+		default:
+			// Returning an error allows senders to retry the request
+			return nil, errors.Wrap(err, "failed to read HTTP push request")
+		}
+	}
+	newProcessor, err := r.NewProcessor(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create log processor")
+	}
+
+	streamChan := make(chan *common.DataStream, len(streams))
+	for _, s := range streams {
+		streamChan <- s
+	}
+	close(streamChan)
+	if err := processor.Process(ctx, streamChan, r.NewDestination(), newProcessor); err != nil {
+		// Returning an error allows senders to retry the request
+		return nil, errors.Wrap(err, "failed to process HTTP push request")
+	}
+	return &events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+}
@@ -0,0 +1,77 @@
+package receiver
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/sources"
+)
+
+type discardDestination struct{}
+
+func (discardDestination) SendEvents(_ context.Context, results chan *parsers.Result, _ chan error) {
+	for range results {
+		// Events are discarded
+	}
+}
+
+func TestReceiverStatusCodes(t *testing.T) {
+	newReceiver := func(readErr error) *Receiver {
+		return &Receiver{
+			NewProcessor: func(_ context.Context) (processor.Factory, error) {
+				return nil, errors.New("unexpected call")
+			},
+			NewDestination: func() destinations.Destination {
+				return discardDestination{}
+			},
+			ReadRequest: func(_ context.Context, _ *events.APIGatewayProxyRequest) ([]*common.DataStream, error) {
+				return nil, readErr
+			},
+		}
+	}
+	req := &events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost}
+
+	resp, err := newReceiver(sources.ErrHTTPPushUnauthorized).Handle(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = newReceiver(sources.ErrHTTPPushInvalidBody).Handle(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Internal failures return an error so that senders retry the request
+	resp, err = newReceiver(errors.New("failed to load source")).Handle(context.Background(), req)
+	require.Error(t, err)
+	require.Nil(t, resp)
+
+	resp, err = newReceiver(nil).Handle(context.Background(), &events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
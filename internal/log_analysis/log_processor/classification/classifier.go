@@ -53,12 +53,37 @@ type ClassifierResult struct {
 
 // NewClassifier returns a new instance of a ClassifierAPI implementation
 func NewClassifier(parsers map[string]parsers.Interface) ClassifierAPI {
+	return NewClassifierWithHints(parsers, Hints{})
+}
+
+// NewClassifierWithHints returns a new instance of a ClassifierAPI implementation that uses hints for all log lines
+func NewClassifierWithHints(parsers map[string]parsers.Interface, hints Hints) ClassifierAPI {
 	return &Classifier{
 		parsers:     NewParserPriorityQueue(parsers),
 		parserStats: make(map[string]*ParserStats),
+		hints:       hints,
 	}
 }
 
+// Hints guide the classification of a stream of log lines
+type Hints struct {
+	// LogTypes are tried in order before any other log type.
+	// If none of them matches a log line, the rest of the log types are tried in priority order.
+	LogTypes []string
+	// Sticky pins the log type of the first classified line for the rest of the stream.
+	// Lines that do not match the pinned log type fail to classify.
+	Sticky bool
+}
+
+// HintedClassifier is a classifier that accepts hints for each log line
+type HintedClassifier interface {
+	ClassifierAPI
+	// ClassifyWithHints attempts to classify the provided log line trying the hinted log types first
+	ClassifyWithHints(log string, logTypes ...string) (*ClassifierResult, error)
+}
+
+var _ HintedClassifier = (*Classifier)(nil)
+
 // Classifier is the struct responsible for classifying logs
 type Classifier struct {
 	parsers *ParserPriorityQueue
@@ -66,6 +91,11 @@ type Classifier struct {
 	stats ClassifierStats
 	// per-parser stats, map of LogType -> stats
 	parserStats map[string]*ParserStats
+	hints       Hints
+	// pinned is the parser used for all lines in sticky mode
+	pinned *ParserQueueItem
+	// lastLogType is the log type of the last classified line
+	lastLogType string
 }
 
 func (c *Classifier) Stats() *ClassifierStats {
@@ -93,6 +123,12 @@ func safeLogParse(logType string, parser parsers.Interface, log string) (results
 
 // Classify attempts to classify the provided log line
 func (c *Classifier) Classify(log string) (*ClassifierResult, error) {
+	return c.ClassifyWithHints(log)
+}
+
+// ClassifyWithHints attempts to classify the provided log line.
+// The hinted log types are tried after the log types of the classifier hints.
+func (c *Classifier) ClassifyWithHints(log string, logTypes ...string) (*ClassifierResult, error) {
 	startClassify := time.Now().UTC()
 	// Slice containing the popped queue items
 	var popped []interface{}
@@ -102,6 +138,12 @@ func (c *Classifier) Classify(log string) (*ClassifierResult, error) {
 		return result, nil
 	}
 
+	var (
+		hinted      bool
+		matchedHint bool
+		logType     string
+	)
+
 	// update aggregate stats
 	defer func() {
 		c.stats.ClassifyTimeMicroseconds = uint64(time.Since(startClassify).Microseconds())
@@ -110,6 +152,17 @@ func (c *Classifier) Classify(log string) (*ClassifierResult, error) {
 		if result.Matched {
 			c.stats.SuccessfullyClassifiedCount++
 			c.stats.EventCount += uint64(len(result.Events))
+			if hinted {
+				if matchedHint {
+					c.stats.HintMatchCount++
+				} else {
+					c.stats.HintMissCount++
+				}
+			}
+			if c.lastLogType != "" && c.lastLogType != logType {
+				c.stats.LogTypeChangeCount++
+			}
+			c.lastLogType = logType
 		} else if result.NumMiss != 0 {
 			c.stats.ClassificationFailureCount++
 		}
@@ -121,49 +174,58 @@ func (c *Classifier) Classify(log string) (*ClassifierResult, error) {
 		return result, nil
 	}
 
-	for c.parsers.Len() > 0 {
-		currentItem := c.parsers.Peek()
+	// Collects the errors of the parsers that failed so that unclassified lines can be troubleshot
+	failed := &ClassificationError{}
 
-		startParseTime := time.Now().UTC()
-		logType := currentItem.logType
-		parsedEvents, err := safeLogParse(logType, currentItem.parser, log)
-		endParseTime := time.Now().UTC()
+	// In sticky mode only the pinned parser is used once a line has been classified
+	if c.pinned != nil {
+		logType = c.pinned.logType
+		if !c.tryParse(c.pinned, log, result, failed) {
+			return result, failed
+		}
+		return result, nil
+	}
 
-		// Parser failed to parse event
-		if err != nil {
-			zap.L().Debug("failed to parse event", zap.String("expectedLogType", logType), zap.Error(err))
-			// Removing parser from queue
+	// Try the hinted parsers first, remembering which ones failed so they are not retried
+	var tried []*ParserQueueItem
+	for _, hint := range [][]string{c.hints.LogTypes, logTypes} {
+		for _, name := range hint {
+			i := c.parsers.Find(name)
+			if i == -1 {
+				continue
+			}
+			hinted = true
+			item := c.parsers.items[i]
+			if containsItem(tried, item) {
+				continue
+			}
+			matched := c.tryParse(item, log, result, failed)
+			// Restore the heap order since the penalty of the item has changed
+			heap.Fix(c.parsers, i)
+			if matched {
+				matchedHint, logType = true, name
+				break
+			}
+			tried = append(tried, item)
+		}
+		if result.Matched {
+			break
+		}
+	}
+
+	for !result.Matched && c.parsers.Len() > 0 {
+		currentItem := c.parsers.Peek()
+		if containsItem(tried, currentItem) {
 			popped = append(popped, heap.Pop(c.parsers))
-			// Increasing penalty of the parser
-			// Due to increased penalty the parser will be lower priority in the queue
-			currentItem.penalty++
-			// Increment the number of misses in the result
-			result.NumMiss++
-			// record failure
 			continue
 		}
-		result.Matched = true
-
-		// Since the parsing was successful, remove all penalty from the parser
-		// The parser will be higher priority in the queue
-		currentItem.penalty = 0
-		result.Events = parsedEvents
-
-		// update per-parser stats
-		var parserStat *ParserStats
-		var parserStatExists bool
-		// lazy create
-		if parserStat, parserStatExists = c.parserStats[logType]; !parserStatExists {
-			parserStat = &ParserStats{
-				LogType: logType,
-			}
-			c.parserStats[logType] = parserStat
+		if !c.tryParse(currentItem, log, result, failed) {
+			// Removing parser from queue
+			// Due to increased penalty the parser will be lower priority in the queue
+			popped = append(popped, heap.Pop(c.parsers))
+			continue
 		}
-		parserStat.ParserTimeMicroseconds += uint64(endParseTime.Sub(startParseTime).Microseconds())
-		parserStat.BytesProcessedCount += uint64(len(log))
-		parserStat.LogLineCount++
-		parserStat.EventCount += uint64(len(result.Events))
-		break
+		logType = currentItem.logType
 	}
 
 	// Put back the popped items to the ParserPriorityQueue.
@@ -171,11 +233,82 @@ func (c *Classifier) Classify(log string) (*ClassifierResult, error) {
 		heap.Push(c.parsers, item)
 	}
 	if !result.Matched {
-		return result, errors.New("failed to classify log line")
+		return result, failed
+	}
+	if c.hints.Sticky {
+		c.pinned = c.parsers.items[c.parsers.Find(logType)]
 	}
 	return result, nil
 }
 
+// tryParse parses a log line with the parser of a queue item and updates the result and the stats.
+// Parser errors are recorded in failed.
+func (c *Classifier) tryParse(item *ParserQueueItem, log string, result *ClassifierResult, failed *ClassificationError) bool {
+	startParseTime := time.Now().UTC()
+	logType := item.logType
+	parsedEvents, err := safeLogParse(logType, item.parser, log)
+	endParseTime := time.Now().UTC()
+
+	// Parser failed to parse event
+	if err != nil {
+		zap.L().Debug("failed to parse event", zap.String("expectedLogType", logType), zap.Error(err))
+		// Increasing penalty of the parser
+		item.penalty++
+		// Increment the number of misses in the result
+		result.NumMiss++
+		failed.add(logType, err)
+		return false
+	}
+	result.Matched = true
+
+	// Since the parsing was successful, remove all penalty from the parser
+	// The parser will be higher priority in the queue
+	item.penalty = 0
+	result.Events = parsedEvents
+
+	// update per-parser stats
+	var parserStat *ParserStats
+	var parserStatExists bool
+	// lazy create
+	if parserStat, parserStatExists = c.parserStats[logType]; !parserStatExists {
+		parserStat = &ParserStats{
+			LogType: logType,
+		}
+		c.parserStats[logType] = parserStat
+	}
+	parserStat.ParserTimeMicroseconds += uint64(endParseTime.Sub(startParseTime).Microseconds())
+	parserStat.BytesProcessedCount += uint64(len(log))
+	parserStat.LogLineCount++
+	parserStat.EventCount += uint64(len(result.Events))
+	return true
+}
+
+// ClassificationError is returned when a log line fails to classify
+type ClassificationError struct {
+	// ParserErrors maps the log types that were tried to the error of their parser
+	ParserErrors map[string]string
+}
+
+func (e *ClassificationError) Error() string {
+	return "failed to classify log line"
+}
+
+func (e *ClassificationError) add(logType string, err error) {
+	if e.ParserErrors == nil {
+		e.ParserErrors = make(map[string]string)
+	}
+	e.ParserErrors[logType] = err.Error()
+}
+
+func containsItem(items []*ParserQueueItem, item *ParserQueueItem) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
 // aggregate stats
 type ClassifierStats struct {
 	ClassifyTimeMicroseconds    uint64 // total time parsing
@@ -184,6 +317,15 @@ type ClassifierStats struct {
 	EventCount                  uint64 // output records
 	SuccessfullyClassifiedCount uint64
 	ClassificationFailureCount  uint64
+	// Lines with hints classified as one of the hinted log types
+	HintMatchCount uint64
+	// Lines with hints classified as a log type that was not hinted, these are likely misclassified
+	HintMissCount uint64
+	// Lines classified as a different log type than the previous line.
+	// For streams of a single log type this counts lines misclassified as a similar format.
+	LogTypeChangeCount uint64
+	// Events dropped by event filters before they were stored
+	FilteredEventCount uint64
 }
 
 func (s *ClassifierStats) Add(other *ClassifierStats) {
@@ -193,6 +335,10 @@ func (s *ClassifierStats) Add(other *ClassifierStats) {
 	s.SuccessfullyClassifiedCount += other.EventCount
 	s.LogLineCount += other.LogLineCount
 	s.ClassificationFailureCount += other.ClassificationFailureCount
+	s.HintMatchCount += other.HintMatchCount
+	s.HintMissCount += other.HintMissCount
+	s.LogTypeChangeCount += other.LogTypeChangeCount
+	s.FilteredEventCount += other.FilteredEventCount
 }
 
 // per parser stats
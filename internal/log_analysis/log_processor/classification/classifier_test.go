@@ -129,6 +129,9 @@ func TestClassifyNoMatch(t *testing.T) {
 
 	result, err := classifier.Classify(logLine)
 	require.Error(t, err)
+	require.Equal(t, &ClassificationError{
+		ParserErrors: map[string]string{"failure": "fail"},
+	}, err)
 
 	// skipping specifically validating the times
 	expectedStats.ClassifyTimeMicroseconds = classifier.Stats().ClassifyTimeMicroseconds
@@ -258,3 +261,90 @@ func testSkipClassify(logLine string, t *testing.T) {
 	require.Nil(t, classifier.ParserStats()["failure1"])
 	require.Nil(t, classifier.ParserStats()["failure2"])
 }
+
+func TestClassifyWithHints(t *testing.T) {
+	fooLine, barLine := "foo", "bar"
+	fooResult := &parsers.Result{CoreFields: pantherlog.CoreFields{PantherLogType: "Foo"}}
+	barResult := &parsers.Result{CoreFields: pantherlog.CoreFields{PantherLogType: "Bar"}}
+	// Both parsers accept the foo line but only Foo is correct
+	fooParser := testutil.ParserConfig{
+		fooLine: fooResult,
+		barLine: errors.New("not foo"),
+	}.Parser()
+	barParser := testutil.ParserConfig{
+		fooLine: barResult,
+		barLine: barResult,
+	}.Parser()
+	classifier := NewClassifierWithHints(map[string]parsers.Interface{
+		"Foo": fooParser,
+		"Bar": barParser,
+	}, Hints{
+		LogTypes: []string{"Foo"},
+	})
+
+	result, err := classifier.Classify(fooLine)
+	require.NoError(t, err)
+	require.Equal(t, []*parsers.Result{fooResult}, result.Events)
+	require.Equal(t, 0, result.NumMiss)
+	// Bar had a successful parse but the hint has higher priority
+	result, err = classifier.Classify(barLine)
+	require.NoError(t, err)
+	require.Equal(t, []*parsers.Result{barResult}, result.Events)
+	require.Equal(t, 1, result.NumMiss)
+	result, err = classifier.Classify(fooLine)
+	require.NoError(t, err)
+	require.Equal(t, []*parsers.Result{fooResult}, result.Events)
+
+	// Hints per line are tried after the classifier hints
+	hinted := classifier.(HintedClassifier)
+	result, err = hinted.ClassifyWithHints(barLine, "Bar")
+	require.NoError(t, err)
+	require.Equal(t, []*parsers.Result{barResult}, result.Events)
+	// The foo parser failed once for the second line and once for this one
+	fooParser.AssertNumberOfCalls(t, "Parse", 4)
+	barParser.AssertNumberOfCalls(t, "Parse", 2)
+
+	stats := classifier.Stats()
+	require.Equal(t, uint64(4), stats.SuccessfullyClassifiedCount)
+	require.Equal(t, uint64(3), stats.HintMatchCount)
+	require.Equal(t, uint64(1), stats.HintMissCount)
+	require.Equal(t, uint64(3), stats.LogTypeChangeCount)
+}
+
+func TestClassifySticky(t *testing.T) {
+	fooLine, barLine := "foo", "bar"
+	fooResult := &parsers.Result{CoreFields: pantherlog.CoreFields{PantherLogType: "Foo"}}
+	barResult := &parsers.Result{CoreFields: pantherlog.CoreFields{PantherLogType: "Bar"}}
+	fooParser := testutil.ParserConfig{
+		fooLine: fooResult,
+		barLine: errors.New("not foo"),
+	}.Parser()
+	barParser := testutil.ParserConfig{
+		fooLine: errors.New("not bar"),
+		barLine: barResult,
+	}.Parser()
+	classifier := NewClassifierWithHints(map[string]parsers.Interface{
+		"Foo": fooParser,
+		"Bar": barParser,
+	}, Hints{
+		Sticky: true,
+	})
+
+	result, err := classifier.Classify(fooLine)
+	require.NoError(t, err)
+	require.Equal(t, []*parsers.Result{fooResult}, result.Events)
+	barCalls := len(barParser.Calls)
+	// Once pinned, other log types are not tried
+	result, err = classifier.Classify(barLine)
+	require.Error(t, err)
+	require.Equal(t, &ClassifierResult{NumMiss: 1}, result)
+	result, err = classifier.Classify(fooLine)
+	require.NoError(t, err)
+	require.Equal(t, []*parsers.Result{fooResult}, result.Events)
+	barParser.AssertNumberOfCalls(t, "Parse", barCalls)
+
+	stats := classifier.Stats()
+	require.Equal(t, uint64(2), stats.SuccessfullyClassifiedCount)
+	require.Equal(t, uint64(1), stats.ClassificationFailureCount)
+	require.Equal(t, uint64(0), stats.LogTypeChangeCount)
+}
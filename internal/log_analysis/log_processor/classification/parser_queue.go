@@ -82,6 +82,16 @@ func (q *ParserPriorityQueue) Pop() interface{} {
 	return item
 }
 
+// Find returns the index of the item for a log type or -1 if the log type is not in the queue
+func (q *ParserPriorityQueue) Find(logType string) int {
+	for i, item := range q.items {
+		if item.logType == logType {
+			return i
+		}
+	}
+	return -1
+}
+
 // Peek returns the item with the higher priority without removing it
 func (q *ParserPriorityQueue) Peek() *ParserQueueItem {
 	return q.items[0]
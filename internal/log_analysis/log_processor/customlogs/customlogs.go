@@ -30,6 +30,8 @@ import (
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
 )
 
 const LogTypePrefix = "Custom"
@@ -42,6 +44,7 @@ func LogType(name string) string {
 }
 
 // Build validates the schema and metadata and builds a logtypes.Entry
+// It does not register the scanners of custom indicators declared in the schema, see RegisterIndicators.
 func Build(name string, schema *logschema.Schema) (logtypes.Entry, error) {
 	desc := logtypes.Desc{
 		Name:         name,
@@ -56,17 +59,25 @@ func Build(name string, schema *logschema.Schema) (logtypes.Entry, error) {
 	if err := logschema.ValidateSchema(schema); err != nil {
 		return nil, err
 	}
+	indicators, err := scopedIndicators(name, schema.Indicators...)
+	if err != nil {
+		return nil, err
+	}
 	valueSchema, err := logschema.Resolve(schema)
 	if err != nil {
 		return nil, err
 	}
+	indicatorFields, err := scopeIndicators(valueSchema, indicators)
+	if err != nil {
+		return nil, err
+	}
 
 	typ, err := valueSchema.GoType()
 	if err != nil {
 		return nil, err
 	}
 	eventType := typ.Elem()
-	eventSchema, err := pantherlog.BuildEventTypeSchema(eventType)
+	eventSchema, err := pantherlog.BuildEventTypeSchema(eventType, indicatorFields...)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +85,17 @@ func Build(name string, schema *logschema.Schema) (logtypes.Entry, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to build preprocessor")
 	}
+	var multiLine *logstream.MultiLineConfig
+	if schema.Parser != nil && schema.Parser.MultiLine != nil {
+		multiLine = schema.Parser.MultiLine
+		if err := multiLine.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid multi-line config")
+		}
+	}
+	transform, err := transforms.Build(schema.Transform...)
+	if err != nil {
+		return nil, err
+	}
 	entry, err := logtypes.Config{
 		Name:         name,
 		Description:  desc.Description,
@@ -86,6 +108,8 @@ func Build(name string, schema *logschema.Schema) (logtypes.Entry, error) {
 			API:          pantherlog.ConfigJSON(),
 			Builder:      pantherlog.ResultBuilder{},
 			Validate:     pantherlog.ValidateStruct,
+			MultiLine:    multiLine,
+			Transform:    transform,
 		},
 	}.BuildEntry()
 	if err != nil {
@@ -104,6 +128,10 @@ func buildPreprocessor(parser *logschema.Parser) (preprocessors.Interface, error
 		return parser.CSV.BuildPreprocessor()
 	case parser.Regex != nil:
 		return parser.Regex.BuildPreprocessor()
+	case parser.Grok != nil:
+		return parser.Grok.BuildPreprocessor()
+	case parser.KV != nil:
+		return parser.KV.BuildPreprocessor()
 	default:
 		return preprocessors.Nop(), nil
 	}
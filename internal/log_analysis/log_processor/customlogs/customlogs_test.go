@@ -31,6 +31,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,6 +45,9 @@ import (
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes/logtesting"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
 )
 
 func ExampleBuild() {
@@ -233,6 +237,7 @@ func TestApacheCommonLog_FastMatch(t *testing.T) {
 	for _, schemaFile := range []string{
 		"../logschema/testdata/apache_common_log_fastmatch_schema.yml",
 		"../logschema/testdata/apache_common_log_regex_schema.yml",
+		"../logschema/testdata/apache_common_log_grok_schema.yml",
 	} {
 		schemaFile := schemaFile
 		t.Run(schemaFile, func(t *testing.T) {
@@ -304,6 +309,178 @@ func TestVPCFlowLog_CSV(t *testing.T) {
 	logtesting.TestRegisteredParser(t, entry, entry.String(), vpcFlowSampleLog, expectJSON)
 }
 
+func TestMultiLine_Regex(t *testing.T) {
+	schemaFile := "../logschema/testdata/multiline_regex_schema.yml"
+	assert := require.New(t)
+	data, err := ioutil.ReadFile(schemaFile)
+	assert.NoError(err)
+	logSchema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal(data, &logSchema))
+	err = logschema.ValidateSchema(&logSchema)
+	assert.NoError(err)
+	entry, err := customlogs.Build(logSchema.Schema, &logSchema)
+	assert.NoError(err)
+	parser, err := entry.NewParser(nil)
+	assert.NoError(err)
+	configurer, ok := parser.(logstream.MultiLineConfigurer)
+	assert.True(ok)
+	assert.Equal(logSchema.Parser.MultiLine, configurer.MultiLineConfig())
+
+	const input = "2020-10-10 13:55:36 ERROR request failed\n" +
+		"java.lang.NullPointerException\n" +
+		"\tat com.example.App.main(App.java:10)\n" +
+		"2020-10-10 13:55:37 INFO recovered\n"
+	stream, err := logstream.NewMultiLineStream(logstream.NewLineStream(strings.NewReader(input), 0), configurer.MultiLineConfig())
+	assert.NoError(err)
+	jsonAPI := pantherlog.ConfigJSON()
+	var messages []string
+	for entry := stream.Next(); entry != nil; entry = stream.Next() {
+		results, err := parser.ParseLog(string(entry))
+		assert.NoError(err)
+		assert.Len(results, 1)
+		data, err := jsonAPI.Marshal(results[0])
+		assert.NoError(err)
+		messages = append(messages, gjson.GetBytes(data, "message").String())
+	}
+	assert.NoError(stream.Err())
+	assert.Equal([]string{
+		"request failed\njava.lang.NullPointerException\n\tat com.example.App.main(App.java:10)",
+		"recovered",
+	}, messages)
+}
+
+func TestGrok_FieldReferences(t *testing.T) {
+	schemaFile := "../logschema/testdata/postfix_grok_schema.yml"
+	assert := require.New(t)
+	data, err := ioutil.ReadFile(schemaFile)
+	assert.NoError(err)
+	logSchema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal(data, &logSchema))
+	err = logschema.ValidateSchema(&logSchema)
+	assert.NoError(err)
+	entry, err := customlogs.Build(logSchema.Schema, &logSchema)
+	assert.NoError(err)
+	expectJSON := fmt.Sprintf(`{
+  "timestamp": "2020-10-10T13:55:36Z",
+  "host": {"hostname": "mail.example.com"},
+  "postfix": {"process": "smtpd", "queueid": "4A5C13FA4E"},
+  "process": {"pid": 1234},
+  "message": "client=unknown[10.0.0.1]",
+  "p_log_type": "%s",
+  "p_event_time": "2020-10-10T13:55:36Z"
+}`, entry.String())
+	logtesting.TestRegisteredParser(t, entry, entry.String(),
+		`2020-10-10T13:55:36Z mail.example.com postfix/smtpd[1234]: 4A5C13FA4E: client=unknown[10.0.0.1]`, expectJSON)
+	// Falls back to the next pattern
+	expectJSON = fmt.Sprintf(`{
+  "timestamp": "2020-10-10T13:55:36Z",
+  "host": {"hostname": "mail.example.com"},
+  "message": "kernel: oops",
+  "p_log_type": "%s",
+  "p_event_time": "2020-10-10T13:55:36Z"
+}`, entry.String())
+	logtesting.TestRegisteredParser(t, entry, entry.String(), `2020-10-10T13:55:36Z mail.example.com kernel: oops`, expectJSON)
+}
+
+//nolint: lll
+func TestGrok_Preprocessor(t *testing.T) {
+	assert := require.New(t)
+	logSchema := logschema.Schema{
+		Parser: &logschema.Parser{
+			Grok: &preprocessors.GrokConfig{
+				PatternDefinitions: map[string]string{
+					"STATUS": `(?:OK|FAIL)`,
+				},
+				Match: []string{
+					`%{TIMESTAMP_ISO8601:time} %{STATUS:status} %{IP:client} %{INT:bytes} %{NUMBER:duration} %{WORD:cached}(?: %{WORD:user})?`,
+				},
+			},
+		},
+		Fields: []logschema.FieldSchema{
+			{
+				Name: "time",
+				ValueSchema: logschema.ValueSchema{
+					Type:        logschema.TypeTimestamp,
+					TimeFormat:  "rfc3339",
+					IsEventTime: true,
+				},
+			},
+			{Name: "status", ValueSchema: logschema.ValueSchema{Type: logschema.TypeString}},
+			{Name: "client", ValueSchema: logschema.ValueSchema{Type: logschema.TypeString, Indicators: []string{"ip"}}},
+			{Name: "bytes", ValueSchema: logschema.ValueSchema{Type: logschema.TypeBigInt}},
+			{Name: "duration", ValueSchema: logschema.ValueSchema{Type: logschema.TypeFloat}},
+			{Name: "cached", ValueSchema: logschema.ValueSchema{Type: logschema.TypeBoolean}},
+			{Name: "user", ValueSchema: logschema.ValueSchema{Type: logschema.TypeString}},
+		},
+	}
+	entry, err := customlogs.Build("TestGrok", &logSchema)
+	assert.NoError(err)
+	parser, err := entry.NewParser(nil)
+	assert.NoError(err)
+
+	results, err := parser.ParseLog(`2020-10-10T13:55:36Z OK 10.0.0.1 1024 0.25 true alice`)
+	assert.NoError(err)
+	assert.Len(results, 1)
+	data, err := pantherlog.ConfigJSON().Marshal(results[0])
+	assert.NoError(err)
+	assert.Equal("2020-10-10T13:55:36Z", gjson.GetBytes(data, "p_event_time").String())
+	assert.Equal(`"OK"`, gjson.GetBytes(data, "status").Raw)
+	assert.Equal(`1024`, gjson.GetBytes(data, "bytes").Raw)
+	assert.Equal(`0.25`, gjson.GetBytes(data, "duration").Raw)
+	assert.Equal(`true`, gjson.GetBytes(data, "cached").Raw)
+	assert.Equal(`"alice"`, gjson.GetBytes(data, "user").Raw)
+	assert.Equal(`["10.0.0.1"]`, gjson.GetBytes(data, "p_any_ip_addresses").Raw)
+
+	// Optional captures that did not match are omitted
+	results, err = parser.ParseLog(`2020-10-10T13:55:36Z FAIL 10.0.0.1 0 1.5 false`)
+	assert.NoError(err)
+	assert.Len(results, 1)
+	data, err = pantherlog.ConfigJSON().Marshal(results[0])
+	assert.NoError(err)
+	assert.Equal(`"FAIL"`, gjson.GetBytes(data, "status").Raw)
+	assert.Equal(`false`, gjson.GetBytes(data, "cached").Raw)
+	assert.False(gjson.GetBytes(data, "user").Exists())
+
+	// Custom pattern definitions are enforced
+	_, err = parser.ParseLog(`2020-10-10T13:55:36Z MAYBE 10.0.0.1 0 1.5 false`)
+	assert.Error(err)
+	// Values that cannot be coerced to the field type fail
+	_, err = parser.ParseLog(`2020-10-10T13:55:36Z OK 10.0.0.1 1024 0.25 yes`)
+	assert.Error(err)
+}
+
+//nolint: lll
+func TestKV_CEF(t *testing.T) {
+	schemaFile := "../logschema/testdata/cef_schema.yml"
+	assert := require.New(t)
+	data, err := ioutil.ReadFile(schemaFile)
+	assert.NoError(err)
+	logSchema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal(data, &logSchema))
+	err = logschema.ValidateSchema(&logSchema)
+	assert.NoError(err)
+	entry, err := customlogs.Build(logSchema.Schema, &logSchema)
+	assert.NoError(err)
+	const input = `Oct 10 13:55:36 host CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|rt=1602338136000 src=10.0.0.1 dst=2.1.2.2 msg=Detected a threat. No action needed`
+	expectJSON := fmt.Sprintf(`{
+  "cefVersion": 0,
+  "deviceVendor": "Security",
+  "deviceProduct": "threatmanager",
+  "deviceVersion": "1.0",
+  "deviceEventClassId": "100",
+  "name": "worm successfully stopped",
+  "severity": 10,
+  "rt": 1602338136000,
+  "src": "10.0.0.1",
+  "dst": "2.1.2.2",
+  "msg": "Detected a threat. No action needed",
+  "p_log_type": "%s",
+  "p_any_ip_addresses": ["10.0.0.1","2.1.2.2"],
+  "p_event_time": "2020-10-10T13:55:36Z"
+}`, entry.String())
+	logtesting.TestRegisteredParser(t, entry, entry.String(), input, expectJSON)
+}
+
 func TestNameCollisions(t *testing.T) {
 	schema := logschema.Schema{
 		Fields: []logschema.FieldSchema{
@@ -328,3 +505,43 @@ func TestNameCollisions(t *testing.T) {
 	assert.Error(err)
 	assert.Nil(entry)
 }
+
+func TestTransform(t *testing.T) {
+	transforms.SetHashKey([]byte("key"))
+	schemaFile := "../logschema/testdata/transform_schema.yml"
+	assert := require.New(t)
+	data, err := ioutil.ReadFile(schemaFile)
+	assert.NoError(err)
+	logSchema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal(data, &logSchema))
+	err = logschema.ValidateSchema(&logSchema)
+	assert.NoError(err)
+	entry, err := customlogs.Build(logSchema.Schema, &logSchema)
+	assert.NoError(err)
+	parser, err := entry.NewParser(nil)
+	assert.NoError(err)
+
+	const input = `{
+		"ts": "2020-10-10T13:55:36Z",
+		"user": {"email": "alice@example.com"},
+		"host": {"name": "example.com", "port": 8080},
+		"tags": "foo, bar",
+		"status": "200",
+		"token": "secret"
+	}`
+	results, err := parser.ParseLog(input)
+	assert.NoError(err)
+	assert.Len(results, 1)
+	data, err = pantherlog.ConfigJSON().Marshal(results[0])
+	assert.NoError(err)
+	assert.Equal("2020-10-10T13:55:36Z", gjson.GetBytes(data, "p_event_time").String())
+	assert.Equal("a****@example.com", gjson.GetBytes(data, "user.email").String())
+	assert.Equal("example.com:8080", gjson.GetBytes(data, "address").String())
+	assert.Equal(`["foo","bar"]`, gjson.GetBytes(data, "tags").Raw)
+	assert.Equal(`200`, gjson.GetBytes(data, "status").Raw)
+	assert.Equal("tok_25cf3c44c8f39313e8cbf7c23e22fe8b", gjson.GetBytes(data, "token").String())
+	assert.False(gjson.GetBytes(data, "ts").Exists())
+
+	_, err = parser.ParseLog(`{"ts": "2020-10-10T13:55:36Z", "status": "OK"}`)
+	assert.Error(err)
+}
@@ -28,6 +28,8 @@ import (
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
 )
 
 // Factory implements parsers.Factory interface using reflection to parse json log entries to a single log event.
@@ -38,6 +40,10 @@ type Factory struct {
 	API          jsoniter.API
 	Builder      pantherlog.ResultBuilder
 	Validate     func(interface{}) error
+	// MultiLine is an optional config for assembling log entries that span multiple lines
+	MultiLine *logstream.MultiLineConfig
+	// Transform is an optional set of field transformations applied to log entries before validation
+	Transform *transforms.Transformer
 }
 
 // NewParser implements parsers.Factory interface.
@@ -45,12 +51,33 @@ type Factory struct {
 func (f *Factory) NewParser(_ interface{}) (pantherlog.LogParser, error) {
 	decoder := newEventDecoderJSON(f.API, f.EventSchema)
 	builder := f.Builder
-	return preprocessors.Wrap(&parser{
+	p := preprocessors.Wrap(&parser{
 		logType:       f.LogType,
 		eventDecoder:  decoder,
 		validate:      f.Validate,
+		transform:     f.Transform,
 		resultBuilder: &builder,
-	}, f.PreProcessor), nil
+	}, f.PreProcessor)
+	if f.MultiLine != nil {
+		return &multiLineParser{
+			LogParser: p,
+			config:    f.MultiLine,
+		}, nil
+	}
+	return p, nil
+}
+
+// multiLineParser exposes the multi-line config of a schema so that log streams can be assembled accordingly
+type multiLineParser struct {
+	pantherlog.LogParser
+	config *logstream.MultiLineConfig
+}
+
+var _ logstream.MultiLineConfigurer = (*multiLineParser)(nil)
+
+// MultiLineConfig implements logstream.MultiLineConfigurer
+func (p *multiLineParser) MultiLineConfig() *logstream.MultiLineConfig {
+	return p.config
 }
 
 type eventDecoderJSON struct {
@@ -89,6 +116,7 @@ type parser struct {
 	logType       string
 	eventDecoder  *eventDecoderJSON
 	validate      func(interface{}) error
+	transform     *transforms.Transformer
 	resultBuilder *pantherlog.ResultBuilder
 }
 
@@ -97,6 +125,13 @@ func (p *parser) ParseLog(log string) ([]*pantherlog.Result, error) {
 	if log == "" {
 		return nil, nil
 	}
+	if p.transform != nil {
+		transformed, err := p.transform.TransformLog(log)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transform failed")
+		}
+		log = transformed
+	}
 	event, err := p.eventDecoder.DecodeEvent(log)
 	if err != nil {
 		return nil, errors.Wrapf(err, "parse failed")
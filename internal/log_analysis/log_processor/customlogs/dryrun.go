@@ -0,0 +1,341 @@
+package customlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+// DryRun parses log entries with a draft schema and collects statistics without storing any results.
+type DryRun struct {
+	// MaxEvents is the maximum number of log entries to parse (zero means no limit)
+	MaxEvents int
+	// MaxErrors is the number of distinct error messages reported for each field (defaults to 5)
+	MaxErrors int
+
+	entry      logtypes.Entry
+	value      *logschema.ValueSchema
+	classifier classification.ClassifierAPI
+	numLines   int
+	numEvents  int
+	errors     map[string]map[string]int
+}
+
+// DryRunReport is the result of a dry run
+type DryRunReport struct {
+	NumLines    int            `json:"numLines" description:"The number of log entries parsed"`
+	NumMatched  int            `json:"numMatched" description:"The number of log entries successfully parsed"`
+	NumEvents   int            `json:"numEvents" description:"The number of events produced"`
+	SuccessRate float64        `json:"successRate" description:"The ratio of log entries successfully parsed"`
+	Errors      []FieldErrors  `json:"errors" description:"The most common parse errors for each field"`
+	Columns     []ColumnChange `json:"columns" description:"The changes to the Glue table columns"`
+}
+
+// FieldErrors are the parse errors for a field
+type FieldErrors struct {
+	Field    string       `json:"field" description:"The path of the field (empty if the error is not specific to a field)"`
+	Count    int          `json:"count" description:"The number of log entries that failed to parse because of this field"`
+	Messages []ErrorCount `json:"messages" description:"The most common error messages"`
+}
+
+// ErrorCount counts the occurrences of an error message
+type ErrorCount struct {
+	Message string `json:"message" description:"The error message"`
+	Count   int    `json:"count" description:"The number of occurrences"`
+}
+
+// Column change types
+const (
+	ColumnAdd    = "add"
+	ColumnDelete = "delete"
+	ColumnUpdate = "update"
+)
+
+// ColumnChange describes a change to a Glue table column
+type ColumnChange struct {
+	Column string `json:"column" description:"The name of the column"`
+	Change string `json:"change" description:"The type of change (add, delete, update)"`
+	From   string `json:"from,omitempty" description:"The Glue type of the column before the change"`
+	To     string `json:"to,omitempty" description:"The Glue type of the column after the change"`
+}
+
+const defaultDryRunMaxErrors = 5
+
+// NewDryRun builds a parser for a draft schema to use in a dry run
+func NewDryRun(name string, schema *logschema.Schema) (*DryRun, error) {
+	entry, err := Build(name, schema)
+	if err != nil {
+		return nil, err
+	}
+	// The field indexes in parser errors refer to the resolved schema
+	value, err := logschema.Resolve(schema)
+	if err != nil {
+		return nil, err
+	}
+	parser, err := entry.NewParser(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DryRun{
+		entry: entry,
+		value: value,
+		classifier: classification.NewClassifier(map[string]parsers.Interface{
+			name: parser,
+		}),
+	}, nil
+}
+
+// Full checks if the dry run has reached MaxEvents
+func (d *DryRun) Full() bool {
+	return d.MaxEvents > 0 && d.numLines >= d.MaxEvents
+}
+
+// ParseStream parses log entries from a stream until the stream is exhausted or the dry run is full.
+func (d *DryRun) ParseStream(stream logstream.Stream) error {
+	for !d.Full() {
+		entry := stream.Next()
+		if entry == nil {
+			return stream.Err()
+		}
+		d.ParseLog(string(entry))
+	}
+	return nil
+}
+
+// ParseLog parses a single log entry and records any errors
+func (d *DryRun) ParseLog(log string) {
+	if d.Full() || strings.TrimSpace(log) == "" {
+		return
+	}
+	d.numLines++
+	result, err := d.classifier.Classify(log)
+	if err == nil {
+		d.numEvents += len(result.Events)
+		return
+	}
+	msg := err.Error()
+	if e, ok := err.(*classification.ClassificationError); ok {
+		for _, parserErr := range e.ParserErrors {
+			msg = parserErr
+		}
+	}
+	field, msg := d.explainError(msg)
+	if d.errors == nil {
+		d.errors = make(map[string]map[string]int)
+	}
+	messages := d.errors[field]
+	if messages == nil {
+		messages = make(map[string]int)
+		d.errors[field] = messages
+	}
+	messages[msg]++
+}
+
+var (
+	// Go struct field names in parser errors are of the form `Field_<INDEX>_<NAME>`
+	reFieldName = regexp.MustCompile(`Field_(\d+)_[A-Za-z0-9_]*`)
+	// Values in error messages are quoted, we hide them so that messages can be aggregated
+	reQuoted = regexp.MustCompile(`"[^"]*"`)
+)
+
+// explainError resolves the path of the field that caused a parser error and a message suitable for aggregation.
+func (d *DryRun) explainError(msg string) (string, string) {
+	// Remove the input context added by jsoniter
+	if i := strings.Index(msg, ", error found in"); i != -1 {
+		msg = msg[:i]
+	}
+	matches := reFieldName.FindAllStringSubmatchIndex(msg, -1)
+	var indexes []int
+	for _, m := range matches {
+		index, _ := strconv.Atoi(msg[m[2]:m[3]])
+		indexes = append(indexes, index)
+	}
+	var path []string
+	// jsoniter error prefixes can include names of fields that precede the failed field.
+	// We drop leading names until the names resolve to a path of nested fields.
+	for i := range indexes {
+		if p, ok := resolveFieldPath(d.value, indexes[i:]); ok {
+			path = p
+			break
+		}
+	}
+	if n := len(matches); n > 0 {
+		msg = msg[matches[n-1][1]:]
+	}
+	msg = strings.TrimLeft(msg, "': ")
+	msg = reQuoted.ReplaceAllString(msg, `"..."`)
+	return strings.Join(path, "."), msg
+}
+
+func resolveFieldPath(value *logschema.ValueSchema, indexes []int) ([]string, bool) {
+	path := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		// Array elements have the same fields as their element object
+		for value != nil && value.Type == logschema.TypeArray {
+			value = value.Element
+		}
+		if value == nil || value.Type != logschema.TypeObject || index >= len(value.Fields) {
+			return nil, false
+		}
+		field := &value.Fields[index]
+		path = append(path, field.Name)
+		value = &field.ValueSchema
+	}
+	return path, true
+}
+
+// Report reports the results of the dry run.
+// If current is not nil the changes to Glue columns from the current schema to the draft schema are reported.
+func (d *DryRun) Report(current *logschema.Schema) (*DryRunReport, error) {
+	numFailed := 0
+	report := DryRunReport{
+		NumLines:  d.numLines,
+		NumEvents: d.numEvents,
+		Errors:    make([]FieldErrors, 0, len(d.errors)),
+	}
+	maxErrors := d.MaxErrors
+	if maxErrors <= 0 {
+		maxErrors = defaultDryRunMaxErrors
+	}
+	for field, messages := range d.errors {
+		fieldErrors := FieldErrors{
+			Field: field,
+		}
+		for msg, n := range messages {
+			fieldErrors.Count += n
+			fieldErrors.Messages = append(fieldErrors.Messages, ErrorCount{
+				Message: msg,
+				Count:   n,
+			})
+		}
+		sortErrorCounts(fieldErrors.Messages)
+		if len(fieldErrors.Messages) > maxErrors {
+			fieldErrors.Messages = fieldErrors.Messages[:maxErrors]
+		}
+		numFailed += fieldErrors.Count
+		report.Errors = append(report.Errors, fieldErrors)
+	}
+	sort.Slice(report.Errors, func(i, j int) bool {
+		a, b := &report.Errors[i], &report.Errors[j]
+		if a.Count == b.Count {
+			return a.Field < b.Field
+		}
+		return a.Count > b.Count
+	})
+	report.NumMatched = d.numLines - numFailed
+	if d.numLines > 0 {
+		report.SuccessRate = float64(report.NumMatched) / float64(d.numLines)
+	}
+	columns, err := d.diffColumns(current)
+	if err != nil {
+		return nil, err
+	}
+	report.Columns = columns
+	return &report, nil
+}
+
+func sortErrorCounts(counts []ErrorCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := &counts[i], &counts[j]
+		if a.Count == b.Count {
+			return a.Message < b.Message
+		}
+		return a.Count > b.Count
+	})
+}
+
+func (d *DryRun) diffColumns(current *logschema.Schema) ([]ColumnChange, error) {
+	to, err := columnTypes(d.entry)
+	if err != nil {
+		return nil, err
+	}
+	var fromFields []logschema.FieldSchema
+	from := map[string]string{}
+	if current != nil {
+		entry, err := Build(d.entry.String(), current)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build current schema")
+		}
+		if from, err = columnTypes(entry); err != nil {
+			return nil, err
+		}
+		value, err := logschema.Resolve(current)
+		if err != nil {
+			return nil, err
+		}
+		fromFields = value.Fields
+	}
+	changes := []ColumnChange{}
+	for _, diff := range logschema.DiffFields(fromFields, d.value.Fields) {
+		switch a, b := diff.A, diff.B; {
+		case a != nil && b != nil:
+			col := glueschema.ColumnName(b.Name)
+			if from[col] != to[col] {
+				changes = append(changes, ColumnChange{
+					Column: col,
+					Change: ColumnUpdate,
+					From:   from[col],
+					To:     to[col],
+				})
+			}
+		case a != nil:
+			col := glueschema.ColumnName(a.Name)
+			changes = append(changes, ColumnChange{
+				Column: col,
+				Change: ColumnDelete,
+				From:   from[col],
+			})
+		case b != nil:
+			col := glueschema.ColumnName(b.Name)
+			changes = append(changes, ColumnChange{
+				Column: col,
+				Change: ColumnAdd,
+				To:     to[col],
+			})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Column < changes[j].Column
+	})
+	return changes, nil
+}
+
+func columnTypes(entry logtypes.Entry) (map[string]string, error) {
+	columns, err := glueschema.InferColumns(entry.Schema())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to infer Glue columns for %q", entry.String())
+	}
+	types := make(map[string]string, len(columns))
+	for _, col := range columns {
+		types[col.Name] = col.Type.String()
+	}
+	return types, nil
+}
@@ -0,0 +1,116 @@
+package customlogs_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+func TestDryRun(t *testing.T) {
+	const currentSpec = `
+fields:
+- name: ts
+  type: timestamp
+  timeFormat: rfc3339
+  isEventTime: true
+  required: true
+- name: id
+  type: string
+- name: extra
+  type: string
+`
+	const draftSpec = `
+fields:
+- name: ts
+  type: timestamp
+  timeFormat: rfc3339
+  isEventTime: true
+  required: true
+- name: id
+  type: bigint
+- name: user
+  type: object
+  fields:
+  - name: age
+    type: int
+`
+	const input = `{"ts": "2020-01-02T10:11:12Z", "id": 1, "user": {"age": 42}}
+{"ts": "2020-01-02T10:11:12Z", "id": "foo"}
+{"ts": "2020-01-02T10:11:12Z", "id": "bar"}
+{"ts": "2020-01-02T10:11:12Z", "user": {"age": "old"}}
+{"id": 2}
+not json
+
+{"ts": "2020-01-02T10:11:12Z", "id": 3}
+`
+	assert := require.New(t)
+	current := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal([]byte(currentSpec), &current))
+	draft := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal([]byte(draftSpec), &draft))
+
+	dryRun, err := customlogs.NewDryRun("Custom.Test", &draft)
+	assert.NoError(err)
+	stream := logstream.NewLineStream(strings.NewReader(input), logstream.DefaultBufferSize)
+	assert.NoError(dryRun.ParseStream(stream))
+
+	report, err := dryRun.Report(&current)
+	assert.NoError(err)
+	assert.Equal(7, report.NumLines)
+	assert.Equal(2, report.NumMatched)
+	assert.Equal(2, report.NumEvents)
+	assert.InDelta(2.0/7.0, report.SuccessRate, 0.001)
+
+	errorsByField := map[string]customlogs.FieldErrors{}
+	for _, e := range report.Errors {
+		errorsByField[e.Field] = e
+	}
+	assert.Len(errorsByField, 4)
+	assert.Equal(2, errorsByField["id"].Count)
+	assert.Len(errorsByField["id"].Messages, 1, "quoted values should be hidden")
+	assert.Equal(2, errorsByField["id"].Messages[0].Count)
+	assert.NotContains(errorsByField["id"].Messages[0].Message, "foo")
+	assert.Equal(1, errorsByField["user.age"].Count)
+	assert.Equal(1, errorsByField["ts"].Count)
+	assert.Contains(errorsByField["ts"].Messages[0].Message, "required")
+	assert.Equal(1, errorsByField[""].Count)
+	assert.Equal("id", report.Errors[0].Field)
+
+	assert.Equal([]customlogs.ColumnChange{
+		{Column: "extra", Change: customlogs.ColumnDelete, From: "string"},
+		{Column: "id", Change: customlogs.ColumnUpdate, From: "string", To: "bigint"},
+		{Column: "user", Change: customlogs.ColumnAdd, To: "struct<age:int>"},
+	}, report.Columns)
+
+	// Without a current schema all columns are new
+	report, err = dryRun.Report(nil)
+	assert.NoError(err)
+	assert.Len(report.Columns, 3)
+	for _, c := range report.Columns {
+		assert.Equal(customlogs.ColumnAdd, c.Change)
+	}
+}
@@ -0,0 +1,202 @@
+package customlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// ValidateIndicators checks custom indicator declarations without registering them.
+func ValidateIndicators(indicators ...logschema.IndicatorSchema) error {
+	names := make(map[string]bool, len(indicators))
+	for i := range indicators {
+		ind := &indicators[i]
+		if names[ind.Name] {
+			return errors.Errorf("duplicate indicator %q", ind.Name)
+		}
+		names[ind.Name] = true
+		if _, err := buildIndicatorScanner(ind, pantherlog.FieldNone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterIndicators registers the scanners of the custom indicators declared for a log type.
+// The scanners are registered under names scoped to the log type so that schemas declaring the same indicator name
+// with a different definition do not affect each other. Indicator fields are shared across all log types.
+// Build does not register any scanners, so that validating a draft schema does not affect live parsers,
+// and it must be called before using the parsers of a log type with custom indicators.
+func RegisterIndicators(logType string, indicators ...logschema.IndicatorSchema) error {
+	if err := ValidateIndicators(indicators...); err != nil {
+		return err
+	}
+	for i := range indicators {
+		ind := &indicators[i]
+		id, err := pantherlog.RegisterCustomIndicator(ind.Field, ind.Description)
+		if err != nil {
+			return errors.Wrapf(err, "invalid indicator %q", ind.Name)
+		}
+		scanner, err := buildIndicatorScanner(ind, id)
+		if err != nil {
+			return err
+		}
+		if err := pantherlog.RegisterCustomScanner(scopedIndicatorName(logType, ind.Name), scanner, id); err != nil {
+			return errors.Wrapf(err, "failed to register indicator %q", ind.Name)
+		}
+	}
+	return nil
+}
+
+func scopedIndicatorName(logType, name string) string {
+	return logType + ":" + name
+}
+
+// scopedIndicator is an indicator declared in a schema
+type scopedIndicator struct {
+	name  string
+	field pantherlog.FieldID
+}
+
+// scopedIndicators resolves the scoped scanner names and the field ids of the custom indicators declared for a log type.
+// It registers the indicator fields, which are needed to build the event schema, but not the scanners.
+func scopedIndicators(logType string, indicators ...logschema.IndicatorSchema) (map[string]scopedIndicator, error) {
+	if err := ValidateIndicators(indicators...); err != nil {
+		return nil, err
+	}
+	scoped := make(map[string]scopedIndicator, len(indicators))
+	for i := range indicators {
+		ind := &indicators[i]
+		id, err := pantherlog.RegisterCustomIndicator(ind.Field, ind.Description)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid indicator %q", ind.Name)
+		}
+		scoped[ind.Name] = scopedIndicator{
+			name:  scopedIndicatorName(logType, ind.Name),
+			field: id,
+		}
+	}
+	return scoped, nil
+}
+
+func buildIndicatorScanner(ind *logschema.IndicatorSchema, id pantherlog.FieldID) (pantherlog.ValueScanner, error) {
+	if ind.Name == "" {
+		return nil, errors.New("anonymous indicator")
+	}
+	switch {
+	case ind.Match != "" && ind.Scanner != "":
+		return nil, errors.Errorf("indicator %q can either set a match pattern or a scanner", ind.Name)
+	case ind.Match != "":
+		pattern, err := regexp.Compile(ind.Match)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid match pattern for indicator %q", ind.Name)
+		}
+		return &matchScanner{
+			pattern: pattern,
+			field:   id,
+		}, nil
+	case ind.Scanner != "":
+		scanner, _ := pantherlog.LookupScanner(ind.Scanner)
+		if scanner == nil {
+			return nil, errors.Errorf("unknown scanner %q for indicator %q", ind.Scanner, ind.Name)
+		}
+		return &fieldScanner{
+			scanner: scanner,
+			field:   id,
+		}, nil
+	default:
+		return id, nil
+	}
+}
+
+// matchScanner collects all matches of a pattern (or the first capture group if the pattern has one)
+type matchScanner struct {
+	pattern *regexp.Regexp
+	field   pantherlog.FieldID
+}
+
+func (s *matchScanner) ScanValues(w pantherlog.ValueWriter, input string) {
+	for _, match := range s.pattern.FindAllStringSubmatch(input, -1) {
+		if len(match) > 1 {
+			w.WriteValues(s.field, match[1])
+			continue
+		}
+		w.WriteValues(s.field, match[0])
+	}
+}
+
+// fieldScanner collects all values of a built-in scanner to a single field
+type fieldScanner struct {
+	scanner pantherlog.ValueScanner
+	field   pantherlog.FieldID
+}
+
+func (s *fieldScanner) ScanValues(w pantherlog.ValueWriter, input string) {
+	s.scanner.ScanValues(&fieldWriter{
+		w:     w,
+		field: s.field,
+	}, input)
+}
+
+type fieldWriter struct {
+	w     pantherlog.ValueWriter
+	field pantherlog.FieldID
+}
+
+func (w *fieldWriter) WriteValues(_ pantherlog.FieldID, values ...string) {
+	w.w.WriteValues(w.field, values...)
+}
+
+// scopeIndicators replaces the names of indicators declared in the schema with their scoped scanner names.
+// It returns the ids of the indicator fields used by the schema.
+// It fails if an indicator is neither declared nor a registered scanner.
+func scopeIndicators(v *logschema.ValueSchema, scoped map[string]scopedIndicator) ([]pantherlog.FieldID, error) {
+	var fields []pantherlog.FieldID
+	switch v.Type {
+	case logschema.TypeObject:
+		for i := range v.Fields {
+			ids, err := scopeIndicators(&v.Fields[i].ValueSchema, scoped)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, ids...)
+		}
+	case logschema.TypeArray:
+		if v.Element != nil {
+			return scopeIndicators(v.Element, scoped)
+		}
+	case logschema.TypeString:
+		for i, name := range v.Indicators {
+			if ind, ok := scoped[name]; ok {
+				v.Indicators[i] = ind.name
+				fields = append(fields, ind.field)
+				continue
+			}
+			if scanner, _ := pantherlog.LookupScanner(name); scanner == nil {
+				return nil, errors.Errorf("unknown indicator %q", name)
+			}
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,216 @@
+package customlogs_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes/logtesting"
+)
+
+func TestBuildCustomIndicators(t *testing.T) {
+	const schemaYAML = `
+version: 0
+indicators:
+  - name: employee_id
+    field: p_any_employee_ids
+    description: Employee ids
+    match: '\bE[0-9]{6}\b'
+  - name: pod
+    field: p_any_k8s_pods
+    match: 'pod/([a-z0-9-]+)'
+  - name: serial
+    field: p_any_device_serials
+  - name: corp_email
+    field: p_any_corp_emails
+    scanner: email
+fields:
+  - name: ts
+    type: timestamp
+    timeFormat: rfc3339
+    isEventTime: true
+  - name: user
+    type: string
+    indicators: [employee_id]
+  - name: resource
+    type: string
+    indicators: [pod]
+  - name: serial
+    type: string
+    indicators: [serial]
+  - name: contact
+    type: string
+    indicators: [corp_email, email]
+`
+	assert := require.New(t)
+	schema := logschema.Schema{}
+	assert.NoError(yaml.Unmarshal([]byte(schemaYAML), &schema))
+	assert.NoError(customlogs.RegisterIndicators("Custom.Indicators", schema.Indicators...))
+	entry, err := customlogs.Build("Custom.Indicators", &schema)
+	assert.NoError(err)
+
+	columns, err := glueschema.InferColumns(entry.Schema())
+	assert.NoError(err)
+	comments := map[string]string{}
+	for _, c := range columns {
+		comments[c.Name] = c.Comment
+	}
+	assert.Equal("Employee ids", comments["p_any_employee_ids"])
+	assert.Contains(comments, "p_any_k8s_pods")
+	assert.Contains(comments, "p_any_device_serials")
+	assert.Contains(comments, "p_any_corp_emails")
+	assert.Contains(comments, "p_any_emails")
+
+	input := `{"ts":"2020-01-01T00:00:00Z","user":"login E123456 by E654321","resource":"ns/default/pod/api-7f8d","serial":" SN-42 ","contact":"jdoe@example.com"}`
+	expect := `{
+		"ts":"2020-01-01T00:00:00Z",
+		"p_event_time":"2020-01-01T00:00:00Z",
+		"user":"login E123456 by E654321",
+		"resource":"ns/default/pod/api-7f8d",
+		"serial":" SN-42 ",
+		"contact":"jdoe@example.com",
+		"p_log_type": "Custom.Indicators",
+		"p_any_employee_ids": ["E123456", "E654321"],
+		"p_any_k8s_pods": ["api-7f8d"],
+		"p_any_device_serials": ["SN-42"],
+		"p_any_corp_emails": ["jdoe@example.com"],
+		"p_any_emails": ["jdoe@example.com"]
+	}`
+	logtesting.TestRegisteredParser(t, entry, entry.String(), input, expect)
+}
+
+func TestBuildCustomIndicatorsScoped(t *testing.T) {
+	assert := require.New(t)
+	build := func(name, pattern string) {
+		schema := logschema.Schema{
+			Version: 0,
+			Indicators: []logschema.IndicatorSchema{
+				{Name: "ticket", Field: "p_any_tickets", Match: pattern},
+			},
+			Fields: []logschema.FieldSchema{
+				{
+					Name:        "ts",
+					ValueSchema: logschema.ValueSchema{Type: logschema.TypeTimestamp, TimeFormat: "rfc3339", IsEventTime: true},
+				},
+				{
+					Name:        "ref",
+					ValueSchema: logschema.ValueSchema{Type: logschema.TypeString, Indicators: []string{"ticket"}},
+				},
+			},
+		}
+		assert.NoError(customlogs.RegisterIndicators(name, schema.Indicators...))
+		entry, err := customlogs.Build(name, &schema)
+		assert.NoError(err)
+		logtesting.TestRegisteredParser(t, entry, entry.String(), `{"ts":"2020-01-01T00:00:00Z","ref":"SEC-1 OPS-2"}`, `{
+			"ts":"2020-01-01T00:00:00Z",
+			"p_event_time":"2020-01-01T00:00:00Z",
+			"ref":"SEC-1 OPS-2",
+			"p_log_type": "`+name+`",
+			"p_any_tickets": ["`+pattern+`"]
+		}`)
+	}
+	build("Custom.Security", "SEC-1")
+	build("Custom.Operations", "OPS-2")
+}
+
+func TestBuildCustomIndicatorsChanged(t *testing.T) {
+	assert := require.New(t)
+	const logType = "Custom.Changed"
+	newSchema := func(pattern string) *logschema.Schema {
+		return &logschema.Schema{
+			Version: 0,
+			Indicators: []logschema.IndicatorSchema{
+				{Name: "ticket", Field: "p_any_tickets", Match: pattern},
+			},
+			Fields: []logschema.FieldSchema{
+				{
+					Name:        "ts",
+					ValueSchema: logschema.ValueSchema{Type: logschema.TypeTimestamp, TimeFormat: "rfc3339", IsEventTime: true},
+				},
+				{
+					Name:        "ref",
+					ValueSchema: logschema.ValueSchema{Type: logschema.TypeString, Indicators: []string{"ticket"}},
+				},
+			},
+		}
+	}
+	const input = `{"ts":"2020-01-01T00:00:00Z","ref":"SEC-1 OPS-2"}`
+	expect := func(tickets string) string {
+		return `{
+			"ts":"2020-01-01T00:00:00Z",
+			"p_event_time":"2020-01-01T00:00:00Z",
+			"ref":"SEC-1 OPS-2",
+			"p_log_type": "` + logType + `",
+			"p_any_tickets": ` + tickets + `
+		}`
+	}
+	live := newSchema(`SEC-[0-9]+`)
+	assert.NoError(customlogs.RegisterIndicators(logType, live.Indicators...))
+	entry, err := customlogs.Build(logType, live)
+	assert.NoError(err)
+	logtesting.TestRegisteredParser(t, entry, entry.String(), input, expect(`["SEC-1"]`))
+
+	// Building a draft schema does not affect the registered scanners
+	draft := newSchema(`OPS-[0-9]+`)
+	_, err = customlogs.Build(logType, draft)
+	assert.NoError(err)
+	logtesting.TestRegisteredParser(t, entry, entry.String(), input, expect(`["SEC-1"]`))
+
+	// Registering the changed schema applies to existing types
+	assert.NoError(customlogs.RegisterIndicators(logType, draft.Indicators...))
+	entry, err = customlogs.Build(logType, draft)
+	assert.NoError(err)
+	logtesting.TestRegisteredParser(t, entry, entry.String(), input, expect(`["OPS-2"]`))
+}
+
+func TestBuildCustomIndicatorsInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		Name       string
+		Indicators []logschema.IndicatorSchema
+		Use        string
+	}{
+		{"unknown", nil, "employee_id"},
+		{"bad pattern", []logschema.IndicatorSchema{{Name: "x", Field: "p_any_x", Match: "("}}, "x"},
+		{"bad scanner", []logschema.IndicatorSchema{{Name: "x", Field: "p_any_x", Scanner: "foo"}}, "x"},
+		{"bad field", []logschema.IndicatorSchema{{Name: "x", Field: "p_foo"}}, "x"},
+		{"duplicate", []logschema.IndicatorSchema{{Name: "x", Field: "p_any_x"}, {Name: "x", Field: "p_any_y"}}, "x"},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			schema := logschema.Schema{
+				Version:    0,
+				Indicators: tc.Indicators,
+				Fields: []logschema.FieldSchema{
+					{
+						Name:        "foo",
+						ValueSchema: logschema.ValueSchema{Type: logschema.TypeString, Indicators: []string{tc.Use}},
+					},
+				},
+			}
+			_, err := customlogs.Build("Custom.Invalid", &schema)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package customlogs
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+// Sampler infers a draft schema from sample log entries.
+// It keeps track of how many of the sampled entries had each field to help review the draft schema.
+type Sampler struct {
+	// MaxEvents is the maximum number of entries to sample (zero means no limit)
+	MaxEvents int
+
+	value      *logschema.ValueSchema
+	entries    []string
+	numInvalid int
+	fields     map[string]int
+}
+
+// SampleReport describes the result of sampling log entries
+type SampleReport struct {
+	NumEvents      int             `json:"numEvents" description:"The number of log entries sampled"`
+	NumInvalid     int             `json:"numInvalid" description:"The number of entries that were not JSON objects"`
+	NumParseErrors int             `json:"numParseErrors" description:"The number of sampled entries that failed to parse with the draft schema"`
+	ParseError     string          `json:"parseError,omitempty" description:"The first error when parsing sampled entries with the draft schema"`
+	Fields         []FieldCoverage `json:"fields" description:"Coverage of each field in the sampled entries"`
+}
+
+// FieldCoverage reports how often a field was present in the sampled entries
+type FieldCoverage struct {
+	Path     string  `json:"path" description:"The path of the field (array elements are denoted with '[]')"`
+	Count    int     `json:"count" description:"The number of sampled entries that had the field"`
+	Coverage float64 `json:"coverage" description:"The ratio of sampled entries that had the field"`
+}
+
+var samplerJSON = jsoniter.Config{
+	UseNumber: true,
+}.Froze()
+
+// Full checks if the sampler has reached MaxEvents
+func (s *Sampler) Full() bool {
+	return s.MaxEvents > 0 && len(s.entries) >= s.MaxEvents
+}
+
+// SampleStream samples entries from a stream until the stream is exhausted or the sampler is full.
+func (s *Sampler) SampleStream(stream logstream.Stream) error {
+	for !s.Full() {
+		entry := stream.Next()
+		if entry == nil {
+			return stream.Err()
+		}
+		s.Sample(entry)
+	}
+	return nil
+}
+
+// Sample infers the schema of a single log entry and merges it to the draft schema.
+// Entries that are not JSON objects are counted as invalid.
+func (s *Sampler) Sample(entry []byte) {
+	if s.Full() {
+		return
+	}
+	var data map[string]interface{}
+	if err := samplerJSON.Unmarshal(entry, &data); err != nil || data == nil {
+		s.numInvalid++
+		return
+	}
+	value := logschema.InferJSONValueSchema(data)
+	s.value = logschema.Merge(s.value, value)
+	s.entries = append(s.entries, string(entry))
+	if s.fields == nil {
+		s.fields = make(map[string]int)
+	}
+	seen := make(map[string]struct{})
+	collectPaths(seen, data, "")
+	for path := range seen {
+		s.fields[path]++
+	}
+}
+
+func collectPaths(paths map[string]struct{}, x interface{}, prefix string) {
+	switch v := x.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if val == nil {
+				continue
+			}
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			paths[path] = struct{}{}
+			collectPaths(paths, val, path)
+		}
+	case []interface{}:
+		for _, el := range v {
+			collectPaths(paths, el, prefix+"[]")
+		}
+	}
+}
+
+// Schema returns the draft schema inferred from the sampled entries.
+// It returns nil if no entries were sampled.
+func (s *Sampler) Schema() *logschema.Schema {
+	value := s.value.NonEmpty()
+	if value == nil {
+		return nil
+	}
+	markEventTime(value.Fields)
+	return &logschema.Schema{
+		Version: 0,
+		Fields:  value.Fields,
+	}
+}
+
+// eventTimeFieldNames are common names for the field holding the event timestamp, in order of preference
+var eventTimeFieldNames = []string{
+	"timestamp",
+	"@timestamp",
+	"eventtime",
+	"event_time",
+	"time",
+	"ts",
+	"date",
+	"datetime",
+}
+
+// markEventTime marks a top level timestamp field that was present in all entries as the event time.
+func markEventTime(fields []logschema.FieldSchema) {
+	candidate := -1
+	rank := len(eventTimeFieldNames)
+	for i := range fields {
+		field := &fields[i]
+		if field.Type != logschema.TypeTimestamp || !field.Required {
+			continue
+		}
+		r := len(eventTimeFieldNames)
+		for j, name := range eventTimeFieldNames {
+			if strings.EqualFold(field.Name, name) {
+				r = j
+				break
+			}
+		}
+		if candidate == -1 || r < rank {
+			candidate, rank = i, r
+		}
+	}
+	if candidate != -1 {
+		fields[candidate].IsEventTime = true
+	}
+}
+
+// Report validates the draft schema against the sampled entries and reports field coverage.
+// The name is used as the log type name when building a parser for the draft schema.
+func (s *Sampler) Report(name string) *SampleReport {
+	numEvents := len(s.entries)
+	report := SampleReport{
+		NumEvents:  numEvents,
+		NumInvalid: s.numInvalid,
+		Fields:     make([]FieldCoverage, 0, len(s.fields)),
+	}
+	for path, count := range s.fields {
+		report.Fields = append(report.Fields, FieldCoverage{
+			Path:     path,
+			Count:    count,
+			Coverage: float64(count) / float64(numEvents),
+		})
+	}
+	sort.Slice(report.Fields, func(i, j int) bool {
+		return report.Fields[i].Path < report.Fields[j].Path
+	})
+	schema := s.Schema()
+	if schema == nil {
+		return &report
+	}
+	entry, err := Build(name, schema)
+	if err != nil {
+		report.NumParseErrors = numEvents
+		report.ParseError = err.Error()
+		return &report
+	}
+	parser, err := entry.NewParser(nil)
+	if err != nil {
+		report.NumParseErrors = numEvents
+		report.ParseError = err.Error()
+		return &report
+	}
+	for _, e := range s.entries {
+		if _, err := parser.ParseLog(e); err != nil {
+			if report.NumParseErrors == 0 {
+				report.ParseError = err.Error()
+			}
+			report.NumParseErrors++
+		}
+	}
+	return &report
+}
@@ -0,0 +1,88 @@
+package customlogs_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/customlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+func TestSampler(t *testing.T) {
+	const input = `{"time": "2020-01-02 10:11:12", "ip": "10.0.0.1", "user": {"name": "alice"}, "tags": [{"key": "a"}]}
+{"time": "2020-01-02 10:11:13", "ip": "10.0.0.2", "status": 200}
+not json
+{"time": "2020-01-02 10:11:14", "ip": "10.0.0.3", "status": 404}
+`
+	assert := require.New(t)
+	sampler := customlogs.Sampler{
+		MaxEvents: 10,
+	}
+	stream := logstream.NewLineStream(strings.NewReader(input), logstream.DefaultBufferSize)
+	assert.NoError(sampler.SampleStream(stream))
+
+	schema := sampler.Schema()
+	assert.NotNil(schema)
+	fields := map[string]logschema.FieldSchema{}
+	for _, f := range schema.Fields {
+		fields[f.Name] = f
+	}
+	assert.Equal(logschema.TypeTimestamp, fields["time"].Type)
+	assert.Equal("%Y-%m-%d %H:%M:%S", fields["time"].TimeFormat)
+	assert.True(fields["time"].IsEventTime)
+	assert.Equal([]string{"ip"}, fields["ip"].Indicators)
+	assert.True(fields["ip"].Required)
+	assert.False(fields["status"].Required)
+
+	report := sampler.Report("Custom.Test")
+	assert.Equal(3, report.NumEvents)
+	assert.Equal(1, report.NumInvalid)
+	assert.Equal(0, report.NumParseErrors, report.ParseError)
+	coverage := map[string]int{}
+	for _, f := range report.Fields {
+		coverage[f.Path] = f.Count
+	}
+	assert.Equal(map[string]int{
+		"time":       3,
+		"ip":         3,
+		"status":     2,
+		"user":       1,
+		"user.name":  1,
+		"tags":       1,
+		"tags[].key": 1,
+	}, coverage)
+}
+
+func TestSamplerMaxEvents(t *testing.T) {
+	assert := require.New(t)
+	sampler := customlogs.Sampler{
+		MaxEvents: 1,
+	}
+	stream := logstream.NewLineStream(strings.NewReader("{\"a\":1}\n{\"b\":2}\n"), logstream.DefaultBufferSize)
+	assert.NoError(sampler.SampleStream(stream))
+	assert.True(sampler.Full())
+	schema := sampler.Schema()
+	assert.Len(schema.Fields, 1)
+	assert.Equal("a", schema.Fields[0].Name)
+}
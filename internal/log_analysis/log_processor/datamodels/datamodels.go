@@ -0,0 +1,251 @@
+package datamodels
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/pkg/stringset"
+)
+
+// DataModel maps fields of log types to normalized data model fields
+type DataModel struct {
+	ID       string
+	LogTypes []string
+	Mappings []Mapping
+}
+
+// Mapping maps an event field to a data model field.
+// Mappings using a Python method can only be evaluated by the rules engine.
+type Mapping struct {
+	Name   string
+	Path   string
+	Method string
+}
+
+// FieldName normalizes the name of a data model field to be used in the p_udm column
+func FieldName(name string) string {
+	return strings.ToLower(glueschema.ColumnName(name))
+}
+
+// Path is a parsed data model path.
+// Its elements are either object keys (string) or array indexes (int).
+type Path []interface{}
+
+// ParsePath parses the path of a data model mapping.
+//
+// Paths are either a field name or a JSONPath expression selecting a single value (ie `$.foo.bar[0]['baz']`).
+// Wildcards, recursive descent and filters are not supported.
+func ParsePath(path string) (Path, error) {
+	p := strings.TrimSpace(path)
+	switch {
+	case strings.HasPrefix(p, "$."):
+		p = p[2:]
+	case strings.HasPrefix(p, "$["):
+		p = p[1:]
+	}
+	if p == "" || p == "$" {
+		return nil, errors.Errorf("empty path %q", path)
+	}
+	var out Path
+	for p != "" {
+		if p[0] == '[' {
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, errors.Errorf("unterminated bracket in path %q", path)
+			}
+			el, err := parseBracket(p[1:end])
+			if err != nil {
+				return nil, errors.WithMessagef(err, "invalid path %q", path)
+			}
+			out = append(out, el)
+			p = p[end+1:]
+		} else {
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			key := p[:end]
+			if key == "" || key == "*" {
+				return nil, errors.Errorf("unsupported path %q", path)
+			}
+			out = append(out, key)
+			p = p[end:]
+		}
+		if strings.HasPrefix(p, ".") {
+			p = p[1:]
+			if p == "" || p[0] == '.' || p[0] == '[' {
+				return nil, errors.Errorf("unsupported path %q", path)
+			}
+		}
+	}
+	return out, nil
+}
+
+func parseBracket(s string) (interface{}, error) {
+	if n := len(s); n >= 2 && (s[0] == '\'' || s[0] == '"') && s[n-1] == s[0] {
+		return s[1 : n-1], nil
+	}
+	if index, err := strconv.Atoi(s); err == nil && index >= 0 {
+		return index, nil
+	}
+	return nil, errors.Errorf("unsupported selector [%s]", s)
+}
+
+// Normalizer maps event fields of log types to data model fields
+type Normalizer struct {
+	logTypes map[string][]field
+	fields   []string
+}
+
+type field struct {
+	name string
+	path Path
+}
+
+var _ pantherlog.Normalizer = (*Normalizer)(nil)
+
+// New builds a normalizer for data models.
+//
+// Mappings using Python methods are skipped since they can only be evaluated by the rules engine.
+// If some mappings are invalid, it returns a normalizer for the rest of the mappings along with the error.
+func New(models ...DataModel) (*Normalizer, error) {
+	n := Normalizer{
+		logTypes: map[string][]field{},
+	}
+	var err error
+	names := map[string]struct{}{}
+	for i := range models {
+		model := &models[i]
+		fields := make([]field, 0, len(model.Mappings))
+		seen := map[string]struct{}{}
+		for _, mapping := range model.Mappings {
+			if mapping.Path == "" {
+				continue
+			}
+			name := FieldName(mapping.Name)
+			if name == "" {
+				err = multierr.Append(err, errors.Errorf("data model %q has invalid field name %q", model.ID, mapping.Name))
+				continue
+			}
+			if _, duplicate := seen[name]; duplicate {
+				err = multierr.Append(err, errors.Errorf("data model %q has duplicate field %q", model.ID, name))
+				continue
+			}
+			path, pathErr := ParsePath(mapping.Path)
+			if pathErr != nil {
+				err = multierr.Append(err, errors.WithMessagef(pathErr, "data model %q field %q", model.ID, name))
+				continue
+			}
+			seen[name] = struct{}{}
+			names[name] = struct{}{}
+			fields = append(fields, field{
+				name: name,
+				path: path,
+			})
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		for _, logType := range model.LogTypes {
+			n.logTypes[logType] = append(n.logTypes[logType], fields...)
+		}
+	}
+	n.fields = make([]string, 0, len(names))
+	for name := range names {
+		n.fields = append(n.fields, name)
+	}
+	sort.Strings(n.fields)
+	return &n, err
+}
+
+// Fields returns the sorted names of all data model fields.
+func (n *Normalizer) Fields() []string {
+	if n == nil {
+		return nil
+	}
+	return n.fields
+}
+
+// LogTypeFields returns the sorted names of the data model fields mapped for a log type.
+// These are the fields of the p_udm column in the tables of the log type.
+func (n *Normalizer) LogTypeFields(logType string) []string {
+	if n == nil {
+		return nil
+	}
+	var names []string
+	for i := range n.logTypes[logType] {
+		names = stringset.Append(names, n.logTypes[logType][i].name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FieldsByLogType returns the sorted names of the data model fields mapped for each log type
+func (n *Normalizer) FieldsByLogType() map[string][]string {
+	if n == nil {
+		return nil
+	}
+	out := make(map[string][]string, len(n.logTypes))
+	for logType := range n.logTypes {
+		out[logType] = n.LogTypeFields(logType)
+	}
+	return out
+}
+
+// Normalize implements pantherlog.Normalizer interface
+func (n *Normalizer) Normalize(logType string, event []byte) pantherlog.UDM {
+	fields := n.logTypes[logType]
+	if len(fields) == 0 {
+		return nil
+	}
+	var udm pantherlog.UDM
+	for i := range fields {
+		f := &fields[i]
+		value, ok := valueAt(event, f.path)
+		if !ok {
+			continue
+		}
+		if udm == nil {
+			udm = make(pantherlog.UDM, len(fields))
+		}
+		udm[f.name] = value
+	}
+	return udm
+}
+
+// valueAt converts the JSON value at a path to a string.
+// Strings are unquoted, null values are skipped and all other values are kept as JSON.
+func valueAt(event []byte, path Path) (string, bool) {
+	value := jsoniter.Get(event, path...)
+	switch value.ValueType() {
+	case jsoniter.InvalidValue, jsoniter.NilValue:
+		return "", false
+	default:
+		return value.ToString(), true
+	}
+}
@@ -0,0 +1,111 @@
+package datamodels
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+func TestParsePath(t *testing.T) {
+	for _, tc := range []struct {
+		Path   string
+		Expect Path
+	}{
+		{"srcAddr", Path{"srcAddr"}},
+		{"$.srcAddr", Path{"srcAddr"}},
+		{"$.userIdentity.arn", Path{"userIdentity", "arn"}},
+		{"$.records[0].user", Path{"records", 0, "user"}},
+		{"$['detail-type']", Path{"detail-type"}},
+		{`$.detail["user name"].id`, Path{"detail", "user name", "id"}},
+	} {
+		path, err := ParsePath(tc.Path)
+		require.NoError(t, err, tc.Path)
+		require.Equal(t, tc.Expect, path, tc.Path)
+	}
+	for _, path := range []string{
+		"",
+		"$",
+		"$.records[*].user",
+		"$..user",
+		"$.user.",
+		"$.records[0",
+		"$.records[?(@.user)]",
+	} {
+		_, err := ParsePath(path)
+		require.Error(t, err, path)
+	}
+}
+
+func TestNormalizer(t *testing.T) {
+	assert := require.New(t)
+	normalizer, err := New(
+		DataModel{
+			ID:       "AWS.CloudTrail",
+			LogTypes: []string{"AWS.CloudTrail"},
+			Mappings: []Mapping{
+				{Name: "source_ip", Path: "sourceIPAddress"},
+				{Name: "actor_user", Path: "$.userIdentity.userName"},
+				{Name: "event_type", Method: "get_event_type"},
+				{Name: "request_count", Path: "$.requestParameters.count"},
+				{Name: "resources", Path: "$.resources"},
+			},
+		},
+		DataModel{
+			ID:       "Okta.SystemLog",
+			LogTypes: []string{"Okta.SystemLog"},
+			Mappings: []Mapping{
+				{Name: "Source_IP", Path: "$.client.ipAddress"},
+				{Name: "actor_user", Path: "$.actor.alternateId"},
+				{Name: "invalid", Path: "$.outcome[*]"},
+			},
+		},
+	)
+	assert.Error(err)
+	assert.Equal([]string{"actor_user", "request_count", "resources", "source_ip"}, normalizer.Fields())
+	assert.Equal(map[string][]string{
+		"AWS.CloudTrail": {"actor_user", "request_count", "resources", "source_ip"},
+		"Okta.SystemLog": {"actor_user", "source_ip"},
+	}, normalizer.FieldsByLogType())
+	assert.Nil(normalizer.LogTypeFields("GitLab.API"))
+
+	udm := normalizer.Normalize("AWS.CloudTrail", []byte(`{
+		"sourceIPAddress": "1.1.1.1",
+		"userIdentity": {"userName": "alice"},
+		"requestParameters": {"count": 42},
+		"resources": [{"arn":"foo"}]
+	}`))
+	assert.Equal(pantherlog.UDM{
+		"source_ip":     "1.1.1.1",
+		"actor_user":    "alice",
+		"request_count": "42",
+		"resources":     `[{"arn":"foo"}]`,
+	}, udm)
+
+	udm = normalizer.Normalize("Okta.SystemLog", []byte(`{"client": {"ipAddress": "2.2.2.2"}, "actor": {"alternateId": null}}`))
+	assert.Equal(pantherlog.UDM{
+		"source_ip": "2.2.2.2",
+	}, udm)
+
+	assert.Nil(normalizer.Normalize("Okta.SystemLog", []byte(`{}`)))
+	assert.Nil(normalizer.Normalize("Foo.Bar", []byte(`{"sourceIPAddress": "1.1.1.1"}`)))
+}
@@ -0,0 +1,118 @@
+package datamodels
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	analysismodels "github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/pkg/box"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+// Loader loads data models and caches the resulting normalizer.
+type Loader struct {
+	// ListDataModels lists the data models to apply to events
+	ListDataModels func(ctx context.Context) ([]DataModel, error)
+	// MaxAge is the duration to use the loaded data models before checking for updates
+	MaxAge time.Duration
+	// OnChange is called when the data model fields of any log type change.
+	// The fields of the p_udm column of log tables are updated when data models change.
+	OnChange func()
+
+	mu         sync.Mutex
+	normalizer *Normalizer
+	loadedAt   time.Time
+}
+
+// Normalizer returns a normalizer for the current data models.
+// It returns nil if there are no data model fields to materialize.
+// If some data models fail to load, it returns a normalizer for the rest of the data models along with the error.
+func (l *Loader) Normalizer(ctx context.Context) (pantherlog.Normalizer, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.loadedAt.IsZero() && time.Since(l.loadedAt) < l.MaxAge {
+		return l.currentNormalizer(), nil
+	}
+	models, err := l.ListDataModels(ctx)
+	if err != nil {
+		// Keep using the data models we already have
+		return l.currentNormalizer(), errors.WithMessage(err, "failed to list data models")
+	}
+	normalizer, err := New(models...)
+	changed := !reflect.DeepEqual(normalizer.FieldsByLogType(), l.normalizer.FieldsByLogType())
+	l.normalizer = normalizer
+	l.loadedAt = time.Now()
+	if changed && l.OnChange != nil {
+		l.OnChange()
+	}
+	return l.currentNormalizer(), err
+}
+
+func (l *Loader) currentNormalizer() pantherlog.Normalizer {
+	if len(l.normalizer.Fields()) == 0 {
+		return nil
+	}
+	return l.normalizer
+}
+
+const listPageSize = 1000
+
+// ListEnabled lists the enabled data models using the analysis-api
+func ListEnabled(client gatewayapi.API) ([]DataModel, error) {
+	input := analysismodels.LambdaInput{
+		ListDataModels: &analysismodels.ListDataModelsInput{
+			Enabled:  box.Bool(true),
+			Page:     1,
+			PageSize: listPageSize,
+		},
+	}
+	var models []DataModel
+	for {
+		var output analysismodels.ListDataModelsOutput
+		if _, err := client.Invoke(&input, &output); err != nil {
+			return nil, errors.WithMessage(err, "failed to list data models from analysis-api")
+		}
+		for _, model := range output.Models {
+			mappings := make([]Mapping, len(model.Mappings))
+			for i, m := range model.Mappings {
+				mappings[i] = Mapping{
+					Name:   m.Name,
+					Path:   m.Path,
+					Method: m.Method,
+				}
+			}
+			models = append(models, DataModel{
+				ID:       model.ID,
+				LogTypes: model.LogTypes,
+				Mappings: mappings,
+			})
+		}
+		if output.Paging.ThisPage >= output.Paging.TotalPages {
+			return models, nil
+		}
+		input.ListDataModels.Page++
+	}
+}
@@ -0,0 +1,139 @@
+package datamodels
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	analysismodels "github.com/panther-labs/panther/api/lambda/analysis/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/pkg/box"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+)
+
+func TestLoader(t *testing.T) {
+	models := []DataModel{
+		{
+			ID:       "Foo.Bar",
+			LogTypes: []string{"Foo.Bar"},
+			Mappings: []Mapping{
+				{Name: "source_ip", Path: "remote_ip"},
+			},
+		},
+	}
+	changes := 0
+	loader := Loader{
+		ListDataModels: func(_ context.Context) ([]DataModel, error) {
+			return models, nil
+		},
+		MaxAge: -1,
+		OnChange: func() {
+			changes++
+		},
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+	event := []byte(`{"remote_ip":"1.1.1.1","client_ip":"2.2.2.2"}`)
+
+	normalizer, err := loader.Normalizer(ctx)
+	assert.NoError(err)
+	assert.Equal(pantherlog.UDM{"source_ip": "1.1.1.1"}, normalizer.Normalize("Foo.Bar", event))
+	assert.Equal(1, changes)
+
+	// Updated data models are reloaded
+	models[0].Mappings[0].Path = "client_ip"
+	normalizer, err = loader.Normalizer(ctx)
+	assert.NoError(err)
+	assert.Equal(pantherlog.UDM{"source_ip": "2.2.2.2"}, normalizer.Normalize("Foo.Bar", event))
+	// The fields of the log type did not change
+	assert.Equal(1, changes)
+
+	// Without any fields there is nothing to normalize
+	models = nil
+	normalizer, err = loader.Normalizer(ctx)
+	assert.NoError(err)
+	assert.Nil(normalizer)
+	assert.Equal(2, changes)
+
+	// Cached data models are used until they expire
+	loader.MaxAge = time.Hour
+	loader.ListDataModels = func(_ context.Context) ([]DataModel, error) {
+		return nil, errors.New("data models should not be listed")
+	}
+	normalizer, err = loader.Normalizer(ctx)
+	assert.NoError(err)
+	assert.Nil(normalizer)
+}
+
+func TestListEnabled(t *testing.T) {
+	assert := require.New(t)
+	client := &gatewayapi.MockClient{}
+	page := func(n int, models ...analysismodels.DataModel) {
+		input := &analysismodels.LambdaInput{
+			ListDataModels: &analysismodels.ListDataModelsInput{
+				Enabled:  box.Bool(true),
+				Page:     n,
+				PageSize: listPageSize,
+			},
+		}
+		client.On("Invoke", input, mock.Anything).Return(http.StatusOK, nil, &analysismodels.ListDataModelsOutput{
+			Models: models,
+			Paging: analysismodels.Paging{
+				ThisPage:   n,
+				TotalPages: 2,
+			},
+		}).Once()
+	}
+	page(1, analysismodels.DataModel{
+		ID:       "Foo.Bar",
+		LogTypes: []string{"Foo.Bar"},
+		Mappings: []analysismodels.DataModelMapping{
+			{Name: "source_ip", Path: "remote_ip"},
+		},
+	})
+	page(2, analysismodels.DataModel{
+		ID:       "Foo.Baz",
+		LogTypes: []string{"Foo.Baz"},
+		Mappings: []analysismodels.DataModelMapping{
+			{Name: "event_type", Method: "get_event_type"},
+		},
+	})
+	models, err := ListEnabled(client)
+	assert.NoError(err)
+	assert.Equal([]DataModel{
+		{
+			ID:       "Foo.Bar",
+			LogTypes: []string{"Foo.Bar"},
+			Mappings: []Mapping{{Name: "source_ip", Path: "remote_ip"}},
+		},
+		{
+			ID:       "Foo.Baz",
+			LogTypes: []string{"Foo.Baz"},
+			Mappings: []Mapping{{Name: "event_type", Method: "get_event_type"}},
+		},
+	}, models)
+	client.AssertExpectations(t)
+}
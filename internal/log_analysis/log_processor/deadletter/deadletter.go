@@ -0,0 +1,207 @@
+package deadletter
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/notify"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+const (
+	// DefaultMaxBufferSize is the size of compressed data buffered before writing to S3
+	DefaultMaxBufferSize = 10 * 1024 * 1024
+
+	// The timestamp layout used in the S3 object key filename part with second precision: yyyyMMddTHHmmssZ
+	s3ObjectTimestampLayout = "20060102T150405Z"
+)
+
+var jsonAPI = common.ConfigForDataLakeWriters()
+
+// Entry is a log line that failed to classify
+type Entry struct {
+	CaptureTime  time.Time         `json:"p_capture_time" tcodec:"layout=2006-01-02 15:04:05.000000000" description:"The time the log line failed to classify"`
+	SourceID     string            `json:"p_source_id" description:"The id of the source the log line came from"`
+	SourceLabel  string            `json:"p_source_label" description:"The label of the source the log line came from"`
+	S3Bucket     string            `json:"s3_bucket,omitempty" description:"The S3 bucket of the object the log line came from"`
+	S3ObjectKey  string            `json:"s3_object_key,omitempty" description:"The S3 key of the object the log line came from"`
+	LineNumber   uint64            `json:"line_number" description:"The line number of the log line in its input"`
+	Line         string            `json:"line" description:"The log line"`
+	ParserErrors map[string]string `json:"parser_errors,omitempty" description:"The errors of the parsers that were tried by log type"`
+}
+
+// GlueTableMetadata returns the metadata of the table holding log lines that failed to classify
+func GlueTableMetadata() *awsglue.GlueTableMetadata {
+	return awsglue.NewGlueTableMetadata(
+		pantherdb.LogProcessingDatabase,
+		pantherdb.DeadLettersTable,
+		pantherdb.DeadLettersTableDescription,
+		awsglue.GlueTableHourly,
+		&Entry{},
+	)
+}
+
+// Sink captures log lines that failed to classify
+type Sink interface {
+	Write(entry *Entry) error
+	Flush() error
+}
+
+var _ Sink = (*Writer)(nil)
+
+// Writer buffers log lines that failed to classify and writes them to the dead letters table.
+// Entries are partitioned by the hour they were captured.
+// A nil Writer discards all entries. It is safe to use concurrently.
+type Writer struct {
+	S3Uploader s3manageriface.UploaderAPI
+	SNSClient  snsiface.SNSAPI
+	Bucket     string
+	TopicARN   string
+	// MaxBufferSize is the size of compressed data buffered before writing to S3, if zero DefaultMaxBufferSize is used
+	MaxBufferSize int
+
+	mu      sync.Mutex
+	buffers map[time.Time]*buffer
+	size    int
+}
+
+type buffer struct {
+	data   bytes.Buffer
+	writer *gzip.Writer
+}
+
+// Write buffers an entry, writing all buffers to S3 if they are full
+func (w *Writer) Write(entry *Entry) error {
+	if w == nil {
+		return nil
+	}
+	data, err := jsonAPI.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode dead letter entry")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	hour := entry.CaptureTime.UTC().Truncate(time.Hour)
+	buf, ok := w.buffers[hour]
+	if !ok {
+		buf = &buffer{}
+		buf.writer = gzip.NewWriter(&buf.data)
+		if w.buffers == nil {
+			w.buffers = make(map[time.Time]*buffer)
+		}
+		w.buffers[hour] = buf
+	}
+	size := buf.data.Len()
+	if _, err := buf.writer.Write(data); err != nil {
+		return errors.Wrap(err, "failed to buffer dead letter entry")
+	}
+	if _, err := buf.writer.Write([]byte("\n")); err != nil {
+		return errors.Wrap(err, "failed to buffer dead letter entry")
+	}
+	w.size += buf.data.Len() - size
+
+	maxSize := w.MaxBufferSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBufferSize
+	}
+	if w.size < maxSize {
+		return nil
+	}
+	return w.flush()
+}
+
+// Flush writes all buffered entries to S3
+func (w *Writer) Flush() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+func (w *Writer) flush() (err error) {
+	for hour, buf := range w.buffers {
+		err = multierr.Append(err, w.send(hour, buf))
+	}
+	w.buffers = nil
+	w.size = 0
+	return err
+}
+
+func (w *Writer) send(hour time.Time, buf *buffer) error {
+	if err := buf.writer.Close(); err != nil {
+		return errors.Wrap(err, "failed to close dead letters buffer")
+	}
+	payload := buf.data.Bytes()
+	key := S3ObjectKey(hour)
+	if _, err := w.S3Uploader.Upload(&s3manager.UploadInput{
+		Bucket: &w.Bucket,
+		Key:    &key,
+		Body:   bytes.NewReader(payload),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to upload dead letters to s3://%s/%s", w.Bucket, key)
+	}
+
+	// The notification creates the partition of the object in the dead letters table
+	notification, err := jsoniter.MarshalToString(notify.NewS3ObjectPutNotification(w.Bucket, key, len(payload)))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification")
+	}
+	input := &sns.PublishInput{
+		TopicArn:          &w.TopicARN,
+		Message:           &notification,
+		MessageAttributes: notify.NewLogAnalysisSNSMessageAttributes(pantherdb.DeadLetters, pantherdb.DeadLettersTable),
+	}
+	if _, err := w.SNSClient.Publish(input); err != nil {
+		return errors.Wrap(err, "failed to send notification to topic")
+	}
+	return nil
+}
+
+// S3ObjectKey builds the S3 object key for storing a partition file of dead letters
+func S3ObjectKey(hour time.Time) string {
+	partitionPrefix := awsglue.PartitionPrefix(pantherdb.LogProcessingDatabase, pantherdb.DeadLettersTable, awsglue.GlueTableHourly, hour)
+	filename := fmt.Sprintf("%s-%s.json.gz", hour.Format(s3ObjectTimestampLayout), uuid.New())
+	return path.Join(partitionPrefix, filename)
+}
+
+// TablePrefix is the S3 prefix of all objects in the dead letters table
+func TablePrefix() string {
+	return awsglue.TablePrefix(pantherdb.LogProcessingDatabase, pantherdb.DeadLettersTable)
+}
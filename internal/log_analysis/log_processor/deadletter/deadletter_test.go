@@ -0,0 +1,126 @@
+package deadletter
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestWriter(t *testing.T) {
+	uploader := &testutils.S3UploaderMock{}
+	snsClient := &testutils.SnsMock{}
+	w := &Writer{
+		S3Uploader: uploader,
+		SNSClient:  snsClient,
+		Bucket:     "bucket",
+		TopicARN:   "topic",
+	}
+	var uploads []*s3manager.UploadInput
+	uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Run(func(args mock.Arguments) {
+		uploads = append(uploads, args.Get(0).(*s3manager.UploadInput))
+	}).Twice()
+	snsClient.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Twice()
+
+	tm := time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC)
+	require.NoError(t, w.Write(&Entry{
+		CaptureTime: tm,
+		SourceID:    "source-id",
+		SourceLabel: "source-label",
+		S3Bucket:    "input",
+		S3ObjectKey: "input.log",
+		LineNumber:  1,
+		Line:        "foo",
+		ParserErrors: map[string]string{
+			"Foo.Bar": "failed",
+		},
+	}))
+	require.NoError(t, w.Write(&Entry{
+		CaptureTime: tm.Add(time.Hour),
+		SourceID:    "source-id",
+		LineNumber:  2,
+		Line:        "bar",
+	}))
+	// Nothing is written until the writer is flushed
+	uploader.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything)
+	require.NoError(t, w.Flush())
+	// Flushing an empty writer is a no-op
+	require.NoError(t, w.Flush())
+	uploader.AssertExpectations(t)
+	snsClient.AssertExpectations(t)
+
+	lines := map[string]string{}
+	for _, input := range uploads {
+		require.Equal(t, "bucket", aws.StringValue(input.Bucket))
+		key := aws.StringValue(input.Key)
+		require.True(t, strings.HasPrefix(key, TablePrefix()), key)
+		require.True(t, strings.HasSuffix(key, ".json.gz"), key)
+		r, err := gzip.NewReader(input.Body)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		lines[key[:len(TablePrefix())+len("year=2020/month=01/day=01/hour=10")]] = string(data)
+	}
+	// nolint:lll
+	require.Equal(t, map[string]string{
+		"logs/panther_dead_letters/year=2020/month=01/day=01/hour=10": `{"p_capture_time":"2020-01-01 10:30:00.000000000","p_source_id":"source-id","p_source_label":"source-label","s3_bucket":"input","s3_object_key":"input.log","line_number":1,"line":"foo","parser_errors":{"Foo.Bar":"failed"}}` + "\n",
+		"logs/panther_dead_letters/year=2020/month=01/day=01/hour=11": `{"p_capture_time":"2020-01-01 11:30:00.000000000","p_source_id":"source-id","line_number":2,"line":"bar"}` + "\n",
+	}, lines)
+
+	publish := snsClient.Calls[0].Arguments.Get(0).(*sns.PublishInput)
+	require.Equal(t, "topic", aws.StringValue(publish.TopicArn))
+	require.Equal(t, string(pantherdb.DeadLetters), aws.StringValue(publish.MessageAttributes["type"].StringValue))
+}
+
+func TestWriterMaxBufferSize(t *testing.T) {
+	uploader := &testutils.S3UploaderMock{}
+	snsClient := &testutils.SnsMock{}
+	w := &Writer{
+		S3Uploader:    uploader,
+		SNSClient:     snsClient,
+		MaxBufferSize: 1,
+	}
+	uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Once()
+	snsClient.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+	require.NoError(t, w.Write(&Entry{
+		CaptureTime: time.Now(),
+		Line:        string(bytes.Repeat([]byte("x"), 1024)),
+	}))
+	uploader.AssertExpectations(t)
+	snsClient.AssertExpectations(t)
+}
+
+func TestNilWriter(t *testing.T) {
+	var w *Writer
+	require.NoError(t, w.Write(&Entry{Line: "foo"}))
+	require.NoError(t, w.Flush())
+}
@@ -19,10 +19,12 @@ package destinations
  */
 
 import (
+	"context"
+
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
 )
 
 // Destination defines the interface that all Destinations should follow
 type Destination interface {
-	SendEvents(parsedEventChannel chan *parsers.Result, errChan chan error)
+	SendEvents(ctx context.Context, parsedEventChannel chan *parsers.Result, errChan chan error)
 }
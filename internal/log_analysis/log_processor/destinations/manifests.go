@@ -0,0 +1,100 @@
+package destinations
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
+)
+
+// manifestBatch collects the data files uploaded to tables with the manifest layout,
+// so that each partition is committed once for all the files written by an invocation.
+// It is safe to use concurrently.
+type manifestBatch struct {
+	mu         sync.Mutex
+	partitions map[manifestPartitionKey]*manifestPartition
+}
+
+type manifestPartitionKey struct {
+	prefix string
+	hour   int64
+}
+
+type manifestPartition struct {
+	manifests *tablemeta.Table
+	hour      time.Time
+	files     []tablemeta.DataFile
+}
+
+// add collects an uploaded data file for the table manifests
+func (b *manifestBatch) add(t *tableFormat, hour time.Time, key string, rows, size int) {
+	if t.manifests == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	k := manifestPartitionKey{
+		prefix: t.manifests.Prefix,
+		hour:   hour.Unix(),
+	}
+	p, ok := b.partitions[k]
+	if !ok {
+		if b.partitions == nil {
+			b.partitions = make(map[manifestPartitionKey]*manifestPartition)
+		}
+		p = &manifestPartition{
+			manifests: t.manifests,
+			hour:      hour,
+		}
+		b.partitions[k] = p
+	}
+	p.files = append(p.files, tablemeta.DataFile{
+		Key:      key,
+		Format:   t.format,
+		SchemaID: t.schemaID,
+		Rows:     int64(rows),
+		Size:     int64(size),
+	})
+}
+
+// commit adds the collected files to the table manifests.
+// The files are already uploaded and announced, so failing here would only duplicate them when the input is retried.
+// Files that could not be committed are left pending for the compactor to reconcile.
+func (b *manifestBatch) commit(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, p := range b.partitions {
+		delete(b.partitions, k)
+		err := p.manifests.AppendFiles(ctx, p.hour, p.files...)
+		if err == nil {
+			continue
+		}
+		log := zap.L().With(zap.String("table", p.manifests.Prefix), zap.Time("hour", p.hour), zap.Int("numFiles", len(p.files)))
+		log.Warn("failed to commit files to table manifests, leaving them pending", zap.Error(err))
+		if err := p.manifests.AppendPending(ctx, p.hour, p.files...); err != nil {
+			// Untracked files are still merged by the compactor
+			log.Error("failed to store pending files of table manifests", zap.Error(err))
+		}
+	}
+}
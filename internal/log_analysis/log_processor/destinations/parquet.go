@@ -0,0 +1,219 @@
+package destinations
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueparquet"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+	"github.com/panther-labs/panther/pkg/awsutils"
+)
+
+// tableFormat is the storage format of a log table
+type tableFormat struct {
+	format awsglue.DataFormat
+	// schema is used to write Parquet files, it is nil for JSON tables
+	schema *glueparquet.Schema
+	// manifests track the data files of tables with the manifest layout, it is nil for other tables
+	manifests *tablemeta.Table
+	// schemaID is the id of the table schema in the manifests
+	schemaID string
+}
+
+var jsonTableFormat = &tableFormat{format: awsglue.DataFormatJSON}
+
+// How often we check if the columns of a table have been updated
+const tableFormatsMaxAge = 5 * time.Minute
+
+var (
+	// sharedTableFormats caches the table formats across invocations
+	sharedTableFormats     *tableFormats
+	sharedTableFormatsOnce sync.Once
+)
+
+func defaultTableFormats() *tableFormats {
+	sharedTableFormatsOnce.Do(func() {
+		sharedTableFormats = &tableFormats{
+			glueClient: common.GlueClient,
+			store: &tablemeta.S3Store{
+				S3:     common.S3Client,
+				Bucket: common.Config.ProcessedDataBucket,
+			},
+			maxAge: tableFormatsMaxAge,
+		}
+	})
+	return sharedTableFormats
+}
+
+// InvalidateTableFormats drops the cached formats of all log tables.
+// It should be called when the columns of log tables are updated (ie when data models change),
+// so that the next buffers are written with the updated Parquet schema.
+func InvalidateTableFormats() {
+	defaultTableFormats().invalidate()
+}
+
+// tableFormats resolves the storage format of log tables from their Glue table definitions.
+// Results are cached since all buffers of a log type are written in the same format.
+// It is safe to use concurrently.
+type tableFormats struct {
+	glueClient glueiface.GlueAPI
+	mu         sync.Mutex
+	tables     map[string]*cachedTableFormat
+	// store is used for the manifests of tables with the manifest layout
+	store tablemeta.Store
+	// maxAge is the duration to use a cached table format before fetching the table again (zero means forever)
+	maxAge time.Duration
+}
+
+type cachedTableFormat struct {
+	*tableFormat
+	fetchedAt time.Time
+}
+
+func (t *tableFormats) lookup(ctx context.Context, logType string) (*tableFormat, error) {
+	// Without a Glue client all data is written as JSON (ie when running devtools locally)
+	if t == nil || t.glueClient == nil {
+		return jsonTableFormat, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tbl, ok := t.tables[logType]; ok && (t.maxAge == 0 || time.Since(tbl.fetchedAt) < t.maxAge) {
+		return tbl.tableFormat, nil
+	}
+	tbl, err := t.fetch(ctx, logType)
+	if err != nil {
+		return nil, err
+	}
+	if t.tables == nil {
+		t.tables = make(map[string]*cachedTableFormat)
+	}
+	t.tables[logType] = &cachedTableFormat{
+		tableFormat: tbl,
+		fetchedAt:   time.Now(),
+	}
+	return tbl, nil
+}
+
+// invalidate drops all cached table formats
+func (t *tableFormats) invalidate() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tables = nil
+}
+
+func (t *tableFormats) fetch(ctx context.Context, logType string) (*tableFormat, error) {
+	db := pantherdb.DatabaseName(pantherdb.GetDataType(logType))
+	tableName := pantherdb.TableName(logType)
+	out, err := awsglue.GetTable(t.glueClient, db, tableName)
+	if err != nil {
+		// Tables are created when sources are onboarded, a missing table will get JSON partitions as before
+		if awsutils.IsAnyError(err, glue.ErrCodeEntityNotFoundException) {
+			return jsonTableFormat, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get table %s.%s", db, tableName)
+	}
+	desc := out.Table.StorageDescriptor
+	tbl := *jsonTableFormat // copy because we will mutate
+	if awsglue.DataFormatFromStorageDescriptor(desc) == awsglue.DataFormatParquet {
+		schema, err := glueparquet.NewSchema(desc.Columns)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid Parquet schema for table %s.%s", db, tableName)
+		}
+		tbl.format = awsglue.DataFormatParquet
+		tbl.schema = schema
+	}
+	if t.store != nil && awsglue.TableLayoutFromTable(out.Table) == awsglue.TableLayoutManifest {
+		tbl.manifests = &tablemeta.Table{
+			Store:  t.store,
+			Prefix: awsglue.TablePrefix(db, tableName),
+		}
+		// Data files reference the schema they were written with so that columns can be added to the table later on
+		schemaID, err := tbl.manifests.PutSchema(ctx, schemaColumns(desc.Columns))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to store schema for table %s.%s", db, tableName)
+		}
+		tbl.schemaID = schemaID
+	}
+	return &tbl, nil
+}
+
+func schemaColumns(cols []*glue.Column) []glueschema.Column {
+	columns := make([]glueschema.Column, 0, len(cols))
+	for _, col := range cols {
+		columns = append(columns, glueschema.Column{
+			Name: aws.StringValue(col.Name),
+			Type: glueschema.Type(aws.StringValue(col.Type)),
+		})
+	}
+	return columns
+}
+
+// encode converts the gzip compressed JSON lines of a buffer to the table format
+func (t *tableFormat) encode(data []byte) ([]byte, error) {
+	if t.schema == nil {
+		return data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read buffer")
+	}
+	out := bytes.Buffer{}
+	w, err := t.schema.NewWriter(&out)
+	if err != nil {
+		return nil, err
+	}
+	lines := bufio.NewReader(r)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err := w.WriteJSON(line); err != nil {
+				return nil, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read buffer")
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package destinations
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/glue"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueparquet"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestTableFormats(t *testing.T) {
+	glueMock := &testutils.GlueMock{}
+	parquetTable := &glue.GetTableOutput{
+		Table: &glue.TableData{
+			StorageDescriptor: awsglue.DataFormatParquet.WithStorageDescriptor(&glue.StorageDescriptor{
+				Columns: []*glue.Column{
+					{Name: aws.String("name"), Type: aws.String("string")},
+					{Name: aws.String("count"), Type: aws.String("bigint")},
+				},
+			}),
+		},
+	}
+	glueMock.On("GetTable", &glue.GetTableInput{
+		DatabaseName: aws.String("panther_logs"),
+		Name:         aws.String("foo_parquet"),
+	}).Return(parquetTable, nil).Twice()
+	glueMock.On("GetTable", &glue.GetTableInput{
+		DatabaseName: aws.String("panther_logs"),
+		Name:         aws.String("foo_missing"),
+	}).Return(&glue.GetTableOutput{}, awserr.New(glue.ErrCodeEntityNotFoundException, "not found", nil)).Once()
+	glueMock.On("GetTable", &glue.GetTableInput{
+		DatabaseName: aws.String("panther_logs"),
+		Name:         aws.String("foo_fail"),
+	}).Return(&glue.GetTableOutput{}, awserr.New(glue.ErrCodeInternalServiceException, "failed", nil)).Once()
+
+	formats := &tableFormats{glueClient: glueMock}
+	tbl, err := formats.lookup(context.Background(), "Foo.Parquet")
+	require.NoError(t, err)
+	require.Equal(t, awsglue.DataFormatParquet, tbl.format)
+	require.NotNil(t, tbl.schema)
+	// Check that results are cached
+	cached, err := formats.lookup(context.Background(), "Foo.Parquet")
+	require.NoError(t, err)
+	require.Same(t, tbl, cached)
+	// Check that tables are fetched again once invalidated
+	formats.invalidate()
+	refreshed, err := formats.lookup(context.Background(), "Foo.Parquet")
+	require.NoError(t, err)
+	require.NotSame(t, tbl, refreshed)
+	require.Equal(t, awsglue.DataFormatParquet, refreshed.format)
+
+	tbl, err = formats.lookup(context.Background(), "Foo.Missing")
+	require.NoError(t, err)
+	require.Equal(t, awsglue.DataFormatJSON, tbl.format)
+
+	_, err = formats.lookup(context.Background(), "Foo.Fail")
+	require.Error(t, err)
+	glueMock.AssertExpectations(t)
+
+	// Without a Glue client all tables are JSON
+	tbl, err = (&tableFormats{}).lookup(context.Background(), "Foo.Parquet")
+	require.NoError(t, err)
+	require.Equal(t, jsonTableFormat, tbl)
+}
+
+func TestTableFormat_Encode(t *testing.T) {
+	var data []byte
+	{
+		buf := bytes.Buffer{}
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write([]byte(`{"name":"foo","count":1}` + "\n" + `{"name":"bar","count":2}` + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		data = buf.Bytes()
+	}
+
+	// JSON data is left as is
+	out, err := jsonTableFormat.encode(data)
+	require.NoError(t, err)
+	require.Equal(t, data, out)
+
+	schema, err := glueparquet.NewSchema([]*glue.Column{
+		{Name: aws.String("name"), Type: aws.String("string")},
+		{Name: aws.String("count"), Type: aws.String("bigint")},
+	})
+	require.NoError(t, err)
+	tbl := &tableFormat{
+		format: awsglue.DataFormatParquet,
+		schema: schema,
+	}
+	out, err = tbl.encode(data)
+	require.NoError(t, err)
+	f, err := buffer.NewBufferFile(out)
+	require.NoError(t, err)
+	r, err := reader.NewParquetReader(f, nil, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.GetNumRows())
+	r.ReadStop()
+}
+
+func TestGetS3ObjectKey(t *testing.T) {
+	buf := &s3EventBuffer{
+		logType: "Foo.Bar",
+		hour:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	key := getS3ObjectKey(buf, awsglue.DataFormatParquet)
+	require.True(t, strings.HasPrefix(key, "logs/foo_bar/year=2020/month=01/day=01/hour=00/20200101T000000Z-"), key)
+	require.True(t, strings.HasSuffix(key, ".parquet"), key)
+	key = getS3ObjectKey(buf, awsglue.DataFormatJSON)
+	require.True(t, strings.HasSuffix(key, ".json.gz"), key)
+}
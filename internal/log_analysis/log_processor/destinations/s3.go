@@ -21,6 +21,7 @@ package destinations
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"path"
 	"runtime"
@@ -92,6 +93,7 @@ func CreateS3Destination(jsonAPI jsoniter.API) Destination {
 		maxDuration:         maxDuration,
 		maxBuffers:          maxBuffers,
 		jsonAPI:             jsonAPI,
+		tableFormats:        defaultTableFormats(),
 	}
 }
 
@@ -129,6 +131,8 @@ type S3Destination struct {
 	latencyCounter      metrics.Counter
 	outputFiles         metrics.Counter
 	outputBytes         metrics.Counter
+	// tableFormats resolves the format of the S3 objects for each log type
+	tableFormats *tableFormats
 }
 
 // SendEvents stores events in S3.
@@ -136,7 +140,7 @@ type S3Destination struct {
 // and stores them in the appropriate S3 path. If the method encounters an error
 // it writes an error to the errorChannel and continues until channel is closed (skipping events).
 // The sendData() method is called as go routine to allow processing to continue and hide network latency.
-func (d *S3Destination) SendEvents(parsedEventChannel chan *parsers.Result, errChan chan error) {
+func (d *S3Destination) SendEvents(ctx context.Context, parsedEventChannel chan *parsers.Result, errChan chan error) {
 	// used to flush expired buffers
 	flushExpired := time.NewTicker(d.maxDuration)
 	defer flushExpired.Stop()
@@ -145,6 +149,8 @@ func (d *S3Destination) SendEvents(parsedEventChannel chan *parsers.Result, errC
 	var sendWaitGroup sync.WaitGroup
 	// FIXME: We risk a panic causing a memory leak by never exiting the write goroutine (see below).
 	sendChan := make(chan *s3EventBuffer) // unbuffered for back pressure
+	// uploaded files are committed to table manifests once all writes are done
+	manifests := &manifestBatch{}
 
 	for i := 0; i < numberConcurrentUploads; i++ {
 		sendWaitGroup.Add(1)
@@ -152,7 +158,7 @@ func (d *S3Destination) SendEvents(parsedEventChannel chan *parsers.Result, errC
 			// Make sure a panic does not prevent SendEvents from exiting
 			defer sendWaitGroup.Done()
 			for buffer := range sendChan {
-				d.sendData(buffer, errChan)
+				d.sendData(ctx, buffer, manifests, errChan)
 			}
 		}()
 	}
@@ -218,12 +224,13 @@ func (d *S3Destination) SendEvents(parsedEventChannel chan *parsers.Result, errC
 	// Write failures should also abort the whole log processing so this is more complex than it looks.
 	close(sendChan)
 	sendWaitGroup.Wait() // wait until all writes to s3 are done
+	manifests.commit(ctx)
 
 	zap.L().Debug("finished sending s3 files")
 }
 
 // sendData puts data in S3 and sends notification to SNS
-func (d *S3Destination) sendData(buffer *s3EventBuffer, errChan chan error) {
+func (d *S3Destination) sendData(ctx context.Context, buffer *s3EventBuffer, manifests *manifestBatch, errChan chan error) {
 	if buffer.events == 0 { // skip empty buffers
 		return
 	}
@@ -233,18 +240,56 @@ func (d *S3Destination) sendData(buffer *s3EventBuffer, errChan chan error) {
 		key string
 	)
 
-	key = getS3ObjectKey(buffer)
+	table, err := d.tableFormats.lookup(ctx, buffer.logType)
 	if err != nil {
 		errChan <- err
 		return
 	}
 
-	payload, err := buffer.read()
+	key = getS3ObjectKey(buffer, table.format)
+
+	data, err := buffer.read()
 	if err != nil {
 		errChan <- err
 		return
 	}
+	payload, err := table.encode(data)
+	if err != nil {
+		errChan <- errors.Wrapf(err, "failed to encode %s data", table.format)
+		return
+	}
+
+	// Processed data consumers (rules engine, cloud security) read gzip JSON.
+	// For other formats we upload a copy of the JSON data and announce that instead of the data file.
+	// The copy is uploaded first so that a failure does not leave data files behind to be duplicated on retry.
+	notifyKey := key
+	buffer.bytes = len(payload) // the notification reports the size of the object
+	if table.format != awsglue.DataFormatJSON {
+		notifyKey = awsglue.NotificationCopyKey(key)
+		buffer.bytes = len(data)
+		if err := d.upload(notifyKey, data); err != nil {
+			errChan <- err
+			return
+		}
+	}
+
+	if err := d.upload(key, payload); err != nil {
+		errChan <- err
+		return
+	}
 
+	// The file is only visible to manifest readers once it is committed
+	manifests.add(table, buffer.hour, key, buffer.events, len(payload))
+
+	err = d.sendSNSNotification(notifyKey, buffer) // if send fails we fail whole operation
+	if err != nil {
+		errChan <- err
+	}
+	d.outputBytes.Add(float64(len(payload)))
+	d.outputFiles.Add(1)
+}
+
+func (d *S3Destination) upload(key string, payload []byte) error {
 	if _, err := d.s3Uploader.Upload(&s3manager.UploadInput{
 		Bucket: &d.s3Bucket,
 		Key:    &key,
@@ -253,16 +298,9 @@ func (d *S3Destination) sendData(buffer *s3EventBuffer, errChan chan error) {
 		u.Concurrency = (len(payload) / uploaderPartSize) + 1 // if it evenly divides an extra won't matter
 		u.PartSize = uploaderPartSize
 	}); err != nil {
-		errChan <- errors.Wrap(err, "S3Upload")
-		return
+		return errors.Wrap(err, "S3Upload")
 	}
-
-	err = d.sendSNSNotification(key, buffer) // if send fails we fail whole operation
-	if err != nil {
-		errChan <- err
-	}
-	d.outputBytes.Add(float64(len(payload)))
-	d.outputFiles.Add(1)
+	return nil
 }
 
 func (d *S3Destination) sendSNSNotification(key string, buffer *s3EventBuffer) error {
@@ -288,14 +326,15 @@ func (d *S3Destination) sendSNSNotification(key string, buffer *s3EventBuffer) e
 }
 
 // getS3ObjectKey builds the S3 object key for storing a partition file of processed logs.
-func getS3ObjectKey(buf *s3EventBuffer) string {
+func getS3ObjectKey(buf *s3EventBuffer, format awsglue.DataFormat) string {
 	typ := pantherdb.GetDataType(buf.logType)
 	db := pantherdb.DatabaseName(typ)
 	table := pantherdb.TableName(buf.logType)
 	partitionPrefix := awsglue.PartitionPrefix(db, table, awsglue.GlueTableHourly, buf.hour)
-	filename := fmt.Sprintf("%s-%s.json.gz",
+	filename := fmt.Sprintf("%s-%s%s",
 		buf.hour.Format(S3ObjectTimestampLayout),
 		uuid.New(),
+		format.FileExtension(),
 	)
 	return path.Join(partitionPrefix, filename)
 }
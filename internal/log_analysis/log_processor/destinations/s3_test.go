@@ -21,6 +21,7 @@ package destinations
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"io/ioutil"
 	"strings"
 	"sync"
@@ -28,6 +29,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/glue"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sns"
 	jsoniter "github.com/json-iterator/go"
@@ -38,6 +40,8 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/panther-labs/panther/internal/compliance/snapshotlogs"
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue"
+	"github.com/panther-labs/panther/internal/log_analysis/datalake/tablemeta"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog/null"
@@ -251,6 +255,168 @@ func TestSendDataToS3BeforeTerminating(t *testing.T) {
 	assert.Equal(t, expectedSnsPublishInput, publishInput)
 }
 
+func TestSendDataToS3WithManifests(t *testing.T) {
+	t.Parallel()
+
+	destination := mockDestination()
+	glueMock := &testutils.GlueMock{}
+	glueMock.On("GetTable", mock.Anything).Return(&glue.GetTableOutput{
+		Table: &glue.TableData{
+			Parameters: map[string]*string{
+				awsglue.TableParameterLayout: aws.String(string(awsglue.TableLayoutManifest)),
+			},
+			StorageDescriptor: &glue.StorageDescriptor{
+				Columns: []*glue.Column{{Name: aws.String("foo"), Type: aws.String("string")}},
+			},
+		},
+	}, nil).Once()
+	store := &tablemeta.FSStore{Root: t.TempDir()}
+	destination.tableFormats = &tableFormats{
+		glueClient: glueMock,
+		store:      store,
+	}
+
+	destination.mockLatencyCounter.On("With", mock.Anything).Return(destination.mockLatencyCounter).Once()
+	destination.mockLatencyCounter.On("Add", mock.Anything).Once()
+	destination.mockOutputBytesCounter.On("Add", mock.Anything).Once()
+	destination.mockOutputFilesCounter.On("Add", mock.Anything).Once()
+	destination.mockS3Uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Once()
+	destination.mockSns.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	eventChannel := make(chan *parsers.Result, 1)
+	eventChannel <- newTestResult(nil)
+	close(eventChannel)
+	assert.NoError(t, runDestination(destination, eventChannel))
+	destination.AssertExpectations(t)
+	glueMock.AssertExpectations(t)
+
+	uploadInput := destination.mockS3Uploader.Calls[0].Arguments.Get(0).(*s3manager.UploadInput)
+	table := tablemeta.Table{
+		Store:  store,
+		Prefix: "logs/testlogtype/",
+	}
+	snapshot, err := table.Snapshot(context.Background(), time.Time(refTime))
+	require.NoError(t, err)
+	require.Len(t, snapshot.Files, 1)
+	file := snapshot.Files[0]
+	require.Equal(t, aws.StringValue(uploadInput.Key), file.Key)
+	require.Equal(t, awsglue.DataFormatJSON, file.Format)
+	require.Equal(t, int64(1), file.Rows)
+	schema, err := table.Schema(context.Background(), file.SchemaID)
+	require.NoError(t, err)
+	require.Equal(t, "foo", schema.Columns[0].Name)
+}
+
+func TestSendDataToS3WithManifestsPending(t *testing.T) {
+	t.Parallel()
+
+	destination := mockDestination()
+	glueMock := &testutils.GlueMock{}
+	glueMock.On("GetTable", mock.Anything).Return(&glue.GetTableOutput{
+		Table: &glue.TableData{
+			Parameters: map[string]*string{
+				awsglue.TableParameterLayout: aws.String(string(awsglue.TableLayoutManifest)),
+			},
+			StorageDescriptor: &glue.StorageDescriptor{},
+		},
+	}, nil).Once()
+	store := &tablemeta.FSStore{Root: t.TempDir()}
+	destination.tableFormats = &tableFormats{
+		glueClient: glueMock,
+		store:      &failingCommitStore{FSStore: store},
+	}
+
+	destination.mockLatencyCounter.On("With", mock.Anything).Return(destination.mockLatencyCounter).Once()
+	destination.mockLatencyCounter.On("Add", mock.Anything).Once()
+	destination.mockOutputBytesCounter.On("Add", mock.Anything).Once()
+	destination.mockOutputFilesCounter.On("Add", mock.Anything).Once()
+	destination.mockS3Uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Once()
+	destination.mockSns.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	eventChannel := make(chan *parsers.Result, 1)
+	eventChannel <- newTestResult(nil)
+	close(eventChannel)
+	// The file was uploaded and announced, a failed commit must not fail the invocation
+	assert.NoError(t, runDestination(destination, eventChannel))
+	destination.AssertExpectations(t)
+
+	// The file is committed when the manifests are reconciled
+	uploadInput := destination.mockS3Uploader.Calls[0].Arguments.Get(0).(*s3manager.UploadInput)
+	table := tablemeta.Table{
+		Store:  store,
+		Prefix: "logs/testlogtype/",
+	}
+	n, err := table.Reconcile(context.Background(), time.Time(refTime))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	snapshot, err := table.Snapshot(context.Background(), time.Time(refTime))
+	require.NoError(t, err)
+	require.Len(t, snapshot.Files, 1)
+	require.Equal(t, aws.StringValue(uploadInput.Key), snapshot.Files[0].Key)
+}
+
+// failingCommitStore fails all manifest commits
+type failingCommitStore struct {
+	*tablemeta.FSStore
+}
+
+func (*failingCommitStore) PutIfAbsent(_ context.Context, _ string, _ []byte) error {
+	return errors.New("commit failed")
+}
+
+func TestSendDataToS3WithParquet(t *testing.T) {
+	t.Parallel()
+
+	destination := mockDestination()
+	glueMock := &testutils.GlueMock{}
+	glueMock.On("GetTable", mock.Anything).Return(&glue.GetTableOutput{
+		Table: &glue.TableData{
+			StorageDescriptor: awsglue.DataFormatParquet.WithStorageDescriptor(&glue.StorageDescriptor{
+				Columns: []*glue.Column{{Name: aws.String("foo"), Type: aws.String("string")}},
+			}),
+		},
+	}, nil).Once()
+	destination.tableFormats = &tableFormats{
+		glueClient: glueMock,
+	}
+
+	destination.mockLatencyCounter.On("With", mock.Anything).Return(destination.mockLatencyCounter).Once()
+	destination.mockLatencyCounter.On("Add", mock.Anything).Once()
+	destination.mockOutputBytesCounter.On("Add", mock.Anything).Once()
+	destination.mockOutputFilesCounter.On("Add", mock.Anything).Once()
+	destination.mockS3Uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Twice()
+	destination.mockSns.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	eventChannel := make(chan *parsers.Result, 1)
+	eventChannel <- newTestResult(nil)
+	close(eventChannel)
+	assert.NoError(t, runDestination(destination, eventChannel))
+	destination.AssertExpectations(t)
+	glueMock.AssertExpectations(t)
+
+	// The gzip JSON copy is uploaded before the Parquet data file
+	copyInput := destination.mockS3Uploader.Calls[0].Arguments.Get(0).(*s3manager.UploadInput)
+	dataInput := destination.mockS3Uploader.Calls[1].Arguments.Get(0).(*s3manager.UploadInput)
+	dataKey := aws.StringValue(dataInput.Key)
+	require.True(t, strings.HasPrefix(dataKey, expectedS3Prefix), dataKey)
+	require.True(t, strings.HasSuffix(dataKey, ".parquet"), dataKey)
+	require.Equal(t, awsglue.NotificationCopyKey(dataKey), aws.StringValue(copyInput.Key))
+
+	// Consumers are notified about the copy and can read it as gzip JSON lines
+	publishInput := destination.mockSns.Calls[0].Arguments.Get(0).(*sns.PublishInput)
+	notification := notify.S3Notification{}
+	require.NoError(t, jsoniter.UnmarshalFromString(aws.StringValue(publishInput.Message), &notification))
+	require.Equal(t, aws.StringValue(copyInput.Key), notification.Records[0].S3.Object.Key)
+	body, err := ioutil.ReadAll(copyInput.Body)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(body)), notification.Records[0].S3.Object.Size)
+	r, err := gzip.NewReader(bytes.NewReader(body))
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"foo":"bar"`)
+}
+
 func TestSendDataIfTotalMemSizeLimitHasBeenReached(t *testing.T) {
 	t.Parallel()
 
@@ -612,7 +778,7 @@ func runDestination(destination Destination, events chan *parsers.Result) error
 	errChan := make(chan error, 1)
 	go func() {
 		defer close(errChan)
-		destination.SendEvents(events, errChan)
+		destination.SendEvents(context.Background(), events, errChan)
 	}()
 
 	var foundErr error
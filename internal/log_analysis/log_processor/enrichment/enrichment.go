@@ -0,0 +1,192 @@
+package enrichment
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Table is a lookup table loaded in memory
+type Table struct {
+	// Name is used as the key of the table rows in the p_enrichment field
+	Name string
+	// Fields are the indicator fields to match against the keys of the table
+	Fields []pantherlog.FieldID
+	// Rows maps key column values to the columns of each row
+	Rows map[string]map[string]string
+}
+
+// Enricher joins rows of lookup tables to events by matching indicator values against the keys of each table.
+type Enricher []*Table
+
+var _ pantherlog.Enricher = (Enricher)(nil)
+
+// Enrich implements pantherlog.Enricher interface
+func (tables Enricher) Enrich(values *pantherlog.ValueBuffer) pantherlog.Enrichment {
+	var enrichment pantherlog.Enrichment
+	for _, table := range tables {
+		for _, id := range table.Fields {
+			for _, value := range values.Get(id) {
+				row, ok := table.Rows[value]
+				if !ok {
+					continue
+				}
+				if enrichment == nil {
+					enrichment = pantherlog.Enrichment{}
+				}
+				matches, ok := enrichment[table.Name]
+				if !ok {
+					matches = map[string]map[string]string{}
+					enrichment[table.Name] = matches
+				}
+				matches[value] = row
+			}
+		}
+	}
+	return enrichment
+}
+
+// ReadRows reads the rows of a lookup table keyed by the values of a key column.
+//
+// CSV data require a header row with the column names.
+// JSON data can be either an array of objects or a stream of objects.
+// All column values are converted to strings and rows with an empty key are skipped.
+func ReadRows(r io.Reader, format, keyColumn string) (map[string]map[string]string, error) {
+	switch format {
+	case FormatCSV:
+		return readRowsCSV(r, keyColumn)
+	case FormatJSON:
+		return readRowsJSON(r, keyColumn)
+	default:
+		return nil, errors.Errorf("invalid lookup table format %q", format)
+	}
+}
+
+func readRowsCSV(r io.Reader, keyColumn string) (map[string]map[string]string, error) {
+	rd := csv.NewReader(r)
+	rd.TrimLeadingSpace = true
+	header, err := rd.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CSV header")
+	}
+	keyIndex := -1
+	for i, column := range header {
+		if column == keyColumn {
+			keyIndex = i
+			break
+		}
+	}
+	if keyIndex == -1 {
+		return nil, errors.Errorf("key column %q not found in CSV header", keyColumn)
+	}
+	rows := map[string]map[string]string{}
+	for {
+		record, err := rd.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read CSV row")
+		}
+		key := record[keyIndex]
+		if key == "" {
+			continue
+		}
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows[key] = row
+	}
+}
+
+func readRowsJSON(r io.Reader, keyColumn string) (map[string]map[string]string, error) {
+	br := bufio.NewReader(r)
+	iter := jsoniter.Parse(jsoniter.ConfigDefault, br, 4096)
+	rows := map[string]map[string]string{}
+	addRow := func(obj map[string]jsoniter.RawMessage) {
+		row := make(map[string]string, len(obj))
+		for column, value := range obj {
+			if s, ok := stringValue(value); ok {
+				row[column] = s
+			}
+		}
+		if key := row[keyColumn]; key != "" {
+			rows[key] = row
+		}
+	}
+	for {
+		switch iter.WhatIsNext() {
+		case jsoniter.ArrayValue:
+			iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+				obj := map[string]jsoniter.RawMessage{}
+				iter.ReadVal(&obj)
+				addRow(obj)
+				return iter.Error == nil
+			})
+		case jsoniter.ObjectValue:
+			obj := map[string]jsoniter.RawMessage{}
+			iter.ReadVal(&obj)
+			if iter.Error == nil {
+				addRow(obj)
+			}
+		case jsoniter.InvalidValue:
+			// WhatIsNext reports an invalid value at the end of input
+			if iter.Error == nil || iter.Error == io.EOF {
+				return rows, nil
+			}
+		default:
+			return nil, errors.New("lookup table JSON rows must be objects")
+		}
+		if iter.Error != nil && iter.Error != io.EOF {
+			return nil, errors.Wrap(iter.Error, "failed to read JSON rows")
+		}
+	}
+}
+
+// stringValue converts a JSON value to a string column value.
+// Strings are unquoted, null values are skipped and all other values are kept as JSON.
+func stringValue(value jsoniter.RawMessage) (string, bool) {
+	raw := strings.TrimSpace(string(value))
+	switch {
+	case raw == "" || raw == "null":
+		return "", false
+	case raw[0] == '"':
+		var s string
+		if err := jsoniter.UnmarshalFromString(raw, &s); err != nil {
+			return "", false
+		}
+		return s, true
+	default:
+		return raw, true
+	}
+}
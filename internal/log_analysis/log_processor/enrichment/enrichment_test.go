@@ -0,0 +1,106 @@
+package enrichment
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+func TestReadRowsCSV(t *testing.T) {
+	input := `ip,owner,department
+10.0.0.1,alice,security
+10.0.0.2, bob, engineering
+,nobody,none
+`
+	rows, err := ReadRows(strings.NewReader(input), FormatCSV, "ip")
+	require.NoError(t, err)
+	require.Equal(t, map[string]map[string]string{
+		"10.0.0.1": {"ip": "10.0.0.1", "owner": "alice", "department": "security"},
+		"10.0.0.2": {"ip": "10.0.0.2", "owner": "bob", "department": "engineering"},
+	}, rows)
+
+	_, err = ReadRows(strings.NewReader(input), FormatCSV, "address")
+	require.Error(t, err)
+}
+
+func TestReadRowsJSON(t *testing.T) {
+	expect := map[string]map[string]string{
+		"evil.com":    {"domain": "evil.com", "score": "99", "tags": `["malware"]`},
+		"phishy.info": {"domain": "phishy.info", "score": "42.5"},
+	}
+	array := `[
+		{"domain": "evil.com", "score": 99, "tags": ["malware"]},
+		{"domain": "phishy.info", "score": 42.5, "tags": null},
+		{"score": 1}
+	]`
+	rows, err := ReadRows(strings.NewReader(array), FormatJSON, "domain")
+	require.NoError(t, err)
+	require.Equal(t, expect, rows)
+
+	lines := `{"domain": "evil.com", "score": 99, "tags": ["malware"]}
+{"domain": "phishy.info", "score": 42.5}
+`
+	rows, err = ReadRows(strings.NewReader(lines), FormatJSON, "domain")
+	require.NoError(t, err)
+	require.Equal(t, expect, rows)
+
+	_, err = ReadRows(strings.NewReader(`{"domain": "evil.com"`), FormatJSON, "domain")
+	require.Error(t, err)
+	_, err = ReadRows(strings.NewReader(`"evil.com"`), FormatJSON, "domain")
+	require.Error(t, err)
+	_, err = ReadRows(strings.NewReader(lines), "xml", "domain")
+	require.Error(t, err)
+}
+
+func TestEnricher(t *testing.T) {
+	owners := &Table{
+		Name:   "owners",
+		Fields: []pantherlog.FieldID{pantherlog.FieldIPAddress},
+		Rows: map[string]map[string]string{
+			"10.0.0.1": {"owner": "alice"},
+			"10.0.0.2": {"owner": "bob"},
+		},
+	}
+	badDomains := &Table{
+		Name:   "bad_domains",
+		Fields: []pantherlog.FieldID{pantherlog.FieldDomainName},
+		Rows: map[string]map[string]string{
+			"evil.com": {"score": "99"},
+		},
+	}
+	enricher := Enricher{owners, badDomains}
+
+	values := pantherlog.ValueBuffer{}
+	values.WriteValues(pantherlog.FieldIPAddress, "10.0.0.1", "192.168.1.1")
+	values.WriteValues(pantherlog.FieldDomainName, "example.com")
+	require.Equal(t, pantherlog.Enrichment{
+		"owners": {
+			"10.0.0.1": {"owner": "alice"},
+		},
+	}, enricher.Enrich(&values))
+
+	values.Reset()
+	values.WriteValues(pantherlog.FieldDomainName, "example.com")
+	require.Nil(t, enricher.Enrich(&values))
+}
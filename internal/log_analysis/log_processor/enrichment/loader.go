@@ -0,0 +1,138 @@
+package enrichment
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// TableConfig describes a lookup table to load
+type TableConfig struct {
+	Name       string
+	Revision   int64
+	S3Key      string
+	Format     string
+	KeyColumn  string
+	Indicators []string
+}
+
+// Loader loads lookup tables from S3.
+// Loaded tables are cached and only reloaded when their revision changes.
+type Loader struct {
+	S3     s3iface.S3API
+	Bucket string
+	// ListTables lists the lookup tables to apply to events
+	ListTables func(ctx context.Context) ([]TableConfig, error)
+	// MaxAge is the duration to use the loaded tables before checking for updates
+	MaxAge time.Duration
+
+	mu       sync.Mutex
+	tables   map[string]*cachedTable
+	enricher Enricher
+	loadedAt time.Time
+}
+
+type cachedTable struct {
+	config TableConfig
+	table  *Table
+}
+
+// Enricher returns an enricher for the current lookup tables.
+// It returns nil if there are no lookup tables to apply.
+// If some lookup tables fail to load, it returns an enricher for the rest of the tables along with the error.
+func (l *Loader) Enricher(ctx context.Context) (pantherlog.Enricher, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.loadedAt.IsZero() && time.Since(l.loadedAt) < l.MaxAge {
+		return l.currentEnricher(), nil
+	}
+	configs, err := l.ListTables(ctx)
+	if err != nil {
+		// Keep using the tables we already have
+		return l.currentEnricher(), errors.WithMessage(err, "failed to list lookup tables")
+	}
+	tables := make(map[string]*cachedTable, len(configs))
+	enricher := make(Enricher, 0, len(configs))
+	for i := range configs {
+		config := &configs[i]
+		cached, ok := l.tables[config.Name]
+		if !ok || cached.config.Revision != config.Revision {
+			table, loadErr := l.loadTable(ctx, config)
+			if loadErr != nil {
+				err = multierr.Append(err, loadErr)
+				continue
+			}
+			cached = &cachedTable{
+				config: *config,
+				table:  table,
+			}
+		}
+		tables[config.Name] = cached
+		enricher = append(enricher, cached.table)
+	}
+	l.tables = tables
+	l.enricher = enricher
+	l.loadedAt = time.Now()
+	return l.currentEnricher(), err
+}
+
+func (l *Loader) currentEnricher() pantherlog.Enricher {
+	if len(l.enricher) == 0 {
+		return nil
+	}
+	return l.enricher
+}
+
+func (l *Loader) loadTable(ctx context.Context, config *TableConfig) (*Table, error) {
+	fields := make([]pantherlog.FieldID, 0, len(config.Indicators))
+	for _, name := range config.Indicators {
+		id, ok := pantherlog.IndicatorFieldByNameJSON(name)
+		if !ok {
+			return nil, errors.Errorf("lookup table %q has invalid indicator field %q", config.Name, name)
+		}
+		fields = append(fields, id)
+	}
+	obj, err := l.S3.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(l.Bucket),
+		Key:    aws.String(config.S3Key),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch lookup table %q data", config.Name)
+	}
+	defer obj.Body.Close()
+	rows, err := ReadRows(obj.Body, config.Format, config.KeyColumn)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read lookup table %q", config.Name)
+	}
+	return &Table{
+		Name:   config.Name,
+		Fields: fields,
+		Rows:   rows,
+	}, nil
+}
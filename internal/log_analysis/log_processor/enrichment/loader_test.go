@@ -0,0 +1,108 @@
+package enrichment
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestLoader(t *testing.T) {
+	s3Mock := &testutils.S3Mock{}
+	getObject := func(key, body string) {
+		s3Mock.On("GetObjectWithContext", mock.Anything, &s3.GetObjectInput{
+			Bucket: aws.String("bucket"),
+			Key:    aws.String(key),
+		}, mock.Anything).Return(&s3.GetObjectOutput{
+			Body: ioutil.NopCloser(strings.NewReader(body)),
+		}, nil).Once()
+	}
+	configs := []TableConfig{
+		{
+			Name:       "owners",
+			Revision:   1,
+			S3Key:      "lookup_tables/owners.csv",
+			Format:     FormatCSV,
+			KeyColumn:  "ip",
+			Indicators: []string{"p_any_ip_addresses"},
+		},
+	}
+	loader := Loader{
+		S3:     s3Mock,
+		Bucket: "bucket",
+		ListTables: func(_ context.Context) ([]TableConfig, error) {
+			return configs, nil
+		},
+		MaxAge: -1,
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+
+	getObject("lookup_tables/owners.csv", "ip,owner\n10.0.0.1,alice\n")
+	enricher, err := loader.Enricher(ctx)
+	assert.NoError(err)
+	values := pantherlog.ValueBuffer{}
+	values.WriteValues(pantherlog.FieldIPAddress, "10.0.0.1")
+	assert.Equal(pantherlog.Enrichment{
+		"owners": {"10.0.0.1": {"ip": "10.0.0.1", "owner": "alice"}},
+	}, enricher.Enrich(&values))
+
+	// Same revision does not reload the table
+	_, err = loader.Enricher(ctx)
+	assert.NoError(err)
+	s3Mock.AssertNumberOfCalls(t, "GetObjectWithContext", 1)
+
+	// New revision reloads the table
+	configs[0].Revision = 2
+	getObject("lookup_tables/owners.csv", "ip,owner\n10.0.0.1,bob\n")
+	enricher, err = loader.Enricher(ctx)
+	assert.NoError(err)
+	assert.Equal(pantherlog.Enrichment{
+		"owners": {"10.0.0.1": {"ip": "10.0.0.1", "owner": "bob"}},
+	}, enricher.Enrich(&values))
+
+	// Tables that fail to load are skipped
+	configs[0].Indicators = []string{"p_any_foo"}
+	configs[0].Revision = 3
+	enricher, err = loader.Enricher(ctx)
+	assert.Error(err)
+	assert.Nil(enricher)
+
+	// Cached tables are used until they expire
+	loader.MaxAge = time.Hour
+	loader.ListTables = func(_ context.Context) ([]TableConfig, error) {
+		return nil, errors.New("tables should not be listed")
+	}
+	enricher, err = loader.Enricher(ctx)
+	assert.NoError(err)
+	assert.Nil(enricher)
+	s3Mock.AssertExpectations(t)
+}
@@ -0,0 +1,324 @@
+package eventfilter
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"hash/fnv"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+const (
+	// ActionDrop drops matching events
+	ActionDrop = "drop"
+	// ActionKeep keeps matching events, skipping any rules that follow
+	ActionKeep = "keep"
+	// ActionSample keeps a deterministic sample of matching events
+	ActionSample = "sample"
+)
+
+// Rule decides whether events are stored in the data lake.
+//
+// Rules are evaluated in order and the first rule matching an event decides its fate.
+// Events that match no rules are kept.
+// nolint:lll
+type Rule struct {
+	Action     string      `json:"action" yaml:"action" validate:"oneof=drop keep sample" description:"What to do with matching events (drop, keep or sample)"`
+	LogTypes   []string    `json:"logTypes,omitempty" yaml:"logTypes,omitempty" description:"Apply the rule only to events of these log types (all log types if empty)"`
+	Match      []Condition `json:"match,omitempty" yaml:"match,omitempty" validate:"omitempty,dive" description:"Conditions that must all be true for an event to match (all events match if empty)"`
+	SampleRate float64     `json:"sampleRate,omitempty" yaml:"sampleRate,omitempty" validate:"omitempty,gt=0,lte=1" description:"The fraction of matching events to keep for sample rules"`
+	SampleBy   []string    `json:"sampleBy,omitempty" yaml:"sampleBy,omitempty" description:"The fields whose values decide if an event is in the sample"`
+}
+
+// Condition checks a field of an event.
+// If the field is an array, the condition is true if it is true for any of the values.
+// nolint:lll
+type Condition struct {
+	Field  string   `json:"field" yaml:"field" validate:"required" description:"The path of the field in the event JSON (ie 'dstport' or 'p_any_ip_addresses')"`
+	Op     string   `json:"op" yaml:"op" validate:"oneof=eq in prefix suffix contains regex cidr exists gt gte lt lte" description:"The operator to use"`
+	Value  string   `json:"value,omitempty" yaml:"value,omitempty" description:"The value to check against"`
+	Values []string `json:"values,omitempty" yaml:"values,omitempty" description:"The values to check against for 'in' and 'cidr' operators"`
+	Not    bool     `json:"not,omitempty" yaml:"not,omitempty" description:"Negate the condition"`
+}
+
+// Filter decides which events should be stored according to a list of rules
+type Filter struct {
+	rules []*rule
+}
+
+// Build compiles rules to a filter.
+// It returns a nil filter if there are no rules.
+func Build(rules ...Rule) (*Filter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	f := Filter{
+		rules: make([]*rule, 0, len(rules)),
+	}
+	for i := range rules {
+		r, err := buildRule(&rules[i])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid event filter rule #%d", i)
+		}
+		f.rules = append(f.rules, r)
+	}
+	return &f, nil
+}
+
+// Keep checks if an event should be stored.
+func (f *Filter) Keep(result *pantherlog.Result) bool {
+	if f == nil {
+		return true
+	}
+	var event []byte
+	for _, r := range f.rules {
+		if !r.appliesTo(result.PantherLogType) {
+			continue
+		}
+		// We only serialize the event if a rule applies to its log type.
+		// The encoding is cached on the result, so it is not encoded again when it is stored.
+		if event == nil {
+			data, err := result.EncodeJSON()
+			if err != nil {
+				// Keep events we cannot inspect
+				return true
+			}
+			event = data
+		}
+		if !r.matches(event) {
+			continue
+		}
+		switch r.action {
+		case ActionDrop:
+			return false
+		case ActionSample:
+			return r.sample(event)
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+type rule struct {
+	action     string
+	logTypes   map[string]bool
+	match      []*condition
+	sampleRate float64
+	sampleBy   []string
+}
+
+func buildRule(r *Rule) (*rule, error) {
+	switch r.Action {
+	case ActionDrop, ActionKeep:
+	case ActionSample:
+		if !(0 < r.SampleRate && r.SampleRate <= 1) {
+			return nil, errors.Errorf("invalid sample rate %v", r.SampleRate)
+		}
+		if len(r.SampleBy) == 0 {
+			return nil, errors.New("sample rules require at least one 'sampleBy' field")
+		}
+	default:
+		return nil, errors.Errorf("invalid action %q", r.Action)
+	}
+	out := rule{
+		action:     r.Action,
+		sampleRate: r.SampleRate,
+		sampleBy:   r.SampleBy,
+	}
+	if len(r.LogTypes) > 0 {
+		out.logTypes = make(map[string]bool, len(r.LogTypes))
+		for _, logType := range r.LogTypes {
+			out.logTypes[logType] = true
+		}
+	}
+	for i := range r.Match {
+		c, err := buildCondition(&r.Match[i])
+		if err != nil {
+			return nil, err
+		}
+		out.match = append(out.match, c)
+	}
+	return &out, nil
+}
+
+func (r *rule) appliesTo(logType string) bool {
+	return r.logTypes == nil || r.logTypes[logType]
+}
+
+func (r *rule) matches(event []byte) bool {
+	for _, c := range r.match {
+		if !c.check(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// sample hashes the values of the sample fields to decide if an event is in the sample.
+// Events with the same values always get the same decision.
+func (r *rule) sample(event []byte) bool {
+	if r.sampleRate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	for _, field := range r.sampleBy {
+		_, _ = h.Write([]byte(gjson.GetBytes(event, field).Raw))
+		_, _ = h.Write([]byte{0})
+	}
+	return float64(h.Sum64()) < r.sampleRate*math.MaxUint64
+}
+
+type condition struct {
+	field  string
+	not    bool
+	check1 func(value gjson.Result) bool
+}
+
+func (c *condition) check(event []byte) bool {
+	value := gjson.GetBytes(event, c.field)
+	match := false
+	if value.IsArray() {
+		value.ForEach(func(_, v gjson.Result) bool {
+			match = c.check1(v)
+			return !match
+		})
+	} else {
+		match = c.check1(value)
+	}
+	return match != c.not
+}
+
+func buildCondition(c *Condition) (*condition, error) {
+	if c.Field == "" {
+		return nil, errors.New("condition field is required")
+	}
+	check, err := buildCheck(c)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid condition for field %q", c.Field)
+	}
+	return &condition{
+		field:  c.Field,
+		not:    c.Not,
+		check1: check,
+	}, nil
+}
+
+func buildCheck(c *Condition) (func(value gjson.Result) bool, error) {
+	switch c.Op {
+	case "exists":
+		return func(v gjson.Result) bool {
+			return v.Exists() && v.Type != gjson.Null
+		}, nil
+	case "eq":
+		want := c.Value
+		return func(v gjson.Result) bool {
+			return v.Exists() && v.String() == want
+		}, nil
+	case "in":
+		want := make(map[string]bool, len(c.Values))
+		for _, value := range c.Values {
+			want[value] = true
+		}
+		return func(v gjson.Result) bool {
+			return v.Exists() && want[v.String()]
+		}, nil
+	case "prefix":
+		prefix := c.Value
+		return func(v gjson.Result) bool {
+			return v.Exists() && strings.HasPrefix(v.String(), prefix)
+		}, nil
+	case "suffix":
+		suffix := c.Value
+		return func(v gjson.Result) bool {
+			return v.Exists() && strings.HasSuffix(v.String(), suffix)
+		}, nil
+	case "contains":
+		substr := c.Value
+		return func(v gjson.Result) bool {
+			return v.Exists() && strings.Contains(v.String(), substr)
+		}, nil
+	case "regex":
+		rx, err := regexp.Compile(c.Value)
+		if err != nil {
+			return nil, err
+		}
+		return func(v gjson.Result) bool {
+			return v.Exists() && rx.MatchString(v.String())
+		}, nil
+	case "cidr":
+		cidrs := c.Values
+		if c.Value != "" {
+			cidrs = append([]string{c.Value}, cidrs...)
+		}
+		nets := make([]*net.IPNet, 0, len(cidrs))
+		for _, cidr := range cidrs {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, err
+			}
+			nets = append(nets, ipNet)
+		}
+		return func(v gjson.Result) bool {
+			ip := net.ParseIP(v.String())
+			if ip == nil {
+				return false
+			}
+			for _, ipNet := range nets {
+				if ipNet.Contains(ip) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "gt", "gte", "lt", "lte":
+		want, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return nil, err
+		}
+		cmp := compareFloat(c.Op)
+		return func(v gjson.Result) bool {
+			x, err := strconv.ParseFloat(v.String(), 64)
+			return err == nil && cmp(x, want)
+		}, nil
+	default:
+		return nil, errors.Errorf("invalid operator %q", c.Op)
+	}
+}
+
+func compareFloat(op string) func(x, y float64) bool {
+	switch op {
+	case "gt":
+		return func(x, y float64) bool { return x > y }
+	case "gte":
+		return func(x, y float64) bool { return x >= y }
+	case "lt":
+		return func(x, y float64) bool { return x < y }
+	default:
+		return func(x, y float64) bool { return x <= y }
+	}
+}
@@ -0,0 +1,162 @@
+package eventfilter_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+type testEvent struct {
+	SrcAddr string `json:"srcaddr" panther:"ip"`
+	DstPort int    `json:"dstport"`
+	Action  string `json:"action"`
+}
+
+func newResult(logType string, event *testEvent) *pantherlog.Result {
+	return &pantherlog.Result{
+		CoreFields: pantherlog.CoreFields{
+			PantherLogType: logType,
+		},
+		Event: event,
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f, err := eventfilter.Build(
+		eventfilter.Rule{
+			Action: eventfilter.ActionKeep,
+			Match: []eventfilter.Condition{
+				{Field: "action", Op: "eq", Value: "REJECT"},
+			},
+		},
+		eventfilter.Rule{
+			Action:   eventfilter.ActionDrop,
+			LogTypes: []string{"AWS.VPCFlow"},
+			Match: []eventfilter.Condition{
+				{Field: "p_any_ip_addresses", Op: "cidr", Values: []string{"10.0.0.0/8"}},
+				{Field: "dstport", Op: "in", Values: []string{"80", "443"}, Not: true},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		LogType string
+		Event   testEvent
+		Keep    bool
+	}{
+		{"AWS.VPCFlow", testEvent{SrcAddr: "10.1.1.1", DstPort: 22, Action: "ACCEPT"}, false},
+		{"AWS.VPCFlow", testEvent{SrcAddr: "10.1.1.1", DstPort: 22, Action: "REJECT"}, true},
+		{"AWS.VPCFlow", testEvent{SrcAddr: "10.1.1.1", DstPort: 443, Action: "ACCEPT"}, true},
+		{"AWS.VPCFlow", testEvent{SrcAddr: "192.168.1.1", DstPort: 22, Action: "ACCEPT"}, true},
+		{"AWS.ALB", testEvent{SrcAddr: "10.1.1.1", DstPort: 22, Action: "ACCEPT"}, true},
+	} {
+		tc := tc
+		t.Run(fmt.Sprintf("%s %v", tc.LogType, tc.Event), func(t *testing.T) {
+			require.Equal(t, tc.Keep, f.Keep(newResult(tc.LogType, &tc.Event)))
+		})
+	}
+}
+
+func TestFilterEncodesOnce(t *testing.T) {
+	f, err := eventfilter.Build(eventfilter.Rule{
+		Action: eventfilter.ActionDrop,
+		Match: []eventfilter.Condition{
+			{Field: "action", Op: "eq", Value: "REJECT"},
+		},
+	})
+	require.NoError(t, err)
+	numEncoded := 0
+	result := &pantherlog.Result{
+		CoreFields: pantherlog.CoreFields{
+			PantherLogType: "AWS.VPCFlow",
+		},
+		Event: &countedEvent{
+			Action:  "ACCEPT",
+			Counter: &encodeCounter{n: &numEncoded},
+		},
+	}
+	require.True(t, f.Keep(result))
+	// Results are stored with the encoding the filter inspected
+	data, err := pantherlog.ConfigJSON().Marshal(result)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"action":"ACCEPT"`)
+	require.Equal(t, 1, numEncoded)
+}
+
+type countedEvent struct {
+	Action  string         `json:"action"`
+	Counter *encodeCounter `json:"counter"`
+}
+
+type encodeCounter struct {
+	n *int
+}
+
+func (c *encodeCounter) MarshalJSON() ([]byte, error) {
+	*c.n++
+	return []byte(`"counted"`), nil
+}
+
+func TestFilterSample(t *testing.T) {
+	f, err := eventfilter.Build(eventfilter.Rule{
+		Action:     eventfilter.ActionSample,
+		SampleRate: 0.25,
+		SampleBy:   []string{"srcaddr"},
+	})
+	require.NoError(t, err)
+	const numEvents = 4000
+	kept := 0
+	for i := 0; i < numEvents; i++ {
+		event := testEvent{SrcAddr: fmt.Sprintf("10.0.%d.%d", i/256, i%256)}
+		keep := f.Keep(newResult("AWS.VPCFlow", &event))
+		// Sampling is deterministic
+		require.Equal(t, keep, f.Keep(newResult("AWS.VPCFlow", &event)))
+		if keep {
+			kept++
+		}
+	}
+	require.InDelta(t, numEvents/4, kept, numEvents/20)
+}
+
+func TestBuildErrors(t *testing.T) {
+	for name, r := range map[string]eventfilter.Rule{
+		"action":     {Action: "forward"},
+		"sampleRate": {Action: eventfilter.ActionSample, SampleRate: 1.5, SampleBy: []string{"foo"}},
+		"sampleBy":   {Action: eventfilter.ActionSample, SampleRate: 0.5},
+		"op":         {Action: eventfilter.ActionDrop, Match: []eventfilter.Condition{{Field: "foo", Op: "like"}}},
+		"field":      {Action: eventfilter.ActionDrop, Match: []eventfilter.Condition{{Op: "exists"}}},
+		"regex":      {Action: eventfilter.ActionDrop, Match: []eventfilter.Condition{{Field: "foo", Op: "regex", Value: "("}}},
+		"cidr":       {Action: eventfilter.ActionDrop, Match: []eventfilter.Condition{{Field: "foo", Op: "cidr", Value: "10.0.0.0"}}},
+		"number":     {Action: eventfilter.ActionDrop, Match: []eventfilter.Condition{{Field: "foo", Op: "gt", Value: "ten"}}},
+	} {
+		_, err := eventfilter.Build(r)
+		require.Error(t, err, name)
+	}
+	f, err := eventfilter.Build()
+	require.NoError(t, err)
+	require.Nil(t, f)
+	require.True(t, f.Keep(newResult("AWS.VPCFlow", &testEvent{})))
+}
@@ -95,7 +95,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _schemaJson = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x1a\x7b\x6f\x14\xb7\xf3\xff\x7c\x0a\x6b\xc9\x4f\xfa\x15\x2e\x5c\xd2\x94\x56\x44\xaa\x2a\x48\xa1\x20\xf1\x88\x48\x01\x95\xdc\x25\x72\x76\x67\xef\x4c\xbd\xf6\xd6\xf6\x26\x39\xd0\x7d\xf7\x6a\xdf\x6b\xaf\xbd\x8f\x5c\x42\x55\xca\x1f\x90\x3b\x7b\x66\x3c\x33\x9e\xa7\xe7\x3e\x6f\x21\xe4\x6d\x4b\x7f\x09\x11\xf6\x0e\x90\xb7\x54\x2a\x3e\x98\x4e\x3f\x4a\xce\x76\xf2\xd5\xfb\x5c\x2c\xa6\x81\xc0\xa1\xda\xd9\xfd\x69\x9a\xaf\xdd\xf1\x26\x29\x9e\x22\x8a\x42\x8a\x75\x84\x99\x5a\x82\x40\x94\x2f\x50\x41\x2b\x03\xd8\x26\x41\x49\x54\x1e\x4c\xa7\x22\x61\x71\x0e\x79\x9f\xf0\x82\x94\x9c\x52\xbe\x90\x31\xf8\xd3\x8b\xdd\x02\x49\x40\x98\x62\xdd\x99\x06\x10\x12\x46\x14\xe1\x4c\x16\xd0\xc7\x31\xf8\x39\x54\x63\xcf\x3b\x40\xa9\x18\x08\x79\x0d\xa0\x72\x2d\x65\x73\x15\x67\x5c\xf2\xf3\x8f\xe0\xab\x0c\x3d\x5b\x8f\x05\x8f\x41\x28\x02\xb2\x01\x8d\x90\x77\x01\x42\x12\xce\xb4\x45\x84\x3c\x9f\x33\xa9\xbc\x03\xb4\x5b\x2d\xae\x27\x35\x52\xa5\x42\x0d\xa7\x3c\x5a\x2a\x41\xd8\xc2\x9b\x34\xf7\x22\xc2\x5e\x00\x5b\xa8\xa5\x77\x80\xf6\xb5\x9d\x18\x2b\x05\x22\x65\xc0\x3b\x3d\x79\xb4\xf3\x61\x9e\xfe\x87\x77\x3e\xed\xee\x3c\x9c\xdf\xfb\xff\x6c\x76\xbf\xb5\xf8\xdd\x2f\xdb\x9e\x95\xad\x00\xa4\x2f\x48\xac\x2c\xf2\x18\xbc\x59\xd1\x05\x84\x20\x80\xf9\xf0\xf6\xcd\x8b\x31\xb2\x85\x5c\x44\x38\x55\x96\x97\x08\x62\x27\x1d\x63\x21\x41\xb8\x88\x1a\x77\x95\x2b\x0c\x5f\x1d\x35\xaf\x6c\xcf\x54\x67\xc7\xae\xe3\xae\xf3\x8b\x95\x17\xad\x45\x84\x3c\xce\xe0\x75\x6a\x88\x27\xc6\x06\x6a\x81\x66\xe0\x76\xb3\xcd\xa5\x3c\x3c\x7e\xf7\x9e\xa8\xe5\x33\xc0\x01\x08\xaf\x85\xbd\x9e\xdc\xd8\x11\x3c\x51\xce\x53\x8c\x95\xf9\x56\x07\x0f\x5e\x88\xa5\x8a\xb0\xf2\x97\x36\xd5\x74\x31\xf2\x14\x4b\xf5\x32\x43\xec\xa4\x2f\x60\x01\x57\x63\x69\xbf\x49\x91\x06\x10\x67\x58\x91\x0b\x18\x4b\xfd\x55\x8e\xb5\xe5\x52\xda\xda\x6a\xc7\x21\x01\x1a\x98\x56\xe5\x38\x27\xb7\xea\xa7\x39\x86\xc3\x5f\xdb\x61\xad\xd8\xea\x72\x8d\x22\x62\x1c\x75\x58\x79\x3b\x92\x6c\x0f\x57\xd0\x05\xa6\x09\x64\x71\xd5\xad\x1d\x8d\x21\x1c\x04\x19\x2a\xa6\x1a\x4f\x21\xa6\x12\xb6\x4c\xf4\x0a\xd5\x13\xf0\x57\x42\x04\xa4\x59\xe3\xa4\x8a\xc3\x93\x4a\xc9\xb9\xc9\x16\xe0\x9e\xa6\x4d\x4b\xbc\xc7\x42\xe0\x55\x1d\xee\x23\xc2\x9e\x2b\x88\xb4\xd8\xe0\x91\x62\xa5\x11\xfe\xed\x1a\xc8\x38\x68\x6a\x60\xad\xf1\x52\x6f\x37\x18\xc1\x94\x1a\x11\x64\xf8\x85\x76\xdc\x24\xc3\x91\xd5\xb6\x5d\x01\xbd\x75\x3b\xba\xa2\x9d\x74\xce\x39\xa7\x80\x59\x37\xa1\x02\x78\xa0\x1d\xa5\xd0\xc7\x7e\xcb\x8c\x0c\x9a\xee\xa4\xd5\x2f\xa7\xd3\x22\x35\xd3\xca\x54\x38\x29\x48\xcd\x6d\x9e\x38\xc0\x9b\x2d\x4e\x51\x1e\xaf\x1b\x6a\x0d\xd8\x30\x8e\x76\x7a\x19\x70\x64\x2e\xb2\x71\xe6\x28\xa6\x73\x63\xdb\x84\x42\xe6\x56\x9b\x10\x90\x3e\xa6\x58\x6c\x42\x41\x91\xc8\x54\xfc\x28\x7c\x01\xe1\x90\x7b\xab\xac\xd5\x12\x5c\x8c\xaa\xc7\x03\x96\x44\xda\x6d\xb6\xeb\xa2\xb6\xa3\x1b\x21\x0a\x21\x2f\x2d\xbc\x9b\xdf\x09\xd3\xe0\x43\xca\xb1\xb6\x20\x23\x4c\xa9\x01\x74\x4e\x16\xe6\x4a\xe1\xc9\x8d\xa5\x54\x85\x52\xe1\x28\xf6\xf4\x72\xcf\xb3\x6a\xa2\x61\x35\x1b\x14\xd6\x96\x58\x51\x55\xd5\x25\x91\xdb\xca\xb1\xdd\xa9\x26\xe3\xcc\x95\x67\x6a\x83\xbf\x2d\xd9\x73\x33\xb0\x8a\x0e\x14\x22\x60\x6a\x98\xec\x1d\x11\xa9\x47\xf0\xf2\x18\x5d\xf2\x86\xa7\xde\xb0\xe8\x5d\xcd\x43\xe9\x4a\xb9\xf1\x57\x46\x5f\x1b\x76\xd3\xec\x4b\x97\xa9\x8d\x7c\x3e\x4a\xf6\x56\xa4\x26\x01\x56\x63\x83\x75\x57\x26\xd7\x8e\xc4\x94\xf2\x4b\x6f\x3e\x38\xd5\xe7\xf0\x1d\x39\xd0\x0c\x20\xc5\xb6\xad\xd6\xa9\x36\xdb\x35\xcf\x90\x6b\xa9\x29\x57\x5d\xeb\x9e\xb1\xbd\x76\xa7\xe2\xde\x40\x3d\x58\x85\x01\xb0\xd5\x08\x0d\x66\xe0\x5f\x95\x02\xad\x01\xba\x51\x18\x0c\xf0\x54\x8b\x1b\x6c\x16\xc0\xba\xde\x10\x08\x0b\x88\x8f\x15\x17\xce\x8e\xa6\x7d\x07\xba\x96\xb4\x1d\xbb\xf2\x1d\x01\xb1\x3a\xdb\xeb\x33\xc6\xca\xf5\x07\x47\xda\x3a\x50\xb4\x03\x8e\x76\x37\x35\x13\xd7\xaa\x23\x88\x96\xa2\x03\x1e\x61\xa2\x65\xf2\x25\x97\x2a\xaf\x67\xeb\xb5\x44\xd0\xe6\xd7\x28\x78\xa0\x15\x0d\x4b\xbc\x67\x7c\xff\xfe\xc1\x8f\x5a\x5d\x72\x29\xcf\xb0\x60\xad\x25\xdf\xe7\x09\x53\x67\x24\x30\x77\x08\x93\x0a\x33\x1f\x2c\x5b\x0a\x6b\x45\x90\x12\xb8\x05\x96\x48\x10\xa6\x08\x10\x61\xa2\x09\xc1\x40\x9d\xe1\x20\x10\xf6\x0a\xa5\x2a\x09\x6f\x2b\x47\xd7\x05\x93\xdd\xca\xe5\x93\x0b\x60\xea\x77\xd2\xea\xcd\xdc\xfd\xd4\xda\xa8\xc7\x9e\x96\xaf\x66\x1a\xba\xfd\x1d\xaa\xaf\x2b\x6a\x05\xad\xea\xb1\xf6\x71\x42\xa8\xda\x21\x0c\x55\x12\xa1\xe2\xb9\xae\x85\xa3\x37\x62\xde\x21\x8f\x22\xde\xc6\x93\x6d\xc4\x2a\x85\x8b\xd0\xdf\xdf\xdf\x7f\x98\xe6\xe7\x84\x91\xab\xf2\xef\x59\x24\xab\x8f\x49\xfd\x91\x65\x1f\x7d\xca\x93\x20\xa4\x58\x34\x5b\x33\xd4\xee\x13\xaf\xaf\x82\xc3\x44\x2a\x1e\x8d\x57\xc0\x23\xe4\xd7\x98\x05\x12\x22\x0c\x49\x25\xc2\x6c\x89\x71\x85\x33\xe0\x16\xa5\xc6\x9b\xee\xff\x4e\xf0\xa3\xf3\xc7\xfe\x61\x10\x3e\x7b\xfe\x31\x7a\x19\x1f\xbf\xbd\x7c\x7f\xb5\xfa\xe3\xd3\x87\x79\x67\x5b\x7c\x7d\x71\x7f\xe3\x88\xe2\x15\x4f\xd4\xcd\x49\xbc\xa8\x48\x0e\x12\xf9\x34\x07\xfe\xd9\xdd\xac\x8f\xab\xda\x26\x9a\xc3\xe8\x91\xa0\x6c\xee\x6e\x2b\x10\x34\x9a\x24\xc3\x85\xb1\x58\x40\xcb\x7d\x87\x8e\x00\xf6\x06\x2b\x20\x3f\x46\x7f\xbd\x28\x85\xb7\xbd\x37\x8f\x2d\x08\x96\x58\x16\x98\xfd\x55\x41\x0d\xeb\x50\x97\x12\x09\x58\xb5\x15\x00\x25\x11\x51\xee\x11\x40\x67\x6b\x30\x49\xe5\x9f\x65\x5a\x40\x46\x35\x18\x40\x88\x13\x9a\x5d\xd5\xc4\x7e\x51\x3e\xa7\x49\xe4\x7e\x60\xb5\x97\x23\xd6\x72\xd0\x55\x8d\x74\xba\xa6\xb3\x04\xb4\x17\x26\xf2\x4f\x12\x1f\x09\x08\x89\xf9\x5c\x7e\x1d\xd3\xd2\x12\x6c\xac\x56\xef\xd2\x8e\xf1\x0b\x6a\xa2\x57\x5a\x25\x48\x74\x1c\x63\xff\x7a\x59\x14\xae\x62\xcc\x82\xd6\x93\x30\x72\x17\x72\x0a\xae\xd4\x51\xe6\x34\x4f\x9a\xb8\x3d\x25\x9d\x63\xe6\x32\xd6\xd3\x4a\x43\xec\xf7\xb3\x7f\xd0\x5b\x7a\x5d\xdc\x78\xd4\xff\xe6\x68\xdf\x1c\xed\x86\x1d\xad\x9e\x29\x8e\xf5\xb0\x7c\x84\xd9\xef\x5f\xb6\x51\xe7\x4d\xde\x8e\x63\xa8\x54\x0e\x59\x8f\x8a\x52\xe9\xbf\x65\xa3\x1b\x79\xeb\xbf\xc9\x7e\x5f\x99\xf3\x68\xcb\x34\xaa\xdf\x46\x2d\x73\x3f\xf7\x8f\x38\x3a\xb8\x69\x4c\xd1\x6f\xcb\x9d\x8a\xd2\xff\xd7\x54\x7f\xb7\x3b\xcc\xde\xdd\x79\x78\x36\xbf\x6b\x1d\x65\xf7\xf5\x47\xee\x97\xb8\x4d\xe7\xda\x93\x2f\x17\x58\xc6\x86\xfd\xaf\x2a\x80\x7c\x25\x41\xc2\x81\x35\xc0\x39\x1d\xe6\xd8\x6e\x65\x0d\x8d\x19\x3f\x5e\x30\xd3\xd0\xf8\xdf\x30\xf4\x19\xcd\x0f\x36\x05\x0f\xa6\xd4\xf8\xa1\x61\xc6\x20\xba\x24\x6a\x89\x62\x8a\x7d\x58\x72\x9a\x96\xa6\x1a\xf8\xb6\xcf\xa3\x62\x68\xe6\xbd\x4c\xa4\x42\x3e\x67\x0a\x13\x86\xb0\x42\x14\xb0\x54\x88\x33\x70\xa3\x37\x9f\x6a\x3e\xcf\x66\xf2\xee\xc9\xe9\x7a\x7e\x2f\xfd\x30\x9b\xad\xfb\x47\xce\xa3\x05\xc9\x5e\x51\xe0\x92\x12\x06\xd2\x2d\xc8\x6b\x46\x57\x28\x9b\x07\x95\xc0\xa9\x38\x6a\x09\x08\x58\xe0\x14\xe0\xf4\xe4\x74\x36\x63\x29\xf7\x4c\xfb\x89\x60\xf1\xa9\x78\x3c\xd8\x4a\xff\xad\xb7\xfe\x0e\x00\x00\xff\xff\x20\x81\x22\xc9\x0d\x2a\x00\x00")
+var _schemaJson = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xec\x1c\x69\x6f\xdc\x36\xf6\xbb\x7e\x05\xa1\xba\xe8\x35\x8e\x93\x66\xbb\x8b\x18\x58\x2c\x92\xb4\x49\x83\x26\xad\xd1\x6c\x53\x6c\xed\xb1\x41\x4b\x6f\x66\x98\x48\xa4\x4a\x52\xb6\x27\xe9\xfc\xf7\x05\x75\x92\x14\xa9\x63\x66\xdc\x4d\xb3\x41\x8d\x54\xa2\xf8\xee\x83\x7c\x3c\xe6\x5d\x80\x50\x78\x20\xa2\x15\xa4\x38\x3c\x46\xe1\x4a\xca\xec\xf8\xe8\xe8\xb5\x60\xf4\xb0\x6c\xbd\xc3\xf8\xf2\x28\xe6\x78\x21\x0f\xef\xfe\xe3\xa8\x6c\xfb\x24\x9c\x29\x38\x49\x64\x02\x0a\xea\x04\x53\xb9\x02\x8e\x12\xb6\x44\x15\xae\xa2\xc3\x01\x89\x6b\xa4\xe2\xf8\xe8\x88\xe7\x34\x2b\x7b\xde\x21\xac\x42\x25\x8e\x12\xb6\x14\x19\x44\x47\x57\x77\x4b\xac\x07\x1c\x16\x0a\xea\x93\xa3\x18\x16\x84\x12\x49\x18\x15\x55\xef\x97\x19\x44\x65\x2f\xed\x5b\x78\x8c\x94\x18\x08\x85\x5a\xa7\xba\x4d\xb1\xb9\xce\x0a\x2e\xd9\xe5\x6b\x88\x64\x01\x5e\xb4\x67\x9c\x65\xc0\x25\x81\x16\x83\xfa\x0b\xaf\x80\x0b\xc2\xa8\xd1\x88\x50\x18\x31\x2a\x64\x78\x8c\xee\x36\x8d\x9b\x1a\x55\x43\xda\x86\xa9\x49\x0b\xc9\x09\x5d\x36\xa4\xd5\x5f\x98\x12\xfa\x1c\xe8\x52\xae\xc2\x63\x74\xdf\xf8\x92\x61\x29\x81\x2b\x06\xc2\xf3\xd3\x87\x87\xbf\xcd\xd5\x3f\xf8\xf0\xed\xdd\xc3\x07\xf3\xaf\x3e\x3f\x3b\xbb\xd3\x69\xfc\xe2\x5f\x07\xa1\x93\xad\x18\x44\xc4\x49\x26\x1d\xf2\x58\xbc\x39\xc1\x39\x2c\x80\x03\x8d\xe0\x97\x9f\x9f\x4f\x91\x6d\xc1\x78\x8a\x95\xb2\xc2\x9c\x13\x37\x67\x19\xe6\x02\xb8\x0f\xa9\x65\xab\x5a\x61\x27\xba\xc9\xee\x19\x5f\x0f\x22\x96\xa6\x40\x0b\xa2\x3f\xd1\x64\x8d\x18\x05\x54\x12\x41\x11\xa6\xe8\x12\x90\x00\x39\x43\x69\x9e\x48\x92\x10\x0a\x75\x6b\xc4\xd2\x4b\x42\x21\x46\xd7\x44\xae\x10\xa6\x6b\xc4\x16\x48\xae\x20\x35\xa9\x93\x85\xc5\x6b\xa1\x9e\xdf\x73\xc2\x41\x39\xf9\x69\xd8\x20\x0e\xe7\x5a\x2f\x4d\x64\xe5\x8a\x2b\xb0\xed\xa0\x04\xc3\x37\x86\x60\x5f\x7b\xe1\x21\x11\x30\x0c\x7f\xcf\x0b\xef\x71\x79\xf5\x17\x46\xe2\xaa\xd3\x88\x50\xc8\x28\xfc\xa4\x44\x3f\xb5\x3e\xa0\x4e\x57\x84\xbc\xd1\x5b\xda\xe1\xf1\xcb\x57\xbf\x12\xb9\xfa\x1e\x70\x0c\x3c\x0c\x2c\x50\x93\xd5\xdd\x48\xb0\x5c\x7a\xa9\x58\x2d\xba\xb1\x2c\x75\x21\x14\x2e\xb0\x90\x29\x96\xd1\xca\xa5\x9a\x3e\x46\x9e\x60\x21\x5f\x14\x80\xbd\xf8\x39\x2c\xe1\x66\x2a\xee\x9f\x15\xd0\x08\xe4\x4b\xce\xde\x4c\xc5\xfd\x54\xc1\xf4\x62\x7d\x73\x35\x15\xe7\x0f\xaf\xfa\x31\x52\x2c\xc9\x15\x4c\xc5\xfa\x63\x09\xd5\x8b\xb9\x8d\xc9\x89\xc8\x5f\x28\xc0\xe7\x0a\xd0\x80\xda\x04\xae\x67\x8d\x6a\xb8\x20\x90\xc4\x76\x74\x79\x48\x95\x49\xee\x49\x09\xe1\xc4\x26\x39\xa6\x42\x25\x53\x5f\x9e\xc4\x9c\xe3\xb5\x95\xa8\x24\xa4\x8e\xf0\x76\xb3\xd0\x10\x28\x06\xcd\x41\xe9\x08\x8d\x49\x84\x25\xe3\xe2\xb6\x18\x6a\x28\x8c\x63\x48\x03\x9d\x32\x94\x54\x23\xac\x91\x35\x2d\xf6\xba\x23\xef\x41\xa7\x93\x57\x8a\x2b\x9c\xe4\x60\x4b\xa0\xcb\x60\x48\x81\x50\x88\xe3\xb8\x00\xc5\x89\xc1\xd3\x02\x27\x02\x02\x1b\xbc\x01\x35\x07\xa0\x7a\xde\x32\x6b\xbc\x70\x1e\x68\xdd\x43\xc3\xdd\x5a\x51\x3c\xa6\x53\xc3\xed\xb3\xca\x72\xcd\x48\xeb\xb0\xa5\x47\x03\x05\x07\xba\x06\x36\x06\x2f\xed\x67\x8d\x11\x9c\x24\xd6\x50\x33\xde\xa0\x3d\x96\xa4\x38\x75\xc6\xbf\x35\x81\x31\x3e\x6f\x66\xc6\xab\xae\x68\x2f\x9e\x4b\xc6\x12\xc0\xb4\x1f\x51\xd5\x79\xa4\x1f\xa9\xde\x2f\x23\x88\xfa\x71\xfa\x27\x79\xc3\x72\x06\x1e\xb4\xa6\x6b\x15\x2a\x9c\x55\xa8\xe6\x81\x03\xe2\x5d\x30\x28\x8c\x23\x28\x6a\xf2\xa6\xa3\xb6\x1d\x5b\x69\x1c\xf3\x90\x11\x24\x4b\xd3\x5a\x34\x27\x31\x5d\x06\xcd\x2e\x18\x8a\x14\xbd\x0b\x02\x11\xe1\x04\xf3\x5d\x30\x48\x92\xc2\x2e\xf0\x1c\x16\x16\xb8\xd3\x6e\x8d\xb7\x6a\x66\xb3\x9c\xaf\x26\x1b\x02\xcd\x53\xc3\x9a\x76\x0f\xe4\x08\xf4\xce\xe8\x12\xaa\x42\x55\x7f\x27\xd4\xe8\xbf\x48\x18\x36\x1a\x44\x8a\x93\xc4\xea\x74\x49\x96\x76\x4b\x15\xc9\x5a\x93\x52\xa1\x90\x38\xcd\xf4\x7e\x4a\x59\x4e\x4d\x68\x5e\xe3\xd0\x85\x25\x97\x2f\x79\xd5\xfd\x9d\x55\x68\xad\x9c\xe6\xdb\x9e\x27\x21\x81\x85\xd5\xcc\x07\x05\x67\xbe\x71\xa6\x75\xf8\xdb\x92\xbd\xa0\xe0\x16\x1d\x12\xa8\xaa\xc0\x11\xb2\xf7\x64\xa4\x01\xc1\x6b\x32\xa6\xe4\x5a\xa4\xee\x59\x74\x4f\x18\x19\xa1\x14\x16\x5e\xdc\x38\x7d\xeb\xd8\xba\xdb\xd7\x21\xd3\x3a\xb9\x96\xce\x47\xc8\x6e\x0a\x7c\x85\x13\x12\x63\x39\x35\x59\x7b\x34\xd2\x25\x89\x93\x84\x5d\x87\x73\xa3\x83\x47\x6d\xf5\x0c\x82\x5d\x77\x9a\xbd\x73\x1c\x84\x7a\xe6\x3a\x08\x79\xe7\x3c\x1d\xb4\x0e\xb3\x38\x56\x79\xf4\xba\x5c\xd7\x77\xf7\xad\x7d\xde\xcc\x76\x54\x61\x0c\x74\x3d\x41\x83\x45\xf7\xae\xac\x7f\x61\x05\x56\x4f\x56\xa4\xb6\x13\x83\x96\x2f\x9f\x52\x4d\x85\x16\x9d\xe6\x5b\x45\x71\x93\xc0\x2a\x71\x5d\x56\xde\xae\xc6\x32\xb4\x34\x0b\x06\x95\x3f\x54\x7d\x85\x6e\x5d\xb6\x98\x9b\xd0\x1f\x9d\x69\xdb\x44\x11\xd8\x98\xcd\xda\xa0\x65\xa2\x45\xed\x73\x94\x10\xd3\xf5\xf4\x69\xe1\x65\x4e\x12\x49\xe8\xb3\xae\xb4\x9b\x99\x07\x8f\xb6\xba\xf8\x38\x17\x92\xa5\xa8\x61\x53\xa0\x18\xa2\x04\x73\x88\x11\xa1\x6a\xe5\x50\xff\x54\xae\x25\xea\xab\xe2\x76\x11\xaa\xd8\x3b\x3f\xc5\x87\x6f\xe7\xea\x9f\xbb\x87\x0f\x2e\xe6\x5f\xea\xab\xb9\x4e\xff\xed\x48\xb0\xd5\x94\x8b\x18\xb3\x99\x98\xa5\x98\x18\x93\x9e\x15\x13\xb2\x9c\xfa\xb7\x6d\x39\x4f\xf4\xd7\x34\xfe\x46\x7f\x15\x2b\x7c\xcf\x7a\xff\xfa\x9b\xbf\xeb\x2d\xf8\x5a\x5c\x60\x6e\x90\x29\x9a\xa2\x88\xe5\x54\x5e\x90\xd8\xfe\x42\xa8\x90\x98\x46\xe0\xf8\x24\x71\x2b\x9e\x12\x9c\xe3\x4e\xb7\x5c\x00\xb7\x45\x80\x14\x13\x43\x08\x0a\xf2\x02\xc7\x31\x0f\x9d\xba\x6e\xac\x39\x36\x5d\xe8\x4b\xd1\xaf\xd4\x14\x43\x20\xcc\xd5\x42\x73\x92\x40\x24\x21\x46\x58\x1c\x12\x81\x72\x9a\x80\x10\x08\x88\xda\x11\x41\xc5\x2a\x23\x62\x1c\x89\x08\x53\x0a\x1c\x11\xa1\x16\xac\xc3\xc1\x44\xe3\x28\x6f\x7d\x2e\x30\xc5\xed\x5a\x98\x72\x96\xb7\x2d\x89\xec\x02\xd3\xf5\xc5\x38\x42\x3b\xee\x56\xb8\x16\x6a\x7b\xf9\x74\x8f\x2d\x3a\xca\xca\x18\xe1\xf1\x8e\x69\x25\xb0\x50\x1b\x43\x8a\x65\x4b\x8d\x7c\xa9\xf9\xea\xbd\x1d\x73\x28\x33\x27\xb8\x6e\x6c\x8d\x46\x5a\x84\x9a\x44\x4d\xdb\xbc\xc3\xdb\xe0\x62\x54\xd5\x35\x6c\xea\xca\x96\x19\x6f\x50\x6c\x35\x4e\xb6\x55\x57\xf3\xb9\x61\x53\x45\xa6\xf8\xee\x0a\xa8\xfc\x37\xf1\x47\x40\x3d\xcb\x75\xc2\x2b\xf4\x4f\xea\xad\x2a\x03\xbc\x3b\x81\xb5\x87\x84\x21\xe7\x42\x48\xdf\x21\x7d\xa4\xf2\xf5\xa1\x1a\x1e\x6a\x89\x50\xb5\x47\xd6\x81\x31\x83\x20\x7c\xcc\xd2\x94\x75\xe1\x44\x97\x58\x53\x07\xf0\x45\x74\xff\xfe\xfd\x07\x6a\xb2\x9f\x53\x72\x53\xff\xff\x22\x15\xcd\x63\xde\x3e\xd2\xe2\x31\x4a\x58\x1e\x2f\xd4\x28\xa6\x15\x04\x96\xbe\x76\x53\x41\x35\x6c\x4e\x56\xc0\x43\x14\xb5\x90\x15\x90\x1a\x68\x85\xe4\x8b\xa2\x89\x32\x89\x95\xbb\x76\x31\x69\x79\xe8\xd3\x53\xfc\xf0\xf2\x51\xf4\x38\x5e\x7c\xff\xec\x75\xfa\x22\x7b\xf9\xcb\xf5\xaf\x37\xeb\xff\xbc\xfd\x6d\x1e\xde\x8e\xb8\x4f\x19\x4a\xf0\x9a\xe5\x72\x7f\x12\x2f\x1b\x94\xa3\x44\x3e\x2f\x3b\xff\xd3\x12\x50\x7b\x9b\x77\x66\x19\xee\x04\xd5\x94\xbd\x5a\xc0\x98\x63\x64\xbd\x42\xd4\x86\xd1\x7e\x13\x81\xb6\xd2\xa2\x31\xa9\xa0\x30\x5f\x42\x27\x7c\x7b\xac\xe4\x29\x28\x86\x14\x50\x92\x31\x97\x40\xab\xbe\xa1\xb1\xf5\x58\xed\x3b\x8e\x50\x84\x41\x60\x85\x45\x05\x39\x5c\x5a\xb4\x7d\x3d\xea\x92\x3c\xd7\x76\x0d\x6a\x7c\x85\xa3\x25\x24\x25\x12\xf8\x14\x85\x35\x79\x65\xa6\x12\xc5\x59\xa1\x05\x64\x95\x94\x31\x2c\x70\x9e\x28\x3b\x84\x33\xb7\xa1\x22\x96\xe4\x29\x9d\x58\xd3\x38\x6b\x4a\x5f\x49\xd3\x23\x83\xd7\xec\xad\xe1\x4d\x6e\xc5\x1b\x92\x9d\x70\x58\x90\x1b\x1f\xc3\x13\x5c\x4b\xc3\x0b\x69\x26\xd7\xe5\x9c\xf0\xcf\xd3\xc4\xa0\xb4\x92\x93\xf4\x65\x86\xa3\xed\x46\x51\xb8\xc9\x30\x8d\x3b\xfb\x4a\x3d\x13\x25\x09\x37\xf2\xa4\x08\x9a\xef\x74\xd8\xc0\xe6\x72\xe3\x0f\xb3\x76\x87\xbf\xa5\x38\x2e\xd2\x6a\x47\x1c\x8e\xb3\xff\x61\xb4\x0c\x86\xb8\xb5\x33\xf8\x31\xd0\x3e\x06\xda\x9e\x03\xad\x3d\xc1\xd2\x92\x1a\x17\x61\x65\x1d\x36\x1c\x5f\xbd\xf5\xda\x1e\xac\xe3\xd6\x49\x73\xa4\xe7\xa4\x9a\x2a\x0d\x5a\xed\x83\xf2\x51\x1d\xc4\xcb\xe5\x87\xe0\xbf\xd5\xf9\xa0\x96\x8e\xe9\xa4\x45\xa9\x3d\xdf\xc7\xea\xca\x28\x6e\xb4\x33\x5b\x2d\xb6\xfd\x86\x53\x35\xf5\xff\x56\xc5\xff\xed\x9e\x88\xa9\x17\x71\xec\x2e\x5d\xdd\xe8\x34\x7a\xfd\xad\xd5\x9e\xa6\xbb\xb1\xeb\x11\x16\xc8\xad\x27\x16\x4b\xc8\xc0\x25\xc4\x07\x9b\x40\x06\xa5\xfd\x2b\x25\x89\xa7\xe6\x11\x49\x5f\x88\x18\x01\x69\xfb\x59\xf5\x3e\x2d\x40\xc9\xff\x77\x84\x56\xc2\x3c\x27\x97\x1c\xf3\xb5\x4f\x0d\xdb\x45\xc5\x9f\x1c\xfd\xb3\x60\x94\xfa\x36\x4e\x5e\x55\x66\x50\xc7\x4c\x85\x8f\x5f\x42\x25\x2c\x81\x77\x38\x26\x69\x9e\xfa\x2f\x23\x7c\xcc\x37\xef\x43\xbe\x99\x05\x63\xc3\xc3\x4c\x4a\x3f\xbc\xd2\x18\xf1\xe5\x04\x6f\x96\xa9\x16\x19\xf5\x36\xad\x2c\xd5\xda\x10\x0a\x13\xb6\x5c\xa4\x66\x9e\x51\x15\x24\x2c\xec\xa6\x04\xf4\x05\x38\x63\xe5\xb0\xed\x19\x66\x98\xf0\x6f\xb7\x29\x9a\x87\x5d\xaf\x38\xaa\x73\x4b\xb8\x7f\xcf\x99\x84\xc7\x2b\xcc\xc5\x7e\xf1\x82\x88\x70\x56\x20\xde\x1a\xaf\xf9\x05\xdf\x0c\x50\xfc\x98\x4e\x3e\xa6\x93\x4e\x3a\x69\xef\x32\xb4\xfc\x78\xb3\xca\xc0\x79\x0a\xa3\x30\x11\x12\x73\x59\x57\xd1\xce\x8c\xe0\x87\x8d\x18\x95\x84\xe6\xe0\x00\x1f\x3d\x9f\x32\xe8\x7b\x8c\xb5\x9d\x67\xda\xdc\xed\x15\x79\x8a\x6f\x76\x89\x51\x2f\xd2\x47\x6b\xb9\x67\xa4\x8b\x24\x17\x2b\xb5\xbb\xca\xf2\x49\x5b\x2c\xd5\xec\x4e\xd1\x3d\x3f\x2d\x2e\x28\x7e\x9e\x8a\x3f\xc4\x1f\xe9\x17\xfa\xc6\x7f\x60\x11\x1c\xef\xd7\xe6\xad\x95\x96\x31\xaf\x57\x3b\x2f\x07\x36\x58\x8a\xdd\xc3\xfa\x3a\xa0\x00\x89\x32\x75\xfc\x42\x6a\x37\x00\xfd\x77\x0f\xbb\x97\xef\x06\xdd\x96\x83\x6b\x51\xc1\x93\x1c\x6a\x1e\x5f\x30\xfd\xae\x53\xa3\x33\x35\x5a\xb3\x6c\xbd\x47\x64\x34\xc2\x5e\x5b\x5b\x6a\xed\x93\xb2\x3d\xbe\xd0\x6d\xef\xcd\xef\xbd\x39\xbe\x27\xcf\x8f\x91\xfb\x04\x4b\xeb\xca\x5c\xeb\x86\x1d\x5d\xa8\xbf\x50\x40\x86\xb9\x75\xc0\xaa\x23\x44\x15\x02\xbd\x88\x24\x73\x61\x98\xcc\xef\x26\xf0\x90\x70\x57\xa8\xba\x15\xba\x0c\x69\x0d\x4d\xb2\x1d\x17\x88\x16\xf9\x50\x64\x09\xd9\xaf\xd7\xec\x47\x5b\x3b\x58\xd3\x04\xf5\x26\xf6\xdb\xb4\xb5\x8d\xd6\x33\x31\xe9\x9f\x9c\xec\xe4\x35\xa1\x57\x81\xfb\xf3\x9d\x14\x0b\xfb\x96\xea\x7b\xe8\x3a\x29\xc8\x15\x73\xa2\x76\x56\x56\xf5\x26\x9e\x79\xf8\xaa\x6a\xe7\x10\xe3\x48\xba\xbe\x94\x27\x13\xad\xf6\x79\x2f\x63\x1c\xb2\x04\x47\xae\x4b\x0f\xbb\x7a\x75\xe0\x21\x3a\xd5\x69\x2a\xd5\xed\xcf\x63\x22\x06\x3c\x82\xf7\xdf\x67\x2a\x8e\x26\x78\x8c\xc7\x4a\x9d\x8b\x4d\xf5\x7f\xf5\x5d\x8f\xee\x07\x67\x1e\xe8\xf8\x52\xe0\x61\x7e\xaa\x89\x0b\x49\x77\x34\x70\x60\xf1\x31\x0c\xd6\x99\x0e\x16\xf3\x9a\x56\xe1\x3e\x97\xf0\xb9\x43\xb8\xe0\xac\x73\xd3\x7a\x92\x17\x34\xcc\xbb\x46\x81\x69\x0e\xb5\x09\x2c\x94\x6e\x9b\x94\x3c\x9b\x64\x03\xcb\x08\x5b\x68\xb2\xe0\xc5\xa1\x49\xcb\x41\x8d\xa3\xce\x3f\x82\x50\x87\x9b\xcb\xf8\x43\x6f\x60\x5d\x1e\x7b\xae\xc6\x0c\x88\xd1\xe5\x1a\x7d\x76\xe7\xb3\x16\xd8\x28\x13\xce\xef\x54\xbf\x6d\xa2\x1e\xbe\xa8\x4f\x08\x37\x8c\xb9\x8b\xe4\x11\xb6\xf6\xc8\x5e\xc3\x75\x65\x73\xee\x16\x74\x36\x8d\xa7\x5f\x5b\xb6\x54\xd7\xc9\xbe\x7f\x6b\x3e\x6c\x66\xd3\x31\x35\xe7\x0e\x2b\x06\xcb\x5f\x33\x29\x86\x86\x15\x4b\x62\xbb\xf2\xd3\xed\xf6\x22\x17\x12\xa9\x8a\x17\x13\x8a\xb0\x44\x09\x60\x21\x8b\x0a\xc9\x0b\xae\x59\xee\xd3\x77\x67\x67\xe2\xcb\xd3\xf3\xcd\xfc\x2b\xf5\x70\x76\xb6\x09\xf7\x2f\x88\x3a\x46\x49\xe1\x5a\xfd\x6c\x8b\x39\x91\x35\x1c\xb0\xa8\xec\x8a\x2b\x63\x75\x67\x25\x8e\xba\x90\x01\x34\x76\x6e\xa6\x54\xae\x77\x76\x46\x15\xf7\xd4\xf8\x15\x9d\xea\xa9\x3a\xea\x17\x20\xb4\x09\x36\xc1\x7f\x07\x00\xea\xe4\x63\xdd\x30\x49\x00\x00")
 
 func schemaJsonBytes() ([]byte, error) {
 	return bindataRead(
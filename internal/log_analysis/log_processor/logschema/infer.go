@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/itchyny/timefmt-go"
 	"github.com/pkg/errors"
 
 	"github.com/panther-labs/panther/pkg/x/structfields"
@@ -116,12 +117,40 @@ func inferString(s string) *ValueSchema {
 			TimeFormat: "rfc3339",
 		}
 	}
+	if format := inferTimeFormat(s); format != "" {
+		return &ValueSchema{
+			Type:       TypeTimestamp,
+			TimeFormat: format,
+		}
+	}
 	return &ValueSchema{
 		Type:       TypeString,
 		Indicators: inferIndicators(s),
 	}
 }
 
+// inferTimeFormats are strftime formats commonly found in logs.
+// Formats with fractional seconds come first so that they take precedence.
+var inferTimeFormats = []string{
+	"%Y-%m-%d %H:%M:%S.%f",
+	"%Y-%m-%d %H:%M:%S",
+	"%Y-%m-%dT%H:%M:%S.%f",
+	"%Y-%m-%dT%H:%M:%S",
+	"%Y/%m/%d %H:%M:%S",
+	"%d/%b/%Y:%H:%M:%S %z",
+	"%a, %d %b %Y %H:%M:%S %z",
+	"%a, %d %b %Y %H:%M:%S %Z",
+}
+
+func inferTimeFormat(s string) string {
+	for _, format := range inferTimeFormats {
+		if _, err := timefmt.Parse(s, format); err == nil {
+			return format
+		}
+	}
+	return ""
+}
+
 func inferIndicators(s string) []string {
 	if ip := net.ParseIP(s); ip != nil {
 		return []string{"ip"}
@@ -26,6 +26,8 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/preprocessors"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
 	"github.com/panther-labs/panther/pkg/stringset"
 
 	// Force dependency on go-bindata to avoid fetching during mage gen
@@ -66,6 +68,8 @@ type Schema struct {
 	Version      int                     `json:"version" yaml:"version"`
 	Definitions  map[string]*ValueSchema `json:"definitions,omitempty" yaml:"definitions,omitempty"`
 	Fields       []FieldSchema           `json:"fields" yaml:"fields"`
+	Transform    []transforms.Config     `json:"transform,omitempty" yaml:"transform,omitempty"`
+	Indicators   []IndicatorSchema       `json:"indicators,omitempty" yaml:"indicators,omitempty"`
 }
 
 func (s *Schema) Clone() *Schema {
@@ -85,11 +89,26 @@ func (s *Schema) Clone() *Schema {
 	return &out
 }
 
+// IndicatorSchema declares a custom indicator that string fields can use in their `indicators`.
+// Values are collected in the indicator field `Field` either as-is, by matching `Match` or by using a built-in `Scanner`.
+// If `Match` has a capture group, the value of the first group is collected instead of the whole match.
+type IndicatorSchema struct {
+	Name        string `json:"name" yaml:"name"`
+	Field       string `json:"field" yaml:"field"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Match       string `json:"match,omitempty" yaml:"match,omitempty"`
+	Scanner     string `json:"scanner,omitempty" yaml:"scanner,omitempty"`
+}
+
 type Parser struct {
 	CSV       *preprocessors.CSVMatchConfig  `json:"csv,omitempty" yaml:"csv,omitempty"`
 	FastMatch *preprocessors.FastMatchConfig `json:"fastmatch,omitempty" yaml:"fastmatch,omitempty"`
 	Regex     *preprocessors.RegexConfig     `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Grok      *preprocessors.GrokConfig      `json:"grok,omitempty" yaml:"grok,omitempty"`
+	KV        *preprocessors.KVConfig        `json:"kv,omitempty" yaml:"kv,omitempty"`
 	Native    *NativeParser                  `json:"native,omitempty" taml:"native,omitempty"`
+	// MultiLine can be combined with any of the above to assemble log entries spanning multiple lines
+	MultiLine *logstream.MultiLineConfig `json:"multiline,omitempty" yaml:"multiline,omitempty"`
 }
 
 type NativeParser struct {
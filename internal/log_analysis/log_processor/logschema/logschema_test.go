@@ -42,6 +42,10 @@ func TestValueSpecValidate(t *testing.T) {
 		"./testdata/apache_common_log_fastmatch_schema.yml",
 		"./testdata/apache_common_log_regex_schema.yml",
 		"./testdata/vpcflow_schema.yml",
+		"./testdata/transform_schema.yml",
+		"./testdata/apache_common_log_grok_schema.yml",
+		"./testdata/postfix_grok_schema.yml",
+		"./testdata/cef_schema.yml",
 	} {
 		schemaFile := schemaFile
 		t.Run(schemaFile, func(t *testing.T) {
@@ -42,8 +42,12 @@ const (
 	defaultScalingDecisionInterval = 30 * time.Second
 )
 
+// extensions caches lookup tables, data models and redaction rules across invocations
+var extensions *processor.Extensions
+
 func main() {
 	common.Setup()
+	extensions = processor.NewExtensions()
 	lambda.Start(handle)
 }
 
@@ -68,11 +72,7 @@ func process(ctx context.Context, scalingDecisionInterval time.Duration) (err er
 	}()
 
 	apiResolver := &logtypesapi.Resolver{
-		LogTypesAPI: &logtypesapi.LogTypesAPILambdaClient{
-			LambdaName: logtypesapi.LambdaName,
-			LambdaAPI:  common.LambdaClient,
-			Validate:   validator.New().Struct,
-		},
+		LogTypesAPI:    newLogTypesAPI(),
 		NativeLogTypes: logtypes.MustMerge("native", registry.NativeLogTypes(), snapshotlogs.LogTypes()),
 	}
 
@@ -105,8 +105,24 @@ func process(ctx context.Context, scalingDecisionInterval time.Duration) (err er
 		}
 	}()
 
+	enricher, normalizer := extensions.Load(ctx)
+
+	// Events must not be stored without redacting personal data, the messages will be retried
+	redactor, err := extensions.Redactor(ctx)
+	if err != nil {
+		return err
+	}
+
 	parsersResolver := logtypes.ParserResolver(logTypesResolver)
-	sqsMessageCount, err = processor.PollEvents(ctx, common.SqsClient, parsersResolver)
+	sqsMessageCount, err = processor.PollEvents(ctx, common.SqsClient, parsersResolver, enricher, normalizer, redactor)
 
 	return err
 }
+
+func newLogTypesAPI() *logtypesapi.LogTypesAPILambdaClient {
+	return &logtypesapi.LogTypesAPILambdaClient{
+		LambdaName: logtypesapi.LambdaName,
+		LambdaAPI:  common.LambdaClient,
+		Validate:   validator.New().Struct,
+	}
+}
@@ -31,6 +31,7 @@ const (
 	MetricLogProcessorOutputBytes     = "OutputBytes"
 	MetricLogProcessorBytesProcessed  = "BytesProcessed"
 	MetricLogProcessorEventsProcessed = "EventsProcessed"
+	MetricLogProcessorEventsFiltered  = "EventsFiltered"
 	MetricLogProcessorEventLatency    = "EventLatency"
 
 	// StatusDimension indicating that a subsystem operation is well
@@ -46,6 +47,7 @@ var (
 	GetObject           metrics.Counter
 	BytesProcessed      metrics.Counter
 	EventsProcessed     metrics.Counter
+	EventsFiltered      metrics.Counter
 	EventLatencySeconds metrics.Counter
 	OutputFiles         metrics.Counter
 	OutputBytes         metrics.Counter
@@ -65,5 +67,6 @@ func Setup() {
 	// Note that these don't have all the dimensions
 	BytesProcessed = CWManager.NewCounter(MetricLogProcessorBytesProcessed, metrics.UnitBytes)
 	EventsProcessed = CWManager.NewCounter(MetricLogProcessorEventsProcessed, metrics.UnitCount)
+	EventsFiltered = CWManager.NewCounter(MetricLogProcessorEventsFiltered, metrics.UnitCount)
 	EventLatencySeconds = CWManager.NewCounter(MetricLogProcessorEventLatency, metrics.UnitSeconds)
 }
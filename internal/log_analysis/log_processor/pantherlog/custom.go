@@ -0,0 +1,202 @@
+package pantherlog
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Custom indicator fields are defined by user configuration (ie custom log schemas) and are registered at runtime.
+// They are kept apart from the fields registered at `init()` so that lookups of built-in fields remain lock-free.
+// Readers load a snapshot of the custom registry and writers replace it with an updated copy.
+var (
+	customRegistry   atomic.Value
+	customRegistryMu sync.Mutex
+)
+
+// customFieldOffset is the first field id assigned to custom indicator fields.
+const customFieldOffset FieldID = 1 << 16
+
+type customFields struct {
+	fields    map[FieldID]reflect.StructField
+	namesJSON map[FieldID]string
+	byName    map[string]FieldID
+	scanners  map[string]*scannerEntry
+}
+
+func loadCustomFields() *customFields {
+	if r, ok := customRegistry.Load().(*customFields); ok {
+		return r
+	}
+	return &customFields{}
+}
+
+func (r *customFields) clone() *customFields {
+	out := customFields{
+		fields:    make(map[FieldID]reflect.StructField, len(r.fields)+1),
+		namesJSON: make(map[FieldID]string, len(r.namesJSON)+1),
+		byName:    make(map[string]FieldID, len(r.byName)+2),
+		scanners:  make(map[string]*scannerEntry, len(r.scanners)+1),
+	}
+	for id, field := range r.fields {
+		out.fields[id] = field
+	}
+	for id, name := range r.namesJSON {
+		out.namesJSON[id] = name
+	}
+	for name, id := range r.byName {
+		out.byName[name] = id
+	}
+	for name, entry := range r.scanners {
+		out.scanners[name] = entry
+	}
+	return &out
+}
+
+var customFieldNameJSON = regexp.MustCompile(`^p_any_[a-z][a-z0-9_]*$`)
+
+// RegisterCustomIndicator registers an indicator field defined by user configuration and returns its id.
+// The field name must be of the form `p_any_<name>`.
+// If a field with the same JSON name is already registered (either built-in or custom) its id is returned instead.
+// Unlike RegisterIndicator, it is safe to use concurrently after `init()`.
+func RegisterCustomIndicator(nameJSON, description string) (FieldID, error) {
+	if id, ok := lookupFieldByName(nameJSON); ok {
+		if id.IsCore() {
+			return FieldNone, errors.Errorf(`invalid field name JSON %q`, nameJSON)
+		}
+		return id, nil
+	}
+	if !customFieldNameJSON.MatchString(nameJSON) {
+		return FieldNone, errors.Errorf(`invalid field name JSON %q`, nameJSON)
+	}
+	if description == "" {
+		description = "Panther added field with collection of custom indicator values associated with the row"
+	}
+	field := FieldMeta{
+		Name:     customFieldName(nameJSON),
+		NameJSON: nameJSON,
+		// Make sure the description does not break the struct tag
+		Description: strings.ReplaceAll(description, `"`, `'`),
+	}
+
+	customRegistryMu.Lock()
+	defer customRegistryMu.Unlock()
+	r := loadCustomFields()
+	// Check again while holding the lock in case another writer registered the field
+	if id, ok := r.byName[nameJSON]; ok {
+		return id, nil
+	}
+	if _, duplicate := lookupFieldByName(field.Name); duplicate {
+		return FieldNone, errors.Errorf(`duplicate field name %q`, field.Name)
+	}
+	id := customFieldOffset + FieldID(len(r.fields))
+	r = r.clone()
+	r.fields[id] = field.StructField()
+	r.namesJSON[id] = field.NameJSON
+	r.byName[field.Name] = id
+	r.byName[field.NameJSON] = id
+	customRegistry.Store(r)
+	return id, nil
+}
+
+// RegisterCustomScanner registers a value scanner defined by user configuration.
+// It replaces any custom scanner previously registered with the same name.
+// Encoders look up custom scanners by name when scanning values, so the new scanner also affects types built earlier.
+// The field ids are only used when building event schemas, callers should register fields with RegisterCustomIndicator.
+// Names of scanners registered at `init()` cannot be used.
+// Unlike RegisterScanner, it is safe to use concurrently after `init()`.
+func RegisterCustomScanner(name string, scanner ValueScanner, fields ...FieldID) error {
+	if name == "" {
+		return errors.New("anonymous scanner")
+	}
+	if scanner == nil {
+		return errors.New("nil scanner")
+	}
+	if _, builtin := registeredScanners[name]; builtin {
+		return errors.Errorf("duplicate scanner %q", name)
+	}
+	if len(fields) == 0 {
+		return errors.New("no value fields")
+	}
+	for _, id := range fields {
+		if _, ok := lookupField(id); !ok || id.IsCore() {
+			return errors.New("unregistered field id")
+		}
+	}
+
+	customRegistryMu.Lock()
+	defer customRegistryMu.Unlock()
+	r := loadCustomFields().clone()
+	r.scanners[name] = &scannerEntry{
+		Scanner: scanner,
+		Fields:  append([]FieldID(nil), fields...),
+	}
+	customRegistry.Store(r)
+	return nil
+}
+
+// customScanner resolves a custom scanner by name each time it scans a value.
+// Encoders are cached per type (and struct types built with reflect.StructOf are cached too) so resolving
+// custom scanners when the encoder is built would keep using a replaced scanner until the process restarts.
+type customScanner string
+
+func (name customScanner) ScanValues(w ValueWriter, input string) {
+	if entry, ok := loadCustomFields().scanners[string(name)]; ok {
+		entry.Scanner.ScanValues(w, input)
+	}
+}
+
+// lookupField finds the struct field of a registered field id
+func lookupField(id FieldID) (reflect.StructField, bool) {
+	if field, ok := registeredFields[id]; ok {
+		return field, true
+	}
+	field, ok := loadCustomFields().fields[id]
+	return field, ok
+}
+
+// lookupFieldByName finds the id of a registered field by its JSON or go field name
+func lookupFieldByName(name string) (FieldID, bool) {
+	if id, ok := fieldsByName[name]; ok {
+		return id, true
+	}
+	id, ok := loadCustomFields().byName[name]
+	return id, ok
+}
+
+// customFieldName converts `p_any_foo_bar` to `PantherAnyFooBar`
+func customFieldName(nameJSON string) string {
+	parts := strings.Split(strings.TrimPrefix(nameJSON, FieldPrefixJSON), "_")
+	name := strings.Builder{}
+	name.WriteString(FieldPrefix)
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		name.WriteString(strings.ToUpper(part[:1]))
+		name.WriteString(part[1:])
+	}
+	return name.String()
+}
@@ -0,0 +1,138 @@
+package pantherlog_test
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/log_analysis/awsglue/glueschema"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+func TestRegisterCustomIndicator(t *testing.T) {
+	assert := require.New(t)
+	id, err := pantherlog.RegisterCustomIndicator("p_any_employee_ids", "Employee ids")
+	assert.NoError(err)
+	assert.False(id.IsCore())
+	assert.Equal("p_any_employee_ids", pantherlog.FieldNameJSON(id))
+	assert.Contains(pantherlog.RegisteredFieldNamesJSON(), "p_any_employee_ids")
+	again, err := pantherlog.RegisterCustomIndicator("p_any_employee_ids", "")
+	assert.NoError(err)
+	assert.Equal(id, again)
+	found, ok := pantherlog.IndicatorFieldByNameJSON("p_any_employee_ids")
+	assert.True(ok)
+	assert.Equal(id, found)
+
+	builtin, err := pantherlog.RegisterCustomIndicator("p_any_emails", "")
+	assert.NoError(err)
+	assert.Equal(pantherlog.FieldEmail, builtin)
+
+	_, err = pantherlog.RegisterCustomIndicator("p_event_time", "")
+	assert.Error(err)
+	_, err = pantherlog.RegisterCustomIndicator("employee_ids", "")
+	assert.Error(err)
+	_, err = pantherlog.RegisterCustomIndicator("p_any_Employee", "")
+	assert.Error(err)
+}
+
+func TestRegisterCustomScanner(t *testing.T) {
+	assert := require.New(t)
+	id, err := pantherlog.RegisterCustomIndicator("p_any_device_serials", `Device "serial" numbers`)
+	assert.NoError(err)
+	assert.Error(pantherlog.RegisterCustomScanner("ip", id, id))
+	assert.Error(pantherlog.RegisterCustomScanner("serial", id))
+	assert.Error(pantherlog.RegisterCustomScanner("serial", id, pantherlog.CoreFieldRowID))
+	assert.NoError(pantherlog.RegisterCustomScanner("serial", id, id))
+
+	scanner, fields := pantherlog.LookupScanner("serial")
+	assert.NotNil(scanner)
+	assert.Equal([]pantherlog.FieldID{id}, fields)
+
+	type T struct {
+		Serial string `json:"serial" panther:"serial"`
+		Host   string `json:"host" panther:"ip"`
+	}
+	schema := pantherlog.MustBuildEventSchema(&T{})
+	field, ok := reflect.TypeOf(schema).Elem().FieldByName("PantherAnyDeviceSerials")
+	assert.True(ok)
+	assert.Equal(`json:"p_any_device_serials,omitempty" description:"Device 'serial' numbers"`, string(field.Tag))
+	columns, err := glueschema.InferColumns(schema)
+	assert.NoError(err)
+	var columnNames []string
+	for _, c := range columns {
+		columnNames = append(columnNames, c.Name)
+	}
+	assert.Contains(columnNames, "p_any_device_serials")
+
+	result := pantherlog.Result{
+		CoreFields: pantherlog.CoreFields{
+			PantherLogType: "Foo.Bar",
+			PantherRowID:   "id",
+		},
+		Event: &T{
+			Serial: "ABC123",
+			Host:   "1.1.1.1",
+		},
+	}
+	actual, err := jsoniter.MarshalToString(&result)
+	assert.NoError(err)
+	out := map[string]interface{}{}
+	assert.NoError(jsoniter.UnmarshalFromString(actual, &out))
+	assert.Equal([]interface{}{"ABC123"}, out["p_any_device_serials"])
+	assert.Equal([]interface{}{"1.1.1.1"}, out["p_any_ip_addresses"])
+}
+
+func TestReplaceCustomScanner(t *testing.T) {
+	assert := require.New(t)
+	id, err := pantherlog.RegisterCustomIndicator("p_any_badge_ids", "")
+	assert.NoError(err)
+	type T struct {
+		Badge string `json:"badge" panther:"badge"`
+	}
+	marshal := func() interface{} {
+		result := pantherlog.Result{
+			CoreFields: pantherlog.CoreFields{
+				PantherLogType: "Foo.Bar",
+				PantherRowID:   "id",
+			},
+			Event: &T{
+				Badge: "b-42",
+			},
+		}
+		actual, err := jsoniter.MarshalToString(&result)
+		assert.NoError(err)
+		out := map[string]interface{}{}
+		assert.NoError(jsoniter.UnmarshalFromString(actual, &out))
+		return out["p_any_badge_ids"]
+	}
+	// The encoder is built before the scanner is registered
+	assert.Nil(marshal())
+	assert.NoError(pantherlog.RegisterCustomScanner("badge", id, id))
+	assert.Equal([]interface{}{"b-42"}, marshal())
+	upper := pantherlog.ValueScannerFunc(func(w pantherlog.ValueWriter, input string) {
+		w.WriteValues(id, strings.ToUpper(input))
+	})
+	assert.NoError(pantherlog.RegisterCustomScanner("badge", upper, id))
+	assert.Equal([]interface{}{"B-42"}, marshal())
+}
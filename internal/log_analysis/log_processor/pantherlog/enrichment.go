@@ -0,0 +1,40 @@
+package pantherlog
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Enrichment holds the lookup table rows matching the indicator values of an event.
+// It maps lookup table names to matched indicator values to the columns of the matching row.
+type Enrichment map[string]map[string]map[string]string
+
+// Enricher joins lookup table rows to events.
+type Enricher interface {
+	// Enrich returns the lookup table rows matching the collected indicator values of an event.
+	// It should return nil if no rows match.
+	Enrich(values *ValueBuffer) Enrichment
+}
+
+// EnricherFunc is a function implementing the Enricher interface
+type EnricherFunc func(values *ValueBuffer) Enrichment
+
+var _ Enricher = (EnricherFunc)(nil)
+
+// Enrich implements Enricher interface
+func (f EnricherFunc) Enrich(values *ValueBuffer) Enrichment {
+	return f(values)
+}
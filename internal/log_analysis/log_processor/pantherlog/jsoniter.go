@@ -75,10 +75,14 @@ func (*resultEncoder) IsEmpty(ptr unsafe.Pointer) bool {
 // Encode implements jsoniter.ValEncoder interface
 func (e *resultEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	result := (*Result)(ptr)
+	if result.encoded != nil {
+		stream.SetBuffer(append(stream.Buffer(), result.encoded...))
+		return
+	}
 	// Hack around events with embedded parsers.PantherLog.
 	// TODO: Remove this once all parsers are ported to not use parsers.PantherLog
 	if result.EventIncludesPantherFields {
-		stream.WriteVal(result.Event)
+		e.encodeLegacy(result, stream)
 		return
 	}
 
@@ -91,11 +95,11 @@ func (e *resultEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	// We swap the attachment after we're done so other code that depends on their set attachment behaves correctly
 	att := stream.Attachment
 	stream.Attachment = result
+	start := len(stream.Buffer())
 	stream.WriteVal(result.Event)
 	stream.Attachment = att
 
-	// Extend the JSON object in the stream buffer with the required Panther fields
-	e.writePantherFields(result, stream)
+	e.extendEvent(result, start, stream)
 
 	// Recycle value buffer if it was borrowed
 	if values == nil {
@@ -104,8 +108,99 @@ func (e *resultEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	result.values = values
 }
 
+// encodeLegacy encodes events with embedded parsers.PantherLog.
+// If the event needs to be enriched, normalized or redacted, its Panther fields are moved out of the event JSON object,
+// so that lookup tables, data models and redaction apply the same way as for other events.
+func (e *resultEncoder) encodeLegacy(result *Result, stream *jsoniter.Stream) {
+	redacts := result.Redactor != nil && result.Redactor.Redacts(result.PantherLogType)
+	if !redacts && result.Enricher == nil && result.Normalizer == nil {
+		stream.WriteVal(result.Event)
+		return
+	}
+	start := len(stream.Buffer())
+	stream.WriteVal(result.Event)
+	if stream.Error != nil {
+		return
+	}
+	buf := stream.Buffer()
+	event := append([]byte(nil), buf[start:]...)
+	stream.SetBuffer(buf[:start])
+
+	values := result.values
+	if values == nil {
+		result.values = BlankValueBuffer()
+	}
+	iter := jsoniter.ConfigDefault.BorrowIterator(event)
+	defer jsoniter.ConfigDefault.ReturnIterator(iter)
+	more := false
+	stream.WriteObjectStart()
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+		if !strings.HasPrefix(field, FieldPrefixJSON) {
+			if more {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField(field)
+			stream.WriteRaw(string(iter.SkipAndReturnBytes()))
+			more = true
+			return true
+		}
+		// Core fields are written from the result, except for the source fields set on the event
+		switch field {
+		case FieldSourceIDJSON:
+			result.PantherSourceID = iter.ReadString()
+		case FieldSourceLabelJSON:
+			result.PantherSourceLabel = iter.ReadString()
+		default:
+			id, ok := IndicatorFieldByNameJSON(field)
+			if !ok {
+				iter.Skip()
+				return true
+			}
+			for iter.ReadArray() {
+				result.values.WriteValues(id, iter.ReadString())
+			}
+		}
+		return true
+	})
+	stream.WriteObjectEnd()
+	if iter.Error != nil {
+		// The event was written by our encoder, this should never happen
+		stream.Error = iter.Error
+	}
+
+	e.extendEvent(result, start, stream)
+
+	if values == nil {
+		result.values.Recycle()
+	}
+	result.values = values
+}
+
+// extendEvent redacts the event JSON object written at start and extends it with the Panther fields
+func (e *resultEncoder) extendEvent(result *Result, start int, stream *jsoniter.Stream) {
+	// Lookup tables are joined on the original indicator values, before they are redacted.
+	// Data model fields are resolved after redaction so they never hold the original values.
+	if result.Redactor != nil {
+		if result.Enricher != nil && result.PantherEnrichment == nil {
+			result.PantherEnrichment = result.Enricher.Enrich(result.values)
+		}
+		buf := stream.Buffer()
+		event := result.Redactor.Redact(result.PantherLogType, buf[start:], result.values, result.PantherEnrichment)
+		stream.SetBuffer(append(buf[:start], event...))
+	}
+
+	// Data model fields are resolved from the event JSON before it is extended with Panther fields
+	var udm UDM
+	if result.Normalizer != nil {
+		udm = result.Normalizer.Normalize(result.PantherLogType, stream.Buffer()[start:])
+	}
+
+	// Extend the JSON object in the stream buffer with the required Panther fields
+	e.writePantherFields(result, udm, stream)
+}
+
 // writePantherFields extends the JSON object buffer with all required Panther fields.
-func (*resultEncoder) writePantherFields(r *Result, stream *jsoniter.Stream) {
+func (*resultEncoder) writePantherFields(r *Result, udm UDM, stream *jsoniter.Stream) {
 	// For unit tests it will be useful to be able to write only the panther added field as a 'proper' JSON object
 	if !extendJSON(stream.Buffer()) {
 		stream.Reset(nil)
@@ -148,7 +243,9 @@ func (*resultEncoder) writePantherFields(r *Result, stream *jsoniter.Stream) {
 		}
 		fieldName, ok := registeredFieldNamesJSON[id]
 		if !ok {
-			continue
+			if fieldName = FieldNameJSON(id); fieldName == "" {
+				continue
+			}
 		}
 		sort.Strings(values)
 		stream.WriteMore()
@@ -163,6 +260,21 @@ func (*resultEncoder) writePantherFields(r *Result, stream *jsoniter.Stream) {
 		stream.WriteArrayEnd()
 	}
 
+	if r.Enricher != nil && r.PantherEnrichment == nil {
+		r.PantherEnrichment = r.Enricher.Enrich(r.values)
+	}
+	if len(r.PantherEnrichment) != 0 {
+		stream.WriteMore()
+		stream.WriteObjectField(FieldEnrichmentJSON)
+		stream.WriteVal(r.PantherEnrichment)
+	}
+
+	if len(udm) != 0 {
+		stream.WriteMore()
+		stream.WriteObjectField(FieldUDMJSON)
+		stream.WriteVal(udm)
+	}
+
 	stream.WriteObjectEnd()
 }
 
@@ -303,9 +415,15 @@ func isIndicatorTag(tag reflect.StructTag) (scanners []ValueScanner, ok bool) {
 	}
 	for _, scannerName := range strings.Split(indicatorTag, ",") {
 		scannerName = strings.TrimSpace(scannerName)
-		if scanner, _ := LookupScanner(scannerName); scanner != nil {
-			scanners = append(scanners, scanner)
+		if scannerName == "" {
+			continue
+		}
+		if entry, ok := registeredScanners[scannerName]; ok {
+			scanners = append(scanners, entry.Scanner)
+			continue
 		}
+		// Custom scanners can be registered or replaced after the encoder is built
+		scanners = append(scanners, customScanner(scannerName))
 	}
 	return scanners, len(scanners) > 0
 }
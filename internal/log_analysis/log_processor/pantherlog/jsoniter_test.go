@@ -171,6 +171,127 @@ func TestResultEncoder(t *testing.T) {
 	assert.JSONEq(expect, actual)
 }
 
+func TestResultEncoderEnrichment(t *testing.T) {
+	now := time.Now()
+	assert := require.New(t)
+	type T struct {
+		RemoteIP string `json:"remote_ip" panther:"ip"`
+	}
+	result := Result{
+		CoreFields: CoreFields{
+			PantherLogType:   "Foo.Bar",
+			PantherRowID:     "id",
+			PantherParseTime: now.UTC(),
+		},
+		Event: &T{RemoteIP: "1.1.1.1"},
+		Enricher: EnricherFunc(func(values *ValueBuffer) Enrichment {
+			ips := values.Get(FieldIPAddress)
+			if len(ips) == 0 {
+				return nil
+			}
+			return Enrichment{
+				"geo": {
+					ips[0]: {"country": "GR"},
+				},
+			}
+		}),
+	}
+	actual, err := jsoniter.MarshalToString(&result)
+	assert.NoError(err)
+	expect := fmt.Sprintf(`{
+		"remote_ip":"1.1.1.1",
+		"p_row_id": "id",
+		"p_event_time": "%s",
+		"p_parse_time": "%s",
+		"p_any_ip_addresses": ["1.1.1.1"],
+		"p_enrichment": {"geo":{"1.1.1.1":{"country":"GR"}}},
+		"p_log_type": "Foo.Bar"
+	}`, now.UTC().Format(time.RFC3339Nano), now.UTC().Format(time.RFC3339Nano))
+	assert.JSONEq(expect, actual)
+}
+
+func TestResultEncoderUDM(t *testing.T) {
+	now := time.Now()
+	assert := require.New(t)
+	type T struct {
+		RemoteIP string `json:"remote_ip"`
+	}
+	result := Result{
+		CoreFields: CoreFields{
+			PantherLogType:   "Foo.Bar",
+			PantherRowID:     "id",
+			PantherParseTime: now.UTC(),
+		},
+		Event: &T{RemoteIP: "1.1.1.1"},
+		Normalizer: NormalizerFunc(func(logType string, event []byte) UDM {
+			assert.Equal("Foo.Bar", logType)
+			assert.JSONEq(`{"remote_ip":"1.1.1.1"}`, string(event))
+			return UDM{
+				"source_ip": "1.1.1.1",
+			}
+		}),
+	}
+	actual, err := jsoniter.MarshalToString(&result)
+	assert.NoError(err)
+	expect := fmt.Sprintf(`{
+		"remote_ip":"1.1.1.1",
+		"p_row_id": "id",
+		"p_event_time": "%s",
+		"p_parse_time": "%s",
+		"p_udm": {"source_ip":"1.1.1.1"},
+		"p_log_type": "Foo.Bar"
+	}`, now.UTC().Format(time.RFC3339Nano), now.UTC().Format(time.RFC3339Nano))
+	assert.JSONEq(expect, actual)
+}
+
+func TestResultEncoderRedactor(t *testing.T) {
+	now := time.Now()
+	assert := require.New(t)
+	type T struct {
+		RemoteIP string `json:"remote_ip" panther:"ip"`
+	}
+	result := Result{
+		CoreFields: CoreFields{
+			PantherLogType:   "Foo.Bar",
+			PantherRowID:     "id",
+			PantherParseTime: now.UTC(),
+		},
+		Event: &T{RemoteIP: "1.1.1.1"},
+		Enricher: EnricherFunc(func(values *ValueBuffer) Enrichment {
+			return Enrichment{
+				"geo": {
+					values.Get(FieldIPAddress)[0]: {"country": "GR"},
+				},
+			}
+		}),
+		Normalizer: NormalizerFunc(func(_ string, event []byte) UDM {
+			return UDM{"event": string(event)}
+		}),
+		Redactor: testRedactor(func(_ string, _ []byte, values *ValueBuffer, enrichment Enrichment) []byte {
+			values.MapValues(FieldIPAddress, func(string) string {
+				return "x.x.x.x"
+			})
+			enrichment["geo"]["x.x.x.x"] = enrichment["geo"]["1.1.1.1"]
+			delete(enrichment["geo"], "1.1.1.1")
+			return []byte(`{"remote_ip":"x.x.x.x"}`)
+		}),
+	}
+	actual, err := jsoniter.MarshalToString(&result)
+	assert.NoError(err)
+	// Lookup tables are joined on the original values but data model fields are resolved from the redacted event
+	expect := fmt.Sprintf(`{
+		"remote_ip":"x.x.x.x",
+		"p_row_id": "id",
+		"p_event_time": "%s",
+		"p_parse_time": "%s",
+		"p_any_ip_addresses": ["x.x.x.x"],
+		"p_enrichment": {"geo":{"x.x.x.x":{"country":"GR"}}},
+		"p_udm": {"event":"{\"remote_ip\":\"x.x.x.x\"}"},
+		"p_log_type": "Foo.Bar"
+	}`, now.UTC().Format(time.RFC3339Nano), now.UTC().Format(time.RFC3339Nano))
+	assert.JSONEq(expect, actual)
+}
+
 func TestResultEncoderEmptyEvent(t *testing.T) {
 	now := time.Now()
 	assert := require.New(t)
@@ -218,3 +339,13 @@ func TestResultEncoderNilEvent(t *testing.T) {
 	}`, now.UTC().Format(time.RFC3339Nano), now.UTC().Format(time.RFC3339Nano))
 	assert.JSONEq(expect, actual)
 }
+
+type testRedactor func(logType string, event []byte, values *ValueBuffer, enrichment Enrichment) []byte
+
+func (f testRedactor) Redact(logType string, event []byte, values *ValueBuffer, enrichment Enrichment) []byte {
+	return f(logType, event, values, enrichment)
+}
+
+func (f testRedactor) Redacts(_ string) bool {
+	return true
+}
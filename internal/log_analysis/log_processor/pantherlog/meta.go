@@ -44,6 +44,7 @@ const (
 	CoreFieldRowID
 	CoreFieldSourceID
 	CoreFieldSourceLabel
+	CoreFieldEnrichment
 )
 
 func coreField(id FieldID) reflect.StructField {
@@ -59,6 +60,7 @@ func (id FieldID) IsCore() bool {
 // These fields collect string values from the log event.
 // Each log type can choose the indicator fields it requires.
 // Modules can register new indicator fields at init() using RegisterIndicator
+// Indicator fields defined by user configuration are registered at runtime using RegisterCustomIndicator
 const (
 	FieldIPAddress FieldID = 1 + iota
 	FieldDomainName
@@ -82,12 +84,13 @@ func (id FieldID) ScanValues(w ValueWriter, input string) {
 // CoreFields are the 'core' fields Panther adds to each log.
 // External modules cannot add core fields.
 type CoreFields struct {
-	PantherEventTime   time.Time `json:"p_event_time" validate:"required" description:"Panther added standardized event time (UTC)"`
-	PantherParseTime   time.Time `json:"p_parse_time" validate:"required" description:"Panther added standardized log parse time (UTC)"`
-	PantherLogType     string    `json:"p_log_type" validate:"required" description:"Panther added field with type of log"`
-	PantherRowID       string    `json:"p_row_id" validate:"required" description:"Panther added field with unique id (within table)"`
-	PantherSourceID    string    `json:"p_source_id,omitempty" description:"Panther added field with the source id"`
-	PantherSourceLabel string    `json:"p_source_label,omitempty" description:"Panther added field with the source label"`
+	PantherEventTime   time.Time  `json:"p_event_time" validate:"required" description:"Panther added standardized event time (UTC)"`
+	PantherParseTime   time.Time  `json:"p_parse_time" validate:"required" description:"Panther added standardized log parse time (UTC)"`
+	PantherLogType     string     `json:"p_log_type" validate:"required" description:"Panther added field with type of log"`
+	PantherRowID       string     `json:"p_row_id" validate:"required" description:"Panther added field with unique id (within table)"`
+	PantherSourceID    string     `json:"p_source_id,omitempty" description:"Panther added field with the source id"`
+	PantherSourceLabel string     `json:"p_source_label,omitempty" description:"Panther added field with the source label"`
+	PantherEnrichment  Enrichment `json:"p_enrichment,omitempty" description:"Panther added field with rows from lookup tables matching indicator values"`
 }
 
 const (
@@ -100,6 +103,8 @@ const (
 	FieldParseTimeJSON   = FieldPrefixJSON + "parse_time"
 	FieldSourceIDJSON    = FieldPrefixJSON + "source_id"
 	FieldSourceLabelJSON = FieldPrefixJSON + "source_label"
+	FieldEnrichmentJSON  = FieldPrefixJSON + "enrichment"
+	FieldUDMJSON         = FieldPrefixJSON + "udm"
 )
 
 var (
@@ -114,6 +119,7 @@ var (
 		CoreFieldLogType:     coreField(CoreFieldLogType),
 		CoreFieldSourceID:    coreField(CoreFieldSourceID),
 		CoreFieldSourceLabel: coreField(CoreFieldSourceLabel),
+		CoreFieldEnrichment:  coreField(CoreFieldEnrichment),
 	}
 	// registeredFieldNamesJSON stores the JSON field names of registered field ids.
 	registeredFieldNamesJSON = map[FieldID]string{}
@@ -122,20 +128,27 @@ var (
 		// Reserve field name for embedded event
 		"PantherEvent": FieldNone,
 		// Reserve all field names for core fields
-		FieldEventTimeJSON: FieldNone,
-		"PantherEventTime": FieldNone,
-		FieldParseTimeJSON: FieldNone,
-		"PantherParseTime": FieldNone,
-		FieldLogTypeJSON:   FieldNone,
-		"PantherLogType":   FieldNone,
-		FieldRowIDJSON:     FieldNone,
-		"PantherRowID":     FieldNone,
+		FieldEventTimeJSON:  FieldNone,
+		"PantherEventTime":  FieldNone,
+		FieldParseTimeJSON:  FieldNone,
+		"PantherParseTime":  FieldNone,
+		FieldLogTypeJSON:    FieldNone,
+		"PantherLogType":    FieldNone,
+		FieldRowIDJSON:      FieldNone,
+		"PantherRowID":      FieldNone,
+		FieldEnrichmentJSON: FieldNone,
+		"PantherEnrichment": FieldNone,
+		FieldUDMJSON:        FieldNone,
+		"PantherUDM":        FieldNone,
 	}
 )
 
 // FieldNameJSON returns the JSON field name of a field id.
 func FieldNameJSON(kind FieldID) string {
-	return registeredFieldNamesJSON[kind]
+	if name, ok := registeredFieldNamesJSON[kind]; ok {
+		return name
+	}
+	return loadCustomFields().namesJSON[kind]
 }
 
 // RegisteredFieldNamesJSON returns the JSON field names for registered indicator fields
@@ -146,9 +159,27 @@ func RegisteredFieldNamesJSON() (names []string) {
 		}
 		names = append(names, name)
 	}
+	for _, name := range loadCustomFields().namesJSON {
+		names = append(names, name)
+	}
 	return
 }
 
+// IndicatorFieldByNameJSON returns the field id of a registered indicator field by its JSON field name.
+func IndicatorFieldByNameJSON(name string) (FieldID, bool) {
+	for id, fieldName := range registeredFieldNamesJSON {
+		if fieldName == name && !id.IsCore() {
+			return id, true
+		}
+	}
+	for id, fieldName := range loadCustomFields().namesJSON {
+		if fieldName == name {
+			return id, true
+		}
+	}
+	return FieldNone, false
+}
+
 func init() {
 	MustRegisterIndicator(FieldIPAddress, FieldMeta{
 		Name:        "PantherAnyIPAddresses",
@@ -351,7 +382,7 @@ func BuildEventTypeSchema(eventType reflect.Type, indicators ...FieldID) (reflec
 			return nil, errors.New(`invalid field id`)
 		}
 
-		field, ok := registeredFields[id]
+		field, ok := lookupField(id)
 		if !ok {
 			continue
 		}
@@ -532,7 +563,7 @@ func FieldSetFromTag(tag string) FieldSet {
 
 	// Lookup field json name to see if it is one of the registered fields
 	if jsonTag, err := tags.Get(`json`); err == nil {
-		if id, ok := fieldsByName[jsonTag.Name]; ok {
+		if id, ok := lookupFieldByName(jsonTag.Name); ok {
 			return FieldSet{id}
 		}
 	}
@@ -578,7 +609,7 @@ func FieldSetFromType(typ reflect.Type) (fields FieldSet) {
 }
 
 func appendFieldSet(fields FieldSet, field *reflect.StructField) FieldSet {
-	if id, ok := fieldsByName[field.Name]; ok {
+	if id, ok := lookupFieldByName(field.Name); ok {
 		return fields.Add(id)
 	}
 
@@ -595,7 +626,7 @@ func FieldSetFromJSON(input []byte) (fields FieldSet) {
 		return nil
 	}
 	for key := range obj {
-		if id, ok := fieldsByName[key]; ok {
+		if id, ok := lookupFieldByName(key); ok {
 			fields = fields.Add(id)
 		}
 	}
@@ -43,6 +43,7 @@ func TestMetaEventStruct(t *testing.T) {
 		"foo":                "foo",
 		"p_any_domain_names": "p_any_domain_names",
 		"p_any_ip_addresses": "p_any_ip_addresses",
+		"p_enrichment":       "p_enrichment",
 		"p_event_time":       "p_event_time",
 		"p_log_type":         "p_log_type",
 		"p_parse_time":       "p_parse_time",
@@ -63,6 +64,7 @@ func TestMetaEventStruct(t *testing.T) {
 		{"p_row_id", "string", "Panther added field with unique id (within table)", true},
 		{"p_source_id", "string", "Panther added field with the source id", false},
 		{"p_source_label", "string", "Panther added field with the source label", false},
+		{"p_enrichment", "map<string,map<string,map<string,string>>>", "Panther added field with rows from lookup tables matching indicator values", false},
 		{"p_any_ip_addresses", "array<string>", "Panther added field with collection of ip addresses associated with the row", false},
 		{"p_any_domain_names", "array<string>", "Panther added field with collection of domain names associated with the row", false},
 	}, columns)
@@ -0,0 +1,30 @@
+package pantherlog
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Redactor removes or tokenizes personal data in events before they are stored.
+type Redactor interface {
+	// Redact returns the redacted JSON object of an event.
+	// It also redacts the indicator values of the event and the keys of the lookup table rows joined on them,
+	// so that Panther fields do not hold the original values.
+	// It should return the event unchanged if the log type has no redaction rules.
+	Redact(logType string, event []byte, values *ValueBuffer, enrichment Enrichment) []byte
+	// Redacts returns true if the log type has redaction rules
+	Redacts(logType string) bool
+}
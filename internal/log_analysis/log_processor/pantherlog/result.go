@@ -35,10 +35,35 @@ type Result struct {
 	// to avoid duplicate panther fields in resulting JSON.
 	// FIXME: Remove this field once all parsers are ported to the new method.
 	EventIncludesPantherFields bool
+	// Enricher joins lookup table rows to the result based on the collected indicator values.
+	// If set, the p_enrichment field is populated when the result is encoded.
+	Enricher Enricher
+	// Normalizer maps event fields to data model fields.
+	// If set, the p_udm field is populated when the result is encoded.
+	Normalizer Normalizer
+	// Redactor removes or tokenizes personal data in the event.
+	// If set, the event and its indicator values are redacted when the result is encoded.
+	Redactor Redactor
 	// Collected indicator values for this result.
 	// This field is normally nil throughout the lifetime of results.
 	// It is populated temporarily by the custom jsoniter encoder for *Result to collect all indicator field values.
 	values *ValueBuffer
+	// The JSON encoding of the result cached by EncodeJSON
+	encoded []byte
+}
+
+// EncodeJSON encodes the result using ConfigJSON.
+// The encoding is cached and reused by the result encoder, so a result that is inspected before it is stored
+// is only encoded once. The result must not be modified after it is encoded.
+func (r *Result) EncodeJSON() ([]byte, error) {
+	if r.encoded == nil {
+		data, err := ConfigJSON().Marshal(r)
+		if err != nil {
+			return nil, err
+		}
+		r.encoded = data
+	}
+	return r.encoded, nil
 }
 
 // WriteValues implements ValueWriter interface
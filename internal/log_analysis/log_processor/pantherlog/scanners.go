@@ -110,7 +110,11 @@ func checkFields(fields []FieldID) error {
 
 // LookupScanner finds a registered scanner and field ids by name.
 func LookupScanner(name string) (scanner ValueScanner, fields []FieldID) {
-	if entry, ok := registeredScanners[name]; ok {
+	entry, ok := registeredScanners[name]
+	if !ok {
+		entry, ok = loadCustomFields().scanners[name]
+	}
+	if ok {
 		scanner = entry.Scanner
 		fields = append(fields, entry.Fields...)
 	}
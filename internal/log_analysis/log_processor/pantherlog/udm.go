@@ -0,0 +1,42 @@
+package pantherlog
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// UDM holds the values of data model fields for an event.
+// It maps data model field names to the string value of the mapped event field.
+// Unlike other Panther fields, its Glue column is not inferred from CoreFields.
+// It is a struct with the fields of the data model enabled for the log type (see awsglue.UDMColumn).
+type UDM map[string]string
+
+// Normalizer maps event fields to data model fields.
+type Normalizer interface {
+	// Normalize returns the data model field values of an event JSON object.
+	// It should return nil if the log type has no data model or no fields match.
+	Normalize(logType string, event []byte) UDM
+}
+
+// NormalizerFunc is a function implementing the Normalizer interface
+type NormalizerFunc func(logType string, event []byte) UDM
+
+var _ Normalizer = (NormalizerFunc)(nil)
+
+// Normalize implements Normalizer interface
+func (f NormalizerFunc) Normalize(logType string, event []byte) UDM {
+	return f(logType, event)
+}
@@ -125,6 +125,31 @@ nextValue:
 	}
 }
 
+// MapValues replaces the values stored for a field id with the result of fn.
+// Values mapped to an empty string are removed.
+func (b *ValueBuffer) MapValues(id FieldID, fn func(value string) string) {
+	values := b.index[id]
+	if len(values) == 0 {
+		return
+	}
+	mapped := values[:0]
+nextValue:
+	for _, value := range values {
+		value = fn(value)
+		if value == "" {
+			continue
+		}
+		// Distinct values can map to the same value
+		for _, v := range mapped {
+			if v == value {
+				continue nextValue
+			}
+		}
+		mapped = append(mapped, value)
+	}
+	b.index[id] = mapped
+}
+
 func (b *ValueBuffer) WriteValuesTo(w ValueWriter) {
 	for id, values := range b.index {
 		w.WriteValues(id, values...)
@@ -64,6 +64,9 @@ type PantherLog struct {
 	PantherAnySHA1Hashes   PantherAnyString `json:"p_any_sha1_hashes,omitempty" description:"Panther added field with collection of SHA1 hashes associated with the row"`
 	PantherAnyMD5Hashes    PantherAnyString `json:"p_any_md5_hashes,omitempty" description:"Panther added field with collection of MD5 hashes associated with the row"`
 	PantherAnySHA256Hashes PantherAnyString `json:"p_any_sha256_hashes,omitempty" description:"Panther added field with collection of SHA256 hashes of any algorithm associated with the row"`
+
+	// Declared for the table schema, the result encoder writes the lookup table rows joined to the event
+	PantherEnrichment pantherlog.Enrichment `json:"p_enrichment,omitempty" description:"Panther added field with rows from lookup tables matching indicator values"`
 }
 
 type PantherAnyString []string
@@ -26,6 +26,7 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/require"
 
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/timestamp"
 )
 
@@ -236,3 +237,45 @@ func TestAppendAnyMD5Hashes(t *testing.T) {
 	event.AppendAnyMD5HashPtrs(&value)
 	require.Equal(t, expectedAny, event.PantherAnyMD5Hashes)
 }
+
+func TestResultEnrichmentAndUDM(t *testing.T) {
+	type legacyEvent struct {
+		User *string `json:"user"`
+		IP   *string `json:"ip"`
+		PantherLog
+	}
+	event := legacyEvent{
+		User: aws.String("alice"),
+		IP:   aws.String("1.1.1.1"),
+	}
+	eventTime := (timestamp.RFC3339)(time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC))
+	event.SetCoreFields("Legacy.Event", &eventTime, &event)
+	event.AppendAnyIPAddress("1.1.1.1")
+	result := event.Result()
+	result.Enricher = pantherlog.EnricherFunc(func(values *pantherlog.ValueBuffer) pantherlog.Enrichment {
+		// Lookup tables are joined on the indicator values of the event
+		if !values.Contains(pantherlog.FieldIPAddress, "1.1.1.1") {
+			return nil
+		}
+		return pantherlog.Enrichment{"geo": {"1.1.1.1": {"country": "AU"}}}
+	})
+	result.Normalizer = pantherlog.NormalizerFunc(func(logType string, event []byte) pantherlog.UDM {
+		require.Equal(t, "Legacy.Event", logType)
+		return pantherlog.UDM{"username": jsoniter.Get(event, "user").ToString()}
+	})
+
+	actual, err := jsoniter.MarshalToString(result)
+	require.NoError(t, err)
+	expect := `{
+		"user": "alice",
+		"ip": "1.1.1.1",
+		"p_log_type": "Legacy.Event",
+		"p_row_id": "` + *event.PantherRowID + `",
+		"p_event_time": "2020-01-02T03:00:00Z",
+		"p_parse_time": "` + (*time.Time)(event.PantherParseTime).Format(time.RFC3339Nano) + `",
+		"p_any_ip_addresses": ["1.1.1.1"],
+		"p_enrichment": {"geo": {"1.1.1.1": {"country": "AU"}}},
+		"p_udm": {"username": "alice"}
+	}`
+	require.JSONEq(t, expect, actual)
+}
@@ -0,0 +1,137 @@
+package preprocessors
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/pkg/x/gork"
+)
+
+// GrokConfig parses text logs using Grok patterns.
+// Patterns and pattern definitions are compatible with Logstash so that existing patterns can be reused as is.
+// nolint:lll
+type GrokConfig struct {
+	Match              []string          `json:"match" yaml:"match" description:"Grok patterns to try in order, the first matching pattern is used"`
+	PatternDefinitions map[string]string `json:"patternDefinitions,omitempty" yaml:"patternDefinitions,omitempty" description:"Additional pattern definitions, overriding builtin patterns with the same name"`
+	PatternLibrary     string            `json:"patternLibrary,omitempty" yaml:"patternLibrary,omitempty" description:"Additional pattern definitions in Logstash patterns file format"`
+	SkipLines          int               `json:"skipLines,omitempty" yaml:"skipLines,omitempty" description:"Number of lines to skip at start of file"`
+	SkipPrefix         string            `json:"skipPrefix,omitempty" yaml:"skipPrefix,omitempty" description:"Skip comment lines by prefix"`
+	EmptyValues        []string          `json:"emptyValues,omitempty" yaml:"emptyValues,omitempty" description:"Placeholder value for empty or missing data"`
+	ExpandFields       map[string]string `json:"expandFields,omitempty" yaml:"expandFields,omitempty" description:"Add fields by text templates"`
+	TrimSpace          bool              `json:"trimSpace,omitempty" yaml:"trimSpace,omitempty" description:"Trim space surrounding values"`
+}
+
+func (config GrokConfig) BuildPreprocessor() (Interface, error) {
+	if len(config.Match) == 0 {
+		return nil, errors.New("no match patterns")
+	}
+	env := gork.New()
+	if config.PatternLibrary != "" {
+		patterns, err := gork.ReadPatterns(strings.NewReader(config.PatternLibrary))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read pattern library")
+		}
+		if err := env.Override(patterns); err != nil {
+			return nil, errors.Wrap(err, "failed to parse pattern library")
+		}
+	}
+	if len(config.PatternDefinitions) > 0 {
+		if err := env.Override(config.PatternDefinitions); err != nil {
+			return nil, errors.Wrap(err, "failed to parse custom patterns")
+		}
+	}
+	patterns := make([]*gork.Pattern, len(config.Match))
+	nested := false
+	for i, src := range config.Match {
+		p, err := env.Compile(src)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile pattern #%d", i)
+		}
+		patterns[i] = p
+		for _, name := range p.Names() {
+			if isFieldReference(name) {
+				nested = true
+			}
+		}
+	}
+	pp := &matchTextPreprocessor{
+		match: func(dst []string, src string) ([]string, error) {
+			for _, p := range patterns {
+				if matches, err := p.MatchString(dst, src); err == nil {
+					// Like Logstash we drop empty captures (ie groups in alternatives that did not match)
+					return omitValues(matches[:len(dst)], matches[len(dst):], emptyCaptures), nil
+				}
+			}
+			return dst, errors.New("No match")
+		},
+		skipLines:    config.SkipLines,
+		skipPrefix:   config.SkipPrefix,
+		emptyValues:  config.EmptyValues,
+		expandFields: compileFieldTemplates(config.ExpandFields),
+		stream:       buildJSONStream(),
+		trimSpace:    config.TrimSpace,
+	}
+	if nested {
+		return Pipeline(pp, &fieldReferencePreprocessor{}), nil
+	}
+	return pp, nil
+}
+
+var emptyCaptures = []string{""}
+
+// isFieldReference checks if a field name is a Logstash field reference (ie `[source][address]`)
+func isFieldReference(name string) bool {
+	return strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]")
+}
+
+// fieldReferencePreprocessor expands Logstash field references to nested JSON objects
+type fieldReferencePreprocessor struct{}
+
+func (*fieldReferencePreprocessor) PreProcessLog(log string) (string, error) {
+	if log == "" {
+		return log, nil
+	}
+	fields := map[string]string{}
+	if err := jsoniter.UnmarshalFromString(log, &fields); err != nil {
+		return "", err
+	}
+	event := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		if !isFieldReference(name) {
+			event[name] = value
+			continue
+		}
+		path := strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, "["), "]"), "][")
+		obj := event
+		for _, key := range path[:len(path)-1] {
+			next, ok := obj[key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				obj[key] = next
+			}
+			obj = next
+		}
+		obj[path[len(path)-1]] = value
+	}
+	return jsoniter.MarshalToString(event)
+}
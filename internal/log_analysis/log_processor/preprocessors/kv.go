@@ -0,0 +1,388 @@
+package preprocessors
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// KVFormatLogfmt parses logfmt entries where keys without a value are set to "true"
+	KVFormatLogfmt = "logfmt"
+	// KVFormatCEF parses ArcSight Common Event Format entries
+	KVFormatCEF = "cef"
+	// KVFormatLEEF parses IBM Log Event Extended Format entries
+	KVFormatLEEF = "leef"
+)
+
+// KVConfig parses logs consisting of key/value pairs.
+// nolint:lll
+type KVConfig struct {
+	Format         string            `json:"format,omitempty" yaml:"format,omitempty" description:"The format of log entries (logfmt, cef, leef), by default generic key/value pairs"`
+	PairDelimiter  string            `json:"pairDelimiter,omitempty" yaml:"pairDelimiter,omitempty" description:"Delimiter between key/value pairs, by default any whitespace (LEEF detects the delimiter from the header)"`
+	ValueDelimiter string            `json:"valueDelimiter,omitempty" yaml:"valueDelimiter,omitempty" description:"Delimiter between keys and values, by default '='"`
+	QuoteChars     string            `json:"quoteChars,omitempty" yaml:"quoteChars,omitempty" description:"Characters used to quote values, by default '\"'"`
+	EscapeChar     string            `json:"escapeChar,omitempty" yaml:"escapeChar,omitempty" description:"Character used to escape delimiters and quotes in values, by default '\\'"`
+	SkipLines      int               `json:"skipLines,omitempty" yaml:"skipLines,omitempty" description:"Number of lines to skip at start of file"`
+	SkipPrefix     string            `json:"skipPrefix,omitempty" yaml:"skipPrefix,omitempty" description:"Skip comment lines by prefix"`
+	EmptyValues    []string          `json:"emptyValues,omitempty" yaml:"emptyValues,omitempty" description:"Placeholder value for empty or missing data"`
+	ExpandFields   map[string]string `json:"expandFields,omitempty" yaml:"expandFields,omitempty" description:"Add fields by text templates"`
+	TrimSpace      bool              `json:"trimSpace,omitempty" yaml:"trimSpace,omitempty" description:"Trim space surrounding values"`
+}
+
+func (config KVConfig) BuildPreprocessor() (Interface, error) {
+	if len(config.EscapeChar) > 1 {
+		return nil, errors.Errorf("invalid escape character %q", config.EscapeChar)
+	}
+	scanner := kvScanner{
+		pairDelimiter:  config.PairDelimiter,
+		valueDelimiter: config.ValueDelimiter,
+		quoteChars:     config.QuoteChars,
+		escapeChar:     '\\',
+	}
+	if scanner.valueDelimiter == "" {
+		scanner.valueDelimiter = "="
+	}
+	if scanner.quoteChars == "" {
+		scanner.quoteChars = `"`
+	}
+	if config.EscapeChar != "" {
+		scanner.escapeChar = config.EscapeChar[0]
+	}
+	if scanner.pairDelimiter == scanner.valueDelimiter {
+		return nil, errors.New("pair and value delimiters must be different")
+	}
+	var match stringMatcher
+	switch config.Format {
+	case "":
+		match = scanner.scan
+	case KVFormatLogfmt:
+		scanner.bareKeyValue = "true"
+		match = scanner.scan
+	case KVFormatCEF:
+		match = matchCEF
+	case KVFormatLEEF:
+		match = (&leefMatcher{
+			pairDelimiter: config.PairDelimiter,
+		}).match
+	default:
+		return nil, errors.Errorf("invalid key/value format %q", config.Format)
+	}
+	return &matchTextPreprocessor{
+		match:        match,
+		skipLines:    config.SkipLines,
+		skipPrefix:   config.SkipPrefix,
+		emptyValues:  config.EmptyValues,
+		expandFields: compileFieldTemplates(config.ExpandFields),
+		stream:       buildJSONStream(),
+		trimSpace:    config.TrimSpace,
+	}, nil
+}
+
+// kvScanner scans generic key/value pairs
+type kvScanner struct {
+	// empty delimiter matches any whitespace
+	pairDelimiter  string
+	valueDelimiter string
+	quoteChars     string
+	escapeChar     byte
+	// value for keys without a value delimiter, if empty such keys are skipped
+	bareKeyValue string
+}
+
+func (s *kvScanner) scan(dst []string, src string) ([]string, error) {
+	n := len(dst)
+	for src != "" {
+		src = s.skipPairDelimiters(src)
+		if src == "" {
+			break
+		}
+		var key string
+		key, src = s.scanKey(src)
+		if !strings.HasPrefix(src, s.valueDelimiter) {
+			if key != "" && s.bareKeyValue != "" {
+				dst = append(dst, key, s.bareKeyValue)
+			}
+			continue
+		}
+		src = src[len(s.valueDelimiter):]
+		var value string
+		var err error
+		if value, src, err = s.scanValue(src); err != nil {
+			return dst[:n], err
+		}
+		if key != "" {
+			dst = append(dst, key, value)
+		}
+	}
+	if len(dst) == n {
+		return dst, errors.New("No match")
+	}
+	return dst, nil
+}
+
+func (s *kvScanner) isPairDelimiter(src string) (int, bool) {
+	if s.pairDelimiter == "" {
+		if src != "" && isSpace(src[0]) {
+			return 1, true
+		}
+		return 0, false
+	}
+	return len(s.pairDelimiter), strings.HasPrefix(src, s.pairDelimiter)
+}
+
+func (s *kvScanner) skipPairDelimiters(src string) string {
+	for {
+		n, ok := s.isPairDelimiter(src)
+		if !ok {
+			return src
+		}
+		src = src[n:]
+	}
+}
+
+func (s *kvScanner) scanKey(src string) (key, tail string) {
+	for i := 0; i < len(src); i++ {
+		if _, ok := s.isPairDelimiter(src[i:]); ok || strings.HasPrefix(src[i:], s.valueDelimiter) {
+			return src[:i], src[i:]
+		}
+	}
+	return src, ""
+}
+
+func (s *kvScanner) scanValue(src string) (value, tail string, err error) {
+	if src != "" && strings.IndexByte(s.quoteChars, src[0]) != -1 {
+		quote := src[0]
+		escaped := false
+		for i := 1; i < len(src); i++ {
+			switch c := src[i]; {
+			case s.isEscape(src, i, quote):
+				i++
+				escaped = true
+			case c == quote:
+				value = src[1:i]
+				if escaped {
+					value = s.unescape(value, quote)
+				}
+				return value, src[i+1:], nil
+			}
+		}
+		return "", "", errors.New("unterminated quoted value")
+	}
+	escaped := false
+	value = src
+	for i := 0; i < len(src); i++ {
+		if s.isEscape(src, i, 0) {
+			i++
+			escaped = true
+			continue
+		}
+		if _, ok := s.isPairDelimiter(src[i:]); ok {
+			value, tail = src[:i], src[i:]
+			break
+		}
+	}
+	if escaped {
+		value = s.unescape(value, 0)
+	}
+	return value, tail, nil
+}
+
+// isEscape checks if there is an escape character at src[i].
+// Escape characters only escape delimiters, quotes and themselves so that values like Windows paths are kept as is.
+func (s *kvScanner) isEscape(src string, i int, quote byte) bool {
+	if src[i] != s.escapeChar || i+1 >= len(src) {
+		return false
+	}
+	next := src[i+1:]
+	if quote != 0 {
+		return next[0] == quote || next[0] == s.escapeChar
+	}
+	if _, ok := s.isPairDelimiter(next); ok {
+		return true
+	}
+	return next[0] == s.escapeChar || strings.HasPrefix(next, s.valueDelimiter) || strings.IndexByte(s.quoteChars, next[0]) != -1
+}
+
+func (s *kvScanner) unescape(value string, quote byte) string {
+	b := strings.Builder{}
+	b.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		if s.isEscape(value, i, quote) {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// CEF header fields are named after ArcSight field names
+var cefHeaderFields = []string{
+	"cefVersion",
+	"deviceVendor",
+	"deviceProduct",
+	"deviceVersion",
+	"deviceEventClassId",
+	"name",
+	"severity",
+}
+
+// matchCEF matches ArcSight Common Event Format entries (ie `CEF:0|Vendor|Product|1.0|100|Name|10|src=10.0.0.1 msg=foo bar`).
+// Any prefix before `CEF:` such as a syslog header is ignored.
+func matchCEF(dst []string, src string) ([]string, error) {
+	pos := strings.Index(src, "CEF:")
+	if pos == -1 {
+		return dst, errors.New("No match")
+	}
+	src = src[pos+len("CEF:"):]
+	n := len(dst)
+	for _, name := range cefHeaderFields {
+		end := indexUnescaped(src, '|')
+		if end == -1 {
+			return dst[:n], errors.New("invalid CEF header")
+		}
+		dst = append(dst, name, unescapeCEF(src[:end]))
+		src = src[end+1:]
+	}
+	return scanCEFExtension(dst, src), nil
+}
+
+func indexUnescaped(src string, c byte) int {
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// scanCEFExtension scans the extension of a CEF entry.
+// Values are not quoted and can contain spaces so a value ends where the next key starts.
+func scanCEFExtension(dst []string, src string) []string {
+	type mark struct {
+		keyStart int
+		keyEnd   int
+	}
+	var marks []mark
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '=':
+			start := i
+			for start > 0 && src[start-1] != ' ' && src[start-1] != '=' {
+				start--
+			}
+			// Unescaped '=' in values (ie URLs) are not keys
+			if start == i || (start > 0 && src[start-1] == '=') {
+				continue
+			}
+			marks = append(marks, mark{keyStart: start, keyEnd: i})
+		}
+	}
+	for i, m := range marks {
+		end := len(src)
+		if i+1 < len(marks) {
+			end = marks[i+1].keyStart
+		}
+		value := strings.TrimRight(src[m.keyEnd+1:end], " ")
+		dst = append(dst, src[m.keyStart:m.keyEnd], unescapeCEF(value))
+	}
+	return dst
+}
+
+var cefUnescape = strings.NewReplacer(`\\`, `\`, `\|`, `|`, `\=`, `=`, `\n`, "\n", `\r`, "\r")
+
+func unescapeCEF(s string) string {
+	if strings.IndexByte(s, '\\') == -1 {
+		return s
+	}
+	return cefUnescape.Replace(s)
+}
+
+// leefMatcher matches IBM Log Event Extended Format entries.
+// LEEF 1.0 attributes are delimited by tab and LEEF 2.0 entries can specify the delimiter in the header.
+type leefMatcher struct {
+	// overrides the delimiter of the entries
+	pairDelimiter string
+}
+
+func (m *leefMatcher) match(dst []string, src string) ([]string, error) {
+	n := len(dst)
+	pos := strings.Index(src, "LEEF:")
+	if pos == -1 {
+		return dst[:n], errors.New("No match")
+	}
+	src = src[pos+len("LEEF:"):]
+	// LEEF:Version|Vendor|Product|Version|EventID|[Delimiter|]Attributes
+	header := strings.SplitN(src, "|", 6)
+	if len(header) < 6 {
+		return dst[:n], errors.New("invalid LEEF header")
+	}
+	dst = append(dst,
+		"leefVersion", header[0],
+		"vendor", header[1],
+		"product", header[2],
+		"version", header[3],
+		"eventId", header[4],
+	)
+	attributes := header[5]
+	delimiter := "\t"
+	if strings.HasPrefix(header[0], "2") {
+		// The delimiter field is optional, attributes always contain a '=' so a field without one is the delimiter
+		if parts := strings.SplitN(attributes, "|", 2); len(parts) == 2 && !strings.Contains(parts[0], "=") {
+			if d := parseLEEFDelimiter(parts[0]); d != "" {
+				delimiter = d
+			}
+			attributes = parts[1]
+		}
+	}
+	if m.pairDelimiter != "" {
+		delimiter = m.pairDelimiter
+	}
+	for _, attr := range strings.Split(attributes, delimiter) {
+		pos := strings.IndexByte(attr, '=')
+		if pos < 1 {
+			continue
+		}
+		dst = append(dst, attr[:pos], attr[pos+1:])
+	}
+	return dst, nil
+}
+
+// parseLEEFDelimiter parses the delimiter of LEEF 2.0 headers which can be a character or its hex code (ie `^`, `0x5E`, `x5E`)
+func parseLEEFDelimiter(s string) string {
+	hexCode := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(s), "0"), "x")
+	if len(s) > 1 && hexCode != s {
+		if b, err := hex.DecodeString(hexCode); err == nil && len(b) == 1 {
+			return string(b)
+		}
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package preprocessors
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nolint:lll
+func TestKVConfig(t *testing.T) {
+	type testCase struct {
+		Name   string
+		Config KVConfig
+		Input  string
+		Expect string
+	}
+	for _, tc := range []testCase{
+		{
+			Name:   "generic",
+			Config: KVConfig{},
+			Input:  `a=1  b="foo \"bar\"" c= d e=C:\Users\foo f=x\ y`,
+			Expect: `{"a":"1","b":"foo \"bar\"","c":"","e":"C:\\Users\\foo","f":"x y"}`,
+		},
+		{
+			Name:   "custom delimiters",
+			Config: KVConfig{PairDelimiter: ";", ValueDelimiter: ":", QuoteChars: `'`},
+			Input:  `a:1;b:'x;y';c:foo bar`,
+			Expect: `{"a":"1","b":"x;y","c":"foo bar"}`,
+		},
+		{
+			Name:   "logfmt",
+			Config: KVConfig{Format: KVFormatLogfmt},
+			Input:  `level=info msg="request done" debug duration=1.5s`,
+			Expect: `{"level":"info","msg":"request done","debug":"true","duration":"1.5s"}`,
+		},
+		{
+			Name:   "cef",
+			Config: KVConfig{Format: KVFormatCEF},
+			Input:  `<134>Oct 10 13:55:36 host CEF:0|Security|threat\|manager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 msg=Detected a threat. No action needed request=http://example.com?a\=b&c=d cs1=a\\b`,
+			Expect: `{"cefVersion":"0","deviceVendor":"Security","deviceProduct":"threat|manager","deviceVersion":"1.0","deviceEventClassId":"100","name":"worm successfully stopped","severity":"10","src":"10.0.0.1","dst":"2.1.2.2","msg":"Detected a threat. No action needed","request":"http://example.com?a=b&c=d","cs1":"a\\b"}`,
+		},
+		{
+			Name:   "leef 1.0",
+			Config: KVConfig{Format: KVFormatLEEF},
+			Input:  "LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tdst=172.50.123.1\tmsg=a=b c",
+			Expect: `{"leefVersion":"1.0","vendor":"Microsoft","product":"MSExchange","version":"4.0 SP1","eventId":"15345","src":"192.0.2.0","dst":"172.50.123.1","msg":"a=b c"}`,
+		},
+		{
+			Name:   "leef 2.0",
+			Config: KVConfig{Format: KVFormatLEEF},
+			Input:  "LEEF:2.0|Lancope|StealthWatch|1.0|41|0x5E|src=10.0.1.8^dst=10.0.0.5^sev=5",
+			Expect: `{"leefVersion":"2.0","vendor":"Lancope","product":"StealthWatch","version":"1.0","eventId":"41","src":"10.0.1.8","dst":"10.0.0.5","sev":"5"}`,
+		},
+		{
+			Name:   "leef 2.0 character delimiter",
+			Config: KVConfig{Format: KVFormatLEEF},
+			Input:  "LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5",
+			Expect: `{"leefVersion":"2.0","vendor":"Lancope","product":"StealthWatch","version":"1.0","eventId":"41","src":"10.0.1.8","dst":"10.0.0.5"}`,
+		},
+		{
+			Name:   "leef 2.0 hex delimiter without leading zero",
+			Config: KVConfig{Format: KVFormatLEEF},
+			Input:  "LEEF:2.0|Lancope|StealthWatch|1.0|41|x7C|src=10.0.1.8|dst=10.0.0.5",
+			Expect: `{"leefVersion":"2.0","vendor":"Lancope","product":"StealthWatch","version":"1.0","eventId":"41","src":"10.0.1.8","dst":"10.0.0.5"}`,
+		},
+		{
+			Name:   "leef 2.0 without delimiter",
+			Config: KVConfig{Format: KVFormatLEEF},
+			Input:  "LEEF:2.0|Lancope|StealthWatch|1.0|41|src=10.0.1.8\tdst=10.0.0.5\tmsg=a|b",
+			Expect: `{"leefVersion":"2.0","vendor":"Lancope","product":"StealthWatch","version":"1.0","eventId":"41","src":"10.0.1.8","dst":"10.0.0.5","msg":"a|b"}`,
+		},
+		{
+			Name:   "leef 2.0 empty delimiter",
+			Config: KVConfig{Format: KVFormatLEEF},
+			Input:  "LEEF:2.0|Lancope|StealthWatch|1.0|41||src=10.0.1.8\tdst=10.0.0.5",
+			Expect: `{"leefVersion":"2.0","vendor":"Lancope","product":"StealthWatch","version":"1.0","eventId":"41","src":"10.0.1.8","dst":"10.0.0.5"}`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			pp, err := tc.Config.BuildPreprocessor()
+			require.NoError(t, err)
+			actual, err := pp.PreProcessLog(tc.Input)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.Expect, actual)
+		})
+	}
+}
+
+func TestKVConfigErrors(t *testing.T) {
+	_, err := KVConfig{Format: "foo"}.BuildPreprocessor()
+	require.Error(t, err)
+	_, err = KVConfig{PairDelimiter: "=", ValueDelimiter: "="}.BuildPreprocessor()
+	require.Error(t, err)
+
+	for _, config := range []KVConfig{{}, {Format: KVFormatCEF}, {Format: KVFormatLEEF}} {
+		pp, err := config.BuildPreprocessor()
+		require.NoError(t, err)
+		_, err = pp.PreProcessLog(`foo bar`)
+		require.Error(t, err)
+	}
+	pp, err := KVConfig{}.BuildPreprocessor()
+	require.NoError(t, err)
+	_, err = pp.PreProcessLog(`a="foo`)
+	require.Error(t, err)
+}
+
+func TestLEEFMatchErrors(t *testing.T) {
+	dst := []string{"foo", "bar"}
+	m := leefMatcher{}
+	for _, input := range []string{
+		"foo bar",
+		"LEEF:2.0|Lancope|StealthWatch|1.0",
+	} {
+		matches, err := m.match(dst, input)
+		require.Error(t, err)
+		require.Equal(t, dst, matches)
+	}
+}
@@ -68,6 +68,8 @@ func (p *matchTextPreprocessor) PreProcessLog(log string) (string, error) {
 			}
 		}
 	}
+	// Reset the stream buffer so that each log produces a single JSON object
+	p.stream.SetBuffer(p.stream.Buffer()[:0])
 	writeFieldsJSON(p.stream, matches)
 	// Reuse buffer
 	p.matches = matches
@@ -0,0 +1,148 @@
+package processor
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/datamodels"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/enrichment"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/redaction"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/transforms"
+	"github.com/panther-labs/panther/pkg/encryption"
+	"github.com/panther-labs/panther/pkg/gatewayapi"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+const (
+	// How often we check if lookup tables have been updated
+	lookupTablesMaxAge = 5 * time.Minute
+	// How often we check if data models have been updated
+	dataModelsMaxAge = 5 * time.Minute
+	// How often we check if redaction rules have been updated
+	redactionMaxAge = 5 * time.Minute
+)
+
+// Extensions loads the lookup tables, data models and redaction rules that are applied to events.
+// All lambdas writing events to the data lake use them, so that events are the same regardless of how they were received.
+type Extensions struct {
+	LookupTables *enrichment.Loader
+	DataModels   *datamodels.Loader
+	Redaction    *redaction.Loader
+}
+
+// NewExtensions creates extensions that load lookup tables, data models and redaction rules using the common clients.
+// The loaded configuration is cached across invocations.
+func NewExtensions() *Extensions {
+	analysisAPI := gatewayapi.NewClient(common.LambdaClient, "panther-analysis-api")
+	return &Extensions{
+		LookupTables: &enrichment.Loader{
+			S3:         common.S3Client,
+			Bucket:     common.Config.ProcessedDataBucket,
+			ListTables: listLookupTables,
+			MaxAge:     lookupTablesMaxAge,
+		},
+		DataModels: &datamodels.Loader{
+			ListDataModels: func(_ context.Context) ([]datamodels.DataModel, error) {
+				return datamodels.ListEnabled(analysisAPI)
+			},
+			MaxAge: dataModelsMaxAge,
+			// Data model changes update the p_udm column of log tables
+			OnChange: destinations.InvalidateTableFormats,
+		},
+		Redaction: &redaction.Loader{
+			LoadConfig: func(ctx context.Context) (*redaction.Config, error) {
+				return redaction.ReadConfigS3(ctx, common.S3Client, common.Config.ProcessedDataBucket, redaction.ConfigS3Key)
+			},
+			// KMS ciphertext includes the key id
+			Decrypt: encryption.New(redaction.KeyAlias, common.Session).DecryptConfig,
+			MaxAge:  redactionMaxAge,
+			// Hash masks of custom log transforms use the redaction key
+			SetKey: transforms.SetHashKey,
+		},
+	}
+}
+
+// Load returns the current enricher and normalizer.
+// Events are processed without enrichment or data model fields if lookup tables or data models fail to load.
+func (e *Extensions) Load(ctx context.Context) (enricher pantherlog.Enricher, normalizer pantherlog.Normalizer) {
+	if e == nil {
+		return nil, nil
+	}
+	if e.LookupTables != nil {
+		var err error
+		if enricher, err = e.LookupTables.Enricher(ctx); err != nil {
+			lambdalogger.FromContext(ctx).Error("failed to load lookup tables", zap.Error(err))
+		}
+	}
+	if e.DataModels != nil {
+		var err error
+		if normalizer, err = e.DataModels.Normalizer(ctx); err != nil {
+			lambdalogger.FromContext(ctx).Error("failed to load data models", zap.Error(err))
+		}
+	}
+	return enricher, normalizer
+}
+
+// Redactor returns the current redactor.
+// Unlike lookup tables and data models, events must not be stored if the redaction rules fail to load.
+func (e *Extensions) Redactor(ctx context.Context) (pantherlog.Redactor, error) {
+	if e == nil || e.Redaction == nil {
+		return nil, nil
+	}
+	return e.Redaction.Redactor(ctx)
+}
+
+func listLookupTables(ctx context.Context) ([]enrichment.TableConfig, error) {
+	api := &logtypesapi.LogTypesAPILambdaClient{
+		LambdaName: logtypesapi.LambdaName,
+		LambdaAPI:  common.LambdaClient,
+		Validate:   validator.New().Struct,
+	}
+	reply, err := api.ListLookupTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if reply.Error != nil {
+		return nil, logtypesapi.NewAPIError(reply.Error.Code, reply.Error.Message)
+	}
+	tables := make([]enrichment.TableConfig, 0, len(reply.Records))
+	for _, record := range reply.Records {
+		if record.Disabled {
+			continue
+		}
+		tables = append(tables, enrichment.TableConfig{
+			Name:       record.Name,
+			Revision:   record.Revision,
+			S3Key:      record.S3Key,
+			Format:     record.Format,
+			KeyColumn:  record.KeyColumn,
+			Indicators: record.Indicators,
+		})
+	}
+	return tables, nil
+}
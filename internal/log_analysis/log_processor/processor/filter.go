@@ -0,0 +1,72 @@
+package processor
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
+)
+
+// sourceFilters builds and caches the event filters of log sources.
+type sourceFilters struct {
+	// loadSource loads sources not already in the cache, if nil only cached sources are used
+	loadSource func(id string) (*models.SourceIntegration, error)
+	filters    map[string]*eventfilter.Filter
+}
+
+// newSourceFilters creates the event filters for a single source
+func newSourceFilters(src *models.SourceIntegration) (*sourceFilters, error) {
+	filter, err := eventfilter.Build(src.EventFilters...)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid event filters for source %s", src.IntegrationID)
+	}
+	return &sourceFilters{
+		filters: map[string]*eventfilter.Filter{
+			src.IntegrationID: filter,
+		},
+	}, nil
+}
+
+// Filter returns the event filter of a source or nil if the source does not filter events
+func (s *sourceFilters) Filter(sourceID string) (*eventfilter.Filter, error) {
+	if filter, ok := s.filters[sourceID]; ok || s.loadSource == nil {
+		return filter, nil
+	}
+	if s.filters == nil {
+		s.filters = map[string]*eventfilter.Filter{}
+	}
+	// The filter is cached even if it fails to load, so we do not retry for every event
+	filter, err := s.buildFilter(sourceID)
+	s.filters[sourceID] = filter
+	return filter, err
+}
+
+func (s *sourceFilters) buildFilter(sourceID string) (*eventfilter.Filter, error) {
+	src, err := s.loadSource(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := eventfilter.Build(src.EventFilters...)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid event filters for source %s", sourceID)
+	}
+	return filter, nil
+}
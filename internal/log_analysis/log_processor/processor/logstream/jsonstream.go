@@ -0,0 +1,250 @@
+package logstream
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// JSONStreamConfig configures how log entries are extracted from a stream of JSON values.
+type JSONStreamConfig struct {
+	// Path is a dot separated path to the value holding the log entries in each JSON value (ie 'data.events').
+	// Array indexes are specified as numbers (ie 'results.0.events').
+	// If the value at path is an array, each element is a log entry, otherwise the value itself is a log entry.
+	// JSON values without a value at path (ie status messages) are skipped.
+	// If the path is empty each top-level JSON value (or each element of a top-level array) is a log entry.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// Validate checks that the configuration is valid
+func (c *JSONStreamConfig) Validate() error {
+	if c == nil {
+		return errors.New("nil JSON stream config")
+	}
+	for _, p := range c.path() {
+		if p == "" {
+			return errors.Errorf("invalid JSON path %q", c.Path)
+		}
+	}
+	return nil
+}
+
+func (c *JSONStreamConfig) path() []string {
+	if c.Path == "" {
+		return nil
+	}
+	return strings.Split(c.Path, ".")
+}
+
+// NewJSONStreamWithConfig creates a new JSON stream using a config
+func NewJSONStreamWithConfig(r io.Reader, size int, config *JSONStreamConfig) (*JSONStream, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return NewJSONStream(r, size, config.path()...), nil
+}
+
+// NewJSONStream creates a new stream of log entries from concatenated JSON values.
+// r is the underlying io.Reader
+// size is the read buffer size for the jsoniter.Iterator
+// path is a path to the value to extract entries from in each JSON value (empty means the JSON values themselves)
+func NewJSONStream(r io.Reader, size int, path ...string) *JSONStream {
+	if size <= 0 {
+		size = DefaultBufferSize
+	} else if size < MinBufferSize {
+		size = MinBufferSize
+	}
+	return &JSONStream{
+		iter: jsoniter.Parse(jsoniter.ConfigDefault, r, size),
+		seek: path,
+	}
+}
+
+// JSONStream is a log entry stream that iterates over a sequence of JSON values.
+// The values can be separated by whitespace or not at all and can span multiple lines (ie pretty-printed JSON).
+// If the value (or the value at the seek path) is an array, its elements are returned as separate entries.
+type JSONStream struct {
+	iter  *jsoniter.Iterator
+	seek  []string
+	err   error
+	entry []byte
+	// The containers entered while seeking the current value, used to skip the remaining of each JSON value
+	parents []jsoniter.ValueType
+	// Set while iterating the elements of an array
+	inArray bool
+	// The number of top-level values skipped because they have no value at the seek path
+	skipped int64
+}
+
+// Skipped returns the number of top-level JSON values without a value at the seek path (ie heartbeat messages).
+// These values are skipped, they are not errors.
+func (s *JSONStream) Skipped() int64 {
+	return s.skipped
+}
+
+// Err implements the Stream interface
+func (s *JSONStream) Err() error {
+	if errors.Is(s.err, io.EOF) {
+		return nil
+	}
+	return s.err
+}
+
+// Next implements the Stream interface
+func (s *JSONStream) Next() []byte {
+	for s.err == nil {
+		if s.inArray {
+			if s.iter.ReadArray() {
+				return s.readEntry()
+			}
+			if s.checkError() {
+				return nil
+			}
+			s.inArray = false
+			s.skipParents()
+			continue
+		}
+		switch s.iter.WhatIsNext() {
+		case jsoniter.InvalidValue:
+			// At the end of input the iterator reports io.EOF
+			s.err = errors.WithStack(s.iter.Error)
+			if s.err == nil {
+				s.err = errors.New("invalid JSON input")
+			}
+			return nil
+		case jsoniter.NilValue:
+			s.iter.Skip()
+			continue
+		}
+		if !s.seekPath() {
+			if s.err != nil {
+				return nil
+			}
+			s.skipped++
+			continue
+		}
+		if s.iter.WhatIsNext() == jsoniter.ArrayValue {
+			s.inArray = true
+			continue
+		}
+		entry := s.readEntry()
+		if entry != nil {
+			s.skipParents()
+		}
+		return entry
+	}
+	return nil
+}
+
+func (s *JSONStream) readEntry() []byte {
+	// Initialize the entry buffer, SkipAndAppendBytes requires a non-nil buffer
+	if s.entry == nil {
+		s.entry = make([]byte, MinBufferSize)
+	}
+	s.entry = s.iter.SkipAndAppendBytes(s.entry[:0])
+	if s.checkError() {
+		return nil
+	}
+	// Return the entry data. It is valid until the next call to Next
+	return s.entry
+}
+
+func (s *JSONStream) checkError() bool {
+	if err := s.iter.Error; err != nil {
+		s.err = errors.WithStack(err)
+		if errors.Is(err, io.EOF) {
+			// The input ended in the middle of a JSON value
+			s.err = errors.WithStack(io.ErrUnexpectedEOF)
+		}
+		return true
+	}
+	return false
+}
+
+// seekPath advances the iterator to the value at the seek path, recording the containers it enters.
+// If the value has nothing at the seek path, the rest of the value is skipped and it returns false.
+func (s *JSONStream) seekPath() bool {
+	s.parents = s.parents[:0]
+	iter := s.iter
+	for _, seek := range s.seek {
+		t := iter.WhatIsNext()
+		found := false
+		switch t {
+		case jsoniter.ObjectValue:
+			for key := iter.ReadObject(); key != "" && iter.Error == nil; key = iter.ReadObject() {
+				if key == seek {
+					found = true
+					break
+				}
+				iter.Skip()
+			}
+		case jsoniter.ArrayValue:
+			n, err := strconv.ParseInt(seek, 10, 64)
+			if err != nil || n < 0 {
+				iter.Skip()
+				break
+			}
+			for i := int64(0); iter.ReadArray(); i++ {
+				if i == n {
+					found = true
+					break
+				}
+				iter.Skip()
+			}
+		default:
+			iter.Skip()
+		}
+		if s.checkError() {
+			return false
+		}
+		if !found {
+			// The container of the current path element was consumed, skip the rest of its parents
+			s.skipParents()
+			return false
+		}
+		s.parents = append(s.parents, t)
+	}
+	return true
+}
+
+// skipParents skips the remaining values of the containers entered by seekPath
+func (s *JSONStream) skipParents() {
+	iter := s.iter
+	for i := len(s.parents) - 1; i >= 0; i-- {
+		switch s.parents[i] {
+		case jsoniter.ObjectValue:
+			for key := iter.ReadObject(); key != "" && iter.Error == nil; key = iter.ReadObject() {
+				iter.Skip()
+			}
+		case jsoniter.ArrayValue:
+			for iter.ReadArray() {
+				iter.Skip()
+			}
+		}
+		if s.checkError() {
+			return
+		}
+	}
+	s.parents = s.parents[:0]
+}
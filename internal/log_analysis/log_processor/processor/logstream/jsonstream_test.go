@@ -0,0 +1,127 @@
+package logstream
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONStream(t *testing.T) {
+	type testCase struct {
+		Name    string
+		Input   string
+		Path    string
+		Expect  []string
+		WantErr string
+		Skipped int64
+	}
+	for _, tc := range []testCase{
+		{
+			Name:   "Concatenated values",
+			Input:  `{"a":1}{"b":2}` + "\n" + `{"c":3}`,
+			Expect: []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+		},
+		{
+			Name: "Pretty printed values",
+			Input: `{
+  "a": 1
+}
+{
+  "b": 2
+}
+`,
+			Expect: []string{"{\n  \"a\": 1\n}", "{\n  \"b\": 2\n}"},
+		},
+		{
+			Name:   "Top-level arrays",
+			Input:  `[{"a":1},{"b":2}] [] null [{"c":3}]`,
+			Expect: []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+		},
+		{
+			Name:   "Arrays at path",
+			Input:  `{"meta":{},"data":{"events":[{"a":1},{"b":2}],"next":"foo"}}{"data":{"events":[{"c":3}]}}`,
+			Path:   "data.events",
+			Expect: []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+		},
+		{
+			Name:   "Value at path",
+			Input:  `{"data":{"event":{"a":1},"next":"foo"}} {"data":{"event":{"b":2}}}`,
+			Path:   "data.event",
+			Expect: []string{`{"a":1}`, `{"b":2}`},
+		},
+		{
+			Name:   "Array index in path",
+			Input:  `{"results":[{"events":[]},{"events":[{"a":1}]}]}`,
+			Path:   "results.1.events",
+			Expect: []string{`{"a":1}`},
+		},
+		{
+			Name:    "Missing key",
+			Input:   `{"data":{"events":[{"a":1}]}} {"data":{}} {"status":"ok"} {"data":"foo","next":1} {"data":{"events":[{"b":2}]}}`,
+			Path:    "data.events",
+			Expect:  []string{`{"a":1}`, `{"b":2}`},
+			Skipped: 3,
+		},
+		{
+			Name:    "Missing array index",
+			Input:   `{"results":[]} {"results":{"1":{}}} {"results":[{},{"events":[{"a":1}]}]}`,
+			Path:    "results.1.events",
+			Expect:  []string{`{"a":1}`},
+			Skipped: 2,
+		},
+		{
+			Name:    "Unexpected EOF",
+			Input:   `{"a":1} {"b":`,
+			Expect:  []string{`{"a":1}`},
+			WantErr: `Skip: do not know how to skip`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			assert := require.New(t)
+			s, err := NewJSONStreamWithConfig(strings.NewReader(tc.Input), 512, &JSONStreamConfig{
+				Path: tc.Path,
+			})
+			assert.NoError(err)
+			var entries []string
+			for entry := s.Next(); entry != nil; entry = s.Next() {
+				entries = append(entries, string(entry))
+			}
+			assert.Equal(tc.Expect, entries)
+			assert.Equal(tc.Skipped, s.Skipped())
+			if tc.WantErr != "" {
+				assert.Error(s.Err())
+				assert.Contains(s.Err().Error(), tc.WantErr)
+				return
+			}
+			assert.NoError(s.Err())
+		})
+	}
+}
+
+func TestJSONStreamConfig_Validate(t *testing.T) {
+	assert := require.New(t)
+	assert.NoError((&JSONStreamConfig{}).Validate())
+	assert.NoError((&JSONStreamConfig{Path: "data.events"}).Validate())
+	assert.Error((&JSONStreamConfig{Path: "data..events"}).Validate())
+	assert.Error((*JSONStreamConfig)(nil).Validate())
+}
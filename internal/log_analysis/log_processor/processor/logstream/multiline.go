@@ -0,0 +1,265 @@
+package logstream
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultMultiLineMaxLines is the default maximum number of lines in a multi-line entry
+	DefaultMultiLineMaxLines = 500
+	// DefaultMultiLineMaxBytes is the default maximum size of a multi-line entry
+	DefaultMultiLineMaxBytes = 1024 * 1024
+)
+
+// MultiLineConfig configures how lines are assembled into multi-line log entries.
+// At least one of StartPattern, ContinuePattern must be set.
+// If both are set, lines matching StartPattern start a new entry and lines matching ContinuePattern are appended
+// to the current entry. Lines that match neither start a new entry.
+type MultiLineConfig struct {
+	// StartPattern is a regular expression matching the first line of an entry.
+	// If it is the only pattern set, all lines not matching it are appended to the current entry.
+	StartPattern string `json:"startPattern,omitempty" yaml:"startPattern,omitempty"`
+	// ContinuePattern is a regular expression matching lines that continue the current entry.
+	// If it is the only pattern set, all lines not matching it start a new entry.
+	ContinuePattern string `json:"continuePattern,omitempty" yaml:"continuePattern,omitempty"`
+	// MaxLines is the maximum number of lines in an entry (defaults to DefaultMultiLineMaxLines)
+	MaxLines int `json:"maxLines,omitempty" yaml:"maxLines,omitempty"`
+	// MaxBytes is the maximum size of an entry in bytes (defaults to DefaultMultiLineMaxBytes)
+	MaxBytes int `json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+	// FlushTimeout is the time to wait for the next line before emitting a pending entry (ie '5s').
+	// It is only useful for streams where data arrives slowly and is disabled by default.
+	// For streams of files it should not be set since a slow read would split an entry in two.
+	FlushTimeout string `json:"flushTimeout,omitempty" yaml:"flushTimeout,omitempty"`
+}
+
+// Validate checks that the configuration is valid
+func (c *MultiLineConfig) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+type multiLineMatcher struct {
+	start        *regexp.Regexp
+	cont         *regexp.Regexp
+	maxLines     int
+	maxBytes     int
+	flushTimeout time.Duration
+}
+
+func (c *MultiLineConfig) compile() (*multiLineMatcher, error) {
+	if c == nil {
+		return nil, errors.New("nil multi-line config")
+	}
+	if c.StartPattern == "" && c.ContinuePattern == "" {
+		return nil, errors.New("multi-line config requires a start or a continue pattern")
+	}
+	m := multiLineMatcher{
+		maxLines: c.MaxLines,
+		maxBytes: c.MaxBytes,
+	}
+	if m.maxLines <= 0 {
+		m.maxLines = DefaultMultiLineMaxLines
+	}
+	if m.maxBytes <= 0 {
+		m.maxBytes = DefaultMultiLineMaxBytes
+	}
+	if c.StartPattern != "" {
+		start, err := regexp.Compile(c.StartPattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid multi-line start pattern")
+		}
+		m.start = start
+	}
+	if c.ContinuePattern != "" {
+		cont, err := regexp.Compile(c.ContinuePattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid multi-line continue pattern")
+		}
+		m.cont = cont
+	}
+	if c.FlushTimeout != "" {
+		timeout, err := time.ParseDuration(c.FlushTimeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid multi-line flush timeout")
+		}
+		m.flushTimeout = timeout
+	}
+	return &m, nil
+}
+
+// startsEntry checks if a line starts a new entry
+func (m *multiLineMatcher) startsEntry(line []byte) bool {
+	if m.start != nil && m.start.Match(line) {
+		return true
+	}
+	if m.cont != nil && m.cont.Match(line) {
+		return false
+	}
+	// If only a start pattern is set, all other lines are continuation lines
+	return m.start == nil || m.cont != nil
+}
+
+// MultiLineConfigurer is implemented by components that expect their input to be assembled into multi-line entries.
+type MultiLineConfigurer interface {
+	MultiLineConfig() *MultiLineConfig
+}
+
+// MultiLineStream is a log entry stream that assembles lines of an underlying stream into multi-line entries.
+// This is needed for logs like stack traces or pretty-printed JSON where a single entry spans multiple lines.
+type MultiLineStream struct {
+	lines   Stream
+	matcher *multiLineMatcher
+	// entry is the last entry returned by Next
+	entry []byte
+	// pending is the entry currently being assembled
+	pending      []byte
+	pendingLines int
+	done         bool
+	// linesCh is used to read lines when a flush timeout is set
+	linesCh chan []byte
+	// stop is closed to stop reading lines when the stream is abandoned
+	stop chan struct{}
+}
+
+var _ io.Closer = (*MultiLineStream)(nil)
+
+// NewMultiLineStream creates a new stream that assembles multi-line entries from the lines of an underlying stream.
+func NewMultiLineStream(lines Stream, config *MultiLineConfig) (*MultiLineStream, error) {
+	matcher, err := config.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &MultiLineStream{
+		lines:   lines,
+		matcher: matcher,
+	}, nil
+}
+
+// Err implements the Stream interface
+func (s *MultiLineStream) Err() error {
+	return s.lines.Err()
+}
+
+// Next implements the Stream interface
+func (s *MultiLineStream) Next() []byte {
+	for !s.done {
+		line, timeout := s.nextLine()
+		if timeout {
+			if s.pendingLines > 0 {
+				return s.flush()
+			}
+			continue
+		}
+		if line == nil {
+			s.done = true
+			break
+		}
+		if s.pendingLines > 0 && s.shouldFlush(line) {
+			entry := s.flush()
+			s.appendLine(line)
+			return entry
+		}
+		s.appendLine(line)
+	}
+	if s.pendingLines > 0 {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *MultiLineStream) shouldFlush(line []byte) bool {
+	m := s.matcher
+	if m.startsEntry(line) {
+		return true
+	}
+	if s.pendingLines+1 > m.maxLines {
+		return true
+	}
+	// Account for the newline joining the lines
+	return len(s.pending)+1+len(line) > m.maxBytes
+}
+
+func (s *MultiLineStream) appendLine(line []byte) {
+	if s.pendingLines > 0 {
+		s.pending = append(s.pending, '\n')
+	}
+	s.pending = append(s.pending, line...)
+	s.pendingLines++
+}
+
+// flush returns the pending entry and resets the pending buffer.
+// The returned slice is valid until the next call to Next.
+func (s *MultiLineStream) flush() []byte {
+	s.entry, s.pending = s.pending, s.entry[:0]
+	s.pendingLines = 0
+	return s.entry
+}
+
+// nextLine reads the next line from the underlying stream.
+// If a flush timeout is set and no line is available in time, it reports a timeout.
+func (s *MultiLineStream) nextLine() (line []byte, timeout bool) {
+	if s.matcher.flushTimeout <= 0 {
+		return s.lines.Next(), false
+	}
+	if s.linesCh == nil {
+		s.linesCh = make(chan []byte, 1)
+		s.stop = make(chan struct{})
+		go s.readLines()
+	}
+	timer := time.NewTimer(s.matcher.flushTimeout)
+	defer timer.Stop()
+	select {
+	case line := <-s.linesCh:
+		return line, false
+	case <-timer.C:
+		return nil, true
+	}
+}
+
+// readLines copies lines from the underlying stream to linesCh.
+// The goroutine exits once the underlying stream is exhausted or the stream is closed.
+func (s *MultiLineStream) readLines() {
+	defer close(s.linesCh)
+	for line := s.lines.Next(); line != nil; line = s.lines.Next() {
+		// Lines are only valid until the next call to Next, so we need to copy them
+		select {
+		case s.linesCh <- append([]byte(nil), line...):
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops reading lines from the underlying stream.
+// It must be called if the stream is abandoned before it is exhausted, so that the goroutine reading lines
+// when a flush timeout is set exits. Pending lines are discarded and the underlying stream is not closed.
+func (s *MultiLineStream) Close() error {
+	if s.stop != nil && !s.done {
+		close(s.stop)
+	}
+	s.done = true
+	s.pending, s.pendingLines = s.pending[:0], 0
+	return nil
+}
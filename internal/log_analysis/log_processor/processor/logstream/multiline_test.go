@@ -0,0 +1,180 @@
+package logstream
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiLineStream(t *testing.T) {
+	type testCase struct {
+		Name   string
+		Config MultiLineConfig
+		Input  string
+		Expect []string
+	}
+	for _, tc := range []testCase{
+		{
+			Name: "Java stack trace",
+			Config: MultiLineConfig{
+				StartPattern: `^\d{4}-\d{2}-\d{2}`,
+			},
+			Input: `2020-01-01 00:00:00 ERROR failed
+java.lang.NullPointerException
+	at com.example.Foo.bar(Foo.java:42)
+	at com.example.Foo.main(Foo.java:10)
+2020-01-01 00:00:01 INFO ok`,
+			Expect: []string{
+				"2020-01-01 00:00:00 ERROR failed\njava.lang.NullPointerException\n\tat com.example.Foo.bar(Foo.java:42)\n\tat com.example.Foo.main(Foo.java:10)",
+				"2020-01-01 00:00:01 INFO ok",
+			},
+		},
+		{
+			Name: "Continue pattern",
+			Config: MultiLineConfig{
+				ContinuePattern: `^\s`,
+			},
+			Input: "foo\n bar\nbaz\n qux\n quux",
+			Expect: []string{
+				"foo\n bar",
+				"baz\n qux\n quux",
+			},
+		},
+		{
+			Name: "Start and continue patterns",
+			Config: MultiLineConfig{
+				StartPattern:    `^\{`,
+				ContinuePattern: `^[\s}]`,
+			},
+			Input: "{\n  \"foo\": 1\n}\nbar\n{\n  \"foo\": 2\n}",
+			Expect: []string{
+				"{\n  \"foo\": 1\n}",
+				"bar",
+				"{\n  \"foo\": 2\n}",
+			},
+		},
+		{
+			Name: "Max lines",
+			Config: MultiLineConfig{
+				StartPattern: `^START`,
+				MaxLines:     2,
+			},
+			Input: "START\na\nb\nc",
+			Expect: []string{
+				"START\na",
+				"b\nc",
+			},
+		},
+		{
+			Name: "Max bytes",
+			Config: MultiLineConfig{
+				StartPattern: `^START`,
+				MaxBytes:     10,
+			},
+			Input: "START\nabc\ndef",
+			Expect: []string{
+				"START\nabc",
+				"def",
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			lines := NewLineStream(strings.NewReader(tc.Input), MinBufferSize)
+			s, err := NewMultiLineStream(lines, &tc.Config)
+			require.NoError(t, err)
+			var result []string
+			for entry := s.Next(); entry != nil; entry = s.Next() {
+				result = append(result, string(entry))
+			}
+			require.NoError(t, s.Err())
+			require.Equal(t, tc.Expect, result)
+		})
+	}
+}
+
+func TestMultiLineStreamFlushTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	lines := NewLineStream(r, MinBufferSize)
+	s, err := NewMultiLineStream(lines, &MultiLineConfig{
+		StartPattern: `^START`,
+		FlushTimeout: "10ms",
+	})
+	require.NoError(t, err)
+	go func() {
+		_, _ = w.Write([]byte("START\nfoo\n"))
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte("bar\n"))
+		_ = w.Close()
+	}()
+	require.Equal(t, "START\nfoo", string(s.Next()))
+	require.Equal(t, "bar", string(s.Next()))
+	require.Nil(t, s.Next())
+	require.NoError(t, s.Err())
+}
+
+func TestMultiLineStreamClose(t *testing.T) {
+	r, w := io.Pipe()
+	lines := NewLineStream(r, MinBufferSize)
+	s, err := NewMultiLineStream(lines, &MultiLineConfig{
+		StartPattern: `^START`,
+		FlushTimeout: "10ms",
+	})
+	require.NoError(t, err)
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		for i := 0; i < 10; i++ {
+			if _, err := w.Write([]byte("START\n")); err != nil {
+				return
+			}
+		}
+		_ = w.Close()
+	}()
+	require.Equal(t, "START", string(s.Next()))
+	// The stream is abandoned, the goroutine reading lines must exit once the reader is closed
+	require.NoError(t, s.Close())
+	require.NoError(t, r.Close())
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for range s.linesCh {
+		}
+	}()
+	for _, done := range []chan struct{}{writeDone, readerDone} {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the stream did not stop reading lines after it was closed")
+		}
+	}
+	require.Nil(t, s.Next())
+}
+
+func TestMultiLineConfig_Validate(t *testing.T) {
+	require.Error(t, (&MultiLineConfig{}).Validate())
+	require.Error(t, (&MultiLineConfig{StartPattern: `(`}).Validate())
+	require.Error(t, (&MultiLineConfig{StartPattern: `^a`, FlushTimeout: "foo"}).Validate())
+	require.NoError(t, (&MultiLineConfig{ContinuePattern: `^\s`}).Validate())
+}
@@ -20,7 +20,9 @@ package processor
 
 import (
 	"context"
+	"io"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
@@ -29,6 +31,7 @@ import (
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
 	logmetrics "github.com/panther-labs/panther/internal/log_analysis/log_processor/metrics"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
@@ -94,7 +97,7 @@ func Process(
 	// Write results and return error(s) via the channel
 	go func() {
 		defer wg.Done()
-		destination.SendEvents(resultsChannel, errorChannel) // runs until results channel is closed
+		destination.SendEvents(ctx, resultsChannel, errorChannel) // runs until results channel is closed
 	}()
 
 	wg.Add(1)
@@ -152,11 +155,60 @@ type Processor struct {
 	input      *common.DataStream
 	classifier classification.ClassifierAPI
 	operation  *oplog.Operation
+	// deadLetters captures log lines that failed to classify, if nil the lines are dropped
+	deadLetters deadletter.Sink
+	// enricher joins lookup table rows to events, if nil events are not enriched
+	enricher pantherlog.Enricher
+	// normalizer maps event fields to data model fields, if nil events are not normalized
+	normalizer pantherlog.Normalizer
+	// redactor removes personal data from events before they are stored, if nil events are not redacted
+	redactor pantherlog.Redactor
+	// filters decides which events of each source are stored
+	filters *sourceFilters
+	// filteredEvents counts the events dropped by event filters for each log type
+	filteredEvents map[string]uint64
 }
 
 type Factory func(r *common.DataStream) (*Processor, error)
 
+// WithEnricher returns a processor factory that enriches events with lookup table rows
+func (f Factory) WithEnricher(enricher pantherlog.Enricher) Factory {
+	if enricher == nil {
+		return f
+	}
+	return func(input *common.DataStream) (*Processor, error) {
+		p, err := f(input)
+		if err != nil {
+			return nil, err
+		}
+		p.enricher = enricher
+		return p, nil
+	}
+}
+
+// WithNormalizer returns a processor factory that adds data model fields to events
+func (f Factory) WithNormalizer(normalizer pantherlog.Normalizer) Factory {
+	if normalizer == nil {
+		return f
+	}
+	return func(input *common.DataStream) (*Processor, error) {
+		p, err := f(input)
+		if err != nil {
+			return nil, err
+		}
+		p.normalizer = normalizer
+		return p, nil
+	}
+}
+
+// NewFactory returns a processor factory that does not redact events, it is only meant for local tools and tests
 func NewFactory(resolver pantherlog.ParserResolver) Factory {
+	return NewFactoryWithDeadLetters(resolver, nil, nil)
+}
+
+// NewFactoryWithDeadLetters returns a processor factory that captures log lines that failed to classify.
+// All paths that store events must pass the current redactor, if it is nil events are not redacted.
+func NewFactoryWithDeadLetters(resolver pantherlog.ParserResolver, deadLetters deadletter.Sink, redactor pantherlog.Redactor) Factory {
 	return func(input *common.DataStream) (*Processor, error) {
 		switch src := input.Source; src.IntegrationType {
 		case models.IntegrationTypeSqs:
@@ -167,31 +219,60 @@ func NewFactory(resolver pantherlog.ParserResolver) Factory {
 					Resolver:   resolver,
 					LoadSource: sources.LoadSource,
 				},
+				deadLetters: deadLetters,
+				redactor:    redactor,
+				// Messages in SQS streams come from many sources
+				filters: &sourceFilters{
+					loadSource: sources.LoadSource,
+				},
 			}, nil
 		case models.IntegrationTypeAWS3:
 			var availableLogTypes []string
+			var hints classification.Hints
 			// S3 sources has multiple prefix<>logtypes mappings specified.
 			if m, matched := src.S3PrefixLogTypes.LongestPrefixMatch(input.S3ObjectKey); matched {
 				availableLogTypes = m.LogTypes
+				hints.LogTypes = m.HintedLogTypes(input.S3ObjectKey)
+				hints.Sticky = m.StickyLogType
+			}
+			c, err := sources.BuildClassifierWithHints(availableLogTypes, hints, src, resolver)
+			if err != nil {
+				return nil, err
 			}
-			c, err := sources.BuildClassifier(availableLogTypes, src, resolver)
+			if input.Stream, err = sources.WrapMultiLineStream(input.Stream, availableLogTypes, resolver); err != nil {
+				return nil, err
+			}
+			filters, err := newSourceFilters(src)
 			if err != nil {
 				return nil, err
 			}
 			return &Processor{
-				operation:  common.OpLogManager.Start(operationName),
-				input:      input,
-				classifier: c,
+				operation:   common.OpLogManager.Start(operationName),
+				input:       input,
+				classifier:  c,
+				deadLetters: deadLetters,
+				redactor:    redactor,
+				filters:     filters,
 			}, nil
-		case models.IntegrationTypeAWSScan:
+		case models.IntegrationTypeAWSScan, models.IntegrationTypeHTTPPush:
 			c, err := sources.BuildClassifier(src.RequiredLogTypes(), src, resolver)
 			if err != nil {
 				return nil, err
 			}
+			if input.Stream, err = sources.WrapMultiLineStream(input.Stream, src.RequiredLogTypes(), resolver); err != nil {
+				return nil, err
+			}
+			filters, err := newSourceFilters(src)
+			if err != nil {
+				return nil, err
+			}
 			return &Processor{
-				operation:  common.OpLogManager.Start(operationName),
-				input:      input,
-				classifier: c,
+				operation:   common.OpLogManager.Start(operationName),
+				input:       input,
+				classifier:  c,
+				deadLetters: deadLetters,
+				redactor:    redactor,
+				filters:     filters,
 			}, nil
 
 		default:
@@ -216,16 +297,34 @@ func (p *Processor) run(ctx context.Context, outputChan chan<- *parsers.Result)
 		)
 	}()
 	stream := p.input.Stream
+	// Streams reading ahead in a goroutine need to be stopped if they are abandoned
+	if c, ok := stream.(io.Closer); ok {
+		defer c.Close()
+	}
 	for {
 		line := stream.Next()
 		if line == nil {
 			break
 		}
 		p.processLogLine(ctx, string(line), outputChan)
+		if err = ctx.Err(); err != nil {
+			break
+		}
 	}
-	if err = stream.Err(); err != nil {
+	if err != nil {
+		err = errors.Wrap(err, "stopped reading log lines")
+	} else if err = stream.Err(); err != nil {
 		err = errors.Wrap(err, "failed to read log line")
 	}
+	// JSON streams skip the values without entries at their configured path
+	if s, ok := stream.(interface{ Skipped() int64 }); ok && s.Skipped() > 0 {
+		p.operation.LogWarn(errors.New("skipped JSON values without log entries"),
+			zap.Int64("skipped", s.Skipped()),
+			zap.String("sourceId", p.input.Source.IntegrationID),
+			zap.String("s3ObjectKey", p.input.S3ObjectKey),
+		)
+	}
+	p.flushDeadLetters()
 	return
 }
 
@@ -241,12 +340,25 @@ func (p *Processor) processLogLine(ctx context.Context, line string, outputChan
 			zap.String("s3Bucket", p.input.S3Bucket),
 			zap.String("s3ObjectKey", p.input.S3ObjectKey),
 		)
+		p.captureDeadLetter(line, err)
 		return
 	}
 	if result == nil {
 		return
 	}
 	for _, event := range result.Events {
+		if !p.keepEvent(event) {
+			continue
+		}
+		if p.enricher != nil {
+			event.Enricher = p.enricher
+		}
+		if p.normalizer != nil {
+			event.Normalizer = p.normalizer
+		}
+		if p.redactor != nil {
+			event.Redactor = p.redactor
+		}
 		select {
 		case outputChan <- event:
 		case <-ctx.Done():
@@ -255,11 +367,100 @@ func (p *Processor) processLogLine(ctx context.Context, line string, outputChan
 	}
 }
 
+// keepEvent applies the event filters of the source of an event before it is sent to the destination
+func (p *Processor) keepEvent(event *parsers.Result) bool {
+	if p.filters == nil {
+		return true
+	}
+	filter, err := p.filters.Filter(event.PantherSourceID)
+	if err != nil {
+		// Events are stored if we cannot load the filters of their source
+		p.operation.LogWarn(errors.Wrap(err, "failed to load event filters"),
+			zap.String("sourceId", event.PantherSourceID),
+		)
+		return true
+	}
+	if filter.Keep(event) {
+		return true
+	}
+	if p.filteredEvents == nil {
+		p.filteredEvents = map[string]uint64{}
+	}
+	p.filteredEvents[event.PantherLogType]++
+	return false
+}
+
+// captureDeadLetter writes a log line that failed to classify to the dead letters table
+func (p *Processor) captureDeadLetter(line string, err error) {
+	if p.deadLetters == nil {
+		return
+	}
+	entry := deadletter.Entry{
+		CaptureTime: time.Now().UTC(),
+		SourceID:    p.input.Source.IntegrationID,
+		SourceLabel: p.input.Source.IntegrationLabel,
+		S3Bucket:    p.input.S3Bucket,
+		S3ObjectKey: p.input.S3ObjectKey,
+		LineNumber:  p.classifier.Stats().LogLineCount,
+		Line:        p.deadLetterLine(line),
+	}
+	if e, ok := err.(*classification.ClassificationError); ok {
+		entry.ParserErrors = e.ParserErrors
+	}
+	if err := p.deadLetters.Write(&entry); err != nil {
+		p.operation.LogWarn(errors.Wrap(err, "failed to capture unclassified log line"),
+			zap.String("sourceId", p.input.Source.IntegrationID),
+			zap.String("s3ObjectKey", p.input.S3ObjectKey),
+		)
+	}
+}
+
+// deadLetterLine returns the log line to store in the dead letters table.
+// Lines that failed to classify cannot be redacted, so they are dropped if the log types of the source have redaction rules.
+// Entries of dropped lines are kept to report the failure, but they cannot be replayed.
+func (p *Processor) deadLetterLine(line string) string {
+	if p.redactor == nil {
+		return line
+	}
+	src := p.input.Source
+	if src.IntegrationType == models.IntegrationTypeSqs {
+		// Lines in SQS streams come from many sources
+		return ""
+	}
+	for _, logType := range src.RequiredLogTypes() {
+		if p.redactor.Redacts(logType) {
+			return ""
+		}
+	}
+	return line
+}
+
+// flushDeadLetters writes captured log lines to the dead letters table.
+// Failing to write them does not fail the stream since retrying it would produce duplicate events.
+func (p *Processor) flushDeadLetters() {
+	if p.deadLetters == nil {
+		return
+	}
+	if err := p.deadLetters.Flush(); err != nil {
+		p.operation.LogWarn(errors.Wrap(err, "failed to write unclassified log lines"),
+			zap.String("sourceId", p.input.Source.IntegrationID),
+			zap.String("s3ObjectKey", p.input.S3ObjectKey),
+		)
+	}
+}
+
 func (p *Processor) logStats(err error) {
 	p.operation.Stop()
-	p.operation.Log(err, zap.Any(statsKey, *p.classifier.Stats()))
+	classifierStats := *p.classifier.Stats()
+	for _, n := range p.filteredEvents {
+		classifierStats.FilteredEventCount += n
+	}
+	p.operation.Log(err, zap.Any(statsKey, classifierStats))
 	for _, stats := range p.classifier.ParserStats() {
 		logmetrics.BytesProcessed.With(metrics.LogTypeDimension, stats.LogType).Add(float64(stats.BytesProcessedCount))
 		logmetrics.EventsProcessed.With(metrics.LogTypeDimension, stats.LogType).Add(float64(stats.EventCount))
 	}
+	for logType, n := range p.filteredEvents {
+		logmetrics.EventsFiltered.With(metrics.LogTypeDimension, logType).Add(float64(n))
+	}
 }
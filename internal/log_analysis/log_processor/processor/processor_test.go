@@ -19,13 +19,18 @@ package processor
  */
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/sns"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -37,7 +42,9 @@ import (
 	"github.com/panther-labs/panther/api/lambda/source/models"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/eventfilter"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/logtypes"
 	logmetrics "github.com/panther-labs/panther/internal/log_analysis/log_processor/metrics"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
@@ -379,6 +386,158 @@ func TestProcessClassifyFailure(t *testing.T) {
 	assert.True(t, dataStream.Closer.(*dummyCloser).closed)
 }
 
+func TestProcessClassifyFailureDeadLetters(t *testing.T) {
+	metrics := setupMockMetrics()
+	metrics.bytesProcessed.On("With", mock.Anything).Return(metrics.bytesProcessed).Once()
+	metrics.bytesProcessed.On("Add", mock.Anything).Once()
+	metrics.eventsProcessed.On("With", mock.Anything).Return(metrics.eventsProcessed).Once()
+	metrics.eventsProcessed.On("Add", mock.Anything).Once()
+
+	uploader := &testutils.S3UploaderMock{}
+	snsClient := &testutils.SnsMock{}
+	deadLetters := &deadletter.Writer{
+		S3Uploader: uploader,
+		SNSClient:  snsClient,
+		Bucket:     "processed",
+		TopicARN:   "topic",
+	}
+	var uploaded []byte
+	uploader.On("Upload", mock.Anything, mock.Anything).Return(&s3manager.UploadOutput{}, nil).Run(func(args mock.Arguments) {
+		r, err := gzip.NewReader(args.Get(0).(*s3manager.UploadInput).Body)
+		require.NoError(t, err)
+		uploaded, err = ioutil.ReadAll(r)
+		require.NoError(t, err)
+	}).Once()
+	snsClient.On("Publish", mock.Anything).Return(&sns.PublishOutput{}, nil).Once()
+
+	destination := (&testDestination{}).standardMock()
+	dataStream := makeDataStream()
+	f := NewFactoryWithDeadLetters(testResolver, deadLetters, nil)
+	p, err := f(dataStream)
+	require.NoError(t, err)
+	mockClassifier := &testClassifier{}
+	p.classifier = mockClassifier
+
+	classifyErr := &classification.ClassificationError{
+		ParserErrors: map[string]string{testLogType: "fail parser"},
+	}
+	// first one fails
+	mockClassifier.On("Classify", mock.Anything).Return(&classification.ClassifierResult{}, classifyErr).Once()
+	mockClassifier.On("Classify", mock.Anything).Return(&classification.ClassifierResult{
+		Events:  []*parsers.Result{newTestLog()},
+		Matched: true,
+	}, nil)
+	mockClassifier.On("Stats", mock.Anything).Return(&classification.ClassifierStats{LogLineCount: 1})
+	mockClassifier.On("ParserStats", mock.Anything).Return(map[string]*classification.ParserStats{})
+
+	newProcessorFunc := func(*common.DataStream) (*Processor, error) { return p, nil }
+	streamChan := make(chan *common.DataStream, 1)
+	streamChan <- dataStream
+	close(streamChan)
+	err = Process(context.Background(), streamChan, destination, newProcessorFunc)
+	require.NoError(t, err)
+	require.Equal(t, testLogEvents-1, destination.nEvents)
+	uploader.AssertExpectations(t)
+	snsClient.AssertExpectations(t)
+
+	entry := map[string]interface{}{}
+	require.NoError(t, jsoniter.Unmarshal(uploaded, &entry))
+	delete(entry, "p_capture_time")
+	require.Equal(t, map[string]interface{}{
+		"p_source_id":    testSourceID,
+		"p_source_label": testSourceLabel,
+		"s3_bucket":      testBucket,
+		"s3_object_key":  testKey,
+		"line_number":    float64(1),
+		"line":           testLogLine,
+		"parser_errors":  map[string]interface{}{testLogType: "fail parser"},
+	}, entry)
+}
+
+func TestDeadLetterLine(t *testing.T) {
+	p := Processor{
+		input: &common.DataStream{Source: testSource},
+	}
+	require.Equal(t, testLogLine, p.deadLetterLine(testLogLine))
+	p.redactor = testRedactor{}
+	require.Equal(t, testLogLine, p.deadLetterLine(testLogLine))
+	// Lines of log types with redaction rules are dropped
+	p.redactor = testRedactor{testLogType: true}
+	require.Empty(t, p.deadLetterLine(testLogLine))
+	// Lines of SQS sources are always dropped
+	sqsSource := *testSource
+	sqsSource.IntegrationType = models.IntegrationTypeSqs
+	p.input = &common.DataStream{Source: &sqsSource}
+	p.redactor = testRedactor{}
+	require.Empty(t, p.deadLetterLine(testLogLine))
+}
+
+type testRedactor map[string]bool
+
+func (r testRedactor) Redact(_ string, event []byte, _ *pantherlog.ValueBuffer, _ pantherlog.Enrichment) []byte {
+	return event
+}
+
+func (r testRedactor) Redacts(logType string) bool {
+	return r[logType]
+}
+
+func TestProcessEventFilters(t *testing.T) {
+	metrics := setupMockMetrics()
+	metrics.bytesProcessed.On("With", mock.Anything).Return(metrics.bytesProcessed).Once()
+	metrics.bytesProcessed.On("Add", mock.Anything).Once()
+	metrics.eventsProcessed.On("With", mock.Anything).Return(metrics.eventsProcessed).Once()
+	metrics.eventsProcessed.On("Add", mock.Anything).Once()
+	metrics.eventsFiltered.On("With", []string{"LogType", testLogType}).Return(metrics.eventsFiltered).Once()
+	metrics.eventsFiltered.On("Add", float64(testLogLines-1)).Once()
+
+	destination := (&testDestination{}).standardMock()
+	dataStream := makeDataStream()
+	src := *testSource
+	src.EventFilters = []eventfilter.Rule{
+		{
+			Action: eventfilter.ActionDrop,
+			Match: []eventfilter.Condition{
+				{Field: "p_event_time", Op: "prefix", Value: "2020-01-01"},
+			},
+		},
+	}
+	dataStream.Source = &src
+	p, err := NewFactory(testResolver)(dataStream)
+	require.NoError(t, err)
+	mockClassifier := &testClassifier{}
+	p.classifier = mockClassifier
+
+	newEvent := func(tm time.Time) *parsers.Result {
+		event := newTestLog()
+		event.PantherSourceID = testSourceID
+		event.PantherEventTime = tm
+		event.Event.(*testLog).PantherEventTime = (*timestamp.RFC3339)(&tm)
+		return event
+	}
+	// Only the first event is kept
+	mockClassifier.On("Classify", mock.Anything).Return(&classification.ClassifierResult{
+		Events: []*parsers.Result{newEvent(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))},
+	}, nil).Once()
+	mockClassifier.On("Classify", mock.Anything).Return(&classification.ClassifierResult{
+		Events: []*parsers.Result{newEvent(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))},
+	}, nil)
+	mockClassifier.On("Stats", mock.Anything).Return(&classification.ClassifierStats{})
+	mockClassifier.On("ParserStats", mock.Anything).Return(map[string]*classification.ParserStats{
+		testLogType: {LogType: testLogType},
+	})
+
+	newProcessorFunc := func(*common.DataStream) (*Processor, error) { return p, nil }
+	streamChan := make(chan *common.DataStream, 1)
+	streamChan <- dataStream
+	close(streamChan)
+	err = Process(context.Background(), streamChan, destination, newProcessorFunc)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), destination.nEvents)
+	require.Equal(t, map[string]uint64{testLogType: testLogLines - 1}, p.filteredEvents)
+	metrics.eventsFiltered.AssertExpectations(t)
+}
+
 // deals with the error package inserting line numbers into errors
 func assertLogEqual(t *testing.T, expected, actual observer.LoggedEntry) {
 	for k, v := range expected.ContextMap() {
@@ -402,7 +561,7 @@ type testDestination struct {
 }
 
 // mocks override
-func (d *testDestination) SendEvents(parsedEventChannel chan *parsers.Result, errChan chan error) {
+func (d *testDestination) SendEvents(_ context.Context, parsedEventChannel chan *parsers.Result, errChan chan error) {
 	d.MethodCalled("SendEvents", parsedEventChannel, errChan) // execute mocks
 }
 
@@ -511,6 +670,7 @@ func mockLogger() *observer.ObservedLogs {
 type mockMetrics struct {
 	bytesProcessed  *testutils.CounterMock
 	eventsProcessed *testutils.CounterMock
+	eventsFiltered  *testutils.CounterMock
 }
 
 func setupMockMetrics() *mockMetrics {
@@ -520,8 +680,12 @@ func setupMockMetrics() *mockMetrics {
 	eventsProcessedMock := &testutils.CounterMock{}
 	logmetrics.EventsProcessed = eventsProcessedMock
 
+	eventsFilteredMock := &testutils.CounterMock{}
+	logmetrics.EventsFiltered = eventsFilteredMock
+
 	return &mockMetrics{
 		bytesProcessed:  bytesProcessedMock,
 		eventsProcessed: eventsProcessedMock,
+		eventsFiltered:  eventsFilteredMock,
 	}
 }
@@ -26,6 +26,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/pkg/errors"
@@ -33,6 +34,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/deadletter"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/destinations"
 	logmetrics "github.com/panther-labs/panther/internal/log_analysis/log_processor/metrics"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
@@ -57,9 +59,18 @@ func PollEvents(
 	ctx context.Context,
 	sqsClient sqsiface.SQSAPI,
 	resolver pantherlog.ParserResolver,
+	enricher pantherlog.Enricher,
+	normalizer pantherlog.Normalizer,
+	redactor pantherlog.Redactor,
 ) (sqsMessageCount int, err error) {
 
-	newProcessor := NewFactory(resolver)
+	deadLetters := &deadletter.Writer{
+		S3Uploader: s3manager.NewUploaderWithClient(common.S3Client),
+		SNSClient:  common.SnsClient,
+		Bucket:     common.Config.ProcessedDataBucket,
+		TopicARN:   common.Config.SnsTopicARN,
+	}
+	newProcessor := NewFactoryWithDeadLetters(resolver, deadLetters, redactor).WithEnricher(enricher).WithNormalizer(normalizer)
 	process := func(streams <-chan *common.DataStream, dest destinations.Destination) error {
 		return Process(ctx, streams, dest, newProcessor)
 	}
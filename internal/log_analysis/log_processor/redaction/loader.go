@@ -0,0 +1,185 @@
+package redaction
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/pkg/awsutils"
+)
+
+const (
+	// ConfigS3Key is the key of the redaction configuration in the processed data bucket
+	ConfigS3Key = "redaction/config.json"
+	// KeyAlias is the alias of the KMS key that encrypts the HMAC key
+	KeyAlias = "alias/panther-redaction"
+)
+
+// Config is the redaction configuration
+type Config struct {
+	// EncryptedKey is a JSON encoded Secret encrypted with KMS
+	EncryptedKey []byte `json:"encryptedKey"`
+	// LogTypes maps log types to their redaction rules
+	LogTypes map[string][]Rule `json:"logTypes"`
+}
+
+// Secret holds the key used to compute tokens
+type Secret struct {
+	HMACKey []byte `json:"hmacKey"`
+}
+
+const hmacKeySize = 32
+
+// EnsureKey generates a new HMAC key if the configuration has none and encrypts it.
+// The key is only generated once, a new key would change the tokens of all values.
+// It returns true if a key was generated.
+func (c *Config) EnsureKey(encrypt func(secret interface{}) ([]byte, error)) (bool, error) {
+	if c.EncryptedKey != nil {
+		return false, nil
+	}
+	secret := Secret{
+		HMACKey: make([]byte, hmacKeySize),
+	}
+	if _, err := rand.Read(secret.HMACKey); err != nil {
+		return false, errors.Wrap(err, "failed to generate redaction key")
+	}
+	encryptedKey, err := encrypt(&secret)
+	if err != nil {
+		return false, errors.WithMessage(err, "failed to encrypt redaction key")
+	}
+	c.EncryptedKey = encryptedKey
+	return true, nil
+}
+
+// Loader loads the redaction configuration and caches the resulting redactor.
+type Loader struct {
+	// LoadConfig loads the redaction configuration, it returns nil if there is no configuration
+	LoadConfig func(ctx context.Context) (*Config, error)
+	// Decrypt decrypts the secret of the configuration (see encryption.Key.DecryptConfig)
+	Decrypt func(ciphertext []byte, secret interface{}) error
+	// MaxAge is the duration to use the loaded configuration before checking for updates
+	MaxAge time.Duration
+	// SetKey is called with the HMAC key when the configuration is loaded (see transforms.SetHashKey)
+	SetKey func(key []byte)
+
+	mu       sync.Mutex
+	redactor *Redactor
+	loadedAt time.Time
+}
+
+// Redactor returns a redactor for the current configuration.
+// It returns nil if there are no redaction rules.
+//
+// Unlike other enrichments, events should not be stored if redaction fails.
+// If the configuration cannot be loaded it returns the last loaded redactor along with the error,
+// if the configuration has invalid rules it returns an error.
+func (l *Loader) Redactor(ctx context.Context) (pantherlog.Redactor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.loadedAt.IsZero() && time.Since(l.loadedAt) < l.MaxAge {
+		return l.currentRedactor(), nil
+	}
+	config, err := l.LoadConfig(ctx)
+	if err != nil {
+		return l.currentRedactor(), errors.WithMessage(err, "failed to load redaction configuration")
+	}
+	if config == nil || config.EncryptedKey == nil {
+		l.redactor = nil
+		l.loadedAt = time.Now()
+		return nil, nil
+	}
+	secret := Secret{}
+	if err := l.Decrypt(config.EncryptedKey, &secret); err != nil {
+		return l.currentRedactor(), errors.WithMessage(err, "failed to decrypt redaction key")
+	}
+	// The key is used by hash masks of log transforms even if there are no redaction rules
+	if l.SetKey != nil {
+		l.SetKey(secret.HMACKey)
+	}
+	if len(config.LogTypes) == 0 {
+		l.redactor = nil
+		l.loadedAt = time.Now()
+		return nil, nil
+	}
+	redactor, err := New(secret.HMACKey, config.LogTypes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid redaction configuration")
+	}
+	l.redactor = redactor
+	l.loadedAt = time.Now()
+	return l.currentRedactor(), nil
+}
+
+func (l *Loader) currentRedactor() pantherlog.Redactor {
+	if l.redactor == nil {
+		return nil
+	}
+	return l.redactor
+}
+
+// ReadConfigS3 reads the redaction configuration from an S3 object.
+// It returns nil if the object does not exist.
+func ReadConfigS3(ctx context.Context, s3API s3iface.S3API, bucket, key string) (*Config, error) {
+	reply, err := s3API.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if awsutils.IsAnyError(err, s3.ErrCodeNoSuchKey) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer reply.Body.Close()
+	data, err := ioutil.ReadAll(reply.Body)
+	if err != nil {
+		return nil, err
+	}
+	config := Config{}
+	if err := jsoniter.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "invalid redaction configuration at s3://%s/%s", bucket, key)
+	}
+	return &config, nil
+}
+
+// WriteConfigS3 writes the redaction configuration to an S3 object
+func WriteConfigS3(ctx context.Context, s3API s3iface.S3API, bucket, key string, config *Config) error {
+	body, err := jsoniter.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode redaction configuration")
+	}
+	_, err = s3API.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	})
+	return err
+}
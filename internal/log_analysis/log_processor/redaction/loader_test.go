@@ -0,0 +1,167 @@
+package redaction
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/pkg/testutils"
+)
+
+func TestLoader(t *testing.T) {
+	config := &Config{
+		EncryptedKey: []byte("encrypted"),
+		LogTypes: map[string][]Rule{
+			"Foo.Bar": {{Field: "$.user", Action: ActionHash}},
+		},
+	}
+	loader := Loader{
+		LoadConfig: func(_ context.Context) (*Config, error) {
+			return config, nil
+		},
+		Decrypt: func(ciphertext []byte, secret interface{}) error {
+			if string(ciphertext) != "encrypted" {
+				return errors.New("invalid ciphertext")
+			}
+			secret.(*Secret).HMACKey = testKey
+			return nil
+		},
+		MaxAge: -1,
+	}
+	var key []byte
+	loader.SetKey = func(k []byte) {
+		key = k
+	}
+	ctx := context.Background()
+	assert := require.New(t)
+	event := []byte(`{"user":"alice"}`)
+	expect := `{"user":"` + Token(testKey, "alice") + `"}`
+
+	redactor, err := loader.Redactor(ctx)
+	assert.NoError(err)
+	assert.JSONEq(expect, string(redactor.Redact("Foo.Bar", event, nil, nil)))
+	assert.Equal(testKey, key)
+
+	// The key is set even without rules
+	key = nil
+	config.LogTypes, config.EncryptedKey = nil, []byte("encrypted")
+	redactor, err = loader.Redactor(ctx)
+	assert.NoError(err)
+	assert.Nil(redactor)
+	assert.Equal(testKey, key)
+	config.LogTypes = map[string][]Rule{
+		"Foo.Bar": {{Field: "$.user", Action: ActionHash}},
+	}
+
+	// Invalid rules fail so that events are not stored unredacted
+	config.LogTypes["Foo.Bar"][0].Action = "encrypt"
+	redactor, err = loader.Redactor(ctx)
+	assert.Error(err)
+	assert.Nil(redactor)
+	config.LogTypes["Foo.Bar"][0].Action = ActionHash
+
+	// The last redactor is kept if the configuration cannot be loaded
+	loader.Redactor(ctx) // nolint:errcheck
+	config.EncryptedKey = []byte("invalid")
+	redactor, err = loader.Redactor(ctx)
+	assert.Error(err)
+	assert.JSONEq(expect, string(redactor.Redact("Foo.Bar", event, nil, nil)))
+
+	// Without any rules there is nothing to redact
+	config = nil
+	redactor, err = loader.Redactor(ctx)
+	assert.NoError(err)
+	assert.Nil(redactor)
+
+	// Cached configuration is used until it expires
+	loader.MaxAge = time.Hour
+	loader.LoadConfig = func(_ context.Context) (*Config, error) {
+		return nil, errors.New("configuration should not be loaded")
+	}
+	redactor, err = loader.Redactor(ctx)
+	assert.NoError(err)
+	assert.Nil(redactor)
+}
+
+func TestReadConfigS3(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+	s3Mock := &testutils.S3Mock{}
+	input := &s3.GetObjectInput{
+		Bucket: aws.String("bucket"),
+		Key:    aws.String(ConfigS3Key),
+	}
+	expect := Config{
+		EncryptedKey: []byte("encrypted"),
+		LogTypes: map[string][]Rule{
+			"Foo.Bar": {{Indicator: "p_any_emails", Action: ActionMask}},
+		},
+	}
+	data, err := jsoniter.MarshalToString(&expect)
+	assert.NoError(err)
+	s3Mock.On("GetObjectWithContext", ctx, input, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: ioutil.NopCloser(strings.NewReader(data)),
+	}, nil).Once()
+	config, err := ReadConfigS3(ctx, s3Mock, "bucket", ConfigS3Key)
+	assert.NoError(err)
+	assert.Equal(&expect, config)
+
+	// Missing configuration is not an error
+	s3Mock.On("GetObjectWithContext", ctx, input, mock.Anything).Return(
+		(*s3.GetObjectOutput)(nil), awserr.New(s3.ErrCodeNoSuchKey, "not found", nil)).Once()
+	config, err = ReadConfigS3(ctx, s3Mock, "bucket", ConfigS3Key)
+	assert.NoError(err)
+	assert.Nil(config)
+	s3Mock.AssertExpectations(t)
+}
+
+func TestConfig_EnsureKey(t *testing.T) {
+	assert := require.New(t)
+	var secret *Secret
+	encrypt := func(s interface{}) ([]byte, error) {
+		secret = s.(*Secret)
+		return []byte("encrypted"), nil
+	}
+	config := Config{}
+	generated, err := config.EnsureKey(encrypt)
+	assert.NoError(err)
+	assert.True(generated)
+	assert.Equal([]byte("encrypted"), config.EncryptedKey)
+	assert.Len(secret.HMACKey, hmacKeySize)
+
+	// Existing keys are kept
+	generated, err = config.EnsureKey(func(_ interface{}) ([]byte, error) {
+		return nil, errors.New("should not be called")
+	})
+	assert.NoError(err)
+	assert.False(generated)
+	assert.Equal([]byte("encrypted"), config.EncryptedKey)
+}
@@ -0,0 +1,438 @@
+package redaction
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package redaction removes or tokenizes personal data in events before they are stored in the data lake.
+//
+// Redaction rules are set per log type and select string values either by field path or by indicator field.
+// Selected values are dropped, replaced by a keyed HMAC token or masked.
+// Tokens are deterministic for a key, so rules and queries can match tokenized values consistently across log types.
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/datamodels"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+)
+
+// Action is the redaction applied to a value
+type Action string
+
+const (
+	// ActionDrop removes the value from the event
+	ActionDrop Action = "drop"
+	// ActionHash replaces the value with a keyed HMAC token
+	ActionHash Action = "hash"
+	// ActionMask replaces all letters and digits of the value with '*', keeping the domain of email addresses
+	ActionMask Action = "mask"
+
+	// TokenPrefix is the prefix of all tokens
+	TokenPrefix = "tok_"
+	// Number of HMAC bytes in a token
+	tokenSize = 16
+)
+
+// Rule selects the values to redact in events of a log type
+type Rule struct {
+	// Field is the path of an event field (ie `$.actor.alternateId`).
+	// Paths apply to all elements of arrays and to all string values nested in objects.
+	// Copies of the values in other fields are only redacted if they are also indicator values.
+	Field string `json:"field,omitempty"`
+	// Indicator is the name of an indicator field (ie `p_any_emails`).
+	// The indicator values are redacted wherever they appear in string values of the event,
+	// including within longer strings (ie an email address in a message).
+	Indicator string `json:"indicator,omitempty"`
+	// Action is the redaction applied to the selected values
+	Action Action `json:"action"`
+}
+
+// Token returns the HMAC token of a value
+func Token(key []byte, value string) string {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(value))
+	return TokenPrefix + hex.EncodeToString(h.Sum(nil)[:tokenSize])
+}
+
+// Mask replaces all letters and digits of a value with '*'.
+// The domain of email addresses is kept so that masked values keep their format and some of their use for analysis.
+func Mask(value string) string {
+	if at := strings.LastIndexByte(value, '@'); at > 0 {
+		return mask(value[:at]) + value[at:]
+	}
+	return mask(value)
+}
+
+func mask(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return '*'
+		}
+		return r
+	}, value)
+}
+
+// Redactor applies the redaction rules of log types.
+type Redactor struct {
+	key      []byte
+	logTypes map[string]*logTypeRules
+}
+
+var _ pantherlog.Redactor = (*Redactor)(nil)
+
+type logTypeRules struct {
+	fields     *fieldNode
+	indicators map[pantherlog.FieldID]Action
+}
+
+// fieldNode is a node in the tree of field paths with redaction rules
+type fieldNode struct {
+	action Action
+	fields map[string]*fieldNode
+}
+
+// New builds a redactor for the rules of log types.
+//
+// If some rules are invalid, it returns a redactor for the rest of the rules along with the error.
+func New(key []byte, logTypes map[string][]Rule) (*Redactor, error) {
+	if len(key) == 0 {
+		return nil, errors.New("empty redaction key")
+	}
+	r := Redactor{
+		key:      key,
+		logTypes: make(map[string]*logTypeRules, len(logTypes)),
+	}
+	var err error
+	for logType, rules := range logTypes {
+		lt := logTypeRules{}
+		for _, rule := range rules {
+			if ruleErr := lt.add(rule); ruleErr != nil {
+				err = multierr.Append(err, errors.WithMessagef(ruleErr, "log type %q", logType))
+			}
+		}
+		if lt.fields != nil || lt.indicators != nil {
+			r.logTypes[logType] = &lt
+		}
+	}
+	return &r, err
+}
+
+func (lt *logTypeRules) add(rule Rule) error {
+	switch rule.Action {
+	case ActionDrop, ActionHash, ActionMask:
+	default:
+		return errors.Errorf("invalid redaction action %q", rule.Action)
+	}
+	switch {
+	case rule.Field != "" && rule.Indicator != "":
+		return errors.New("a redaction rule cannot set both a field and an indicator")
+	case rule.Indicator != "":
+		id, ok := pantherlog.IndicatorFieldByNameJSON(rule.Indicator)
+		if !ok {
+			return errors.Errorf("unknown indicator field %q", rule.Indicator)
+		}
+		if lt.indicators == nil {
+			lt.indicators = map[pantherlog.FieldID]Action{}
+		}
+		lt.indicators[id] = rule.Action
+		return nil
+	case rule.Field != "":
+		path, err := datamodels.ParsePath(rule.Field)
+		if err != nil {
+			return err
+		}
+		for _, p := range path {
+			if _, ok := p.(string); !ok {
+				return errors.Errorf("invalid field path %q: array indexes are not supported", rule.Field)
+			}
+		}
+		if lt.fields == nil {
+			lt.fields = &fieldNode{}
+		}
+		node := lt.fields
+		for _, p := range path {
+			name := p.(string)
+			child := node.fields[name]
+			if child == nil {
+				if node.fields == nil {
+					node.fields = map[string]*fieldNode{}
+				}
+				child = &fieldNode{}
+				node.fields[name] = child
+			}
+			node = child
+		}
+		node.action = rule.Action
+		return nil
+	default:
+		return errors.New("a redaction rule must set a field or an indicator")
+	}
+}
+
+// Redacts implements pantherlog.Redactor interface
+func (r *Redactor) Redacts(logType string) bool {
+	return r.logTypes[logType] != nil
+}
+
+// Redact implements pantherlog.Redactor interface
+func (r *Redactor) Redact(logType string, event []byte, values *pantherlog.ValueBuffer, enrichment pantherlog.Enrichment) []byte {
+	lt := r.logTypes[logType]
+	if lt == nil {
+		return event
+	}
+	w := eventRedactor{
+		key:        r.key,
+		indicators: map[string]Action{},
+		redacted:   map[string]Action{},
+	}
+	// String values equal to the values of redacted indicators are redacted everywhere in the event
+	if values != nil {
+		for id, action := range lt.indicators {
+			for _, value := range values.Get(id) {
+				w.indicators[value] = action
+				w.redacted[value] = action
+			}
+		}
+	}
+	iter := jsoniter.ConfigDefault.BorrowIterator(event)
+	defer jsoniter.ConfigDefault.ReturnIterator(iter)
+	stream := jsoniter.ConfigDefault.BorrowStream(nil)
+	defer jsoniter.ConfigDefault.ReturnStream(stream)
+	w.redactValue(iter, stream, lt.fields, "")
+	if iter.Error != nil || stream.Error != nil {
+		// Events are always valid JSON objects written by the result encoder
+		return event
+	}
+	// Redact the values of all indicator fields so that Panther fields do not hold the original values
+	if values != nil {
+		for _, id := range values.Fields() {
+			values.MapValues(id, w.redactString)
+		}
+	}
+	// Lookup table rows are joined on indicator values
+	for _, rows := range enrichment {
+		for key, row := range rows {
+			if redacted := w.redactString(key); redacted != key {
+				delete(rows, key)
+				if redacted != "" {
+					rows[redacted] = row
+				}
+			}
+		}
+	}
+	return append([]byte(nil), stream.Buffer()...)
+}
+
+type eventRedactor struct {
+	key []byte
+	// indicators maps the values of redacted indicators to their redaction
+	indicators map[string]Action
+	// replacer redacts indicator values within longer strings, it is built on first use
+	replacer *strings.Replacer
+	// redacted maps all values redacted in the event to their redaction
+	redacted map[string]Action
+}
+
+// redactValue copies the JSON value from iter to stream redacting string values.
+// Values under a field node with an action inherit it.
+func (w *eventRedactor) redactValue(iter *jsoniter.Iterator, stream *jsoniter.Stream, node *fieldNode, action Action) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		stream.WriteObjectStart()
+		more := false
+		iter.ReadObjectCB(func(iter *jsoniter.Iterator, field string) bool {
+			child, childAction := node.child(field), action
+			if child != nil && child.action != "" {
+				childAction = child.action
+			}
+			if childAction == ActionDrop {
+				w.dropValue(iter)
+				return true
+			}
+			if iter.WhatIsNext() == jsoniter.StringValue {
+				value, ok := w.redactStringValue(iter.ReadString(), childAction)
+				if !ok {
+					return true
+				}
+				if more {
+					stream.WriteMore()
+				}
+				stream.WriteObjectField(field)
+				stream.WriteString(value)
+				more = true
+				return true
+			}
+			if more {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField(field)
+			w.redactValue(iter, stream, child, childAction)
+			more = true
+			return true
+		})
+		stream.WriteObjectEnd()
+	case jsoniter.ArrayValue:
+		stream.WriteArrayStart()
+		more := false
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			if action == ActionDrop {
+				w.dropValue(iter)
+				return true
+			}
+			if iter.WhatIsNext() == jsoniter.StringValue {
+				value, ok := w.redactStringValue(iter.ReadString(), action)
+				if !ok {
+					return true
+				}
+				if more {
+					stream.WriteMore()
+				}
+				stream.WriteString(value)
+				more = true
+				return true
+			}
+			if more {
+				stream.WriteMore()
+			}
+			// Array elements are matched by the same field paths as the array
+			w.redactValue(iter, stream, node, action)
+			more = true
+			return true
+		})
+		stream.WriteArrayEnd()
+	case jsoniter.StringValue:
+		value, ok := w.redactStringValue(iter.ReadString(), action)
+		if !ok {
+			stream.WriteNil()
+			return
+		}
+		stream.WriteString(value)
+	default:
+		stream.Write(iter.SkipAndReturnBytes())
+	}
+}
+
+// dropValue skips a dropped JSON value keeping track of its string values,
+// so that indicator values scanned from it are also dropped.
+func (w *eventRedactor) dropValue(iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		iter.ReadObjectCB(func(iter *jsoniter.Iterator, _ string) bool {
+			w.dropValue(iter)
+			return true
+		})
+	case jsoniter.ArrayValue:
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			w.dropValue(iter)
+			return true
+		})
+	case jsoniter.StringValue:
+		w.redactStringValue(iter.ReadString(), ActionDrop)
+	default:
+		iter.Skip()
+	}
+}
+
+// redactStringValue redacts a string value in the event.
+// It returns false if the value should be dropped.
+func (w *eventRedactor) redactStringValue(value string, action Action) (string, bool) {
+	if action == "" {
+		var ok bool
+		if action, ok = w.indicators[value]; !ok {
+			return w.replaceIndicators(value), true
+		}
+	} else if _, ok := w.redacted[value]; !ok {
+		// Indicator values scanned from this field are redacted the same way
+		w.redacted[value] = action
+	}
+	if action == ActionDrop {
+		return "", false
+	}
+	return w.apply(action, value), true
+}
+
+// replaceIndicators redacts the indicator values found within a string
+func (w *eventRedactor) replaceIndicators(value string) string {
+	if len(w.indicators) == 0 {
+		return value
+	}
+	if w.replacer == nil {
+		values := make([]string, 0, len(w.indicators))
+		for v := range w.indicators {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+		// Longer values are replaced first so that values containing other values are fully redacted
+		sort.Slice(values, func(i, j int) bool {
+			if len(values[i]) != len(values[j]) {
+				return len(values[i]) > len(values[j])
+			}
+			return values[i] < values[j]
+		})
+		pairs := make([]string, 0, 2*len(values))
+		for _, v := range values {
+			replacement := ""
+			if action := w.indicators[v]; action != ActionDrop {
+				replacement = w.apply(action, v)
+			}
+			pairs = append(pairs, v, replacement)
+		}
+		w.replacer = strings.NewReplacer(pairs...)
+	}
+	return w.replacer.Replace(value)
+}
+
+// redactString redacts an indicator value, it returns an empty string if the value should be dropped.
+func (w *eventRedactor) redactString(value string) string {
+	action, ok := w.redacted[value]
+	if !ok {
+		return value
+	}
+	if action == ActionDrop {
+		return ""
+	}
+	return w.apply(action, value)
+}
+
+func (w *eventRedactor) apply(action Action, value string) string {
+	switch action {
+	case ActionHash:
+		return Token(w.key, value)
+	case ActionMask:
+		return Mask(value)
+	default:
+		return value
+	}
+}
+
+func (n *fieldNode) child(name string) *fieldNode {
+	if n == nil {
+		return nil
+	}
+	return n.fields[name]
+}
@@ -0,0 +1,237 @@
+package redaction
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/multierr"
+
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers/oktalogs"
+)
+
+var testKey = []byte("secret")
+
+func TestToken(t *testing.T) {
+	assert := require.New(t)
+	token := Token(testKey, "alice@example.com")
+	assert.Len(token, len(TokenPrefix)+2*tokenSize)
+	assert.Equal(token, Token(testKey, "alice@example.com"))
+	assert.NotEqual(token, Token(testKey, "bob@example.com"))
+	assert.NotEqual(token, Token([]byte("other"), "alice@example.com"))
+}
+
+func TestMask(t *testing.T) {
+	assert := require.New(t)
+	assert.Equal("*****@example.com", Mask("alice@example.com"))
+	assert.Equal("*.*.*.***", Mask("1.1.1.255"))
+	assert.Equal("@***", Mask("@foo"))
+	assert.Equal("", Mask(""))
+}
+
+func TestNew(t *testing.T) {
+	assert := require.New(t)
+	_, err := New(nil, nil)
+	assert.Error(err)
+
+	r, err := New(testKey, map[string][]Rule{
+		"Foo.Bar": {
+			{Field: "$.user.email", Action: ActionHash},
+			{Indicator: "p_any_ip_addresses", Action: ActionMask},
+		},
+		"Foo.Baz": {
+			{Field: "$.records[0].user", Action: ActionDrop},
+			{Indicator: "p_any_foo", Action: ActionDrop},
+			{Field: "$.user", Indicator: "p_any_emails", Action: ActionDrop},
+			{Action: ActionDrop},
+			{Field: "$.user", Action: "encrypt"},
+			{Field: "$..user", Action: ActionDrop},
+		},
+	})
+	assert.Error(err)
+	assert.Len(multierr.Errors(err), 6)
+	assert.NotNil(r)
+	assert.Contains(r.logTypes, "Foo.Bar")
+	assert.NotContains(r.logTypes, "Foo.Baz")
+}
+
+func TestRedactor(t *testing.T) {
+	assert := require.New(t)
+	r, err := New(testKey, map[string][]Rule{
+		"Foo.Bar": {
+			{Field: "$.user.email", Action: ActionHash},
+			{Field: "$.user.phone", Action: ActionDrop},
+			{Field: "$.actor", Action: ActionMask},
+			{Field: "$.targets.name", Action: ActionHash},
+			{Indicator: "p_any_ip_addresses", Action: ActionHash},
+		},
+	})
+	assert.NoError(err)
+
+	event := []byte(`{
+		"user": {"email":"alice@example.com","phone":"555-1234","age":42},
+		"actor": {"name":"bob","tags":["admin"],"active":true},
+		"targets": [{"name":"carol","id":1},{"name":"dave","id":2}],
+		"remote_ip": "1.1.1.1",
+		"message": "login from 1.1.1.1",
+		"client": {"ip":"1.1.1.1"}
+	}`)
+	values := pantherlog.ValueBuffer{}
+	values.WriteValues(pantherlog.FieldIPAddress, "1.1.1.1")
+	values.WriteValues(pantherlog.FieldEmail, "alice@example.com")
+	values.WriteValues(pantherlog.FieldDomainName, "example.com")
+	enrichment := pantherlog.Enrichment{
+		"geo": {"1.1.1.1": {"country": "GR"}},
+	}
+	actual := r.Redact("Foo.Bar", event, &values, enrichment)
+
+	token := func(value string) string {
+		return Token(testKey, value)
+	}
+	expect := fmt.Sprintf(`{
+		"user": {"email":%q,"age":42},
+		"actor": {"name":"***","tags":["*****"],"active":true},
+		"targets": [{"name":%q,"id":1},{"name":%q,"id":2}],
+		"remote_ip": %q,
+		"message": %q,
+		"client": {"ip":%q}
+	}`, token("alice@example.com"), token("carol"), token("dave"), token("1.1.1.1"), "login from "+token("1.1.1.1"), token("1.1.1.1"))
+	assert.JSONEq(expect, string(actual))
+	// Indicator values redacted in the event are redacted the same way
+	assert.Equal(map[pantherlog.FieldID][]string{
+		pantherlog.FieldIPAddress:  {token("1.1.1.1")},
+		pantherlog.FieldEmail:      {token("alice@example.com")},
+		pantherlog.FieldDomainName: {"example.com"},
+	}, values.Inspect())
+	assert.Equal(pantherlog.Enrichment{
+		"geo": {token("1.1.1.1"): {"country": "GR"}},
+	}, enrichment)
+
+	// Other log types are not redacted
+	assert.True(r.Redacts("Foo.Bar"))
+	assert.False(r.Redacts("Foo.Baz"))
+	assert.Equal(event, r.Redact("Foo.Baz", event, &values, nil))
+}
+
+func TestRedactorDrop(t *testing.T) {
+	assert := require.New(t)
+	r, err := New(testKey, map[string][]Rule{
+		"Foo.Bar": {
+			{Field: "$.emails", Action: ActionDrop},
+			{Indicator: "p_any_ip_addresses", Action: ActionDrop},
+		},
+	})
+	assert.NoError(err)
+	event := []byte(`{"emails":["alice@example.com"],"ips":["1.1.1.1","2.2.2.2"],"remote_ip":"1.1.1.1","msg":"from 1.1.1.1","port":22}`)
+	values := pantherlog.ValueBuffer{}
+	values.WriteValues(pantherlog.FieldIPAddress, "1.1.1.1", "2.2.2.2")
+	values.WriteValues(pantherlog.FieldEmail, "alice@example.com")
+	enrichment := pantherlog.Enrichment{
+		"geo": {"1.1.1.1": {"country": "GR"}},
+	}
+	actual := r.Redact("Foo.Bar", event, &values, enrichment)
+	assert.JSONEq(`{"ips":[],"msg":"from ","port":22}`, string(actual))
+	assert.Empty(values.Get(pantherlog.FieldIPAddress))
+	assert.Empty(values.Get(pantherlog.FieldEmail))
+	assert.Equal(pantherlog.Enrichment{"geo": {}}, enrichment)
+}
+
+func TestRedactorResult(t *testing.T) {
+	assert := require.New(t)
+	r, err := New(testKey, map[string][]Rule{
+		"Foo.Bar": {
+			{Indicator: "p_any_emails", Action: ActionHash},
+		},
+	})
+	assert.NoError(err)
+	type T struct {
+		User  string `json:"user" panther:"email"`
+		Actor string `json:"actor"`
+	}
+	now := time.Now().UTC()
+	result := pantherlog.Result{
+		CoreFields: pantherlog.CoreFields{
+			PantherLogType:   "Foo.Bar",
+			PantherRowID:     "id",
+			PantherParseTime: now,
+		},
+		Event:    &T{User: "alice@example.com", Actor: "alice@example.com"},
+		Redactor: r,
+	}
+	actual, err := jsoniter.MarshalToString(&result)
+	assert.NoError(err)
+	token := Token(testKey, "alice@example.com")
+	expect := fmt.Sprintf(`{
+		"user": %q,
+		"actor": %q,
+		"p_row_id": "id",
+		"p_event_time": %q,
+		"p_parse_time": %q,
+		"p_any_emails": [%q],
+		"p_log_type": "Foo.Bar"
+	}`, token, token, now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano), token)
+	assert.JSONEq(expect, actual)
+}
+
+func TestRedactorLegacyResult(t *testing.T) {
+	assert := require.New(t)
+	r, err := New(testKey, map[string][]Rule{
+		oktalogs.TypeSystemLog: {
+			{Field: "$.actor.alternateId", Action: ActionHash},
+			{Indicator: "p_any_ip_addresses", Action: ActionMask},
+		},
+	})
+	assert.NoError(err)
+	const log = `{
+		"uuid": "f790999f-fe87-467a-9880-6982a583986c",
+		"published": "2017-09-30T22:23:07.777Z",
+		"eventType": "user.session.start",
+		"version": "0",
+		"severity": "INFO",
+		"actor": {"id": "00u1qw1mqitPHM8AJ0g7", "type": "User", "alternateId": "admin@example.com"},
+		"client": {"ipAddress": "12.97.85.90"}
+	}`
+	logs, err := oktalogs.NewSystemLogParser().Parse(log)
+	assert.NoError(err)
+	assert.Len(logs, 1)
+	logs[0].SetPantherSource("source-id", "source-label")
+	// Events of parsers embedding parsers.PantherLog write their own Panther fields
+	result := logs[0].Result()
+	assert.True(result.EventIncludesPantherFields)
+	result.Redactor = r
+
+	actual, err := jsoniter.Marshal(result)
+	assert.NoError(err)
+	event := map[string]interface{}{}
+	assert.NoError(jsoniter.Unmarshal(actual, &event))
+	assert.Equal(Token(testKey, "admin@example.com"), event["actor"].(map[string]interface{})["alternateId"])
+	assert.Equal(Mask("12.97.85.90"), event["client"].(map[string]interface{})["ipAddress"])
+	assert.Equal([]interface{}{Mask("12.97.85.90")}, event["p_any_ip_addresses"])
+	assert.Equal(oktalogs.TypeSystemLog, event["p_log_type"])
+	assert.Equal("source-id", event["p_source_id"])
+	assert.Equal("source-label", event["p_source_label"])
+	assert.Equal("2017-09-30T22:23:07.777Z", event["p_event_time"])
+	assert.NotContains(string(actual), "admin@example.com")
+	assert.NotContains(string(actual), "12.97.85.90")
+}
@@ -0,0 +1,129 @@
+package sources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/common"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
+)
+
+const (
+	// HTTPPushSourceIDParam is the path parameter holding the source id in HTTP push requests
+	HTTPPushSourceIDParam = "sourceId"
+
+	httpPushTokenScheme = "Bearer "
+)
+
+var (
+	// ErrHTTPPushUnauthorized is returned when an HTTP push request cannot be authorized.
+	// We use the same error for unknown sources and invalid tokens to avoid leaking source ids to senders.
+	ErrHTTPPushUnauthorized = errors.New("unauthorized")
+	// ErrHTTPPushInvalidBody is returned when the body of an HTTP push request cannot be decoded.
+	ErrHTTPPushInvalidBody = errors.New("invalid request body")
+)
+
+// ReadHTTPPushRequest reads a request received by an HTTP push source and returns a slice of DataStream items
+func ReadHTTPPushRequest(ctx context.Context, req *events.APIGatewayProxyRequest) ([]*common.DataStream, error) {
+	stream, err := readHTTPPushRequest(req, FindSource)
+	if err != nil {
+		return nil, err
+	}
+	// Same as S3 sources, update the source status if enough time has passed
+	id := stream.Source.IntegrationID
+	now := time.Now() // No need to be UTC. We care about relative time
+	if now.After(lastEventReceived[id].Add(statusUpdateFrequency)) {
+		updateIntegrationStatus(id, now)
+		lastEventReceived[id] = now
+	}
+	return []*common.DataStream{stream}, nil
+}
+
+func readHTTPPushRequest(
+	req *events.APIGatewayProxyRequest,
+	findSource func(id string) (*models.SourceIntegration, error),
+) (*common.DataStream, error) {
+
+	src, err := findSource(req.PathParameters[HTTPPushSourceIDParam])
+	if err != nil {
+		// Senders should retry the request, the source might be valid
+		return nil, errors.WithMessage(err, "failed to load source")
+	}
+	if src == nil || src.IntegrationType != models.IntegrationTypeHTTPPush {
+		return nil, ErrHTTPPushUnauthorized
+	}
+	token := httpPushRequestToken(req.Headers)
+	if !src.HTTPPushConfig.Authorize(token) {
+		return nil, ErrHTTPPushUnauthorized
+	}
+
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(req.Body); err != nil {
+			return nil, errors.WithMessagef(ErrHTTPPushInvalidBody, "failed to decode base64 body: %s", err)
+		}
+	}
+	var r io.Reader = bytes.NewReader(body)
+	if strings.EqualFold(httpHeader(req.Headers, "Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.WithMessagef(ErrHTTPPushInvalidBody, "failed to read gzip body: %s", err)
+		}
+		r = gz
+	}
+	return &common.DataStream{
+		// Each line of the request body is a separate log entry, same as SQS sources
+		Stream: logstream.NewLineStream(r, logstream.DefaultBufferSize),
+		Source: src,
+	}, nil
+}
+
+// httpPushRequestToken reads the auth token from the Authorization header of a request.
+func httpPushRequestToken(headers map[string]string) string {
+	auth := httpHeader(headers, "Authorization")
+	if len(auth) < len(httpPushTokenScheme) || !strings.EqualFold(auth[:len(httpPushTokenScheme)], httpPushTokenScheme) {
+		return ""
+	}
+	return strings.TrimSpace(auth[len(httpPushTokenScheme):])
+}
+
+// httpHeader does a case-insensitive lookup of an HTTP header.
+// API Gateway passes the headers as they were sent by the client.
+func httpHeader(headers map[string]string, name string) string {
+	if value, ok := headers[name]; ok {
+		return value
+	}
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
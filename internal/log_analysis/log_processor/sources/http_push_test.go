@@ -0,0 +1,118 @@
+package sources
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/api/lambda/source/models"
+)
+
+func TestReadHTTPPushRequest(t *testing.T) {
+	const testSourceID = "testSource"
+	testSource := &models.SourceIntegration{
+		SourceIntegrationMetadata: models.SourceIntegrationMetadata{
+			IntegrationID:   testSourceID,
+			IntegrationType: models.IntegrationTypeHTTPPush,
+			HTTPPushConfig: &models.HTTPPushConfig{
+				LogTypes:  []string{"testLog"},
+				AuthToken: "token",
+			},
+		},
+	}
+	loadSource := func(id string) (*models.SourceIntegration, error) {
+		switch id {
+		case testSourceID:
+			return testSource, nil
+		case "failing":
+			return nil, errors.New("failed to list sources")
+		default:
+			return nil, nil
+		}
+	}
+	newRequest := func(sourceID, auth, body string) *events.APIGatewayProxyRequest {
+		return &events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{HTTPPushSourceIDParam: sourceID},
+			Headers:        map[string]string{"authorization": auth},
+			Body:           body,
+		}
+	}
+	readLines := func(t *testing.T, req *events.APIGatewayProxyRequest) []string {
+		t.Helper()
+		stream, err := readHTTPPushRequest(req, loadSource)
+		require.NoError(t, err)
+		require.Equal(t, testSource, stream.Source)
+		var lines []string
+		for line := stream.Stream.Next(); line != nil; line = stream.Stream.Next() {
+			lines = append(lines, string(line))
+		}
+		require.NoError(t, stream.Stream.Err())
+		return lines
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		lines := readLines(t, newRequest(testSourceID, "Bearer token", "foo\nbar\n"))
+		require.Equal(t, []string{"foo", "bar"}, lines)
+	})
+	t.Run("Base64Gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write([]byte("foo\nbar\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		req := newRequest(testSourceID, "bearer token", base64.StdEncoding.EncodeToString(buf.Bytes()))
+		req.IsBase64Encoded = true
+		req.Headers["Content-Encoding"] = "gzip"
+		require.Equal(t, []string{"foo", "bar"}, readLines(t, req))
+	})
+	t.Run("InvalidToken", func(t *testing.T) {
+		_, err := readHTTPPushRequest(newRequest(testSourceID, "Bearer invalid", "foo"), loadSource)
+		require.True(t, errors.Is(err, ErrHTTPPushUnauthorized))
+		_, err = readHTTPPushRequest(newRequest(testSourceID, "token", "foo"), loadSource)
+		require.True(t, errors.Is(err, ErrHTTPPushUnauthorized))
+	})
+	t.Run("UnknownSource", func(t *testing.T) {
+		_, err := readHTTPPushRequest(newRequest("unknown", "Bearer token", "foo"), loadSource)
+		require.True(t, errors.Is(err, ErrHTTPPushUnauthorized))
+	})
+	t.Run("SourceError", func(t *testing.T) {
+		_, err := readHTTPPushRequest(newRequest("failing", "Bearer token", "foo"), loadSource)
+		require.Error(t, err)
+		// Failures to load sources are not reported as unauthorized so that senders retry the request
+		require.False(t, errors.Is(err, ErrHTTPPushUnauthorized))
+		require.False(t, errors.Is(err, ErrHTTPPushInvalidBody))
+	})
+	t.Run("InvalidBody", func(t *testing.T) {
+		req := newRequest(testSourceID, "Bearer token", "not base64")
+		req.IsBase64Encoded = true
+		_, err := readHTTPPushRequest(req, loadSource)
+		require.True(t, errors.Is(err, ErrHTTPPushInvalidBody))
+		req = newRequest(testSourceID, "Bearer token", "not gzip")
+		req.Headers["Content-Encoding"] = "gzip"
+		_, err = readHTTPPushRequest(req, loadSource)
+		require.True(t, errors.Is(err, ErrHTTPPushInvalidBody))
+	})
+}
@@ -20,6 +20,7 @@ package sources
 
 import (
 	"context"
+	"io"
 	"net/url"
 	"path"
 	"regexp"
@@ -156,11 +157,18 @@ func buildStream(ctx context.Context, s3Object *S3ObjectInfo) (*common.DataStrea
 	var stream logstream.Stream
 	switch src.IntegrationType {
 	case models.IntegrationTypeAWS3:
-		if isCloudTrailLog(key) && stringset.Contains(src.RequiredLogTypes(), "AWS.CloudTrail") {
+		if m, matched := src.S3PrefixLogTypes.LongestPrefixMatch(key); matched && m.JSONStream != nil {
+			stream, err = logstream.NewJSONStreamWithConfig(r, DownloadMinPartSize, m.JSONStream)
+			if err != nil {
+				// The configuration is validated by the source API so this should not happen.
+				_ = r.Close()
+				return nil, errors.Wrapf(err, "invalid JSON stream configuration for prefix %q", m.S3Prefix)
+			}
+		} else if isCloudTrailLog(key) && stringset.Contains(src.RequiredLogTypes(), "AWS.CloudTrail") {
 			zap.L().Debug("detected CloudTrail logs", zap.String("bucket", bucket), zap.String("key", key))
 			stream = logstream.NewJSONArrayStream(r, DownloadMinPartSize, "Records")
 		} else {
-			stream = logstream.NewLineStream(r, DownloadMinPartSize)
+			stream = newS3LineStream(r, src, key)
 		}
 	default:
 		// Set the buffer size to something big to avoid multiple fill() calls if possible
@@ -176,6 +184,27 @@ func buildStream(ctx context.Context, s3Object *S3ObjectInfo) (*common.DataStrea
 	}, nil
 }
 
+// newS3LineStream creates a line stream for an S3 object.
+// If the prefix of the object is configured to assemble multi-line entries the lines are assembled accordingly.
+func newS3LineStream(r io.Reader, src *models.SourceIntegration, key string) logstream.Stream {
+	lines := logstream.NewLineStream(r, DownloadMinPartSize)
+	m, matched := src.S3PrefixLogTypes.LongestPrefixMatch(key)
+	if !matched || m.MultiLine == nil {
+		return lines
+	}
+	stream, err := logstream.NewMultiLineStream(lines, m.MultiLine)
+	if err != nil {
+		// The configuration is validated by the source API so this should not happen.
+		// We fall back to reading lines to avoid blocking the processing of the object.
+		zap.L().Warn("invalid multi-line configuration for source",
+			zap.String("sourceId", src.IntegrationID),
+			zap.String("prefix", m.S3Prefix),
+			zap.Error(err))
+		return lines
+	}
+	return stream
+}
+
 func calculatePartSize(size int64) int64 {
 	// we want this as large as possible to minimize S3 api calls, not more than DownloadMaxPartSize to control memory use
 	partSize := size / 2 // use 1/2 to allow processing first half while reading second half on small files
@@ -20,6 +20,7 @@ package sources
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/pkg/errors"
@@ -29,6 +30,7 @@ import (
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/classification"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/pantherlog"
 	"github.com/panther-labs/panther/internal/log_analysis/log_processor/parsers"
+	"github.com/panther-labs/panther/internal/log_analysis/log_processor/processor/logstream"
 )
 
 // LoadSource loads the source configuration for an source id.
@@ -38,6 +40,16 @@ func LoadSource(id string) (*models.SourceIntegration, error) {
 	return globalSourceCache.Load(id)
 }
 
+// FindSource finds the source configuration for an id.
+// It will update the global cache if needed
+// It returns nil if the source is not found and an error only if it failed to retrieve the source information.
+func FindSource(id string) (*models.SourceIntegration, error) {
+	if err := globalSourceCache.Sync(time.Now()); err != nil {
+		return nil, err
+	}
+	return globalSourceCache.Find(id), nil
+}
+
 // LoadSourceS3 loads the source configuration for an S3 object.
 // It will update the global cache if needed
 // It will return error if it encountered an issue retrieving the source information or if the source is not found.
@@ -71,6 +83,16 @@ func BuildClassifier(
 	src *models.SourceIntegration,
 	r pantherlog.ParserResolver,
 ) (classification.ClassifierAPI, error) {
+	return BuildClassifierWithHints(availableLogTypes, classification.Hints{}, src, r)
+}
+
+// BuildClassifierWithHints builds a classifier for a source that uses hints to classify log lines
+func BuildClassifierWithHints(
+	availableLogTypes []string,
+	hints classification.Hints,
+	src *models.SourceIntegration,
+	r pantherlog.ParserResolver,
+) (classification.ClassifierAPI, error) {
 
 	parserIndex := map[string]pantherlog.LogParser{}
 	for _, logType := range availableLogTypes {
@@ -84,7 +106,34 @@ func BuildClassifier(
 		}
 		parserIndex[logType] = newSourceFieldsParser(src.IntegrationID, src.IntegrationLabel, parser)
 	}
-	return classification.NewClassifier(parserIndex), nil
+	return classification.NewClassifierWithHints(parserIndex, hints), nil
+}
+
+// WrapMultiLineStream assembles lines into multi-line entries if all available log types expect it.
+// Streams that are not plain line streams (ie JSON arrays or already configured by the source) are returned as is.
+func WrapMultiLineStream(stream logstream.Stream, availableLogTypes []string, r pantherlog.ParserResolver) (logstream.Stream, error) {
+	lines, ok := stream.(*logstream.LineStream)
+	if !ok || len(availableLogTypes) == 0 {
+		return stream, nil
+	}
+	var config *logstream.MultiLineConfig
+	for _, logType := range availableLogTypes {
+		parser, err := r.ResolveParser(context.TODO(), logType)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not resolve log type parser %q", logType)
+		}
+		c, ok := parser.(logstream.MultiLineConfigurer)
+		if !ok || c.MultiLineConfig() == nil {
+			// Multi-line assembly would break parsing of single line log types
+			return stream, nil
+		}
+		if config != nil && !reflect.DeepEqual(config, c.MultiLineConfig()) {
+			zap.L().Warn("conflicting multi-line configuration", zap.Strings("logTypes", availableLogTypes))
+			return stream, nil
+		}
+		config = c.MultiLineConfig()
+	}
+	return logstream.NewMultiLineStream(lines, config)
 }
 
 func newSourceFieldsParser(id, label string, parser pantherlog.LogParser) pantherlog.LogParser {
@@ -62,6 +62,10 @@ func (c *SQSClassifier) Classify(log string) (*classification.ClassifierResult,
 		}
 		c.classifiers[msg.SourceIntegrationID] = cls
 	}
+	// Use the log type set in the message attributes as a hint
+	if hinted, ok := cls.(classification.HintedClassifier); ok && msg.LogType != "" {
+		return hinted.ClassifyWithHints(msg.Payload, msg.LogType)
+	}
 	return cls.Classify(msg.Payload)
 }
 
@@ -0,0 +1,510 @@
+package transforms
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// Interface transforms a log event decoded as a JSON object
+type Interface interface {
+	Transform(event map[string]interface{}) error
+}
+
+// Config describes a transformation of a log event, exactly one of its fields must be set.
+// Fields are referenced by their path in the event with nested object keys separated by '.'
+// nolint:lll
+type Config struct {
+	Rename *RenameConfig `json:"rename,omitempty" yaml:"rename,omitempty" description:"Move a field to another path"`
+	Copy   *CopyConfig   `json:"copy,omitempty" yaml:"copy,omitempty" description:"Copy a field to another path"`
+	Concat *ConcatConfig `json:"concat,omitempty" yaml:"concat,omitempty" description:"Join the values of multiple fields into a string"`
+	Split  *SplitConfig  `json:"split,omitempty" yaml:"split,omitempty" description:"Split a string field into an array of strings"`
+	Mask   *MaskConfig   `json:"mask,omitempty" yaml:"mask,omitempty" description:"Hash or mask the value of a field"`
+	Coerce *CoerceConfig `json:"coerce,omitempty" yaml:"coerce,omitempty" description:"Convert the value of a field to another type"`
+}
+
+// nolint:lll
+type RenameConfig struct {
+	From string `json:"from" yaml:"from" description:"The path of the field to move"`
+	To   string `json:"to" yaml:"to" description:"The path to move the field to"`
+}
+
+// nolint:lll
+type CopyConfig struct {
+	From string `json:"from" yaml:"from" description:"The path of the field to copy"`
+	To   string `json:"to" yaml:"to" description:"The path to copy the field to"`
+}
+
+// nolint:lll
+type ConcatConfig struct {
+	Fields    []string `json:"fields" yaml:"fields" description:"The paths of the fields to join, missing fields are skipped"`
+	Separator string   `json:"separator,omitempty" yaml:"separator,omitempty" description:"The separator to put between values"`
+	To        string   `json:"to" yaml:"to" description:"The path to store the joined value"`
+}
+
+// nolint:lll
+type SplitConfig struct {
+	Field     string `json:"field" yaml:"field" description:"The path of the string field to split"`
+	Separator string `json:"separator" yaml:"separator" description:"The separator of values in the string"`
+	To        string `json:"to,omitempty" yaml:"to,omitempty" description:"The path to store the array of values, defaults to the split field"`
+	TrimSpace bool   `json:"trimSpace,omitempty" yaml:"trimSpace,omitempty" description:"Trim space surrounding values"`
+}
+
+const (
+	// MaskHash replaces a value with a keyed HMAC token so that it can still be correlated (see SetHashKey)
+	MaskHash = "hash"
+	// MaskRedact replaces a value with a fixed string
+	MaskRedact = "redact"
+	// MaskEmail masks the local part of an email address keeping the domain
+	MaskEmail = "email"
+
+	defaultMaskReplacement = "****"
+
+	// Same as redaction tokens so that hashed values can be matched in rules and queries
+	hashTokenPrefix = "tok_"
+	hashTokenSize   = 16
+)
+
+// hashKey holds the HMAC key of hash masks
+var (
+	hashKey   atomic.Value
+	hashKeyMu sync.Mutex
+)
+
+// SetHashKey sets the HMAC key used by hash masks.
+// The log processor sets it to the redaction key, so that hashed values are the same as redaction tokens.
+// The key is generated on deployment even if there are no redaction rules.
+// Plain hashes of low-entropy values such as emails or IP addresses can be reversed with a dictionary,
+// so hash masks fail until a key is set.
+func SetHashKey(key []byte) {
+	hashKeyMu.Lock()
+	defer hashKeyMu.Unlock()
+	hashKey.Store(append([]byte(nil), key...))
+}
+
+// SetRandomHashKey sets a random HMAC key for hash masks if no key has been set.
+// It is used by dry runs that do not store events, the tokens of hashed values differ from the stored ones.
+func SetRandomHashKey() error {
+	hashKeyMu.Lock()
+	defer hashKeyMu.Unlock()
+	if key, _ := hashKey.Load().([]byte); len(key) > 0 {
+		return nil
+	}
+	key := make([]byte, hashTokenSize*2)
+	if _, err := rand.Read(key); err != nil {
+		return errors.Wrap(err, "failed to generate hash key")
+	}
+	hashKey.Store(key)
+	return nil
+}
+
+func hashToken(value string) (string, error) {
+	key, _ := hashKey.Load().([]byte)
+	if len(key) == 0 {
+		return "", errors.New("hash masks require a redaction key")
+	}
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(value))
+	return hashTokenPrefix + hex.EncodeToString(h.Sum(nil)[:hashTokenSize]), nil
+}
+
+// nolint:lll
+type MaskConfig struct {
+	Field       string `json:"field" yaml:"field" description:"The path of the field to mask"`
+	Method      string `json:"method" yaml:"method" description:"The masking method (hash, redact, email)"`
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty" description:"The string to replace redacted values with"`
+}
+
+const (
+	CoerceString  = "string"
+	CoerceInt     = "int"
+	CoerceFloat   = "float"
+	CoerceBoolean = "boolean"
+)
+
+// nolint:lll
+type CoerceConfig struct {
+	Field string `json:"field" yaml:"field" description:"The path of the field to convert"`
+	Type  string `json:"type" yaml:"type" description:"The type to convert the value to (string, int, float, boolean)"`
+}
+
+// Transformer applies transformations in order to JSON log entries
+type Transformer struct {
+	steps []Interface
+}
+
+// Build builds a transformer for a list of transformations.
+// It returns nil if there are no transformations.
+func Build(configs ...Config) (*Transformer, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	steps := make([]Interface, 0, len(configs))
+	for i := range configs {
+		step, err := configs[i].BuildTransform()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transform #%d", i)
+		}
+		steps = append(steps, step)
+	}
+	return &Transformer{steps: steps}, nil
+}
+
+// Transform implements Interface
+func (t *Transformer) Transform(event map[string]interface{}) error {
+	for _, step := range t.steps {
+		if err := step.Transform(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Numbers are kept as is so that large integers do not lose precision
+var jsonAPI = jsoniter.Config{
+	UseNumber: true,
+}.Froze()
+
+// TransformLog applies the transformations to a JSON object log entry
+func (t *Transformer) TransformLog(log string) (string, error) {
+	event := map[string]interface{}{}
+	if err := jsonAPI.UnmarshalFromString(log, &event); err != nil {
+		return "", errors.Wrap(err, "failed to decode log entry for transform")
+	}
+	if err := t.Transform(event); err != nil {
+		return "", err
+	}
+	return jsonAPI.MarshalToString(event)
+}
+
+// BuildTransform validates the config and builds a transformation
+func (c *Config) BuildTransform() (Interface, error) {
+	var steps []Interface
+	if c.Rename != nil {
+		steps = append(steps, c.Rename)
+	}
+	if c.Copy != nil {
+		steps = append(steps, c.Copy)
+	}
+	if c.Concat != nil {
+		steps = append(steps, c.Concat)
+	}
+	if c.Split != nil {
+		steps = append(steps, c.Split)
+	}
+	if c.Mask != nil {
+		steps = append(steps, c.Mask)
+	}
+	if c.Coerce != nil {
+		steps = append(steps, c.Coerce)
+	}
+	if len(steps) != 1 {
+		return nil, errors.New("exactly one transformation must be set")
+	}
+	step := steps[0]
+	if err := step.(validator).validate(); err != nil {
+		return nil, err
+	}
+	return step, nil
+}
+
+type validator interface {
+	validate() error
+}
+
+func (c *RenameConfig) validate() error {
+	if c.From == "" || c.To == "" {
+		return errors.New("rename requires 'from' and 'to' fields")
+	}
+	return nil
+}
+
+func (c *RenameConfig) Transform(event map[string]interface{}) error {
+	if value, ok := deleteField(event, c.From); ok {
+		setField(event, c.To, value)
+	}
+	return nil
+}
+
+func (c *CopyConfig) validate() error {
+	if c.From == "" || c.To == "" {
+		return errors.New("copy requires 'from' and 'to' fields")
+	}
+	return nil
+}
+
+func (c *CopyConfig) Transform(event map[string]interface{}) error {
+	if value, ok := getField(event, c.From); ok {
+		// Objects and arrays are copied so that later transformations of either field do not affect the other
+		setField(event, c.To, deepCopy(value))
+	}
+	return nil
+}
+
+func (c *ConcatConfig) validate() error {
+	if len(c.Fields) == 0 || c.To == "" {
+		return errors.New("concat requires 'fields' and 'to' fields")
+	}
+	return nil
+}
+
+func (c *ConcatConfig) Transform(event map[string]interface{}) error {
+	var values []string
+	for _, field := range c.Fields {
+		value, ok := getField(event, field)
+		if !ok || value == nil {
+			continue
+		}
+		s, err := toString(value)
+		if err != nil {
+			return errors.Wrapf(err, "cannot concat field %q", field)
+		}
+		values = append(values, s)
+	}
+	if values != nil {
+		setField(event, c.To, strings.Join(values, c.Separator))
+	}
+	return nil
+}
+
+func (c *SplitConfig) validate() error {
+	if c.Field == "" || c.Separator == "" {
+		return errors.New("split requires 'field' and 'separator' fields")
+	}
+	return nil
+}
+
+func (c *SplitConfig) Transform(event map[string]interface{}) error {
+	value, ok := getField(event, c.Field)
+	if !ok {
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	parts := strings.Split(s, c.Separator)
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		if c.TrimSpace {
+			part = strings.TrimSpace(part)
+		}
+		values = append(values, part)
+	}
+	to := c.To
+	if to == "" {
+		to = c.Field
+	}
+	setField(event, to, values)
+	return nil
+}
+
+func (c *MaskConfig) validate() error {
+	if c.Field == "" {
+		return errors.New("mask requires a 'field' field")
+	}
+	switch c.Method {
+	case MaskHash, MaskRedact, MaskEmail:
+		return nil
+	default:
+		return errors.Errorf("invalid mask method %q", c.Method)
+	}
+}
+
+func (c *MaskConfig) Transform(event map[string]interface{}) error {
+	value, ok := getField(event, c.Field)
+	if !ok || value == nil {
+		return nil
+	}
+	s, err := toString(value)
+	if err != nil {
+		return errors.Wrapf(err, "cannot mask field %q", c.Field)
+	}
+	masked, err := c.mask(s)
+	if err != nil {
+		return errors.Wrapf(err, "cannot mask field %q", c.Field)
+	}
+	setField(event, c.Field, masked)
+	return nil
+}
+
+func (c *MaskConfig) mask(s string) (string, error) {
+	replacement := c.Replacement
+	if replacement == "" {
+		replacement = defaultMaskReplacement
+	}
+	switch c.Method {
+	case MaskHash:
+		return hashToken(s)
+	case MaskEmail:
+		at := strings.LastIndexByte(s, '@')
+		if at < 1 {
+			return replacement, nil
+		}
+		// Keep the first character of the local part so that masked addresses are easier to tell apart
+		_, size := utf8.DecodeRuneInString(s)
+		return s[:size] + replacement + s[at:], nil
+	default:
+		return replacement, nil
+	}
+}
+
+func (c *CoerceConfig) validate() error {
+	if c.Field == "" {
+		return errors.New("coerce requires a 'field' field")
+	}
+	switch c.Type {
+	case CoerceString, CoerceInt, CoerceFloat, CoerceBoolean:
+		return nil
+	default:
+		return errors.Errorf("invalid coerce type %q", c.Type)
+	}
+}
+
+func (c *CoerceConfig) Transform(event map[string]interface{}) error {
+	value, ok := getField(event, c.Field)
+	if !ok || value == nil {
+		return nil
+	}
+	s, err := toString(value)
+	if err != nil {
+		return errors.Wrapf(err, "cannot coerce field %q", c.Field)
+	}
+	s = strings.TrimSpace(s)
+	var coerced interface{}
+	switch c.Type {
+	case CoerceString:
+		coerced = s
+	case CoerceInt:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			// Allow integral floats such as "1.0" or "1e3"
+			f, errFloat := strconv.ParseFloat(s, 64)
+			// Floats outside the int64 range cannot be converted, 2^63 itself is out of range
+			if errFloat != nil || f < math.MinInt64 || f >= math.MaxInt64 || f != math.Trunc(f) {
+				return errors.Errorf("cannot coerce field %q to int: %q", c.Field, s)
+			}
+			n = int64(f)
+		}
+		coerced = jsoniter.Number(strconv.FormatInt(n, 10))
+	case CoerceFloat:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return errors.Errorf("cannot coerce field %q to float: %q", c.Field, s)
+		}
+		coerced = jsoniter.Number(s)
+	case CoerceBoolean:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.Errorf("cannot coerce field %q to boolean: %q", c.Field, s)
+		}
+		coerced = b
+	}
+	setField(event, c.Field, coerced)
+	return nil
+}
+
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case jsoniter.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		// Objects and arrays are converted to their JSON representation
+		return jsonAPI.MarshalToString(v)
+	}
+}
+
+func getField(event map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	obj := event
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		obj = next
+	}
+	value, ok := obj[keys[len(keys)-1]]
+	return value, ok
+}
+
+func setField(event map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	obj := event
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := obj[key].(map[string]interface{})
+		if !ok {
+			// Create missing parent objects, replacing any non-object values
+			next = map[string]interface{}{}
+			obj[key] = next
+		}
+		obj = next
+	}
+	obj[keys[len(keys)-1]] = value
+}
+
+func deleteField(event map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	obj := event
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		obj = next
+	}
+	key := keys[len(keys)-1]
+	value, ok := obj[key]
+	delete(obj, key)
+	return value, ok
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			obj[key] = deepCopy(value)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, value := range v {
+			arr[i] = deepCopy(value)
+		}
+		return arr
+	default:
+		return v
+	}
+}
@@ -0,0 +1,174 @@
+package transforms
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformLog(t *testing.T) {
+	SetHashKey([]byte("key"))
+	type testCase struct {
+		Name    string
+		Configs []Config
+		Input   string
+		Expect  string
+	}
+	for _, tc := range []testCase{
+		{
+			Name:    "rename nested",
+			Configs: []Config{{Rename: &RenameConfig{From: "a.b", To: "c.d.e"}}},
+			Input:   `{"a":{"b":1,"x":2}}`,
+			Expect:  `{"a":{"x":2},"c":{"d":{"e":1}}}`,
+		},
+		{
+			Name:    "rename missing",
+			Configs: []Config{{Rename: &RenameConfig{From: "a.b", To: "c"}}},
+			Input:   `{"a":1}`,
+			Expect:  `{"a":1}`,
+		},
+		{
+			Name: "copy is deep",
+			Configs: []Config{
+				{Copy: &CopyConfig{From: "a", To: "b"}},
+				{Mask: &MaskConfig{Field: "b.c", Method: MaskRedact}},
+			},
+			Input:  `{"a":{"c":"foo"}}`,
+			Expect: `{"a":{"c":"foo"},"b":{"c":"****"}}`,
+		},
+		{
+			Name:    "concat",
+			Configs: []Config{{Concat: &ConcatConfig{Fields: []string{"a", "b", "missing", "c"}, Separator: "-", To: "d"}}},
+			Input:   `{"a":"foo","b":42,"c":true}`,
+			Expect:  `{"a":"foo","b":42,"c":true,"d":"foo-42-true"}`,
+		},
+		{
+			Name:    "split",
+			Configs: []Config{{Split: &SplitConfig{Field: "a", Separator: ",", To: "b", TrimSpace: true}}},
+			Input:   `{"a":"foo, bar"}`,
+			Expect:  `{"a":"foo, bar","b":["foo","bar"]}`,
+		},
+		{
+			Name:    "mask email",
+			Configs: []Config{{Mask: &MaskConfig{Field: "a", Method: MaskEmail, Replacement: "x"}}},
+			Input:   `{"a":"alice@example.com"}`,
+			Expect:  `{"a":"ax@example.com"}`,
+		},
+		{
+			Name:    "mask email multi-byte",
+			Configs: []Config{{Mask: &MaskConfig{Field: "a", Method: MaskEmail, Replacement: "x"}}},
+			Input:   `{"a":"élodie@example.com"}`,
+			Expect:  `{"a":"éx@example.com"}`,
+		},
+		{
+			Name:    "mask hash",
+			Configs: []Config{{Mask: &MaskConfig{Field: "a", Method: MaskHash}}},
+			Input:   `{"a":"secret"}`,
+			Expect:  `{"a":"tok_25cf3c44c8f39313e8cbf7c23e22fe8b"}`,
+		},
+		{
+			Name: "coerce",
+			Configs: []Config{
+				{Coerce: &CoerceConfig{Field: "a", Type: CoerceInt}},
+				{Coerce: &CoerceConfig{Field: "b", Type: CoerceFloat}},
+				{Coerce: &CoerceConfig{Field: "c", Type: CoerceBoolean}},
+				{Coerce: &CoerceConfig{Field: "d", Type: CoerceString}},
+				{Coerce: &CoerceConfig{Field: "e", Type: CoerceInt}},
+			},
+			Input:  `{"a":"42","b":" 1.5 ","c":"true","d":12345678901234567890,"e":1e3}`,
+			Expect: `{"a":42,"b":1.5,"c":true,"d":"12345678901234567890","e":1000}`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			transformer, err := Build(tc.Configs...)
+			require.NoError(t, err)
+			actual, err := transformer.TransformLog(tc.Input)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.Expect, actual)
+		})
+	}
+}
+
+func TestTransformLogErrors(t *testing.T) {
+	transformer, err := Build(Config{Coerce: &CoerceConfig{Field: "a", Type: CoerceInt}})
+	require.NoError(t, err)
+	_, err = transformer.TransformLog(`{"a":"foo"}`)
+	require.Error(t, err)
+	_, err = transformer.TransformLog(`{"a":1.5}`)
+	require.Error(t, err)
+	_, err = transformer.TransformLog(`[]`)
+	require.Error(t, err)
+	// Floats out of the int64 range cannot be converted
+	_, err = transformer.TransformLog(`{"a":9223372036854775808.0}`)
+	require.Error(t, err)
+	_, err = transformer.TransformLog(`{"a":-1e19}`)
+	require.Error(t, err)
+	_, err = transformer.TransformLog(`{"a":"-9.223372036854775808e18"}`)
+	require.NoError(t, err)
+}
+
+func TestMaskHashRequiresKey(t *testing.T) {
+	SetHashKey(nil)
+	defer SetHashKey([]byte("key"))
+	transformer, err := Build(Config{Mask: &MaskConfig{Field: "a", Method: MaskHash}})
+	require.NoError(t, err)
+	_, err = transformer.TransformLog(`{"a":"secret"}`)
+	require.Error(t, err)
+}
+
+func TestSetRandomHashKey(t *testing.T) {
+	defer SetHashKey([]byte("key"))
+	transformer, err := Build(Config{Mask: &MaskConfig{Field: "a", Method: MaskHash}})
+	require.NoError(t, err)
+	expect, err := transformer.TransformLog(`{"a":"secret"}`)
+	require.NoError(t, err)
+	// An existing key is kept
+	require.NoError(t, SetRandomHashKey())
+	actual, err := transformer.TransformLog(`{"a":"secret"}`)
+	require.NoError(t, err)
+	require.Equal(t, expect, actual)
+
+	SetHashKey(nil)
+	require.NoError(t, SetRandomHashKey())
+	actual, err = transformer.TransformLog(`{"a":"secret"}`)
+	require.NoError(t, err)
+	require.NotEqual(t, expect, actual)
+}
+
+func TestBuild(t *testing.T) {
+	transformer, err := Build()
+	require.NoError(t, err)
+	require.Nil(t, transformer)
+
+	for _, config := range []Config{
+		{},
+		{Rename: &RenameConfig{From: "a", To: "b"}, Copy: &CopyConfig{From: "a", To: "b"}},
+		{Rename: &RenameConfig{From: "a"}},
+		{Concat: &ConcatConfig{To: "a"}},
+		{Split: &SplitConfig{Field: "a"}},
+		{Mask: &MaskConfig{Field: "a", Method: "foo"}},
+		{Coerce: &CoerceConfig{Field: "a", Type: "foo"}},
+	} {
+		_, err := Build(config)
+		require.Error(t, err)
+	}
+}
@@ -43,8 +43,13 @@ import (
 type Message struct {
 	Payload             string `json:"payload" validate:"required,min=1"`
 	SourceIntegrationID string `json:"sourceId" validate:"required,uuid4"`
+	// LogType is an optional hint for the log type of the payload set by the sender as a message attribute
+	LogType string `json:"logType,omitempty"`
 }
 
+// LogTypeAttribute is the SQS message attribute senders can use to hint the log type of a message
+const LogTypeAttribute = "logType"
+
 const RecordDelimiter = '\n'
 
 var (
@@ -78,6 +83,9 @@ func Handle(ctx context.Context, event *events.SQSEvent) error {
 			Payload:             record.Body,
 			SourceIntegrationID: integrationID,
 		}
+		if attr, ok := record.MessageAttributes[LogTypeAttribute]; ok && attr.StringValue != nil {
+			message.LogType = *attr.StringValue
+		}
 		data, err := jsoniter.Marshal(message)
 		if err != nil {
 			return errors.Wrap(err, "failed to marshal event")
@@ -89,6 +89,12 @@ func TestShouldSendBatchRequest(t *testing.T) {
 			{
 				EventSourceARN: "arn:aws:sqs:eu-west-2:123456789012:test-queue-2",
 				Body:           "payload2",
+				MessageAttributes: map[string]events.SQSMessageAttribute{
+					LogTypeAttribute: {
+						StringValue: aws.String("AWS.CloudTrail"),
+						DataType:    "String",
+					},
+				},
 			},
 		},
 	}
@@ -110,6 +116,7 @@ func TestShouldSendBatchRequest(t *testing.T) {
 		{
 			Payload:             "payload2",
 			SourceIntegrationID: "45c378a7-2e36-4b12-8e16-2d3c49ff1372",
+			LogType:             "AWS.CloudTrail",
 		},
 	}
 	var expectedFirehoseRecords []*firehose.Record
@@ -42,6 +42,10 @@ const (
 
 	TempDatabase            = "panther_temp"
 	TempDatabaseDescription = "Holds temporary tables used for processing tasks"
+
+	// DeadLettersTable is the table in the log processing database holding log lines that failed to classify
+	DeadLettersTable            = "panther_dead_letters"
+	DeadLettersTableDescription = "Holds log lines that failed to classify with the log types of their source"
 )
 
 var Databases = map[string]string{
@@ -65,6 +69,8 @@ const (
 	RuleErrors DataType = "RuleErrors"
 	// CloudSecurity represents CloudSecurity data processed by Panther
 	CloudSecurity DataType = "CloudSecurity"
+	// DeadLetters represents log lines that failed to classify
+	DeadLetters DataType = "DeadLetters"
 )
 
 // Returns the datatype associated to this LogType
@@ -98,7 +104,7 @@ func TableName(logType string) string {
 // Returns the database in which exists the
 func DatabaseName(typ DataType) string {
 	switch typ {
-	case LogData:
+	case LogData, DeadLetters:
 		return LogProcessingDatabase
 	case RuleData:
 		return RuleMatchDatabase
@@ -0,0 +1,125 @@
+package main
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/glue"
+	awslambda "github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/go-playground/validator.v9"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/retention"
+	"github.com/panther-labs/panther/pkg/awsretry"
+	"github.com/panther-labs/panther/pkg/lambdalogger"
+)
+
+// The panther-retention-enforcer lambda runs on a schedule and expires data older than the retention policies of log types.
+
+const (
+	maxRetries = 20
+	// Stop expiring partitions before the lambda times out
+	deadlineMargin = 2 * time.Minute
+)
+
+type handler struct {
+	LogTypesAPI *logtypesapi.LogTypesAPILambdaClient
+	Enforcer    *retention.Enforcer
+	Logger      *zap.Logger
+}
+
+func (h *handler) Run(ctx context.Context, _ events.CloudWatchEvent) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-deadlineMargin))
+		defer cancel()
+	}
+	reply, err := h.LogTypesAPI.ListRetention(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list retention policies")
+	}
+	if reply.Error != nil {
+		return logtypesapi.NewAPIError(reply.Error.Code, reply.Error.Message)
+	}
+	report, err := h.Enforcer.Run(ctx, time.Now(), reply.Records...)
+	if err != nil {
+		// Partitions left in place are expired by the next run
+		if ctx.Err() == context.DeadlineExceeded {
+			h.Logger.Warn("retention stopped before the lambda deadline", zap.Int("numPartitions", report.NumPartitions))
+			return nil
+		}
+		return err
+	}
+	h.Logger.Info("retention enforced",
+		zap.Int("numPartitions", report.NumPartitions),
+		zap.Int("numObjects", report.NumObjects),
+		zap.Int64("numBytes", report.NumBytes),
+		zap.Bool("dryRun", h.Enforcer.DryRun),
+	)
+	return nil
+}
+
+func main() {
+	config := struct {
+		Debug  bool `split_words:"true"`
+		DryRun bool `split_words:"true"`
+	}{}
+	envconfig.MustProcess("", &config)
+
+	logger := lambdalogger.Config{
+		Debug:     config.Debug,
+		Namespace: "log_analysis",
+		Component: "retention_enforcer",
+	}.MustBuild()
+
+	awsSession := session.Must(session.NewSession()) // use default retries for fetching creds, avoids hangs!
+	clientsSession := awsSession.Copy(
+		request.WithRetryer(
+			aws.NewConfig().WithMaxRetries(maxRetries),
+			awsretry.NewConnectionErrRetryer(maxRetries),
+		),
+	)
+
+	h := handler{
+		LogTypesAPI: &logtypesapi.LogTypesAPILambdaClient{
+			LambdaName: logtypesapi.LambdaName,
+			LambdaAPI:  awslambda.New(awsSession),
+			Validate:   validator.New().Struct,
+		},
+		Enforcer: &retention.Enforcer{
+			GlueAPI: glue.New(clientsSession),
+			S3API:   s3.New(clientsSession),
+			Logger:  logger,
+			DryRun:  config.DryRun,
+		},
+		Logger: logger,
+	}
+	lambda.Start(h.Run)
+}
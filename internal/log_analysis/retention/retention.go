@@ -0,0 +1,86 @@
+package retention
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package retention enforces the retention policies of log types stored in the logtypes API.
+//
+// Each policy applies to the table of a log type in one of the Panther databases.
+// Hourly partitions whose data are all older than the retention period are expired using gluetasks.ExpireTablePartitions.
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/glue/glueiface"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/gluetasks"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+// Enforcer removes the data of log types that are older than their retention policies
+type Enforcer struct {
+	GlueAPI glueiface.GlueAPI
+	S3API   s3iface.S3API
+	Logger  *zap.Logger
+	// DryRun is a flag to only report the partitions that would expire
+	DryRun bool
+}
+
+// Run enforces the retention policies of the records.
+//
+// The report lists the partitions expired before any error occurred.
+func (e *Enforcer) Run(ctx context.Context, now time.Time, records ...*logtypesapi.RetentionRecord) (*gluetasks.RetentionReport, error) {
+	report := gluetasks.RetentionReport{}
+	for _, task := range Tasks(now, records...) {
+		task.DryRun = e.DryRun
+		err := task.Run(ctx, e.GlueAPI, e.S3API, e.Logger)
+		report.Merge(&task.Report)
+		if err != nil {
+			return &report, err
+		}
+	}
+	return &report, nil
+}
+
+// Tasks returns the expiration tasks for the retention policies of the records at a point in time
+func Tasks(now time.Time, records ...*logtypesapi.RetentionRecord) []*gluetasks.ExpireTablePartitions {
+	var tasks []*gluetasks.ExpireTablePartitions
+	for _, record := range records {
+		for dbName, policy := range record.Policies() {
+			tasks = append(tasks, &gluetasks.ExpireTablePartitions{
+				DatabaseName:        dbName,
+				TableName:           pantherdb.TableName(record.LogType),
+				Before:              now.UTC().AddDate(0, 0, -policy.Days),
+				ArchiveStorageClass: policy.ArchiveStorageClass,
+			})
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if a.TableName != b.TableName {
+			return a.TableName < b.TableName
+		}
+		return a.DatabaseName < b.DatabaseName
+	})
+	return tasks
+}
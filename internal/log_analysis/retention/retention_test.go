@@ -0,0 +1,57 @@
+package retention
+
+/**
+ * Panther is a Cloud-Native SIEM for the Modern Security Team.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/internal/core/logtypesapi"
+	"github.com/panther-labs/panther/internal/log_analysis/gluetasks"
+	"github.com/panther-labs/panther/internal/log_analysis/pantherdb"
+)
+
+func TestTasks(t *testing.T) {
+	now := time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC)
+	tasks := Tasks(now,
+		&logtypesapi.RetentionRecord{
+			LogType:    "Custom.Foo",
+			Logs:       &logtypesapi.RetentionPolicy{Days: 30, ArchiveStorageClass: logtypesapi.ArchiveGlacier},
+			RuleErrors: &logtypesapi.RetentionPolicy{Days: 7},
+		},
+		&logtypesapi.RetentionRecord{
+			LogType: "AWS.CloudTrail",
+		},
+	)
+	require.Equal(t, []*gluetasks.ExpireTablePartitions{
+		{
+			DatabaseName:        pantherdb.LogProcessingDatabase,
+			TableName:           "custom_foo",
+			Before:              time.Date(2020, 1, 31, 10, 30, 0, 0, time.UTC),
+			ArchiveStorageClass: logtypesapi.ArchiveGlacier,
+		},
+		{
+			DatabaseName: pantherdb.RuleErrorsDatabase,
+			TableName:    "custom_foo",
+			Before:       time.Date(2020, 2, 23, 10, 30, 0, 0, time.UTC),
+		},
+	}, tasks)
+}
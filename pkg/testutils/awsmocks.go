@@ -71,6 +71,27 @@ func (m *S3Mock) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsO
 	return args.Get(0).(*s3.DeleteObjectsOutput), args.Error(1)
 }
 
+func (m *S3Mock) DeleteObjectsWithContext(ctx aws.Context, input *s3.DeleteObjectsInput,
+	_ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.DeleteObjectsOutput), args.Error(1)
+}
+
+func (m *S3Mock) CopyObjectWithContext(ctx aws.Context, input *s3.CopyObjectInput,
+	_ ...request.Option) (*s3.CopyObjectOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.CopyObjectOutput), args.Error(1)
+}
+
+func (m *S3Mock) DeleteObjectWithContext(ctx aws.Context, input *s3.DeleteObjectInput,
+	options ...request.Option) (*s3.DeleteObjectOutput, error) {
+
+	args := m.Called(ctx, input, options)
+	return args.Get(0).(*s3.DeleteObjectOutput), args.Error(1)
+}
+
 func (m *S3Mock) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
@@ -119,6 +140,14 @@ func (m *S3Mock) ListObjectsV2PagesWithContext(ctx aws.Context, input *s3.ListOb
 	return args.Error(1)
 }
 
+func (m *S3Mock) ListObjectVersionsPagesWithContext(ctx aws.Context, input *s3.ListObjectVersionsInput,
+	f func(page *s3.ListObjectVersionsOutput, morePages bool) bool, options ...request.Option) error {
+
+	args := m.Called(ctx, input, f, options)
+	f(args.Get(0).(*s3.ListObjectVersionsOutput), false)
+	return args.Error(1)
+}
+
 func (m *S3Mock) SelectObjectContent(input *s3.SelectObjectContentInput) (*s3.SelectObjectContentOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*s3.SelectObjectContentOutput), args.Error(1)
@@ -396,6 +425,20 @@ func (m *GlueMock) UpdatePartition(input *glue.UpdatePartitionInput) (*glue.Upda
 	return args.Get(0).(*glue.UpdatePartitionOutput), args.Error(1)
 }
 
+func (m *GlueMock) UpdatePartitionWithContext(ctx context.Context,
+	input *glue.UpdatePartitionInput, _ ...request.Option) (*glue.UpdatePartitionOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*glue.UpdatePartitionOutput), args.Error(1)
+}
+
+func (m *GlueMock) DeletePartitionWithContext(ctx context.Context,
+	input *glue.DeletePartitionInput, _ ...request.Option) (*glue.DeletePartitionOutput, error) {
+
+	args := m.Called(ctx, input)
+	return args.Get(0).(*glue.DeletePartitionOutput), args.Error(1)
+}
+
 // nolint:lll
 func (m *GlueMock) GetTablesPagesWithContext(
 	ctx aws.Context,
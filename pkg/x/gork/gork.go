@@ -20,6 +20,7 @@ package gork
 
 import (
 	"bufio"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"regexp"
@@ -51,6 +52,12 @@ func (p *Pattern) String() string {
 	return p.src
 }
 
+// Names returns the field names of the pattern in the order they are matched.
+// Unnamed groups have an empty name.
+func (p *Pattern) Names() []string {
+	return p.names
+}
+
 // MatchString matches src appending key/value pairs to dst.
 // If the text does not match an error is return
 func (p *Pattern) MatchString(dst []string, src string) ([]string, error) {
@@ -165,6 +172,20 @@ func (e *Env) SetMap(patterns map[string]string) error {
 	return nil
 }
 
+// Override adds multiple patterns to an environment replacing existing patterns with the same name.
+// Patterns compiled before the override keep using the previous definitions.
+func (e *Env) Override(patterns map[string]string) error {
+	child := e.Clone()
+	for name := range patterns {
+		delete(child.patterns, name)
+	}
+	if err := child.SetMap(patterns); err != nil {
+		return err
+	}
+	e.patterns = child.patterns
+	return nil
+}
+
 // Clone clones an environment
 func (e *Env) Clone() *Env {
 	patterns := make(map[string]*Pattern, len(e.patterns))
@@ -203,9 +224,65 @@ func (e *Env) Compile(pattern string) (*Pattern, error) {
 
 var (
 	validPatternName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
-	validFieldName   = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	validGroupName   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// Oniguruma style named groups used in Logstash patterns
+	namedGroup = regexp.MustCompile(`\(\?<([^>=!][^>]*)>`)
 )
 
+// Regular expression group names can only contain word characters.
+// Other field names (ie Logstash field references like `[source][address]`) are hex encoded.
+const encodedGroupPrefix = "gork__"
+
+func groupName(field string) string {
+	if validGroupName.MatchString(field) && !strings.HasPrefix(field, encodedGroupPrefix) {
+		return field
+	}
+	return encodedGroupPrefix + hex.EncodeToString([]byte(field))
+}
+
+func fieldName(group string) string {
+	if !strings.HasPrefix(group, encodedGroupPrefix) {
+		return group
+	}
+	name, err := hex.DecodeString(strings.TrimPrefix(group, encodedGroupPrefix))
+	if err != nil {
+		return group
+	}
+	return string(name)
+}
+
+// rewriteNamedGroups converts `(?<name>...)` groups to `(?P<name>...)` groups
+func rewriteNamedGroups(src string) string {
+	matches := namedGroup.FindAllStringSubmatchIndex(src, -1)
+	if matches == nil {
+		return src
+	}
+	s := strings.Builder{}
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		// Skip escaped parentheses
+		if isEscaped(src, start) {
+			continue
+		}
+		s.WriteString(src[last:start])
+		s.WriteString("(?P<")
+		s.WriteString(groupName(src[m[2]:m[3]]))
+		s.WriteString(">")
+		last = end
+	}
+	s.WriteString(src[last:])
+	return s.String()
+}
+
+func isEscaped(src string, pos int) bool {
+	n := 0
+	for i := pos - 1; i >= 0 && src[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
 func (e *Env) compile(root, src string, patterns map[string]string, visited []string) (*Pattern, error) {
 	tpl := fasttemplate.New(src, startDelimiter, endDelimiter)
 	s := strings.Builder{}
@@ -216,7 +293,7 @@ func (e *Env) compile(root, src string, patterns map[string]string, visited []st
 		if !validPatternName.MatchString(name) {
 			return 0, errors.Errorf("invalid pattern name %q in tag %q of pattern %q", name, tag, root)
 		}
-		if field != "" && !validFieldName.MatchString(field) {
+		if field != "" && strings.TrimSpace(field) != field {
 			return 0, errors.Errorf("invalid field name %q in tag %q of pattern %q", field, tag, root)
 		}
 		for _, visited := range visited {
@@ -243,7 +320,7 @@ func (e *Env) compile(root, src string, patterns map[string]string, visited []st
 		if field == "" {
 			group = fmt.Sprintf("(?:%s)", expr.Regexp())
 		} else {
-			group = fmt.Sprintf("(?P<%s>%s)", field, expr.Regexp())
+			group = fmt.Sprintf("(?P<%s>%s)", groupName(field), expr.Regexp())
 		}
 		return w.Write([]byte(group))
 	})
@@ -251,14 +328,19 @@ func (e *Env) compile(root, src string, patterns map[string]string, visited []st
 		return nil, errors.Wrapf(err, "failed to expand pattern %q", root)
 	}
 
-	expr, err := regexp.Compile(s.String())
+	expr, err := regexp.Compile(rewriteNamedGroups(s.String()))
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to compile pattern %q", root)
 	}
+	// The slice returned by SubexpNames must not be modified
+	names := append([]string(nil), expr.SubexpNames()[1:]...)
+	for i, name := range names {
+		names[i] = fieldName(name)
+	}
 	return &Pattern{
 		src:   src,
 		expr:  expr,
-		names: expr.SubexpNames()[1:],
+		names: names,
 	}, nil
 }
 
@@ -279,10 +361,19 @@ func (e *Env) checkDuplicate(name string) error {
 	return nil
 }
 
+// splitTag splits a `PATTERN:field` tag.
+// Logstash style type conversions (`PATTERN:field:int`) are ignored since values are converted by the log schema.
 func splitTag(tag string) (pattern, field string) {
 	tag = strings.TrimSpace(tag)
 	if pos := strings.IndexByte(tag, ':'); 0 <= pos && pos < len(tag) {
-		return tag[:pos], tag[pos+1:]
+		pattern, field = tag[:pos], tag[pos+1:]
+		if pos := strings.LastIndexByte(field, ':'); pos != -1 {
+			switch field[pos+1:] {
+			case "int", "float":
+				field = field[:pos]
+			}
+		}
+		return pattern, field
 	}
 	return tag, ""
 }
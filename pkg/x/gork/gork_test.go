@@ -67,3 +67,55 @@ BAR %{FOO}`
 		assert.Contains(err.Error(), "recursive")
 	}
 }
+
+func TestLogstashCompat(t *testing.T) {
+	assert := require.New(t)
+	env := New()
+	patterns := `
+POSTFIX_QUEUEID (?<queue_id>[0-9A-F]{10,11})
+`
+	assert.NoError(env.ReadPatterns(strings.NewReader(patterns)))
+	src := `%{POSTFIX_QUEUEID}: %{IP:[source][address]} %{NUMBER:bytes:int} \(?<not_a_group>%{WORD:word}`
+	pattern, err := env.Compile(src)
+	assert.NoError(err)
+	var names []string
+	for _, name := range pattern.Names() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	assert.Equal([]string{"queue_id", "[source][address]", "bytes", "word"}, names)
+	matches, err := pattern.MatchString(nil, "ABCDEF01234: 10.0.0.1 42 (<not_a_group>foo")
+	assert.NoError(err)
+	assert.Equal([]string{
+		"queue_id", "ABCDEF01234",
+		"[source][address]", "10.0.0.1",
+		"bytes", "42",
+		"word", "foo",
+	}, matches)
+}
+
+func TestOverride(t *testing.T) {
+	assert := require.New(t)
+	env := New()
+	assert.Error(env.SetMap(map[string]string{"WORD": `[a-z]+`}))
+	assert.NoError(env.Override(map[string]string{"WORD": `[a-z]+`}))
+	pattern, err := env.Compile(`%{WORD:word}`)
+	assert.NoError(err)
+	matches, err := pattern.MatchString(nil, "FOObar")
+	assert.NoError(err)
+	assert.Equal([]string{"word", "bar"}, matches)
+}
+
+func TestFieldReferenceNames(t *testing.T) {
+	assert := require.New(t)
+	env := New()
+	pattern, err := env.Compile(`%{WORD:[source][name]} %{INT:count}`)
+	assert.NoError(err)
+	assert.Equal([]string{"[source][name]", "count"}, pattern.Names())
+	// The group names of the compiled expression are left encoded
+	assert.Equal(groupName("[source][name]"), pattern.expr.SubexpNames()[1])
+	matches, err := pattern.MatchString(nil, "foo 42")
+	assert.NoError(err)
+	assert.Equal([]string{"[source][name]", "foo", "count", "42"}, matches)
+}
@@ -38,6 +38,8 @@ type Infra struct {
 	LoadBalancerSecurityGroupCidr      string   `yaml:"LoadBalancerSecurityGroupCidr"`
 	LogProcessorLambdaMemorySize       int      `yaml:"LogProcessorLambdaMemorySize"`
 	LogProcessorLambdaSQSReadBatchSize string   `yaml:"LogProcessorLambdaSQSReadBatchSize"`
+	ParquetLogTypes                    []string `yaml:"ParquetLogTypes"`
+	ManifestLogTypes                   []string `yaml:"ManifestLogTypes"`
 	PipLayer                           []string `yaml:"PipLayer"`
 	KvTableBillingMode                 string   `yaml:"KvTableBillingMode"`
 	PythonLayerVersionArn              string   `yaml:"PythonLayerVersionArn"`
@@ -457,6 +457,7 @@ func deployBootstrapGatewayStack(
 		"LayerVersionArns":           settings.Infra.BaseLayerVersionArns,
 		"ProcessedDataBucket":        outputs["ProcessedDataBucket"],
 		"PythonLayerVersionArn":      settings.Infra.PythonLayerVersionArn,
+		"RedactionKeyId":             outputs["RedactionEncryptionKeyId"],
 		"SqsKeyId":                   outputs["QueueEncryptionKeyId"],
 		"TracingMode":                settings.Monitoring.TracingMode,
 		"UserPoolId":                 outputs["UserPoolId"],
@@ -542,11 +543,14 @@ func deployLogAnalysisStack(settings *PantherConfig, packager *pkg.Packager, out
 		"LayerVersionArns":                   settings.Infra.BaseLayerVersionArns,
 		"LogProcessorLambdaMemorySize":       strconv.Itoa(settings.Infra.LogProcessorLambdaMemorySize),
 		"LogProcessorLambdaSQSReadBatchSize": settings.Infra.LogProcessorLambdaSQSReadBatchSize,
+		"ParquetLogTypes":                    strings.Join(settings.Infra.ParquetLogTypes, ","),
+		"ManifestLogTypes":                   strings.Join(settings.Infra.ManifestLogTypes, ","),
 		"ProcessedDataBucket":                outputs["ProcessedDataBucket"],
 		"ProcessedDataTopicArn":              outputs["ProcessedDataTopicArn"],
 		"PythonAssumableRoleArns":            strings.Join(settings.Infra.PythonAssumableRoleArns, ","),
 		"PythonLayerVersionArn":              outputs["PythonLayerVersionArn"],
 		"PythonManagedPolicyArn":             settings.Infra.PythonManagedPolicyArn,
+		"RedactionKeyId":                     outputs["RedactionEncryptionKeyId"],
 		"SqsKeyId":                           outputs["QueueEncryptionKeyId"],
 		"TracingMode":                        settings.Monitoring.TracingMode,
 	})
@@ -342,10 +342,33 @@ export type CustomLogRecord = {
 export type CustomWebhookConfig = {
   __typename?: 'CustomWebhookConfig';
   webhookURL: Scalars['String'];
+  method?: Maybe<Scalars['String']>;
+  bodyTemplate?: Maybe<Scalars['String']>;
+  headers?: Maybe<Array<CustomWebhookHeader>>;
+  signatureHeader?: Maybe<Scalars['String']>;
+  signatureSecret?: Maybe<Scalars['String']>;
 };
 
 export type CustomWebhookConfigInput = {
   webhookURL: Scalars['String'];
+  method?: Maybe<Scalars['String']>;
+  bodyTemplate?: Maybe<Scalars['String']>;
+  headers?: Maybe<Array<CustomWebhookHeaderInput>>;
+  signatureHeader?: Maybe<Scalars['String']>;
+  signatureSecret?: Maybe<Scalars['String']>;
+};
+
+export type CustomWebhookHeader = {
+  __typename?: 'CustomWebhookHeader';
+  name: Scalars['String'];
+  value: Scalars['String'];
+  secret?: Maybe<Scalars['Boolean']>;
+};
+
+export type CustomWebhookHeaderInput = {
+  name: Scalars['String'];
+  value: Scalars['String'];
+  secret?: Maybe<Scalars['Boolean']>;
 };
 
 export type DataModel = {
@@ -404,6 +427,19 @@ export type DeliverAlertInput = {
   outputIds: Array<Scalars['ID']>;
 };
 
+export type DeliveryPolicy = {
+  __typename?: 'DeliveryPolicy';
+  rateLimit?: Maybe<RateLimit>;
+  digestWindowMinutes?: Maybe<Scalars['Int']>;
+  quietHours?: Maybe<QuietHours>;
+};
+
+export type DeliveryPolicyInput = {
+  rateLimit?: Maybe<RateLimitInput>;
+  digestWindowMinutes?: Maybe<Scalars['Int']>;
+  quietHours?: Maybe<QuietHoursInput>;
+};
+
 export type DeliveryResponse = {
   __typename?: 'DeliveryResponse';
   outputId: Scalars['ID'];
@@ -426,6 +462,8 @@ export type Destination = {
   verificationStatus?: Maybe<Scalars['String']>;
   defaultForSeverity: Array<Maybe<SeverityEnum>>;
   alertTypes: Array<AlertTypesEnum>;
+  deliveryPolicy?: Maybe<DeliveryPolicy>;
+  routingRules?: Maybe<Array<RoutingRule>>;
 };
 
 export type DestinationConfig = {
@@ -440,6 +478,10 @@ export type DestinationConfig = {
   msTeams?: Maybe<MsTeamsConfig>;
   asana?: Maybe<AsanaConfig>;
   customWebhook?: Maybe<CustomWebhookConfig>;
+  email?: Maybe<EmailConfig>;
+  googleChat?: Maybe<GoogleChatConfig>;
+  mattermost?: Maybe<MattermostConfig>;
+  webex?: Maybe<WebexConfig>;
 };
 
 export type DestinationConfigInput = {
@@ -453,6 +495,10 @@ export type DestinationConfigInput = {
   msTeams?: Maybe<MsTeamsConfigInput>;
   asana?: Maybe<AsanaConfigInput>;
   customWebhook?: Maybe<CustomWebhookConfigInput>;
+  email?: Maybe<EmailConfigInput>;
+  googleChat?: Maybe<GoogleChatConfigInput>;
+  mattermost?: Maybe<MattermostConfigInput>;
+  webex?: Maybe<WebexConfigInput>;
 };
 
 export type DestinationInput = {
@@ -462,6 +508,8 @@ export type DestinationInput = {
   outputType: Scalars['String'];
   defaultForSeverity: Array<Maybe<SeverityEnum>>;
   alertTypes: Array<Maybe<AlertTypesEnum>>;
+  deliveryPolicy?: Maybe<DeliveryPolicyInput>;
+  routingRules?: Maybe<Array<RoutingRuleInput>>;
 };
 
 export enum DestinationTypeEnum {
@@ -475,6 +523,10 @@ export enum DestinationTypeEnum {
   Sqs = 'sqs',
   Asana = 'asana',
   Customwebhook = 'customwebhook',
+  Email = 'email',
+  Googlechat = 'googlechat',
+  Mattermost = 'mattermost',
+  Webex = 'webex',
 }
 
 export type Detection = {
@@ -515,6 +567,31 @@ export enum DetectionTypeEnum {
   Policy = 'POLICY',
 }
 
+export type EmailConfig = {
+  __typename?: 'EmailConfig';
+  from: Scalars['String'];
+  to: Array<Scalars['String']>;
+  transport?: Maybe<Scalars['String']>;
+  sesRegion?: Maybe<Scalars['String']>;
+  smtpHost?: Maybe<Scalars['String']>;
+  smtpPort?: Maybe<Scalars['Int']>;
+  smtpTls?: Maybe<Scalars['String']>;
+  smtpUserName?: Maybe<Scalars['String']>;
+  smtpPassword?: Maybe<Scalars['String']>;
+};
+
+export type EmailConfigInput = {
+  from: Scalars['String'];
+  to: Array<Scalars['String']>;
+  transport?: Maybe<Scalars['String']>;
+  sesRegion?: Maybe<Scalars['String']>;
+  smtpHost?: Maybe<Scalars['String']>;
+  smtpPort?: Maybe<Scalars['Int']>;
+  smtpTls?: Maybe<Scalars['String']>;
+  smtpUserName?: Maybe<Scalars['String']>;
+  smtpPassword?: Maybe<Scalars['String']>;
+};
+
 export type Error = {
   __typename?: 'Error';
   code?: Maybe<Scalars['String']>;
@@ -616,6 +693,15 @@ export type GlobalPythonModule = {
   lastModified: Scalars['AWSDateTime'];
 };
 
+export type GoogleChatConfig = {
+  __typename?: 'GoogleChatConfig';
+  webhookURL: Scalars['String'];
+};
+
+export type GoogleChatConfigInput = {
+  webhookURL: Scalars['String'];
+};
+
 export type IntegrationItemHealthStatus = {
   __typename?: 'IntegrationItemHealthStatus';
   healthy: Scalars['Boolean'];
@@ -866,6 +952,17 @@ export type ManagedS3Resources = {
   topicARN?: Maybe<Scalars['String']>;
 };
 
+export type MattermostConfig = {
+  __typename?: 'MattermostConfig';
+  webhookURL: Scalars['String'];
+  channel?: Maybe<Scalars['String']>;
+};
+
+export type MattermostConfigInput = {
+  webhookURL: Scalars['String'];
+  channel?: Maybe<Scalars['String']>;
+};
+
 export enum MessageActionEnum {
   Resend = 'RESEND',
   Suppress = 'SUPPRESS',
@@ -1305,6 +1402,32 @@ export type QueryGetCustomLogArgs = {
   input: GetCustomLogInput;
 };
 
+export type QuietHours = {
+  __typename?: 'QuietHours';
+  start: Scalars['String'];
+  end: Scalars['String'];
+  timeZone?: Maybe<Scalars['String']>;
+  severities?: Maybe<Array<SeverityEnum>>;
+};
+
+export type QuietHoursInput = {
+  start: Scalars['String'];
+  end: Scalars['String'];
+  timeZone?: Maybe<Scalars['String']>;
+  severities?: Maybe<Array<SeverityEnum>>;
+};
+
+export type RateLimit = {
+  __typename?: 'RateLimit';
+  alertsPerMinute: Scalars['Float'];
+  burst: Scalars['Int'];
+};
+
+export type RateLimitInput = {
+  alertsPerMinute: Scalars['Float'];
+  burst: Scalars['Int'];
+};
+
 export type RemediateResourceInput = {
   policyId: Scalars['ID'];
   resourceId: Scalars['ID'];
@@ -1340,6 +1463,40 @@ export type ResourceSummary = {
   type?: Maybe<Scalars['String']>;
 };
 
+export type RoutingContextMatch = {
+  __typename?: 'RoutingContextMatch';
+  key: Scalars['String'];
+  values: Array<Scalars['String']>;
+};
+
+export type RoutingContextMatchInput = {
+  key: Scalars['String'];
+  values: Array<Scalars['String']>;
+};
+
+export type RoutingRule = {
+  __typename?: 'RoutingRule';
+  priority: Scalars['Int'];
+  logTypes?: Maybe<Array<Scalars['String']>>;
+  tags?: Maybe<Array<Scalars['String']>>;
+  analysisIds?: Maybe<Array<Scalars['String']>>;
+  resourceTypes?: Maybe<Array<Scalars['String']>>;
+  awsAccountIds?: Maybe<Array<Scalars['String']>>;
+  context?: Maybe<Array<RoutingContextMatch>>;
+  stopProcessing?: Maybe<Scalars['Boolean']>;
+};
+
+export type RoutingRuleInput = {
+  priority: Scalars['Int'];
+  logTypes?: Maybe<Array<Scalars['String']>>;
+  tags?: Maybe<Array<Scalars['String']>>;
+  analysisIds?: Maybe<Array<Scalars['String']>>;
+  resourceTypes?: Maybe<Array<Scalars['String']>>;
+  awsAccountIds?: Maybe<Array<Scalars['String']>>;
+  context?: Maybe<Array<RoutingContextMatchInput>>;
+  stopProcessing?: Maybe<Scalars['Boolean']>;
+};
+
 export type Rule = Detection & {
   __typename?: 'Rule';
   body: Scalars['String'];
@@ -1684,6 +1841,17 @@ export type User = {
   status: Scalars['String'];
 };
 
+export type WebexConfig = {
+  __typename?: 'WebexConfig';
+  botToken: Scalars['String'];
+  roomId: Scalars['String'];
+};
+
+export type WebexConfigInput = {
+  botToken: Scalars['String'];
+  roomId: Scalars['String'];
+};
+
 export type ResolverTypeWrapper<T> = Promise<T> | T;
 
 export type LegacyStitchingResolver<TResult, TParent, TContext, TArgs> = {
@@ -1831,6 +1999,16 @@ export type ResolversTypes = {
   MsTeamsConfig: ResolverTypeWrapper<MsTeamsConfig>;
   AsanaConfig: ResolverTypeWrapper<AsanaConfig>;
   CustomWebhookConfig: ResolverTypeWrapper<CustomWebhookConfig>;
+  CustomWebhookHeader: ResolverTypeWrapper<CustomWebhookHeader>;
+  EmailConfig: ResolverTypeWrapper<EmailConfig>;
+  GoogleChatConfig: ResolverTypeWrapper<GoogleChatConfig>;
+  MattermostConfig: ResolverTypeWrapper<MattermostConfig>;
+  WebexConfig: ResolverTypeWrapper<WebexConfig>;
+  DeliveryPolicy: ResolverTypeWrapper<DeliveryPolicy>;
+  RateLimit: ResolverTypeWrapper<RateLimit>;
+  QuietHours: ResolverTypeWrapper<QuietHours>;
+  RoutingRule: ResolverTypeWrapper<RoutingRule>;
+  RoutingContextMatch: ResolverTypeWrapper<RoutingContextMatch>;
   GeneralSettings: ResolverTypeWrapper<GeneralSettings>;
   ComplianceIntegration: ResolverTypeWrapper<ComplianceIntegration>;
   ComplianceIntegrationHealth: ResolverTypeWrapper<ComplianceIntegrationHealth>;
@@ -1912,6 +2090,16 @@ export type ResolversTypes = {
   MsTeamsConfigInput: MsTeamsConfigInput;
   AsanaConfigInput: AsanaConfigInput;
   CustomWebhookConfigInput: CustomWebhookConfigInput;
+  CustomWebhookHeaderInput: CustomWebhookHeaderInput;
+  EmailConfigInput: EmailConfigInput;
+  GoogleChatConfigInput: GoogleChatConfigInput;
+  MattermostConfigInput: MattermostConfigInput;
+  WebexConfigInput: WebexConfigInput;
+  DeliveryPolicyInput: DeliveryPolicyInput;
+  RateLimitInput: RateLimitInput;
+  QuietHoursInput: QuietHoursInput;
+  RoutingRuleInput: RoutingRuleInput;
+  RoutingContextMatchInput: RoutingContextMatchInput;
   AddComplianceIntegrationInput: AddComplianceIntegrationInput;
   AddS3LogIntegrationInput: AddS3LogIntegrationInput;
   S3PrefixLogTypesInput: S3PrefixLogTypesInput;
@@ -2023,6 +2211,16 @@ export type ResolversParentTypes = {
   MsTeamsConfig: MsTeamsConfig;
   AsanaConfig: AsanaConfig;
   CustomWebhookConfig: CustomWebhookConfig;
+  CustomWebhookHeader: CustomWebhookHeader;
+  EmailConfig: EmailConfig;
+  GoogleChatConfig: GoogleChatConfig;
+  MattermostConfig: MattermostConfig;
+  WebexConfig: WebexConfig;
+  DeliveryPolicy: DeliveryPolicy;
+  RateLimit: RateLimit;
+  QuietHours: QuietHours;
+  RoutingRule: RoutingRule;
+  RoutingContextMatch: RoutingContextMatch;
   GeneralSettings: GeneralSettings;
   ComplianceIntegration: ComplianceIntegration;
   ComplianceIntegrationHealth: ComplianceIntegrationHealth;
@@ -2106,6 +2304,16 @@ export type ResolversParentTypes = {
   MsTeamsConfigInput: MsTeamsConfigInput;
   AsanaConfigInput: AsanaConfigInput;
   CustomWebhookConfigInput: CustomWebhookConfigInput;
+  CustomWebhookHeaderInput: CustomWebhookHeaderInput;
+  EmailConfigInput: EmailConfigInput;
+  GoogleChatConfigInput: GoogleChatConfigInput;
+  MattermostConfigInput: MattermostConfigInput;
+  WebexConfigInput: WebexConfigInput;
+  DeliveryPolicyInput: DeliveryPolicyInput;
+  RateLimitInput: RateLimitInput;
+  QuietHoursInput: QuietHoursInput;
+  RoutingRuleInput: RoutingRuleInput;
+  RoutingContextMatchInput: RoutingContextMatchInput;
   AddComplianceIntegrationInput: AddComplianceIntegrationInput;
   AddS3LogIntegrationInput: AddS3LogIntegrationInput;
   S3PrefixLogTypesInput: S3PrefixLogTypesInput;
@@ -2479,6 +2687,25 @@ export type CustomWebhookConfigResolvers<
   ParentType extends ResolversParentTypes['CustomWebhookConfig'] = ResolversParentTypes['CustomWebhookConfig']
 > = {
   webhookURL?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  method?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  bodyTemplate?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  headers?: Resolver<
+    Maybe<Array<ResolversTypes['CustomWebhookHeader']>>,
+    ParentType,
+    ContextType
+  >;
+  signatureHeader?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  signatureSecret?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
+export type CustomWebhookHeaderResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['CustomWebhookHeader'] = ResolversParentTypes['CustomWebhookHeader']
+> = {
+  name?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  value?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  secret?: Resolver<Maybe<ResolversTypes['Boolean']>, ParentType, ContextType>;
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
@@ -2515,6 +2742,16 @@ export type DeleteCustomLogOutputResolvers<
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
+export type DeliveryPolicyResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['DeliveryPolicy'] = ResolversParentTypes['DeliveryPolicy']
+> = {
+  rateLimit?: Resolver<Maybe<ResolversTypes['RateLimit']>, ParentType, ContextType>;
+  digestWindowMinutes?: Resolver<Maybe<ResolversTypes['Int']>, ParentType, ContextType>;
+  quietHours?: Resolver<Maybe<ResolversTypes['QuietHours']>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type DeliveryResponseResolvers<
   ContextType = any,
   ParentType extends ResolversParentTypes['DeliveryResponse'] = ResolversParentTypes['DeliveryResponse']
@@ -2546,6 +2783,8 @@ export type DestinationResolvers<
     ContextType
   >;
   alertTypes?: Resolver<Array<ResolversTypes['AlertTypesEnum']>, ParentType, ContextType>;
+  deliveryPolicy?: Resolver<Maybe<ResolversTypes['DeliveryPolicy']>, ParentType, ContextType>;
+  routingRules?: Resolver<Maybe<Array<ResolversTypes['RoutingRule']>>, ParentType, ContextType>;
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
@@ -2563,6 +2802,10 @@ export type DestinationConfigResolvers<
   msTeams?: Resolver<Maybe<ResolversTypes['MsTeamsConfig']>, ParentType, ContextType>;
   asana?: Resolver<Maybe<ResolversTypes['AsanaConfig']>, ParentType, ContextType>;
   customWebhook?: Resolver<Maybe<ResolversTypes['CustomWebhookConfig']>, ParentType, ContextType>;
+  email?: Resolver<Maybe<ResolversTypes['EmailConfig']>, ParentType, ContextType>;
+  googleChat?: Resolver<Maybe<ResolversTypes['GoogleChatConfig']>, ParentType, ContextType>;
+  mattermost?: Resolver<Maybe<ResolversTypes['MattermostConfig']>, ParentType, ContextType>;
+  webex?: Resolver<Maybe<ResolversTypes['WebexConfig']>, ParentType, ContextType>;
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
@@ -2600,6 +2843,22 @@ export type DetectionTestDefinitionResolvers<
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
+export type EmailConfigResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['EmailConfig'] = ResolversParentTypes['EmailConfig']
+> = {
+  from?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  to?: Resolver<Array<ResolversTypes['String']>, ParentType, ContextType>;
+  transport?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  sesRegion?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  smtpHost?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  smtpPort?: Resolver<Maybe<ResolversTypes['Int']>, ParentType, ContextType>;
+  smtpTls?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  smtpUserName?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  smtpPassword?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type ErrorResolvers<
   ContextType = any,
   ParentType extends ResolversParentTypes['Error'] = ResolversParentTypes['Error']
@@ -2668,6 +2927,14 @@ export type GlobalPythonModuleResolvers<
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
+export type GoogleChatConfigResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['GoogleChatConfig'] = ResolversParentTypes['GoogleChatConfig']
+> = {
+  webhookURL?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type IntegrationItemHealthStatusResolvers<
   ContextType = any,
   ParentType extends ResolversParentTypes['IntegrationItemHealthStatus'] = ResolversParentTypes['IntegrationItemHealthStatus']
@@ -2837,6 +3104,15 @@ export type ManagedS3ResourcesResolvers<
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
+export type MattermostConfigResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['MattermostConfig'] = ResolversParentTypes['MattermostConfig']
+> = {
+  webhookURL?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  channel?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type MsTeamsConfigResolvers<
   ContextType = any,
   ParentType extends ResolversParentTypes['MsTeamsConfig'] = ResolversParentTypes['MsTeamsConfig']
@@ -3342,6 +3618,26 @@ export type QueryResolvers<
   listCustomLogs?: Resolver<Array<ResolversTypes['CustomLogRecord']>, ParentType, ContextType>;
 };
 
+export type QuietHoursResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['QuietHours'] = ResolversParentTypes['QuietHours']
+> = {
+  start?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  end?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  timeZone?: Resolver<Maybe<ResolversTypes['String']>, ParentType, ContextType>;
+  severities?: Resolver<Maybe<Array<ResolversTypes['SeverityEnum']>>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
+export type RateLimitResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['RateLimit'] = ResolversParentTypes['RateLimit']
+> = {
+  alertsPerMinute?: Resolver<ResolversTypes['Float'], ParentType, ContextType>;
+  burst?: Resolver<ResolversTypes['Int'], ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type ResourceDetailsResolvers<
   ContextType = any,
   ParentType extends ResolversParentTypes['ResourceDetails'] = ResolversParentTypes['ResourceDetails']
@@ -3378,6 +3674,34 @@ export type ResourceSummaryResolvers<
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
+export type RoutingContextMatchResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['RoutingContextMatch'] = ResolversParentTypes['RoutingContextMatch']
+> = {
+  key?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  values?: Resolver<Array<ResolversTypes['String']>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
+export type RoutingRuleResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['RoutingRule'] = ResolversParentTypes['RoutingRule']
+> = {
+  priority?: Resolver<ResolversTypes['Int'], ParentType, ContextType>;
+  logTypes?: Resolver<Maybe<Array<ResolversTypes['String']>>, ParentType, ContextType>;
+  tags?: Resolver<Maybe<Array<ResolversTypes['String']>>, ParentType, ContextType>;
+  analysisIds?: Resolver<Maybe<Array<ResolversTypes['String']>>, ParentType, ContextType>;
+  resourceTypes?: Resolver<Maybe<Array<ResolversTypes['String']>>, ParentType, ContextType>;
+  awsAccountIds?: Resolver<Maybe<Array<ResolversTypes['String']>>, ParentType, ContextType>;
+  context?: Resolver<
+    Maybe<Array<ResolversTypes['RoutingContextMatch']>>,
+    ParentType,
+    ContextType
+  >;
+  stopProcessing?: Resolver<Maybe<ResolversTypes['Boolean']>, ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type RuleResolvers<
   ContextType = any,
   ParentType extends ResolversParentTypes['Rule'] = ResolversParentTypes['Rule']
@@ -3712,6 +4036,15 @@ export type UserResolvers<
   __isTypeOf?: IsTypeOfResolverFn<ParentType>;
 };
 
+export type WebexConfigResolvers<
+  ContextType = any,
+  ParentType extends ResolversParentTypes['WebexConfig'] = ResolversParentTypes['WebexConfig']
+> = {
+  botToken?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  roomId?: Resolver<ResolversTypes['String'], ParentType, ContextType>;
+  __isTypeOf?: IsTypeOfResolverFn<ParentType>;
+};
+
 export type Resolvers<ContextType = any> = {
   ActiveSuppressCount?: ActiveSuppressCountResolvers<ContextType>;
   Alert?: AlertResolvers;
@@ -3739,14 +4072,17 @@ export type Resolvers<ContextType = any> = {
   CustomLogOutput?: CustomLogOutputResolvers<ContextType>;
   CustomLogRecord?: CustomLogRecordResolvers<ContextType>;
   CustomWebhookConfig?: CustomWebhookConfigResolvers<ContextType>;
+  CustomWebhookHeader?: CustomWebhookHeaderResolvers<ContextType>;
   DataModel?: DataModelResolvers<ContextType>;
   DataModelMapping?: DataModelMappingResolvers<ContextType>;
   DeleteCustomLogOutput?: DeleteCustomLogOutputResolvers<ContextType>;
+  DeliveryPolicy?: DeliveryPolicyResolvers<ContextType>;
   DeliveryResponse?: DeliveryResponseResolvers<ContextType>;
   Destination?: DestinationResolvers<ContextType>;
   DestinationConfig?: DestinationConfigResolvers<ContextType>;
   Detection?: DetectionResolvers;
   DetectionTestDefinition?: DetectionTestDefinitionResolvers<ContextType>;
+  EmailConfig?: EmailConfigResolvers<ContextType>;
   Error?: ErrorResolvers<ContextType>;
   FloatSeries?: FloatSeriesResolvers<ContextType>;
   FloatSeriesData?: FloatSeriesDataResolvers<ContextType>;
@@ -3754,6 +4090,7 @@ export type Resolvers<ContextType = any> = {
   GetCustomLogOutput?: GetCustomLogOutputResolvers<ContextType>;
   GithubConfig?: GithubConfigResolvers<ContextType>;
   GlobalPythonModule?: GlobalPythonModuleResolvers<ContextType>;
+  GoogleChatConfig?: GoogleChatConfigResolvers<ContextType>;
   IntegrationItemHealthStatus?: IntegrationItemHealthStatusResolvers<ContextType>;
   IntegrationTemplate?: IntegrationTemplateResolvers<ContextType>;
   JiraConfig?: JiraConfigResolvers<ContextType>;
@@ -3771,6 +4108,7 @@ export type Resolvers<ContextType = any> = {
   LongSeries?: LongSeriesResolvers<ContextType>;
   LongSeriesData?: LongSeriesDataResolvers<ContextType>;
   ManagedS3Resources?: ManagedS3ResourcesResolvers<ContextType>;
+  MattermostConfig?: MattermostConfigResolvers<ContextType>;
   MsTeamsConfig?: MsTeamsConfigResolvers<ContextType>;
   Mutation?: MutationResolvers<ContextType>;
   OpsgenieConfig?: OpsgenieConfigResolvers<ContextType>;
@@ -3780,8 +4118,12 @@ export type Resolvers<ContextType = any> = {
   PagingData?: PagingDataResolvers<ContextType>;
   Policy?: PolicyResolvers<ContextType>;
   Query?: QueryResolvers<ContextType>;
+  QuietHours?: QuietHoursResolvers<ContextType>;
+  RateLimit?: RateLimitResolvers<ContextType>;
   ResourceDetails?: ResourceDetailsResolvers<ContextType>;
   ResourceSummary?: ResourceSummaryResolvers<ContextType>;
+  RoutingContextMatch?: RoutingContextMatchResolvers<ContextType>;
+  RoutingRule?: RoutingRuleResolvers<ContextType>;
   Rule?: RuleResolvers<ContextType>;
   S3LogIntegration?: S3LogIntegrationResolvers<ContextType>;
   S3LogIntegrationHealth?: S3LogIntegrationHealthResolvers<ContextType>;
@@ -3805,6 +4147,7 @@ export type Resolvers<ContextType = any> = {
   TestRuleResponse?: TestRuleResponseResolvers<ContextType>;
   UploadDetectionsResponse?: UploadDetectionsResponseResolvers<ContextType>;
   User?: UserResolvers<ContextType>;
+  WebexConfig?: WebexConfigResolvers<ContextType>;
 };
 
 /**
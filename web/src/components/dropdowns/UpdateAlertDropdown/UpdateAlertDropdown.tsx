@@ -35,6 +35,7 @@ import { AlertSummaryFull } from 'Source/graphql/fragments/AlertSummaryFull.gene
 import { useListUsers } from 'Pages/Users/graphql/listUsers.generated';
 import { EventEnum, SrcEnum, trackEvent } from 'Helpers/analytics';
 import { useUpdateAlertStatus } from 'Source/graphql/queries';
+import { PANTHER_USER_ID } from 'Source/constants';
 
 interface UpdateAlertDropdownProps {
   alert: AlertSummaryFull;
@@ -119,8 +120,10 @@ const UpdateAlertDropdown: React.FC<UpdateAlertDropdownProps> = ({ alert }) => {
     },
   });
 
+  // Alerts updated by Panther itself (i.e. when their ticket is closed) are attributed to the system user
   const user = listUsersData?.users.find(({ id }) => id === alert.lastUpdatedBy);
-  const lastUpdatedBy = getUserDisplayName(user);
+  const lastUpdatedBy =
+    alert.lastUpdatedBy === PANTHER_USER_ID ? 'Panther' : getUserDisplayName(user);
   const lastUpdatedByTime = formatDatetime(alert.lastUpdatedByTime);
   const availableStatusesEntries = React.useMemo(() => Object.entries(AlertStatusesEnum), []);
 